@@ -0,0 +1,108 @@
+// Copyright (c) mcp-unreal project contributors. Apache-2.0 license.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+
+	"github.com/remiphilippe/mcp-unreal/internal/audit"
+	"github.com/remiphilippe/mcp-unreal/internal/config"
+)
+
+// runAudit implements "mcp-unreal audit": it prints the audit log, or
+// its sessions, to stdout. It runs instead of the MCP server, so stdout
+// is free for output.
+func runAudit(args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet("audit", flag.ContinueOnError)
+	fs.SetOutput(stdout)
+	fs.Usage = func() {
+		_, _ = fmt.Fprintln(stdout, "Usage: mcp-unreal audit [flags]\n\nReview tool calls recorded in the audit log.\n\nFlags:")
+		fs.PrintDefaults()
+	}
+	dir := fs.String("dir", "", "Audit log directory (default MCP_UNREAL_AUDIT_DIR or Saved/mcp-unreal/audit in the project)")
+	session := fs.String("session", "", "Only calls from this session ID")
+	tool := fs.String("tool", "", "Only calls to tools matching this pattern, e.g. execute_script or level_ops:save_*")
+	status := fs.String("status", "", "Only calls with this outcome: ok, error, or denied")
+	mutating := fs.Bool("mutating", false, "Only write and dangerous calls")
+	since := fs.String("since", "", "Only calls at or after this time: RFC 3339, a date, or a duration ago (24h)")
+	until := fs.String("until", "", "Only calls before this time")
+	sessions := fs.Bool("sessions", false, "List sessions with call counts instead of individual calls")
+	limit := fs.Int("limit", audit.DefaultLimit, fmt.Sprintf("Maximum calls or sessions to print, newest first (max %d)", audit.MaxLimit))
+	asJSON := fs.Bool("json", false, "Print JSON lines instead of a table")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *dir == "" {
+		*dir = config.Load().AuditDir
+	}
+	h := &audit.Handler{Dir: *dir}
+	_, out, err := h.Query(context.Background(), nil, audit.QueryInput{
+		Session: *session, Tool: *tool, Status: *status, Mutating: *mutating,
+		Since: *since, Until: *until, Sessions: *sessions, Limit: *limit,
+	})
+	if err != nil {
+		return err
+	}
+
+	if *asJSON {
+		enc := json.NewEncoder(stdout)
+		if *sessions {
+			for _, s := range out.Sessions {
+				if err := enc.Encode(s); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+		for _, e := range out.Entries {
+			if err := enc.Encode(e); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	tw := tabwriter.NewWriter(stdout, 0, 4, 2, ' ', 0)
+	if *sessions {
+		_, _ = fmt.Fprintln(tw, "SESSION\tCLIENT\tFIRST\tLAST\tCALLS\tMUTATIONS\tERRORS\tDENIED")
+		for _, s := range out.Sessions {
+			_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%d\t%d\t%d\t%d\n", s.Session, s.Client,
+				s.First.Local().Format(time.DateTime), s.Last.Local().Format(time.DateTime),
+				s.Calls, s.Mutations, s.Errors, s.Denied)
+		}
+	} else {
+		_, _ = fmt.Fprintln(tw, "TIME\tSESSION\tTOOL\tCLASS\tSTATUS\tDURATION\tDETAIL")
+		for _, e := range out.Entries {
+			name := e.Tool
+			if e.Operation != "" {
+				name += ":" + e.Operation
+			}
+			detail := e.Error
+			if detail == "" {
+				if args, err := json.Marshal(e.Arguments); err == nil && e.Arguments != nil {
+					detail = string(args)
+				}
+			}
+			if r := []rune(detail); len(r) > 120 {
+				detail = string(r[:117]) + "..."
+			}
+			_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%dms\t%s\n", e.Time.Local().Format(time.DateTime),
+				e.Session, name, e.Class, e.Status, e.DurationMS, detail)
+		}
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	shown := len(out.Entries) + len(out.Sessions)
+	if out.Total > shown {
+		_, _ = fmt.Fprintf(stdout, "(%d of %d shown; use --limit for more)\n", shown, out.Total)
+	}
+	return nil
+}
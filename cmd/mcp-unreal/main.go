@@ -13,29 +13,80 @@ package main
 
 import (
 	"context"
+	"errors"
 	"flag"
 	"fmt"
 	"log/slog"
+	"net"
 	"os"
 	"os/signal"
 	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/remiphilippe/mcp-unreal/internal/audit"
+	"github.com/remiphilippe/mcp-unreal/internal/batch"
 	"github.com/remiphilippe/mcp-unreal/internal/config"
 	"github.com/remiphilippe/mcp-unreal/internal/docs"
 	"github.com/remiphilippe/mcp-unreal/internal/editor"
+	"github.com/remiphilippe/mcp-unreal/internal/editor/editortest"
 	"github.com/remiphilippe/mcp-unreal/internal/headless"
+	"github.com/remiphilippe/mcp-unreal/internal/policy"
+	"github.com/remiphilippe/mcp-unreal/internal/sandbox"
+	"github.com/remiphilippe/mcp-unreal/internal/session"
 	"github.com/remiphilippe/mcp-unreal/internal/status"
+	"github.com/remiphilippe/mcp-unreal/internal/telemetry"
+	"github.com/remiphilippe/mcp-unreal/internal/toolerr"
+	"github.com/remiphilippe/mcp-unreal/internal/workflow"
 )
 
 // Version is set at build time via -ldflags.
 var Version = "0.2.0"
 
 func main() {
-	// Parse CLI flags.
+	// Subcommands run instead of the server.
+	if len(os.Args) > 1 && os.Args[1] == "audit" {
+		if err := runAudit(os.Args[2:], os.Stdout); err != nil {
+			if errors.Is(err, flag.ErrHelp) {
+				os.Exit(0)
+			}
+			fmt.Fprintln(os.Stderr, "mcp-unreal audit:", err)
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+	if len(os.Args) > 1 && os.Args[1] == "replay" {
+		if err := runReplay(os.Args[2:], os.Stdout); err != nil {
+			if errors.Is(err, flag.ErrHelp) {
+				os.Exit(0)
+			}
+			fmt.Fprintln(os.Stderr, "mcp-unreal replay:", err)
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+
+	// Parse CLI flags. Flags that override a setting are listed in
+	// flagSettings and passed to config.LoadWith.
 	buildIndex := flag.Bool("build-index", false, "Build the documentation search index and exit")
-	docsIndex := flag.String("docs-index", "", "Path to the bleve documentation index (overrides MCP_UNREAL_DOCS_INDEX)")
-	logLevel := flag.String("log-level", "", "Log level: debug, info, warn, error (overrides MCP_UNREAL_LOG_LEVEL)")
+	flag.String("config", "", "Config file to use instead of mcp-unreal.toml or .json in the project root (overrides MCP_UNREAL_CONFIG)")
+	flag.String("profile", "", "Config file profile to use (overrides MCP_UNREAL_PROFILE; default the profile named after the project, if any)")
+	flag.String("docs-index", "", "Path to the bleve documentation index (overrides MCP_UNREAL_DOCS_INDEX)")
+	flag.String("docs-manifest", "", "JSON or YAML manifest of extra doc sources for --build-index (overrides MCP_UNREAL_DOCS_MANIFEST)")
+	fakeEditor := flag.Bool("fake-editor", false, "Serve editor tools from an in-memory fake editor instead of a running UE editor (for dry-runs)")
+	flag.String("record-editor", "", "Append all editor HTTP traffic to this cassette file (overrides MCP_UNREAL_EDITOR_RECORD)")
+	flag.String("replay-editor", "", "Answer editor requests from this cassette file instead of the network (overrides MCP_UNREAL_EDITOR_REPLAY)")
+	flag.Bool("allow-remote-editor", false, "Allow non-loopback RC_API_HOST/PLUGIN_HOST (overrides MCP_UNREAL_ALLOW_REMOTE_EDITOR)")
+	flag.Bool("static-tools", false, "Register all editor tools at startup instead of tracking editor availability (overrides MCP_UNREAL_STATIC_TOOLS)")
+	flag.String("policy", "", "Tool policy file (overrides MCP_UNREAL_POLICY; default mcp-unreal.policy.json in the project root)")
+	flag.Bool("read-only", false, "Refuse every tool call that changes the project or editor (overrides MCP_UNREAL_READ_ONLY)")
+	flag.String("trace-exporter", "", "Export OpenTelemetry spans: otlp or file (overrides MCP_UNREAL_TRACE_EXPORTER)")
+	flag.Int("metrics-port", 0, "Serve Prometheus metrics on this localhost port (overrides MCP_UNREAL_METRICS_PORT)")
+	flag.String("log-level", "", "Log level: debug, info, warn, error (overrides MCP_UNREAL_LOG_LEVEL)")
+	flag.String("log-format", "", "Log format: text or json (overrides MCP_UNREAL_LOG_FORMAT)")
+	printConfig := flag.Bool("print-config", false, "Print the effective configuration, with where each setting came from, and exit")
 	showVersion := flag.Bool("version", false, "Print version and exit")
 	flag.Parse()
 
@@ -44,25 +95,42 @@ func main() {
 		os.Exit(0)
 	}
 
-	// Load configuration from environment (IMPLEMENTATION.md §6, config.go).
-	cfg := config.Load()
-
-	// CLI flags override env vars.
-	if *logLevel != "" {
-		if err := os.Setenv("MCP_UNREAL_LOG_LEVEL", *logLevel); err == nil {
-			cfg = config.Load() // reload to pick up new level
+	// Load configuration from config files and the environment, with CLI
+	// flags taking precedence (IMPLEMENTATION.md §6, config.go).
+	overrides := make(map[string]string)
+	flag.Visit(func(f *flag.Flag) {
+		if key, ok := flagSettings[f.Name]; ok {
+			overrides[key] = f.Value.String()
 		}
-	}
-	if *docsIndex != "" {
-		cfg.DocsIndexPath = *docsIndex
+	})
+	cfg := config.LoadWith(overrides)
+
+	// --print-config is the one mode that writes to stdout, since it runs
+	// instead of the server.
+	if *printConfig {
+		if err := cfg.WriteTOML(os.Stdout); err != nil {
+			fmt.Fprintln(os.Stderr, "mcp-unreal:", err)
+			os.Exit(1)
+		}
+		if err := cfg.CheckSettings(); err != nil {
+			fmt.Fprintln(os.Stderr, "mcp-unreal: invalid configuration:", err)
+			os.Exit(1)
+		}
+		os.Exit(0)
 	}
 
 	// All logging goes to stderr — stdout is sacred (CLAUDE.md Security §1).
-	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{
-		Level: cfg.LogLevel,
-	}))
+	logger := newLogger(cfg)
 	slog.SetDefault(logger)
 
+	if err := cfg.CheckSettings(); err != nil {
+		logger.Error("invalid configuration", "error", err)
+		os.Exit(1)
+	}
+	if len(cfg.Files) > 0 {
+		logger.Debug("read config files", "files", cfg.Files, "profile", cfg.Profile)
+	}
+
 	// Handle --build-index mode (IMPLEMENTATION.md §4.3).
 	if *buildIndex {
 		if err := buildDocsIndex(cfg, logger); err != nil {
@@ -72,23 +140,48 @@ func main() {
 		os.Exit(0)
 	}
 
-	// Create MCP server (IMPLEMENTATION.md §2).
-	server := mcp.NewServer(
-		&mcp.Implementation{Name: "mcp-unreal", Version: Version},
-		&mcp.ServerOptions{
-			Instructions: "MCP server for Unreal Engine 5.7. " +
-				"Use the 'status' tool first to check connectivity and available features.",
-			Logger: logger,
-		},
-	)
+	// Handle --fake-editor mode: point the editor tools at an in-memory
+	// fake plugin and RC API so agents can rehearse without UE running.
+	if *fakeEditor {
+		fake, err := startFakeEditor(cfg)
+		if err != nil {
+			logger.Error("failed to start fake editor", "error", err)
+			os.Exit(1)
+		}
+		defer func() { _ = fake.Close() }()
+		logger.Warn("using fake editor — editor tools do not touch a real UE instance",
+			"plugin", fake.PluginURL(), "rc_api", fake.RCAPIURL())
+	}
 
-	// Register tools.
-	registerTools(server, cfg, logger)
+	// Tracing covers tool calls, editor requests, and subprocesses.
+	shutdownTracing, err := telemetry.Setup(context.Background(), cfg, Version, logger)
+	if err != nil {
+		logger.Error("failed to set up tracing", "error", err)
+		os.Exit(1)
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = shutdownTracing(ctx)
+	}()
 
 	// Set up graceful shutdown.
 	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
 	defer cancel()
 
+	if cfg.MetricsPort != 0 {
+		if err := telemetry.ServeMetrics(ctx, cfg.MetricsPort, logger); err != nil {
+			logger.Warn("metrics unavailable", "port", cfg.MetricsPort, "error", err)
+		}
+	}
+
+	server, closeServer, err := newServer(ctx, cfg, logger)
+	if err != nil {
+		logger.Error("failed to set up server", "error", err)
+		os.Exit(1)
+	}
+	defer closeServer()
+
 	logger.Info("starting mcp-unreal", "version", Version, "project", cfg.ProjectRoot)
 
 	// Run stdio transport (blocks until client disconnects).
@@ -98,83 +191,288 @@ func main() {
 	}
 }
 
-// registerTools wires up all MCP tool handlers.
-// Tools are added in phases — see IMPLEMENTATION.md §9 for the roadmap.
-func registerTools(server *mcp.Server, cfg *config.Config, logger *slog.Logger) {
-	// Phase 1: Status tool.
-	statusHandler := &status.Handler{Config: cfg, Version: Version}
-	statusHandler.Register(server)
+// flagSettings maps the CLI flags that override a setting to the
+// environment variable they replace.
+var flagSettings = map[string]string{
+	"config":              "MCP_UNREAL_CONFIG",
+	"profile":             "MCP_UNREAL_PROFILE",
+	"docs-index":          "MCP_UNREAL_DOCS_INDEX",
+	"docs-manifest":       "MCP_UNREAL_DOCS_MANIFEST",
+	"record-editor":       "MCP_UNREAL_EDITOR_RECORD",
+	"replay-editor":       "MCP_UNREAL_EDITOR_REPLAY",
+	"allow-remote-editor": "MCP_UNREAL_ALLOW_REMOTE_EDITOR",
+	"static-tools":        "MCP_UNREAL_STATIC_TOOLS",
+	"policy":              "MCP_UNREAL_POLICY",
+	"read-only":           "MCP_UNREAL_READ_ONLY",
+	"trace-exporter":      "MCP_UNREAL_TRACE_EXPORTER",
+	"metrics-port":        "MCP_UNREAL_METRICS_PORT",
+	"log-level":           "MCP_UNREAL_LOG_LEVEL",
+	"log-format":          "MCP_UNREAL_LOG_FORMAT",
+}
 
-	// Phase 2: Headless build & test tools.
-	headlessHandler := &headless.Handler{Config: cfg, Logger: logger}
-	headlessHandler.Register(server)
-	headlessHandler.RegisterTests(server)
-	headlessHandler.RegisterLog(server)
-	headlessHandler.RegisterCook(server)
-	headlessHandler.RegisterConfig(server)
-	headlessHandler.RegisterProject(server)
+// newLogger returns a stderr logger in cfg's level and format.
+func newLogger(cfg *config.Config) *slog.Logger {
+	opts := &slog.HandlerOptions{Level: cfg.LogLevel}
+	if cfg.LogFormat == "json" {
+		return slog.New(slog.NewJSONHandler(os.Stderr, opts))
+	}
+	return slog.New(slog.NewTextHandler(os.Stderr, opts))
+}
 
-	// Phase 3: Documentation lookup tools (IMPLEMENTATION.md §4).
-	docIdx, err := docs.OpenOrCreate(cfg.DocsIndexPath)
-	if err != nil {
-		logger.Warn("documentation index unavailable, lookup tools disabled", "path", cfg.DocsIndexPath, "error", err)
-	} else {
-		docIdx.Register(server)
-		logger.Debug("documentation index loaded", "path", cfg.DocsIndexPath)
+// toolTimeoutMiddleware bounds calls to the tools in timeouts. The
+// deadline replaces a tool's own default timeout, such as the 30 minutes
+// of build_project.
+func toolTimeoutMiddleware(timeouts map[string]time.Duration) mcp.Middleware {
+	return func(next mcp.MethodHandler) mcp.MethodHandler {
+		return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+			if call, ok := req.(*mcp.CallToolRequest); ok {
+				if d, ok := timeouts[call.Params.Name]; ok {
+					var cancel context.CancelFunc
+					ctx, cancel = context.WithTimeout(ctx, d)
+					defer cancel()
+				}
+			}
+			return next(ctx, method, req)
+		}
 	}
+}
 
-	// Phase 4: Editor communication tools (IMPLEMENTATION.md §3.3–§3.11).
-	editorClient := editor.NewClient(cfg, logger)
-	editorHandler := &editor.Handler{Client: editorClient, Logger: logger}
-	editorHandler.RegisterProperties(server)
-	editorHandler.RegisterActors(server)
-	editorHandler.RegisterUtilities(server)
+// newServer creates the MCP server with its middleware and tools. The
+// returned func closes the editor cassette, audit log, and session
+// recorder; call it once the server has stopped.
+func newServer(ctx context.Context, cfg *config.Config, logger *slog.Logger) (*mcp.Server, func(), error) {
+	// Editor hosts stay on loopback unless the operator opts in
+	// (CLAUDE.md Security §3).
+	if err := cfg.CheckEditorEndpoints(); err != nil {
+		return nil, nil, fmt.Errorf("invalid editor endpoint settings: %w", err)
+	}
+	if !config.IsLoopbackHost(cfg.PluginHost) && cfg.EditorToken == "" {
+		logger.Warn("remote plugin host without MCP_UNREAL_EDITOR_TOKEN — anyone who can reach it controls the editor",
+			"plugin", cfg.PluginURL())
+	}
 
-	// Phase 6: Blueprint & Animation Blueprint tools.
-	editorHandler.RegisterBlueprints(server)
-	editorHandler.RegisterAnimBlueprints(server)
+	// Create MCP server (IMPLEMENTATION.md §2).
+	server := mcp.NewServer(
+		&mcp.Implementation{Name: "mcp-unreal", Version: Version},
+		&mcp.ServerOptions{
+			Instructions: "MCP server for Unreal Engine 5.7. " +
+				"Use the 'status' tool first to check connectivity and available features. " +
+				"Editor tools appear once the UE editor and MCPUnreal plugin are reachable.",
+			Logger: logger,
+			// Clients may subscribe to the editor events resources.
+			SubscribeHandler:   editor.SubscribeEvents,
+			UnsubscribeHandler: editor.UnsubscribeEvents,
+		},
+	)
 
-	// Phase 7: Assets, materials, characters, input, levels, editor utilities.
-	editorHandler.RegisterAssets(server)
-	editorHandler.RegisterMaterials(server)
-	editorHandler.RegisterCharacters(server)
-	editorHandler.RegisterInput(server)
-	editorHandler.RegisterLevels(server)
-	editorHandler.RegisterEditorUtils(server)
+	// Editor client, optionally recording to or replaying from a cassette.
+	editorClient, closeEditor, err := newEditorClient(cfg, logger)
+	if err != nil {
+		return nil, nil, fmt.Errorf("setting up editor client: %w", err)
+	}
+	closers := []func(){closeEditor}
+	closeAll := func() {
+		for i := len(closers) - 1; i >= 0; i-- {
+			closers[i]()
+		}
+	}
 
-	// Phase 8: Mesh tools.
-	editorHandler.RegisterMesh(server)
+	// Named editor instances; tools take an optional "instance" argument
+	// that the middleware routes to the matching editor.
+	if _, err := editor.NewInstances(editorClient, cfg); err != nil {
+		closeAll()
+		return nil, nil, fmt.Errorf("invalid editor instance settings: %w", err)
+	}
 
-	// Phase 10+: Component introspection (issue #40).
-	editorHandler.RegisterComponents(server)
+	// The tool policy is checked before anything else runs, including
+	// instance routing and transactions.
+	pol, err := policy.Find(cfg.PolicyPath, cfg.ProjectRoot)
+	if err != nil {
+		closeAll()
+		return nil, nil, fmt.Errorf("loading tool policy: %w", err)
+	}
+	if cfg.ReadOnly {
+		pol.SetReadOnly()
+	}
+	if sum := pol.Summary(); sum.ReadOnly || sum.Source != "default" {
+		logger.Info("tool policy loaded", "source", sum.Source, "read_only", sum.ReadOnly)
+	}
 
-	// Phase 10+: ISM/HISM management (issue #41).
-	editorHandler.RegisterISM(server)
+	// Every call is traced, audited, and recorded, including those the
+	// policy refuses. List results are paged within the response token
+	// budget. Error results of every layer get an error code and a hint.
+	middleware := []mcp.Middleware{pol.Middleware(logger), editor.PageMiddleware(cfg.ResponseTokenBudget),
+		editor.InstanceMiddleware, editorClient.TransactionMiddleware, editor.DryRunMiddleware}
+	if cfg.SessionDir != "" {
+		recorder, err := session.NewRecorder(cfg.SessionDir, cfg.ProjectRoot, logger)
+		if err != nil {
+			logger.Warn("session recording unavailable", "dir", cfg.SessionDir, "error", err)
+		} else {
+			closers = append(closers, func() { _ = recorder.Close() })
+			middleware = append([]mcp.Middleware{recorder.Middleware}, middleware...)
+			logger.Debug("recording sessions", "dir", cfg.SessionDir)
+		}
+	}
+	if cfg.AuditDir != "" {
+		auditLog, err := audit.Open(cfg.AuditDir, pol, logger)
+		if err != nil {
+			logger.Warn("audit log unavailable, tool calls are not recorded", "dir", cfg.AuditDir, "error", err)
+		} else {
+			closers = append(closers, func() { _ = auditLog.Close() })
+			middleware = append([]mcp.Middleware{auditLog.Middleware}, middleware...)
+			logger.Debug("auditing tool calls", "dir", cfg.AuditDir)
+		}
+	}
+	if len(cfg.ToolTimeouts) > 0 {
+		middleware = append([]mcp.Middleware{toolTimeoutMiddleware(cfg.ToolTimeouts)}, middleware...)
+	}
+	middleware = append([]mcp.Middleware{telemetry.Middleware, toolerr.Middleware}, middleware...)
+	server.AddReceivingMiddleware(middleware...)
 
-	// Phase 10+: Fab marketplace cache/import (issue #42).
-	editorHandler.RegisterFab(server)
+	// Register tools.
+	registerTools(ctx, server, cfg, editorClient, pol, logger)
+	return server, closeAll, nil
+}
 
-	// Phase 10+: Texture management (issue #44).
-	editorHandler.RegisterTextures(server)
+// registerTools wires up all MCP tool handlers.
+// Tools are added in phases — see IMPLEMENTATION.md §9 for the roadmap.
+//
+// Editor tools are added and removed by an editor.ToolWatcher as the
+// editor comes and goes, unless cfg.StaticTools is set.
+func registerTools(ctx context.Context, server *mcp.Server, cfg *config.Config, editorClient *editor.Client, pol *policy.Policy, logger *slog.Logger) {
+	// Phase 3: Documentation lookup tools (IMPLEMENTATION.md §4). Opened
+	// before the status tool so status can report indexed sources.
+	statusHandler := &status.Handler{Config: cfg, Version: Version, Editor: editorClient, Policy: pol}
+	docIdx, err := docs.OpenOrCreate(cfg.DocsIndexPath)
+	if err != nil {
+		logger.Warn("documentation index unavailable, lookup tools disabled", "path", cfg.DocsIndexPath, "error", err)
+	} else {
+		docIdx.SetDefaultVersion(cfg.EngineVersion)
+		docIdx.Register(server)
+		statusHandler.Docs = docIdx
+		logger.Debug("documentation index loaded", "path", cfg.DocsIndexPath, "default_version", cfg.EngineVersion)
+	}
 
-	// Phase 10+: Subsystem introspection (issue #45).
-	editorHandler.RegisterSubsystems(server)
+	// Phase 1: Status tool.
+	statusHandler.Register(server)
 
-	// Phase 10+: DataTable/DataAsset management (issue #46).
-	editorHandler.RegisterDataAssets(server)
+	// File paths in tool arguments are confined to the project, engine,
+	// and extra roots (CLAUDE.md Security §4).
+	paths := sandbox.New(cfg)
 
-	// Phase 10+: UI widget introspection (issue #47).
-	editorHandler.RegisterUIQuery(server)
+	// Phase 2: Headless build & test tools.
+	headlessHandler := &headless.Handler{Config: cfg, Logger: logger, Paths: paths}
+	headlessHandler.Register(server)
+	headlessHandler.RegisterTests(server)
+	headlessHandler.RegisterLog(server)
+	headlessHandler.RegisterCook(server)
+	headlessHandler.RegisterConfig(server)
+	headlessHandler.RegisterProject(server)
 
-	// Phase 10+: Network debug (issue #48).
-	editorHandler.RegisterNetworkDebug(server)
+	// The audit log is readable even when this server is not writing it,
+	// e.g. to review sessions of another server on the same project.
+	auditHandler := &audit.Handler{Dir: cfg.AuditDir}
+	auditHandler.Register(server)
+
+	// Batch runs other tools through a loopback session, so it works with
+	// whichever tools are registered when it is called.
+	batchHandler := &batch.Handler{Server: server, Logger: logger}
+	batchHandler.Register(server)
+
+	// Workflows and replays run their steps the same way.
+	workflowHandler := &workflow.Handler{Server: server, Library: &workflow.Library{Dir: cfg.WorkflowDir}, Logger: logger}
+	workflowHandler.Register(server)
+	replayHandler := &session.Handler{Server: server, Dir: cfg.SessionDir, Paths: paths, Logger: logger}
+	replayHandler.Register(server)
+
+	// Phases 4–10: Editor tools (IMPLEMENTATION.md §3.3–§3.11), grouped
+	// by the plugin route or RC API they need.
+	editorHandler := &editor.Handler{Client: editorClient, Logger: logger, Paths: paths}
+	editorHandler.RegisterInstances(server)
+	groups := editorHandler.ToolGroups()
+
+	// Cassette sessions keep the static tool list and skip the event
+	// stream so polling neither consumes a replay nor fills a recording.
+	cassette := cfg.EditorReplayPath != "" || cfg.EditorRecordPath != ""
+	if !cassette {
+		events := editor.NewEventStream(editorClient, logger)
+		events.RegisterResources(server)
+		editorHandler.Events = events
+		go events.Run(ctx)
+	}
+	if cfg.StaticTools || cassette {
+		names := make([]string, len(groups))
+		for i, g := range groups {
+			g.Register(server)
+			names[i] = g.Name
+		}
+		logger.Debug("registered tools", "editor_groups", names)
+		return
+	}
 
-	// Phase 10: PCG, GAS, Niagara tools.
-	editorHandler.RegisterPCG(server)
-	editorHandler.RegisterGAS(server)
-	editorHandler.RegisterNiagara(server)
+	watcher, err := editor.NewToolWatcher(editorClient, server, groups, logger)
+	if err != nil {
+		logger.Error("failed to set up editor tool watcher, registering all editor tools", "error", err)
+		for _, g := range groups {
+			g.Register(server)
+		}
+		return
+	}
+	watcher.Sync(ctx)
+	go watcher.Run(ctx, editor.DefaultToolRefreshInterval)
+	logger.Debug("registered tools", "editor_groups", watcher.Registered())
+}
 
-	logger.Debug("registered tools", "count", 49)
+// newEditorClient creates the editor client and applies the cassette
+// settings. Replay takes precedence over recording. The returned func
+// closes the recording cassette, if any.
+func newEditorClient(cfg *config.Config, logger *slog.Logger) (*editor.Client, func(), error) {
+	client := editor.NewClient(cfg, logger)
+	if cfg.EditorReplayPath != "" {
+		if cfg.EditorRecordPath != "" {
+			logger.Warn("editor replay enabled, ignoring record cassette", "record", cfg.EditorRecordPath)
+		}
+		if err := client.ReplayFrom(cfg.EditorReplayPath); err != nil {
+			return nil, nil, err
+		}
+		return client, func() {}, nil
+	}
+	if cfg.EditorRecordPath != "" {
+		cassette, err := client.RecordTo(cfg.EditorRecordPath)
+		if err != nil {
+			return nil, nil, err
+		}
+		return client, func() { _ = cassette.Close() }, nil
+	}
+	return client, func() {}, nil
+}
+
+// startFakeEditor starts an editortest fake on ephemeral localhost ports
+// and rewrites the configured endpoints to reach it.
+func startFakeEditor(cfg *config.Config) (*editortest.Server, error) {
+	fake := editortest.New(nil)
+	if err := fake.Start(); err != nil {
+		return nil, err
+	}
+	cfg.RCAPIHost, cfg.PluginHost = "127.0.0.1", "127.0.0.1"
+	cfg.RCAPIScheme, cfg.PluginScheme = "http", "http"
+	if cfg.EditorToken != "" {
+		fake.RequireToken(cfg.EditorToken)
+	}
+	for _, p := range []struct {
+		url  string
+		port *int
+	}{{fake.PluginURL(), &cfg.PluginPort}, {fake.RCAPIURL(), &cfg.RCAPIPort}} {
+		_, port, err := net.SplitHostPort(strings.TrimPrefix(p.url, "http://"))
+		if err == nil {
+			*p.port, err = strconv.Atoi(port)
+		}
+		if err != nil {
+			_ = fake.Close()
+			return nil, fmt.Errorf("parsing fake editor address %s: %w", p.url, err)
+		}
+	}
+	return fake, nil
 }
 
 // buildDocsIndex creates or rebuilds the documentation search index
@@ -182,6 +480,12 @@ func registerTools(server *mcp.Server, cfg *config.Config, logger *slog.Logger)
 func buildDocsIndex(cfg *config.Config, logger *slog.Logger) error {
 	logger.Info("building documentation index", "output", cfg.DocsIndexPath)
 
+	// Resolve sources first so a bad manifest does not wipe the old index.
+	sources, err := collectDocSources(cfg, logger)
+	if err != nil {
+		return err
+	}
+
 	// Remove existing index to rebuild from scratch.
 	if err := os.RemoveAll(cfg.DocsIndexPath); err != nil {
 		return fmt.Errorf("removing old index: %w", err)
@@ -194,32 +498,29 @@ func buildDocsIndex(cfg *config.Config, logger *slog.Logger) error {
 	defer func() { _ = idx.Close() }()
 
 	total := 0
-
-	// Find the docs directory relative to the binary or working directory.
-	docsRoot := findDocsRoot()
-	if docsRoot == "" {
-		logger.Warn("no docs/ directory found, index will be empty")
-	} else {
-		// Ingest UE 5.7 docs.
-		ueDir := filepath.Join(docsRoot, "ue5.7")
-		if info, err := os.Stat(ueDir); err == nil && info.IsDir() {
-			n, err := docs.IngestDirectory(idx, ueDir, "ue5.7", logger)
-			if err != nil {
-				return fmt.Errorf("ingesting UE docs: %w", err)
+	for _, src := range sources {
+		if !src.IsRemote() {
+			if _, err := os.Stat(src.Location()); err != nil {
+				logger.Warn("skipping missing doc source", "source", src.Name, "path", src.Location())
+				continue
 			}
-			total += n
-			logger.Info("indexed UE 5.7 docs", "count", n)
 		}
+		n, err := docs.IngestSource(idx, src, logger)
+		if err != nil {
+			return fmt.Errorf("ingesting %s docs: %w", src.Name, err)
+		}
+		total += n
+		logger.Info("indexed docs", "source", src.Name, "path", src.Location(), "version", src.Version, "count", n)
+	}
 
-		// Ingest RealtimeMesh docs.
-		rmcDir := filepath.Join(docsRoot, "realtimemesh")
-		if info, err := os.Stat(rmcDir); err == nil && info.IsDir() {
-			n, err := docs.IngestDirectory(idx, rmcDir, "realtimemesh", logger)
+	// Project C++ functions double as code examples for lookup_examples.
+	if cfg.ProjectRoot != "" {
+		if info, err := os.Stat(filepath.Join(cfg.ProjectRoot, "Source")); err == nil && info.IsDir() {
+			n, err := docs.IngestProjectSource(idx, cfg.ProjectRoot, logger)
 			if err != nil {
-				return fmt.Errorf("ingesting RealtimeMesh docs: %w", err)
+				return fmt.Errorf("indexing project source examples: %w", err)
 			}
-			total += n
-			logger.Info("indexed RealtimeMesh docs", "count", n)
+			logger.Info("indexed project source examples", "count", n)
 		}
 	}
 
@@ -227,6 +528,42 @@ func buildDocsIndex(cfg *config.Config, logger *slog.Logger) error {
 	return nil
 }
 
+// collectDocSources lists every doc source for --build-index: the
+// bundled docs/ tree, the operator's docs manifest (MCP_UNREAL_DOCS_MANIFEST),
+// and the project's CLAUDE.md.
+func collectDocSources(cfg *config.Config, logger *slog.Logger) ([]docs.Source, error) {
+	var sources []docs.Source
+
+	// Find the docs directory relative to the binary or working directory.
+	// Each docs/<source> directory (or docs manifest entry) is a source;
+	// engine docs named ueX.Y are tagged with their engine version.
+	if docsRoot := findDocsRoot(); docsRoot == "" {
+		logger.Warn("no docs/ directory found")
+	} else {
+		found, err := docs.DiscoverSources(docsRoot)
+		if err != nil {
+			return nil, fmt.Errorf("discovering doc sources: %w", err)
+		}
+		sources = append(sources, found...)
+	}
+
+	if cfg.DocsManifestPath != "" {
+		extra, err := docs.LoadManifest(cfg.DocsManifestPath)
+		if err != nil {
+			return nil, fmt.Errorf("loading docs manifest: %w", err)
+		}
+		sources = append(sources, extra...)
+	}
+
+	if cfg.ProjectRoot != "" {
+		claudeMD := filepath.Join(cfg.ProjectRoot, "CLAUDE.md")
+		if _, err := os.Stat(claudeMD); err == nil {
+			sources = append(sources, docs.Source{Name: "project", File: claudeMD})
+		}
+	}
+	return sources, nil
+}
+
 // findDocsRoot locates the docs/ directory by checking common locations.
 func findDocsRoot() string {
 	// Check relative to working directory.
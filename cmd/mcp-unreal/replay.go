@@ -0,0 +1,150 @@
+// Copyright (c) mcp-unreal project contributors. Apache-2.0 license.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/remiphilippe/mcp-unreal/internal/config"
+	"github.com/remiphilippe/mcp-unreal/internal/loopback"
+	"github.com/remiphilippe/mcp-unreal/internal/sandbox"
+	"github.com/remiphilippe/mcp-unreal/internal/session"
+)
+
+// paramFlags collects repeated --param name=value flags. Values that
+// parse as JSON keep their type; anything else is a string.
+type paramFlags map[string]any
+
+func (p paramFlags) String() string { return "" }
+
+func (p paramFlags) Set(s string) error {
+	name, value, ok := strings.Cut(s, "=")
+	if !ok || name == "" {
+		return fmt.Errorf("want name=value, got %q", s)
+	}
+	var v any
+	if err := json.Unmarshal([]byte(value), &v); err != nil {
+		v = value
+	}
+	p[name] = v
+	return nil
+}
+
+// runReplay implements "mcp-unreal replay": it re-runs a recorded
+// session against the current editor and project and prints each call's
+// outcome to stdout. It runs instead of the MCP server, so stdout is free
+// for output. It fails if the replay stopped at a diverging call.
+func runReplay(args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet("replay", flag.ContinueOnError)
+	fs.SetOutput(stdout)
+	fs.Usage = func() {
+		_, _ = fmt.Fprintln(stdout, "Usage: mcp-unreal replay [flags] <session ID or recording path>\n\n"+
+			"Re-run the tool calls of a recorded session.\n\nFlags:")
+		fs.PrintDefaults()
+	}
+	params := paramFlags{}
+	fs.Var(params, "param", "Value for a {{name}} placeholder, as name=value (repeatable)")
+	dir := fs.String("dir", "", "Session recording directory (default MCP_UNREAL_SESSION_DIR or Saved/mcp-unreal/sessions in the project)")
+	from := fs.Int("from", 0, "First call to replay, by sequence number")
+	to := fs.Int("to", 0, "Last call to replay, by sequence number")
+	includeFailed := fs.Bool("include-failed", false, "Also replay calls that failed or were denied when recorded")
+	keepGoing := fs.Bool("continue", false, "Replay every call instead of stopping at the first divergence")
+	asJSON := fs.Bool("json", false, "Print the report as JSON instead of a table")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return fmt.Errorf("want one session, got %d arguments", fs.NArg())
+	}
+
+	cfg := config.Load()
+	if err := cfg.CheckSettings(); err != nil {
+		return fmt.Errorf("invalid configuration: %w", err)
+	}
+	if *dir != "" {
+		cfg.SessionDir = *dir
+	}
+	// A replay must not wait for the editor watcher to add its tools.
+	cfg.StaticTools = true
+
+	// All logging goes to stderr (CLAUDE.md Security §1).
+	logger := newLogger(cfg)
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+
+	// The recording is read directly, so resolve the path here as the
+	// tool would.
+	h := &session.Handler{Dir: cfg.SessionDir, Paths: sandbox.New(cfg), Logger: logger}
+	path, err := h.Locate(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	rec, err := session.Load(path)
+	if err != nil {
+		return err
+	}
+
+	// Replayed calls go through the full middleware, so they are audited
+	// and checked against the tool policy, but are not recorded again.
+	server, closeServer, err := newServer(ctx, cfg, logger)
+	if err != nil {
+		return err
+	}
+	defer closeServer()
+	client, err := loopback.Connect(server, "replay")
+	if err != nil {
+		return err
+	}
+	defer func() { _ = client.Close() }()
+
+	out, err := session.Replay(ctx, client, rec, session.Options{
+		Params: params, From: *from, To: *to, IncludeFailed: *includeFailed, Continue: *keepGoing,
+	})
+	if err != nil {
+		return err
+	}
+
+	if *asJSON {
+		enc := json.NewEncoder(stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(out); err != nil {
+			return err
+		}
+	} else {
+		tw := tabwriter.NewWriter(stdout, 0, 4, 2, ' ', 0)
+		_, _ = fmt.Fprintln(tw, "SEQ\tTOOL\tSTATUS\tRECORDED\tDETAIL")
+		for _, s := range out.Steps {
+			detail := s.Skipped
+			if s.Error != "" {
+				detail = s.Code + ": " + s.Error
+			}
+			if r := []rune(detail); len(r) > 120 {
+				detail = string(r[:117]) + "..."
+			}
+			status := s.Status
+			if s.Diverged {
+				status += " (diverged)"
+			}
+			_, _ = fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t%s\n", s.Seq, s.Tool, status, s.RecordedStatus, detail)
+		}
+		if err := tw.Flush(); err != nil {
+			return err
+		}
+		_, _ = fmt.Fprintf(stdout, "%d succeeded, %d failed, %d skipped, %d diverged\n",
+			out.Succeeded, out.Failed, out.Skipped, out.Diverged)
+	}
+	if out.StoppedAt != 0 {
+		return fmt.Errorf("stopped at call %d, whose outcome differs from the recording", out.StoppedAt)
+	}
+	return nil
+}
@@ -0,0 +1,238 @@
+// Copyright (c) mcp-unreal project contributors. Apache-2.0 license.
+
+// Package audit keeps a persistent, append-only record of tool calls.
+// Every tools/call is written as one JSON line — time, MCP session and
+// client, tool, redacted arguments, outcome, and duration — to a daily
+// file under the project's Saved/mcp-unreal/audit directory. The
+// audit_query tool and the "mcp-unreal audit" command read it back.
+package audit
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/remiphilippe/mcp-unreal/internal/policy"
+)
+
+// Call outcomes recorded in Entry.Status.
+const (
+	StatusOK     = "ok"
+	StatusError  = "error"
+	StatusDenied = "denied"
+)
+
+// maxSummary caps Entry.Result, in bytes.
+const maxSummary = 300
+
+// Entry is one audited tool call.
+type Entry struct {
+	Time       time.Time      `json:"time" jsonschema:"when the call started (UTC)"`
+	Session    string         `json:"session" jsonschema:"MCP session ID"`
+	Client     string         `json:"client,omitempty" jsonschema:"MCP client name and version from initialize"`
+	Tool       string         `json:"tool" jsonschema:"tool name"`
+	Operation  string         `json:"operation,omitempty" jsonschema:"the call's operation argument, if any"`
+	Class      policy.Class   `json:"class,omitempty" jsonschema:"read, write, or dangerous"`
+	Arguments  map[string]any `json:"arguments,omitempty" jsonschema:"call arguments with secrets redacted"`
+	Status     string         `json:"status" jsonschema:"ok, error, or denied (refused by the tool policy)"`
+	Error      string         `json:"error,omitempty" jsonschema:"error message when status is not ok"`
+	Result     string         `json:"result,omitempty" jsonschema:"start of the tool result text"`
+	DurationMS int64          `json:"duration_ms" jsonschema:"call duration in milliseconds"`
+}
+
+// Classifier returns the class of a tool call. *policy.Policy
+// implements it.
+type Classifier interface {
+	ClassifyCall(c policy.Call) policy.Class
+}
+
+// Log appends entries to daily JSONL files in a directory. It is safe
+// for concurrent use.
+type Log struct {
+	dir        string
+	classifier Classifier
+	logger     *slog.Logger
+
+	mu      sync.Mutex
+	file    *os.File
+	fileDay string
+}
+
+// Open returns a Log writing to dir, creating it if needed. classifier
+// may be nil.
+func Open(dir string, classifier Classifier, logger *slog.Logger) (*Log, error) {
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, fmt.Errorf("creating audit directory: %w", err)
+	}
+	return &Log{
+		dir:        dir,
+		classifier: classifier,
+		logger:     logger,
+	}, nil
+}
+
+// Dir returns the directory the log writes to.
+func (l *Log) Dir() string {
+	return l.dir
+}
+
+// Close closes the current log file.
+func (l *Log) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.file == nil {
+		return nil
+	}
+	err := l.file.Close()
+	l.file = nil
+	return err
+}
+
+// fileName returns the log file for entries on day t.
+func fileName(t time.Time) string {
+	return t.UTC().Format(time.DateOnly) + ".jsonl"
+}
+
+// Append writes e as one line of the file for its day. Files are opened
+// append-only; earlier lines are never rewritten.
+func (l *Log) Append(e Entry) error {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("encoding audit entry: %w", err)
+	}
+	data = append(data, '\n')
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	name := fileName(e.Time)
+	if l.file == nil || l.fileDay != name {
+		if l.file != nil {
+			_ = l.file.Close()
+		}
+		f, err := os.OpenFile(filepath.Join(l.dir, name), os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o600)
+		if err != nil {
+			l.file = nil
+			return fmt.Errorf("opening audit log: %w", err)
+		}
+		l.file, l.fileDay = f, name
+	}
+	if _, err := l.file.Write(data); err != nil {
+		return fmt.Errorf("writing audit log: %w", err)
+	}
+	return nil
+}
+
+// Middleware returns MCP receiving middleware that records every
+// tools/call. Install it first in server.AddReceivingMiddleware so calls
+// refused by the policy are recorded too. A failure to write the log is
+// reported on stderr and does not fail the call.
+func (l *Log) Middleware(next mcp.MethodHandler) mcp.MethodHandler {
+	return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+		call, ok := req.(*mcp.CallToolRequest)
+		if !ok || call.Params == nil {
+			return next(ctx, method, req)
+		}
+		start := time.Now()
+		res, err := next(ctx, method, req)
+
+		pc := policy.NewCall(call.Params.Name, call.Params.Arguments)
+		e := Entry{
+			Time:       start.UTC(),
+			Tool:       pc.Tool,
+			Operation:  pc.Operation,
+			Arguments:  Redact(pc.Args),
+			DurationMS: time.Since(start).Milliseconds(),
+		}
+		e.Session, e.Client = SessionInfo(call.Session)
+		if l.classifier != nil {
+			e.Class = l.classifier.ClassifyCall(pc)
+		}
+		e.Status, e.Error, e.Result = outcome(res, err)
+
+		if werr := l.Append(e); werr != nil {
+			l.logger.Warn("audit log write failed", "tool", e.Tool, "error", werr)
+		}
+		return res, err
+	}
+}
+
+// sessionIDs holds the IDs given to sessions whose transport has none,
+// until the session ends.
+var sessionIDs = struct {
+	sync.Mutex
+	m map[*mcp.ServerSession]string
+}{m: make(map[*mcp.ServerSession]string)}
+
+// SessionInfo returns the ID and client of ss. Transports without
+// session IDs, such as stdio, get a random one per session, the same for
+// every caller, so other records of a session can be matched with its
+// audit entries.
+func SessionInfo(ss *mcp.ServerSession) (id, client string) {
+	if ss == nil {
+		return "", ""
+	}
+	if p := ss.InitializeParams(); p != nil && p.ClientInfo != nil {
+		client = p.ClientInfo.Name
+		if p.ClientInfo.Version != "" {
+			client += " " + p.ClientInfo.Version
+		}
+	}
+	if id = ss.ID(); id != "" {
+		return id, client
+	}
+	sessionIDs.Lock()
+	defer sessionIDs.Unlock()
+	id, ok := sessionIDs.m[ss]
+	if !ok {
+		id = newSessionID()
+		sessionIDs.m[ss] = id
+		go func() {
+			_ = ss.Wait()
+			sessionIDs.Lock()
+			delete(sessionIDs.m, ss)
+			sessionIDs.Unlock()
+		}()
+	}
+	return id, client
+}
+
+// newSessionID returns a sortable, unique session ID such as
+// 20261018T101500-3f9a2c1b.
+func newSessionID() string {
+	b := make([]byte, 4)
+	_, _ = rand.Read(b)
+	return time.Now().UTC().Format("20060102T150405") + "-" + hex.EncodeToString(b)
+}
+
+// outcome summarizes a tools/call result.
+func outcome(res mcp.Result, err error) (status, errMsg, summary string) {
+	if err != nil {
+		return StatusError, err.Error(), ""
+	}
+	r, ok := res.(*mcp.CallToolResult)
+	if !ok || r == nil {
+		return StatusOK, "", ""
+	}
+	var text string
+	for _, c := range r.Content {
+		if t, ok := c.(*mcp.TextContent); ok {
+			text = t.Text
+			break
+		}
+	}
+	if r.IsError {
+		if policy.IsDenial(text) {
+			return StatusDenied, text, ""
+		}
+		return StatusError, text, ""
+	}
+	return StatusOK, "", truncate(text, maxSummary)
+}
@@ -0,0 +1,268 @@
+// Copyright (c) mcp-unreal project contributors. Apache-2.0 license.
+
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/remiphilippe/mcp-unreal/internal/policy"
+)
+
+func TestRedact(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"plain", `{"class_name":"PointLight","location":[1,2,3]}`, `{"class_name":"PointLight","location":[1,2,3]}`},
+		{"secret names", `{"password":"p","api_key":"k","AuthToken":"t","x-auth":"a","author":"me"}`,
+			`{"password":"[REDACTED]","api_key":"[REDACTED]","AuthToken":"[REDACTED]","x-auth":"[REDACTED]","author":"me"}`},
+		{"nested", `{"params":{"client_secret":"s","name":"ok"}}`, `{"params":{"client_secret":"[REDACTED]","name":"ok"}}`},
+		{"secret config key", `{"operation":"set","key":"ApiToken","value":"abc"}`, `{"operation":"set","key":"ApiToken","value":"[REDACTED]"}`},
+		{"ordinary config key", `{"operation":"set","key":"bUseVSync","value":"True"}`, `{"operation":"set","key":"bUseVSync","value":"True"}`},
+		{"inline bearer", `{"command":"http.Get Authorization: Bearer eyJhbGci.x.y"}`, `{"command":"http.Get Authorization: Bearer [REDACTED]"}`},
+		{"inline assignment", `{"script":"login(password='hunter2', user='u')"}`, `{"script":"login(password='[REDACTED]', user='u')"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var in, want map[string]any
+			if err := json.Unmarshal([]byte(tt.in), &in); err != nil {
+				t.Fatal(err)
+			}
+			if err := json.Unmarshal([]byte(tt.want), &want); err != nil {
+				t.Fatal(err)
+			}
+			if got := Redact(in); !reflect.DeepEqual(got, want) {
+				t.Errorf("Redact = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestRedactTruncates(t *testing.T) {
+	got := Redact(map[string]any{"script": strings.Repeat("x", maxArgString+10)})
+	s := got["script"].(string)
+	if !strings.HasSuffix(s, "…[10 bytes truncated]") {
+		t.Errorf("long string not truncated: ...%s", s[len(s)-40:])
+	}
+}
+
+func TestParseTime(t *testing.T) {
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		in      string
+		want    time.Time
+		wantErr bool
+	}{
+		{"", time.Time{}, false},
+		{"2026-10-17T08:30:00Z", time.Date(2026, 10, 17, 8, 30, 0, 0, time.UTC), false},
+		{"2026-10-17", time.Date(2026, 10, 17, 0, 0, 0, 0, time.UTC), false},
+		{"90m", now.Add(-90 * time.Minute), false},
+		{"-1h", time.Time{}, true},
+		{"yesterday", time.Time{}, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseTime(tt.in, now)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseTime(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if !got.Equal(tt.want) {
+			t.Errorf("ParseTime(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestQuery(t *testing.T) {
+	dir := t.TempDir()
+	l, err := Open(dir, nil, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	day1 := time.Date(2026, 10, 17, 9, 0, 0, 0, time.UTC)
+	day2 := day1.Add(24 * time.Hour)
+	for _, e := range []Entry{
+		{Time: day1, Session: "a", Tool: "get_level_actors", Class: policy.Read, Status: StatusOK},
+		{Time: day1.Add(time.Minute), Session: "a", Tool: "execute_script", Class: policy.Dangerous, Status: StatusDenied},
+		{Time: day2, Session: "b", Tool: "level_ops", Operation: "save_level", Class: policy.Write, Status: StatusOK},
+		{Time: day2.Add(time.Minute), Session: "b", Tool: "spawn_actor", Class: policy.Write, Status: StatusError},
+	} {
+		if err := l.Append(e); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := l.Close(); err != nil {
+		t.Fatal(err)
+	}
+	// A torn final line is skipped.
+	f, err := os.OpenFile(filepath.Join(dir, "2026-10-18.jsonl"), os.O_WRONLY|os.O_APPEND, 0)
+	if err != nil {
+		t.Fatalf("daily file missing: %v", err)
+	}
+	_, _ = f.WriteString(`{"time":"2026-10-18T10:00`)
+	_ = f.Close()
+
+	h := &Handler{Dir: dir}
+	tests := []struct {
+		name  string
+		input QueryInput
+		want  []string // tools, newest first
+		total int
+	}{
+		{"all", QueryInput{}, []string{"spawn_actor", "level_ops", "execute_script", "get_level_actors"}, 4},
+		{"limit", QueryInput{Limit: 1}, []string{"spawn_actor"}, 4},
+		{"session", QueryInput{Session: "a"}, []string{"execute_script", "get_level_actors"}, 2},
+		{"tool op pattern", QueryInput{Tool: "level_ops:save_*"}, []string{"level_ops"}, 1},
+		{"status", QueryInput{Status: StatusDenied}, []string{"execute_script"}, 1},
+		{"mutating", QueryInput{Mutating: true, Session: "a"}, []string{"execute_script"}, 1},
+		{"since", QueryInput{Since: "2026-10-18"}, []string{"spawn_actor", "level_ops"}, 2},
+		{"until", QueryInput{Until: "2026-10-17T09:00:30Z"}, []string{"get_level_actors"}, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, out, err := h.Query(context.Background(), nil, tt.input)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, e := range out.Entries {
+				got = append(got, e.Tool)
+			}
+			if !reflect.DeepEqual(got, tt.want) || out.Total != tt.total {
+				t.Errorf("got %v (total %d), want %v (total %d)", got, out.Total, tt.want, tt.total)
+			}
+		})
+	}
+
+	_, out, err := h.Query(context.Background(), nil, QueryInput{Sessions: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []SessionSummary{
+		{Session: "b", First: day2, Last: day2.Add(time.Minute), Calls: 2, Mutations: 2, Errors: 1},
+		{Session: "a", First: day1, Last: day1.Add(time.Minute), Calls: 2, Mutations: 1, Denied: 1},
+	}
+	if !reflect.DeepEqual(out.Sessions, want) {
+		t.Errorf("sessions = %+v, want %+v", out.Sessions, want)
+	}
+
+	for _, bad := range []QueryInput{{Status: "failed"}, {Limit: MaxLimit + 1}, {Since: "soon"}, {Tool: "["}} {
+		if _, _, err := h.Query(context.Background(), nil, bad); err == nil {
+			t.Errorf("Query(%+v): want error", bad)
+		}
+	}
+	if _, _, err := (&Handler{}).Query(context.Background(), nil, QueryInput{}); err == nil {
+		t.Error("Query with auditing off: want error")
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	dir := t.TempDir()
+	pol, err := policy.New(policy.File{Deny: []string{"forbidden"}}, "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	l, err := Open(dir, pol, logger)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = l.Close() }()
+
+	server := mcp.NewServer(&mcp.Implementation{Name: "test", Version: "0"}, nil)
+	server.AddReceivingMiddleware(l.Middleware, pol.Middleware(logger))
+	type in struct {
+		Operation string `json:"operation,omitempty"`
+		Password  string `json:"password,omitempty"`
+		Fail      bool   `json:"fail,omitempty"`
+	}
+	type out struct {
+		OK bool `json:"ok"`
+	}
+	handler := func(ctx context.Context, req *mcp.CallToolRequest, input in) (*mcp.CallToolResult, out, error) {
+		if input.Fail {
+			return nil, out{}, errBoom
+		}
+		return nil, out{OK: true}, nil
+	}
+	mcp.AddTool(server, &mcp.Tool{Name: "level_ops"}, handler)
+	mcp.AddTool(server, &mcp.Tool{Name: "forbidden"}, handler)
+
+	ctx := context.Background()
+	st, ct := mcp.NewInMemoryTransports()
+	if _, err := server.Connect(ctx, st, nil); err != nil {
+		t.Fatal(err)
+	}
+	client := mcp.NewClient(&mcp.Implementation{Name: "agent", Version: "1.2"}, nil)
+	session, err := client.Connect(ctx, ct, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = session.Close() }()
+
+	for _, call := range []mcp.CallToolParams{
+		{Name: "level_ops", Arguments: map[string]any{"operation": "save_level", "password": "hunter2"}},
+		{Name: "level_ops", Arguments: map[string]any{"operation": "get_current", "fail": true}},
+		{Name: "forbidden", Arguments: map[string]any{}},
+	} {
+		if _, err := session.CallTool(ctx, &call); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	entries, err := Read(dir, Query{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 3 {
+		t.Fatalf("got %d entries, want 3", len(entries))
+	}
+	want := []struct {
+		op     string
+		class  policy.Class
+		status string
+	}{
+		{"save_level", policy.Write, StatusOK},
+		{"get_current", policy.Read, StatusError},
+		{"", policy.Write, StatusDenied},
+	}
+	for i, w := range want {
+		e := entries[i]
+		if e.Operation != w.op || e.Class != w.class || e.Status != w.status {
+			t.Errorf("entry %d = %s/%s/%s, want %s/%s/%s", i, e.Operation, e.Class, e.Status, w.op, w.class, w.status)
+		}
+		if e.Session == "" || e.Session != entries[0].Session || e.Client != "agent 1.2" {
+			t.Errorf("entry %d: session %q client %q", i, e.Session, e.Client)
+		}
+	}
+	if got := entries[0].Arguments["password"]; got != Redacted {
+		t.Errorf("password logged as %v", got)
+	}
+	if !strings.Contains(entries[0].Result, `"ok":true`) {
+		t.Errorf("result summary = %q", entries[0].Result)
+	}
+	if !strings.Contains(entries[1].Error, errBoom.Error()) {
+		t.Errorf("error = %q", entries[1].Error)
+	}
+
+	info, err := os.Stat(filepath.Join(dir, fileName(entries[0].Time)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perm := info.Mode().Perm(); perm&0o077 != 0 {
+		t.Errorf("audit file mode = %v, want owner-only", perm)
+	}
+}
+
+var errBoom = errors.New("boom")
@@ -0,0 +1,287 @@
+// Copyright (c) mcp-unreal project contributors. Apache-2.0 license.
+
+package audit
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/remiphilippe/mcp-unreal/internal/policy"
+)
+
+// Limits for query results.
+const (
+	DefaultLimit = 50
+	MaxLimit     = 1000
+)
+
+// Query selects audit entries. Zero fields match everything.
+type Query struct {
+	Session string
+	// Tool is a path.Match pattern for the tool or tool:operation name.
+	Tool   string
+	Status string
+	// Mutating keeps only write and dangerous calls.
+	Mutating bool
+	Since    time.Time
+	Until    time.Time
+}
+
+func (q Query) match(e Entry) bool {
+	if q.Session != "" && e.Session != q.Session {
+		return false
+	}
+	if q.Tool != "" {
+		ok, _ := path.Match(q.Tool, e.Tool)
+		if !ok && e.Operation != "" {
+			ok, _ = path.Match(q.Tool, e.Tool+":"+e.Operation)
+		}
+		if !ok {
+			return false
+		}
+	}
+	if q.Status != "" && e.Status != q.Status {
+		return false
+	}
+	if q.Mutating && e.Class == policy.Read {
+		return false
+	}
+	if !q.Since.IsZero() && e.Time.Before(q.Since) {
+		return false
+	}
+	if !q.Until.IsZero() && !e.Time.Before(q.Until) {
+		return false
+	}
+	return true
+}
+
+// Read returns the entries in dir matching q, oldest first. Lines that
+// do not parse, such as one cut short by a crash, are skipped.
+func Read(dir string, q Query) ([]Entry, error) {
+	if q.Tool != "" {
+		if _, err := path.Match(q.Tool, ""); err != nil {
+			return nil, fmt.Errorf("invalid tool pattern %q: %w", q.Tool, err)
+		}
+	}
+	files, err := filepath.Glob(filepath.Join(dir, "*.jsonl"))
+	if err != nil {
+		return nil, fmt.Errorf("listing audit logs: %w", err)
+	}
+	slices.Sort(files)
+
+	var out []Entry
+	for _, name := range files {
+		// Files are named by UTC day, so whole days can be skipped.
+		day := strings.TrimSuffix(filepath.Base(name), ".jsonl")
+		if !q.Since.IsZero() && day < q.Since.UTC().Format(time.DateOnly) {
+			continue
+		}
+		if !q.Until.IsZero() && day > q.Until.UTC().Format(time.DateOnly) {
+			continue
+		}
+		entries, err := readFile(name, q)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, entries...)
+	}
+	return out, nil
+}
+
+func readFile(name string, q Query) ([]Entry, error) {
+	f, err := os.Open(name) //nolint:gosec // file in the audit directory
+	if err != nil {
+		return nil, fmt.Errorf("opening audit log: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	var out []Entry
+	sc := bufio.NewScanner(f)
+	sc.Buffer(make([]byte, 0, 64<<10), 16<<20)
+	for sc.Scan() {
+		var e Entry
+		if json.Unmarshal(sc.Bytes(), &e) != nil {
+			continue
+		}
+		if q.match(e) {
+			out = append(out, e)
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("reading %s: %w", name, err)
+	}
+	return out, nil
+}
+
+// SessionSummary describes one session's calls.
+type SessionSummary struct {
+	Session   string    `json:"session" jsonschema:"MCP session ID"`
+	Client    string    `json:"client,omitempty" jsonschema:"MCP client name and version"`
+	First     time.Time `json:"first" jsonschema:"time of the first matching call"`
+	Last      time.Time `json:"last" jsonschema:"time of the last matching call"`
+	Calls     int       `json:"calls" jsonschema:"number of matching calls"`
+	Mutations int       `json:"mutations" jsonschema:"number of write and dangerous calls"`
+	Errors    int       `json:"errors" jsonschema:"number of failed calls"`
+	Denied    int       `json:"denied" jsonschema:"number of calls refused by the tool policy"`
+}
+
+// Sessions groups entries by session, most recently active first.
+func Sessions(entries []Entry) []SessionSummary {
+	index := map[string]int{}
+	var out []SessionSummary
+	for _, e := range entries {
+		i, ok := index[e.Session]
+		if !ok {
+			i = len(out)
+			index[e.Session] = i
+			out = append(out, SessionSummary{Session: e.Session, Client: e.Client, First: e.Time})
+		}
+		s := &out[i]
+		s.Calls++
+		s.First = minTime(s.First, e.Time)
+		if e.Time.After(s.Last) {
+			s.Last = e.Time
+		}
+		if e.Class == policy.Write || e.Class == policy.Dangerous {
+			s.Mutations++
+		}
+		switch e.Status {
+		case StatusError:
+			s.Errors++
+		case StatusDenied:
+			s.Denied++
+		}
+	}
+	slices.SortStableFunc(out, func(a, b SessionSummary) int { return b.Last.Compare(a.Last) })
+	return out
+}
+
+func minTime(a, b time.Time) time.Time {
+	if b.Before(a) {
+		return b
+	}
+	return a
+}
+
+// ParseTime parses a query bound: an RFC 3339 time, a date
+// (2026-10-18, UTC midnight), or a duration before now (90m, 24h).
+func ParseTime(s string, now time.Time) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse(time.DateOnly, s); err == nil {
+		return t, nil
+	}
+	if d, err := time.ParseDuration(s); err == nil && d >= 0 {
+		return now.Add(-d), nil
+	}
+	return time.Time{}, fmt.Errorf("invalid time %q: use RFC 3339 (2026-10-18T09:00:00Z), a date (2026-10-18), or a duration ago (24h)", s)
+}
+
+// --- audit_query ---
+
+// QueryInput defines parameters for the audit_query tool.
+type QueryInput struct {
+	Session  string `json:"session,omitempty" jsonschema:"Only calls from this session ID"`
+	Tool     string `json:"tool,omitempty" jsonschema:"Only calls to tools matching this pattern, e.g. execute_script, level_ops:save_*, *_ops"`
+	Status   string `json:"status,omitempty" jsonschema:"Only calls with this outcome: ok, error, or denied"`
+	Mutating bool   `json:"mutating,omitempty" jsonschema:"Only write and dangerous calls"`
+	Since    string `json:"since,omitempty" jsonschema:"Only calls at or after this time: RFC 3339, a date (2026-10-18), or a duration ago (24h). Default: all"`
+	Until    string `json:"until,omitempty" jsonschema:"Only calls before this time, same formats as since"`
+	Sessions bool   `json:"sessions,omitempty" jsonschema:"List sessions with call counts instead of individual calls"`
+	Limit    int    `json:"limit,omitempty" jsonschema:"Maximum calls or sessions to return, newest first (default 50, max 1000)"`
+}
+
+// QueryOutput is returned by the audit_query tool.
+type QueryOutput struct {
+	Entries  []Entry          `json:"entries,omitempty" jsonschema:"matching calls, newest first"`
+	Sessions []SessionSummary `json:"sessions,omitempty" jsonschema:"matching sessions, most recently active first"`
+	Total    int              `json:"total" jsonschema:"number of matching calls or sessions before the limit"`
+	Dir      string           `json:"dir" jsonschema:"audit log directory"`
+}
+
+// Handler serves the audit_query tool.
+type Handler struct {
+	// Dir is the audit log directory, or "" when auditing is off.
+	Dir string
+}
+
+// Register adds the audit_query tool to the MCP server.
+func (h *Handler) Register(server *mcp.Server) {
+	mcp.AddTool(server, &mcp.Tool{
+		Name: "audit_query",
+		Description: "Review the audit log of tool calls made through this server, across sessions: " +
+			"time, session and client, tool, redacted arguments, outcome, and duration. Filter by " +
+			"session, tool pattern, outcome, mutating calls, and time range, or set sessions=true " +
+			"to list sessions with call counts.",
+	}, h.Query)
+}
+
+// Query implements the audit_query tool.
+func (h *Handler) Query(ctx context.Context, req *mcp.CallToolRequest, input QueryInput) (*mcp.CallToolResult, QueryOutput, error) {
+	if h.Dir == "" {
+		return nil, QueryOutput{}, fmt.Errorf("audit log is off: no project root detected; set MCP_UNREAL_PROJECT or MCP_UNREAL_AUDIT_DIR")
+	}
+	q, limit, err := input.query(time.Now())
+	if err != nil {
+		return nil, QueryOutput{}, err
+	}
+	entries, err := Read(h.Dir, q)
+	if err != nil {
+		return nil, QueryOutput{}, fmt.Errorf("reading audit log: %w", err)
+	}
+
+	out := QueryOutput{Dir: h.Dir}
+	if input.Sessions {
+		sessions := Sessions(entries)
+		out.Total = len(sessions)
+		out.Sessions = sessions[:min(limit, len(sessions))]
+		return nil, out, nil
+	}
+	out.Total = len(entries)
+	out.Entries = Newest(entries, limit)
+	return nil, out, nil
+}
+
+func (in QueryInput) query(now time.Time) (Query, int, error) {
+	switch in.Status {
+	case "", StatusOK, StatusError, StatusDenied:
+	default:
+		return Query{}, 0, fmt.Errorf("status must be ok, error, or denied")
+	}
+	limit := in.Limit
+	switch {
+	case limit == 0:
+		limit = DefaultLimit
+	case limit < 0 || limit > MaxLimit:
+		return Query{}, 0, fmt.Errorf("limit must be between 1 and %d", MaxLimit)
+	}
+	q := Query{Session: in.Session, Tool: in.Tool, Status: in.Status, Mutating: in.Mutating}
+	var err error
+	if q.Since, err = ParseTime(in.Since, now); err != nil {
+		return Query{}, 0, fmt.Errorf("since: %w", err)
+	}
+	if q.Until, err = ParseTime(in.Until, now); err != nil {
+		return Query{}, 0, fmt.Errorf("until: %w", err)
+	}
+	return q, limit, nil
+}
+
+// Newest returns the last n entries, newest first.
+func Newest(entries []Entry, n int) []Entry {
+	out := slices.Clone(entries[max(len(entries)-n, 0):])
+	slices.Reverse(out)
+	return out
+}
@@ -0,0 +1,130 @@
+// Copyright (c) mcp-unreal project contributors. Apache-2.0 license.
+
+package audit
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// Redacted replaces secret values in audited arguments.
+const Redacted = "[REDACTED]"
+
+// maxArgString caps each string argument in the log, in bytes, so a
+// large script or base64 payload does not swamp it.
+const maxArgString = 16 << 10
+
+// secretWords mark an argument name as holding a secret when one of
+// them is a word of the name (api_token, x-auth).
+var secretWords = map[string]bool{
+	"password": true, "passwd": true, "passphrase": true,
+	"secret": true, "token": true, "auth": true, "authorization": true,
+	"credential": true, "credentials": true, "cookie": true,
+	"apikey": true, "privatekey": true, "accesskey": true, "secretkey": true,
+}
+
+// secretSuffixes mark camel-case or run-together names (ApiToken,
+// clientsecret) as secrets.
+var secretSuffixes = []string{"password", "passwd", "secret", "token", "apikey", "privatekey", "accesskey", "secretkey", "credentials"}
+
+// inlineSecrets match secrets embedded in free text, such as a script or
+// a console command: bearer tokens and password=..., token: ... pairs.
+var inlineSecrets = []*regexp.Regexp{
+	regexp.MustCompile(`(?i)(bearer\s+)[A-Za-z0-9._~+/-]+=*`),
+	regexp.MustCompile(`(?i)((?:password|passwd|secret|token|api_?key)\s*[=:]\s*["']?)[^\s"',;]+`),
+}
+
+// isSecretKey reports whether an argument or config key name holds a
+// secret.
+func isSecretKey(key string) bool {
+	k := strings.ToLower(key)
+	for _, w := range strings.FieldsFunc(k, func(r rune) bool { return r == '_' || r == '-' || r == '.' }) {
+		if secretWords[w] {
+			return true
+		}
+	}
+	k = strings.NewReplacer("_", "", "-", "", ".", "").Replace(k)
+	for _, s := range secretSuffixes {
+		if strings.HasSuffix(k, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// Redact returns a copy of args with secrets replaced by Redacted:
+// values under secret-looking names, the value of a key/value pair whose
+// key looks secret (as config_ops set takes), and inline secrets in
+// strings. Long strings are truncated.
+func Redact(args map[string]any) map[string]any {
+	if args == nil {
+		return nil
+	}
+	r, _ := redactValue(args, maxArgString).(map[string]any)
+	return r
+}
+
+// RedactSecrets is Redact without truncation, for records whose
+// arguments are sent again.
+func RedactSecrets(args map[string]any) map[string]any {
+	if args == nil {
+		return nil
+	}
+	r, _ := redactValue(args, 0).(map[string]any)
+	return r
+}
+
+// redactValue redacts v, truncating strings to limit bytes unless limit
+// is zero.
+func redactValue(v any, limit int) any {
+	switch v := v.(type) {
+	case map[string]any:
+		out := make(map[string]any, len(v))
+		secretPair := false
+		for _, k := range []string{"key", "name"} {
+			if s, ok := v[k].(string); ok && isSecretKey(s) {
+				secretPair = true
+			}
+		}
+		for k, e := range v {
+			switch {
+			case isSecretKey(k), secretPair && k == "value":
+				out[k] = Redacted
+			default:
+				out[k] = redactValue(e, limit)
+			}
+		}
+		return out
+	case []any:
+		out := make([]any, len(v))
+		for i, e := range v {
+			out[i] = redactValue(e, limit)
+		}
+		return out
+	case string:
+		for _, re := range inlineSecrets {
+			v = re.ReplaceAllString(v, "${1}"+Redacted)
+		}
+		if limit > 0 {
+			v = truncate(v, limit)
+		}
+		return v
+	default:
+		return v
+	}
+}
+
+// truncate shortens s to at most n bytes on a rune boundary, noting how
+// much was cut.
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	cut := n
+	for cut > 0 && !utf8.RuneStart(s[cut]) {
+		cut--
+	}
+	return fmt.Sprintf("%s…[%d bytes truncated]", s[:cut], len(s)-cut)
+}
@@ -0,0 +1,361 @@
+// Copyright (c) mcp-unreal project contributors. Apache-2.0 license.
+
+// Package batch implements the "batch" MCP tool, which runs an ordered
+// list of tool calls in one request. Later steps can reference the
+// results of earlier ones with ${step.path} placeholders, e.g. the
+// actor_path returned by spawn_actor.
+//
+// Steps are dispatched through an in-memory client session connected to
+// the same server, so each one passes the same middleware (instance
+// routing, and any checks added later) as a call from the MCP client.
+package batch
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/jsonrpc"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/remiphilippe/mcp-unreal/internal/loopback"
+	"github.com/remiphilippe/mcp-unreal/internal/policy"
+	"github.com/remiphilippe/mcp-unreal/internal/toolerr"
+)
+
+// MaxSteps bounds the number of steps in one batch.
+const MaxSteps = 200
+
+// Step is one tool invocation in a batch.
+type Step struct {
+	ID        string         `json:"id,omitempty" jsonschema:"Optional name for referencing this step's result as ${id.field}; steps can always be referenced by 0-based index as ${0.field}"`
+	Tool      string         `json:"tool" jsonschema:"required,Tool name, e.g. spawn_actor"`
+	Arguments map[string]any `json:"arguments,omitempty" jsonschema:"Tool arguments. String values may contain ${step.path} references to earlier results"`
+}
+
+// Input defines parameters for the batch tool.
+type Input struct {
+	Steps    []Step `json:"steps" jsonschema:"required,Tool calls to run in order"`
+	OnError  string `json:"on_error,omitempty" jsonschema:"stop (default): skip the remaining steps after a failure; continue: run them anyway"`
+	Instance string `json:"instance,omitempty" jsonschema:"Editor instance for steps of editor tools that do not set their own instance argument"`
+}
+
+// StepResult reports the outcome of one step.
+type StepResult struct {
+	Index   int    `json:"index" jsonschema:"0-based step index"`
+	ID      string `json:"id,omitempty" jsonschema:"step id, if given"`
+	Tool    string `json:"tool" jsonschema:"tool name"`
+	Status  string `json:"status" jsonschema:"ok, error, or skipped"`
+	Error   string `json:"error,omitempty" jsonschema:"error message if the step failed"`
+	Code    string `json:"code,omitempty" jsonschema:"error code if the step failed, e.g. EDITOR_OFFLINE or VALIDATION"`
+	Result  any    `json:"result,omitempty" jsonschema:"the tool's structured result, or its text output"`
+	Skipped string `json:"skip_reason,omitempty" jsonschema:"why the step was not run"`
+}
+
+// Output is returned by the batch tool.
+type Output struct {
+	Steps     []StepResult `json:"steps" jsonschema:"per-step results in order"`
+	Succeeded int          `json:"succeeded" jsonschema:"number of steps that succeeded"`
+	Failed    int          `json:"failed" jsonschema:"number of steps that failed"`
+	Skipped   int          `json:"skipped" jsonschema:"number of steps not run"`
+}
+
+// Handler runs batches against the tools of Server.
+type Handler struct {
+	Server *mcp.Server
+	Logger *slog.Logger
+
+	loop loopback.Lazy
+}
+
+// Register adds the batch tool to the MCP server.
+func (h *Handler) Register(server *mcp.Server) {
+	mcp.AddTool(server, &mcp.Tool{
+		Name: "batch",
+		Description: "Run several tool calls in order in one request, e.g. to build a scene with many " +
+			"spawn_actor and set_property calls. Each step names a tool and its arguments. A string argument " +
+			"can reference an earlier step's result as ${id.path} or ${index.path}, e.g. " +
+			"\"${lamp.actor_path}\" or \"${0.actors.2.name}\"; a reference that is the whole string keeps " +
+			"the value's JSON type. on_error=stop (default) skips the remaining steps after a failure; " +
+			"on_error=continue runs them, failing only steps that reference a failed one. " +
+			"Returns each step's status and result.",
+	}, h.Batch)
+}
+
+// Batch implements the batch tool.
+func (h *Handler) Batch(ctx context.Context, req *mcp.CallToolRequest, input Input) (*mcp.CallToolResult, Output, error) {
+	if len(input.Steps) == 0 {
+		return nil, Output{}, toolerr.New(toolerr.Validation, "steps is required")
+	}
+	if len(input.Steps) > MaxSteps {
+		return nil, Output{}, toolerr.New(toolerr.Validation, "batch has %d steps, at most %d allowed", len(input.Steps), MaxSteps)
+	}
+	switch input.OnError {
+	case "", "stop", "continue":
+	default:
+		return nil, Output{}, toolerr.New(toolerr.Validation, "invalid on_error %q — use stop or continue", input.OnError)
+	}
+	ids := make(map[string]int)
+	for i, s := range input.Steps {
+		if s.Tool == "" {
+			return nil, Output{}, toolerr.New(toolerr.Validation, "step %d: tool is required", i)
+		}
+		if s.Tool == "batch" {
+			return nil, Output{}, toolerr.New(toolerr.Validation, "step %d: batches cannot be nested", i)
+		}
+		if s.ID != "" {
+			if _, err := strconv.Atoi(s.ID); err == nil {
+				return nil, Output{}, toolerr.New(toolerr.Validation, "step %d: id %q must not be a number", i, s.ID)
+			}
+			if _, dup := ids[s.ID]; dup {
+				return nil, Output{}, toolerr.New(toolerr.Validation, "step %d: duplicate id %q", i, s.ID)
+			}
+			ids[s.ID] = i
+		}
+	}
+
+	ctx, err := loopback.Enter(ctx, req)
+	if err != nil {
+		return nil, Output{}, err
+	}
+	session, err := h.loop.Get(h.Server, "batch")
+	if err != nil {
+		return nil, Output{}, err
+	}
+
+	// Only tools that take an instance get the batch's instance; the
+	// others reject unknown arguments.
+	var instanceTools map[string]bool
+	if input.Instance != "" {
+		if instanceTools, err = toolsWithProperty(ctx, session, "instance"); err != nil {
+			return nil, Output{}, err
+		}
+	}
+
+	out := Output{Steps: make([]StepResult, len(input.Steps))}
+	stopped := false
+	for i, s := range input.Steps {
+		r := &out.Steps[i]
+		*r = StepResult{Index: i, ID: s.ID, Tool: s.Tool}
+		if stopped {
+			r.Status, r.Skipped = "skipped", "an earlier step failed and on_error is stop"
+			out.Skipped++
+			continue
+		}
+		if ctx.Err() != nil {
+			return nil, Output{}, ctx.Err()
+		}
+
+		prior := &refs{steps: out.Steps[:i], ids: ids}
+		args, err := Resolve(s.Arguments, prior.lookup)
+		if err == nil {
+			if a, ok := args.(map[string]any); ok && instanceTools[s.Tool] {
+				if _, set := a["instance"]; !set {
+					a["instance"] = input.Instance
+				}
+			}
+			r.Result, err = Call(ctx, session, s.Tool, args)
+		}
+		if err != nil {
+			r.Status, r.Error, r.Result = "error", err.Error(), nil
+			r.Code = string(toolerr.CodeOf(err))
+			out.Failed++
+			stopped = input.OnError != "continue"
+			h.Logger.Debug("batch step failed", "index", i, "tool", s.Tool, "error", err)
+			continue
+		}
+		r.Status = "ok"
+		out.Succeeded++
+	}
+	return nil, out, nil
+}
+
+// toolsWithProperty returns the names of the tools listed by session
+// whose input schema has the property name.
+func toolsWithProperty(ctx context.Context, session *mcp.ClientSession, name string) (map[string]bool, error) {
+	tools := make(map[string]bool)
+	for t, err := range session.Tools(ctx, nil) {
+		if err != nil {
+			return nil, fmt.Errorf("listing tools: %w", err)
+		}
+		if policy.HasProperty(t, name) {
+			tools[t.Name] = true
+		}
+	}
+	return tools, nil
+}
+
+// Call runs one tool through session and returns its structured result,
+// or its text output when it has none. A tool error result is returned
+// as an error with the result's error code; a call the server refuses
+// as malformed is a VALIDATION error, or NOT_FOUND for an unknown tool.
+func Call(ctx context.Context, session *mcp.ClientSession, tool string, args any) (any, error) {
+	res, err := session.CallTool(ctx, loopback.Params(ctx, tool, args))
+	if err != nil {
+		return nil, protocolError(err)
+	}
+	if res.IsError {
+		if d, ok := toolerr.FromResult(res); ok {
+			return nil, toolerr.New(d.Code, "%s", d.Message).WithHint(d.Hint)
+		}
+		return nil, fmt.Errorf("%s", toolerr.ResultText(res))
+	}
+	if res.StructuredContent != nil {
+		// Normalize to plain JSON values for reference lookups.
+		data, err := json.Marshal(res.StructuredContent)
+		if err != nil {
+			return nil, fmt.Errorf("encoding %s result: %w", tool, err)
+		}
+		var v any
+		if err := json.Unmarshal(data, &v); err != nil {
+			return nil, fmt.Errorf("decoding %s result: %w", tool, err)
+		}
+		return v, nil
+	}
+	if text := toolerr.ResultText(res); text != "" {
+		return text, nil
+	}
+	return nil, nil
+}
+
+// protocolError codes a JSON-RPC error from a tools/call. Errors other
+// than invalid params and unknown methods are returned unchanged.
+func protocolError(err error) error {
+	var rpcErr *jsonrpc.Error
+	if !errors.As(err, &rpcErr) {
+		return err
+	}
+	switch {
+	case rpcErr.Code == jsonrpc.CodeInvalidParams && strings.HasPrefix(rpcErr.Message, "unknown tool"):
+		return toolerr.Wrap(toolerr.NotFound, err)
+	case rpcErr.Code == jsonrpc.CodeInvalidParams, rpcErr.Code == jsonrpc.CodeMethodNotFound:
+		return toolerr.Wrap(toolerr.Validation, err)
+	}
+	return err
+}
+
+// refPattern matches ${step.path} references. The step is an id or a
+// 0-based index; the path is dot-separated keys and array indexes.
+var refPattern = regexp.MustCompile(`\$\{([A-Za-z0-9_-]+)((?:\.[A-Za-z0-9_-]+)*)\}`)
+
+// Lookup returns the value of the reference ${name.path}; path is empty
+// or dot-prefixed (".a.0.b").
+type Lookup func(name, path string) (any, error)
+
+// refs resolves references against the steps run so far.
+type refs struct {
+	steps []StepResult
+	ids   map[string]int
+}
+
+// Resolve returns v with every ${name.path} reference in its strings
+// replaced by lookup. A string that is a single reference becomes the
+// referenced value itself; otherwise referenced values are formatted
+// into the string.
+func Resolve(v any, lookup Lookup) (any, error) {
+	switch v := v.(type) {
+	case map[string]any:
+		out := make(map[string]any, len(v))
+		for k, e := range v {
+			resolved, err := Resolve(e, lookup)
+			if err != nil {
+				return nil, err
+			}
+			out[k] = resolved
+		}
+		return out, nil
+	case []any:
+		out := make([]any, len(v))
+		for i, e := range v {
+			resolved, err := Resolve(e, lookup)
+			if err != nil {
+				return nil, err
+			}
+			out[i] = resolved
+		}
+		return out, nil
+	case string:
+		return expand(v, lookup)
+	default:
+		return v, nil
+	}
+}
+
+// expand resolves the references in s.
+func expand(s string, lookup Lookup) (any, error) {
+	if m := refPattern.FindStringSubmatchIndex(s); m != nil && m[0] == 0 && m[1] == len(s) {
+		return lookup(s[m[2]:m[3]], s[m[4]:m[5]])
+	}
+	var firstErr error
+	out := refPattern.ReplaceAllStringFunc(s, func(ref string) string {
+		m := refPattern.FindStringSubmatch(ref)
+		v, err := lookup(m[1], m[2])
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			return ref
+		}
+		if str, ok := v.(string); ok {
+			return str
+		}
+		data, _ := json.Marshal(v)
+		return string(data)
+	})
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	return out, nil
+}
+
+// lookup returns the value at path (".a.0.b") in the result of step.
+func (r *refs) lookup(step, path string) (any, error) {
+	ref := "${" + step + path + "}"
+	i, ok := r.ids[step]
+	if !ok {
+		n, err := strconv.Atoi(step)
+		if err != nil {
+			return nil, toolerr.New(toolerr.Validation, "reference %s: no step with id %q", ref, step)
+		}
+		i = n
+	}
+	if i < 0 || i >= len(r.steps) {
+		return nil, toolerr.New(toolerr.Validation, "reference %s: step %s has not run yet (only earlier steps can be referenced)", ref, step)
+	}
+	if st := r.steps[i]; st.Status != "ok" {
+		return nil, toolerr.New(toolerr.Validation, "reference %s: step %s did not succeed (%s)", ref, step, st.Status)
+	}
+	return Select(r.steps[i].Result, step, path)
+}
+
+// Select returns the value at path (".a.0.b") in v, the value of the
+// reference name. Map keys and array indexes are separated by dots.
+func Select(v any, name, path string) (any, error) {
+	ref := "${" + name + path + "}"
+	for _, key := range strings.Split(strings.TrimPrefix(path, "."), ".") {
+		if key == "" {
+			continue
+		}
+		switch c := v.(type) {
+		case map[string]any:
+			e, ok := c[key]
+			if !ok {
+				return nil, toolerr.New(toolerr.Validation, "reference %s: no field %q", ref, key)
+			}
+			v = e
+		case []any:
+			n, err := strconv.Atoi(key)
+			if err != nil || n < 0 || n >= len(c) {
+				return nil, toolerr.New(toolerr.Validation, "reference %s: index %q out of range (length %d)", ref, key, len(c))
+			}
+			v = c[n]
+		default:
+			return nil, toolerr.New(toolerr.Validation, "reference %s: cannot select %q from a %T", ref, key, v)
+		}
+	}
+	return v, nil
+}
@@ -0,0 +1,241 @@
+// Copyright (c) mcp-unreal project contributors. Apache-2.0 license.
+
+package batch
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/remiphilippe/mcp-unreal/internal/toolerr"
+)
+
+type spawnInput struct {
+	Name     string `json:"name"`
+	Instance string `json:"instance,omitempty"`
+}
+
+type spawnOutput struct {
+	ActorPath string   `json:"actor_path"`
+	Tags      []string `json:"tags"`
+	Instance  string   `json:"instance,omitempty"`
+}
+
+type labelInput struct {
+	Target any    `json:"target"`
+	Label  string `json:"label,omitempty"`
+}
+
+type labelOutput struct {
+	Target any    `json:"target"`
+	Label  string `json:"label,omitempty"`
+}
+
+// newTestHandler returns a batch handler on a server with a spawn tool
+// that fails for the name "bad", and a label tool that echoes its input.
+func newTestHandler() *Handler {
+	server := mcp.NewServer(&mcp.Implementation{Name: "test"}, nil)
+	mcp.AddTool(server, &mcp.Tool{Name: "spawn"}, func(_ context.Context, _ *mcp.CallToolRequest, in spawnInput) (*mcp.CallToolResult, spawnOutput, error) {
+		if in.Name == "bad" {
+			return nil, spawnOutput{}, fmt.Errorf("cannot spawn %q", in.Name)
+		}
+		return nil, spawnOutput{ActorPath: "/Game/Maps/Main." + in.Name, Tags: []string{"a", "b"}, Instance: in.Instance}, nil
+	})
+	mcp.AddTool(server, &mcp.Tool{Name: "label"}, func(_ context.Context, _ *mcp.CallToolRequest, in labelInput) (*mcp.CallToolResult, labelOutput, error) {
+		return nil, labelOutput(in), nil
+	})
+	h := &Handler{Server: server, Logger: slog.New(slog.NewTextHandler(io.Discard, nil))}
+	h.Register(server)
+	return h
+}
+
+func TestBatch(t *testing.T) {
+	h := newTestHandler()
+	ctx := context.Background()
+
+	_, out, err := h.Batch(ctx, nil, Input{Steps: []Step{
+		{ID: "lamp", Tool: "spawn", Arguments: map[string]any{"name": "Lamp"}},
+		{Tool: "label", Arguments: map[string]any{"target": "${lamp.actor_path}", "label": "tag ${0.tags.1} of ${lamp.tags}"}},
+		{Tool: "label", Arguments: map[string]any{"target": "${lamp.tags}"}},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out.Succeeded != 3 || out.Failed != 0 || out.Skipped != 0 {
+		t.Fatalf("totals = %d/%d/%d, want 3/0/0: %+v", out.Succeeded, out.Failed, out.Skipped, out.Steps)
+	}
+	want := map[string]any{"target": "/Game/Maps/Main.Lamp", "label": `tag b of ["a","b"]`}
+	if got := out.Steps[1].Result; !reflect.DeepEqual(got, want) {
+		t.Errorf("step 1 result = %v, want %v", got, want)
+	}
+	// A whole-string reference keeps the value's JSON type.
+	if got := out.Steps[2].Result.(map[string]any)["target"]; !reflect.DeepEqual(got, []any{"a", "b"}) {
+		t.Errorf("step 2 target = %#v, want the tags array", got)
+	}
+}
+
+func TestBatchErrors(t *testing.T) {
+	tests := []struct {
+		name       string
+		input      Input
+		wantStatus []string
+		wantError  string // substring of the first failed step's error
+	}{
+		{
+			name: "stop skips the rest",
+			input: Input{Steps: []Step{
+				{Tool: "spawn", Arguments: map[string]any{"name": "bad"}},
+				{Tool: "spawn", Arguments: map[string]any{"name": "Lamp"}},
+			}},
+			wantStatus: []string{"error", "skipped"},
+			wantError:  `cannot spawn "bad"`,
+		},
+		{
+			name: "continue runs the rest",
+			input: Input{OnError: "continue", Steps: []Step{
+				{ID: "a", Tool: "spawn", Arguments: map[string]any{"name": "bad"}},
+				{Tool: "spawn", Arguments: map[string]any{"name": "Lamp"}},
+				{Tool: "label", Arguments: map[string]any{"target": "${a.actor_path}"}},
+			}},
+			wantStatus: []string{"error", "ok", "error"},
+			wantError:  `cannot spawn "bad"`,
+		},
+		{
+			name: "unknown tool",
+			input: Input{Steps: []Step{
+				{Tool: "nope"},
+			}},
+			wantStatus: []string{"error"},
+			wantError:  "nope",
+		},
+		{
+			name: "reference to a later step",
+			input: Input{Steps: []Step{
+				{Tool: "label", Arguments: map[string]any{"target": "${1.actor_path}"}},
+				{Tool: "spawn", Arguments: map[string]any{"name": "Lamp"}},
+			}},
+			wantStatus: []string{"error", "skipped"},
+			wantError:  "has not run yet",
+		},
+		{
+			name: "missing field",
+			input: Input{Steps: []Step{
+				{Tool: "spawn", Arguments: map[string]any{"name": "Lamp"}},
+				{Tool: "label", Arguments: map[string]any{"target": "${0.actor_name}"}},
+			}},
+			wantStatus: []string{"ok", "error"},
+			wantError:  `no field "actor_name"`,
+		},
+		{
+			name: "index out of range",
+			input: Input{Steps: []Step{
+				{Tool: "spawn", Arguments: map[string]any{"name": "Lamp"}},
+				{Tool: "label", Arguments: map[string]any{"target": "x ${0.tags.5}"}},
+			}},
+			wantStatus: []string{"ok", "error"},
+			wantError:  "out of range",
+		},
+	}
+	h := newTestHandler()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, out, err := h.Batch(context.Background(), nil, tt.input)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var status []string
+			firstErr := ""
+			for _, s := range out.Steps {
+				status = append(status, s.Status)
+				if s.Error != "" && firstErr == "" {
+					firstErr = s.Error
+				}
+			}
+			if !reflect.DeepEqual(status, tt.wantStatus) {
+				t.Errorf("status = %v, want %v", status, tt.wantStatus)
+			}
+			if !strings.Contains(firstErr, tt.wantError) {
+				t.Errorf("error = %q, want it to contain %q", firstErr, tt.wantError)
+			}
+		})
+	}
+}
+
+func TestBatchErrorCodes(t *testing.T) {
+	h := newTestHandler()
+	h.Server.AddReceivingMiddleware(toolerr.Middleware)
+	mcp.AddTool(h.Server, &mcp.Tool{Name: "find"}, func(context.Context, *mcp.CallToolRequest, struct{}) (*mcp.CallToolResult, struct{}, error) {
+		return nil, struct{}{}, toolerr.New(toolerr.NotFound, "Actor not found: Lamp")
+	})
+
+	_, out, err := h.Batch(context.Background(), nil, Input{OnError: "continue", Steps: []Step{
+		{Tool: "find"},
+		{Tool: "label", Arguments: map[string]any{"target": "${9.x}"}},
+		{Tool: "label", Arguments: map[string]any{"target": "x", "colour": "red"}},
+		{Tool: "no_such_tool"},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, want := range map[int]string{2: "VALIDATION", 3: "NOT_FOUND"} {
+		if s := out.Steps[i]; s.Code != want {
+			t.Errorf("step %d = %+v, want code %s", i, s, want)
+		}
+	}
+	if s := out.Steps[0]; s.Code != "NOT_FOUND" || s.Error != "Actor not found: Lamp" {
+		t.Errorf("step 0 = %+v, want code NOT_FOUND and the plain message", s)
+	}
+	if s := out.Steps[1]; s.Code != "VALIDATION" {
+		t.Errorf("step 1 code = %q, want VALIDATION", s.Code)
+	}
+}
+
+func TestBatchValidation(t *testing.T) {
+	tests := []struct {
+		name  string
+		input Input
+		want  string
+	}{
+		{"no steps", Input{}, "steps is required"},
+		{"bad on_error", Input{OnError: "retry", Steps: []Step{{Tool: "spawn"}}}, "invalid on_error"},
+		{"missing tool", Input{Steps: []Step{{ID: "a"}}}, "tool is required"},
+		{"nested", Input{Steps: []Step{{Tool: "batch"}}}, "cannot be nested"},
+		{"numeric id", Input{Steps: []Step{{ID: "1", Tool: "spawn"}}}, "must not be a number"},
+		{"duplicate id", Input{Steps: []Step{{ID: "a", Tool: "spawn"}, {ID: "a", Tool: "spawn"}}}, "duplicate id"},
+	}
+	h := newTestHandler()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := h.Batch(context.Background(), nil, tt.input)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("err = %v, want it to contain %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestBatchInstance(t *testing.T) {
+	h := newTestHandler()
+	_, out, err := h.Batch(context.Background(), nil, Input{Instance: "second", Steps: []Step{
+		{Tool: "spawn", Arguments: map[string]any{"name": "A"}},
+		{Tool: "spawn", Arguments: map[string]any{"name": "B", "instance": "third"}},
+		// label takes no instance, so it must not get the batch's.
+		{Tool: "label", Arguments: map[string]any{"target": "${0.actor_path}"}},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out.Failed != 0 {
+		t.Fatalf("steps failed: %+v", out.Steps)
+	}
+	for i, want := range []string{"second", "third"} {
+		if got := out.Steps[i].Result.(map[string]any)["instance"]; got != want {
+			t.Errorf("step %d instance = %v, want %s", i, got, want)
+		}
+	}
+}
@@ -3,19 +3,27 @@
 // Package config handles environment configuration, UE path detection,
 // and project root discovery for the mcp-unreal server.
 //
-// All configuration is read from environment variables with sensible
-// platform-dependent defaults. See IMPLEMENTATION.md §6 and CLAUDE.md
-// Environment Variables table for the full list.
+// Configuration is read from command-line overrides, environment
+// variables, and mcp-unreal.toml or .json config files with per-project
+// profiles, with sensible platform-dependent defaults. See
+// IMPLEMENTATION.md §6 and CLAUDE.md Environment Variables table for the
+// full list, and file.go for the config files.
 package config
 
 import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
 	"fmt"
 	"log/slog"
+	"net"
 	"os"
 	"path/filepath"
+	"regexp"
 	"runtime"
 	"strconv"
 	"strings"
+	"time"
 )
 
 // Config holds all runtime configuration for the MCP server.
@@ -29,65 +37,336 @@ type Config struct {
 	// UProjectFile is the full path to the .uproject file, if found.
 	UProjectFile string
 
+	// EngineVersion is the project's engine version (e.g. "5.7"), from
+	// MCP_UNREAL_ENGINE_VERSION or the .uproject EngineAssociation. Empty
+	// when unknown, e.g. for source builds associated by GUID.
+	EngineVersion string
+
 	// RCAPIPort is the UE Remote Control API HTTP port (default 30010).
 	RCAPIPort int
 
 	// PluginPort is the MCPUnreal editor plugin HTTP port (default 8090).
 	PluginPort int
 
+	// RCAPIHost and PluginHost are the editor hosts (default 127.0.0.1).
+	// A non-loopback host is refused unless AllowRemoteEditor is set.
+	RCAPIHost  string
+	PluginHost string
+
+	// RCAPIScheme and PluginScheme are "http" (default) or "https".
+	RCAPIScheme  string
+	PluginScheme string
+
+	// EditorToken, if set, is sent to the plugin as a bearer token. The
+	// plugin rejects requests without it when configured with the same
+	// token (MCP_UNREAL_EDITOR_TOKEN in the editor's environment).
+	EditorToken string
+
+	// EditorCAFile is a PEM file of extra CA certificates trusted for
+	// https editor endpoints, e.g. a self-signed proxy in front of a VM.
+	EditorCAFile string
+
+	// AllowRemoteEditor permits editor hosts other than loopback.
+	AllowRemoteEditor bool
+
+	// EditorInstances lists extra named editors on the configured hosts
+	// as name=pluginPort[/rcAPIPort] entries separated by commas.
+	EditorInstances string
+
+	// DiscoverPorts is a plugin port range, e.g. "8090-8099", scanned
+	// for further editor instances. Empty disables discovery.
+	DiscoverPorts string
+
 	// LogLevel is the slog level for the server.
 	LogLevel slog.Level
 
 	// DocsIndexPath is the path to the bleve documentation index.
 	DocsIndexPath string
+
+	// DocsManifestPath is an optional JSON manifest of extra doc sources
+	// (directories, single files, doc packs) for --build-index.
+	DocsManifestPath string
+
+	// EditorRecordPath, if set, is a cassette file that every editor HTTP
+	// request and response is appended to.
+	EditorRecordPath string
+
+	// EditorReplayPath, if set, is a cassette file that editor requests
+	// are answered from instead of the network.
+	EditorReplayPath string
+
+	// StaticTools registers every editor tool at startup regardless of
+	// editor availability, instead of adding and removing them as the
+	// editor and plugin come and go.
+	StaticTools bool
+
+	// PolicyPath is the tool policy file. Empty means
+	// mcp-unreal.policy.json in the project root, if present.
+	PolicyPath string
+
+	// ReadOnly refuses every tool call that is not classed as a read,
+	// whatever the policy file says.
+	ReadOnly bool
+
+	// ExtraRoots are directories, besides the project root and the engine
+	// directory, that file paths in tool arguments may point into.
+	ExtraRoots []string
+
+	// ResponseTokenBudget caps, in estimated tokens, the items a paged
+	// list tool returns in one response when the call sets no max_tokens.
+	// Zero or less leaves pages unbounded.
+	ResponseTokenBudget int
+
+	// TraceExporter selects where OpenTelemetry spans go: "otlp" for an
+	// OTLP/HTTP collector (OTEL_EXPORTER_OTLP_ENDPOINT, default
+	// localhost:4318), "file" for JSON lines in TraceFile, or empty to
+	// turn tracing off.
+	TraceExporter string
+
+	// TraceFile is the span file for the "file" exporter. It defaults to
+	// Saved/mcp-unreal/traces.jsonl in the project root.
+	TraceFile string
+
+	// MetricsPort is the localhost port serving Prometheus metrics at
+	// /metrics. Zero turns the metrics endpoint off.
+	MetricsPort int
+
+	// AuditDir is where tool calls are logged. It defaults to
+	// Saved/mcp-unreal/audit in the project root; empty turns the audit
+	// log off.
+	AuditDir string
+
+	// SessionDir is where each MCP session's tool calls and results are
+	// recorded for replay_session. It defaults to Saved/mcp-unreal/sessions
+	// in the project root; empty turns recording off.
+	SessionDir string
+
+	// WorkflowDir holds the project's named workflows for run_workflow.
+	// It defaults to mcp-unreal/workflows in the project root.
+	WorkflowDir string
+
+	// LogFormat is the server log format, "text" (default) or "json".
+	LogFormat string
+
+	// ToolTimeouts bounds calls to the named tools. The deadline replaces
+	// a tool's own default timeout, e.g. 30 minutes for build_project.
+	ToolTimeouts map[string]time.Duration
+
+	// Files are the config files read, and Profile the profile selected
+	// from them, if any.
+	Files   []string
+	Profile string
+
+	// origins records where each setting came from, by environment
+	// variable name, for WriteTOML; errs holds the problems found while
+	// loading, for CheckSettings.
+	origins map[string]string
+	errs    []error
 }
 
-// Load reads configuration from environment variables and applies
-// platform-dependent defaults. It does not fail on missing values —
-// tools check availability at call time (graceful degradation per
-// IMPLEMENTATION.md §10).
+// Load reads configuration from config files and environment variables
+// and applies platform-dependent defaults. It does not fail on missing
+// values — tools check availability at call time (graceful degradation
+// per IMPLEMENTATION.md §10); CheckSettings reports invalid files and
+// values.
 func Load() *Config {
+	return LoadWith(nil)
+}
+
+// LoadWith is Load with command-line overrides, keyed by the environment
+// variable they replace, e.g. "MCP_UNREAL_POLICY". Overrides take
+// precedence over the environment and config files.
+func LoadWith(flags map[string]string) *Config {
+	src := newSources(flags)
+
+	// Project root: explicit setting or auto-detect from cwd. It can be a
+	// .uproject file path or a project directory, and is needed to find
+	// the project's config file.
+	project := src.get(projectEnv)
+	root, uproject := projectRoot(project)
+	if project == "" {
+		src.origins[projectEnv] = "detected from the working directory"
+	}
+	src.addProjectFile(root, projectName(uproject), project)
+
 	cfg := &Config{
-		UEEditorPath:  envOrDefault("UE_EDITOR_PATH", defaultUEEditorPath()),
-		RCAPIPort:     envIntOrDefault("RC_API_PORT", 30010),
-		PluginPort:    envIntOrDefault("PLUGIN_PORT", 8090),
-		LogLevel:      parseLogLevel(envOrDefault("MCP_UNREAL_LOG_LEVEL", "info")),
-		DocsIndexPath: envOrDefault("MCP_UNREAL_DOCS_INDEX", "./docs/index.bleve"),
-	}
-
-	// Project root: explicit env var or auto-detect from cwd.
-	// MCP_UNREAL_PROJECT can be a .uproject file path or a project directory.
-	projectEnv := os.Getenv("MCP_UNREAL_PROJECT")
-	if projectEnv != "" {
-		if strings.HasSuffix(projectEnv, ".uproject") {
-			// Given a .uproject file path directly.
-			if _, err := os.Stat(projectEnv); err == nil {
-				cfg.UProjectFile = projectEnv
-				cfg.ProjectRoot = filepath.Dir(projectEnv)
-			} else {
-				cfg.ProjectRoot = filepath.Dir(projectEnv)
-			}
-		} else {
-			cfg.ProjectRoot = projectEnv
-			cfg.UProjectFile = findUProjectFile(projectEnv)
-		}
-	} else {
-		root, uproject := detectProjectRoot()
-		cfg.ProjectRoot = root
-		cfg.UProjectFile = uproject
+		ProjectRoot:  root,
+		UProjectFile: uproject,
+
+		UEEditorPath:  src.orDefault("UE_EDITOR_PATH", defaultUEEditorPath()),
+		RCAPIPort:     src.intOrDefault("RC_API_PORT", 30010),
+		PluginPort:    src.intOrDefault("PLUGIN_PORT", 8090),
+		LogLevel:      parseLogLevel(src.orDefault("MCP_UNREAL_LOG_LEVEL", "info")),
+		LogFormat:     strings.ToLower(src.orDefault("MCP_UNREAL_LOG_FORMAT", "text")),
+		DocsIndexPath: src.orDefault("MCP_UNREAL_DOCS_INDEX", "./docs/index.bleve"),
+
+		DocsManifestPath: src.get("MCP_UNREAL_DOCS_MANIFEST"),
+		EditorRecordPath: src.get("MCP_UNREAL_EDITOR_RECORD"),
+		EditorReplayPath: src.get("MCP_UNREAL_EDITOR_REPLAY"),
+		StaticTools:      src.boolean("MCP_UNREAL_STATIC_TOOLS"),
+		PolicyPath:       src.get("MCP_UNREAL_POLICY"),
+		ReadOnly:         src.boolean("MCP_UNREAL_READ_ONLY"),
+		ExtraRoots:       filepath.SplitList(src.get("MCP_UNREAL_EXTRA_ROOTS")),
+
+		ResponseTokenBudget: src.intOrDefault("MCP_UNREAL_RESPONSE_TOKEN_BUDGET", 8000),
+
+		TraceExporter: strings.ToLower(src.get("MCP_UNREAL_TRACE_EXPORTER")),
+		TraceFile:     src.get("MCP_UNREAL_TRACE_FILE"),
+		MetricsPort:   src.intOrDefault("MCP_UNREAL_METRICS_PORT", 0),
+
+		RCAPIHost:         src.orDefault("RC_API_HOST", defaultEditorHost),
+		PluginHost:        src.orDefault("PLUGIN_HOST", defaultEditorHost),
+		RCAPIScheme:       src.orDefault("RC_API_SCHEME", "http"),
+		PluginScheme:      src.orDefault("PLUGIN_SCHEME", "http"),
+		EditorToken:       src.get("MCP_UNREAL_EDITOR_TOKEN"),
+		EditorCAFile:      src.get("MCP_UNREAL_EDITOR_CA_FILE"),
+		AllowRemoteEditor: src.boolean("MCP_UNREAL_ALLOW_REMOTE_EDITOR"),
+		EditorInstances:   src.get("MCP_UNREAL_EDITOR_INSTANCES"),
+		DiscoverPorts:     src.get("MCP_UNREAL_DISCOVER_PORTS"),
+	}
+
+	var err error
+	if cfg.ToolTimeouts, err = parseToolTimeouts(src.get("MCP_UNREAL_TOOL_TIMEOUTS")); err != nil {
+		src.errs = append(src.errs, err)
+	}
+
+	cfg.EngineVersion = src.orDefault("MCP_UNREAL_ENGINE_VERSION", engineAssociation(cfg.UProjectFile))
+
+	// Audit log: explicit directory, "off", or the project's Saved folder.
+	switch dir := src.get("MCP_UNREAL_AUDIT_DIR"); {
+	case dir == "off":
+	case dir != "":
+		cfg.AuditDir = dir
+	case cfg.ProjectRoot != "":
+		cfg.AuditDir = filepath.Join(cfg.ProjectRoot, "Saved", "mcp-unreal", "audit")
+	}
+
+	// Session recordings: explicit directory, "off", or the project's
+	// Saved folder.
+	switch dir := src.get("MCP_UNREAL_SESSION_DIR"); {
+	case dir == "off":
+	case dir != "":
+		cfg.SessionDir = dir
+	case cfg.ProjectRoot != "":
+		cfg.SessionDir = filepath.Join(cfg.ProjectRoot, "Saved", "mcp-unreal", "sessions")
 	}
 
+	cfg.WorkflowDir = src.get("MCP_UNREAL_WORKFLOW_DIR")
+	if cfg.WorkflowDir == "" && cfg.ProjectRoot != "" {
+		cfg.WorkflowDir = filepath.Join(cfg.ProjectRoot, "mcp-unreal", "workflows")
+	}
+
+	if cfg.TraceFile == "" && cfg.ProjectRoot != "" {
+		cfg.TraceFile = filepath.Join(cfg.ProjectRoot, "Saved", "mcp-unreal", "traces.jsonl")
+	}
+
+	cfg.Files = src.files()
+	cfg.Profile = src.profile
+	cfg.origins = src.origins
+	cfg.errs = src.errs
 	return cfg
 }
 
+// projectName returns the project's name, the base name of its .uproject
+// file, which selects the profile of the same name by default.
+func projectName(uproject string) string {
+	if uproject == "" {
+		return ""
+	}
+	return strings.TrimSuffix(filepath.Base(uproject), ".uproject")
+}
+
+// projectRoot returns the project root and .uproject file for a project
+// setting, or detects them from the working directory if it is empty.
+func projectRoot(project string) (string, string) {
+	switch {
+	case project == "":
+		return detectProjectRoot()
+	case strings.HasSuffix(project, ".uproject"):
+		// Given a .uproject file path directly.
+		if _, err := os.Stat(project); err == nil {
+			return filepath.Dir(project), project
+		}
+		return filepath.Dir(project), ""
+	default:
+		return project, findUProjectFile(project)
+	}
+}
+
+// defaultEditorHost keeps editor traffic on the local machine unless the
+// operator opts in (CLAUDE.md Security §3).
+const defaultEditorHost = "127.0.0.1"
+
 // RCAPIURL returns the base URL for the UE Remote Control API.
 func (c *Config) RCAPIURL() string {
-	return fmt.Sprintf("http://127.0.0.1:%d", c.RCAPIPort)
+	return editorURL(c.RCAPIScheme, c.RCAPIHost, c.RCAPIPort)
 }
 
 // PluginURL returns the base URL for the MCPUnreal editor plugin.
 func (c *Config) PluginURL() string {
-	return fmt.Sprintf("http://127.0.0.1:%d", c.PluginPort)
+	return editorURL(c.PluginScheme, c.PluginHost, c.PluginPort)
+}
+
+func editorURL(scheme, host string, port int) string {
+	if scheme == "" {
+		scheme = "http"
+	}
+	if host == "" {
+		host = defaultEditorHost
+	}
+	return scheme + "://" + net.JoinHostPort(host, strconv.Itoa(port))
+}
+
+// CheckEditorEndpoints validates the editor host settings: schemes must
+// be http or https, hosts must be loopback unless AllowRemoteEditor is
+// set, and the CA file, if any, must hold at least one certificate.
+func (c *Config) CheckEditorEndpoints() error {
+	for _, ep := range []struct{ name, scheme, host string }{
+		{"RC API", c.RCAPIScheme, c.RCAPIHost},
+		{"plugin", c.PluginScheme, c.PluginHost},
+	} {
+		if ep.scheme != "" && ep.scheme != "http" && ep.scheme != "https" {
+			return fmt.Errorf("%s scheme %q: must be http or https", ep.name, ep.scheme)
+		}
+		if !c.AllowRemoteEditor && !IsLoopbackHost(ep.host) {
+			return fmt.Errorf("%s host %q is not loopback; set MCP_UNREAL_ALLOW_REMOTE_EDITOR=1 or pass --allow-remote-editor to connect to a remote editor", ep.name, ep.host)
+		}
+	}
+	if _, err := c.EditorTLSConfig(); err != nil {
+		return err
+	}
+	return nil
+}
+
+// IsLoopbackHost reports whether host is empty (the default), localhost,
+// or a loopback IP address.
+func IsLoopbackHost(host string) bool {
+	if host == "" || strings.EqualFold(host, "localhost") {
+		return true
+	}
+	ip := net.ParseIP(strings.Trim(host, "[]"))
+	return ip != nil && ip.IsLoopback()
+}
+
+// EditorTLSConfig returns the TLS settings for https editor endpoints:
+// the system roots plus EditorCAFile. It returns nil when no CA file is
+// configured, which means the default TLS settings.
+func (c *Config) EditorTLSConfig() (*tls.Config, error) {
+	if c.EditorCAFile == "" {
+		return nil, nil
+	}
+	pem, err := os.ReadFile(c.EditorCAFile) //nolint:gosec // operator-supplied CA path
+	if err != nil {
+		return nil, fmt.Errorf("reading editor CA file: %w", err)
+	}
+	pool, err := x509.SystemCertPool()
+	if err != nil || pool == nil {
+		pool = x509.NewCertPool()
+	}
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("editor CA file %s: no PEM certificates found", c.EditorCAFile)
+	}
+	return &tls.Config{RootCAs: pool, MinVersion: tls.VersionTLS12}, nil
 }
 
 // defaultUEEditorPath returns the platform-dependent default path to
@@ -145,23 +424,31 @@ func findUProjectFile(dir string) string {
 	return ""
 }
 
-func envOrDefault(key, fallback string) string {
-	if v := os.Getenv(key); v != "" {
-		return v
-	}
-	return fallback
-}
+// engineVersionRe matches the leading major.minor of an EngineAssociation
+// such as "5.7" or "5.5.4".
+var engineVersionRe = regexp.MustCompile(`^(\d+\.\d+)`)
 
-func envIntOrDefault(key string, fallback int) int {
-	v := os.Getenv(key)
-	if v == "" {
-		return fallback
+// engineAssociation reads the EngineAssociation field of a .uproject file
+// and returns its major.minor version, or "" if the file is missing or the
+// association is not a version (source builds use a GUID).
+func engineAssociation(uprojectPath string) string {
+	if uprojectPath == "" {
+		return ""
 	}
-	n, err := strconv.Atoi(v)
+	data, err := os.ReadFile(uprojectPath) //nolint:gosec // path from project detection
 	if err != nil {
-		return fallback
+		return ""
 	}
-	return n
+	var proj struct {
+		EngineAssociation string `json:"EngineAssociation"`
+	}
+	if err := json.Unmarshal(data, &proj); err != nil {
+		return ""
+	}
+	if m := engineVersionRe.FindStringSubmatch(proj.EngineAssociation); m != nil {
+		return m[1]
+	}
+	return ""
 }
 
 func parseLogLevel(s string) slog.Level {
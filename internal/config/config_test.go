@@ -7,6 +7,7 @@ import (
 	"os"
 	"path/filepath"
 	"runtime"
+	"strings"
 	"testing"
 )
 
@@ -32,7 +33,12 @@ func TestDefaultUEEditorPath(t *testing.T) {
 	}
 }
 
-func TestEnvOrDefault(t *testing.T) {
+// envSources returns sources without flags or config files.
+func envSources() *sources {
+	return &sources{origins: make(map[string]string)}
+}
+
+func TestSourcesOrDefault(t *testing.T) {
 	tests := []struct {
 		name     string
 		key      string
@@ -49,15 +55,15 @@ func TestEnvOrDefault(t *testing.T) {
 			if tt.setVal != "" {
 				t.Setenv(tt.key, tt.setVal)
 			}
-			got := envOrDefault(tt.key, tt.fallback)
+			got := envSources().orDefault(tt.key, tt.fallback)
 			if got != tt.want {
-				t.Errorf("envOrDefault(%q) = %q, want %q", tt.key, got, tt.want)
+				t.Errorf("orDefault(%q) = %q, want %q", tt.key, got, tt.want)
 			}
 		})
 	}
 }
 
-func TestEnvIntOrDefault(t *testing.T) {
+func TestSourcesIntOrDefault(t *testing.T) {
 	tests := []struct {
 		name     string
 		key      string
@@ -75,9 +81,34 @@ func TestEnvIntOrDefault(t *testing.T) {
 			if tt.setVal != "" {
 				t.Setenv(tt.key, tt.setVal)
 			}
-			got := envIntOrDefault(tt.key, tt.fallback)
+			got := envSources().intOrDefault(tt.key, tt.fallback)
 			if got != tt.want {
-				t.Errorf("envIntOrDefault(%q) = %d, want %d", tt.key, got, tt.want)
+				t.Errorf("intOrDefault(%q) = %d, want %d", tt.key, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSourcesBoolean(t *testing.T) {
+	tests := []struct {
+		setVal string
+		want   bool
+	}{
+		{"", false},
+		{"1", true},
+		{"true", true},
+		{"YES", true},
+		{"on", true},
+		{"0", false},
+		{"false", false},
+		{"nope", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.setVal, func(t *testing.T) {
+			t.Setenv("TEST_BOOL", tt.setVal)
+			if got := envSources().boolean("TEST_BOOL"); got != tt.want {
+				t.Errorf("boolean(%q) = %v, want %v", tt.setVal, got, tt.want)
 			}
 		})
 	}
@@ -128,12 +159,132 @@ func TestFindUProjectFile(t *testing.T) {
 	}
 }
 
+func TestEngineAssociation(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{"launcher build", `{"EngineAssociation":"5.7"}`, "5.7"},
+		{"patch version", `{"EngineAssociation":"5.5.4"}`, "5.5"},
+		{"source build GUID", `{"EngineAssociation":"{A1B2C3D4-0000-0000-0000-000000000000}"}`, ""},
+		{"missing field", `{"FileVersion":3}`, ""},
+		{"invalid JSON", `not json`, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "P.uproject")
+			if err := os.WriteFile(path, []byte(tt.content), 0600); err != nil {
+				t.Fatal(err)
+			}
+			if got := engineAssociation(path); got != tt.want {
+				t.Errorf("engineAssociation() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	if got := engineAssociation(""); got != "" {
+		t.Errorf("engineAssociation(\"\") = %q, want empty", got)
+	}
+}
+
+func TestLoadEngineVersion(t *testing.T) {
+	dir := t.TempDir()
+	uproject := filepath.Join(dir, "MyProject.uproject")
+	if err := os.WriteFile(uproject, []byte(`{"EngineAssociation":"5.5"}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("MCP_UNREAL_PROJECT", uproject)
+
+	if got := Load().EngineVersion; got != "5.5" {
+		t.Errorf("EngineVersion = %q, want 5.5 from .uproject", got)
+	}
+
+	t.Setenv("MCP_UNREAL_ENGINE_VERSION", "5.7")
+	if got := Load().EngineVersion; got != "5.7" {
+		t.Errorf("EngineVersion = %q, want 5.7 from env override", got)
+	}
+}
+
+func TestLoadAuditDir(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("MCP_UNREAL_PROJECT", dir)
+
+	tests := []struct {
+		env  string
+		want string
+	}{
+		{"", filepath.Join(dir, "Saved", "mcp-unreal", "audit")},
+		{"/var/log/mcp-unreal", "/var/log/mcp-unreal"},
+		{"off", ""},
+	}
+	for _, tt := range tests {
+		t.Setenv("MCP_UNREAL_AUDIT_DIR", tt.env)
+		if got := Load().AuditDir; got != tt.want {
+			t.Errorf("MCP_UNREAL_AUDIT_DIR=%q: AuditDir = %q, want %q", tt.env, got, tt.want)
+		}
+	}
+}
+
+func TestLoadSessionDir(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("MCP_UNREAL_PROJECT", dir)
+
+	tests := []struct {
+		env  string
+		want string
+	}{
+		{"", filepath.Join(dir, "Saved", "mcp-unreal", "sessions")},
+		{"/tmp/sessions", "/tmp/sessions"},
+		{"off", ""},
+	}
+	for _, tt := range tests {
+		t.Setenv("MCP_UNREAL_SESSION_DIR", tt.env)
+		if got := Load().SessionDir; got != tt.want {
+			t.Errorf("MCP_UNREAL_SESSION_DIR=%q: SessionDir = %q, want %q", tt.env, got, tt.want)
+		}
+	}
+}
+
+func TestLoadWorkflowDir(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("MCP_UNREAL_PROJECT", dir)
+
+	t.Setenv("MCP_UNREAL_WORKFLOW_DIR", "")
+	if got, want := Load().WorkflowDir, filepath.Join(dir, "mcp-unreal", "workflows"); got != want {
+		t.Errorf("WorkflowDir = %q, want %q", got, want)
+	}
+	t.Setenv("MCP_UNREAL_WORKFLOW_DIR", "/tmp/workflows")
+	if got := Load().WorkflowDir; got != "/tmp/workflows" {
+		t.Errorf("WorkflowDir = %q, want /tmp/workflows", got)
+	}
+}
+
+func TestLoadTelemetry(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("MCP_UNREAL_PROJECT", dir)
+	t.Setenv("MCP_UNREAL_TRACE_EXPORTER", "OTLP")
+	t.Setenv("MCP_UNREAL_METRICS_PORT", "9464")
+
+	cfg := Load()
+	if cfg.TraceExporter != "otlp" {
+		t.Errorf("TraceExporter = %q, want otlp", cfg.TraceExporter)
+	}
+	if want := filepath.Join(dir, "Saved", "mcp-unreal", "traces.jsonl"); cfg.TraceFile != want {
+		t.Errorf("TraceFile = %q, want %q", cfg.TraceFile, want)
+	}
+	if cfg.MetricsPort != 9464 {
+		t.Errorf("MetricsPort = %d, want 9464", cfg.MetricsPort)
+	}
+}
+
 func TestLoadRespectsEnvVars(t *testing.T) {
 	t.Setenv("UE_EDITOR_PATH", "/custom/editor")
 	t.Setenv("RC_API_PORT", "9999")
 	t.Setenv("PLUGIN_PORT", "7777")
 	t.Setenv("MCP_UNREAL_LOG_LEVEL", "debug")
 	t.Setenv("MCP_UNREAL_DOCS_INDEX", "/custom/index.bleve")
+	t.Setenv("MCP_UNREAL_RESPONSE_TOKEN_BUDGET", "2000")
 
 	cfg := Load()
 
@@ -152,6 +303,9 @@ func TestLoadRespectsEnvVars(t *testing.T) {
 	if cfg.DocsIndexPath != "/custom/index.bleve" {
 		t.Errorf("DocsIndexPath = %q, want /custom/index.bleve", cfg.DocsIndexPath)
 	}
+	if cfg.ResponseTokenBudget != 2000 {
+		t.Errorf("ResponseTokenBudget = %d, want 2000", cfg.ResponseTokenBudget)
+	}
 }
 
 func TestConfigURLs(t *testing.T) {
@@ -163,4 +317,54 @@ func TestConfigURLs(t *testing.T) {
 	if got := cfg.PluginURL(); got != "http://127.0.0.1:8090" {
 		t.Errorf("PluginURL() = %q", got)
 	}
+
+	cfg = &Config{RCAPIPort: 30010, RCAPIHost: "::1", PluginPort: 443, PluginHost: "ue-vm.lan", PluginScheme: "https"}
+	if got := cfg.RCAPIURL(); got != "http://[::1]:30010" {
+		t.Errorf("RCAPIURL() = %q", got)
+	}
+	if got := cfg.PluginURL(); got != "https://ue-vm.lan:443" {
+		t.Errorf("PluginURL() = %q", got)
+	}
+}
+
+func TestCheckEditorEndpoints(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     Config
+		wantErr string
+	}{
+		{"defaults", Config{}, ""},
+		{"localhost", Config{RCAPIHost: "localhost", PluginHost: "127.0.0.2"}, ""},
+		{"ipv6 loopback", Config{PluginHost: "::1"}, ""},
+		{"remote refused", Config{PluginHost: "192.168.1.20"}, "not loopback"},
+		{"remote hostname refused", Config{RCAPIHost: "ue-vm.lan"}, "not loopback"},
+		{"remote allowed", Config{PluginHost: "192.168.1.20", RCAPIHost: "ue-vm.lan", AllowRemoteEditor: true}, ""},
+		{"bad scheme", Config{PluginScheme: "ftp"}, "must be http or https"},
+		{"missing CA", Config{EditorCAFile: "/nonexistent/ca.pem"}, "reading editor CA file"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.cfg.CheckEditorEndpoints()
+			if tt.wantErr == "" && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Errorf("error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestEditorTLSConfig(t *testing.T) {
+	if tlsConfig, err := (&Config{}).EditorTLSConfig(); tlsConfig != nil || err != nil {
+		t.Errorf("no CA file: got (%v, %v), want defaults", tlsConfig, err)
+	}
+
+	notPEM := filepath.Join(t.TempDir(), "ca.pem")
+	if err := os.WriteFile(notPEM, []byte("not a certificate"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := (&Config{EditorCAFile: notPEM}).EditorTLSConfig(); err == nil || !strings.Contains(err.Error(), "no PEM certificates") {
+		t.Errorf("expected PEM error, got %v", err)
+	}
 }
@@ -0,0 +1,618 @@
+// Copyright (c) mcp-unreal project contributors. Apache-2.0 license.
+
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/BurntSushi/toml"
+)
+
+// Config files are named mcp-unreal.toml or mcp-unreal.json and are read
+// from the user config directory (e.g. ~/.config/mcp-unreal on Linux) and
+// the project root. Each holds top-level settings and [profiles.<name>]
+// tables that override them. Settings are resolved, highest first, from:
+//
+//  1. command-line flags
+//  2. environment variables
+//  3. the selected profile of the project file
+//  4. the selected profile of the user file
+//  5. the top level of the project file
+//  6. the top level of the user file
+//  7. defaults
+//
+// The profile is MCP_UNREAL_PROFILE (--profile), else the "profile" key of
+// the project file, then of the user file, else a profile named after the
+// .uproject file, if one is defined. MCP_UNREAL_CONFIG (--config) names a
+// file to use instead of the project file.
+const (
+	// fileBaseName is the config file name without extension.
+	fileBaseName = "mcp-unreal"
+
+	configEnv  = "MCP_UNREAL_CONFIG"
+	profileEnv = "MCP_UNREAL_PROFILE"
+	projectEnv = "MCP_UNREAL_PROJECT"
+)
+
+// fileExtensions are the config file extensions, in lookup order.
+var fileExtensions = []string{".toml", ".json"}
+
+// kind is how a setting's file value is read.
+type kind int
+
+const (
+	kindString kind = iota
+	// kindPath is a string resolved against the file's directory.
+	kindPath
+	kindInt
+	kindBool
+	// kindPathList is an array of paths, joined like PATH.
+	kindPathList
+	// kindDurations is a table of durations, joined as name=duration
+	// pairs separated by commas.
+	kindDurations
+)
+
+// setting maps a config file key to the environment variable it stands
+// in for.
+type setting struct {
+	key  string
+	env  string
+	kind kind
+	// secret settings are redacted by WriteTOML.
+	secret bool
+	// userOnly settings cannot be set in a project's config file, which
+	// usually comes with someone else's project: they pick the editor
+	// the token is sent to, or widen what tools may touch.
+	userOnly bool
+	// value returns the effective value for WriteTOML.
+	value func(c *Config) any
+}
+
+// settings lists every config file key, in WriteTOML order.
+var settings = []setting{
+	{key: "project", env: projectEnv, kind: kindPath, userOnly: true, value: func(c *Config) any { return c.ProjectRoot }},
+	{key: "ue_editor_path", env: "UE_EDITOR_PATH", kind: kindPath, value: func(c *Config) any { return c.UEEditorPath }},
+	{key: "engine_version", env: "MCP_UNREAL_ENGINE_VERSION", value: func(c *Config) any { return c.EngineVersion }},
+	{key: "rc_api_host", env: "RC_API_HOST", value: func(c *Config) any { return c.RCAPIHost }},
+	{key: "rc_api_port", env: "RC_API_PORT", kind: kindInt, value: func(c *Config) any { return c.RCAPIPort }},
+	{key: "rc_api_scheme", env: "RC_API_SCHEME", value: func(c *Config) any { return c.RCAPIScheme }},
+	{key: "plugin_host", env: "PLUGIN_HOST", value: func(c *Config) any { return c.PluginHost }},
+	{key: "plugin_port", env: "PLUGIN_PORT", kind: kindInt, value: func(c *Config) any { return c.PluginPort }},
+	{key: "plugin_scheme", env: "PLUGIN_SCHEME", value: func(c *Config) any { return c.PluginScheme }},
+	{key: "editor_token", env: "MCP_UNREAL_EDITOR_TOKEN", secret: true, userOnly: true, value: func(c *Config) any { return c.EditorToken }},
+	{key: "editor_ca_file", env: "MCP_UNREAL_EDITOR_CA_FILE", kind: kindPath, userOnly: true, value: func(c *Config) any { return c.EditorCAFile }},
+	{key: "allow_remote_editor", env: "MCP_UNREAL_ALLOW_REMOTE_EDITOR", kind: kindBool, userOnly: true, value: func(c *Config) any { return c.AllowRemoteEditor }},
+	{key: "editor_instances", env: "MCP_UNREAL_EDITOR_INSTANCES", value: func(c *Config) any { return c.EditorInstances }},
+	{key: "discover_ports", env: "MCP_UNREAL_DISCOVER_PORTS", value: func(c *Config) any { return c.DiscoverPorts }},
+	{key: "log_level", env: "MCP_UNREAL_LOG_LEVEL", value: func(c *Config) any { return strings.ToLower(c.LogLevel.String()) }},
+	{key: "log_format", env: "MCP_UNREAL_LOG_FORMAT", value: func(c *Config) any { return c.LogFormat }},
+	{key: "docs_index", env: "MCP_UNREAL_DOCS_INDEX", kind: kindPath, value: func(c *Config) any { return c.DocsIndexPath }},
+	{key: "docs_manifest", env: "MCP_UNREAL_DOCS_MANIFEST", kind: kindPath, value: func(c *Config) any { return c.DocsManifestPath }},
+	{key: "editor_record", env: "MCP_UNREAL_EDITOR_RECORD", kind: kindPath, value: func(c *Config) any { return c.EditorRecordPath }},
+	{key: "editor_replay", env: "MCP_UNREAL_EDITOR_REPLAY", kind: kindPath, value: func(c *Config) any { return c.EditorReplayPath }},
+	{key: "static_tools", env: "MCP_UNREAL_STATIC_TOOLS", kind: kindBool, value: func(c *Config) any { return c.StaticTools }},
+	{key: "policy", env: "MCP_UNREAL_POLICY", kind: kindPath, userOnly: true, value: func(c *Config) any { return c.PolicyPath }},
+	{key: "read_only", env: "MCP_UNREAL_READ_ONLY", kind: kindBool, value: func(c *Config) any { return c.ReadOnly }},
+	{key: "extra_roots", env: "MCP_UNREAL_EXTRA_ROOTS", kind: kindPathList, userOnly: true, value: func(c *Config) any { return c.ExtraRoots }},
+	{key: "response_token_budget", env: "MCP_UNREAL_RESPONSE_TOKEN_BUDGET", kind: kindInt, value: func(c *Config) any { return c.ResponseTokenBudget }},
+	{key: "tool_timeouts", env: "MCP_UNREAL_TOOL_TIMEOUTS", kind: kindDurations, value: func(c *Config) any { return c.ToolTimeouts }},
+	{key: "audit_dir", env: "MCP_UNREAL_AUDIT_DIR", kind: kindPath, value: func(c *Config) any { return orOff(c.AuditDir) }},
+	{key: "session_dir", env: "MCP_UNREAL_SESSION_DIR", kind: kindPath, value: func(c *Config) any { return orOff(c.SessionDir) }},
+	{key: "workflow_dir", env: "MCP_UNREAL_WORKFLOW_DIR", kind: kindPath, value: func(c *Config) any { return c.WorkflowDir }},
+	{key: "trace_exporter", env: "MCP_UNREAL_TRACE_EXPORTER", value: func(c *Config) any { return c.TraceExporter }},
+	{key: "trace_file", env: "MCP_UNREAL_TRACE_FILE", kind: kindPath, value: func(c *Config) any { return c.TraceFile }},
+	{key: "metrics_port", env: "MCP_UNREAL_METRICS_PORT", kind: kindInt, value: func(c *Config) any { return c.MetricsPort }},
+}
+
+func orOff(dir string) string {
+	if dir == "" {
+		return "off"
+	}
+	return dir
+}
+
+// layer is one set of settings from a config file, keyed by environment
+// variable name.
+type layer struct {
+	// origin names the file, and the profile if any, for --print-config.
+	origin string
+	values map[string]string
+}
+
+// configFile is a parsed config file.
+type configFile struct {
+	path     string
+	profile  string
+	base     layer
+	profiles map[string]layer
+}
+
+// sources resolves settings from flags, the environment, and config files.
+type sources struct {
+	flags map[string]string
+	// user is the file in the user config directory; project is the
+	// project root's file, or the one named by MCP_UNREAL_CONFIG.
+	user, project *configFile
+	explicit      bool
+
+	profile string
+	// layers are the file layers of the selected profile, highest first.
+	layers []layer
+
+	// origins records where each setting read came from.
+	origins map[string]string
+	errs    []error
+}
+
+// newSources reads the user config file and any file named by
+// MCP_UNREAL_CONFIG. The project file is read by addProjectFile once the
+// project root is known.
+func newSources(flags map[string]string) *sources {
+	s := &sources{flags: flags, origins: make(map[string]string)}
+	if dir, err := os.UserConfigDir(); err == nil {
+		s.user = s.find(filepath.Join(dir, fileBaseName), true)
+	}
+	if path := s.flagOrEnv(configEnv); path != "" {
+		s.explicit = true
+		s.project = s.read(path, true)
+	}
+	s.useProfile(s.profileName(""))
+	return s
+}
+
+// addProjectFile reads the config file in the project root, unless
+// MCP_UNREAL_CONFIG named another, and selects the profile. auto is the
+// profile chosen when none is named, and project the project setting the
+// root was found from, which the profile must not change.
+func (s *sources) addProjectFile(root, auto, project string) {
+	if !s.explicit && root != "" {
+		s.project = s.find(root, false)
+	}
+	name := s.profileName(auto)
+	if name != "" && !s.defines(name) {
+		s.errs = append(s.errs, fmt.Errorf("profile %q is not defined in %s", name, s.fileList()))
+		name = ""
+	}
+	s.useProfile(name)
+	if s.get(projectEnv) != project {
+		s.errs = append(s.errs, fmt.Errorf("profile %q sets project, but the project is found before that profile is selected; "+
+			"select it with --profile or %s instead", name, profileEnv))
+	}
+}
+
+// profileName returns the profile to use: MCP_UNREAL_PROFILE, the project
+// file's profile key, the user file's, or else auto if a file defines it.
+func (s *sources) profileName(auto string) string {
+	if name := s.flagOrEnv(profileEnv); name != "" {
+		return name
+	}
+	for _, f := range []*configFile{s.project, s.user} {
+		if f != nil && f.profile != "" {
+			return f.profile
+		}
+	}
+	if auto != "" && s.defines(auto) {
+		return auto
+	}
+	return ""
+}
+
+func (s *sources) defines(profile string) bool {
+	for _, f := range []*configFile{s.project, s.user} {
+		if f != nil {
+			if _, ok := f.profiles[profile]; ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// useProfile orders the file layers for profile, highest first.
+func (s *sources) useProfile(profile string) {
+	s.profile = profile
+	s.layers = nil
+	files := []*configFile{s.project, s.user}
+	for _, f := range files {
+		if l, ok := f.profileLayer(profile); ok {
+			s.layers = append(s.layers, l)
+		}
+	}
+	for _, f := range files {
+		if f != nil {
+			s.layers = append(s.layers, f.base)
+		}
+	}
+}
+
+func (f *configFile) profileLayer(profile string) (layer, bool) {
+	if f == nil || profile == "" {
+		return layer{}, false
+	}
+	l, ok := f.profiles[profile]
+	return l, ok
+}
+
+// files returns the paths of the config files read.
+func (s *sources) files() []string {
+	var out []string
+	for _, f := range []*configFile{s.user, s.project} {
+		if f != nil {
+			out = append(out, f.path)
+		}
+	}
+	return out
+}
+
+func (s *sources) fileList() string {
+	if files := s.files(); len(files) > 0 {
+		return strings.Join(files, " or ")
+	}
+	return "any config file"
+}
+
+// flagOrEnv returns key's flag override or environment variable. Config
+// files cannot set it.
+func (s *sources) flagOrEnv(key string) string {
+	if v := s.flags[key]; v != "" {
+		return v
+	}
+	return os.Getenv(key)
+}
+
+// get returns the setting named by environment variable key from the
+// highest source that sets it, or "" if none does.
+func (s *sources) get(key string) string {
+	if v := s.flags[key]; v != "" {
+		s.origins[key] = "command line"
+		return v
+	}
+	if v := os.Getenv(key); v != "" {
+		s.origins[key] = "env " + key
+		return v
+	}
+	for _, l := range s.layers {
+		if v, ok := l.values[key]; ok {
+			s.origins[key] = l.origin
+			return v
+		}
+	}
+	delete(s.origins, key)
+	return ""
+}
+
+func (s *sources) orDefault(key, fallback string) string {
+	if v := s.get(key); v != "" {
+		return v
+	}
+	return fallback
+}
+
+func (s *sources) intOrDefault(key string, fallback int) int {
+	v := s.get(key)
+	if v == "" {
+		return fallback
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		return fallback
+	}
+	return n
+}
+
+// boolean reports whether key is set to a true value (1, true, yes, on).
+func (s *sources) boolean(key string) bool {
+	switch strings.ToLower(s.get(key)) {
+	case "1", "true", "yes", "on":
+		return true
+	}
+	return false
+}
+
+// find reads the config file in dir, if there is one.
+func (s *sources) find(dir string, trusted bool) *configFile {
+	var found []string
+	for _, ext := range fileExtensions {
+		path := filepath.Join(dir, fileBaseName+ext)
+		if _, err := os.Stat(path); err == nil {
+			found = append(found, path)
+		}
+	}
+	switch len(found) {
+	case 0:
+		return nil
+	case 1:
+	default:
+		s.errs = append(s.errs, fmt.Errorf("both %s exist; keep one (using %s)", strings.Join(found, " and "), found[0]))
+	}
+	return s.read(found[0], trusted)
+}
+
+// read parses the config file at path, recording any error.
+func (s *sources) read(path string, trusted bool) *configFile {
+	f, err := readFile(path, trusted)
+	if err != nil {
+		s.errs = append(s.errs, err)
+		return nil
+	}
+	return f
+}
+
+// readFile parses a TOML or JSON config file. Relative paths in it are
+// resolved against its directory. Unless the file is trusted, i.e. the
+// user file or one named by MCP_UNREAL_CONFIG, it cannot set userOnly
+// settings.
+func readFile(path string, trusted bool) (*configFile, error) {
+	data, err := os.ReadFile(filepath.Clean(path))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("config file %s does not exist", path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading config file: %w", err)
+	}
+	var raw map[string]any
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".toml":
+		_, err = toml.Decode(string(data), &raw)
+	case ".json":
+		err = json.Unmarshal(data, &raw)
+	default:
+		return nil, fmt.Errorf("config file %s: unsupported extension %q, want .toml or .json", path, ext)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("parsing config file %s: %w", path, err)
+	}
+
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	dir := filepath.Dir(path)
+	f := &configFile{path: path, profiles: make(map[string]layer)}
+	top := make(map[string]any)
+	for key, v := range raw {
+		switch key {
+		case "profile":
+			name, ok := v.(string)
+			if !ok {
+				return nil, fmt.Errorf("config file %s: profile must be a string", path)
+			}
+			f.profile = name
+		case "profiles":
+			tables, ok := v.(map[string]any)
+			if !ok {
+				return nil, fmt.Errorf("config file %s: profiles must be a table of profiles", path)
+			}
+			for name, t := range tables {
+				values, ok := t.(map[string]any)
+				if !ok {
+					return nil, fmt.Errorf("config file %s: profile %q must be a table", path, name)
+				}
+				l, err := parseLayer(values, dir, trusted)
+				if err != nil {
+					return nil, fmt.Errorf("config file %s: profile %q: %w", path, name, err)
+				}
+				l.origin = fmt.Sprintf("%s [profiles.%s]", path, name)
+				f.profiles[name] = l
+			}
+		default:
+			top[key] = v
+		}
+	}
+	base, err := parseLayer(top, dir, trusted)
+	if err != nil {
+		return nil, fmt.Errorf("config file %s: %w", path, err)
+	}
+	base.origin = path
+	f.base = base
+	return f, nil
+}
+
+// settingsByKey indexes settings by config file key.
+var settingsByKey = func() map[string]setting {
+	m := make(map[string]setting, len(settings))
+	for _, st := range settings {
+		m[st.key] = st
+	}
+	return m
+}()
+
+// parseLayer converts file values to their environment variable form.
+func parseLayer(values map[string]any, dir string, trusted bool) (layer, error) {
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+
+	l := layer{values: make(map[string]string, len(values))}
+	for _, key := range keys {
+		st, ok := settingsByKey[key]
+		if !ok {
+			return layer{}, fmt.Errorf("unknown setting %q", key)
+		}
+		if st.userOnly && !trusted {
+			return layer{}, fmt.Errorf("%s cannot be set in the project's own config file; "+
+				"set it in the user config file, a file named by %s, or the environment", key, configEnv)
+		}
+		v, err := st.parse(values[key], dir)
+		if err != nil {
+			return layer{}, fmt.Errorf("%s: %w", key, err)
+		}
+		l.values[st.env] = v
+	}
+	return l, nil
+}
+
+// parse converts a file value to its environment variable form.
+func (st setting) parse(v any, dir string) (string, error) {
+	switch st.kind {
+	case kindInt:
+		switch n := v.(type) {
+		case int64:
+			return strconv.FormatInt(n, 10), nil
+		case float64: // JSON numbers
+			if n == math.Trunc(n) {
+				return strconv.FormatInt(int64(n), 10), nil
+			}
+		}
+		return "", fmt.Errorf("want an integer, got %v", v)
+	case kindBool:
+		b, ok := v.(bool)
+		if !ok {
+			return "", fmt.Errorf("want true or false, got %v", v)
+		}
+		return strconv.FormatBool(b), nil
+	case kindPathList:
+		list, ok := v.([]any)
+		if !ok {
+			return "", fmt.Errorf("want an array of paths")
+		}
+		paths := make([]string, len(list))
+		for i, p := range list {
+			s, ok := p.(string)
+			if !ok {
+				return "", fmt.Errorf("want an array of paths, got %v", p)
+			}
+			paths[i] = resolvePath(s, dir)
+		}
+		return strings.Join(paths, string(os.PathListSeparator)), nil
+	case kindDurations:
+		table, ok := v.(map[string]any)
+		if !ok {
+			return "", fmt.Errorf("want a table of tool names to durations")
+		}
+		pairs := make([]string, 0, len(table))
+		for name, d := range table {
+			s, ok := d.(string)
+			if !ok {
+				return "", fmt.Errorf("%s: want a duration such as \"45m\", got %v", name, d)
+			}
+			pairs = append(pairs, name+"="+s)
+		}
+		slices.Sort(pairs)
+		// Checked with the environment variable form by parseToolTimeouts.
+		return strings.Join(pairs, ","), nil
+	}
+	s, ok := v.(string)
+	if !ok {
+		return "", fmt.Errorf("want a string, got %v", v)
+	}
+	if st.kind == kindPath && s != "off" {
+		s = resolvePath(s, dir)
+	}
+	return s, nil
+}
+
+func resolvePath(p, dir string) string {
+	if p == "" || filepath.IsAbs(p) {
+		return p
+	}
+	return filepath.Join(dir, p)
+}
+
+// parseToolTimeouts parses MCP_UNREAL_TOOL_TIMEOUTS, a comma-separated
+// list of tool=duration pairs such as "build_project=45m,run_tests=1h".
+func parseToolTimeouts(s string) (map[string]time.Duration, error) {
+	if s == "" {
+		return nil, nil
+	}
+	out := make(map[string]time.Duration)
+	for _, pair := range strings.Split(s, ",") {
+		name, value, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		if !ok || name == "" {
+			return nil, fmt.Errorf("tool timeout %q: want tool=duration", pair)
+		}
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return nil, fmt.Errorf("tool timeout for %s: %w", name, err)
+		}
+		if d <= 0 {
+			return nil, fmt.Errorf("tool timeout for %s: %s is not positive", name, value)
+		}
+		out[name] = d
+	}
+	return out, nil
+}
+
+// CheckSettings returns the problems found while loading: unreadable or
+// invalid config files, an undefined profile, and invalid values.
+func (c *Config) CheckSettings() error {
+	errs := slices.Clone(c.errs)
+	if c.LogFormat != "text" && c.LogFormat != "json" {
+		errs = append(errs, fmt.Errorf("log format %q: must be text or json", c.LogFormat))
+	}
+	return errors.Join(errs...)
+}
+
+// WriteTOML writes the effective configuration as a config file, with
+// where each setting came from. The editor token is redacted.
+func (c *Config) WriteTOML(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "# Effective mcp-unreal configuration.")
+	if len(c.Files) > 0 {
+		_, _ = fmt.Fprintf(tw, "# Config files: %s\n", strings.Join(c.Files, ", "))
+	} else {
+		_, _ = fmt.Fprintln(tw, "# Config files: none")
+	}
+	if c.Profile != "" {
+		_, _ = fmt.Fprintf(tw, "# Profile: %s\n", c.Profile)
+	} else {
+		_, _ = fmt.Fprintln(tw, "# Profile: none")
+	}
+	_, _ = fmt.Fprintln(tw)
+
+	for _, st := range settings {
+		v := st.value(c)
+		if s, ok := v.(string); ok && st.secret && s != "" {
+			v = "<redacted>"
+		}
+		origin := c.origins[st.env]
+		if origin == "" {
+			origin = "default"
+		}
+		_, _ = fmt.Fprintf(tw, "%s = %s\t# %s\n", st.key, tomlValue(v), origin)
+	}
+	return tw.Flush()
+}
+
+// tomlValue formats a setting value as TOML.
+func tomlValue(v any) string {
+	switch v := v.(type) {
+	case string:
+		return strconv.Quote(v)
+	case []string:
+		quoted := make([]string, len(v))
+		for i, s := range v {
+			quoted[i] = strconv.Quote(s)
+		}
+		return "[" + strings.Join(quoted, ", ") + "]"
+	case map[string]time.Duration:
+		names := make([]string, 0, len(v))
+		for name := range v {
+			names = append(names, name)
+		}
+		slices.Sort(names)
+		pairs := make([]string, len(names))
+		for i, name := range names {
+			pairs[i] = fmt.Sprintf("%s = %q", name, v[name])
+		}
+		if len(pairs) == 0 {
+			return "{}"
+		}
+		return "{ " + strings.Join(pairs, ", ") + " }"
+	}
+	return fmt.Sprint(v)
+}
@@ -0,0 +1,303 @@
+// Copyright (c) mcp-unreal project contributors. Apache-2.0 license.
+
+package config
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/BurntSushi/toml"
+)
+
+// TestMain keeps the tests from reading the user's own config file.
+func TestMain(m *testing.M) {
+	dir, err := os.MkdirTemp("", "mcp-unreal-config")
+	if err != nil {
+		panic(err)
+	}
+	for _, key := range []string{"XDG_CONFIG_HOME", "HOME", "AppData"} {
+		_ = os.Setenv(key, dir)
+	}
+	code := m.Run()
+	_ = os.RemoveAll(dir)
+	os.Exit(code)
+}
+
+// writeUserConfig writes the user config file name with content to a
+// fresh user config directory.
+func writeUserConfig(t *testing.T, name, content string) string {
+	t.Helper()
+	home := t.TempDir()
+	for _, key := range []string{"XDG_CONFIG_HOME", "HOME", "AppData"} {
+		t.Setenv(key, home)
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return writeFile(t, filepath.Join(dir, fileBaseName), name, content)
+}
+
+func writeFile(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// testProject creates a project root with name.uproject and sets
+// MCP_UNREAL_PROJECT to it.
+func testProject(t *testing.T, name string) string {
+	t.Helper()
+	root := t.TempDir()
+	writeFile(t, root, name+".uproject", `{"EngineAssociation": "5.7"}`)
+	t.Setenv("MCP_UNREAL_PROJECT", root)
+	return root
+}
+
+const projectTOML = `
+rc_api_port = 31000
+plugin_port = 9000
+workflow_dir = "tools/workflows"
+
+[tool_timeouts]
+build_project = "45m"
+
+[profiles.ci]
+plugin_port = 9100
+read_only = true
+`
+
+func TestLoadConfigFilePrecedence(t *testing.T) {
+	user := writeUserConfig(t, "mcp-unreal.json", `{"plugin_port": 7000, "log_format": "json", "metrics_port": 9400,
+		"extra_roots": ["../shared", "/opt/assets"],
+		"profiles": {"ci": {"metrics_port": 9500, "rc_api_port": 32000, "editor_token": "secret"}}}`)
+	userDir := filepath.Dir(user)
+	root := testProject(t, "Game")
+	writeFile(t, root, "mcp-unreal.toml", projectTOML)
+	t.Setenv("MCP_UNREAL_PROFILE", "ci")
+	t.Setenv("RC_API_PORT", "31001")
+	t.Setenv("MCP_UNREAL_READ_ONLY", "0")
+
+	cfg := LoadWith(map[string]string{"RC_API_PORT": "31002"})
+	if err := cfg.CheckSettings(); err != nil {
+		t.Fatalf("CheckSettings: %v", err)
+	}
+	if cfg.Profile != "ci" || len(cfg.Files) != 2 {
+		t.Errorf("Profile = %q, Files = %v; want ci and two files", cfg.Profile, cfg.Files)
+	}
+	checks := []struct {
+		name      string
+		got, want any
+	}{
+		{"flag over env", cfg.RCAPIPort, 31002},
+		{"env over file", cfg.ReadOnly, false},
+		{"project profile over user profile", cfg.PluginPort, 9100},
+		{"user profile over project file", cfg.MetricsPort, 9500},
+		{"user file", cfg.LogFormat, "json"},
+		{"relative path", cfg.WorkflowDir, filepath.Join(root, "tools", "workflows")},
+		{"path list", strings.Join(cfg.ExtraRoots, ","), filepath.Join(filepath.Dir(userDir), "shared") + ",/opt/assets"},
+		{"user profile secret", cfg.EditorToken, "secret"},
+		{"tool timeouts", cfg.ToolTimeouts["build_project"], 45 * time.Minute},
+		{"default", cfg.PluginHost, defaultEditorHost},
+	}
+	for _, c := range checks {
+		if c.got != c.want {
+			t.Errorf("%s: got %v, want %v", c.name, c.got, c.want)
+		}
+	}
+}
+
+func TestLoadProfileByProjectName(t *testing.T) {
+	writeUserConfig(t, "mcp-unreal.toml", `
+profiles.Shooter.engine_version = "5.4"
+profiles.Other.engine_version = "5.3"
+`)
+	testProject(t, "Shooter")
+
+	cfg := Load()
+	if err := cfg.CheckSettings(); err != nil {
+		t.Fatalf("CheckSettings: %v", err)
+	}
+	if cfg.Profile != "Shooter" || cfg.EngineVersion != "5.4" {
+		t.Errorf("Profile = %q, EngineVersion = %q; want Shooter and 5.4", cfg.Profile, cfg.EngineVersion)
+	}
+}
+
+func TestLoadProfileFromFile(t *testing.T) {
+	writeUserConfig(t, "mcp-unreal.toml", `
+profile = "laptop"
+[profiles.laptop]
+plugin_port = 8190
+[profiles.desk]
+plugin_port = 8290
+`)
+	root := testProject(t, "Game")
+
+	if cfg := Load(); cfg.Profile != "laptop" || cfg.PluginPort != 8190 {
+		t.Errorf("user profile: Profile = %q, PluginPort = %d; want laptop and 8190", cfg.Profile, cfg.PluginPort)
+	}
+	writeFile(t, root, "mcp-unreal.json", `{"profile": "desk"}`)
+	if cfg := Load(); cfg.Profile != "desk" || cfg.PluginPort != 8290 {
+		t.Errorf("project profile: Profile = %q, PluginPort = %d; want desk and 8290", cfg.Profile, cfg.PluginPort)
+	}
+}
+
+func TestLoadExplicitConfigFile(t *testing.T) {
+	root := testProject(t, "Game")
+	writeFile(t, root, "mcp-unreal.toml", `plugin_port = 9000`)
+	other := writeFile(t, t.TempDir(), "ci.toml", `plugin_port = 9300`)
+
+	cfg := LoadWith(map[string]string{configEnv: other})
+	if err := cfg.CheckSettings(); err != nil {
+		t.Fatalf("CheckSettings: %v", err)
+	}
+	if cfg.PluginPort != 9300 {
+		t.Errorf("PluginPort = %d, want 9300 from the explicit file", cfg.PluginPort)
+	}
+}
+
+func TestLoadConfigFileErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		content string
+		env     map[string]string
+		want    string
+	}{
+		{"unknown key", "mcp-unreal.toml", `plugin_prot = 1`, nil, `unknown setting "plugin_prot"`},
+		{"wrong type", "mcp-unreal.json", `{"plugin_port": "8090"}`, nil, "plugin_port: want an integer"},
+		{"syntax", "mcp-unreal.toml", `plugin_port = `, nil, "parsing config file"},
+		{"project in project file", "mcp-unreal.toml", `project = "/elsewhere"`, nil, "project cannot be set"},
+		{"undefined profile", "mcp-unreal.toml", `plugin_port = 1`, map[string]string{"MCP_UNREAL_PROFILE": "nope"}, `profile "nope" is not defined`},
+		{"tool timeout", "mcp-unreal.toml", "[tool_timeouts]\nrun_tests = \"soon\"", nil, "tool timeout for run_tests"},
+		{"log format", "mcp-unreal.toml", `log_format = "xml"`, nil, `log format "xml"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := testProject(t, "Game")
+			writeFile(t, root, tt.file, tt.content)
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+			err := Load().CheckSettings()
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("CheckSettings() = %v, want an error containing %q", err, tt.want)
+			}
+		})
+	}
+}
+
+// TestLoadProjectFileUserOnly checks that a project's config file, which
+// comes with the project, cannot redirect the editor token or widen the
+// sandbox and policy, while the user file and --config can.
+func TestLoadProjectFileUserOnly(t *testing.T) {
+	values := map[string]string{
+		"allow_remote_editor": `true`,
+		"editor_token":        `"stolen"`,
+		"editor_ca_file":      `"ca.pem"`,
+		"extra_roots":         `["/"]`,
+		"policy":              `"open.json"`,
+	}
+	for key, v := range values {
+		t.Run(key, func(t *testing.T) {
+			root := testProject(t, "Game")
+			writeFile(t, root, "mcp-unreal.toml", key+" = "+v)
+			err := Load().CheckSettings()
+			if err == nil || !strings.Contains(err.Error(), key+" cannot be set in the project's own config file") {
+				t.Errorf("project file: CheckSettings() = %v, want %s refused", err, key)
+			}
+
+			profile := writeFile(t, root, "mcp-unreal.toml", "[profiles.ci]\n"+key+" = "+v)
+			t.Setenv("MCP_UNREAL_PROFILE", "ci")
+			if err := Load().CheckSettings(); err == nil || !strings.Contains(err.Error(), "cannot be set") {
+				t.Errorf("project file profile: CheckSettings() = %v, want %s refused", err, key)
+			}
+
+			if err := LoadWith(map[string]string{configEnv: profile}).CheckSettings(); err != nil {
+				t.Errorf("--config file: CheckSettings() = %v, want %s allowed", err, key)
+			}
+			t.Setenv("MCP_UNREAL_PROFILE", "")
+			_ = os.Remove(profile)
+			writeUserConfig(t, "mcp-unreal.toml", key+" = "+v)
+			if err := Load().CheckSettings(); err != nil {
+				t.Errorf("user file: CheckSettings() = %v, want %s allowed", err, key)
+			}
+		})
+	}
+}
+
+func TestLoadMissingExplicitConfigFile(t *testing.T) {
+	testProject(t, "Game")
+	t.Setenv("MCP_UNREAL_CONFIG", filepath.Join(t.TempDir(), "missing.toml"))
+	if err := Load().CheckSettings(); err == nil || !strings.Contains(err.Error(), "does not exist") {
+		t.Errorf("CheckSettings() = %v, want a missing file error", err)
+	}
+}
+
+func TestLoadToolTimeoutsEnv(t *testing.T) {
+	root := testProject(t, "Game")
+	writeFile(t, root, "mcp-unreal.toml", "[tool_timeouts]\nbuild_project = \"45m\"")
+	t.Setenv("MCP_UNREAL_TOOL_TIMEOUTS", "run_tests=2h, live_compile=90s")
+
+	cfg := Load()
+	want := map[string]time.Duration{"run_tests": 2 * time.Hour, "live_compile": 90 * time.Second}
+	if len(cfg.ToolTimeouts) != len(want) {
+		t.Fatalf("ToolTimeouts = %v, want %v", cfg.ToolTimeouts, want)
+	}
+	for name, d := range want {
+		if cfg.ToolTimeouts[name] != d {
+			t.Errorf("ToolTimeouts[%s] = %s, want %s", name, cfg.ToolTimeouts[name], d)
+		}
+	}
+}
+
+func TestWriteTOML(t *testing.T) {
+	root := testProject(t, "Game")
+	file := writeFile(t, root, "mcp-unreal.toml", projectTOML)
+	t.Setenv("MCP_UNREAL_PROFILE", "ci")
+	t.Setenv("MCP_UNREAL_EDITOR_TOKEN", "secret")
+
+	cfg := LoadWith(map[string]string{"RC_API_PORT": "31002"})
+	var buf bytes.Buffer
+	if err := cfg.WriteTOML(&buf); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		"# Profile: ci",
+		"# Config files: " + file,
+		"editor_token = \"<redacted>\"",
+		"plugin_port = 9100",
+		"# " + file + " [profiles.ci]",
+		"rc_api_port = 31002",
+		"# command line",
+		"# env MCP_UNREAL_PROJECT",
+		`tool_timeouts = { build_project = "45m0s" }`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "secret") {
+		t.Errorf("output contains the editor token:\n%s", out)
+	}
+
+	// The output is itself a valid config file.
+	var back map[string]any
+	if _, err := toml.Decode(out, &back); err != nil {
+		t.Fatalf("output is not valid TOML: %v\n%s", err, out)
+	}
+	if _, err := parseLayer(back, root, true); err != nil {
+		t.Errorf("output is not a valid config file: %v", err)
+	}
+}
@@ -0,0 +1,152 @@
+// Copyright (c) mcp-unreal project contributors. Apache-2.0 license.
+
+package docs
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/blevesearch/bleve/v2"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/remiphilippe/mcp-unreal/internal/toolerr"
+)
+
+// --- lookup_api_diff tool ---
+
+// LookupAPIDiffInput defines parameters for the lookup_api_diff tool.
+type LookupAPIDiffInput struct {
+	ClassName   string `json:"class_name" jsonschema:"required,UE class name, e.g. AActor or UCharacterMovementComponent"`
+	FromVersion string `json:"from_version" jsonschema:"required,Older engine version, e.g. 5.5"`
+	ToVersion   string `json:"to_version" jsonschema:"required,Newer engine version, e.g. 5.7"`
+}
+
+// LookupAPIDiffOutput is returned by the lookup_api_diff tool.
+type LookupAPIDiffOutput struct {
+	ClassName         string   `json:"class_name" jsonschema:"the compared class"`
+	FromVersion       string   `json:"from_version" jsonschema:"older engine version"`
+	ToVersion         string   `json:"to_version" jsonschema:"newer engine version"`
+	FoundInFrom       bool     `json:"found_in_from" jsonschema:"whether the class is documented for from_version"`
+	FoundInTo         bool     `json:"found_in_to" jsonschema:"whether the class is documented for to_version"`
+	AddedProperties   []string `json:"added_properties,omitempty" jsonschema:"properties documented in to_version but not from_version"`
+	RemovedProperties []string `json:"removed_properties,omitempty" jsonschema:"properties documented in from_version but not to_version"`
+	AddedFunctions    []string `json:"added_functions,omitempty" jsonschema:"functions documented in to_version but not from_version"`
+	RemovedFunctions  []string `json:"removed_functions,omitempty" jsonschema:"functions documented in from_version but not to_version"`
+	ParentChanged     string   `json:"parent_changed,omitempty" jsonschema:"old -> new parent class, if the parent changed"`
+}
+
+// LookupAPIDiff implements the lookup_api_diff tool. It compares the
+// key properties and functions listed in each version's class reference;
+// members not documented in either version are not reported.
+func (d *Index) LookupAPIDiff(ctx context.Context, req *mcp.CallToolRequest, input LookupAPIDiffInput) (*mcp.CallToolResult, LookupAPIDiffOutput, error) {
+	if input.ClassName == "" {
+		return nil, LookupAPIDiffOutput{}, toolerr.New(toolerr.Validation, "class_name is required")
+	}
+	if input.FromVersion == "" || input.ToVersion == "" {
+		return nil, LookupAPIDiffOutput{}, toolerr.New(toolerr.Validation, "from_version and to_version are required")
+	}
+	for _, v := range []string{input.FromVersion, input.ToVersion} {
+		if _, err := d.resolveVersion(v); err != nil {
+			return nil, LookupAPIDiffOutput{}, err
+		}
+	}
+
+	out := LookupAPIDiffOutput{
+		ClassName:   input.ClassName,
+		FromVersion: input.FromVersion,
+		ToVersion:   input.ToVersion,
+	}
+
+	from, okFrom := d.classForVersion(input.ClassName, input.FromVersion)
+	to, okTo := d.classForVersion(input.ClassName, input.ToVersion)
+	out.FoundInFrom, out.FoundInTo = okFrom, okTo
+	if !okFrom && !okTo {
+		return nil, out, nil
+	}
+
+	out.AddedProperties, out.RemovedProperties = diffMembers(from.KeyProps, to.KeyProps)
+	out.AddedFunctions, out.RemovedFunctions = diffMembers(from.KeyFuncs, to.KeyFuncs)
+	if okFrom && okTo && from.Parent != to.Parent {
+		out.ParentChanged = from.Parent + " -> " + to.Parent
+	}
+
+	return nil, out, nil
+}
+
+// classForVersion returns the class reference whose title is exactly the
+// class name and whose version is exactly the given one. Unlike
+// findClass it does not fall back to docs that merely mention the class.
+func (d *Index) classForVersion(className, version string) (ClassInfo, bool) {
+	title := bleve.NewMatchQuery(className)
+	title.SetField("title")
+	ver := bleve.NewTermQuery(version)
+	ver.SetField("version")
+
+	req := bleve.NewSearchRequest(excludeKind(bleve.NewConjunctionQuery(title, ver), kindChunk, kindExample))
+	req.Size = 5
+	req.Fields = []string{"title", "source", "version", "content", "url"}
+	result, err := d.index.Search(req)
+	if err != nil {
+		return ClassInfo{}, false
+	}
+	for _, hit := range result.Hits {
+		if strings.EqualFold(strField(hit.Fields, "title"), className) {
+			info := ParseClassDoc(className, strField(hit.Fields, "content"))
+			info.Source = strField(hit.Fields, "source")
+			info.Version = version
+			info.URL = strField(hit.Fields, "url")
+			return info, true
+		}
+	}
+	return ClassInfo{}, false
+}
+
+// diffMembers compares two documented member lists by member name and
+// returns the names added in newer and removed from older, sorted.
+func diffMembers(older, newer []string) (added, removed []string) {
+	oldNames := memberNames(older)
+	newNames := memberNames(newer)
+	for name := range newNames {
+		if !oldNames[name] {
+			added = append(added, name)
+		}
+	}
+	for name := range oldNames {
+		if !newNames[name] {
+			removed = append(removed, name)
+		}
+	}
+	sort.Strings(added)
+	sort.Strings(removed)
+	return added, removed
+}
+
+func memberNames(items []string) map[string]bool {
+	names := make(map[string]bool, len(items))
+	for _, item := range items {
+		if name := memberName(item); name != "" {
+			names[name] = true
+		}
+	}
+	return names
+}
+
+// memberName extracts the identifier from a class doc list item such as
+// "`SetActorLocation(FVector NewLocation)` — moves the actor" or
+// "RootComponent: the root". Signatures are ignored so a parameter change
+// is not reported as remove+add.
+func memberName(item string) string {
+	s := strings.TrimSpace(item)
+	if strings.HasPrefix(s, "`") {
+		if end := strings.Index(s[1:], "`"); end >= 0 {
+			s = s[1 : end+1]
+		}
+	}
+	end := strings.IndexFunc(s, func(r rune) bool {
+		return !(r == '_' || r == ':' || r >= '0' && r <= '9' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z')
+	})
+	if end >= 0 {
+		s = s[:end]
+	}
+	return strings.Trim(s, ":")
+}
@@ -0,0 +1,251 @@
+// Copyright (c) mcp-unreal project contributors. Apache-2.0 license.
+
+package docs
+
+import (
+	"strings"
+)
+
+// maxChunkChars bounds a chunk's body (~400 tokens). Sections longer than
+// this are split at paragraph boundaries, then at sentence boundaries.
+const maxChunkChars = 1600
+
+// breadcrumbSep joins heading levels in a chunk's breadcrumb.
+const breadcrumbSep = " > "
+
+// docChunk is one heading-scoped piece of a markdown document.
+type docChunk struct {
+	// Heading is the breadcrumb of headings enclosing the chunk, starting
+	// with the document title, e.g. "AActor > Key Functions".
+	Heading string
+	// Text is the chunk body without its heading line.
+	Text string
+}
+
+// chunkMarkdown splits markdown into heading-scoped chunks. Each chunk
+// carries the breadcrumb of enclosing headings so a snippet can be shown
+// in context. Headings inside fenced code blocks are ignored, and
+// oversized sections are split without breaking sentences.
+func chunkMarkdown(title, content string) []docChunk {
+	var chunks []docChunk
+	trail := headingTrail{title: title}
+	var body []string
+	inFence := false
+
+	flush := func() {
+		text := strings.TrimSpace(strings.Join(body, "\n"))
+		body = body[:0]
+		if text == "" {
+			return
+		}
+		heading := trail.String()
+		for _, piece := range splitOversized(text) {
+			chunks = append(chunks, docChunk{Heading: heading, Text: piece})
+		}
+	}
+
+	for _, line := range strings.Split(content, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if isFence(trimmed) {
+			inFence = !inFence
+		}
+		if !inFence {
+			if level, text := parseHeading(trimmed); level > 0 {
+				flush()
+				trail.push(level, text)
+				continue
+			}
+		}
+		body = append(body, line)
+	}
+	flush()
+
+	return chunks
+}
+
+// headingTrail tracks the headings enclosing the current line of a
+// markdown document.
+type headingTrail struct {
+	title string
+	stack []string // heading text per level; stack[0] is H1
+}
+
+// push records a heading, dropping any deeper headings it closes.
+func (t *headingTrail) push(level int, text string) {
+	for len(t.stack) < level {
+		t.stack = append(t.stack, "")
+	}
+	t.stack = t.stack[:level]
+	t.stack[level-1] = text
+}
+
+// String returns the breadcrumb, always starting with the document title.
+func (t *headingTrail) String() string {
+	var parts []string
+	for _, h := range t.stack {
+		if h != "" {
+			parts = append(parts, h)
+		}
+	}
+	if t.title != "" && (len(parts) == 0 || parts[0] != t.title) {
+		parts = append([]string{t.title}, parts...)
+	}
+	return strings.Join(parts, breadcrumbSep)
+}
+
+// isFence reports whether a trimmed line opens or closes a fenced code
+// block.
+func isFence(trimmed string) bool {
+	return strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~")
+}
+
+// parseHeading returns the level and text of an ATX markdown heading
+// ("## Key Functions" → 2, "Key Functions"), or 0 if line is not one.
+func parseHeading(line string) (int, string) {
+	level := 0
+	for level < len(line) && level < 6 && line[level] == '#' {
+		level++
+	}
+	if level == 0 || level >= len(line) || line[level] != ' ' {
+		return 0, ""
+	}
+	return level, strings.TrimSpace(strings.Trim(line[level:], "# "))
+}
+
+// splitOversized breaks text longer than maxChunkChars into pieces at
+// paragraph boundaries (outside code fences), falling back to sentence
+// boundaries for paragraphs that are themselves too long.
+func splitOversized(text string) []string {
+	if len(text) <= maxChunkChars {
+		return []string{text}
+	}
+
+	var pieces []string
+	var cur strings.Builder
+	emit := func() {
+		if s := strings.TrimSpace(cur.String()); s != "" {
+			pieces = append(pieces, s)
+		}
+		cur.Reset()
+	}
+	add := func(part, sep string) {
+		if cur.Len() > 0 && cur.Len()+len(sep)+len(part) > maxChunkChars {
+			emit()
+		}
+		if cur.Len() > 0 {
+			cur.WriteString(sep)
+		}
+		cur.WriteString(part)
+	}
+
+	for _, para := range splitParagraphs(text) {
+		if len(para) <= maxChunkChars {
+			add(para, "\n\n")
+			continue
+		}
+		for _, sentence := range splitSentences(para) {
+			add(sentence, " ")
+		}
+	}
+	emit()
+
+	return pieces
+}
+
+// splitParagraphs splits on blank lines, keeping fenced code blocks whole.
+func splitParagraphs(text string) []string {
+	var paras []string
+	var cur []string
+	inFence := false
+	for _, line := range strings.Split(text, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if isFence(trimmed) {
+			inFence = !inFence
+		}
+		if trimmed == "" && !inFence {
+			if len(cur) > 0 {
+				paras = append(paras, strings.Join(cur, "\n"))
+				cur = cur[:0]
+			}
+			continue
+		}
+		cur = append(cur, line)
+	}
+	if len(cur) > 0 {
+		paras = append(paras, strings.Join(cur, "\n"))
+	}
+	return paras
+}
+
+// splitSentences splits prose after '.', '!' or '?' followed by whitespace.
+// A sentence longer than maxChunkChars is hard-split at a word boundary.
+func splitSentences(text string) []string {
+	var sentences []string
+	start := 0
+	for i := 0; i < len(text)-1; i++ {
+		c := text[i]
+		if (c == '.' || c == '!' || c == '?') && isSpace(text[i+1]) {
+			sentences = append(sentences, strings.TrimSpace(text[start:i+1]))
+			start = i + 1
+		}
+	}
+	if rest := strings.TrimSpace(text[start:]); rest != "" {
+		sentences = append(sentences, rest)
+	}
+
+	var out []string
+	for _, s := range sentences {
+		for len(s) > maxChunkChars {
+			cut := strings.LastIndexByte(s[:maxChunkChars], ' ')
+			if cut <= 0 {
+				cut = maxChunkChars
+			}
+			out = append(out, strings.TrimSpace(s[:cut]))
+			s = strings.TrimSpace(s[cut:])
+		}
+		if s != "" {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// truncateAtSentence shortens text to at most maxChars, ending on a
+// sentence boundary when one exists. If even the first sentence does not
+// fit, it is cut at a word boundary and marked with "...".
+func truncateAtSentence(text string, maxChars int) string {
+	if len(text) <= maxChars {
+		return text
+	}
+	var b strings.Builder
+	for _, s := range splitSentences(text) {
+		sep := 0
+		if b.Len() > 0 {
+			sep = 1
+		}
+		if b.Len()+sep+len(s) > maxChars {
+			break
+		}
+		if sep == 1 {
+			b.WriteByte(' ')
+		}
+		b.WriteString(s)
+	}
+	if b.Len() > 0 {
+		return b.String()
+	}
+
+	limit := maxChars - 3
+	if limit <= 0 {
+		return ""
+	}
+	cut := strings.LastIndexByte(text[:limit], ' ')
+	if cut <= 0 {
+		cut = limit
+	}
+	return strings.TrimSpace(text[:cut]) + "..."
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\n' || c == '\t' || c == '\r'
+}
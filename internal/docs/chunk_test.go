@@ -0,0 +1,220 @@
+// Copyright (c) mcp-unreal project contributors. Apache-2.0 license.
+
+package docs
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+const chunkTestDoc = "# AActor\n\n" +
+	"**Parent**: UObject\n\nBase class for placed or spawned objects.\n\n" +
+	"## Key Properties\n\n- `RootComponent` — the root of the component hierarchy\n\n" +
+	"## Key Functions\n\n### Lifecycle\n\n- `BeginPlay()` — called when play begins\n\n" +
+	"### Transform\n\n- `SetActorLocation()` — moves the actor\n\n" +
+	"```cpp\n# not a heading\nActor->SetActorLocation(FVector::ZeroVector);\n```\n"
+
+func TestChunkMarkdown_Breadcrumbs(t *testing.T) {
+	chunks := chunkMarkdown("AActor", chunkTestDoc)
+
+	want := []string{
+		"AActor",
+		"AActor > Key Properties",
+		"AActor > Key Functions > Lifecycle",
+		"AActor > Key Functions > Transform",
+	}
+	if len(chunks) != len(want) {
+		for _, c := range chunks {
+			t.Logf("chunk %q: %.40q", c.Heading, c.Text)
+		}
+		t.Fatalf("expected %d chunks, got %d", len(want), len(chunks))
+	}
+	for i, w := range want {
+		if chunks[i].Heading != w {
+			t.Errorf("chunk %d heading = %q, want %q", i, chunks[i].Heading, w)
+		}
+	}
+	if !strings.Contains(chunks[3].Text, "# not a heading") {
+		t.Error("heading-like line inside a code fence should stay in the chunk body")
+	}
+	if strings.Contains(chunks[1].Text, "## Key Properties") {
+		t.Error("chunk body should not repeat its heading line")
+	}
+}
+
+func TestChunkMarkdown_NoHeadings(t *testing.T) {
+	chunks := chunkMarkdown("Notes", "Just a paragraph.\n\nAnd another.")
+	if len(chunks) != 1 {
+		t.Fatalf("expected 1 chunk, got %d", len(chunks))
+	}
+	if chunks[0].Heading != "Notes" {
+		t.Errorf("heading = %q, want document title", chunks[0].Heading)
+	}
+}
+
+func TestChunkMarkdown_SplitsOversizedSections(t *testing.T) {
+	var b strings.Builder
+	b.WriteString("# Big\n\n")
+	for i := 0; i < 100; i++ {
+		b.WriteString("This sentence is about actors and spawning them in levels. ")
+	}
+	chunks := chunkMarkdown("Big", b.String())
+	if len(chunks) < 2 {
+		t.Fatalf("expected oversized section to be split, got %d chunk(s)", len(chunks))
+	}
+	for i, c := range chunks {
+		if len(c.Text) > maxChunkChars {
+			t.Errorf("chunk %d is %d chars, max %d", i, len(c.Text), maxChunkChars)
+		}
+		if !strings.HasSuffix(c.Text, ".") {
+			t.Errorf("chunk %d should end on a sentence boundary: %q", i, c.Text[len(c.Text)-20:])
+		}
+	}
+}
+
+func TestParseHeading(t *testing.T) {
+	tests := []struct {
+		line  string
+		level int
+		text  string
+	}{
+		{"# Title", 1, "Title"},
+		{"### Deep ###", 3, "Deep"},
+		{"#NoSpace", 0, ""},
+		{"Plain text", 0, ""},
+		{"####### Seven", 0, ""},
+	}
+	for _, tt := range tests {
+		level, text := parseHeading(tt.line)
+		if level != tt.level || text != tt.text {
+			t.Errorf("parseHeading(%q) = (%d, %q), want (%d, %q)", tt.line, level, text, tt.level, tt.text)
+		}
+	}
+}
+
+func TestTruncateAtSentence(t *testing.T) {
+	text := "First sentence here. Second sentence here. Third one."
+	if got := truncateAtSentence(text, 45); got != "First sentence here. Second sentence here." {
+		t.Errorf("got %q", got)
+	}
+	if got := truncateAtSentence(text, 100); got != text {
+		t.Errorf("short text should be unchanged, got %q", got)
+	}
+	got := truncateAtSentence("Averyveryverylongsentence without any break at all", 20)
+	if len(got) > 20 || !strings.HasSuffix(got, "...") {
+		t.Errorf("fallback cut should fit and end with ..., got %q", got)
+	}
+}
+
+func TestLookupDocs_ReturnsChunkWithBreadcrumb(t *testing.T) {
+	idx := createTestIndex(t)
+	if err := idx.IndexDoc(DocEntry{ID: "aactor", Title: "AActor", Category: "actor", Source: "ue5.7", Content: chunkTestDoc}); err != nil {
+		t.Fatalf("IndexDoc: %v", err)
+	}
+
+	_, out, err := idx.LookupDocs(context.Background(), nil, LookupDocsInput{Query: "BeginPlay lifecycle"})
+	if err != nil {
+		t.Fatalf("LookupDocs: %v", err)
+	}
+	if out.Total == 0 {
+		t.Fatal("expected results")
+	}
+	top := out.Results[0]
+	if top.Heading != "AActor > Key Functions > Lifecycle" {
+		t.Errorf("heading = %q", top.Heading)
+	}
+	if strings.Contains(top.Snippet, "RootComponent") {
+		t.Errorf("snippet should be the matching section only, got %q", top.Snippet)
+	}
+	if len(top.Highlights) == 0 || !strings.Contains(top.Highlights[0], "**BeginPlay**") {
+		t.Errorf("expected highlighted BeginPlay fragment, got %v", top.Highlights)
+	}
+}
+
+func TestLookupDocs_PacksWholeChunks(t *testing.T) {
+	idx := createTestIndex(t)
+	if err := idx.IndexDoc(DocEntry{ID: "aactor", Title: "AActor", Category: "actor", Source: "ue5.7", Content: chunkTestDoc}); err != nil {
+		t.Fatalf("IndexDoc: %v", err)
+	}
+
+	_, out, err := idx.LookupDocs(context.Background(), nil, LookupDocsInput{Query: "actor", MaxTokens: 40})
+	if err != nil {
+		t.Fatalf("LookupDocs: %v", err)
+	}
+	total := 0
+	for _, r := range out.Results {
+		total += len(r.Snippet)
+		if strings.HasSuffix(r.Snippet, "...") {
+			t.Errorf("chunk %q should be returned whole, not truncated", r.Heading)
+		}
+	}
+	if total > 40*4 {
+		t.Errorf("results use %d chars, budget is %d", total, 40*4)
+	}
+}
+
+func TestIndexDoc_ReplacesStaleChunks(t *testing.T) {
+	idx := createTestIndex(t)
+	if err := idx.IndexDoc(DocEntry{ID: "d", Title: "Doc", Source: "test", Content: chunkTestDoc}); err != nil {
+		t.Fatalf("IndexDoc: %v", err)
+	}
+	if err := idx.IndexDoc(DocEntry{ID: "d", Title: "Doc", Source: "test", Content: "Short replacement about widgets."}); err != nil {
+		t.Fatalf("IndexDoc: %v", err)
+	}
+
+	ids, err := idx.chunkIDs("d")
+	if err != nil {
+		t.Fatalf("chunkIDs: %v", err)
+	}
+	if len(ids) != 1 {
+		t.Errorf("expected 1 chunk after reindex, got %d: %v", len(ids), ids)
+	}
+	count, _ := idx.DocCount()
+	if count != 1 {
+		t.Errorf("DocCount = %d, want 1 (chunks not counted)", count)
+	}
+
+	_, out, err := idx.LookupDocs(context.Background(), nil, LookupDocsInput{Query: "BeginPlay"})
+	if err != nil {
+		t.Fatalf("LookupDocs: %v", err)
+	}
+	for _, r := range out.Results {
+		if strings.Contains(r.Snippet, "BeginPlay") {
+			t.Errorf("stale chunk still searchable: %q", r.Snippet)
+		}
+	}
+}
+
+func TestIndexBatch_ReplacesStaleChunks(t *testing.T) {
+	idx := createTestIndex(t)
+	long := []DocEntry{
+		{ID: "a", Title: "A", Source: "test", Content: chunkTestDoc},
+		{ID: "b", Title: "B", Source: "test", Content: chunkTestDoc},
+	}
+	if err := idx.IndexBatch(long); err != nil {
+		t.Fatalf("IndexBatch: %v", err)
+	}
+	ids, err := idx.chunkIDs("a", "b")
+	if err != nil {
+		t.Fatalf("chunkIDs: %v", err)
+	}
+	if len(ids) < 4 {
+		t.Fatalf("expected several chunks per doc, got %d: %v", len(ids), ids)
+	}
+
+	short := []DocEntry{
+		{ID: "a", Title: "A", Source: "test", Content: "Short replacement about widgets."},
+		{ID: "b", Title: "B", Source: "test", Content: "Short replacement about gadgets."},
+	}
+	if err := idx.IndexBatch(short); err != nil {
+		t.Fatalf("IndexBatch: %v", err)
+	}
+	ids, err = idx.chunkIDs("a", "b")
+	if err != nil {
+		t.Fatalf("chunkIDs: %v", err)
+	}
+	if len(ids) != 2 {
+		t.Errorf("expected 1 chunk per doc after reindex, got %d: %v", len(ids), ids)
+	}
+}
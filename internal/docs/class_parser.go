@@ -16,6 +16,7 @@ type ClassInfo struct {
 	KeyProps    []string `json:"key_properties,omitempty"`
 	KeyFuncs    []string `json:"key_functions,omitempty"`
 	Source      string   `json:"source,omitempty"`
+	Version     string   `json:"version,omitempty"`
 	URL         string   `json:"url,omitempty"`
 }
 
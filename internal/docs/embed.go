@@ -0,0 +1,179 @@
+// Copyright (c) mcp-unreal project contributors. Apache-2.0 license.
+
+package docs
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"hash/fnv"
+	"math"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// embeddingDim is the dimensionality of the hashed n-gram vectors. 512
+// buckets keeps collisions low for a corpus of a few thousand UE docs
+// while the stored vector stays under 3 KB per document.
+const embeddingDim = 512
+
+// embedMaxChars caps how much of a document is embedded. The opening of a
+// doc (title, summary, first sections) carries most of its meaning; long
+// guides would otherwise dilute the vector with boilerplate.
+const embedMaxChars = 8000
+
+// embedPrefix returns at most embedMaxChars bytes of text, cut back to
+// the start of a rune so a multi-byte character is never split.
+func embedPrefix(text string) string {
+	if len(text) <= embedMaxChars {
+		return text
+	}
+	n := embedMaxChars
+	for n > 0 && !utf8.RuneStart(text[n]) {
+		n--
+	}
+	return text[:n]
+}
+
+// stopWords are dropped before embedding so natural-language questions
+// ("how do I spawn an actor") are represented by their content words.
+var stopWords = map[string]bool{
+	"a": true, "an": true, "and": true, "are": true, "as": true, "at": true,
+	"be": true, "by": true, "can": true, "do": true, "does": true, "for": true,
+	"from": true, "how": true, "i": true, "in": true, "into": true, "is": true,
+	"it": true, "my": true, "of": true, "on": true, "or": true, "the": true,
+	"this": true, "to": true, "use": true, "what": true, "when": true,
+	"where": true, "which": true, "why": true, "with": true, "you": true,
+}
+
+// embed converts text into a fixed-size, L2-normalized vector using the
+// hashing trick over word unigrams, word bigrams, and character trigrams.
+// It needs no model files or network access, so it is deterministic and
+// identical at index time and query time.
+func embed(text string) []float32 {
+	text = embedPrefix(text)
+
+	vec := make([]float32, embeddingDim)
+	words := embedTokens(text)
+	for i, w := range words {
+		addFeature(vec, "w:"+w, 1.0)
+		if i > 0 {
+			addFeature(vec, "b:"+words[i-1]+" "+w, 0.5)
+		}
+		padded := "^" + w + "$"
+		for j := 0; j+3 <= len(padded); j++ {
+			addFeature(vec, "c:"+padded[j:j+3], 0.25)
+		}
+	}
+
+	normalize(vec)
+	return vec
+}
+
+// embedTokens lowercases text, splits it on non-alphanumerics and
+// CamelCase boundaries, and removes stop words. "SpawnActor" yields
+// "spawnactor", "spawn", and "actor" so identifiers match prose.
+func embedTokens(text string) []string {
+	var tokens []string
+	for _, field := range strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		parts := splitCamel(field)
+		lower := strings.ToLower(field)
+		if !stopWords[lower] {
+			tokens = append(tokens, lower)
+		}
+		if len(parts) > 1 {
+			for _, p := range parts {
+				p = strings.ToLower(p)
+				if len(p) > 1 && !stopWords[p] {
+					tokens = append(tokens, p)
+				}
+			}
+		}
+	}
+	return tokens
+}
+
+// splitCamel splits an identifier on lower→upper transitions, dropping a
+// leading UE type prefix (A, U, F, E, I) when it precedes another capital.
+func splitCamel(s string) []string {
+	runes := []rune(s)
+	if len(runes) > 2 && strings.ContainsRune("AUFEI", runes[0]) && unicode.IsUpper(runes[1]) && unicode.IsLower(runes[2]) {
+		runes = runes[1:]
+	}
+
+	var parts []string
+	start := 0
+	for i := 1; i < len(runes); i++ {
+		if unicode.IsUpper(runes[i]) && unicode.IsLower(runes[i-1]) {
+			parts = append(parts, string(runes[start:i]))
+			start = i
+		}
+	}
+	return append(parts, string(runes[start:]))
+}
+
+// addFeature hashes a feature into a bucket with a hash-derived sign,
+// which keeps collisions unbiased in expectation.
+func addFeature(vec []float32, feature string, weight float32) {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(feature))
+	sum := h.Sum32()
+	idx := sum % embeddingDim
+	if sum&(1<<31) != 0 {
+		weight = -weight
+	}
+	vec[idx] += weight
+}
+
+func normalize(vec []float32) {
+	var sum float64
+	for _, v := range vec {
+		sum += float64(v) * float64(v)
+	}
+	if sum == 0 {
+		return
+	}
+	inv := float32(1 / math.Sqrt(sum))
+	for i := range vec {
+		vec[i] *= inv
+	}
+}
+
+// cosine returns the cosine similarity of two normalized vectors.
+func cosine(a, b []float32) float64 {
+	if len(a) != len(b) {
+		return 0
+	}
+	var dot float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+	}
+	return dot
+}
+
+// encodeVector serializes a vector as base64 little-endian float32s so it
+// can be stored in a bleve text field.
+func encodeVector(vec []float32) string {
+	buf := make([]byte, 4*len(vec))
+	for i, v := range vec {
+		binary.LittleEndian.PutUint32(buf[4*i:], math.Float32bits(v))
+	}
+	return base64.StdEncoding.EncodeToString(buf)
+}
+
+// decodeVector reverses encodeVector. It returns nil for malformed input
+// or vectors of the wrong dimension (e.g. an index built by an older
+// version), which callers treat as "no semantic signal".
+func decodeVector(s string) []float32 {
+	buf, err := base64.StdEncoding.DecodeString(s)
+	if err != nil || len(buf) != 4*embeddingDim {
+		return nil
+	}
+	vec := make([]float32, embeddingDim)
+	for i := range vec {
+		vec[i] = math.Float32frombits(binary.LittleEndian.Uint32(buf[4*i:]))
+	}
+	return vec
+}
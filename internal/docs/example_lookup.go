@@ -0,0 +1,156 @@
+// Copyright (c) mcp-unreal project contributors. Apache-2.0 license.
+
+package docs
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/blevesearch/bleve/v2"
+	"github.com/blevesearch/bleve/v2/search/query"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/remiphilippe/mcp-unreal/internal/toolerr"
+)
+
+// --- lookup_examples tool ---
+
+// LookupExamplesInput defines parameters for the lookup_examples tool.
+type LookupExamplesInput struct {
+	Query      string `json:"query" jsonschema:"required,What the code should do, e.g. bind an Enhanced Input action in C++"`
+	Language   string `json:"language,omitempty" jsonschema:"Optional language filter: cpp, python, ini, bash"`
+	ClassName  string `json:"class_name,omitempty" jsonschema:"Optional: only snippets that use this UE class, e.g. UEnhancedInputComponent"`
+	Version    string `json:"version,omitempty" jsonschema:"Engine version, e.g. 5.5 or 5.7. Defaults to the project's EngineAssociation when docs for it are indexed."`
+	MaxResults int    `json:"max_results,omitempty" jsonschema:"Maximum snippets to return. Default 5, max 20."`
+}
+
+// ExampleResult is one code snippet returned by lookup_examples.
+type ExampleResult struct {
+	Title     string   `json:"title" jsonschema:"heading breadcrumb of the doc section, or Class::Function for project source"`
+	Language  string   `json:"language,omitempty" jsonschema:"snippet language, e.g. cpp"`
+	Code      string   `json:"code" jsonschema:"the code snippet"`
+	Context   string   `json:"context,omitempty" jsonschema:"prose that introduces the snippet in its doc"`
+	Origin    string   `json:"origin,omitempty" jsonschema:"file the snippet came from, with line number"`
+	Source    string   `json:"source" jsonschema:"doc source (ue5.7, realtimemesh, project)"`
+	Version   string   `json:"version,omitempty" jsonschema:"engine version the snippet targets, empty if version-agnostic"`
+	Classes   []string `json:"classes,omitempty" jsonschema:"UE classes the snippet uses"`
+	Functions []string `json:"functions,omitempty" jsonschema:"UE functions the snippet calls or defines"`
+	URL       string   `json:"url,omitempty" jsonschema:"original documentation URL if available"`
+	Score     float64  `json:"score" jsonschema:"relevance score"`
+}
+
+// LookupExamplesOutput is returned by the lookup_examples tool.
+type LookupExamplesOutput struct {
+	Results []ExampleResult `json:"results" jsonschema:"snippets ordered by relevance"`
+	Total   int             `json:"total" jsonschema:"number of snippets returned"`
+}
+
+// LookupExamples implements the lookup_examples tool.
+func (d *Index) LookupExamples(ctx context.Context, req *mcp.CallToolRequest, input LookupExamplesInput) (*mcp.CallToolResult, LookupExamplesOutput, error) {
+	if input.Query == "" {
+		return nil, LookupExamplesOutput{}, toolerr.New(toolerr.Validation, "query is required")
+	}
+	text := sanitizeQuery(input.Query)
+	if text == "" {
+		return nil, LookupExamplesOutput{}, toolerr.New(toolerr.Validation, "query has no searchable terms")
+	}
+
+	maxResults := input.MaxResults
+	if maxResults <= 0 {
+		maxResults = 5
+	}
+	if maxResults > 20 {
+		maxResults = 20
+	}
+
+	version, err := d.resolveVersion(input.Version)
+	if err != nil {
+		return nil, LookupExamplesOutput{}, err
+	}
+	language := normalizeLanguage(input.Language)
+
+	q := d.versionFilter(exampleQuery(text, language, input.ClassName), version)
+	accept := func(dv docVector) bool {
+		return dv.kind == kindExample &&
+			(language == "" || dv.language == language) &&
+			(input.ClassName == "" || containsString(dv.classes, input.ClassName)) &&
+			matchesVersion(dv.version, version)
+	}
+	ranked, err := d.hybridSearch(q, text, accept, maxResults)
+	if err != nil {
+		return nil, LookupExamplesOutput{}, toolerr.New(toolerr.DocsUnavailable, "search failed: %w", err)
+	}
+	if len(ranked) == 0 {
+		return nil, LookupExamplesOutput{Results: []ExampleResult{}}, nil
+	}
+
+	ids := make([]string, len(ranked))
+	for i, r := range ranked {
+		ids[i] = r.ID
+	}
+	docs, err := d.fetchDocs(ids, []string{"title", "language", "content", "section", "origin", "line",
+		"source", "version", "classes", "functions", "url"})
+	if err != nil {
+		return nil, LookupExamplesOutput{}, toolerr.New(toolerr.DocsUnavailable, "loading results: %w", err)
+	}
+
+	results := make([]ExampleResult, 0, len(ranked))
+	for _, r := range ranked {
+		fields, ok := docs[r.ID]
+		if !ok {
+			continue
+		}
+		origin := strField(fields, "origin")
+		if line, ok := fields["line"].(float64); ok && origin != "" && line > 0 {
+			origin = fmt.Sprintf("%s:%d", origin, int(line))
+		}
+		results = append(results, ExampleResult{
+			Title:     strField(fields, "title"),
+			Language:  strField(fields, "language"),
+			Code:      strField(fields, "content"),
+			Context:   strField(fields, "section"),
+			Origin:    origin,
+			Source:    strField(fields, "source"),
+			Version:   strField(fields, "version"),
+			Classes:   strSliceField(fields, "classes"),
+			Functions: strSliceField(fields, "functions"),
+			URL:       strField(fields, "url"),
+			Score:     r.Score,
+		})
+	}
+	return nil, LookupExamplesOutput{Results: results, Total: len(results)}, nil
+}
+
+// exampleQuery builds the BM25 query over examples: identifiers the
+// snippet uses (split into words) weigh most, then the section title,
+// the introducing prose, and the code itself.
+func exampleQuery(text, language, className string) query.Query {
+	symbols := bleve.NewMatchQuery(text)
+	symbols.SetField("symbols")
+	symbols.SetBoost(2.0)
+
+	title := bleve.NewMatchQuery(text)
+	title.SetField("title")
+	title.SetBoost(1.5)
+
+	prose := bleve.NewMatchQuery(text)
+	prose.SetField("section")
+
+	code := bleve.NewMatchQuery(text)
+	code.SetField("content")
+
+	kind := bleve.NewTermQuery(kindExample)
+	kind.SetField("kind")
+
+	conj := bleve.NewConjunctionQuery(kind, bleve.NewDisjunctionQuery(symbols, title, prose, code))
+	if language != "" {
+		lang := bleve.NewTermQuery(language)
+		lang.SetField("language")
+		conj.AddQuery(lang)
+	}
+	if className != "" {
+		class := bleve.NewTermQuery(className)
+		class.SetField("classes")
+		conj.AddQuery(class)
+	}
+	return conj
+}
@@ -0,0 +1,360 @@
+// Copyright (c) mcp-unreal project contributors. Apache-2.0 license.
+
+package docs
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+const (
+	// minExampleChars drops trivial code blocks (a lone identifier or
+	// path) that would only add noise to example search.
+	minExampleChars = 20
+
+	// maxExampleLines skips source functions too long to be useful as
+	// a snippet.
+	maxExampleLines = 80
+
+	// maxContextChars bounds the prose stored with a markdown example.
+	maxContextChars = 300
+)
+
+// Example is one code snippet in the example index: a fenced code block
+// from a markdown doc or a function from project source.
+type Example struct {
+	// Title is the heading breadcrumb of a markdown block, or
+	// Class::Function for source functions.
+	Title    string
+	Language string
+	Code     string
+	// Context is the prose right before a markdown block, describing it.
+	Context string
+	// Origin is the file the snippet came from and Line its first line.
+	Origin    string
+	Line      int
+	Source    string
+	Version   string
+	Category  string
+	Classes   []string
+	Functions []string
+}
+
+// extractCodeBlocks returns the fenced code blocks of a markdown document
+// with their heading breadcrumb, preceding prose, and 1-based line.
+func extractCodeBlocks(title, content string) []Example {
+	var examples []Example
+	trail := headingTrail{title: title}
+	var para []string // current prose paragraph
+	var context string
+	var code []string
+	lang, start := "", 0
+	inFence := false
+
+	for i, line := range strings.Split(content, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if isFence(trimmed) {
+			if !inFence {
+				inFence = true
+				lang = normalizeLanguage(strings.Trim(trimmed, "`~ "))
+				start = i + 2
+				if len(para) > 0 {
+					context = strings.Join(para, " ")
+				}
+				para = para[:0]
+				code = code[:0]
+				continue
+			}
+			inFence = false
+			text := strings.Trim(strings.Join(code, "\n"), "\n")
+			if len(strings.TrimSpace(text)) >= minExampleChars {
+				examples = append(examples, Example{
+					Title:    trail.String(),
+					Language: lang,
+					Code:     text,
+					Context:  truncateAtSentence(context, maxContextChars),
+					Line:     start,
+				})
+			}
+			context = ""
+			continue
+		}
+		if inFence {
+			code = append(code, line)
+			continue
+		}
+		if level, text := parseHeading(trimmed); level > 0 {
+			trail.push(level, text)
+			para, context = para[:0], ""
+			continue
+		}
+		if trimmed == "" {
+			if len(para) > 0 {
+				context = strings.Join(para, " ")
+				para = para[:0]
+			}
+			continue
+		}
+		para = append(para, trimmed)
+	}
+	return examples
+}
+
+// languageAliases maps fence info strings to a canonical language name.
+var languageAliases = map[string]string{
+	"c++": "cpp", "cc": "cpp", "cxx": "cpp", "h": "cpp", "hpp": "cpp",
+	"py": "python",
+	"sh": "bash", "shell": "bash", "zsh": "bash",
+	"cs": "csharp", "c#": "csharp",
+	"js": "javascript",
+	"ts": "typescript",
+}
+
+// normalizeLanguage canonicalizes a fence info string ("C++ title" →
+// "cpp"). Only the first word is the language.
+func normalizeLanguage(info string) string {
+	fields := strings.Fields(strings.ToLower(info))
+	if len(fields) == 0 {
+		return ""
+	}
+	if alias, ok := languageAliases[fields[0]]; ok {
+		return alias
+	}
+	return fields[0]
+}
+
+// ueFunctionRe matches UE-style function calls and definitions: a
+// capitalized identifier, optionally with template arguments, followed by
+// "(" — BindAction(, Cast<UFoo>(, Super::BeginPlay(.
+var ueFunctionRe = regexp.MustCompile(`\b([A-Z][A-Za-z0-9_]*[a-z][A-Za-z0-9_]*)\s*(?:<[^<>();]*>)?\s*\(`)
+
+// callKeywords may directly precede a call without making it a
+// declaration ("return Foo(").
+var callKeywords = map[string]bool{"return": true, "new": true, "else": true, "co_return": true, "throw": true}
+
+// extractFunctionNames finds the UE functions a snippet calls or defines.
+// Class names (constructors like FVector(...)), all-caps macros, and
+// variable declarations with constructor arguments ("FVector Loc(0.f)")
+// are excluded.
+func extractFunctionNames(code string) []string {
+	seen := make(map[string]bool)
+	var funcs []string
+	for _, m := range ueFunctionRe.FindAllStringSubmatchIndex(code, -1) {
+		name := code[m[2]:m[3]]
+		if seen[name] || (ueClassRe.MatchString(name) && isLikelyClassName(name)) {
+			continue
+		}
+		if prev := precedingWord(code[:m[2]]); prev != "" && !callKeywords[prev] {
+			continue
+		}
+		seen[name] = true
+		funcs = append(funcs, name)
+	}
+	return funcs
+}
+
+// precedingWord returns the identifier immediately before the end of s,
+// ignoring spaces, or "" if s ends with punctuation such as "->" or "(".
+func precedingWord(s string) string {
+	s = strings.TrimRight(s, " \t")
+	end := len(s)
+	start := end
+	for start > 0 {
+		c := s[start-1]
+		if c == '_' || c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' {
+			start--
+			continue
+		}
+		break
+	}
+	return s[start:end]
+}
+
+// exampleDocuments extracts the entry's code blocks as example field maps.
+func (e DocEntry) exampleDocuments() []map[string]any {
+	blocks := extractCodeBlocks(e.Title, e.Content)
+	docs := make([]map[string]any, 0, len(blocks))
+	for i, x := range blocks {
+		x.Origin = e.Path
+		x.Source, x.Version, x.Category = e.Source, e.Version, e.Category
+		x.Classes = extractClassNames(x.Code)
+		x.Functions = extractFunctionNames(x.Code)
+		docs = append(docs, x.document(exampleID(e.ID, i), e.ID, e.URL))
+	}
+	return docs
+}
+
+func exampleID(parent string, i int) string {
+	return fmt.Sprintf("%s!ex%d", parent, i)
+}
+
+// document converts the example into its index field map. The "symbols"
+// field holds the CamelCase-split class and function names so a question
+// like "bind input action" matches BindAction.
+func (x Example) document(id, parent, url string) map[string]any {
+	names := append(append([]string{}, x.Classes...), x.Functions...)
+	var words []string
+	for _, name := range names {
+		words = append(words, splitCamel(name)...)
+	}
+	symbols := strings.Join(names, " ")
+	return map[string]any{
+		"id":        id,
+		"kind":      kindExample,
+		"parent":    parent,
+		"title":     x.Title,
+		"section":   x.Context,
+		"category":  x.Category,
+		"source":    x.Source,
+		"version":   x.Version,
+		"language":  x.Language,
+		"content":   x.Code,
+		"classes":   x.Classes,
+		"functions": x.Functions,
+		"symbols":   strings.Join(words, " "),
+		"origin":    x.Origin,
+		"line":      x.Line,
+		"url":       url,
+		"vector":    encodeVector(embed(x.Title + "\n" + x.Context + "\n" + symbols + "\n" + x.Code)),
+	}
+}
+
+// IndexExamples adds standalone examples, such as functions from project
+// source, replacing any previously indexed examples from the same files.
+func (d *Index) IndexExamples(examples []Example) error {
+	d.invalidateCaches()
+	batch := d.index.NewBatch()
+	byOrigin := make(map[string]int)
+	for _, x := range examples {
+		parent := "src:" + fmt.Sprintf("%x", sha256.Sum256([]byte(x.Origin)))[:16]
+		n := byOrigin[parent]
+		if n == 0 {
+			stale, err := d.chunkIDs(parent)
+			if err != nil {
+				return fmt.Errorf("finding examples of %s: %w", x.Origin, err)
+			}
+			for _, id := range stale {
+				batch.Delete(id)
+			}
+		}
+		byOrigin[parent] = n + 1
+		if err := batch.Index(exampleID(parent, n), x.document(exampleID(parent, n), parent, "")); err != nil {
+			return fmt.Errorf("batching example from %s: %w", x.Origin, err)
+		}
+	}
+	return d.index.Batch(batch)
+}
+
+// sourceFunctionRe matches the first line of an out-of-class C++ member
+// function definition at column 0: "void AMyActor::BeginPlay()".
+var sourceFunctionRe = regexp.MustCompile(`^(?:[A-Za-z_][\w<>:,*& ]*?[\s*&]+)?(\w+)::(~?\w+)\s*\(`)
+
+// extractSourceExamples returns the member function definitions of a C++
+// source file as examples. Only functions up to maxExampleLines long are
+// kept; brace matching skips braces in string and char literals and in
+// line comments.
+func extractSourceExamples(path, content string) []Example {
+	lines := strings.Split(content, "\n")
+	var examples []Example
+	for i := 0; i < len(lines); i++ {
+		m := sourceFunctionRe.FindStringSubmatch(lines[i])
+		if m == nil || strings.HasSuffix(strings.TrimSpace(lines[i]), ";") {
+			continue
+		}
+		end := functionEnd(lines, i)
+		if end < 0 {
+			continue
+		}
+		if end-i+1 <= maxExampleLines {
+			code := strings.Join(lines[i:end+1], "\n")
+			examples = append(examples, Example{
+				Title:     m[1] + "::" + m[2],
+				Language:  "cpp",
+				Code:      code,
+				Origin:    path,
+				Line:      i + 1,
+				Classes:   extractClassNames(code),
+				Functions: extractFunctionNames(code),
+			})
+		}
+		i = end
+	}
+	return examples
+}
+
+// functionEnd returns the index of the line closing the function body
+// that starts at or after lines[start], or -1 if there is none.
+func functionEnd(lines []string, start int) int {
+	depth := 0
+	opened := false
+	for i := start; i < len(lines); i++ {
+		line := lines[i]
+		var quote byte
+		for j := 0; j < len(line); j++ {
+			c := line[j]
+			switch {
+			case quote != 0:
+				if c == '\\' {
+					j++
+				} else if c == quote {
+					quote = 0
+				}
+			case c == '"' || c == '\'':
+				quote = c
+			case c == '/' && j+1 < len(line) && line[j+1] == '/':
+				j = len(line)
+			case c == ';' && !opened:
+				return -1 // a declaration, not a definition
+			case c == '{':
+				depth++
+				opened = true
+			case c == '}':
+				depth--
+				if opened && depth == 0 {
+					return i
+				}
+			}
+		}
+	}
+	return -1
+}
+
+// IngestProjectSource indexes member function definitions from the .cpp
+// files under a project's Source directory as examples with source
+// "project".
+func IngestProjectSource(idx *Index, projectRoot string, logger *slog.Logger) (int, error) {
+	dir := filepath.Join(projectRoot, "Source")
+	var examples []Example
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() || strings.ToLower(filepath.Ext(path)) != ".cpp" {
+			return nil
+		}
+		data, err := os.ReadFile(path) //nolint:gosec // path from filepath.Walk within the project
+		if err != nil {
+			logger.Warn("skipping unreadable source file", "path", path, "error", err)
+			return nil
+		}
+		rel, _ := filepath.Rel(projectRoot, path)
+		for _, x := range extractSourceExamples(filepath.ToSlash(rel), string(data)) {
+			x.Source = "project"
+			x.Category = inferCategory(path, x.Code)
+			examples = append(examples, x)
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	if err := idx.IndexExamples(examples); err != nil {
+		return 0, err
+	}
+	return len(examples), nil
+}
@@ -0,0 +1,212 @@
+// Copyright (c) mcp-unreal project contributors. Apache-2.0 license.
+
+package docs
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const inputGuide = "# Enhanced Input\n\n" +
+	"Enhanced Input replaces the legacy input system.\n\n" +
+	"## Binding Actions\n\n" +
+	"Bind an input action to a handler in SetupPlayerInputComponent:\n\n" +
+	"```cpp\n" +
+	"void AMyCharacter::SetupPlayerInputComponent(UInputComponent* PlayerInputComponent)\n" +
+	"{\n" +
+	"    if (UEnhancedInputComponent* Input = Cast<UEnhancedInputComponent>(PlayerInputComponent))\n" +
+	"    {\n" +
+	"        Input->BindAction(JumpAction, ETriggerEvent::Started, this, &ACharacter::Jump);\n" +
+	"    }\n" +
+	"}\n" +
+	"```\n\n" +
+	"## Config\n\n" +
+	"```ini\n[/Script/Engine.InputSettings]\nDefaultPlayerInputClass=/Script/EnhancedInput.EnhancedPlayerInput\n```\n\n" +
+	"```\nshort\n```\n"
+
+func TestExtractCodeBlocks(t *testing.T) {
+	blocks := extractCodeBlocks("Enhanced Input", inputGuide)
+	if len(blocks) != 2 {
+		t.Fatalf("expected 2 blocks (trivial one skipped), got %d", len(blocks))
+	}
+
+	cpp := blocks[0]
+	if cpp.Language != "cpp" {
+		t.Errorf("language = %q, want cpp", cpp.Language)
+	}
+	if cpp.Title != "Enhanced Input > Binding Actions" {
+		t.Errorf("title = %q", cpp.Title)
+	}
+	if !strings.HasPrefix(cpp.Context, "Bind an input action") {
+		t.Errorf("context = %q, want the introducing paragraph", cpp.Context)
+	}
+	if cpp.Line != 10 {
+		t.Errorf("line = %d, want 10 (first line inside the fence)", cpp.Line)
+	}
+	if !strings.Contains(cpp.Code, "BindAction") || strings.Contains(cpp.Code, "```") {
+		t.Errorf("code should be the block body only: %q", cpp.Code)
+	}
+
+	if blocks[1].Language != "ini" || blocks[1].Context != "" {
+		t.Errorf("ini block = %+v", blocks[1])
+	}
+}
+
+func TestNormalizeLanguage(t *testing.T) {
+	tests := map[string]string{
+		"cpp":         "cpp",
+		"C++":         "cpp",
+		"cpp title=x": "cpp",
+		"py":          "python",
+		"ini":         "ini",
+		"":            "",
+	}
+	for in, want := range tests {
+		if got := normalizeLanguage(in); got != want {
+			t.Errorf("normalizeLanguage(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestExtractFunctionNames(t *testing.T) {
+	code := `Super::BeginPlay();
+auto* Comp = Cast<UEnhancedInputComponent>(Input);
+Comp->BindAction(Action, ETriggerEvent::Started, this, &AMy::Jump);
+FVector Loc(0.f);
+UE_LOG(LogTemp, Log, TEXT("hi"));
+check(Comp);`
+	got := extractFunctionNames(code)
+	want := []string{"BeginPlay", "Cast", "BindAction"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("extractFunctionNames = %v, want %v", got, want)
+	}
+}
+
+func TestExtractSourceExamples(t *testing.T) {
+	src := `#include "MyActor.h"
+
+AMyActor::AMyActor()
+{
+	PrimaryActorTick.bCanEverTick = true;
+}
+
+void AMyActor::BeginPlay()
+{
+	Super::BeginPlay();
+	const FString S = TEXT("}");
+	SetActorLocation(FVector::ZeroVector); // }
+}
+
+void FreeFunction()
+{
+}
+
+int32 AMyActor::Declared(int32 X);
+`
+	examples := extractSourceExamples("Source/My/MyActor.cpp", src)
+	if len(examples) != 2 {
+		t.Fatalf("expected 2 member functions, got %d: %+v", len(examples), examples)
+	}
+	if examples[0].Title != "AMyActor::AMyActor" || examples[0].Line != 3 {
+		t.Errorf("first example = %q at line %d", examples[0].Title, examples[0].Line)
+	}
+	begin := examples[1]
+	if begin.Title != "AMyActor::BeginPlay" {
+		t.Errorf("title = %q", begin.Title)
+	}
+	if !strings.HasSuffix(begin.Code, "}") || !strings.Contains(begin.Code, "SetActorLocation") {
+		t.Errorf("body should end at the closing brace despite braces in literals and comments:\n%s", begin.Code)
+	}
+	if !containsString(begin.Functions, "SetActorLocation") {
+		t.Errorf("functions = %v", begin.Functions)
+	}
+}
+
+func TestLookupExamples(t *testing.T) {
+	idx := createTestIndex(t)
+	entries := []DocEntry{
+		{ID: "input", Title: "Enhanced Input", Category: "input", Source: "ue5.7", Content: inputGuide, Path: "ue5.7/EnhancedInput.md"},
+		{ID: "actor", Title: "AActor", Category: "actor", Source: "ue5.7", Content: actorDoc57},
+	}
+	if err := idx.IndexBatch(entries); err != nil {
+		t.Fatalf("IndexBatch: %v", err)
+	}
+
+	_, out, err := idx.LookupExamples(t.Context(), nil, LookupExamplesInput{Query: "how do I bind an Enhanced Input action in C++"})
+	if err != nil {
+		t.Fatalf("LookupExamples: %v", err)
+	}
+	if out.Total == 0 {
+		t.Fatal("expected examples")
+	}
+	top := out.Results[0]
+	if top.Language != "cpp" || !strings.Contains(top.Code, "BindAction") {
+		t.Errorf("top result should be the C++ binding snippet, got %+v", top)
+	}
+	if top.Origin != "ue5.7/EnhancedInput.md:10" {
+		t.Errorf("origin = %q", top.Origin)
+	}
+	if !containsString(top.Classes, "UEnhancedInputComponent") {
+		t.Errorf("classes = %v", top.Classes)
+	}
+
+	_, out, err = idx.LookupExamples(t.Context(), nil, LookupExamplesInput{Query: "input", Language: "ini"})
+	if err != nil {
+		t.Fatalf("LookupExamples: %v", err)
+	}
+	for _, r := range out.Results {
+		if r.Language != "ini" {
+			t.Errorf("language filter leaked %q result", r.Language)
+		}
+	}
+
+	// Examples must not leak into lookup_docs or document counts.
+	_, docsOut, err := idx.LookupDocs(t.Context(), nil, LookupDocsInput{Query: "BindAction"})
+	if err != nil {
+		t.Fatalf("LookupDocs: %v", err)
+	}
+	for _, r := range docsOut.Results {
+		if r.Heading == "" {
+			t.Errorf("lookup_docs returned a non-chunk result: %+v", r)
+		}
+	}
+	if n, _ := idx.DocCount(); n != 2 {
+		t.Errorf("DocCount = %d, want 2", n)
+	}
+}
+
+func TestIngestProjectSource(t *testing.T) {
+	root := t.TempDir()
+	dir := filepath.Join(root, "Source", "MyGame")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	src := "void AMyPawn::SpawnProjectile()\n{\n\tGetWorld()->SpawnActor<AProjectile>(ProjectileClass, GetActorTransform());\n}\n"
+	if err := os.WriteFile(filepath.Join(dir, "MyPawn.cpp"), []byte(src), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	idx := createTestIndex(t)
+	n, err := IngestProjectSource(idx, root, testSlogLogger())
+	if err != nil || n != 1 {
+		t.Fatalf("IngestProjectSource = (%d, %v), want (1, nil)", n, err)
+	}
+	// Re-ingesting replaces rather than duplicates.
+	if _, err := IngestProjectSource(idx, root, testSlogLogger()); err != nil {
+		t.Fatalf("IngestProjectSource: %v", err)
+	}
+
+	_, out, err := idx.LookupExamples(t.Context(), nil, LookupExamplesInput{Query: "spawn actor projectile"})
+	if err != nil {
+		t.Fatalf("LookupExamples: %v", err)
+	}
+	if out.Total != 1 {
+		t.Fatalf("expected 1 example, got %d", out.Total)
+	}
+	if r := out.Results[0]; r.Source != "project" || r.Origin != "Source/MyGame/MyPawn.cpp:1" || r.Title != "AMyPawn::SpawnProjectile" {
+		t.Errorf("result = %+v", r)
+	}
+}
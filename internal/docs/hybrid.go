@@ -0,0 +1,233 @@
+// Copyright (c) mcp-unreal project contributors. Apache-2.0 license.
+
+package docs
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/blevesearch/bleve/v2"
+	"github.com/blevesearch/bleve/v2/registry"
+	"github.com/blevesearch/bleve/v2/search/highlight"
+	"github.com/blevesearch/bleve/v2/search/highlight/format/plain"
+	simplefrag "github.com/blevesearch/bleve/v2/search/highlight/fragmenter/simple"
+	simplehl "github.com/blevesearch/bleve/v2/search/highlight/highlighter/simple"
+	"github.com/blevesearch/bleve/v2/search/query"
+)
+
+// markdownHighlighter is the bleve highlight style used for lookup_docs.
+// It marks matched terms with markdown bold (**term**) instead of HTML,
+// which renders sensibly in agent transcripts.
+const markdownHighlighter = "mcp_markdown"
+
+func init() {
+	err := registry.RegisterHighlighter(markdownHighlighter, func(map[string]interface{}, *registry.Cache) (highlight.Highlighter, error) {
+		return simplehl.NewHighlighter(
+			simplefrag.NewFragmenter(200),
+			plain.NewFragmentFormatter("**", "**"),
+			simplehl.DefaultSeparator,
+		), nil
+	})
+	if err != nil {
+		panic(err)
+	}
+}
+
+// Hybrid ranking weights. Lexical (BM25) scores are normalized to [0,1]
+// against the best hit before blending, so the weights are comparable.
+const (
+	lexicalWeight  = 0.7
+	semanticWeight = 0.3
+
+	// minSemanticScore is the cosine similarity a document needs to be
+	// returned on semantic evidence alone (no lexical match).
+	minSemanticScore = 0.15
+
+	// lexicalCandidates is how many BM25 hits are re-ranked per query.
+	lexicalCandidates = 50
+)
+
+// scoredDoc is a document ID with its blended relevance score and any
+// highlighted content fragments from the lexical match.
+type scoredDoc struct {
+	ID        string
+	Score     float64
+	Fragments []string
+}
+
+// sanitizeQuery strips bleve query-string syntax (field prefixes, +/-
+// operators, quotes, wildcards, boosts) from user input, leaving plain
+// words. "spawn actor: location +rotation" becomes "spawn actor location
+// rotation". Underscores and dots are kept so identifiers survive.
+func sanitizeQuery(q string) string {
+	cleaned := strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' || r == '.' {
+			return r
+		}
+		return ' '
+	}, q)
+	return strings.Join(strings.Fields(cleaned), " ")
+}
+
+// lexicalQuery builds a BM25 query for sanitized text over title and
+// heading breadcrumb (boosted) and content, optionally restricted to a
+// category. Match queries analyze the text with the field's analyzer, so
+// no query-string parsing happens.
+func lexicalQuery(text, category string) query.Query {
+	title := bleve.NewMatchQuery(text)
+	title.SetField("title")
+	title.SetBoost(2.0)
+
+	heading := bleve.NewMatchQuery(text)
+	heading.SetField("section")
+	heading.SetBoost(1.5)
+
+	content := bleve.NewMatchQuery(text)
+	content.SetField("content")
+
+	var q query.Query = bleve.NewDisjunctionQuery(title, heading, content)
+	if category != "" {
+		cat := bleve.NewTermQuery(category)
+		cat.SetField("category")
+		q = bleve.NewConjunctionQuery(cat, q)
+	}
+	return q
+}
+
+// hybridSearch ranks documents by a weighted blend of normalized BM25
+// (from the lexical query, which carries all filters) and hashed n-gram
+// cosine similarity. accept applies the same filters to documents found
+// only semantically. It runs entirely against the local index — no
+// network calls at query time.
+func (d *Index) hybridSearch(lexical query.Query, text string, accept func(docVector) bool, size int) ([]scoredDoc, error) {
+	req := bleve.NewSearchRequest(lexical)
+	req.Size = lexicalCandidates
+	req.Highlight = bleve.NewHighlightWithStyle(markdownHighlighter)
+	req.Highlight.AddField("content")
+	result, err := d.index.Search(req)
+	if err != nil {
+		return nil, err
+	}
+
+	scores := make(map[string]float64, len(result.Hits))
+	fragments := make(map[string][]string)
+	maxLex := result.MaxScore
+	for _, hit := range result.Hits {
+		if maxLex > 0 {
+			scores[hit.ID] = lexicalWeight * hit.Score / maxLex
+		}
+		if frags := hit.Fragments["content"]; len(frags) > 0 {
+			fragments[hit.ID] = frags
+		}
+	}
+
+	vectors, err := d.loadVectors()
+	if err != nil {
+		return nil, err
+	}
+	qvec := embed(text)
+	for id, dv := range vectors {
+		if !accept(dv) {
+			continue
+		}
+		sim := cosine(qvec, dv.vec)
+		if _, lexical := scores[id]; lexical {
+			if sim > 0 {
+				scores[id] += semanticWeight * sim
+			}
+		} else if sim >= minSemanticScore {
+			scores[id] = semanticWeight * sim
+		}
+	}
+
+	ranked := make([]scoredDoc, 0, len(scores))
+	for id, score := range scores {
+		ranked = append(ranked, scoredDoc{ID: id, Score: score, Fragments: fragments[id]})
+	}
+	sort.Slice(ranked, func(i, j int) bool {
+		if ranked[i].Score != ranked[j].Score {
+			return ranked[i].Score > ranked[j].Score
+		}
+		return ranked[i].ID < ranked[j].ID
+	})
+	if len(ranked) > size {
+		ranked = ranked[:size]
+	}
+	return ranked, nil
+}
+
+// loadVectors returns the cached document embeddings, reading them from
+// the index's stored "vector" field on first use.
+func (d *Index) loadVectors() (map[string]docVector, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.vectors != nil {
+		return d.vectors, nil
+	}
+
+	count, err := d.index.DocCount()
+	if err != nil {
+		return nil, fmt.Errorf("counting docs: %w", err)
+	}
+
+	vectors := make(map[string]docVector, count)
+	if count > 0 {
+		req := bleve.NewSearchRequest(excludeKind(bleve.NewMatchAllQuery(), kindDoc))
+		req.Size = int(count)
+		req.Fields = []string{"vector", "kind", "category", "version", "language", "classes"}
+		result, err := d.index.Search(req)
+		if err != nil {
+			return nil, fmt.Errorf("loading doc vectors: %w", err)
+		}
+		for _, hit := range result.Hits {
+			vec := decodeVector(strField(hit.Fields, "vector"))
+			if vec == nil {
+				continue
+			}
+			vectors[hit.ID] = docVector{
+				vec:      vec,
+				kind:     strField(hit.Fields, "kind"),
+				category: strField(hit.Fields, "category"),
+				version:  strField(hit.Fields, "version"),
+				language: strField(hit.Fields, "language"),
+				classes:  strSliceField(hit.Fields, "classes"),
+			}
+		}
+	}
+
+	d.vectors = vectors
+	return vectors, nil
+}
+
+// matchesVersion reports whether a document of docVersion belongs in
+// results filtered to version; version-agnostic docs always do.
+func matchesVersion(docVersion, version string) bool {
+	return version == "" || docVersion == "" || docVersion == version
+}
+
+// invalidateCaches drops the embedding and version caches after the
+// index changes.
+func (d *Index) invalidateCaches() {
+	d.mu.Lock()
+	d.vectors = nil
+	d.versions = nil
+	d.mu.Unlock()
+}
+
+// fetchDocs loads the requested stored fields for a set of document IDs.
+func (d *Index) fetchDocs(ids []string, fields []string) (map[string]map[string]any, error) {
+	req := bleve.NewSearchRequest(bleve.NewDocIDQuery(ids))
+	req.Size = len(ids)
+	req.Fields = fields
+	result, err := d.index.Search(req)
+	if err != nil {
+		return nil, err
+	}
+	docs := make(map[string]map[string]any, len(result.Hits))
+	for _, hit := range result.Hits {
+		docs[hit.ID] = hit.Fields
+	}
+	return docs, nil
+}
@@ -0,0 +1,209 @@
+// Copyright (c) mcp-unreal project contributors. Apache-2.0 license.
+
+package docs
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestSanitizeQuery(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"spawn actor", "spawn actor"},
+		{"title:AActor", "title AActor"},
+		{"+spawn -destroy", "spawn destroy"},
+		{`"exact phrase"^2`, "exact phrase 2"},
+		{"C++ UPROPERTY(EditAnywhere)", "C UPROPERTY EditAnywhere"},
+		{"bTwoSided  OpacityMask_Clip", "bTwoSided OpacityMask_Clip"},
+		{"  :+*?  ", ""},
+	}
+	for _, tt := range tests {
+		if got := sanitizeQuery(tt.in); got != tt.want {
+			t.Errorf("sanitizeQuery(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestEmbed_Deterministic(t *testing.T) {
+	a := embed("SpawnActor creates a new actor in the world")
+	b := embed("SpawnActor creates a new actor in the world")
+	if sim := cosine(a, b); sim < 0.9999 {
+		t.Errorf("identical text should have cosine 1, got %f", sim)
+	}
+}
+
+func TestEmbed_RelatedTextIsCloser(t *testing.T) {
+	doc := embed("Niagara particle system asset containing one or more emitters")
+	related := embed("how do I make particle emitters")
+	unrelated := embed("material shading model blend mode")
+	if cosine(doc, related) <= cosine(doc, unrelated) {
+		t.Errorf("related query should score higher: related=%f unrelated=%f",
+			cosine(doc, related), cosine(doc, unrelated))
+	}
+}
+
+func TestEmbed_EmptyText(t *testing.T) {
+	vec := embed("")
+	if len(vec) != embeddingDim {
+		t.Fatalf("expected %d dims, got %d", embeddingDim, len(vec))
+	}
+	if cosine(vec, embed("actor")) != 0 {
+		t.Error("empty text should have zero similarity to anything")
+	}
+}
+
+func TestEmbedPrefix_RuneBoundary(t *testing.T) {
+	// "é" is two bytes, so the cap falls inside the last one.
+	text := strings.Repeat("a", embedMaxChars-1) + "éé"
+	got := embedPrefix(text)
+	if !utf8.ValidString(got) || len(got) != embedMaxChars-1 {
+		t.Errorf("embedPrefix cut to %d bytes, valid UTF-8 = %v; want %d valid bytes",
+			len(got), utf8.ValidString(got), embedMaxChars-1)
+	}
+	if short := "déjà vu"; embedPrefix(short) != short {
+		t.Errorf("embedPrefix(%q) = %q, want it unchanged", short, embedPrefix(short))
+	}
+}
+
+func TestSplitCamel(t *testing.T) {
+	tests := []struct {
+		in   string
+		want []string
+	}{
+		{"SpawnActor", []string{"Spawn", "Actor"}},
+		{"UCharacterMovementComponent", []string{"Character", "Movement", "Component"}},
+		{"actor", []string{"actor"}},
+		{"UI", []string{"UI"}},
+	}
+	for _, tt := range tests {
+		got := splitCamel(tt.in)
+		if len(got) != len(tt.want) {
+			t.Errorf("splitCamel(%q) = %v, want %v", tt.in, got, tt.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != tt.want[i] {
+				t.Errorf("splitCamel(%q) = %v, want %v", tt.in, got, tt.want)
+				break
+			}
+		}
+	}
+}
+
+func TestVectorRoundTrip(t *testing.T) {
+	vec := embed("UWorld SpawnActor")
+	got := decodeVector(encodeVector(vec))
+	if got == nil {
+		t.Fatal("decodeVector returned nil")
+	}
+	for i := range vec {
+		if got[i] != vec[i] {
+			t.Fatalf("dim %d: got %f, want %f", i, got[i], vec[i])
+		}
+	}
+	if decodeVector("not base64!") != nil {
+		t.Error("expected nil for malformed vector")
+	}
+	if decodeVector(encodeVector(vec[:10])) != nil {
+		t.Error("expected nil for wrong-dimension vector")
+	}
+}
+
+func TestLookupDocs_QuerySyntaxCharacters(t *testing.T) {
+	idx := createPopulatedIndex(t)
+
+	// These used to be parsed as bleve query-string syntax and fail.
+	queries := []string{
+		"AActor::SpawnActor",
+		"C++ SetActorLocation()",
+		"title:UMaterial +BlendMode",
+		"bTwoSided ~ OpacityMask",
+	}
+	for _, q := range queries {
+		_, out, err := idx.LookupDocs(context.Background(), nil, LookupDocsInput{Query: q})
+		if err != nil {
+			t.Errorf("LookupDocs(%q): %v", q, err)
+			continue
+		}
+		if out.Total == 0 {
+			t.Errorf("LookupDocs(%q): expected results", q)
+		}
+	}
+}
+
+func TestLookupDocs_OnlySyntaxCharacters(t *testing.T) {
+	idx := createPopulatedIndex(t)
+
+	_, _, err := idx.LookupDocs(context.Background(), nil, LookupDocsInput{Query: "+:*"})
+	if err == nil {
+		t.Error("expected error for query without searchable terms")
+	}
+}
+
+func TestLookupDocs_SemanticRecall(t *testing.T) {
+	idx := createTestIndex(t)
+	if err := idx.IndexBatch([]DocEntry{
+		{ID: "movement", Title: "UCharacterMovementComponent", Category: "actor", Source: "ue5.7",
+			Content: "Handles walking, falling, swimming and flying for characters. MaxWalkSpeed controls ground speed."},
+		{ID: "material", Title: "UMaterial", Category: "material", Source: "ue5.7",
+			Content: "Defines surface appearance through shading models."},
+	}); err != nil {
+		t.Fatalf("IndexBatch: %v", err)
+	}
+
+	// "walks" and "movement" share no BM25 term with the movement doc body
+	// (standard analyzer does not stem), but n-gram similarity links them
+	// through the CamelCase title and shared trigrams.
+	_, out, err := idx.LookupDocs(context.Background(), nil, LookupDocsInput{
+		Query: "character walks movement",
+	})
+	if err != nil {
+		t.Fatalf("LookupDocs: %v", err)
+	}
+	if out.Total == 0 || out.Results[0].Title != "UCharacterMovementComponent" {
+		t.Fatalf("expected UCharacterMovementComponent first, got %+v", out.Results)
+	}
+}
+
+func TestLookupDocs_SemanticRespectsCategory(t *testing.T) {
+	idx := createPopulatedIndex(t)
+
+	_, out, err := idx.LookupDocs(context.Background(), nil, LookupDocsInput{
+		Query:    "particle emitters",
+		Category: "animation",
+	})
+	if err != nil {
+		t.Fatalf("LookupDocs: %v", err)
+	}
+	for _, r := range out.Results {
+		if r.Category != "animation" {
+			t.Errorf("category filter leaked %s result %q", r.Category, r.Title)
+		}
+	}
+}
+
+func TestLookupDocs_VectorCacheInvalidated(t *testing.T) {
+	idx := createTestIndex(t)
+	if err := idx.IndexDoc(DocEntry{ID: "a", Title: "AActor", Source: "ue5.7", Content: "Base actor class."}); err != nil {
+		t.Fatalf("IndexDoc: %v", err)
+	}
+	if _, _, err := idx.LookupDocs(context.Background(), nil, LookupDocsInput{Query: "actor"}); err != nil {
+		t.Fatalf("LookupDocs: %v", err)
+	}
+
+	if err := idx.IndexDoc(DocEntry{ID: "b", Title: "UNiagaraSystem", Source: "ue5.7", Content: "Particle emitters."}); err != nil {
+		t.Fatalf("IndexDoc: %v", err)
+	}
+	vectors, err := idx.loadVectors()
+	if err != nil {
+		t.Fatalf("loadVectors: %v", err)
+	}
+	if len(vectors) != 2 {
+		t.Errorf("expected 2 cached vectors after reindex, got %d", len(vectors))
+	}
+}
@@ -10,11 +10,15 @@ package docs
 
 import (
 	"fmt"
+	"sort"
+	"strings"
+	"sync"
 
 	"github.com/blevesearch/bleve/v2"
 	"github.com/blevesearch/bleve/v2/analysis/analyzer/keyword"
 	"github.com/blevesearch/bleve/v2/analysis/analyzer/standard"
 	"github.com/blevesearch/bleve/v2/mapping"
+	"github.com/blevesearch/bleve/v2/search/query"
 )
 
 // DocEntry represents a single indexed document.
@@ -24,14 +28,38 @@ type DocEntry struct {
 	Title    string   `json:"title"`
 	Category string   `json:"category"` // actor, blueprint, material, animation, input, realtimemesh, gameplay, rendering, networking
 	Source   string   `json:"source"`   // ue5.7, realtimemesh, project
+	Version  string   `json:"version"`  // engine version (e.g. 5.7); empty for version-agnostic docs
 	Content  string   `json:"content"`
 	Classes  []string `json:"classes"` // related UE class names for cross-referencing
 	URL      string   `json:"url"`
+	Path     string   `json:"path,omitempty"` // origin file, reported with extracted code examples
 }
 
 // Index wraps a Bleve index for documentation search.
 type Index struct {
 	index bleve.Index
+
+	// vectors caches the stored embedding of every document, keyed by doc
+	// ID, for the semantic half of hybrid ranking. It is loaded lazily on
+	// the first query and dropped whenever documents are (re)indexed.
+	mu       sync.Mutex
+	vectors  map[string]docVector
+	versions []string
+
+	// defaultVersion is the engine version lookups filter to when the
+	// caller does not name one (normally the project's EngineAssociation).
+	defaultVersion string
+}
+
+// docVector is a cached document embedding plus the keyword fields needed
+// to apply lookup filters without fetching the stored document.
+type docVector struct {
+	vec      []float32
+	kind     string
+	category string
+	version  string
+	language string
+	classes  []string
 }
 
 // CreateIndex creates a new Bleve index at the given path with custom
@@ -70,30 +98,207 @@ func (d *Index) Close() error {
 	return d.index.Close()
 }
 
+// Document kinds stored in the "kind" field. Every DocEntry is stored
+// whole (for lookup_class), as heading-scoped chunks (for lookup_docs),
+// and as the code examples it contains (for lookup_examples).
+const (
+	kindDoc     = "doc"
+	kindChunk   = "chunk"
+	kindExample = "example"
+)
+
 // IndexDoc adds or updates a single document in the index.
 func (d *Index) IndexDoc(entry DocEntry) error {
-	return d.index.Index(entry.ID, entry)
+	return d.IndexBatch([]DocEntry{entry})
 }
 
 // IndexBatch adds multiple documents in a single batch operation.
+// Each entry is split into heading-scoped chunks and its fenced code
+// blocks are indexed as examples; chunks and examples left over from a
+// previous, longer version of the same document are removed.
 func (d *Index) IndexBatch(entries []DocEntry) error {
+	d.invalidateCaches()
+	parents := make([]string, len(entries))
+	for i, entry := range entries {
+		parents[i] = entry.ID
+	}
+	stale, err := d.chunkIDs(parents...)
+	if err != nil {
+		return fmt.Errorf("finding stale chunks: %w", err)
+	}
 	batch := d.index.NewBatch()
+	for _, id := range stale {
+		batch.Delete(id)
+	}
 	for _, entry := range entries {
-		if err := batch.Index(entry.ID, entry); err != nil {
+		if err := batch.Index(entry.ID, entry.document()); err != nil {
 			return fmt.Errorf("batching doc %s: %w", entry.ID, err)
 		}
+		for i, chunk := range entry.chunkDocuments() {
+			if err := batch.Index(chunkID(entry.ID, i), chunk); err != nil {
+				return fmt.Errorf("batching chunk %d of doc %s: %w", i, entry.ID, err)
+			}
+		}
+		for i, example := range entry.exampleDocuments() {
+			if err := batch.Index(exampleID(entry.ID, i), example); err != nil {
+				return fmt.Errorf("batching example %d of doc %s: %w", i, entry.ID, err)
+			}
+		}
 	}
 	return d.index.Batch(batch)
 }
 
-// DocCount returns the number of documents in the index.
+// document converts the entry into the whole-document field map.
+func (e DocEntry) document() map[string]any {
+	return map[string]any{
+		"id":       e.ID,
+		"kind":     kindDoc,
+		"title":    e.Title,
+		"category": e.Category,
+		"source":   e.Source,
+		"version":  e.Version,
+		"content":  e.Content,
+		"classes":  e.Classes,
+		"url":      e.URL,
+	}
+}
+
+// chunkDocuments splits the entry into heading-scoped chunk field maps,
+// each with the hashed n-gram embedding used for hybrid ranking. The
+// title and breadcrumb are embedded with the body so a chunk keeps the
+// meaning of the section it came from.
+func (e DocEntry) chunkDocuments() []map[string]any {
+	chunks := chunkMarkdown(e.Title, e.Content)
+	docs := make([]map[string]any, 0, len(chunks))
+	for i, c := range chunks {
+		docs = append(docs, map[string]any{
+			"id":       chunkID(e.ID, i),
+			"kind":     kindChunk,
+			"parent":   e.ID,
+			"position": i,
+			"title":    e.Title,
+			"heading":  c.Heading,
+			"section":  strings.TrimPrefix(strings.TrimPrefix(c.Heading, e.Title), breadcrumbSep),
+			"category": e.Category,
+			"source":   e.Source,
+			"version":  e.Version,
+			"content":  c.Text,
+			"classes":  e.Classes,
+			"url":      e.URL,
+			"vector":   encodeVector(embed(e.Title + "\n" + c.Heading + "\n" + c.Text)),
+		})
+	}
+	return docs
+}
+
+func chunkID(parent string, i int) string {
+	return fmt.Sprintf("%s#%d", parent, i)
+}
+
+// chunkIDs returns the IDs of all chunks and examples currently stored
+// for the given documents, using one query for all of them. An empty
+// index, as during a fresh ingest, has none and is not searched.
+func (d *Index) chunkIDs(parents ...string) ([]string, error) {
+	if len(parents) == 0 {
+		return nil, nil
+	}
+	if n, err := d.index.DocCount(); err != nil || n == 0 {
+		return nil, err
+	}
+	terms := make([]query.Query, len(parents))
+	for i, parent := range parents {
+		q := bleve.NewTermQuery(parent)
+		q.SetField("parent")
+		terms[i] = q
+	}
+	req := bleve.NewSearchRequest(bleve.NewDisjunctionQuery(terms...))
+	req.Size = 10000
+	req.SortBy([]string{"_id"})
+	var ids []string
+	for {
+		result, err := d.index.Search(req)
+		if err != nil {
+			return nil, err
+		}
+		for _, hit := range result.Hits {
+			ids = append(ids, hit.ID)
+		}
+		req.From += len(result.Hits)
+		if len(result.Hits) < req.Size || uint64(req.From) >= result.Total {
+			return ids, nil
+		}
+	}
+}
+
+// DocCount returns the number of documents in the index. Chunks and
+// examples are not counted — they are derived from their parent.
 func (d *Index) DocCount() (uint64, error) {
-	return d.index.DocCount()
+	req := bleve.NewSearchRequest(excludeKind(bleve.NewMatchAllQuery(), kindChunk, kindExample))
+	req.Size = 0
+	result, err := d.index.Search(req)
+	if err != nil {
+		return 0, err
+	}
+	return result.Total, nil
+}
+
+// SourceStat summarizes the indexed documents of one doc source.
+type SourceStat struct {
+	Name    string `json:"name" jsonschema:"source name, e.g. ue5.7 or project"`
+	Version string `json:"version,omitempty" jsonschema:"engine version the source describes, empty if version-agnostic"`
+	Docs    int    `json:"docs" jsonschema:"number of documents indexed from the source"`
+}
+
+// Sources returns per-source document counts, sorted by name.
+func (d *Index) Sources() ([]SourceStat, error) {
+	req := bleve.NewSearchRequest(excludeKind(bleve.NewMatchAllQuery(), kindChunk, kindExample))
+	req.Size = 0
+	req.AddFacet("source", bleve.NewFacetRequest("source", 100))
+	result, err := d.index.Search(req)
+	if err != nil {
+		return nil, fmt.Errorf("listing doc sources: %w", err)
+	}
+
+	stats := []SourceStat{}
+	facet, ok := result.Facets["source"]
+	if !ok || facet.Terms == nil {
+		return stats, nil
+	}
+	for _, t := range facet.Terms.Terms() {
+		stat := SourceStat{Name: t.Term, Docs: t.Count}
+
+		// A source's docs share one version; read it from any of them.
+		src := bleve.NewTermQuery(t.Term)
+		src.SetField("source")
+		vreq := bleve.NewSearchRequest(src)
+		vreq.Size = 1
+		vreq.Fields = []string{"version"}
+		if vres, err := d.index.Search(vreq); err == nil && len(vres.Hits) > 0 {
+			stat.Version = strField(vres.Hits[0].Fields, "version")
+		}
+		stats = append(stats, stat)
+	}
+	sort.Slice(stats, func(i, j int) bool { return stats[i].Name < stats[j].Name })
+	return stats, nil
+}
+
+// excludeKind wraps q so it never matches stored documents of the given
+// kinds. Indexes built before chunking have no kind field at all, so
+// exclusion (rather than requiring a kind) keeps them searchable.
+func excludeKind(q query.Query, kinds ...string) query.Query {
+	b := bleve.NewBooleanQuery()
+	b.AddMust(q)
+	for _, kind := range kinds {
+		k := bleve.NewTermQuery(kind)
+		k.SetField("kind")
+		b.AddMustNot(k)
+	}
+	return b
 }
 
 // buildIndexMapping creates the Bleve index mapping for DocEntry.
-// Text fields (title, content) use the standard analyzer for full-text search.
-// Keyword fields (category, source, classes) use exact-match for filtering.
+// Text fields (title, section, content) use the standard analyzer for full-text search.
+// Keyword fields (category, source, version, classes, kind, parent, language, functions) use exact-match for filtering.
 func buildIndexMapping() mapping.IndexMapping {
 	docMapping := bleve.NewDocumentMapping()
 
@@ -108,6 +313,19 @@ func buildIndexMapping() mapping.IndexMapping {
 	contentField.Store = true
 	docMapping.AddFieldMappingsAt("content", contentField)
 
+	// Heading is the display breadcrumb (stored only); section is the same
+	// breadcrumb minus the document title, analyzed for matching so a
+	// title hit is not counted twice.
+	headingField := bleve.NewTextFieldMapping()
+	headingField.Index = false
+	headingField.Store = true
+	headingField.IncludeInAll = false
+	docMapping.AddFieldMappingsAt("heading", headingField)
+
+	sectionField := bleve.NewTextFieldMapping()
+	sectionField.Analyzer = standard.Name
+	docMapping.AddFieldMappingsAt("section", sectionField)
+
 	// Keyword fields — exact match, stored for retrieval.
 	categoryField := bleve.NewTextFieldMapping()
 	categoryField.Analyzer = keyword.Name
@@ -119,17 +337,71 @@ func buildIndexMapping() mapping.IndexMapping {
 	sourceField.Store = true
 	docMapping.AddFieldMappingsAt("source", sourceField)
 
+	versionField := bleve.NewTextFieldMapping()
+	versionField.Analyzer = keyword.Name
+	versionField.Store = true
+	docMapping.AddFieldMappingsAt("version", versionField)
+
 	urlField := bleve.NewTextFieldMapping()
 	urlField.Analyzer = keyword.Name
 	urlField.Store = true
 	docMapping.AddFieldMappingsAt("url", urlField)
 
+	// Kind and parent — distinguish whole docs from their chunks.
+	kindField := bleve.NewTextFieldMapping()
+	kindField.Analyzer = keyword.Name
+	kindField.Store = true
+	docMapping.AddFieldMappingsAt("kind", kindField)
+
+	parentField := bleve.NewTextFieldMapping()
+	parentField.Analyzer = keyword.Name
+	parentField.Store = true
+	docMapping.AddFieldMappingsAt("parent", parentField)
+
+	positionField := bleve.NewNumericFieldMapping()
+	positionField.Store = true
+	docMapping.AddFieldMappingsAt("position", positionField)
+
+	// Example fields — language and functions filter exactly; symbols is
+	// the CamelCase-split identifiers, analyzed so prose queries match
+	// code; origin and line locate the snippet.
+	languageField := bleve.NewTextFieldMapping()
+	languageField.Analyzer = keyword.Name
+	languageField.Store = true
+	docMapping.AddFieldMappingsAt("language", languageField)
+
+	functionsField := bleve.NewTextFieldMapping()
+	functionsField.Analyzer = keyword.Name
+	functionsField.Store = true
+	docMapping.AddFieldMappingsAt("functions", functionsField)
+
+	symbolsField := bleve.NewTextFieldMapping()
+	symbolsField.Analyzer = standard.Name
+	docMapping.AddFieldMappingsAt("symbols", symbolsField)
+
+	originField := bleve.NewTextFieldMapping()
+	originField.Analyzer = keyword.Name
+	originField.Store = true
+	docMapping.AddFieldMappingsAt("origin", originField)
+
+	lineField := bleve.NewNumericFieldMapping()
+	lineField.Store = true
+	docMapping.AddFieldMappingsAt("line", lineField)
+
 	// Classes — keyword array for cross-referencing by class name.
 	classesField := bleve.NewTextFieldMapping()
 	classesField.Analyzer = keyword.Name
 	classesField.Store = true
 	docMapping.AddFieldMappingsAt("classes", classesField)
 
+	// Vector — stored only; semantic scoring happens in Go, not in bleve.
+	vectorField := bleve.NewTextFieldMapping()
+	vectorField.Index = false
+	vectorField.Store = true
+	vectorField.IncludeInAll = false
+	vectorField.IncludeTermVectors = false
+	docMapping.AddFieldMappingsAt("vector", vectorField)
+
 	indexMapping := bleve.NewIndexMapping()
 	indexMapping.DefaultMapping = docMapping
 
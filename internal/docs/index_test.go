@@ -9,6 +9,8 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+
+	"github.com/remiphilippe/mcp-unreal/internal/toolerr"
 )
 
 func createTestIndex(t *testing.T) *Index {
@@ -183,8 +185,8 @@ func TestLookupDocs_EmptyQuery(t *testing.T) {
 	_, _, err := idx.LookupDocs(context.Background(), nil, LookupDocsInput{
 		Query: "",
 	})
-	if err == nil {
-		t.Error("expected error for empty query")
+	if code := toolerr.CodeOf(err); code != toolerr.Validation {
+		t.Errorf("empty query: code = %s (%v), want %s", code, err, toolerr.Validation)
 	}
 }
 
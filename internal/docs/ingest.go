@@ -24,8 +24,28 @@ var skipFiles = map[string]bool{
 // IngestDirectory reads all markdown files from a directory tree and
 // indexes them into the given Index. Files in skipFiles are excluded.
 // The source parameter identifies where the docs came from (e.g.
-// "ue5.7", "realtimemesh", "project").
+// "ue5.7", "realtimemesh", "project"); engine sources named "ueX.Y" are
+// tagged with engine version X.Y.
 func IngestDirectory(idx *Index, dir, source string, logger *slog.Logger) (int, error) {
+	return IngestSource(idx, Source{Name: source, Dir: dir}, logger)
+}
+
+// IngestSource indexes the markdown docs of one source — a directory
+// tree, a single file, or a doc pack — tagging each document with the
+// source name, engine version, and default category.
+func IngestSource(idx *Index, src Source, logger *slog.Logger) (int, error) {
+	switch {
+	case src.File != "":
+		ok, err := ingestFile(idx, src.File, src)
+		if err != nil || !ok {
+			return 0, err
+		}
+		return 1, nil
+	case src.Pack != "":
+		return ingestPack(idx, src, logger)
+	}
+
+	dir := src.Dir
 	count := 0
 
 	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
@@ -50,7 +70,7 @@ func IngestDirectory(idx *Index, dir, source string, logger *slog.Logger) (int,
 			return nil
 		}
 
-		entry := parseMarkdownDoc(path, content, source)
+		entry := src.entry(path, content)
 		if err := idx.IndexDoc(entry); err != nil {
 			logger.Warn("failed to index doc", "path", path, "error", err)
 			return nil
@@ -66,19 +86,41 @@ func IngestDirectory(idx *Index, dir, source string, logger *slog.Logger) (int,
 
 // IngestFile indexes a single file (e.g. a project's CLAUDE.md).
 func IngestFile(idx *Index, path, source string) error {
+	_, err := ingestFile(idx, path, Source{Name: source, File: path})
+	return err
+}
+
+// ingestFile indexes one markdown file for src. It reports false without
+// error when the file is blank.
+func ingestFile(idx *Index, path string, src Source) (bool, error) {
 	cleanPath := filepath.Clean(path)
 	data, err := os.ReadFile(cleanPath) //nolint:gosec // caller provides trusted path
 	if err != nil {
-		return fmt.Errorf("reading %s: %w", cleanPath, err)
+		return false, fmt.Errorf("reading %s: %w", cleanPath, err)
 	}
 
 	content := string(data)
 	if strings.TrimSpace(content) == "" {
-		return nil
+		return false, nil
+	}
+
+	if err := idx.IndexDoc(src.entry(path, content)); err != nil {
+		return false, err
 	}
+	return true, nil
+}
 
-	entry := parseMarkdownDoc(path, content, source)
-	return idx.IndexDoc(entry)
+// entry builds the DocEntry for one file of the source, applying the
+// source's version and default category.
+func (s Source) entry(path, content string) DocEntry {
+	entry := parseMarkdownDoc(path, content, s.Name)
+	if s.Version != "" {
+		entry.Version = s.Version
+	}
+	if s.Category != "" && entry.Category == "general" {
+		entry.Category = s.Category
+	}
+	return entry
 }
 
 // parseMarkdownDoc extracts metadata from a markdown file's content
@@ -101,8 +143,10 @@ func parseMarkdownDoc(path, content, source string) DocEntry {
 		Title:    title,
 		Category: category,
 		Source:   source,
+		Version:  versionFromSourceName(source),
 		Content:  content,
 		Classes:  classes,
+		Path:     filepath.ToSlash(path),
 	}
 }
 
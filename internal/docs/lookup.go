@@ -4,12 +4,13 @@ package docs
 
 import (
 	"context"
-	"fmt"
 	"strings"
 
 	"github.com/blevesearch/bleve/v2"
 	"github.com/blevesearch/bleve/v2/search"
+	"github.com/blevesearch/bleve/v2/search/query"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/remiphilippe/mcp-unreal/internal/toolerr"
 )
 
 // --- lookup_docs tool ---
@@ -19,18 +20,33 @@ type LookupDocsInput struct {
 	Query     string `json:"query" jsonschema:"Natural language query about UE APIs, classes, or patterns"`
 	Category  string `json:"category,omitempty" jsonschema:"Optional filter: actor, blueprint, material, animation, input, realtimemesh, gameplay, rendering, networking"`
 	MaxTokens int    `json:"max_tokens,omitempty" jsonschema:"Max approximate tokens to return. Default 3000."`
+	Version   string `json:"version,omitempty" jsonschema:"Engine version to search, e.g. 5.5 or 5.7. Defaults to the project's EngineAssociation when docs for it are indexed."`
 }
 
-// DocResult represents a single search result.
+// DocResult represents a single search result: one heading-scoped chunk
+// of a document.
 type DocResult struct {
-	Title    string  `json:"title" jsonschema:"document title"`
-	Source   string  `json:"source" jsonschema:"document source (ue5.7, realtimemesh, project)"`
-	Category string  `json:"category" jsonschema:"document category"`
-	Snippet  string  `json:"snippet" jsonschema:"relevant content snippet"`
-	URL      string  `json:"url,omitempty" jsonschema:"original documentation URL if available"`
-	Score    float64 `json:"score" jsonschema:"relevance score"`
+	Title      string   `json:"title" jsonschema:"document title"`
+	Heading    string   `json:"heading,omitempty" jsonschema:"breadcrumb of headings enclosing the snippet, e.g. AActor > Key Functions"`
+	Source     string   `json:"source" jsonschema:"document source (ue5.7, realtimemesh, project)"`
+	Version    string   `json:"version,omitempty" jsonschema:"engine version the document describes, empty if version-agnostic"`
+	Category   string   `json:"category" jsonschema:"document category"`
+	Snippet    string   `json:"snippet" jsonschema:"the matching section of the document"`
+	Highlights []string `json:"highlights,omitempty" jsonschema:"short fragments with matched terms in **bold**"`
+	URL        string   `json:"url,omitempty" jsonschema:"original documentation URL if available"`
+	Score      float64  `json:"score" jsonschema:"relevance score"`
 }
 
+const (
+	// maxChunksPerDoc limits how many sections of one document are
+	// returned, so a single long guide cannot crowd out other sources.
+	maxChunksPerDoc = 3
+
+	// minPackChars is the smallest leftover budget worth filling with
+	// another chunk.
+	minPackChars = 80
+)
+
 // LookupDocsOutput is returned by the lookup_docs tool.
 type LookupDocsOutput struct {
 	Results []DocResult `json:"results" jsonschema:"search results ordered by relevance"`
@@ -42,6 +58,7 @@ type LookupDocsOutput struct {
 // LookupClassInput defines parameters for the lookup_class tool.
 type LookupClassInput struct {
 	ClassName string `json:"class_name" jsonschema:"UE class name, e.g. AActor, UCharacterMovementComponent, URealtimeMeshSimple"`
+	Version   string `json:"version,omitempty" jsonschema:"Engine version, e.g. 5.5 or 5.7. Defaults to the project's EngineAssociation when docs for it are indexed."`
 }
 
 // LookupClassOutput is returned by the lookup_class tool.
@@ -54,8 +71,10 @@ type LookupClassOutput struct {
 func (d *Index) Register(server *mcp.Server) {
 	mcp.AddTool(server, &mcp.Tool{
 		Name: "lookup_docs",
-		Description: "Search UE 5.7 API docs, RealtimeMesh docs, and project-specific docs. " +
-			"Returns relevant snippets with source references. " +
+		Description: "Search UE API docs (per engine version), RealtimeMesh docs, and project-specific docs. " +
+			"Accepts natural-language questions; results are ranked by a blend of keyword (BM25) " +
+			"and local semantic similarity. Returns the best-matching document sections with a " +
+			"heading breadcrumb, highlighted matches, and source references. " +
 			"Use this before writing code that uses UE APIs you are unsure about. " +
 			"Always available — does not require the editor to be running.",
 	}, d.LookupDocs)
@@ -64,16 +83,36 @@ func (d *Index) Register(server *mcp.Server) {
 		Name: "lookup_class",
 		Description: "Get the full class reference for a specific UE class: inheritance chain, " +
 			"key properties, key functions, and usage notes. " +
+			"Pass version to read a specific engine version's reference (defaults to the project's). " +
 			"Use this when you need detailed API information for a specific class. " +
 			"Always available — does not require the editor to be running.",
 	}, d.LookupClass)
+
+	mcp.AddTool(server, &mcp.Tool{
+		Name: "lookup_api_diff",
+		Description: "Compare a UE class's documented API between two engine versions: " +
+			"properties and functions added or removed from from_version to to_version. " +
+			"Use this when porting code between engine versions. " +
+			"Requires docs for both versions in the index (e.g. docs/ue5.5 and docs/ue5.7). " +
+			"Always available — does not require the editor to be running.",
+	}, d.LookupAPIDiff)
+
+	mcp.AddTool(server, &mcp.Tool{
+		Name: "lookup_examples",
+		Description: "Find working code snippets for a task, e.g. \"bind an Enhanced Input action in C++\". " +
+			"Searches fenced code blocks from the indexed docs and function definitions from the " +
+			"project's Source/ folder, ranked by the classes and functions they use. " +
+			"Returns code with its language and origin file. Filter by language or class_name. " +
+			"Use this instead of lookup_docs when you need code rather than API reference. " +
+			"Always available — does not require the editor to be running.",
+	}, d.LookupExamples)
 }
 
 // LookupDocs implements the lookup_docs tool.
 // See IMPLEMENTATION.md §4.4 for the design.
 func (d *Index) LookupDocs(ctx context.Context, req *mcp.CallToolRequest, input LookupDocsInput) (*mcp.CallToolResult, LookupDocsOutput, error) {
 	if input.Query == "" {
-		return nil, LookupDocsOutput{}, fmt.Errorf("query is required")
+		return nil, LookupDocsOutput{}, toolerr.New(toolerr.Validation, "query is required")
 	}
 
 	maxTokens := input.MaxTokens
@@ -81,44 +120,82 @@ func (d *Index) LookupDocs(ctx context.Context, req *mcp.CallToolRequest, input
 		maxTokens = 3000
 	}
 
-	queryStr := input.Query
-	if input.Category != "" {
-		queryStr = fmt.Sprintf("+category:%s %s", input.Category, queryStr)
+	text := sanitizeQuery(input.Query)
+	if text == "" {
+		return nil, LookupDocsOutput{}, toolerr.New(toolerr.Validation, "query has no searchable terms")
 	}
 
-	searchReq := bleve.NewSearchRequest(bleve.NewQueryStringQuery(queryStr))
-	searchReq.Size = 10
-	searchReq.Fields = []string{"title", "source", "category", "content", "url"}
+	version, err := d.resolveVersion(input.Version)
+	if err != nil {
+		return nil, LookupDocsOutput{}, err
+	}
 
-	result, err := d.index.Search(searchReq)
+	q := d.versionFilter(excludeKind(lexicalQuery(text, input.Category), kindDoc, kindExample), version)
+	accept := func(dv docVector) bool {
+		return dv.kind == kindChunk &&
+			(input.Category == "" || dv.category == input.Category) &&
+			matchesVersion(dv.version, version)
+	}
+	ranked, err := d.hybridSearch(q, text, accept, 20)
+	if err != nil {
+		return nil, LookupDocsOutput{}, toolerr.New(toolerr.DocsUnavailable, "search failed: %w", err)
+	}
+	if len(ranked) == 0 {
+		return nil, LookupDocsOutput{Results: []DocResult{}}, nil
+	}
+
+	ids := make([]string, len(ranked))
+	for i, r := range ranked {
+		ids[i] = r.ID
+	}
+	docs, err := d.fetchDocs(ids, []string{"title", "heading", "source", "version", "category", "content", "url", "parent"})
 	if err != nil {
-		return nil, LookupDocsOutput{}, fmt.Errorf("search failed: %w", err)
+		return nil, LookupDocsOutput{}, toolerr.New(toolerr.DocsUnavailable, "loading results: %w", err)
 	}
 
+	// Pack whole chunks into the budget in rank order (rough estimate:
+	// 1 token ~ 4 chars, IMPLEMENTATION.md §10). A chunk that does not fit
+	// is skipped in favour of smaller, lower-ranked ones; only when nothing
+	// has been returned yet is the top chunk cut, at a sentence boundary.
+	budget := maxTokens * 4
+	perDoc := make(map[string]int)
 	var results []DocResult
-	totalTokens := 0
-	for _, hit := range result.Hits {
-		content := strField(hit.Fields, "content")
-
-		// Rough token estimate: 1 token ~ 4 chars (IMPLEMENTATION.md §10: token budget).
-		tokens := len(content) / 4
-		if totalTokens+tokens > maxTokens {
-			remaining := (maxTokens - totalTokens) * 4
-			if remaining > 0 && len(content) > remaining {
-				content = content[:remaining] + "..."
-			} else {
-				break
+	for _, r := range ranked {
+		if budget < minPackChars && len(results) > 0 {
+			break
+		}
+		fields, ok := docs[r.ID]
+		if !ok {
+			continue
+		}
+		parent := strField(fields, "parent")
+		if parent == "" {
+			parent = r.ID
+		}
+		if perDoc[parent] >= maxChunksPerDoc {
+			continue
+		}
+
+		content := strField(fields, "content")
+		if len(content) > budget {
+			if len(results) > 0 {
+				continue
 			}
+			content = truncateAtSentence(content, budget)
 		}
-		totalTokens += tokens
+		budget -= len(content)
+		perDoc[parent]++
 
 		results = append(results, DocResult{
-			Title:    strField(hit.Fields, "title"),
-			Source:   strField(hit.Fields, "source"),
-			Category: strField(hit.Fields, "category"),
-			Snippet:  content,
-			URL:      strField(hit.Fields, "url"),
-			Score:    hit.Score,
+			Title:      strField(fields, "title"),
+			Heading:    strField(fields, "heading"),
+			Source:     strField(fields, "source"),
+			Version:    strField(fields, "version"),
+			Category:   strField(fields, "category"),
+			Snippet:    content,
+			Highlights: r.Fragments,
+			URL:        strField(fields, "url"),
+			Score:      r.Score,
 		})
 	}
 
@@ -128,60 +205,60 @@ func (d *Index) LookupDocs(ctx context.Context, req *mcp.CallToolRequest, input
 // LookupClass implements the lookup_class tool.
 func (d *Index) LookupClass(ctx context.Context, req *mcp.CallToolRequest, input LookupClassInput) (*mcp.CallToolResult, LookupClassOutput, error) {
 	if input.ClassName == "" {
-		return nil, LookupClassOutput{}, fmt.Errorf("class_name is required")
+		return nil, LookupClassOutput{}, toolerr.New(toolerr.Validation, "class_name is required")
+	}
+
+	version, err := d.resolveVersion(input.Version)
+	if err != nil {
+		return nil, LookupClassOutput{}, err
+	}
+
+	info, found := d.findClass(input.ClassName, version)
+	return nil, LookupClassOutput{Found: found, Class: info}, nil
+}
+
+// findClass locates the reference doc for a class, restricted to an
+// engine version when one is given.
+func (d *Index) findClass(className, version string) (ClassInfo, bool) {
+	find := func(q query.Query, size int) *bleve.SearchResult {
+		req := bleve.NewSearchRequest(d.versionFilter(excludeKind(q, kindChunk, kindExample), version))
+		req.Size = size
+		req.Fields = []string{"title", "source", "version", "content", "url", "classes"}
+		result, err := d.index.Search(req)
+		if err != nil || result.Total == 0 {
+			return nil
+		}
+		return result
 	}
 
 	// First try: exact title match — the most authoritative doc for a class
 	// is the one titled with the class name itself.
-	titleQuery := fmt.Sprintf("+title:%s", input.ClassName)
-	titleReq := bleve.NewSearchRequest(bleve.NewQueryStringQuery(titleQuery))
-	titleReq.Size = 5
-	titleReq.Fields = []string{"title", "source", "content", "url", "classes"}
-
-	result, err := d.index.Search(titleReq)
-	if err == nil && result.Total > 0 {
-		// Prefer the hit whose title exactly matches the class name.
-		hit := pickBestHit(result, input.ClassName)
-		content := strField(hit.Fields, "content")
-		info := ParseClassDoc(input.ClassName, content)
-		info.Source = strField(hit.Fields, "source")
-		info.URL = strField(hit.Fields, "url")
-		return nil, LookupClassOutput{Found: true, Class: info}, nil
-	}
+	titleQuery := bleve.NewMatchQuery(className)
+	titleQuery.SetField("title")
+	result := find(titleQuery, 5)
 
 	// Second try: search by classes field — finds docs that reference this class.
-	query := fmt.Sprintf("+classes:%s", input.ClassName)
-	searchReq := bleve.NewSearchRequest(bleve.NewQueryStringQuery(query))
-	searchReq.Size = 10
-	searchReq.Fields = []string{"title", "source", "content", "url", "classes"}
-
-	result, err = d.index.Search(searchReq)
-	if err == nil && result.Total > 0 {
-		hit := pickBestHit(result, input.ClassName)
-		content := strField(hit.Fields, "content")
-		info := ParseClassDoc(input.ClassName, content)
-		info.Source = strField(hit.Fields, "source")
-		info.URL = strField(hit.Fields, "url")
-		return nil, LookupClassOutput{Found: true, Class: info}, nil
+	if result == nil {
+		classQuery := bleve.NewTermQuery(className)
+		classQuery.SetField("classes")
+		result = find(classQuery, 10)
 	}
 
 	// Fallback: try a content search for the class name.
-	fallbackQuery := bleve.NewSearchRequest(bleve.NewQueryStringQuery(input.ClassName))
-	fallbackQuery.Size = 5
-	fallbackQuery.Fields = []string{"title", "source", "content", "url"}
-
-	result, err = d.index.Search(fallbackQuery)
-	if err != nil || result.Total == 0 {
-		return nil, LookupClassOutput{Found: false}, nil
+	if result == nil {
+		result = find(lexicalQuery(sanitizeQuery(className), ""), 5)
+	}
+	if result == nil {
+		return ClassInfo{}, false
 	}
 
-	hit := pickBestHit(result, input.ClassName)
-	content := strField(hit.Fields, "content")
-	info := ParseClassDoc(input.ClassName, content)
+	// Prefer the hit whose title exactly matches the class name.
+	hit := pickBestHit(result, className)
+	info := ParseClassDoc(className, strField(hit.Fields, "content"))
 	info.Source = strField(hit.Fields, "source")
+	info.Version = strField(hit.Fields, "version")
 	info.URL = strField(hit.Fields, "url")
-
-	return nil, LookupClassOutput{Found: true, Class: info}, nil
+	return info, true
 }
 
 // pickBestHit selects the most relevant hit for a class lookup.
@@ -203,3 +280,21 @@ func strField(fields map[string]interface{}, key string) string {
 	}
 	return ""
 }
+
+// strSliceField reads a stored array field. Bleve returns a single-value
+// array as a plain string.
+func strSliceField(fields map[string]interface{}, key string) []string {
+	switch v := fields[key].(type) {
+	case string:
+		return []string{v}
+	case []interface{}:
+		out := make([]string, 0, len(v))
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				out = append(out, s)
+			}
+		}
+		return out
+	}
+	return nil
+}
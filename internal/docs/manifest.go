@@ -0,0 +1,172 @@
+// Copyright (c) mcp-unreal project contributors. Apache-2.0 license.
+
+package docs
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ManifestFiles are the names of the optional file in the docs root that
+// lists doc sources explicitly instead of relying on directory discovery.
+// The first one present is used.
+var ManifestFiles = []string{"manifest.json", "manifest.yaml", "manifest.yml"}
+
+// Source is a named set of markdown docs ingested into the index. Exactly
+// one of Dir, File, or Pack is set.
+type Source struct {
+	// Name identifies the source in results (e.g. "ue5.7", "realtimemesh").
+	Name string `json:"name"`
+	// Dir is a directory whose markdown files are ingested recursively.
+	Dir string `json:"dir,omitempty"`
+	// File is a single markdown file, e.g. a project's CLAUDE.md.
+	File string `json:"file,omitempty"`
+	// Pack is a .tar or .tar.gz doc pack, as a local path or an
+	// http(s) URL downloaded at build time.
+	Pack string `json:"pack,omitempty"`
+	// SHA256 is the expected hex digest of a downloaded pack. Optional,
+	// but recommended for URLs.
+	SHA256 string `json:"sha256,omitempty"`
+	// Version is the engine version the docs describe. Empty means the
+	// docs apply to every engine version. Defaults to X.Y for sources
+	// named "ueX.Y".
+	Version string `json:"version,omitempty"`
+	// Category is used for docs whose category cannot be inferred from
+	// their path or content (which would otherwise be "general").
+	Category string `json:"category,omitempty"`
+}
+
+// Location returns the directory, file, or pack the source reads from.
+func (s Source) Location() string {
+	switch {
+	case s.File != "":
+		return s.File
+	case s.Pack != "":
+		return s.Pack
+	default:
+		return s.Dir
+	}
+}
+
+// IsRemote reports whether the source is a pack downloaded over HTTP.
+func (s Source) IsRemote() bool {
+	return isURL(s.Pack)
+}
+
+// Manifest lists doc sources for --build-index.
+type Manifest struct {
+	Sources []Source `json:"sources"`
+}
+
+// LoadManifest reads a manifest file and validates its sources. Files
+// ending in .yaml or .yml are YAML, others JSON. Relative paths are
+// resolved against the manifest's directory and versions are defaulted
+// from source names.
+func LoadManifest(path string) ([]Source, error) {
+	data, err := os.ReadFile(path) //nolint:gosec // manifest path is operator-configured
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", path, err)
+	}
+	if ext := strings.ToLower(filepath.Ext(path)); ext == ".yaml" || ext == ".yml" {
+		// A JSON round trip gives YAML manifests the same fields and
+		// types as JSON ones.
+		var raw any
+		if err := yaml.Unmarshal(data, &raw); err != nil {
+			return nil, fmt.Errorf("parsing %s: %w", path, err)
+		}
+		if data, err = json.Marshal(raw); err != nil {
+			return nil, fmt.Errorf("parsing %s: %w", path, err)
+		}
+	}
+	var m Manifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+
+	base := filepath.Dir(path)
+	sources := make([]Source, 0, len(m.Sources))
+	for i, src := range m.Sources {
+		if src.Name == "" {
+			return nil, fmt.Errorf("%s: source %d has no name", path, i)
+		}
+		set := 0
+		for _, loc := range []string{src.Dir, src.File, src.Pack} {
+			if loc != "" {
+				set++
+			}
+		}
+		if set != 1 {
+			return nil, fmt.Errorf("%s: source %q needs exactly one of dir, file, or pack", path, src.Name)
+		}
+		src.Dir = resolvePath(base, src.Dir)
+		src.File = resolvePath(base, src.File)
+		if !isURL(src.Pack) {
+			src.Pack = resolvePath(base, src.Pack)
+		}
+		if src.Version == "" {
+			src.Version = versionFromSourceName(src.Name)
+		}
+		sources = append(sources, src)
+	}
+	return sources, nil
+}
+
+// DiscoverSources returns the doc sources under root. If root contains a
+// manifest (see ManifestFiles) its sources are used as listed; otherwise every
+// subdirectory (except hidden ones and bleve indexes) is a source named
+// after the directory, so adding docs/ue5.5 next to docs/ue5.7 is enough
+// to index a second engine version.
+func DiscoverSources(root string) ([]Source, error) {
+	for _, name := range ManifestFiles {
+		manifestPath := filepath.Join(root, name)
+		if _, err := os.Stat(manifestPath); err == nil {
+			return LoadManifest(manifestPath)
+		} else if !os.IsNotExist(err) {
+			return nil, fmt.Errorf("reading %s: %w", manifestPath, err)
+		}
+	}
+
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		return nil, fmt.Errorf("reading docs root %s: %w", root, err)
+	}
+	var sources []Source
+	for _, e := range entries {
+		name := e.Name()
+		if !e.IsDir() || strings.HasPrefix(name, ".") || strings.HasSuffix(name, ".bleve") {
+			continue
+		}
+		sources = append(sources, Source{
+			Name:    name,
+			Dir:     filepath.Join(root, name),
+			Version: versionFromSourceName(name),
+		})
+	}
+	return sources, nil
+}
+
+// resolvePath makes a manifest path absolute relative to base, expanding
+// a leading "~/" to the user's home directory.
+func resolvePath(base, p string) string {
+	if p == "" {
+		return ""
+	}
+	if strings.HasPrefix(p, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			p = filepath.Join(home, p[2:])
+		}
+	}
+	if !filepath.IsAbs(p) {
+		p = filepath.Join(base, p)
+	}
+	return filepath.Clean(p)
+}
+
+func isURL(s string) bool {
+	return strings.HasPrefix(s, "https://") || strings.HasPrefix(s, "http://")
+}
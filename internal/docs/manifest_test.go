@@ -0,0 +1,212 @@
+// Copyright (c) mcp-unreal project contributors. Apache-2.0 license.
+
+package docs
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// buildPack returns a gzipped tarball containing the given files.
+func buildPack(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for name, body := range files {
+		hdr := &tar.Header{Name: name, Mode: 0o644, Size: int64(len(body)), Typeflag: tar.TypeReg}
+		if err := tw.WriteHeader(hdr); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(body)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestLoadManifest(t *testing.T) {
+	manifests := map[string]string{
+		"docs.json": `{"sources": [
+			{"name": "studio", "dir": "studio-docs", "category": "gameplay"},
+			{"name": "project", "file": "../CLAUDE.md"},
+			{"name": "ue5.6", "pack": "packs/ue5.6.tar.gz"},
+			{"name": "plugin", "pack": "https://example.com/plugin-docs.tgz", "sha256": "abc"}
+		]}`,
+		"docs.yaml": `
+sources:
+  - {name: studio, dir: studio-docs, category: gameplay}
+  - name: project
+    file: ../CLAUDE.md
+  - name: ue5.6
+    pack: packs/ue5.6.tar.gz
+  - name: plugin
+    pack: https://example.com/plugin-docs.tgz
+    sha256: abc
+`,
+		"docs.yml": `sources: [{name: studio, dir: studio-docs, category: gameplay}, {name: project, file: ../CLAUDE.md},
+  {name: ue5.6, pack: packs/ue5.6.tar.gz}, {name: plugin, pack: "https://example.com/plugin-docs.tgz", sha256: abc}]`,
+	}
+	for file, manifest := range manifests {
+		t.Run(file, func(t *testing.T) {
+			dir := t.TempDir()
+			path := filepath.Join(dir, file)
+			if err := os.WriteFile(path, []byte(manifest), 0o644); err != nil {
+				t.Fatal(err)
+			}
+
+			sources, err := LoadManifest(path)
+			if err != nil {
+				t.Fatalf("LoadManifest: %v", err)
+			}
+			want := []Source{
+				{Name: "studio", Dir: filepath.Join(dir, "studio-docs"), Category: "gameplay"},
+				{Name: "project", File: filepath.Join(filepath.Dir(dir), "CLAUDE.md")},
+				{Name: "ue5.6", Pack: filepath.Join(dir, "packs/ue5.6.tar.gz"), Version: "5.6"},
+				{Name: "plugin", Pack: "https://example.com/plugin-docs.tgz", SHA256: "abc"},
+			}
+			if !reflect.DeepEqual(sources, want) {
+				t.Errorf("sources =\n%+v\nwant\n%+v", sources, want)
+			}
+			if !sources[3].IsRemote() || sources[2].IsRemote() {
+				t.Error("IsRemote should be true only for URL packs")
+			}
+		})
+	}
+}
+
+func TestLoadManifest_Invalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		file     string
+		manifest string
+	}{
+		{"no name", "docs.json", `{"sources": [{"dir": "a"}]}`},
+		{"no location", "docs.json", `{"sources": [{"name": "a"}]}`},
+		{"two locations", "docs.json", `{"sources": [{"name": "a", "dir": "a", "file": "b.md"}]}`},
+		{"bad json", "docs.json", `{"sources": [`},
+		{"yaml in json file", "docs.json", "sources:\n  - {name: a, dir: a}"},
+		{"bad yaml", "docs.yaml", "sources:\n  - name: a\n dir: a"},
+		{"no location yaml", "docs.yml", "sources: [{name: a}]"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), tt.file)
+			if err := os.WriteFile(path, []byte(tt.manifest), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := LoadManifest(path); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
+
+func TestIngestSource_FileWithDefaultCategory(t *testing.T) {
+	idx := createTestIndex(t)
+	path := filepath.Join(t.TempDir(), "CLAUDE.md")
+	if err := os.WriteFile(path, []byte("# Studio Conventions\n\nPrefix every module with Studio."), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	n, err := IngestSource(idx, Source{Name: "project", File: path, Category: "conventions"}, testSlogLogger())
+	if err != nil {
+		t.Fatalf("IngestSource: %v", err)
+	}
+	if n != 1 {
+		t.Fatalf("count = %d, want 1", n)
+	}
+	_, out, err := idx.LookupDocs(t.Context(), nil, LookupDocsInput{Query: "studio conventions", Category: "conventions"})
+	if err != nil {
+		t.Fatalf("LookupDocs: %v", err)
+	}
+	if out.Total == 0 || out.Results[0].Source != "project" {
+		t.Errorf("expected project doc under default category, got %+v", out.Results)
+	}
+}
+
+func TestIngestSource_Pack(t *testing.T) {
+	pack := buildPack(t, map[string]string{
+		"pack/AActor.md":           "# AActor\n\nBase class for spawned actors.",
+		"pack/guides/Widgets.md":   "# Widgets\n\nUMG widget guide.",
+		"pack/README.md":           "# Readme\n\nNot documentation.",
+		"pack/image.png":           "binary",
+		"../escape/Outside.md":     "# Outside\n\nShould be skipped.",
+		"pack/empty/Whitespace.md": "   \n",
+	})
+	path := filepath.Join(t.TempDir(), "ue5.6.tar.gz")
+	if err := os.WriteFile(path, pack, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	idx := createTestIndex(t)
+	n, err := IngestSource(idx, Source{Name: "ue5.6", Pack: path, Version: "5.6"}, testSlogLogger())
+	if err != nil {
+		t.Fatalf("IngestSource: %v", err)
+	}
+	if n != 2 {
+		t.Errorf("count = %d, want 2 (AActor, Widgets)", n)
+	}
+
+	_, out, err := idx.LookupClass(t.Context(), nil, LookupClassInput{ClassName: "AActor", Version: "5.6"})
+	if err != nil {
+		t.Fatalf("LookupClass: %v", err)
+	}
+	if !out.Found || out.Class.Source != "ue5.6" {
+		t.Errorf("expected AActor from pack, got %+v", out)
+	}
+}
+
+func TestIngestSource_RemotePackChecksum(t *testing.T) {
+	pack := buildPack(t, map[string]string{"Plugin.md": "# Plugin\n\nPlugin docs."})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write(pack)
+	}))
+	defer srv.Close()
+
+	sum := sha256.Sum256(pack)
+	good := hex.EncodeToString(sum[:])
+
+	idx := createTestIndex(t)
+	n, err := IngestSource(idx, Source{Name: "plugin", Pack: srv.URL + "/plugin.tgz", SHA256: good}, testSlogLogger())
+	if err != nil || n != 1 {
+		t.Fatalf("IngestSource = (%d, %v), want (1, nil)", n, err)
+	}
+
+	_, err = IngestSource(idx, Source{Name: "plugin", Pack: srv.URL + "/plugin.tgz", SHA256: strings.Repeat("0", 64)}, testSlogLogger())
+	if err == nil || !strings.Contains(err.Error(), "sha256 mismatch") {
+		t.Errorf("expected checksum error, got %v", err)
+	}
+}
+
+func TestSources(t *testing.T) {
+	idx := createVersionedIndex(t)
+	stats, err := idx.Sources()
+	if err != nil {
+		t.Fatalf("Sources: %v", err)
+	}
+	want := []SourceStat{
+		{Name: "realtimemesh", Docs: 1},
+		{Name: "ue5.5", Version: "5.5", Docs: 1},
+		{Name: "ue5.7", Version: "5.7", Docs: 1},
+	}
+	if !reflect.DeepEqual(stats, want) {
+		t.Errorf("Sources = %+v, want %+v", stats, want)
+	}
+}
@@ -0,0 +1,140 @@
+// Copyright (c) mcp-unreal project contributors. Apache-2.0 license.
+
+package docs
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"path"
+	"strings"
+	"time"
+)
+
+const (
+	// maxPackBytes caps the size of a doc pack, compressed.
+	maxPackBytes = 256 << 20
+
+	// maxPackFileBytes caps a single markdown file inside a pack; larger
+	// entries are skipped.
+	maxPackFileBytes = 4 << 20
+
+	// packDownloadTimeout bounds fetching a remote pack.
+	packDownloadTimeout = 5 * time.Minute
+)
+
+// ingestPack indexes the markdown files inside a .tar or .tar.gz doc
+// pack. Entries are read straight from the archive; nothing is extracted
+// to disk.
+func ingestPack(idx *Index, src Source, logger *slog.Logger) (int, error) {
+	data, err := readPack(src)
+	if err != nil {
+		return 0, err
+	}
+	if src.SHA256 != "" {
+		sum := sha256.Sum256(data)
+		if got := hex.EncodeToString(sum[:]); !strings.EqualFold(got, src.SHA256) {
+			return 0, fmt.Errorf("doc pack %s: sha256 mismatch (got %s, want %s)", src.Pack, got, src.SHA256)
+		}
+	}
+
+	var r io.Reader = bytes.NewReader(data)
+	if len(data) >= 2 && data[0] == 0x1f && data[1] == 0x8b {
+		gz, err := gzip.NewReader(r)
+		if err != nil {
+			return 0, fmt.Errorf("doc pack %s: %w", src.Pack, err)
+		}
+		defer func() { _ = gz.Close() }()
+		r = gz
+	}
+
+	count := 0
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return count, fmt.Errorf("reading doc pack %s: %w", src.Pack, err)
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+		name := path.Clean(strings.TrimPrefix(hdr.Name, "/"))
+		if strings.HasPrefix(name, "../") || !isMarkdown(name) || skipFiles[path.Base(name)] {
+			continue
+		}
+		if hdr.Size > maxPackFileBytes {
+			logger.Warn("skipping oversized doc in pack", "pack", src.Pack, "file", name, "bytes", hdr.Size)
+			continue
+		}
+
+		body, err := io.ReadAll(tr)
+		if err != nil {
+			return count, fmt.Errorf("reading %s from doc pack %s: %w", name, src.Pack, err)
+		}
+		content := string(body)
+		if strings.TrimSpace(content) == "" {
+			continue
+		}
+
+		// Prefix with the source name so IDs are stable across rebuilds
+		// and do not depend on where the pack was downloaded to.
+		entry := src.entry(src.Name+"/"+name, content)
+		if err := idx.IndexDoc(entry); err != nil {
+			logger.Warn("failed to index doc", "pack", src.Pack, "file", name, "error", err)
+			continue
+		}
+		count++
+		logger.Debug("indexed doc", "pack", src.Pack, "file", name, "title", entry.Title, "category", entry.Category)
+	}
+	return count, nil
+}
+
+// readPack loads a pack from disk or downloads it, enforcing maxPackBytes.
+func readPack(src Source) ([]byte, error) {
+	var body io.ReadCloser
+	if src.IsRemote() {
+		ctx, cancel := context.WithTimeout(context.Background(), packDownloadTimeout)
+		defer cancel()
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, src.Pack, nil)
+		if err != nil {
+			return nil, fmt.Errorf("doc pack %s: %w", src.Pack, err)
+		}
+		resp, err := http.DefaultClient.Do(req) //nolint:gosec // URL is operator-configured in the manifest
+		if err != nil {
+			return nil, fmt.Errorf("downloading doc pack %s: %w", src.Pack, err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			_ = resp.Body.Close()
+			return nil, fmt.Errorf("downloading doc pack %s: HTTP %d", src.Pack, resp.StatusCode)
+		}
+		body = resp.Body
+	} else {
+		f, err := os.Open(src.Pack) //nolint:gosec // path is operator-configured in the manifest
+		if err != nil {
+			return nil, fmt.Errorf("opening doc pack: %w", err)
+		}
+		body = f
+	}
+	defer func() { _ = body.Close() }()
+
+	data, err := io.ReadAll(io.LimitReader(body, maxPackBytes+1))
+	if err != nil {
+		return nil, fmt.Errorf("reading doc pack %s: %w", src.Pack, err)
+	}
+	if len(data) > maxPackBytes {
+		return nil, fmt.Errorf("doc pack %s exceeds %d MiB", src.Pack, maxPackBytes>>20)
+	}
+	return data, nil
+}
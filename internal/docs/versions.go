@@ -0,0 +1,133 @@
+// Copyright (c) mcp-unreal project contributors. Apache-2.0 license.
+
+package docs
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/blevesearch/bleve/v2"
+	"github.com/blevesearch/bleve/v2/search/query"
+	"github.com/remiphilippe/mcp-unreal/internal/toolerr"
+)
+
+// engineSourceRe matches engine doc source names like "ue5.7".
+var engineSourceRe = regexp.MustCompile(`^ue(\d+\.\d+)$`)
+
+// versionFromSourceName returns the engine version encoded in a source
+// name ("ue5.5" → "5.5"), or "" for non-engine sources.
+func versionFromSourceName(name string) string {
+	if m := engineSourceRe.FindStringSubmatch(strings.ToLower(name)); m != nil {
+		return m[1]
+	}
+	return ""
+}
+
+// SetDefaultVersion sets the engine version lookups use when the caller
+// does not pass one. It is ignored at query time if the index holds no
+// docs for that version, so a 5.5 project still gets 5.7 docs rather
+// than nothing.
+func (d *Index) SetDefaultVersion(version string) {
+	d.mu.Lock()
+	d.defaultVersion = version
+	d.mu.Unlock()
+}
+
+// Versions returns the sorted engine versions that have indexed docs.
+func (d *Index) Versions() ([]string, error) {
+	d.mu.Lock()
+	cached := d.versions
+	d.mu.Unlock()
+	if cached != nil {
+		return cached, nil
+	}
+
+	req := bleve.NewSearchRequest(bleve.NewMatchAllQuery())
+	req.Size = 0
+	req.AddFacet("version", bleve.NewFacetRequest("version", 100))
+	result, err := d.index.Search(req)
+	if err != nil {
+		return nil, fmt.Errorf("listing doc versions: %w", err)
+	}
+
+	versions := []string{}
+	if facet, ok := result.Facets["version"]; ok && facet.Terms != nil {
+		for _, t := range facet.Terms.Terms() {
+			if t.Term != "" {
+				versions = append(versions, t.Term)
+			}
+		}
+	}
+	sort.Strings(versions)
+
+	d.mu.Lock()
+	d.versions = versions
+	d.mu.Unlock()
+	return versions, nil
+}
+
+// resolveVersion picks the version a lookup filters to: the requested
+// one (which must be indexed), else the default if it is indexed, else
+// "" for no version filtering.
+func (d *Index) resolveVersion(requested string) (string, error) {
+	versions, err := d.Versions()
+	if err != nil {
+		return "", err
+	}
+	if requested != "" {
+		if containsString(versions, requested) {
+			return requested, nil
+		}
+		if len(versions) == 0 {
+			return "", toolerr.New(toolerr.DocsUnavailable, "no versioned docs indexed — rebuild the index with --build-index to filter by version %s", requested)
+		}
+		return "", toolerr.New(toolerr.NotFound, "no docs indexed for engine version %s (available: %s)", requested, strings.Join(versions, ", "))
+	}
+
+	d.mu.Lock()
+	def := d.defaultVersion
+	d.mu.Unlock()
+	if containsString(versions, def) {
+		return def, nil
+	}
+	return "", nil
+}
+
+// versionFilter restricts q to docs for the given version plus
+// version-agnostic docs, by excluding every other indexed version.
+func (d *Index) versionFilter(q query.Query, version string) query.Query {
+	if version == "" {
+		return q
+	}
+	versions, err := d.Versions()
+	if err != nil {
+		return q
+	}
+
+	var others []query.Query
+	for _, v := range versions {
+		if v != version {
+			t := bleve.NewTermQuery(v)
+			t.SetField("version")
+			others = append(others, t)
+		}
+	}
+	if len(others) == 0 {
+		return q
+	}
+	b := bleve.NewBooleanQuery()
+	b.AddMust(q)
+	b.AddMustNot(others...)
+	return b
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
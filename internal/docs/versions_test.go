@@ -0,0 +1,252 @@
+// Copyright (c) mcp-unreal project contributors. Apache-2.0 license.
+
+package docs
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/remiphilippe/mcp-unreal/internal/toolerr"
+)
+
+const (
+	actorDoc55 = "# AActor\n\n**Parent**: UObject\n\nBase class for actors.\n\n" +
+		"## Key Properties\n\n- `RootComponent` — the root component\n- `bHidden` — whether the actor is hidden\n\n" +
+		"## Key Functions\n\n- `BeginPlay()` — called when play begins\n- `K2_DestroyActor()` — destroys the actor\n"
+	actorDoc57 = "# AActor\n\n**Parent**: UObject\n\nBase class for actors.\n\n" +
+		"## Key Properties\n\n- `RootComponent` — the root component\n\n" +
+		"## Key Functions\n\n- `BeginPlay()` — called when play begins\n- `SetActorLocation(FVector NewLocation)` — moves the actor\n"
+)
+
+// createVersionedIndex indexes AActor for 5.5 and 5.7 plus one
+// version-agnostic RealtimeMesh doc.
+func createVersionedIndex(t *testing.T) *Index {
+	t.Helper()
+	idx := createTestIndex(t)
+	entries := []DocEntry{
+		{ID: "ue5.5/aactor", Title: "AActor", Category: "actor", Source: "ue5.5", Version: "5.5", Content: actorDoc55},
+		{ID: "ue5.7/aactor", Title: "AActor", Category: "actor", Source: "ue5.7", Version: "5.7", Content: actorDoc57},
+		{ID: "rmc/actor", Title: "RealtimeMesh Actor", Category: "realtimemesh", Source: "realtimemesh", Content: "Spawning a RealtimeMesh actor in the level."},
+	}
+	if err := idx.IndexBatch(entries); err != nil {
+		t.Fatalf("IndexBatch: %v", err)
+	}
+	return idx
+}
+
+func TestVersionFromSourceName(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"ue5.7", "5.7"},
+		{"UE5.5", "5.5"},
+		{"ue5", ""},
+		{"realtimemesh", ""},
+		{"project", ""},
+	}
+	for _, tt := range tests {
+		if got := versionFromSourceName(tt.name); got != tt.want {
+			t.Errorf("versionFromSourceName(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestDiscoverSources_Directories(t *testing.T) {
+	root := t.TempDir()
+	for _, d := range []string{"ue5.5", "ue5.7", "realtimemesh", ".git", "index.bleve"} {
+		if err := os.MkdirAll(filepath.Join(root, d), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(root, "README.md"), []byte("# Docs"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	sources, err := DiscoverSources(root)
+	if err != nil {
+		t.Fatalf("DiscoverSources: %v", err)
+	}
+	got := make(map[string]string)
+	for _, s := range sources {
+		got[s.Name] = s.Version
+	}
+	want := map[string]string{"ue5.5": "5.5", "ue5.7": "5.7", "realtimemesh": ""}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("sources = %v, want %v", got, want)
+	}
+}
+
+func TestDiscoverSources_Manifest(t *testing.T) {
+	root := t.TempDir()
+	manifest := `{"sources": [
+		{"name": "ue5.5", "dir": "engine/5.5"},
+		{"name": "custom", "dir": "/abs/custom", "version": "5.7"}
+	]}`
+	if err := os.WriteFile(filepath.Join(root, ManifestFiles[0]), []byte(manifest), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	sources, err := DiscoverSources(root)
+	if err != nil {
+		t.Fatalf("DiscoverSources: %v", err)
+	}
+	want := []Source{
+		{Name: "ue5.5", Dir: filepath.Join(root, "engine/5.5"), Version: "5.5"},
+		{Name: "custom", Dir: "/abs/custom", Version: "5.7"},
+	}
+	if !reflect.DeepEqual(sources, want) {
+		t.Errorf("sources = %+v, want %+v", sources, want)
+	}
+}
+
+func TestDiscoverSources_YAMLManifest(t *testing.T) {
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, "manifest.yml"), []byte("sources:\n  - name: ue5.5\n    dir: engine/5.5\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	sources, err := DiscoverSources(root)
+	if err != nil {
+		t.Fatalf("DiscoverSources: %v", err)
+	}
+	want := []Source{{Name: "ue5.5", Dir: filepath.Join(root, "engine/5.5"), Version: "5.5"}}
+	if !reflect.DeepEqual(sources, want) {
+		t.Errorf("sources = %+v, want %+v", sources, want)
+	}
+}
+
+func TestDiscoverSources_InvalidManifest(t *testing.T) {
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, ManifestFiles[0]), []byte(`{"sources": [{"name": "x"}]}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := DiscoverSources(root); err == nil {
+		t.Error("expected error for source without dir")
+	}
+}
+
+func TestVersions(t *testing.T) {
+	idx := createVersionedIndex(t)
+	versions, err := idx.Versions()
+	if err != nil {
+		t.Fatalf("Versions: %v", err)
+	}
+	if !reflect.DeepEqual(versions, []string{"5.5", "5.7"}) {
+		t.Errorf("Versions = %v", versions)
+	}
+}
+
+func TestLookupClass_Version(t *testing.T) {
+	idx := createVersionedIndex(t)
+	ctx := context.Background()
+
+	tests := []struct {
+		name       string
+		defVersion string
+		version    string
+		want       string
+	}{
+		{"explicit 5.5", "", "5.5", "5.5"},
+		{"explicit 5.7", "5.5", "5.7", "5.7"},
+		{"project default", "5.5", "", "5.5"},
+		{"unindexed default ignored", "5.3", "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			idx.SetDefaultVersion(tt.defVersion)
+			_, out, err := idx.LookupClass(ctx, nil, LookupClassInput{ClassName: "AActor", Version: tt.version})
+			if err != nil {
+				t.Fatalf("LookupClass: %v", err)
+			}
+			if !out.Found {
+				t.Fatal("expected AActor to be found")
+			}
+			if tt.want != "" && out.Class.Version != tt.want {
+				t.Errorf("version = %q, want %q", out.Class.Version, tt.want)
+			}
+		})
+	}
+}
+
+func TestLookupDocs_VersionFilter(t *testing.T) {
+	idx := createVersionedIndex(t)
+
+	_, out, err := idx.LookupDocs(context.Background(), nil, LookupDocsInput{Query: "actor", Version: "5.5"})
+	if err != nil {
+		t.Fatalf("LookupDocs: %v", err)
+	}
+	sawAgnostic := false
+	for _, r := range out.Results {
+		if r.Version == "5.7" {
+			t.Errorf("5.7 result returned for version 5.5: %q", r.Heading)
+		}
+		if r.Source == "realtimemesh" {
+			sawAgnostic = true
+		}
+	}
+	if !sawAgnostic {
+		t.Error("version-agnostic docs should be included in filtered results")
+	}
+}
+
+func TestLookupDocs_UnknownVersion(t *testing.T) {
+	idx := createVersionedIndex(t)
+	_, _, err := idx.LookupDocs(context.Background(), nil, LookupDocsInput{Query: "actor", Version: "4.27"})
+	if err == nil || !strings.Contains(err.Error(), "5.5, 5.7") {
+		t.Errorf("expected error listing available versions, got %v", err)
+	}
+	if code := toolerr.CodeOf(err); code != toolerr.NotFound {
+		t.Errorf("code = %s, want %s", code, toolerr.NotFound)
+	}
+}
+
+func TestLookupAPIDiff(t *testing.T) {
+	idx := createVersionedIndex(t)
+	_, out, err := idx.LookupAPIDiff(context.Background(), nil, LookupAPIDiffInput{
+		ClassName: "AActor", FromVersion: "5.5", ToVersion: "5.7",
+	})
+	if err != nil {
+		t.Fatalf("LookupAPIDiff: %v", err)
+	}
+	if !out.FoundInFrom || !out.FoundInTo {
+		t.Fatalf("expected class in both versions: %+v", out)
+	}
+	if !reflect.DeepEqual(out.RemovedProperties, []string{"bHidden"}) {
+		t.Errorf("RemovedProperties = %v", out.RemovedProperties)
+	}
+	if len(out.AddedProperties) != 0 {
+		t.Errorf("AddedProperties = %v", out.AddedProperties)
+	}
+	if !reflect.DeepEqual(out.AddedFunctions, []string{"SetActorLocation"}) {
+		t.Errorf("AddedFunctions = %v", out.AddedFunctions)
+	}
+	if !reflect.DeepEqual(out.RemovedFunctions, []string{"K2_DestroyActor"}) {
+		t.Errorf("RemovedFunctions = %v", out.RemovedFunctions)
+	}
+	if out.ParentChanged != "" {
+		t.Errorf("ParentChanged = %q", out.ParentChanged)
+	}
+}
+
+func TestMemberName(t *testing.T) {
+	tests := []struct {
+		item string
+		want string
+	}{
+		{"`SetActorLocation(FVector NewLocation)` — moves the actor", "SetActorLocation"},
+		{"`RootComponent` — the root", "RootComponent"},
+		{"RootComponent: the root", "RootComponent"},
+		{"`UWorld::SpawnActor()`", "UWorld::SpawnActor"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := memberName(tt.item); got != tt.want {
+			t.Errorf("memberName(%q) = %q, want %q", tt.item, got, tt.want)
+		}
+	}
+}
@@ -8,12 +8,16 @@ import (
 	"fmt"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/remiphilippe/mcp-unreal/internal/toolerr"
 )
 
 // --- get_level_actors ---
 
 // GetLevelActorsInput defines parameters for the get_level_actors tool.
 type GetLevelActorsInput struct {
+	InstanceInput
+	PageInput
+
 	ClassFilter string `json:"class_filter,omitempty" jsonschema:"Filter by UE class name (e.g. StaticMeshActor, PointLight)"`
 	NameFilter  string `json:"name_filter,omitempty" jsonschema:"Filter by actor display name substring"`
 	TagFilter   string `json:"tag_filter,omitempty" jsonschema:"Filter by actor tag"`
@@ -33,13 +37,18 @@ type ActorInfo struct {
 // GetLevelActorsOutput is returned by the get_level_actors tool.
 type GetLevelActorsOutput struct {
 	Actors []ActorInfo `json:"actors" jsonschema:"list of actors in the level"`
-	Total  int         `json:"total" jsonschema:"total number of actors returned"`
+	Total  int         `json:"total" jsonschema:"total number of matching actors, across all pages"`
+	Page   *PageInfo   `json:"page,omitempty" jsonschema:"which part of the list was returned and the cursor for the next page"`
 }
 
 // --- spawn_actor ---
 
 // SpawnActorInput defines parameters for the spawn_actor tool.
 type SpawnActorInput struct {
+	InstanceInput
+	TransactionInput
+	DryRunInput
+
 	ClassName string     `json:"class_name" jsonschema:"required,UE class name (e.g. StaticMeshActor, PointLight, CameraActor)"`
 	Name      string     `json:"name,omitempty" jsonschema:"Optional display name for the actor"`
 	Location  [3]float64 `json:"location,omitempty" jsonschema:"[X,Y,Z] world position in centimeters"`
@@ -59,6 +68,10 @@ type SpawnActorOutput struct {
 
 // DeleteActorsInput defines parameters for the delete_actors tool.
 type DeleteActorsInput struct {
+	InstanceInput
+	TransactionInput
+	DryRunInput
+
 	ActorPaths []string `json:"actor_paths,omitempty" jsonschema:"Object paths of actors to delete"`
 	ActorNames []string `json:"actor_names,omitempty" jsonschema:"Display names of actors to delete"`
 	World      string   `json:"world,omitempty" jsonschema:"Target world: auto (default, PIE if active else editor), pie (error if not running), editor (always editor)"`
@@ -74,6 +87,10 @@ type DeleteActorsOutput struct {
 
 // MoveActorInput defines parameters for the move_actor tool.
 type MoveActorInput struct {
+	InstanceInput
+	TransactionInput
+	DryRunInput
+
 	ObjectPath string      `json:"object_path" jsonschema:"required,Full object path of the actor to move"`
 	Location   *[3]float64 `json:"location,omitempty" jsonschema:"[X,Y,Z] world position in centimeters"`
 	Rotation   *[3]float64 `json:"rotation,omitempty" jsonschema:"[Pitch,Yaw,Roll] in degrees"`
@@ -91,13 +108,14 @@ type MoveActorOutput struct {
 
 // RegisterActors adds the actor CRUD tools to the MCP server.
 func (h *Handler) RegisterActors(server *mcp.Server) {
-	mcp.AddTool(server, &mcp.Tool{
+	mcp.AddTool(server, pagedTool[GetLevelActorsOutput](&mcp.Tool{
 		Name: "get_level_actors",
 		Description: "List all actors in the current level, filterable by class, name, or tag. " +
 			"Requires the editor to be running with the MCPUnreal plugin loaded (port 8090). " +
 			"Returns actor names, classes, object paths, and transforms. " +
-			"Supports world parameter to target PIE game world or editor world (default: auto).",
-	}, h.GetLevelActors)
+			"Supports world parameter to target PIE game world or editor world (default: auto). " +
+			pagedDescription,
+	}, "actors"), h.GetLevelActors)
 
 	mcp.AddTool(server, &mcp.Tool{
 		Name: "spawn_actor",
@@ -155,7 +173,7 @@ func (h *Handler) GetLevelActors(ctx context.Context, req *mcp.CallToolRequest,
 // SpawnActor implements the spawn_actor tool.
 func (h *Handler) SpawnActor(ctx context.Context, req *mcp.CallToolRequest, input SpawnActorInput) (*mcp.CallToolResult, SpawnActorOutput, error) {
 	if input.ClassName == "" {
-		return nil, SpawnActorOutput{}, fmt.Errorf("class_name is required")
+		return nil, SpawnActorOutput{}, toolerr.New(toolerr.Validation, "class_name is required")
 	}
 
 	// Default scale to [1,1,1] when not provided (zero-value means unset).
@@ -195,7 +213,7 @@ func (h *Handler) SpawnActor(ctx context.Context, req *mcp.CallToolRequest, inpu
 // DeleteActors implements the delete_actors tool.
 func (h *Handler) DeleteActors(ctx context.Context, req *mcp.CallToolRequest, input DeleteActorsInput) (*mcp.CallToolResult, DeleteActorsOutput, error) {
 	if len(input.ActorPaths) == 0 && len(input.ActorNames) == 0 {
-		return nil, DeleteActorsOutput{}, fmt.Errorf("at least one of actor_paths or actor_names is required")
+		return nil, DeleteActorsOutput{}, toolerr.New(toolerr.Validation, "at least one of actor_paths or actor_names is required")
 	}
 
 	body := map[string]any{}
@@ -229,10 +247,10 @@ func (h *Handler) DeleteActors(ctx context.Context, req *mcp.CallToolRequest, in
 // and SetActorScale3D, only modifying the components that are provided.
 func (h *Handler) MoveActor(ctx context.Context, req *mcp.CallToolRequest, input MoveActorInput) (*mcp.CallToolResult, MoveActorOutput, error) {
 	if input.ObjectPath == "" {
-		return nil, MoveActorOutput{}, fmt.Errorf("object_path is required")
+		return nil, MoveActorOutput{}, toolerr.New(toolerr.Validation, "object_path is required")
 	}
 	if input.Location == nil && input.Rotation == nil && input.Scale == nil {
-		return nil, MoveActorOutput{}, fmt.Errorf("at least one of location, rotation, or scale must be provided")
+		return nil, MoveActorOutput{}, toolerr.New(toolerr.Validation, "at least one of location, rotation, or scale must be provided")
 	}
 
 	out := MoveActorOutput{
@@ -242,8 +260,9 @@ func (h *Handler) MoveActor(ctx context.Context, req *mcp.CallToolRequest, input
 
 	if input.Location != nil {
 		body := map[string]any{
-			"objectPath":   input.ObjectPath,
-			"functionName": "K2_SetActorLocation",
+			"objectPath":          input.ObjectPath,
+			"functionName":        "K2_SetActorLocation",
+			"generateTransaction": true,
 			"parameters": map[string]any{
 				"NewLocation": map[string]float64{
 					"X": input.Location[0], "Y": input.Location[1], "Z": input.Location[2],
@@ -260,8 +279,9 @@ func (h *Handler) MoveActor(ctx context.Context, req *mcp.CallToolRequest, input
 
 	if input.Rotation != nil {
 		body := map[string]any{
-			"objectPath":   input.ObjectPath,
-			"functionName": "K2_SetActorRotation",
+			"objectPath":          input.ObjectPath,
+			"functionName":        "K2_SetActorRotation",
+			"generateTransaction": true,
 			"parameters": map[string]any{
 				"NewRotation": map[string]float64{
 					"Pitch": input.Rotation[0], "Yaw": input.Rotation[1], "Roll": input.Rotation[2],
@@ -277,8 +297,9 @@ func (h *Handler) MoveActor(ctx context.Context, req *mcp.CallToolRequest, input
 
 	if input.Scale != nil {
 		body := map[string]any{
-			"objectPath":   input.ObjectPath,
-			"functionName": "SetActorScale3D",
+			"objectPath":          input.ObjectPath,
+			"functionName":        "SetActorScale3D",
+			"generateTransaction": true,
 			"parameters": map[string]any{
 				"NewScale3D": map[string]float64{
 					"X": input.Scale[0], "Y": input.Scale[1], "Z": input.Scale[2],
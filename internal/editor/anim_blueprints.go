@@ -8,12 +8,15 @@ import (
 	"fmt"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/remiphilippe/mcp-unreal/internal/toolerr"
 )
 
 // --- anim_blueprint_query ---
 
 // AnimBlueprintQueryInput defines parameters for the anim_blueprint_query tool.
 type AnimBlueprintQueryInput struct {
+	InstanceInput
+
 	Operation        string `json:"operation" jsonschema:"required,One of: list_state_machines, inspect_state_machine"`
 	BlueprintPath    string `json:"blueprint_path" jsonschema:"required,Animation Blueprint asset path (e.g. /Game/Animations/ABP_Character)"`
 	StateMachineName string `json:"state_machine_name,omitempty" jsonschema:"State machine name for inspect_state_machine"`
@@ -28,6 +31,10 @@ type AnimBlueprintQueryOutput struct {
 
 // AnimBlueprintModifyInput defines parameters for the anim_blueprint_modify tool.
 type AnimBlueprintModifyInput struct {
+	InstanceInput
+	TransactionInput
+	DryRunInput
+
 	Operation        string `json:"operation" jsonschema:"required,One of: create_state_machine, delete_state_machine, rename_state_machine, set_entry_state, create_state, delete_state, rename_state, create_transition, delete_transition, add_anim_node, delete_anim_node"`
 	BlueprintPath    string `json:"blueprint_path" jsonschema:"required,Animation Blueprint asset path"`
 	StateMachineName string `json:"state_machine_name,omitempty" jsonschema:"State machine name (required for most operations)"`
@@ -78,10 +85,10 @@ func (h *Handler) RegisterAnimBlueprints(server *mcp.Server) {
 // AnimBlueprintQuery implements the anim_blueprint_query tool.
 func (h *Handler) AnimBlueprintQuery(ctx context.Context, req *mcp.CallToolRequest, input AnimBlueprintQueryInput) (*mcp.CallToolResult, AnimBlueprintQueryOutput, error) {
 	if input.Operation == "" {
-		return nil, AnimBlueprintQueryOutput{}, fmt.Errorf("operation is required (list_state_machines, inspect_state_machine)")
+		return nil, AnimBlueprintQueryOutput{}, toolerr.New(toolerr.Validation, "operation is required (list_state_machines, inspect_state_machine)")
 	}
 	if input.BlueprintPath == "" {
-		return nil, AnimBlueprintQueryOutput{}, fmt.Errorf("blueprint_path is required")
+		return nil, AnimBlueprintQueryOutput{}, toolerr.New(toolerr.Validation, "blueprint_path is required")
 	}
 
 	body := map[string]any{
@@ -110,10 +117,10 @@ func (h *Handler) AnimBlueprintQuery(ctx context.Context, req *mcp.CallToolReque
 // AnimBlueprintModify implements the anim_blueprint_modify tool.
 func (h *Handler) AnimBlueprintModify(ctx context.Context, req *mcp.CallToolRequest, input AnimBlueprintModifyInput) (*mcp.CallToolResult, AnimBlueprintModifyOutput, error) {
 	if input.Operation == "" {
-		return nil, AnimBlueprintModifyOutput{}, fmt.Errorf("operation is required")
+		return nil, AnimBlueprintModifyOutput{}, toolerr.New(toolerr.Validation, "operation is required")
 	}
 	if input.BlueprintPath == "" {
-		return nil, AnimBlueprintModifyOutput{}, fmt.Errorf("blueprint_path is required")
+		return nil, AnimBlueprintModifyOutput{}, toolerr.New(toolerr.Validation, "blueprint_path is required")
 	}
 
 	body := map[string]any{
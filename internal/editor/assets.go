@@ -8,12 +8,16 @@ import (
 	"fmt"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/remiphilippe/mcp-unreal/internal/toolerr"
 )
 
 // --- search_assets ---
 
 // SearchAssetsInput defines parameters for the search_assets tool.
 type SearchAssetsInput struct {
+	InstanceInput
+	PageInput
+
 	ClassFilter   string `json:"class_filter,omitempty" jsonschema:"Filter by asset class (e.g. Blueprint, StaticMesh, Material, Texture2D)"`
 	PathFilter    string `json:"path_filter,omitempty" jsonschema:"Filter by path prefix (e.g. /Game/Blueprints)"`
 	NameFilter    string `json:"name_filter,omitempty" jsonschema:"Filter by asset name substring"`
@@ -31,13 +35,16 @@ type AssetEntry struct {
 // SearchAssetsOutput is returned by the search_assets tool.
 type SearchAssetsOutput struct {
 	Assets []AssetEntry `json:"assets" jsonschema:"matching assets"`
-	Total  int          `json:"total" jsonschema:"number of matching assets"`
+	Total  int          `json:"total" jsonschema:"number of matching assets, across all pages"`
+	Page   *PageInfo    `json:"page,omitempty" jsonschema:"which part of the list was returned and the cursor for the next page"`
 }
 
 // --- get_asset_info ---
 
 // GetAssetInfoInput defines parameters for the get_asset_info tool.
 type GetAssetInfoInput struct {
+	InstanceInput
+
 	AssetPath string `json:"asset_path" jsonschema:"required,Full asset path (e.g. /Game/Blueprints/BP_Player.BP_Player)"`
 }
 
@@ -55,12 +62,13 @@ type GetAssetInfoOutput struct {
 
 // RegisterAssets adds asset query tools to the MCP server.
 func (h *Handler) RegisterAssets(server *mcp.Server) {
-	mcp.AddTool(server, &mcp.Tool{
+	mcp.AddTool(server, pagedTool[SearchAssetsOutput](&mcp.Tool{
 		Name: "search_assets",
 		Description: "Search for assets in the UE Asset Registry by class, path, or name. " +
 			"Returns asset names, paths, and classes. " +
-			"Requires the editor running with the MCPUnreal plugin (port 8090).",
-	}, h.SearchAssets)
+			"Requires the editor running with the MCPUnreal plugin (port 8090). " +
+			pagedDescription,
+	}, "assets"), h.SearchAssets)
 
 	mcp.AddTool(server, &mcp.Tool{
 		Name: "get_asset_info",
@@ -106,7 +114,7 @@ func (h *Handler) SearchAssets(ctx context.Context, req *mcp.CallToolRequest, in
 // GetAssetInfo implements the get_asset_info tool.
 func (h *Handler) GetAssetInfo(ctx context.Context, req *mcp.CallToolRequest, input GetAssetInfoInput) (*mcp.CallToolResult, GetAssetInfoOutput, error) {
 	if input.AssetPath == "" {
-		return nil, GetAssetInfoOutput{}, fmt.Errorf("asset_path is required")
+		return nil, GetAssetInfoOutput{}, toolerr.New(toolerr.Validation, "asset_path is required")
 	}
 
 	// Fetch base info.
@@ -8,12 +8,16 @@ import (
 	"fmt"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/remiphilippe/mcp-unreal/internal/toolerr"
 )
 
 // --- blueprint_query ---
 
 // BlueprintQueryInput defines parameters for the blueprint_query tool.
 type BlueprintQueryInput struct {
+	InstanceInput
+	PageInput
+
 	Operation        string `json:"operation" jsonschema:"required,One of: list, inspect, get_graph, get_node_types"`
 	Path             string `json:"path,omitempty" jsonschema:"Blueprint asset path (e.g. /Game/Blueprints/BP_Player) — required for inspect and get_graph"`
 	GraphName        string `json:"graph_name,omitempty" jsonschema:"Graph name for get_graph (from inspect results)"`
@@ -22,13 +26,18 @@ type BlueprintQueryInput struct {
 
 // BlueprintQueryOutput is returned by the blueprint_query tool.
 type BlueprintQueryOutput struct {
-	Result any `json:"result" jsonschema:"Query results (structure depends on operation)"`
+	Result any       `json:"result" jsonschema:"Query results (structure depends on operation)"`
+	Page   *PageInfo `json:"page,omitempty" jsonschema:"for list: which part of the list was returned and the cursor for the next page"`
 }
 
 // --- blueprint_modify ---
 
 // BlueprintModifyInput defines parameters for the blueprint_modify tool.
 type BlueprintModifyInput struct {
+	InstanceInput
+	TransactionInput
+	DryRunInput
+
 	Operation     string          `json:"operation" jsonschema:"required,One of: create, add_variable, remove_variable, add_function, remove_function, add_node, delete_node, connect_pins, disconnect_pins, set_pin_value, compile"`
 	BlueprintPath string          `json:"blueprint_path,omitempty" jsonschema:"Blueprint asset path — required for all operations except create"`
 	Params        json.RawMessage `json:"params,omitempty" jsonschema:"Operation-specific parameters (see tool description for details)"`
@@ -61,14 +70,15 @@ type BlueprintModifyOutput struct {
 
 // RegisterBlueprints adds the blueprint_query and blueprint_modify tools to the MCP server.
 func (h *Handler) RegisterBlueprints(server *mcp.Server) {
-	mcp.AddTool(server, &mcp.Tool{
+	mcp.AddTool(server, pagedTool[BlueprintQueryOutput](&mcp.Tool{
 		Name: "blueprint_query",
 		Description: "Query Blueprint assets in the UE editor. Operations:\n" +
 			"- list: List all Blueprint assets in the project\n" +
 			"- inspect: Get variables, functions, and event graphs of a Blueprint (requires path)\n" +
 			"- get_graph: Serialize a graph's nodes, pins, and connections (requires path + graph_name)\n" +
-			"Requires the editor running with MCPUnreal plugin (port 8090).",
-	}, h.BlueprintQuery)
+			"Requires the editor running with MCPUnreal plugin (port 8090). " +
+			"list is paged: " + pagedDescription,
+	}), h.BlueprintQuery)
 
 	mcp.AddTool(server, &mcp.Tool{
 		Name: "blueprint_modify",
@@ -91,7 +101,7 @@ func (h *Handler) RegisterBlueprints(server *mcp.Server) {
 // BlueprintQuery implements the blueprint_query tool.
 func (h *Handler) BlueprintQuery(ctx context.Context, req *mcp.CallToolRequest, input BlueprintQueryInput) (*mcp.CallToolResult, BlueprintQueryOutput, error) {
 	if input.Operation == "" {
-		return nil, BlueprintQueryOutput{}, fmt.Errorf("operation is required (list, inspect, get_graph)")
+		return nil, BlueprintQueryOutput{}, toolerr.New(toolerr.Validation, "operation is required (list, inspect, get_graph)")
 	}
 
 	var endpoint string
@@ -102,22 +112,22 @@ func (h *Handler) BlueprintQuery(ctx context.Context, req *mcp.CallToolRequest,
 		endpoint = "/api/blueprints/list"
 	case "inspect":
 		if input.Path == "" {
-			return nil, BlueprintQueryOutput{}, fmt.Errorf("path is required for inspect operation")
+			return nil, BlueprintQueryOutput{}, toolerr.New(toolerr.Validation, "path is required for inspect operation")
 		}
 		endpoint = "/api/blueprints/inspect"
 		body["blueprint_path"] = input.Path
 	case "get_graph":
 		if input.Path == "" {
-			return nil, BlueprintQueryOutput{}, fmt.Errorf("path is required for get_graph operation")
+			return nil, BlueprintQueryOutput{}, toolerr.New(toolerr.Validation, "path is required for get_graph operation")
 		}
 		if input.GraphName == "" {
-			return nil, BlueprintQueryOutput{}, fmt.Errorf("graph_name is required for get_graph operation")
+			return nil, BlueprintQueryOutput{}, toolerr.New(toolerr.Validation, "graph_name is required for get_graph operation")
 		}
 		endpoint = "/api/blueprints/get_graph"
 		body["blueprint_path"] = input.Path
 		body["graph_name"] = input.GraphName
 	default:
-		return nil, BlueprintQueryOutput{}, fmt.Errorf("unknown operation %q — use list, inspect, or get_graph", input.Operation)
+		return nil, BlueprintQueryOutput{}, toolerr.New(toolerr.Validation, "unknown operation %q — use list, inspect, or get_graph", input.Operation)
 	}
 
 	resp, err := h.Client.PluginCall(ctx, endpoint, body)
@@ -138,7 +148,7 @@ func (h *Handler) BlueprintQuery(ctx context.Context, req *mcp.CallToolRequest,
 // BlueprintModify implements the blueprint_modify tool.
 func (h *Handler) BlueprintModify(ctx context.Context, req *mcp.CallToolRequest, input BlueprintModifyInput) (*mcp.CallToolResult, BlueprintModifyOutput, error) {
 	if input.Operation == "" {
-		return nil, BlueprintModifyOutput{}, fmt.Errorf("operation is required")
+		return nil, BlueprintModifyOutput{}, toolerr.New(toolerr.Validation, "operation is required")
 	}
 
 	// Build the request body, forwarding all fields.
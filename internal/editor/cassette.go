@@ -0,0 +1,258 @@
+// Copyright (c) mcp-unreal project contributors. Apache-2.0 license.
+
+package editor
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Interaction is one recorded editor HTTP exchange. A cassette file holds
+// one Interaction per line (JSONL) in the order the requests were sent.
+type Interaction struct {
+	Time   time.Time `json:"time"`
+	Method string    `json:"method"`
+	// Path is the endpoint without the base URL, so a cassette replays
+	// regardless of the ports it was recorded on.
+	Path    string          `json:"path"`
+	Request json.RawMessage `json:"request,omitempty"`
+	Status  int             `json:"status,omitempty"`
+	// Response holds a JSON response body; ResponseText holds any other.
+	Response     json.RawMessage `json:"response,omitempty"`
+	ResponseText string          `json:"response_text,omitempty"`
+	// Error is the transport error, e.g. connection refused, when the
+	// request got no response at all.
+	Error      string  `json:"error,omitempty"`
+	DurationMS float64 `json:"duration_ms"`
+}
+
+// LoadCassette reads the interactions recorded in a cassette file.
+func LoadCassette(path string) ([]Interaction, error) {
+	f, err := os.Open(path) //nolint:gosec // operator-supplied cassette path
+	if err != nil {
+		return nil, fmt.Errorf("opening cassette: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	var out []Interaction
+	sc := bufio.NewScanner(f)
+	sc.Buffer(make([]byte, 0, 64<<10), 64<<20)
+	for line := 1; sc.Scan(); line++ {
+		if len(bytes.TrimSpace(sc.Bytes())) == 0 {
+			continue
+		}
+		var in Interaction
+		if err := json.Unmarshal(sc.Bytes(), &in); err != nil {
+			return nil, fmt.Errorf("cassette %s line %d: %w", path, line, err)
+		}
+		out = append(out, in)
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("reading cassette %s: %w", path, err)
+	}
+	return out, nil
+}
+
+// RecordTo appends every request the client sends, with its response and
+// timing, to the cassette at path. Close the returned closer to flush and
+// close the file.
+func (c *Client) RecordTo(path string) (io.Closer, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600) //nolint:gosec // operator-supplied cassette path
+	if err != nil {
+		return nil, fmt.Errorf("opening cassette for recording: %w", err)
+	}
+	c.httpClient.Transport = &recordingTransport{base: c.transport(), out: f, logger: c.logger}
+	c.logger.Info("recording editor traffic", "cassette", path)
+	return f, nil
+}
+
+// ReplayFrom makes the client answer requests from the cassette at path
+// instead of the network. Each request is matched to the first unused
+// interaction with the same method, path, and JSON body, falling back to
+// the same method and path; a request with no match fails.
+func (c *Client) ReplayFrom(path string) error {
+	interactions, err := LoadCassette(path)
+	if err != nil {
+		return err
+	}
+	c.httpClient.Transport = &replayTransport{cassette: path, interactions: interactions, used: make([]bool, len(interactions))}
+	c.logger.Info("replaying editor traffic", "cassette", path, "interactions", len(interactions))
+	return nil
+}
+
+// transport returns the client's current round tripper.
+func (c *Client) transport() http.RoundTripper {
+	if c.httpClient.Transport != nil {
+		return c.httpClient.Transport
+	}
+	return http.DefaultTransport
+}
+
+// recordingTransport passes requests to base and writes each exchange to
+// out as a cassette line.
+type recordingTransport struct {
+	base   http.RoundTripper
+	logger *slog.Logger
+
+	mu  sync.Mutex
+	out io.Writer
+}
+
+func (t *recordingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	reqBody, err := readRequestBody(req)
+	if err != nil {
+		return nil, err
+	}
+	in := Interaction{Time: time.Now().UTC(), Method: req.Method, Path: req.URL.Path, Request: jsonOrNil(reqBody)}
+
+	start := time.Now()
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		in.Error = err.Error()
+		in.DurationMS = msSince(start)
+		t.write(in)
+		return nil, err
+	}
+
+	respBody, readErr := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+	in.DurationMS = msSince(start)
+	in.Status = resp.StatusCode
+	if in.Response = jsonOrNil(respBody); in.Response == nil {
+		in.ResponseText = string(respBody)
+	}
+	if readErr != nil {
+		in.Error = readErr.Error()
+	}
+	t.write(in)
+	return resp, readErr
+}
+
+func (t *recordingTransport) write(in Interaction) {
+	line, err := json.Marshal(in)
+	if err == nil {
+		t.mu.Lock()
+		_, err = t.out.Write(append(line, '\n'))
+		t.mu.Unlock()
+	}
+	if err != nil {
+		t.logger.Warn("failed to record editor interaction", "path", in.Path, "error", err)
+	}
+}
+
+// replayTransport answers requests from recorded interactions.
+type replayTransport struct {
+	cassette string
+
+	mu           sync.Mutex
+	interactions []Interaction
+	used         []bool
+}
+
+func (t *replayTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, err := readRequestBody(req)
+	if err != nil {
+		return nil, err
+	}
+	in, ok := t.match(req.Method, req.URL.Path, body)
+	if !ok {
+		return nil, fmt.Errorf("cassette %s has no recorded response for %s %s", t.cassette, req.Method, req.URL.Path)
+	}
+	if in.Error != "" {
+		return nil, errors.New(in.Error)
+	}
+
+	respBody := []byte(in.Response)
+	contentType := "application/json"
+	if in.Response == nil {
+		respBody, contentType = []byte(in.ResponseText), "text/plain"
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", in.Status, http.StatusText(in.Status)),
+		StatusCode:    in.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header{"Content-Type": []string{contentType}},
+		Body:          io.NopCloser(bytes.NewReader(respBody)),
+		ContentLength: int64(len(respBody)),
+		Request:       req,
+	}, nil
+}
+
+// match claims the first unused interaction for the request, preferring
+// an exact body match.
+func (t *replayTransport) match(method, path string, body []byte) (Interaction, bool) {
+	want := canonicalJSON(body)
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	fallback := -1
+	for i, in := range t.interactions {
+		if t.used[i] || in.Method != method || in.Path != path {
+			continue
+		}
+		if canonicalJSON(in.Request) == want {
+			t.used[i] = true
+			return in, true
+		}
+		if fallback < 0 {
+			fallback = i
+		}
+	}
+	if fallback < 0 {
+		return Interaction{}, false
+	}
+	t.used[fallback] = true
+	return t.interactions[fallback], true
+}
+
+// readRequestBody returns the request body and replaces it so the request
+// can still be sent.
+func readRequestBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, nil
+	}
+	body, err := io.ReadAll(req.Body)
+	_ = req.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("reading request body: %w", err)
+	}
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	return body, nil
+}
+
+// jsonOrNil returns data as raw JSON, or nil if it is empty or not JSON.
+func jsonOrNil(data []byte) json.RawMessage {
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 || !json.Valid(data) {
+		return nil
+	}
+	return json.RawMessage(data)
+}
+
+// canonicalJSON re-encodes JSON with sorted object keys so bodies compare
+// equal regardless of field order. Non-JSON input is returned as is.
+func canonicalJSON(data []byte) string {
+	var v any
+	if len(bytes.TrimSpace(data)) == 0 || json.Unmarshal(data, &v) != nil {
+		return strings.TrimSpace(string(data))
+	}
+	out, _ := json.Marshal(v)
+	return string(out)
+}
+
+func msSince(start time.Time) float64 {
+	return float64(time.Since(start).Microseconds()) / 1000
+}
@@ -0,0 +1,153 @@
+// Copyright (c) mcp-unreal project contributors. Apache-2.0 license.
+
+package editor
+
+import (
+	"context"
+	"net/http/httptest"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/remiphilippe/mcp-unreal/internal/editor/editortest"
+)
+
+// offlineURL is an address nothing listens on.
+const offlineURL = "http://127.0.0.1:1"
+
+func TestRecordAndReplay(t *testing.T) {
+	fake := editortest.New(nil)
+	plugin := httptest.NewServer(fake.PluginHandler())
+	defer plugin.Close()
+	rc := httptest.NewServer(fake.RCHandler())
+	defer rc.Close()
+
+	path := filepath.Join(t.TempDir(), "session.jsonl")
+	ctx := context.Background()
+
+	// Record a short session, including a request that fails to connect.
+	rec := newTestClient(rc.URL, plugin.URL)
+	cassette, err := rec.RecordTo(path)
+	if err != nil {
+		t.Fatalf("RecordTo: %v", err)
+	}
+	h := &Handler{Client: rec, Logger: testLogger()}
+	_, spawned, err := h.SpawnActor(ctx, nil, SpawnActorInput{ClassName: "PointLight", Name: "Lamp"})
+	if err != nil {
+		t.Fatalf("SpawnActor: %v", err)
+	}
+	_, lights, err := h.GetLevelActors(ctx, nil, GetLevelActorsInput{ClassFilter: "PointLight"})
+	if err != nil {
+		t.Fatalf("GetLevelActors: %v", err)
+	}
+	_, all, err := h.GetLevelActors(ctx, nil, GetLevelActorsInput{})
+	if err != nil {
+		t.Fatalf("GetLevelActors: %v", err)
+	}
+	rec.rcAPIBaseURL = offlineURL
+	loc := [3]float64{1, 2, 3}
+	_, _, moveErr := h.MoveActor(ctx, nil, MoveActorInput{ObjectPath: spawned.ActorPath, Location: &loc})
+	if moveErr == nil {
+		t.Fatal("expected connection error")
+	}
+	if err := cassette.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	interactions, err := LoadCassette(path)
+	if err != nil {
+		t.Fatalf("LoadCassette: %v", err)
+	}
+	// The failed move is recorded once per attempt, retries included.
+	if want := 3 + fastRetry.MaxAttempts; len(interactions) != want {
+		t.Fatalf("recorded %d interactions, want %d", len(interactions), want)
+	}
+	first := interactions[0]
+	if first.Method != "POST" || first.Path != "/api/actors/spawn" || first.Status != 200 ||
+		!strings.Contains(string(first.Request), `"class_name":"PointLight"`) || !strings.Contains(string(first.Response), `"actor_name":"Lamp"`) {
+		t.Errorf("first interaction = %+v", first)
+	}
+	if last := interactions[len(interactions)-1]; last.Error == "" || last.Status != 0 || last.Path != "/remote/object/call" {
+		t.Errorf("connection failure not recorded: %+v", last)
+	}
+
+	// Replay against dead addresses, asking for the two listings in the
+	// opposite order: requests are matched by body, not position.
+	replay := newTestClient(offlineURL, offlineURL)
+	if err := replay.ReplayFrom(path); err != nil {
+		t.Fatalf("ReplayFrom: %v", err)
+	}
+	h = &Handler{Client: replay, Logger: testLogger()}
+	_, gotAll, err := h.GetLevelActors(ctx, nil, GetLevelActorsInput{})
+	if err != nil {
+		t.Fatalf("replayed GetLevelActors: %v", err)
+	}
+	_, gotLights, err := h.GetLevelActors(ctx, nil, GetLevelActorsInput{ClassFilter: "PointLight"})
+	if err != nil {
+		t.Fatalf("replayed GetLevelActors: %v", err)
+	}
+	if !reflect.DeepEqual(gotAll, all) || !reflect.DeepEqual(gotLights, lights) {
+		t.Errorf("replayed listings differ from recording:\n%+v\n%+v", gotAll, gotLights)
+	}
+	_, gotSpawn, err := h.SpawnActor(ctx, nil, SpawnActorInput{ClassName: "PointLight", Name: "Lamp"})
+	if err != nil || gotSpawn != spawned {
+		t.Errorf("replayed SpawnActor = (%+v, %v), want %+v", gotSpawn, err, spawned)
+	}
+	_, _, err = h.MoveActor(ctx, nil, MoveActorInput{ObjectPath: spawned.ActorPath, Location: &loc})
+	if err == nil || !strings.Contains(err.Error(), "unreachable") {
+		t.Errorf("replayed connection error = %v", err)
+	}
+
+	// The cassette is used up: further requests have no recording.
+	if _, _, err := h.GetLevelActors(ctx, nil, GetLevelActorsInput{}); err == nil || !strings.Contains(err.Error(), "no recorded response") {
+		t.Errorf("expected exhausted cassette error, got %v", err)
+	}
+}
+
+// TestReplayRegressionCassette replays a session recorded against the
+// fake editor and checked into testdata.
+func TestReplayRegressionCassette(t *testing.T) {
+	client := newTestClient(offlineURL, offlineURL)
+	if err := client.ReplayFrom(filepath.Join("testdata", "pie_session.jsonl")); err != nil {
+		t.Fatalf("ReplayFrom: %v", err)
+	}
+	h := &Handler{Client: client, Logger: testLogger()}
+	ctx := context.Background()
+
+	_, pie, err := h.PIEControl(ctx, nil, PIEControlInput{Operation: "start"})
+	if err != nil || !pie.PIEActive || pie.PIEMap != "UEDPIE_0_Main" {
+		t.Fatalf("PIEControl(start) = (%+v, %v)", pie, err)
+	}
+	_, actors, err := h.GetLevelActors(ctx, nil, GetLevelActorsInput{World: "pie", ClassFilter: "DefaultPawn"})
+	if err != nil || actors.Total != 1 {
+		t.Errorf("GetLevelActors(pie) = (%+v, %v)", actors, err)
+	}
+	_, _, err = h.PIEControl(ctx, nil, PIEControlInput{Operation: "start"})
+	if err == nil || !strings.Contains(err.Error(), "already active") {
+		t.Errorf("second start should replay the recorded plugin error, got %v", err)
+	}
+}
+
+func TestLoadCassette_Invalid(t *testing.T) {
+	if _, err := LoadCassette(filepath.Join(t.TempDir(), "missing.jsonl")); err == nil {
+		t.Error("expected error for missing cassette")
+	}
+}
+
+func TestCanonicalJSON(t *testing.T) {
+	tests := []struct {
+		a, b string
+		same bool
+	}{
+		{`{"a":1,"b":2}`, `{"b":2, "a":1}`, true},
+		{`{"a":1}`, `{"a":2}`, false},
+		{``, ``, true},
+		{`not json`, `not json`, true},
+	}
+	for _, tt := range tests {
+		if got := canonicalJSON([]byte(tt.a)) == canonicalJSON([]byte(tt.b)); got != tt.same {
+			t.Errorf("canonicalJSON(%q) == canonicalJSON(%q) = %v, want %v", tt.a, tt.b, got, tt.same)
+		}
+	}
+}
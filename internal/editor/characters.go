@@ -8,12 +8,17 @@ import (
 	"fmt"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/remiphilippe/mcp-unreal/internal/toolerr"
 )
 
 // --- character_config ---
 
 // CharacterConfigInput defines parameters for the character_config tool.
 type CharacterConfigInput struct {
+	InstanceInput
+	TransactionInput
+	DryRunInput
+
 	Operation     string          `json:"operation" jsonschema:"required,One of: get_config, set_movement, set_capsule, set_mesh, set_camera, get_movement_modes"`
 	BlueprintPath string          `json:"blueprint_path" jsonschema:"required,Character Blueprint path (e.g. /Game/Characters/BP_PlayerCharacter)"`
 	Params        json.RawMessage `json:"params,omitempty" jsonschema:"Operation-specific parameters"`
@@ -55,10 +60,10 @@ func (h *Handler) RegisterCharacters(server *mcp.Server) {
 // CharacterConfig implements the character_config tool.
 func (h *Handler) CharacterConfig(ctx context.Context, req *mcp.CallToolRequest, input CharacterConfigInput) (*mcp.CallToolResult, CharacterConfigOutput, error) {
 	if input.Operation == "" {
-		return nil, CharacterConfigOutput{}, fmt.Errorf("operation is required")
+		return nil, CharacterConfigOutput{}, toolerr.New(toolerr.Validation, "operation is required")
 	}
 	if input.BlueprintPath == "" {
-		return nil, CharacterConfigOutput{}, fmt.Errorf("blueprint_path is required")
+		return nil, CharacterConfigOutput{}, toolerr.New(toolerr.Validation, "blueprint_path is required")
 	}
 
 	body := map[string]any{
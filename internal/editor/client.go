@@ -20,15 +20,22 @@ import (
 	"io"
 	"log/slog"
 	"net/http"
+	"net/url"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/remiphilippe/mcp-unreal/internal/config"
+	"github.com/remiphilippe/mcp-unreal/internal/sandbox"
+	"github.com/remiphilippe/mcp-unreal/internal/telemetry"
+	"github.com/remiphilippe/mcp-unreal/internal/toolerr"
 )
 
 const (
 	// defaultRequestTimeout is the maximum time for a single HTTP request
-	// to the editor. Most operations complete in under a second; builds
-	// and tests use their own timeouts via headless tools.
+	// to the editor when the caller's context has no deadline. Most
+	// operations complete in under a second; builds and tests use their
+	// own timeouts via headless tools.
 	defaultRequestTimeout = 30 * time.Second
 
 	// defaultConnectTimeout is used for ping/health checks.
@@ -42,23 +49,71 @@ type Client struct {
 	pluginBaseURL string
 	httpClient    *http.Client
 	logger        *slog.Logger
+
+	// tokens, if the plugin token is set, is the transport that adds it;
+	// instance clients and discovery probes register their plugin
+	// endpoints with it.
+	tokens *tokenTransport
+
+	// retry and breakerPolicy default to DefaultRetryPolicy and
+	// DefaultBreakerPolicy when zero.
+	retry         RetryPolicy
+	breakerPolicy BreakerPolicy
+
+	// handshake makes plugin calls check the negotiated route list
+	// first. NewClient enables it.
+	handshake bool
+
+	// instance is set on clients for a named editor instance; instances
+	// is set on the default client once the registry is built.
+	instance string
+
+	mu        sync.Mutex
+	breakers  map[string]*breaker
+	plugin    *PluginInfo
+	instances *Instances
 }
 
 // Handler holds references needed by all editor tools.
 type Handler struct {
 	Client *Client
 	Logger *slog.Logger
+
+	// Events feeds wait_for_event; nil when the event stream is off.
+	Events *EventStream
+
+	// Paths confines local file paths in tool arguments, such as a
+	// screenshot destination or a file to import. When nil, tools refuse
+	// file paths.
+	Paths *sandbox.Sandbox
+}
+
+// resolvePath checks a file path argument against the path sandbox and
+// returns the resolved path to send to the editor.
+func (h *Handler) resolvePath(arg, path string) (string, error) {
+	if h.Paths == nil {
+		return "", toolerr.New(toolerr.PathRefused, "%s refused: file paths are disabled because no path sandbox is configured", arg)
+	}
+	return h.Paths.Resolve(arg, path)
 }
 
 // NewClient creates an editor client from the server configuration.
+// Invalid TLS settings are logged and make every request fail; callers
+// should check cfg.CheckEditorEndpoints first.
 func NewClient(cfg *config.Config, logger *slog.Logger) *Client {
+	transport, err := NewTransport(cfg)
+	if err != nil {
+		logger.Error("invalid editor TLS settings", "error", err)
+		transport = errTransport{err: err}
+	}
+	tokens, _ := transport.(*tokenTransport)
 	return &Client{
 		rcAPIBaseURL:  cfg.RCAPIURL(),
 		pluginBaseURL: cfg.PluginURL(),
-		httpClient: &http.Client{
-			Timeout: defaultRequestTimeout,
-		},
-		logger: logger,
+		httpClient:    &http.Client{Transport: transport},
+		logger:        logger,
+		tokens:        tokens,
+		handshake:     true,
 	}
 }
 
@@ -71,14 +126,40 @@ func (c *Client) PluginURL() string { return c.pluginBaseURL }
 // RCAPICall sends an HTTP PUT request to the Remote Control API and
 // returns the response body as raw JSON. The RC API uses PUT for all
 // mutating operations (set property, call function, search assets).
+// The call goes to the editor instance selected in ctx, if any. During
+// a dry run, writes are validated by the plugin instead.
 func (c *Client) RCAPICall(ctx context.Context, endpoint string, body any) (json.RawMessage, error) {
-	return c.doRequest(ctx, http.MethodPut, c.rcAPIBaseURL+endpoint, body, "RC API")
+	c, err := c.route(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if d := dryRunFrom(ctx); d != nil && mutates(http.MethodPut, endpoint, body) {
+		return c.validate(ctx, d, endpoint, body)
+	}
+	return c.doRequest(ctx, http.MethodPut, c.rcAPIBaseURL+endpoint, body, rcAPIService)
 }
 
 // PluginCall sends an HTTP POST request to the MCPUnreal editor plugin
-// and returns the response body as raw JSON.
+// and returns the response body as raw JSON. It fails fast, without
+// sending the request, when the negotiated plugin lacks the endpoint.
+// The call goes to the editor instance selected in ctx, if any. During
+// a dry run, mutating calls are validated instead of executed.
 func (c *Client) PluginCall(ctx context.Context, endpoint string, body any) (json.RawMessage, error) {
-	return c.doRequest(ctx, http.MethodPost, c.pluginBaseURL+endpoint, body, "MCPUnreal plugin")
+	c, err := c.route(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.requireRoute(ctx, endpoint); err != nil {
+		return nil, err
+	}
+	if d := dryRunFrom(ctx); d != nil && mutates(http.MethodPost, endpoint, body) {
+		return c.validate(ctx, d, endpoint, body)
+	}
+	raw, err := c.doRequest(ctx, http.MethodPost, c.pluginBaseURL+endpoint, body, pluginService)
+	if err != nil {
+		return nil, c.routeNotFound(err, endpoint)
+	}
+	return raw, nil
 }
 
 // PingRCAPI checks whether the Remote Control API is reachable.
@@ -100,45 +181,165 @@ func (c *Client) PingPlugin(ctx context.Context) bool {
 // doRequest performs an HTTP request and returns the response body.
 // It handles JSON marshaling, content-type headers, and produces
 // user-friendly errors when the editor is offline.
+//
+// Calls to a named service go through that service's circuit breaker and
+// are retried with jittered backoff: connection failures always (the
+// request never reached the editor), timeouts and 502/503/504/429 only
+// for idempotent calls. Unnamed calls (pings) are sent once. Each call
+// is traced and counted as one editor request, however many attempts it
+// takes.
 func (c *Client) doRequest(ctx context.Context, method, url string, body any, serviceName string) (json.RawMessage, error) {
-	var bodyReader io.Reader
+	endpoint := endpointOf(url)
+	ctx, end := telemetry.StartEditorRequest(ctx, metricsService(endpoint), method, endpoint)
+	var ex exchange
+	raw, err := c.send(ctx, method, url, body, serviceName, &ex)
+	end(ex.status, ex.attempts, err)
+	return raw, err
+}
+
+// exchange tracks the attempts of one editor request for telemetry.
+type exchange struct {
+	status   int
+	attempts int
+}
+
+func (e *exchange) add(status int) {
+	e.status = status
+	e.attempts++
+}
+
+// metricsService names the editor service of an endpoint in telemetry.
+func metricsService(endpoint string) string {
+	if strings.HasPrefix(endpoint, "/remote/") {
+		return "rc_api"
+	}
+	return "plugin"
+}
+
+// send is doRequest without the telemetry.
+func (c *Client) send(ctx context.Context, method, url string, body any, serviceName string, ex *exchange) (json.RawMessage, error) {
+	var data []byte
 	if body != nil {
-		data, err := json.Marshal(body)
-		if err != nil {
+		var err error
+		if data, err = json.Marshal(body); err != nil {
 			return nil, fmt.Errorf("marshaling request body: %w", err)
 		}
+	}
+
+	if serviceName == "" {
+		respBody, status, err := c.attempt(ctx, method, url, data)
+		ex.add(status)
+		if err != nil {
+			return nil, err
+		}
+		return checkResponse(respBody, status, serviceName)
+	}
+
+	b := c.breakerFor(serviceName)
+	if ok, wait := b.allow(); !ok {
+		state := b.snapshot(serviceName)
+		return nil, toolerr.New(toolerr.EditorOffline,
+			"%s unreachable at %s — circuit open after %d consecutive failures, next attempt in %s "+
+				"(ensure UE is running with %s enabled): %s",
+			serviceName, url, state.ConsecutiveFailures, wait.Round(time.Second), serviceName, state.LastError,
+		)
+	}
+
+	policy := c.retryPolicy()
+	idem := idempotent(method, endpointOf(url), data)
+	var (
+		respBody []byte
+		status   int
+		err      error
+		kind     failureKind
+		attempts int
+	)
+	for attempts = 1; ; attempts++ {
+		respBody, status, err = c.attempt(ctx, method, url, data)
+		ex.add(status)
+		kind = classify(err, status)
+		if kind == failNone && err == nil {
+			b.record(false, nil)
+			return checkResponse(respBody, status, serviceName)
+		}
+		if !retryable(kind, idem) || attempts >= policy.MaxAttempts || ctx.Err() != nil {
+			break
+		}
+		delay := policy.backoff(attempts)
+		c.logger.Debug("retrying editor request", "service", serviceName, "url", url,
+			"attempt", attempts, "delay", delay, "error", err, "status", status)
+		if sleepCtx(ctx, delay) != nil {
+			break
+		}
+	}
+
+	if kind == failUnsent && serviceName == pluginService {
+		c.forgetPlugin()
+	}
+	switch kind {
+	case failUnsent, failTransient:
+		b.record(true, attemptError(err, status))
+	default:
+		b.release()
+	}
+
+	suffix := ""
+	if attempts > 1 {
+		suffix = fmt.Sprintf(" (after %d attempts)", attempts)
+	}
+	if err != nil {
+		return nil, toolerr.New(unreachableCode(err),
+			"%s unreachable at %s — ensure UE is running with %s enabled%s: %w",
+			serviceName, url, serviceName, suffix, err,
+		)
+	}
+	return nil, toolerr.New(statusCode(serviceName, status, string(respBody)),
+		"%s returned HTTP %d%s: %s", serviceName, status, suffix, truncate(string(respBody), 500))
+}
+
+// attempt sends one HTTP request and returns the response body and status.
+// The request is bounded by ctx's deadline, such as a configured per-tool
+// timeout, or else by defaultRequestTimeout.
+func (c *Client) attempt(ctx context.Context, method, url string, data []byte) ([]byte, int, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, defaultRequestTimeout)
+		defer cancel()
+	}
+	var bodyReader io.Reader
+	if data != nil {
 		bodyReader = bytes.NewReader(data)
 	}
 
 	req, err := http.NewRequestWithContext(ctx, method, url, bodyReader)
 	if err != nil {
-		return nil, fmt.Errorf("creating request: %w", err)
+		return nil, 0, fmt.Errorf("creating request: %w", err)
 	}
-	if body != nil {
+	if data != nil {
 		req.Header.Set("Content-Type", "application/json")
 	}
+	telemetry.InjectHeaders(ctx, req.Header)
 
 	c.logger.Debug("editor HTTP request", "method", method, "url", url)
 
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		if serviceName != "" {
-			return nil, fmt.Errorf(
-				"%s unreachable at %s — ensure UE is running with %s enabled: %w",
-				serviceName, url, serviceName, err,
-			)
-		}
-		return nil, err
+		return nil, 0, err
 	}
 	defer func() { _ = resp.Body.Close() }()
 
 	respBody, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return nil, fmt.Errorf("reading response from %s: %w", serviceName, err)
+		return nil, resp.StatusCode, fmt.Errorf("reading response: %w", err)
 	}
+	return respBody, resp.StatusCode, nil
+}
 
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return nil, fmt.Errorf("%s returned HTTP %d: %s", serviceName, resp.StatusCode, truncate(string(respBody), 500))
+// checkResponse turns a non-2xx status or an {"error": "..."} payload
+// into an error.
+func checkResponse(respBody []byte, status int, serviceName string) (json.RawMessage, error) {
+	if status < 200 || status >= 300 {
+		return nil, &HTTPError{Service: serviceName, Status: status, Body: string(respBody)}
 	}
 
 	// The UE plugin may return HTTP 200 with an error payload.
@@ -147,12 +348,65 @@ func (c *Client) doRequest(ctx context.Context, method, url string, body any, se
 		Error string `json:"error"`
 	}
 	if json.Unmarshal(respBody, &errCheck) == nil && errCheck.Error != "" {
-		return nil, fmt.Errorf("%s: %s", serviceName, errCheck.Error)
+		return nil, toolerr.New(messageCode(errCheck.Error), "%s: %s", serviceName, errCheck.Error)
 	}
 
 	return json.RawMessage(respBody), nil
 }
 
+// attemptError describes a failed attempt for breaker state.
+func attemptError(err error, status int) error {
+	if err != nil {
+		return err
+	}
+	return fmt.Errorf("HTTP %d", status)
+}
+
+// endpointOf returns the path of a request URL.
+func endpointOf(rawURL string) string {
+	if u, err := url.Parse(rawURL); err == nil {
+		return u.Path
+	}
+	return rawURL
+}
+
+// retryPolicy returns the client's retry policy, defaulting when unset.
+func (c *Client) retryPolicy() RetryPolicy {
+	if c.retry.MaxAttempts <= 0 {
+		return DefaultRetryPolicy
+	}
+	return c.retry
+}
+
+// breakerFor returns the circuit breaker for a service, creating it on
+// first use.
+func (c *Client) breakerFor(service string) *breaker {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.breakers == nil {
+		c.breakers = make(map[string]*breaker)
+	}
+	b, ok := c.breakers[service]
+	if !ok {
+		policy := c.breakerPolicy
+		if policy.FailureThreshold <= 0 {
+			policy = DefaultBreakerPolicy
+		}
+		b = newBreaker(policy)
+		c.breakers[service] = b
+	}
+	return b
+}
+
+// BreakerStates reports the circuit breaker state of the plugin and the
+// Remote Control API connections.
+func (c *Client) BreakerStates() []BreakerState {
+	return []BreakerState{
+		c.breakerFor(pluginService).snapshot(pluginService),
+		c.breakerFor(rcAPIService).snapshot(rcAPIService),
+	}
+}
+
 // truncate shortens a string to maxLen, appending "..." if truncated.
 func truncate(s string, maxLen int) string {
 	if len(s) <= maxLen {
@@ -9,8 +9,13 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
+	"time"
+
+	"github.com/remiphilippe/mcp-unreal/internal/config"
+	"github.com/remiphilippe/mcp-unreal/internal/sandbox"
 )
 
 func testLogger() *slog.Logger {
@@ -23,9 +28,24 @@ func newTestClient(rcAPIURL, pluginURL string) *Client {
 		pluginBaseURL: pluginURL,
 		httpClient:    &http.Client{},
 		logger:        testLogger(),
+		retry:         fastRetry,
+	}
+}
+
+// testPaths returns a path sandbox rooted at a temporary project
+// directory, and that directory with symlinks resolved.
+func testPaths(t *testing.T) (*sandbox.Sandbox, string) {
+	t.Helper()
+	dir, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
 	}
+	return sandbox.New(&config.Config{ProjectRoot: dir}), dir
 }
 
+// fastRetry keeps retry paths exercised without slowing tests down.
+var fastRetry = RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: 2 * time.Millisecond}
+
 func TestClient_RCAPICall_PUT(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != http.MethodPut {
@@ -8,12 +8,15 @@ import (
 	"fmt"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/remiphilippe/mcp-unreal/internal/toolerr"
 )
 
 // --- get_actor_components ---
 
 // GetActorComponentsInput defines parameters for the get_actor_components tool.
 type GetActorComponentsInput struct {
+	InstanceInput
+
 	ActorPath         string `json:"actor_path,omitempty" jsonschema:"Full object path of the actor"`
 	ActorName         string `json:"actor_name,omitempty" jsonschema:"Display name of the actor"`
 	IncludeTransforms bool   `json:"include_transforms,omitempty" jsonschema:"Include relative transform for each component. Default false."`
@@ -72,7 +75,7 @@ func (h *Handler) RegisterComponents(server *mcp.Server) {
 // GetActorComponents implements the get_actor_components tool.
 func (h *Handler) GetActorComponents(ctx context.Context, req *mcp.CallToolRequest, input GetActorComponentsInput) (*mcp.CallToolResult, GetActorComponentsOutput, error) {
 	if input.ActorPath == "" && input.ActorName == "" {
-		return nil, GetActorComponentsOutput{}, fmt.Errorf("either actor_path or actor_name is required")
+		return nil, GetActorComponentsOutput{}, toolerr.New(toolerr.Validation, "either actor_path or actor_name is required")
 	}
 
 	body := map[string]any{
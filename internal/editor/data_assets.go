@@ -8,6 +8,7 @@ import (
 	"fmt"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/remiphilippe/mcp-unreal/internal/toolerr"
 )
 
 // --- data_asset_ops ---
@@ -28,6 +29,11 @@ type DataTableInfo struct {
 
 // DataAssetOpsInput defines parameters for the data_asset_ops tool.
 type DataAssetOpsInput struct {
+	InstanceInput
+	TransactionInput
+	DryRunInput
+	PageInput
+
 	Operation string `json:"operation" jsonschema:"required,Operation: list_tables, get_table, add_row, update_row, delete_row, create_table, import_csv"`
 	// For most operations: target asset.
 	Asset string `json:"asset,omitempty" jsonschema:"DataTable asset path (e.g. /Game/Data/DT_Items). For get_table, add_row, update_row, delete_row."`
@@ -51,24 +57,26 @@ type DataAssetOpsOutput struct {
 	Rows     []DataTableRow  `json:"rows,omitempty" jsonschema:"table rows (for get_table)"`
 	RowCount int             `json:"row_count,omitempty" jsonschema:"number of rows after operation"`
 	Message  string          `json:"message,omitempty" jsonschema:"status message"`
+	Page     *PageInfo       `json:"page,omitempty" jsonschema:"for get_table and list_tables: which part of the list was returned and the cursor for the next page"`
 }
 
 // RegisterDataAssets adds the data_asset_ops tool to the MCP server.
 func (h *Handler) RegisterDataAssets(server *mcp.Server) {
-	mcp.AddTool(server, &mcp.Tool{
+	mcp.AddTool(server, pagedTool[DataAssetOpsOutput](&mcp.Tool{
 		Name: "data_asset_ops",
 		Description: "Manage UE DataTables: list tables in a folder, read all rows, add/update/delete rows, " +
 			"create new tables with a specified row struct, and import from CSV. " +
 			"Operations: list_tables, get_table, add_row, update_row, delete_row, create_table, import_csv. " +
 			"Row data is serialized as JSON key-value pairs. " +
-			"Requires the editor running with the MCPUnreal plugin loaded (port 8090).",
-	}, h.DataAssetOps)
+			"Requires the editor running with the MCPUnreal plugin loaded (port 8090). " +
+			"get_table and list_tables are paged: " + pagedDescription,
+	}, "rows", "tables"), h.DataAssetOps)
 }
 
 // DataAssetOps implements the data_asset_ops tool.
 func (h *Handler) DataAssetOps(ctx context.Context, req *mcp.CallToolRequest, input DataAssetOpsInput) (*mcp.CallToolResult, DataAssetOpsOutput, error) {
 	if input.Operation == "" {
-		return nil, DataAssetOpsOutput{}, fmt.Errorf("operation is required")
+		return nil, DataAssetOpsOutput{}, toolerr.New(toolerr.Validation, "operation is required")
 	}
 
 	body := map[string]any{
@@ -93,7 +101,11 @@ func (h *Handler) DataAssetOps(ctx context.Context, req *mcp.CallToolRequest, in
 		body["row_struct"] = input.RowStruct
 	}
 	if input.SourcePath != "" {
-		body["source_path"] = input.SourcePath
+		path, err := h.resolvePath("source_path", input.SourcePath)
+		if err != nil {
+			return nil, DataAssetOpsOutput{}, err
+		}
+		body["source_path"] = path
 	}
 
 	resp, err := h.Client.PluginCall(ctx, "/api/data/ops", body)
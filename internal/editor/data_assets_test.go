@@ -7,6 +7,7 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"path/filepath"
 	"strings"
 	"testing"
 
@@ -165,10 +166,12 @@ func TestDataAssetOps_DeleteRow(t *testing.T) {
 }
 
 func TestDataAssetOps_ImportCSV(t *testing.T) {
+	paths, dir := testPaths(t)
+	sourcePath := filepath.Join(dir, "items.csv")
 	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		var body map[string]any
 		_ = json.NewDecoder(r.Body).Decode(&body)
-		if body["source_path"] != "/tmp/items.csv" {
+		if body["source_path"] != sourcePath {
 			t.Errorf("expected source_path, got %v", body["source_path"])
 		}
 		w.Header().Set("Content-Type", "application/json")
@@ -182,10 +185,11 @@ func TestDataAssetOps_ImportCSV(t *testing.T) {
 	defer ts.Close()
 
 	h := newDataAssetTestHandler(ts)
+	h.Paths = paths
 	_, out, err := h.DataAssetOps(context.Background(), &mcp.CallToolRequest{}, DataAssetOpsInput{
 		Operation:  "import_csv",
 		Asset:      "/Game/Data/DT_Items",
-		SourcePath: "/tmp/items.csv",
+		SourcePath: sourcePath,
 	})
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
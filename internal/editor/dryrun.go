@@ -0,0 +1,164 @@
+// Copyright (c) mcp-unreal project contributors. Apache-2.0 license.
+
+package editor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/remiphilippe/mcp-unreal/internal/toolerr"
+)
+
+// validateRoute is the plugin route that checks a mutating request and
+// reports the objects it would change, without committing anything.
+const validateRoute = "/api/validate"
+
+// DryRunInput is embedded in the input of every editor tool that changes
+// editor state. DryRunMiddleware reads it from the raw arguments, so
+// handlers do not use it directly.
+type DryRunInput struct {
+	DryRun bool `json:"dry_run,omitempty" jsonschema:"Preview the call: the editor validates each change and reports the objects it would affect, without committing anything"`
+}
+
+// AffectedObject is an object a validated change would touch.
+type AffectedObject struct {
+	Path   string `json:"path" jsonschema:"object or asset path"`
+	Class  string `json:"class,omitempty" jsonschema:"UE class of the object"`
+	Change string `json:"change" jsonschema:"created, modified, or deleted"`
+}
+
+// DryRunChange is the plugin's verdict on one mutating request.
+type DryRunChange struct {
+	Route    string           `json:"route" jsonschema:"editor route the request was for"`
+	Valid    bool             `json:"valid" jsonschema:"whether the request would succeed"`
+	Reason   string           `json:"reason,omitempty" jsonschema:"why the request would fail"`
+	Note     string           `json:"note,omitempty" jsonschema:"caveat on how thoroughly the editor could check the request"`
+	Affected []AffectedObject `json:"affected" jsonschema:"objects the request would create, modify, or delete"`
+}
+
+// DryRunOutput replaces the result of a tool called with dry_run=true.
+type DryRunOutput struct {
+	DryRun  bool           `json:"dry_run" jsonschema:"always true: nothing was committed"`
+	Valid   bool           `json:"valid" jsonschema:"whether every change would succeed"`
+	Changes []DryRunChange `json:"changes" jsonschema:"validated changes in the order the tool would make them; validation stops at the first invalid one"`
+}
+
+type dryRunKey struct{}
+
+// dryRun collects the changes validated in place of a tool's mutating
+// editor calls.
+type dryRun struct {
+	mu      sync.Mutex
+	changes []DryRunChange
+}
+
+func (d *dryRun) add(c DryRunChange) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.changes = append(d.changes, c)
+}
+
+// dryRunFrom returns the dry run started in ctx, or nil.
+func dryRunFrom(ctx context.Context) *dryRun {
+	d, _ := ctx.Value(dryRunKey{}).(*dryRun)
+	return d
+}
+
+// DryRunMiddleware previews tools/call requests that carry dry_run=true.
+// While the tool runs, its mutating editor calls are sent to the
+// plugin's validation route instead of being executed, and the tool's
+// result is replaced by the validation report. Reads still reach the
+// editor, so tools that look things up first behave as usual. Tools that
+// make no mutating editor call, such as the headless tools, which handle
+// dry_run themselves, keep their own result. Install it with
+// server.AddReceivingMiddleware after TransactionMiddleware.
+func DryRunMiddleware(next mcp.MethodHandler) mcp.MethodHandler {
+	return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+		if !dryRunRequested(req) {
+			return next(ctx, method, req)
+		}
+		d := &dryRun{}
+		res, err := next(context.WithValue(ctx, dryRunKey{}, d), method, req)
+		d.mu.Lock()
+		changes := d.changes
+		d.mu.Unlock()
+		if len(changes) == 0 {
+			return res, err
+		}
+
+		out := DryRunOutput{DryRun: true, Valid: true, Changes: changes}
+		for i := range changes {
+			if changes[i].Affected == nil {
+				changes[i].Affected = []AffectedObject{}
+			}
+			out.Valid = out.Valid && changes[i].Valid
+		}
+		data, jerr := json.Marshal(out)
+		if jerr != nil {
+			return res, err
+		}
+		return &mcp.CallToolResult{
+			Content:           []mcp.Content{&mcp.TextContent{Text: string(data)}},
+			StructuredContent: out,
+		}, nil
+	}
+}
+
+// dryRunRequested reports whether req is a tools/call with dry_run=true.
+func dryRunRequested(req mcp.Request) bool {
+	call, ok := req.(*mcp.CallToolRequest)
+	if !ok || call.Params == nil || len(call.Params.Arguments) == 0 {
+		return false
+	}
+	var args struct {
+		DryRun bool `json:"dry_run"`
+	}
+	return json.Unmarshal(call.Params.Arguments, &args) == nil && args.DryRun
+}
+
+// mutates reports whether a request to endpoint may change editor
+// state: Remote Control property writes, and anything idempotent does
+// not vouch for.
+func mutates(method, endpoint string, body any) bool {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return true
+	}
+	if endpoint == "/remote/object/property" {
+		var prop struct {
+			Access string `json:"access"`
+		}
+		return json.Unmarshal(data, &prop) != nil || !strings.HasPrefix(prop.Access, "READ")
+	}
+	return !idempotent(method, endpoint, data)
+}
+
+// validate sends a mutating request for route to the plugin's validation
+// route and records the verdict in d. It returns an empty object for a
+// valid request, so the tool goes on to its next change, and an error for
+// an invalid one, so it stops.
+func (c *Client) validate(ctx context.Context, d *dryRun, route string, body any) (json.RawMessage, error) {
+	if err := c.requireRoute(ctx, validateRoute); err != nil {
+		return nil, err
+	}
+	raw, err := c.doRequest(ctx, http.MethodPost, c.pluginBaseURL+validateRoute,
+		map[string]any{"route": route, "body": body}, pluginService)
+	if err != nil {
+		return nil, c.routeNotFound(err, validateRoute)
+	}
+	var change DryRunChange
+	if err := json.Unmarshal(raw, &change); err != nil {
+		return nil, fmt.Errorf("parsing validation response: %w", err)
+	}
+	change.Route = route
+	d.add(change)
+	if !change.Valid {
+		return nil, toolerr.New(messageCode(change.Reason), "dry run: %s would fail: %s", route, change.Reason)
+	}
+	return json.RawMessage(`{}`), nil
+}
@@ -0,0 +1,125 @@
+// Copyright (c) mcp-unreal project contributors. Apache-2.0 license.
+
+package editor
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/remiphilippe/mcp-unreal/internal/config"
+)
+
+func TestMutates(t *testing.T) {
+	tests := []struct {
+		method, endpoint string
+		body             any
+		want             bool
+	}{
+		{"POST", "/api/actors/list", map[string]any{}, false},
+		{"POST", "/api/actors/delete", map[string]any{"actor_names": []string{"A"}}, true},
+		{"POST", "/api/data/ops", map[string]any{"operation": "get_table"}, false},
+		{"POST", "/api/data/ops", map[string]any{"operation": "delete_row"}, true},
+		{"PUT", "/remote/object/property", map[string]any{"access": "READ_ACCESS"}, false},
+		{"PUT", "/remote/object/property", map[string]any{"access": "WRITE_TRANSACTION_ACCESS"}, true},
+		{"PUT", "/remote/object/call", map[string]any{"functionName": "K2_SetActorLocation"}, true},
+	}
+	for _, tt := range tests {
+		if got := mutates(tt.method, tt.endpoint, tt.body); got != tt.want {
+			t.Errorf("mutates(%s %s, %v) = %v, want %v", tt.method, tt.endpoint, tt.body, got, tt.want)
+		}
+	}
+}
+
+func TestDryRunMiddleware(t *testing.T) {
+	fake, pluginPort, rcPort := startFake(t)
+	client := NewClient(&config.Config{PluginPort: pluginPort, RCAPIPort: rcPort}, testLogger())
+	h := &Handler{Client: client, Logger: testLogger()}
+	server := mcp.NewServer(&mcp.Implementation{Name: "test"}, nil)
+	server.AddReceivingMiddleware(InstanceMiddleware, client.TransactionMiddleware, DryRunMiddleware)
+	h.RegisterActors(server)
+	h.RegisterDataAssets(server)
+	h.RegisterTransactions(server)
+
+	ctx := context.Background()
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	ss, err := server.Connect(ctx, serverTransport, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = ss.Close() }()
+	session, err := mcp.NewClient(&mcp.Implementation{Name: "test-client"}, nil).Connect(ctx, clientTransport, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = session.Close() }()
+
+	dryRun := func(name string, args map[string]any) DryRunOutput {
+		t.Helper()
+		args["dry_run"] = true
+		res, err := session.CallTool(ctx, &mcp.CallToolParams{Name: name, Arguments: args})
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if res.IsError {
+			t.Fatalf("%s: %+v", name, res.Content)
+		}
+		var out DryRunOutput
+		raw, _ := json.Marshal(res.StructuredContent)
+		if err := json.Unmarshal(raw, &out); err != nil || !out.DryRun {
+			t.Fatalf("%s: not a dry-run report: %s", name, raw)
+		}
+		return out
+	}
+
+	// delete_actors reports the actor it would delete and keeps it.
+	out := dryRun("delete_actors", map[string]any{"actor_names": []string{"PlayerStart"}, "transaction": "Cleanup"})
+	if !out.Valid || len(out.Changes) != 1 || len(out.Changes[0].Affected) != 1 {
+		t.Fatalf("delete_actors dry run = %+v", out)
+	}
+	if got := out.Changes[0].Affected[0]; got.Path != "/Game/Maps/Main.Main:PersistentLevel.PlayerStart" || got.Change != "deleted" {
+		t.Errorf("affected = %+v", got)
+	}
+	if _, ok := fake.Actor("PlayerStart"); !ok {
+		t.Error("dry run deleted the actor")
+	}
+
+	// Every change of a multi-step tool is validated; none is applied.
+	out = dryRun("move_actor", map[string]any{
+		"object_path": "/Game/Maps/Main.Main:PersistentLevel.PlayerStart",
+		"location":    []float64{1, 2, 3},
+		"rotation":    []float64{0, 90, 0},
+	})
+	if !out.Valid || len(out.Changes) != 2 || out.Changes[1].Affected[0].Change != "modified" {
+		t.Errorf("move_actor dry run = %+v", out)
+	}
+	if a, _ := fake.Actor("PlayerStart"); a.Location != [3]float64{0, 0, 100} {
+		t.Errorf("dry run moved the actor to %v", a.Location)
+	}
+
+	// An invalid change is reported, not returned as a tool error.
+	out = dryRun("move_actor", map[string]any{
+		"object_path": "/Game/Maps/Main.Main:PersistentLevel.Nope",
+		"location":    []float64{1, 2, 3},
+	})
+	if out.Valid || len(out.Changes) != 1 || out.Changes[0].Reason == "" {
+		t.Errorf("invalid move dry run = %+v", out)
+	}
+
+	out = dryRun("data_asset_ops", map[string]any{"operation": "delete_row", "asset": "/Game/Data/DT_Items", "row_name": "Sword"})
+	if !out.Valid || out.Changes[0].Route != "/api/data/ops" {
+		t.Errorf("delete_row dry run = %+v", out)
+	}
+
+	// Dry runs are not recorded as undo steps.
+	res, err := session.CallTool(ctx, &mcp.CallToolParams{Name: "undo_history", Arguments: map[string]any{}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var hist UndoHistoryOutput
+	raw, _ := json.Marshal(res.StructuredContent)
+	if err := json.Unmarshal(raw, &hist); err != nil || hist.Total != 0 {
+		t.Errorf("undo_history after dry runs = %s", raw)
+	}
+}
@@ -9,12 +9,15 @@ import (
 	"fmt"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/remiphilippe/mcp-unreal/internal/toolerr"
 )
 
 // --- get_output_log ---
 
 // GetOutputLogInput defines parameters for the get_output_log tool.
 type GetOutputLogInput struct {
+	InstanceInput
+
 	Category     string  `json:"category,omitempty" jsonschema:"Filter by log category substring (e.g. LogTemp, LogBlueprintUserMessages, LogMCPUnreal)"`
 	Verbosity    string  `json:"verbosity,omitempty" jsonschema:"Minimum verbosity: fatal, error, warning, display, log, verbose (default: all)"`
 	Pattern      string  `json:"pattern,omitempty" jsonschema:"Regex pattern to match against log message text (e.g. 'terrain.*failed', 'spawn')"`
@@ -39,6 +42,8 @@ type GetOutputLogOutput struct {
 
 // CaptureViewportInput defines parameters for the capture_viewport tool.
 type CaptureViewportInput struct {
+	InstanceInput
+
 	OutputPath string `json:"output_path,omitempty" jsonschema:"File path to save the PNG screenshot — if empty, returns base64"`
 	World      string `json:"world,omitempty" jsonschema:"Target world: auto (default, PIE if active else editor), pie (error if not running), editor (always editor)"`
 	IncludeUI  bool   `json:"include_ui,omitempty" jsonschema:"If true, capture includes Slate/UMG UI overlays (HUD, menus, debug text). Requires PIE. Uses async FScreenshotRequest — may take one extra frame"`
@@ -58,6 +63,10 @@ type CaptureViewportOutput struct {
 
 // ExecuteScriptInput defines parameters for the execute_script tool.
 type ExecuteScriptInput struct {
+	InstanceInput
+	TransactionInput
+	DryRunInput
+
 	Script string `json:"script" jsonschema:"required,Python script code to execute in the editor (requires Python Editor Script Plugin)"`
 	World  string `json:"world,omitempty" jsonschema:"Target world: auto (default, PIE if active else editor), pie (error if not running), editor (always editor)"`
 }
@@ -170,7 +179,11 @@ func (h *Handler) GetOutputLog(ctx context.Context, req *mcp.CallToolRequest, in
 func (h *Handler) CaptureViewport(ctx context.Context, req *mcp.CallToolRequest, input CaptureViewportInput) (*mcp.CallToolResult, CaptureViewportOutput, error) {
 	body := map[string]any{}
 	if input.OutputPath != "" {
-		body["output_path"] = input.OutputPath
+		path, err := h.resolvePath("output_path", input.OutputPath)
+		if err != nil {
+			return nil, CaptureViewportOutput{}, err
+		}
+		body["output_path"] = path
 	}
 	if input.World != "" {
 		body["world"] = input.World
@@ -219,7 +232,7 @@ func (h *Handler) CaptureViewport(ctx context.Context, req *mcp.CallToolRequest,
 // ExecuteScript implements the execute_script tool.
 func (h *Handler) ExecuteScript(ctx context.Context, req *mcp.CallToolRequest, input ExecuteScriptInput) (*mcp.CallToolResult, ExecuteScriptOutput, error) {
 	if input.Script == "" {
-		return nil, ExecuteScriptOutput{}, fmt.Errorf("script is required")
+		return nil, ExecuteScriptOutput{}, toolerr.New(toolerr.Validation, "script is required")
 	}
 
 	h.Logger.Warn("executing script via editor", "script_length", len(input.Script))
@@ -250,6 +263,8 @@ func (h *Handler) ExecuteScript(ctx context.Context, req *mcp.CallToolRequest, i
 
 // PIEControlInput defines parameters for the pie_control tool.
 type PIEControlInput struct {
+	InstanceInput
+
 	Operation string `json:"operation" jsonschema:"required,Operation: start (begin PIE session), stop (end PIE session), status (check PIE state)"`
 	MapPath   string `json:"map_path,omitempty" jsonschema:"Map to play (e.g. /Game/Maps/MyLevel). Only used with start. Default: current editor map"`
 	Simulate  bool   `json:"simulate,omitempty" jsonschema:"If true, start Simulate In Editor instead of Play In Editor. Only used with start"`
@@ -268,6 +283,8 @@ type PIEControlOutput struct {
 
 // PlayerControlInput defines parameters for the player_control tool.
 type PlayerControlInput struct {
+	InstanceInput
+
 	Operation string      `json:"operation" jsonschema:"required,Operation: get_info (player state), teleport (move pawn), set_rotation (set view direction), set_view_target (change camera target), get_camera (editor viewport), set_camera (move editor viewport)"`
 	Location  *[3]float64 `json:"location,omitempty" jsonschema:"[X,Y,Z] world position in centimeters (for teleport, set_camera)"`
 	Rotation  *[3]float64 `json:"rotation,omitempty" jsonschema:"[Pitch,Yaw,Roll] in degrees (for teleport, set_rotation, set_camera)"`
@@ -294,7 +311,9 @@ type PlayerControlOutput struct {
 // --- live_compile ---
 
 // LiveCompileInput defines parameters for the live_compile tool.
-type LiveCompileInput struct{}
+type LiveCompileInput struct {
+	InstanceInput
+}
 
 // LiveCompileOutput is returned by the live_compile tool.
 type LiveCompileOutput struct {
@@ -323,7 +342,7 @@ func (h *Handler) LiveCompile(ctx context.Context, req *mcp.CallToolRequest, inp
 // PIEControl implements the pie_control tool.
 func (h *Handler) PIEControl(ctx context.Context, req *mcp.CallToolRequest, input PIEControlInput) (*mcp.CallToolResult, PIEControlOutput, error) {
 	if input.Operation == "" {
-		return nil, PIEControlOutput{}, fmt.Errorf("operation is required (start, stop, status)")
+		return nil, PIEControlOutput{}, toolerr.New(toolerr.Validation, "operation is required (start, stop, status)")
 	}
 
 	body := map[string]any{
@@ -354,7 +373,7 @@ func (h *Handler) PIEControl(ctx context.Context, req *mcp.CallToolRequest, inpu
 // PlayerControl implements the player_control tool.
 func (h *Handler) PlayerControl(ctx context.Context, req *mcp.CallToolRequest, input PlayerControlInput) (*mcp.CallToolResult, PlayerControlOutput, error) {
 	if input.Operation == "" {
-		return nil, PlayerControlOutput{}, fmt.Errorf("operation is required (get_info, teleport, set_rotation, set_view_target, get_camera, set_camera)")
+		return nil, PlayerControlOutput{}, toolerr.New(toolerr.Validation, "operation is required (get_info, teleport, set_rotation, set_view_target, get_camera, set_camera)")
 	}
 
 	body := map[string]any{
@@ -7,6 +7,7 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"path/filepath"
 	"testing"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
@@ -72,19 +73,21 @@ func TestGetOutputLog_PluginOffline(t *testing.T) {
 }
 
 func TestCaptureViewport_Success(t *testing.T) {
+	paths, dir := testPaths(t)
+	outputPath := filepath.Join(dir, "screenshot.png")
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if r.URL.Path != "/api/editor/capture_viewport" {
 			t.Errorf("expected /api/editor/capture_viewport, got %s", r.URL.Path)
 		}
 		var body map[string]any
 		_ = json.NewDecoder(r.Body).Decode(&body)
-		if body["output_path"] != "/tmp/screenshot.png" {
-			t.Errorf("expected output_path /tmp/screenshot.png, got %v", body["output_path"])
+		if body["output_path"] != outputPath {
+			t.Errorf("expected output_path %s, got %v", outputPath, body["output_path"])
 		}
 		w.WriteHeader(http.StatusOK)
 		_ = json.NewEncoder(w).Encode(CaptureViewportOutput{
 			Success:  true,
-			FilePath: "/tmp/screenshot.png",
+			FilePath: outputPath,
 			Format:   "png",
 			Width:    1920,
 			Height:   1080,
@@ -95,10 +98,11 @@ func TestCaptureViewport_Success(t *testing.T) {
 	h := &Handler{
 		Client: newTestClient("http://127.0.0.1:1", server.URL),
 		Logger: testLogger(),
+		Paths:  paths,
 	}
 
 	_, out, err := h.CaptureViewport(context.Background(), &mcp.CallToolRequest{}, CaptureViewportInput{
-		OutputPath: "/tmp/screenshot.png",
+		OutputPath: "screenshot.png",
 	})
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
@@ -0,0 +1,114 @@
+// Copyright (c) mcp-unreal project contributors. Apache-2.0 license.
+
+package editortest
+
+import (
+	"context"
+	"time"
+)
+
+// maxEvents is how many events the fake keeps for /api/events/poll,
+// matching the plugin's buffer.
+const maxEvents = 1000
+
+// maxPollWait caps the long-poll timeout, as the plugin does.
+const maxPollWait = 30 * time.Second
+
+// event is one entry of the editor event stream.
+type event struct {
+	Seq  int64          `json:"seq"`
+	Type string         `json:"type"`
+	Time string         `json:"time"`
+	Data map[string]any `json:"data,omitempty"`
+}
+
+// Emit appends an event to the stream served by /api/events/poll, for
+// tests that need events the fake does not raise itself.
+func (s *Server) Emit(typ string, data map[string]any) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.emit(typ, data)
+}
+
+// emit appends an event and wakes pending polls. The caller holds s.mu.
+func (s *Server) emit(typ string, data map[string]any) {
+	if s.validating {
+		return
+	}
+	s.eventSeq++
+	s.events = append(s.events, event{
+		Seq: s.eventSeq, Type: typ, Time: time.Now().UTC().Format(time.RFC3339Nano), Data: data,
+	})
+	if len(s.events) > maxEvents {
+		s.events = s.events[len(s.events)-maxEvents:]
+	}
+	if s.eventsChanged != nil {
+		close(s.eventsChanged)
+	}
+	s.eventsChanged = make(chan struct{})
+}
+
+// pollEvents answers at once when events newer than "since" exist, when
+// "since" is ahead of the stream (a client of an earlier session), or
+// when timeout_ms is 0; otherwise servePlugin holds the request open.
+func (s *Server) pollEvents(body map[string]any) (any, error) {
+	since := int64(num(body, "since"))
+	if since != s.eventSeq || num(body, "timeout_ms") <= 0 {
+		return s.eventBatch(s.eventsSince(since)), nil
+	}
+	wait := time.Duration(num(body, "timeout_ms")) * time.Millisecond
+	return &longPoll{since: since, wait: min(wait, maxPollWait)}, nil
+}
+
+// longPoll is returned by pollEvents to make servePlugin wait.
+type longPoll struct {
+	since int64
+	wait  time.Duration
+}
+
+// waitEvents waits, with s.mu released, until events newer than p.since
+// arrive, the timeout passes, or the request or fake is done. The caller
+// holds s.mu.
+func (s *Server) waitEvents(ctx context.Context, p *longPoll) any {
+	timer := time.NewTimer(p.wait)
+	defer timer.Stop()
+	for {
+		if events := s.eventsSince(p.since); len(events) > 0 {
+			return s.eventBatch(events)
+		}
+		if s.eventsChanged == nil {
+			s.eventsChanged = make(chan struct{})
+		}
+		changed, closed := s.eventsChanged, s.closed
+		s.mu.Unlock()
+		select {
+		case <-changed:
+			s.mu.Lock()
+		case <-timer.C:
+			s.mu.Lock()
+			return s.eventBatch(nil)
+		case <-ctx.Done():
+			s.mu.Lock()
+			return s.eventBatch(nil)
+		case <-closed:
+			s.mu.Lock()
+			return s.eventBatch(nil)
+		}
+	}
+}
+
+func (s *Server) eventsSince(since int64) []event {
+	for i, e := range s.events {
+		if e.Seq > since {
+			return append([]event(nil), s.events[i:]...)
+		}
+	}
+	return nil
+}
+
+func (s *Server) eventBatch(events []event) map[string]any {
+	if events == nil {
+		events = []event{}
+	}
+	return map[string]any{"stream_id": s.streamID, "events": events, "last_seq": s.eventSeq}
+}
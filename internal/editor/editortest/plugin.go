@@ -0,0 +1,775 @@
+// Copyright (c) mcp-unreal project contributors. Apache-2.0 license.
+
+package editortest
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// pluginRoutes maps MCPUnreal plugin paths to their fake handlers. Routes
+// whose effects the world does not model are acknowledged by ack so tools
+// still run end to end during dry-runs.
+var pluginRoutes map[string]routeFunc
+
+func init() {
+	pluginRoutes = map[string]routeFunc{
+		"/api/status": (*Server).status,
+
+		"/api/actors/list":       (*Server).listActors,
+		"/api/actors/spawn":      (*Server).spawnActor,
+		"/api/actors/delete":     (*Server).deleteActors,
+		"/api/actors/components": (*Server).actorComponents,
+
+		"/api/assets/search":       (*Server).searchAssets,
+		"/api/assets/info":         (*Server).assetInfo,
+		"/api/assets/dependencies": (*Server).assetDependencies,
+		"/api/assets/referencers":  (*Server).assetReferencers,
+
+		"/api/blueprints/list":      (*Server).listBlueprints,
+		"/api/blueprints/inspect":   (*Server).inspectBlueprint,
+		"/api/blueprints/get_graph": (*Server).blueprintGraph,
+		"/api/blueprints/modify":    (*Server).modifyBlueprint,
+
+		"/api/editor/console_command":  (*Server).consoleCommand,
+		"/api/editor/output_log":       (*Server).outputLog,
+		"/api/editor/pie_control":      (*Server).pieControl,
+		"/api/editor/player_control":   (*Server).playerControl,
+		"/api/editor/capture_viewport": (*Server).captureViewport,
+		"/api/editor/execute_script":   (*Server).executeScript,
+		"/api/editor/live_compile":     (*Server).liveCompile,
+
+		"/api/levels/ops": (*Server).levelOps,
+
+		"/api/events/poll": (*Server).pollEvents,
+
+		"/api/transactions/begin":   (*Server).transactionBegin,
+		"/api/transactions/end":     (*Server).transactionEnd,
+		"/api/transactions/undo":    (*Server).transactionUndo,
+		"/api/transactions/redo":    (*Server).transactionRedo,
+		"/api/transactions/history": (*Server).transactionHistory,
+
+		"/api/validate": (*Server).validate,
+	}
+	for _, path := range []string{
+		"/api/anim_blueprints/query", "/api/anim_blueprints/modify",
+		"/api/characters/config",
+		"/api/materials/ops", "/api/input/ops", "/api/ism/ops", "/api/fab/ops",
+		"/api/textures/ops", "/api/gas/ops", "/api/pcg/ops", "/api/niagara/ops", "/api/data/ops",
+		"/api/mesh/procedural", "/api/mesh/realtime",
+		"/api/subsystems/query", "/api/ui/query", "/api/network/debug",
+	} {
+		pluginRoutes[path] = ack(path)
+	}
+}
+
+// ack returns a handler that accepts any request to path and logs it.
+func ack(path string) routeFunc {
+	return func(s *Server, body map[string]any) (any, error) {
+		op := str(body, "operation")
+		s.log("LogMCPUnreal", "Log", fmt.Sprintf("%s %s (fake editor: not simulated)", path, op))
+		return map[string]any{
+			"success":   true,
+			"operation": op,
+			"message":   "accepted by fake editor; effects are not simulated",
+		}, nil
+	}
+}
+
+func (s *Server) log(category, verbosity, msg string) {
+	s.world.Log = append(s.world.Log, LogEntry{Category: category, Verbosity: verbosity, Message: msg})
+	if verbosity == "Error" || verbosity == "Fatal" {
+		s.emit("log_error", map[string]any{"category": category, "verbosity": verbosity, "message": msg})
+	}
+}
+
+// --- status ---
+
+func (s *Server) status(map[string]any) (any, error) {
+	out := map[string]any{
+		"name":             "MCPUnreal",
+		"version":          PluginVersion,
+		"protocol_version": ProtocolVersion,
+		"ue_version":       "5.7.0-fake",
+		"project":          "FakeProject",
+		"pie_active":       s.world.PIEActors != nil,
+		"capabilities": []string{"status", "actors", "blueprints", "anim_blueprints", "editor", "assets",
+			"materials", "characters", "input", "levels", "mesh", "pcg", "gas", "niagara", "components",
+			"ism", "fab", "textures", "subsystems", "data_assets", "ui_query", "network_debug"},
+		"routes": s.routes(),
+	}
+	if s.world.PIEActors != nil {
+		out["pie_map"] = s.world.pieMapName()
+	}
+	if port := urlPort(s.rcURL); port != 0 {
+		out["rc_api_port"] = port
+	}
+	return out, nil
+}
+
+// routes lists the plugin routes the fake serves, sorted.
+func (s *Server) routes() []string {
+	out := make([]string, 0, len(pluginRoutes))
+	for path := range pluginRoutes {
+		if !s.removed[path] {
+			out = append(out, path)
+		}
+	}
+	slices.Sort(out)
+	return out
+}
+
+// --- actors ---
+
+// targetWorld resolves the world parameter the way the plugin does: auto
+// (PIE if running, else editor), pie (error if not running), or editor.
+func (s *Server) targetWorld(body map[string]any) (*[]*Actor, bool, error) {
+	switch str(body, "world") {
+	case "", "auto":
+		if s.world.PIEActors != nil {
+			return &s.world.PIEActors, true, nil
+		}
+		return &s.world.Actors, false, nil
+	case "pie":
+		if s.world.PIEActors == nil {
+			return nil, false, fmt.Errorf("PIE is not running — start it with pie_control first")
+		}
+		return &s.world.PIEActors, true, nil
+	case "editor":
+		return &s.world.Actors, false, nil
+	default:
+		return nil, false, fmt.Errorf("Invalid world '%s' — use auto, pie, or editor", str(body, "world"))
+	}
+}
+
+type actorJSON struct {
+	Name     string     `json:"name"`
+	Class    string     `json:"class"`
+	Path     string     `json:"path"`
+	Location [3]float64 `json:"location"`
+	Rotation [3]float64 `json:"rotation"`
+	Scale    [3]float64 `json:"scale"`
+}
+
+func (s *Server) listActors(body map[string]any) (any, error) {
+	actors, pie, err := s.targetWorld(body)
+	if err != nil {
+		return nil, err
+	}
+	classFilter, nameFilter, tagFilter := str(body, "class_filter"), str(body, "name_filter"), str(body, "tag_filter")
+	out := []actorJSON{}
+	for _, a := range *actors {
+		if classFilter != "" && !strings.EqualFold(a.Class, classFilter) {
+			continue
+		}
+		if nameFilter != "" && !strings.Contains(strings.ToLower(a.Name), strings.ToLower(nameFilter)) {
+			continue
+		}
+		if tagFilter != "" && !slices.Contains(a.Tags, tagFilter) {
+			continue
+		}
+		out = append(out, actorJSON{
+			Name: a.Name, Class: a.Class, Path: s.world.actorPath(a, pie),
+			Location: a.Location, Rotation: a.Rotation, Scale: a.Scale,
+		})
+	}
+	return out, nil
+}
+
+func (s *Server) spawnActor(body map[string]any) (any, error) {
+	actors, pie, err := s.targetWorld(body)
+	if err != nil {
+		return nil, err
+	}
+	class := str(body, "class_name")
+	if class == "" {
+		return nil, fmt.Errorf("Missing 'class_name' field")
+	}
+	name := str(body, "name")
+	if name == "" {
+		name = class
+	}
+	a := &Actor{
+		Name:       uniqueName(*actors, name),
+		Class:      class,
+		Location:   vec3(body, "location", [3]float64{}),
+		Rotation:   vec3(body, "rotation", [3]float64{}),
+		Scale:      vec3(body, "scale", [3]float64{1, 1, 1}),
+		Components: []Component{{Name: "DefaultSceneRoot", Class: "SceneComponent", Scene: true, Visible: true}},
+	}
+	*actors = append(*actors, a)
+	s.log("LogMCPUnreal", "Log", fmt.Sprintf("Spawned actor '%s' (%s) at (X=%g Y=%g Z=%g)",
+		a.Name, class, a.Location[0], a.Location[1], a.Location[2]))
+	return map[string]any{
+		"actor_path": s.world.actorPath(a, pie),
+		"actor_name": a.Name,
+		"class":      class,
+	}, nil
+}
+
+func (s *Server) deleteActors(body map[string]any) (any, error) {
+	actors, pie, err := s.targetWorld(body)
+	if err != nil {
+		return nil, err
+	}
+	refs := append(strs(body, "actor_paths"), strs(body, "actor_names")...)
+	if len(refs) == 0 {
+		return nil, fmt.Errorf("Provide 'actor_paths' or 'actor_names'")
+	}
+	deleted := []string{}
+	*actors = slices.DeleteFunc(*actors, func(a *Actor) bool {
+		path := s.world.actorPath(a, pie)
+		if slices.ContainsFunc(refs, func(r string) bool { return r == a.Name || r == path }) {
+			deleted = append(deleted, a.Name)
+			return true
+		}
+		return false
+	})
+	return map[string]any{"deleted_count": len(deleted), "deleted": deleted}, nil
+}
+
+func (s *Server) actorComponents(body map[string]any) (any, error) {
+	actors, pie, err := s.targetWorld(body)
+	if err != nil {
+		return nil, err
+	}
+	ref := str(body, "actor_path")
+	if ref == "" {
+		ref = str(body, "actor_name")
+	}
+	if ref == "" {
+		return nil, fmt.Errorf("Provide 'actor_path' or 'actor_name'")
+	}
+	var actor *Actor
+	for _, a := range *actors {
+		if ref == a.Name || ref == s.world.actorPath(a, pie) {
+			actor = a
+			break
+		}
+	}
+	if actor == nil {
+		return nil, fmt.Errorf("Actor not found: %s", ref)
+	}
+
+	scene, nonScene := []map[string]any{}, []map[string]any{}
+	for _, c := range actor.Components {
+		if !c.Scene {
+			nonScene = append(nonScene, map[string]any{"name": c.Name, "class": c.Class, "is_active": true})
+			continue
+		}
+		info := map[string]any{"name": c.Name, "class": c.Class, "visible": c.Visible}
+		if c.Mesh != "" {
+			info["mesh"] = c.Mesh
+		}
+		if boolean(body, "include_transforms") {
+			info["transform"] = map[string]any{
+				"location": [3]float64{}, "rotation": [3]float64{}, "scale": [3]float64{1, 1, 1},
+			}
+		}
+		scene = append(scene, info)
+	}
+	return map[string]any{
+		"actor":                actor.Name,
+		"class":                actor.Class,
+		"path":                 s.world.actorPath(actor, pie),
+		"components":           scene,
+		"non_scene_components": nonScene,
+		"total_components":     len(actor.Components),
+	}, nil
+}
+
+// --- assets ---
+
+func assetJSON(a *Asset) map[string]any {
+	return map[string]any{"name": a.Name, "path": a.Path, "class": a.Class, "package": a.Package()}
+}
+
+func (s *Server) searchAssets(body map[string]any) (any, error) {
+	classFilter, pathFilter, nameFilter := str(body, "class_filter"), str(body, "path_filter"), str(body, "name_filter")
+	recursive := true
+	if v, ok := body["recursive_path"].(bool); ok {
+		recursive = v
+	}
+	pathFilter = strings.TrimSuffix(pathFilter, "/")
+
+	out := []map[string]any{}
+	for _, a := range s.world.Assets {
+		if classFilter != "" && !strings.EqualFold(a.Class, classFilter) {
+			continue
+		}
+		if nameFilter != "" && !strings.Contains(strings.ToLower(a.Name), strings.ToLower(nameFilter)) {
+			continue
+		}
+		if pathFilter != "" {
+			dir := a.Package()[:strings.LastIndex(a.Package(), "/")]
+			if dir != pathFilter && (!recursive || !strings.HasPrefix(dir, pathFilter+"/")) {
+				continue
+			}
+		}
+		out = append(out, assetJSON(a))
+	}
+	return out, nil
+}
+
+func (s *Server) findAssetParam(body map[string]any) (*Asset, error) {
+	path := str(body, "asset_path")
+	if path == "" {
+		return nil, fmt.Errorf("Missing 'asset_path' field")
+	}
+	a := s.world.findAsset(path)
+	if a == nil {
+		return nil, fmt.Errorf("Asset not found: %s", path)
+	}
+	return a, nil
+}
+
+func (s *Server) assetInfo(body map[string]any) (any, error) {
+	a, err := s.findAssetParam(body)
+	if err != nil {
+		return nil, err
+	}
+	out := assetJSON(a)
+	out["package_flags"] = 0
+	tags := a.Tags
+	if tags == nil {
+		tags = map[string]string{}
+	}
+	out["tags"] = tags
+	return out, nil
+}
+
+func (s *Server) assetDependencies(body map[string]any) (any, error) {
+	a, err := s.findAssetParam(body)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]any{"dependencies": orEmpty(a.Dependencies)}, nil
+}
+
+func (s *Server) assetReferencers(body map[string]any) (any, error) {
+	a, err := s.findAssetParam(body)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]any{"referencers": orEmpty(a.Referencers)}, nil
+}
+
+func orEmpty(list []string) []string {
+	if list == nil {
+		return []string{}
+	}
+	return list
+}
+
+// --- blueprints ---
+
+func (s *Server) listBlueprints(map[string]any) (any, error) {
+	out := []map[string]any{}
+	for _, bp := range s.world.Blueprints {
+		out = append(out, map[string]any{"name": bp.Name, "path": bp.Path, "parent_class": bp.ParentClass})
+	}
+	return out, nil
+}
+
+func (s *Server) findBlueprintParam(body map[string]any) (*Blueprint, error) {
+	path := str(body, "blueprint_path")
+	if path == "" {
+		return nil, fmt.Errorf("Missing 'blueprint_path' field")
+	}
+	bp := s.world.findBlueprint(path)
+	if bp == nil {
+		return nil, fmt.Errorf("Blueprint not found: %s", path)
+	}
+	return bp, nil
+}
+
+func (s *Server) inspectBlueprint(body map[string]any) (any, error) {
+	bp, err := s.findBlueprintParam(body)
+	if err != nil {
+		return nil, err
+	}
+	vars := []map[string]any{}
+	for _, v := range bp.Variables {
+		vars = append(vars, map[string]any{"name": v.Name, "type": v.Type, "is_instance_editable": false})
+	}
+	funcs := []map[string]any{}
+	for _, f := range bp.Functions {
+		funcs = append(funcs, map[string]any{"name": f, "node_count": len(bp.Graphs[f])})
+	}
+	return map[string]any{
+		"name":         bp.Name,
+		"path":         bp.Path,
+		"parent_class": bp.ParentClass,
+		"variables":    vars,
+		"functions":    funcs,
+		"event_graphs": []map[string]any{{"name": "EventGraph", "node_count": len(bp.Graphs["EventGraph"])}},
+	}, nil
+}
+
+func (s *Server) blueprintGraph(body map[string]any) (any, error) {
+	bp, err := s.findBlueprintParam(body)
+	if err != nil {
+		return nil, err
+	}
+	name := str(body, "graph_name")
+	nodes, ok := bp.Graphs[name]
+	if !ok {
+		return nil, fmt.Errorf("Graph '%s' not found in %s", name, bp.Name)
+	}
+	out := []map[string]any{}
+	for i, n := range nodes {
+		out = append(out, map[string]any{
+			"id": n.ID, "class": n.Class, "title": n.Title,
+			"pos_x": i * 300, "pos_y": 0, "comment": "", "pins": []any{},
+		})
+	}
+	return map[string]any{"graph_name": name, "nodes": out}, nil
+}
+
+func (s *Server) modifyBlueprint(body map[string]any) (any, error) {
+	op := str(body, "operation")
+	if op == "create" {
+		return s.createBlueprint(body)
+	}
+	bp, err := s.findBlueprintParam(body)
+	if err != nil {
+		return nil, err
+	}
+	out := map[string]any{"success": true, "compiled": false}
+
+	switch op {
+	case "add_variable":
+		name := str(body, "variable_name")
+		if name == "" {
+			return nil, fmt.Errorf("Missing 'variable_name' field")
+		}
+		if slices.ContainsFunc(bp.Variables, func(v Variable) bool { return v.Name == name }) {
+			return nil, fmt.Errorf("Variable '%s' already exists", name)
+		}
+		bp.Variables = append(bp.Variables, Variable{Name: name, Type: str(body, "variable_type")})
+	case "remove_variable":
+		name := str(body, "variable_name")
+		n := len(bp.Variables)
+		bp.Variables = slices.DeleteFunc(bp.Variables, func(v Variable) bool { return v.Name == name })
+		if len(bp.Variables) == n {
+			return nil, fmt.Errorf("Variable '%s' not found", name)
+		}
+	case "add_function":
+		name := str(body, "function_name")
+		if name == "" {
+			return nil, fmt.Errorf("Missing 'function_name' field")
+		}
+		if _, exists := bp.Graphs[name]; exists {
+			return nil, fmt.Errorf("Graph '%s' already exists", name)
+		}
+		bp.Functions = append(bp.Functions, name)
+		bp.Graphs[name] = []Node{{ID: s.newNodeID(), Class: "K2Node_FunctionEntry", Title: name}}
+	case "remove_function":
+		name := str(body, "function_name")
+		if !slices.Contains(bp.Functions, name) {
+			return nil, fmt.Errorf("Function '%s' not found", name)
+		}
+		bp.Functions = slices.DeleteFunc(bp.Functions, func(f string) bool { return f == name })
+		delete(bp.Graphs, name)
+	case "add_node":
+		class := str(body, "node_class")
+		if class == "" {
+			return nil, fmt.Errorf("Missing 'node_class' field")
+		}
+		graph := graphName(body)
+		if _, ok := bp.Graphs[graph]; !ok {
+			return nil, fmt.Errorf("Graph '%s' not found", graph)
+		}
+		id := s.newNodeID()
+		bp.Graphs[graph] = append(bp.Graphs[graph], Node{ID: id, Class: class, Title: class})
+		out["node_id"] = id
+	case "delete_node":
+		id := str(body, "node_id")
+		found := false
+		for g, nodes := range bp.Graphs {
+			n := len(nodes)
+			bp.Graphs[g] = slices.DeleteFunc(nodes, func(node Node) bool { return node.ID == id })
+			found = found || len(bp.Graphs[g]) != n
+		}
+		if !found {
+			return nil, fmt.Errorf("Node '%s' not found", id)
+		}
+	case "connect_pins", "disconnect_pins", "set_pin_value":
+		// Pins are not modeled; only check the referenced nodes exist.
+		for _, key := range []string{"node_id", "source_node_id", "target_node_id"} {
+			if id := str(body, key); id != "" && !bp.hasNode(id) {
+				return nil, fmt.Errorf("Node '%s' not found", id)
+			}
+		}
+	case "compile":
+		bp.Compiled = true
+		out["compiled"] = true
+		s.log("LogBlueprint", "Log", "Compiled "+bp.Name)
+		s.emit("blueprint_compiled", map[string]any{"blueprint": bp.Path, "name": bp.Name, "success": true})
+		return out, nil
+	default:
+		return nil, fmt.Errorf("Unknown operation '%s'", op)
+	}
+	bp.Compiled = false
+	return out, nil
+}
+
+func (s *Server) createBlueprint(body map[string]any) (any, error) {
+	name, pkg := str(body, "name"), strings.TrimSuffix(str(body, "package_path"), "/")
+	if name == "" || pkg == "" {
+		return nil, fmt.Errorf("Missing 'name' or 'package_path' field")
+	}
+	parent := str(body, "parent_class")
+	if parent == "" {
+		parent = "Actor"
+	}
+	path := pkg + "/" + name + "." + name
+	if s.world.findAsset(path) != nil {
+		return nil, fmt.Errorf("Asset already exists: %s", path)
+	}
+	s.world.Blueprints = append(s.world.Blueprints, &Blueprint{
+		Name: name, Path: path, ParentClass: parent,
+		Graphs: map[string][]Node{"EventGraph": {}},
+	})
+	s.world.Assets = append(s.world.Assets, &Asset{Name: name, Path: path, Class: "Blueprint"})
+	return map[string]any{"success": true, "compiled": false, "path": path}, nil
+}
+
+func (bp *Blueprint) hasNode(id string) bool {
+	for _, nodes := range bp.Graphs {
+		for _, n := range nodes {
+			if n.ID == id {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func graphName(body map[string]any) string {
+	if g := str(body, "graph_name"); g != "" {
+		return g
+	}
+	return "EventGraph"
+}
+
+// newNodeID returns a GUID-shaped node ID, unique within the fake.
+func (s *Server) newNodeID() string {
+	s.nodeID++
+	return fmt.Sprintf("FA4E0000-0000-0000-0000-%012d", s.nodeID)
+}
+
+// --- editor ---
+
+func (s *Server) consoleCommand(body map[string]any) (any, error) {
+	cmd := str(body, "command")
+	if cmd == "" {
+		return nil, fmt.Errorf("Missing 'command' field")
+	}
+	if _, _, err := s.targetWorld(body); err != nil {
+		return nil, err
+	}
+	s.log("LogConsoleResponse", "Display", "Cmd: "+cmd)
+	return map[string]any{"output": ""}, nil
+}
+
+// verbosityRank orders verbosities from most to least severe.
+var verbosityRank = map[string]int{
+	"fatal": 0, "error": 1, "warning": 2, "display": 3, "log": 4, "verbose": 5, "veryverbose": 6,
+}
+
+func (s *Server) outputLog(body map[string]any) (any, error) {
+	category := str(body, "category")
+	maxRank, filterVerbosity := verbosityRank[strings.ToLower(str(body, "verbosity"))]
+	var pattern *regexp.Regexp
+	if p := str(body, "pattern"); p != "" {
+		var err error
+		if pattern, err = regexp.Compile(p); err != nil {
+			return nil, fmt.Errorf("Invalid regex pattern: %s", p)
+		}
+	}
+	maxLines := 100
+	if n, ok := body["max_lines"].(float64); ok && n > 0 {
+		maxLines = int(n)
+	}
+
+	entries := []map[string]any{}
+	for _, e := range s.world.Log {
+		if category != "" && !strings.Contains(e.Category, category) {
+			continue
+		}
+		if filterVerbosity && verbosityRank[strings.ToLower(e.Verbosity)] > maxRank {
+			continue
+		}
+		if pattern != nil && !pattern.MatchString(e.Message) {
+			continue
+		}
+		entries = append(entries, map[string]any{"category": e.Category, "verbosity": e.Verbosity, "message": e.Message})
+	}
+	if len(entries) > maxLines {
+		entries = entries[len(entries)-maxLines:]
+	}
+	return map[string]any{"entries": entries, "count": len(entries)}, nil
+}
+
+// playerPawn is the pawn the fake possesses when PIE starts.
+const playerPawn = "DefaultPawn"
+
+func (s *Server) pieControl(body map[string]any) (any, error) {
+	w := s.world
+	switch op := str(body, "operation"); op {
+	case "start":
+		if w.PIEActors != nil {
+			return nil, fmt.Errorf("PIE session already active")
+		}
+		if m := str(body, "map_path"); m != "" && m != w.Map {
+			if a := w.findAsset(m); a == nil || a.Class != "World" {
+				return nil, fmt.Errorf("Map not found: %s", m)
+			}
+			w.Map = (Asset{Path: m}).Package()
+		}
+		w.PIEActors = make([]*Actor, 0, len(w.Actors)+1)
+		start := [3]float64{}
+		for _, a := range w.Actors {
+			w.PIEActors = append(w.PIEActors, a.clone())
+			if a.Class == "PlayerStart" {
+				start = a.Location
+			}
+		}
+		w.Simulate = boolean(body, "simulate")
+		if !w.Simulate {
+			w.PIEActors = append(w.PIEActors, &Actor{
+				Name: uniqueName(w.PIEActors, playerPawn), Class: playerPawn, Location: start, Scale: [3]float64{1, 1, 1},
+			})
+		}
+		s.log("LogPlayLevel", "Display", "PIE: Play in editor start time for "+w.pieMapName())
+		s.emit("pie_started", map[string]any{"map": w.pieMapName(), "simulate": w.Simulate})
+		return map[string]any{"success": true, "message": "PIE started", "pie_active": true, "pie_map": w.pieMapName()}, nil
+	case "stop":
+		if w.PIEActors == nil {
+			return nil, fmt.Errorf("No PIE session is active")
+		}
+		w.PIEActors, w.Simulate = nil, false
+		s.log("LogPlayLevel", "Display", "PIE: Shutting down PIE online subsystems")
+		s.emit("pie_stopped", map[string]any{"map": w.mapName()})
+		return map[string]any{"success": true, "message": "PIE stopped", "pie_active": false}, nil
+	case "status":
+		out := map[string]any{"success": true, "pie_active": w.PIEActors != nil}
+		if w.PIEActors != nil {
+			out["pie_map"] = w.pieMapName()
+		}
+		return out, nil
+	default:
+		return nil, fmt.Errorf("Unknown operation '%s' — use start, stop, or status", op)
+	}
+}
+
+func (s *Server) playerControl(body map[string]any) (any, error) {
+	w := s.world
+	op := str(body, "operation")
+	switch op {
+	case "get_camera":
+		loc, rot := w.CameraLocation, w.CameraRotation
+		return map[string]any{"success": true, "camera_location": loc, "camera_rotation": rot}, nil
+	case "set_camera":
+		w.CameraLocation = vec3(body, "location", w.CameraLocation)
+		w.CameraRotation = vec3(body, "rotation", w.CameraRotation)
+		loc, rot := w.CameraLocation, w.CameraRotation
+		return map[string]any{"success": true, "camera_location": loc, "camera_rotation": rot}, nil
+	case "get_info", "teleport", "set_rotation", "set_view_target":
+	default:
+		return nil, fmt.Errorf("Unknown operation '%s'", op)
+	}
+
+	if w.PIEActors == nil {
+		return nil, fmt.Errorf("PIE is not running — start it with pie_control first")
+	}
+	var pawn *Actor
+	for _, a := range w.PIEActors {
+		if a.Class == playerPawn {
+			pawn = a
+		}
+	}
+	if pawn == nil {
+		return nil, fmt.Errorf("No player pawn (simulating?)")
+	}
+
+	switch op {
+	case "teleport":
+		pawn.Location = vec3(body, "location", pawn.Location)
+		pawn.Rotation = vec3(body, "rotation", pawn.Rotation)
+	case "set_rotation":
+		pawn.Rotation = vec3(body, "rotation", pawn.Rotation)
+	case "set_view_target":
+		target, _ := w.findActor(str(body, "actor_path"))
+		if target == nil {
+			return nil, fmt.Errorf("Actor not found: %s", str(body, "actor_path"))
+		}
+		return map[string]any{"success": true, "target_path": w.actorPath(target, true)}, nil
+	}
+	loc, rot := pawn.Location, pawn.Rotation
+	return map[string]any{
+		"success":          true,
+		"controller_path":  w.actorPath(&Actor{Name: "PlayerController_0"}, true),
+		"pawn_path":        w.actorPath(pawn, true),
+		"pawn_class":       pawn.Class,
+		"location":         loc,
+		"rotation":         rot,
+		"control_rotation": rot,
+	}, nil
+}
+
+// blankPNG is a 1x1 transparent PNG returned for viewport captures.
+const blankPNG = "iVBORw0KGgoAAAANSUhEUgAAAAEAAAABCAYAAAAfFcSJAAAADUlEQVR42mNkYPhfDwAChwGA60e6kgAAAABJRU5ErkJggg=="
+
+func (s *Server) captureViewport(body map[string]any) (any, error) {
+	if _, _, err := s.targetWorld(body); err != nil {
+		return nil, err
+	}
+	// Never write to output_path: a dry-run must not touch the disk.
+	return map[string]any{"success": true, "image_base64": blankPNG, "format": "png", "width": 1, "height": 1}, nil
+}
+
+func (s *Server) executeScript(body map[string]any) (any, error) {
+	s.log("LogPython", "Log", "fake editor: script not executed")
+	return map[string]any{"success": true, "output": "fake editor: script not executed"}, nil
+}
+
+func (s *Server) liveCompile(map[string]any) (any, error) {
+	s.log("LogLiveCoding", "Display", "Live coding succeeded")
+	return map[string]any{"success": true, "status": "Succeeded"}, nil
+}
+
+func (s *Server) levelOps(body map[string]any) (any, error) {
+	switch op := str(body, "operation"); op {
+	case "get_current":
+		return map[string]any{
+			"level_name":       s.world.mapName(),
+			"package_name":     s.world.Map,
+			"streaming_levels": []any{},
+		}, nil
+	case "list_levels":
+		levels := []map[string]any{}
+		for _, a := range s.world.Assets {
+			if a.Class == "World" {
+				levels = append(levels, map[string]any{"name": a.Name, "path": a.Path, "package": a.Package()})
+			}
+		}
+		return map[string]any{"levels": levels, "count": len(levels)}, nil
+	case "load_level":
+		a := s.world.findAsset(str(body, "level_path"))
+		if a == nil || a.Class != "World" {
+			return nil, fmt.Errorf("Level not found: %s", str(body, "level_path"))
+		}
+		s.world.Map = a.Package()
+		s.emit("level_loaded", map[string]any{"map": s.world.Map})
+		return map[string]any{"success": true, "level_name": s.world.mapName(), "package_name": s.world.Map}, nil
+	case "save_level":
+		s.emit("asset_saved", map[string]any{"package": s.world.Map})
+		return map[string]any{"success": true, "package_name": s.world.Map}, nil
+	default:
+		return ack("/api/levels/ops")(s, body)
+	}
+}
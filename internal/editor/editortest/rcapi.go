@@ -0,0 +1,160 @@
+// Copyright (c) mcp-unreal project contributors. Apache-2.0 license.
+
+package editortest
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// rcRoutes maps Remote Control API paths to their fake handlers.
+var rcRoutes map[string]routeFunc
+
+func init() {
+	rcRoutes = map[string]routeFunc{
+		"/remote/info":            (*Server).rcInfo,
+		"/remote/object/property": (*Server).rcProperty,
+		"/remote/object/call":     (*Server).rcCall,
+	}
+}
+
+func (s *Server) rcInfo(map[string]any) (any, error) {
+	routes := make([]map[string]string, 0, len(rcRoutes))
+	for _, path := range []string{"/remote/info", "/remote/object/property", "/remote/object/call"} {
+		verb := "PUT"
+		if path == "/remote/info" {
+			verb = "GET"
+		}
+		routes = append(routes, map[string]string{"Path": path, "Verb": verb})
+	}
+	return map[string]any{"HttpRoutes": routes}, nil
+}
+
+// rcActor resolves the objectPath of an RC request to an actor.
+func (s *Server) rcActor(body map[string]any) (*Actor, error) {
+	path := str(body, "objectPath")
+	if path == "" {
+		return nil, &statusError{http.StatusBadRequest, "Missing objectPath"}
+	}
+	a, _ := s.world.findActor(path)
+	if a == nil || path == a.Name {
+		// The RC API only accepts full object paths, never labels.
+		return nil, &statusError{http.StatusNotFound, fmt.Sprintf("Object: %s does not exist.", path)}
+	}
+	return a, nil
+}
+
+func (s *Server) rcProperty(body map[string]any) (any, error) {
+	a, err := s.rcActor(body)
+	if err != nil {
+		return nil, err
+	}
+	name := str(body, "propertyName")
+
+	if strings.HasPrefix(str(body, "access"), "READ") {
+		props := a.properties()
+		if name == "" {
+			return props, nil
+		}
+		v, ok := props[name]
+		if !ok {
+			return nil, fmt.Errorf("Property %s does not exist on %s", name, a.Name)
+		}
+		return map[string]any{name: v}, nil
+	}
+
+	if name == "" {
+		return nil, fmt.Errorf("Missing propertyName")
+	}
+	value, ok := body["propertyValue"]
+	if !ok {
+		return nil, fmt.Errorf("Missing propertyValue")
+	}
+	// Callers wrap the value as {"<name>": value}; unwrap it if so.
+	if m, ok := value.(map[string]any); ok && len(m) == 1 {
+		if inner, ok := m[name]; ok {
+			value = inner
+		}
+	}
+	if a.Properties == nil {
+		a.Properties = make(map[string]any)
+	}
+	a.Properties[name] = value
+	return map[string]any{}, nil
+}
+
+// properties returns the actor's readable properties: its transform and
+// tags plus anything written through the RC API.
+func (a *Actor) properties() map[string]any {
+	props := map[string]any{
+		"ActorLabel": a.Name,
+		"Tags":       orEmpty(a.Tags),
+		"RelativeLocation": map[string]float64{
+			"X": a.Location[0], "Y": a.Location[1], "Z": a.Location[2],
+		},
+		"RelativeRotation": map[string]float64{
+			"Pitch": a.Rotation[0], "Yaw": a.Rotation[1], "Roll": a.Rotation[2],
+		},
+		"RelativeScale3D": map[string]float64{
+			"X": a.Scale[0], "Y": a.Scale[1], "Z": a.Scale[2],
+		},
+	}
+	for k, v := range a.Properties {
+		props[k] = v
+	}
+	return props
+}
+
+func (s *Server) rcCall(body map[string]any) (any, error) {
+	fn := str(body, "functionName")
+	if fn == "" {
+		return nil, &statusError{http.StatusBadRequest, "Missing functionName"}
+	}
+	params, _ := body["parameters"].(map[string]any)
+
+	// Static library calls target a class default object, not an actor.
+	if path := str(body, "objectPath"); strings.Contains(path, "Default__") {
+		if fn == "ExecuteConsoleCommand" {
+			s.log("LogConsoleResponse", "Display", "Cmd: "+str(params, "Command"))
+		}
+		return map[string]any{}, nil
+	}
+
+	a, err := s.rcActor(body)
+	if err != nil {
+		return nil, err
+	}
+	switch fn {
+	case "K2_SetActorLocation":
+		v, ok := structVec(params["NewLocation"], "X", "Y", "Z")
+		if !ok {
+			return nil, fmt.Errorf("Invalid NewLocation parameter")
+		}
+		a.Location = v
+		return map[string]any{"ReturnValue": true}, nil
+	case "K2_SetActorRotation":
+		v, ok := structVec(params["NewRotation"], "Pitch", "Yaw", "Roll")
+		if !ok {
+			return nil, fmt.Errorf("Invalid NewRotation parameter")
+		}
+		a.Rotation = v
+		return map[string]any{"ReturnValue": true}, nil
+	case "SetActorScale3D":
+		v, ok := structVec(params["NewScale3D"], "X", "Y", "Z")
+		if !ok {
+			return nil, fmt.Errorf("Invalid NewScale3D parameter")
+		}
+		a.Scale = v
+		return map[string]any{}, nil
+	case "K2_GetActorLocation":
+		return map[string]any{"ReturnValue": map[string]float64{"X": a.Location[0], "Y": a.Location[1], "Z": a.Location[2]}}, nil
+	case "K2_GetActorRotation":
+		return map[string]any{"ReturnValue": map[string]float64{"Pitch": a.Rotation[0], "Yaw": a.Rotation[1], "Roll": a.Rotation[2]}}, nil
+	case "GetActorScale3D":
+		return map[string]any{"ReturnValue": map[string]float64{"X": a.Scale[0], "Y": a.Scale[1], "Z": a.Scale[2]}}, nil
+	default:
+		// Other functions are accepted without effect.
+		return map[string]any{}, nil
+	}
+}
@@ -0,0 +1,434 @@
+// Copyright (c) mcp-unreal project contributors. Apache-2.0 license.
+
+// Package editortest provides an in-memory fake of the UE editor's HTTP
+// surface: the MCPUnreal plugin routes (/api/actors/*, /api/blueprints/*,
+// /api/editor/*, ...) and the Remote Control API (/remote/object/*).
+//
+// The fake keeps a World model of actors, components, assets, Blueprints,
+// the Output Log, and PIE state, so editor tools behave plausibly end to
+// end: a spawned actor shows up in get_level_actors, move_actor changes
+// its transform, and pie_control(start) makes world=pie calls succeed.
+// It is used from Go tests and by `mcp-unreal --fake-editor` for agent
+// dry-runs without a running editor.
+//
+// Usage in tests:
+//
+//	fake := editortest.New(nil)
+//	plugin := httptest.NewServer(fake.PluginHandler())
+//	rc := httptest.NewServer(fake.RCHandler())
+package editortest
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// PluginVersion and ProtocolVersion are what the fake reports from
+// /api/status.
+const (
+	PluginVersion   = "0.5.0-fake"
+	ProtocolVersion = 1
+)
+
+// Call is one HTTP request received by the fake.
+type Call struct {
+	Method string
+	Path   string
+	// Body is the decoded JSON request body, nil if there was none.
+	Body map[string]any
+}
+
+// Fault is an injected failure for the next request to a path.
+type Fault struct {
+	// Status is the HTTP status to return. Zero means HTTP 200 with an
+	// {"error": Message} payload, the way the plugin reports failures.
+	Status  int
+	Message string
+}
+
+// Server is the fake editor. It is safe for concurrent use.
+type Server struct {
+	mu     sync.Mutex
+	world  *World
+	calls  []Call
+	faults map[string][]Fault
+	nodeID int
+	// removed routes answer 404 and are left out of /api/status.
+	removed map[string]bool
+	// token, if set, is the bearer token plugin requests must carry.
+	token string
+
+	// events is the /api/events/poll buffer; eventsChanged is closed and
+	// replaced on every new event to wake pending polls.
+	events        []event
+	eventSeq      int64
+	eventsChanged chan struct{}
+	streamID      string
+	closed        chan struct{}
+	closeOnce     sync.Once
+
+	// undo is the undo history, of which the newest undone steps can be
+	// redone; open is the transaction begun through the API, if any.
+	undo   []transaction
+	undone int
+	open   *openTransaction
+
+	// validating is set while /api/validate runs a request it will roll
+	// back; events are not raised for it.
+	validating bool
+
+	servers   []*http.Server
+	pluginURL string
+	rcURL     string
+}
+
+// New returns a fake serving w. A nil world starts from SampleWorld.
+func New(w *World) *Server {
+	if w == nil {
+		w = SampleWorld()
+	}
+	return &Server{
+		world:    w,
+		faults:   make(map[string][]Fault),
+		streamID: fmt.Sprintf("fake-%d", time.Now().UnixNano()),
+		closed:   make(chan struct{}),
+	}
+}
+
+// Update runs fn with exclusive access to the world, for seeding state
+// or asserting on it from tests.
+func (s *Server) Update(fn func(w *World)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	fn(s.world)
+}
+
+// Actor returns a copy of the actor with the given name or object path,
+// searching the PIE world first.
+func (s *Server) Actor(ref string) (Actor, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	a, _ := s.world.findActor(ref)
+	if a == nil {
+		return Actor{}, false
+	}
+	return *a.clone(), true
+}
+
+// Calls returns the requests received so far, oldest first.
+func (s *Server) Calls() []Call {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Call(nil), s.calls...)
+}
+
+// ResetCalls clears the recorded requests.
+func (s *Server) ResetCalls() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.calls = nil
+}
+
+// InjectFault makes the next request to path fail with f. Faults queue:
+// injecting twice fails the next two requests.
+func (s *Server) InjectFault(path string, f Fault) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults[path] = append(s.faults[path], f)
+}
+
+// RemoveRoutes makes the fake behave like an older plugin that lacks the
+// given routes: they answer 404 and are not listed by /api/status.
+func (s *Server) RemoveRoutes(paths ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.removed == nil {
+		s.removed = make(map[string]bool)
+	}
+	for _, p := range paths {
+		s.removed[p] = true
+	}
+}
+
+// RequireToken makes plugin routes reject requests that do not carry
+// "Authorization: Bearer <token>", as the plugin does when mcp.AuthToken
+// or MCP_UNREAL_EDITOR_TOKEN is set in the editor.
+func (s *Server) RequireToken(token string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.token = token
+}
+
+// PluginHandler returns the handler for the MCPUnreal plugin routes.
+func (s *Server) PluginHandler() http.Handler {
+	return http.HandlerFunc(s.servePlugin)
+}
+
+// RCHandler returns the handler for the Remote Control API routes.
+func (s *Server) RCHandler() http.Handler {
+	return http.HandlerFunc(s.serveRC)
+}
+
+// Start serves the plugin and Remote Control API handlers on ephemeral
+// localhost ports. Use PluginURL and RCAPIURL to reach them and Close
+// to stop.
+func (s *Server) Start() error {
+	pluginURL, err := s.listen(s.PluginHandler())
+	if err != nil {
+		return fmt.Errorf("starting fake plugin server: %w", err)
+	}
+	rcURL, err := s.listen(s.RCHandler())
+	if err != nil {
+		_ = s.Close()
+		return fmt.Errorf("starting fake RC API server: %w", err)
+	}
+	s.pluginURL, s.rcURL = pluginURL, rcURL
+	return nil
+}
+
+func (s *Server) listen(h http.Handler) (string, error) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return "", err
+	}
+	srv := &http.Server{Handler: h, ReadHeaderTimeout: 5 * time.Second}
+	s.servers = append(s.servers, srv)
+	go func() { _ = srv.Serve(ln) }()
+	return "http://" + ln.Addr().String(), nil
+}
+
+// PluginURL returns the plugin base URL after Start.
+func (s *Server) PluginURL() string { return s.pluginURL }
+
+// RCAPIURL returns the Remote Control API base URL after Start.
+func (s *Server) RCAPIURL() string { return s.rcURL }
+
+// urlPort returns the port of a base URL, or 0.
+func urlPort(raw string) int {
+	_, port, err := net.SplitHostPort(strings.TrimPrefix(raw, "http://"))
+	if err != nil {
+		return 0
+	}
+	p, _ := strconv.Atoi(port)
+	return p
+}
+
+// Close stops the servers started by Start and ends pending event polls.
+func (s *Server) Close() error {
+	s.closeOnce.Do(func() { close(s.closed) })
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	var errs []error
+	for _, srv := range s.servers {
+		errs = append(errs, srv.Shutdown(ctx))
+	}
+	s.servers = nil
+	return errors.Join(errs...)
+}
+
+// routeFunc handles one route against the locked world and returns the
+// response value or an error to report.
+type routeFunc func(s *Server, body map[string]any) (any, error)
+
+// statusError is an error reported with a specific HTTP status, as the
+// Remote Control API does for missing objects and bad requests.
+type statusError struct {
+	status int
+	msg    string
+}
+
+func (e *statusError) Error() string { return e.msg }
+
+// begin records the request and pops any injected fault for its path.
+// It returns the decoded body, or writes the error response and returns
+// false.
+func (s *Server) begin(w http.ResponseWriter, r *http.Request) (map[string]any, *Fault, bool) {
+	var body map[string]any
+	data, err := io.ReadAll(io.LimitReader(r.Body, 16<<20))
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "reading request body: " + err.Error()})
+		return nil, nil, false
+	}
+	if len(strings.TrimSpace(string(data))) > 0 {
+		if err := json.Unmarshal(data, &body); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "Invalid JSON body"})
+			return nil, nil, false
+		}
+	}
+
+	s.calls = append(s.calls, Call{Method: r.Method, Path: r.URL.Path, Body: body})
+	if queue := s.faults[r.URL.Path]; len(queue) > 0 {
+		f := queue[0]
+		s.faults[r.URL.Path] = queue[1:]
+		return body, &f, true
+	}
+	return body, nil, true
+}
+
+func (s *Server) servePlugin(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.token != "" && subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), []byte("Bearer "+s.token)) != 1 {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "Missing or invalid auth token"})
+		return
+	}
+
+	body, fault, ok := s.begin(w, r)
+	if !ok {
+		return
+	}
+	if fault != nil {
+		writeFault(w, fault)
+		return
+	}
+
+	route, known := pluginRoutes[r.URL.Path]
+	if !known || s.removed[r.URL.Path] {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "No route for " + r.URL.Path})
+		return
+	}
+	if r.URL.Path != "/api/status" && r.Method != http.MethodPost {
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "Use POST"})
+		return
+	}
+
+	result, err := route(s, body)
+	if err != nil {
+		// The plugin reports failures as HTTP 200 with an error payload.
+		writeJSON(w, http.StatusOK, map[string]string{"error": err.Error()})
+		return
+	}
+	if poll, ok := result.(*longPoll); ok {
+		result = s.waitEvents(r.Context(), poll)
+	}
+	writeJSON(w, http.StatusOK, result)
+}
+
+func (s *Server) serveRC(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	body, fault, ok := s.begin(w, r)
+	if !ok {
+		return
+	}
+	if fault != nil {
+		writeFault(w, fault)
+		return
+	}
+
+	route, known := rcRoutes[r.URL.Path]
+	if !known {
+		writeJSON(w, http.StatusNotFound, rcErrorBody(http.StatusNotFound, "Route not found: "+r.URL.Path))
+		return
+	}
+
+	result, err := route(s, body)
+	if err != nil {
+		status := http.StatusBadRequest
+		var se *statusError
+		if errors.As(err, &se) {
+			status = se.status
+		}
+		writeJSON(w, status, rcErrorBody(status, err.Error()))
+		return
+	}
+	writeJSON(w, http.StatusOK, result)
+}
+
+// rcErrorBody mirrors the Remote Control API's error payload.
+func rcErrorBody(status int, msg string) map[string]any {
+	return map[string]any{"errorCode": status, "errorMessage": msg}
+}
+
+func writeFault(w http.ResponseWriter, f *Fault) {
+	msg := f.Message
+	if msg == "" {
+		msg = "injected fault"
+	}
+	status := f.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	writeJSON(w, status, map[string]string{"error": msg})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// --- request body helpers ---
+
+func str(body map[string]any, key string) string {
+	s, _ := body[key].(string)
+	return s
+}
+
+func num(body map[string]any, key string) float64 {
+	n, _ := body[key].(float64)
+	return n
+}
+
+func boolean(body map[string]any, key string) bool {
+	b, _ := body[key].(bool)
+	return b
+}
+
+func strs(body map[string]any, key string) []string {
+	list, _ := body[key].([]any)
+	out := make([]string, 0, len(list))
+	for _, v := range list {
+		if s, ok := v.(string); ok {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// vec3 reads an [X,Y,Z] array, returning def when absent or short.
+func vec3(body map[string]any, key string, def [3]float64) [3]float64 {
+	list, ok := body[key].([]any)
+	if !ok || len(list) < 3 {
+		return def
+	}
+	var v [3]float64
+	for i := range v {
+		f, ok := list[i].(float64)
+		if !ok {
+			return def
+		}
+		v[i] = f
+	}
+	return v
+}
+
+// structVec reads a UE struct such as {"X":1,"Y":2,"Z":3} in the given
+// field order.
+func structVec(v any, fields ...string) ([3]float64, bool) {
+	m, ok := v.(map[string]any)
+	if !ok {
+		return [3]float64{}, false
+	}
+	var out [3]float64
+	for i, f := range fields {
+		n, ok := m[f].(float64)
+		if !ok {
+			return [3]float64{}, false
+		}
+		out[i] = n
+	}
+	return out, true
+}
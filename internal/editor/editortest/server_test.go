@@ -0,0 +1,480 @@
+// Copyright (c) mcp-unreal project contributors. Apache-2.0 license.
+
+package editortest_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/remiphilippe/mcp-unreal/internal/config"
+	"github.com/remiphilippe/mcp-unreal/internal/editor"
+	"github.com/remiphilippe/mcp-unreal/internal/editor/editortest"
+)
+
+// startFake starts a fake editor and returns it with an editor.Handler
+// pointed at it.
+func startFake(t *testing.T, w *editortest.World) (*editortest.Server, *editor.Handler) {
+	t.Helper()
+	fake := editortest.New(w)
+	if err := fake.Start(); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	t.Cleanup(func() { _ = fake.Close() })
+
+	cfg := &config.Config{RCAPIPort: port(t, fake.RCAPIURL()), PluginPort: port(t, fake.PluginURL())}
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	return fake, &editor.Handler{Client: editor.NewClient(cfg, logger), Logger: logger}
+}
+
+func port(t *testing.T, raw string) int {
+	t.Helper()
+	u, err := url.Parse(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, err := strconv.Atoi(u.Port())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func TestActorLifecycle(t *testing.T) {
+	fake, h := startFake(t, nil)
+	ctx := context.Background()
+
+	_, spawned, err := h.SpawnActor(ctx, nil, editor.SpawnActorInput{ClassName: "PointLight", Name: "Lamp", Location: [3]float64{1, 2, 3}})
+	if err != nil {
+		t.Fatalf("SpawnActor: %v", err)
+	}
+	if spawned.ActorPath != "/Game/Maps/Main.Main:PersistentLevel.Lamp" {
+		t.Errorf("actor_path = %q", spawned.ActorPath)
+	}
+
+	_, list, err := h.GetLevelActors(ctx, nil, editor.GetLevelActorsInput{ClassFilter: "PointLight"})
+	if err != nil {
+		t.Fatalf("GetLevelActors: %v", err)
+	}
+	if list.Total != 1 || list.Actors[0].Location != [3]float64{1, 2, 3} || list.Actors[0].Scale != [3]float64{1, 1, 1} {
+		t.Errorf("list = %+v", list)
+	}
+
+	// move_actor goes through the Remote Control API.
+	loc := [3]float64{10, 20, 30}
+	if _, _, err := h.MoveActor(ctx, nil, editor.MoveActorInput{ObjectPath: spawned.ActorPath, Location: &loc}); err != nil {
+		t.Fatalf("MoveActor: %v", err)
+	}
+	if a, ok := fake.Actor("Lamp"); !ok || a.Location != loc {
+		t.Errorf("actor after move = %+v", a)
+	}
+
+	_, prop, err := h.GetProperty(ctx, nil, editor.GetPropertyInput{ObjectPath: spawned.ActorPath, PropertyName: "RelativeLocation"})
+	if err != nil {
+		t.Fatalf("GetProperty: %v", err)
+	}
+	if !strings.Contains(string(prop.PropertyValue), `"X":10`) {
+		t.Errorf("property = %s", prop.PropertyValue)
+	}
+
+	_, deleted, err := h.DeleteActors(ctx, nil, editor.DeleteActorsInput{ActorNames: []string{"Lamp"}})
+	if err != nil || deleted.DeletedCount != 1 {
+		t.Fatalf("DeleteActors = (%+v, %v)", deleted, err)
+	}
+	if _, ok := fake.Actor("Lamp"); ok {
+		t.Error("actor still present after delete")
+	}
+	if _, _, err := h.MoveActor(ctx, nil, editor.MoveActorInput{ObjectPath: spawned.ActorPath, Location: &loc}); err == nil {
+		t.Error("expected RC API error for deleted actor")
+	}
+}
+
+func TestPIEWorld(t *testing.T) {
+	_, h := startFake(t, nil)
+	ctx := context.Background()
+
+	if _, _, err := h.GetLevelActors(ctx, nil, editor.GetLevelActorsInput{World: "pie"}); err == nil || !strings.Contains(err.Error(), "PIE is not running") {
+		t.Errorf("expected PIE error before start, got %v", err)
+	}
+
+	_, pie, err := h.PIEControl(ctx, nil, editor.PIEControlInput{Operation: "start"})
+	if err != nil || !pie.PIEActive || pie.PIEMap != "UEDPIE_0_Main" {
+		t.Fatalf("PIEControl(start) = (%+v, %v)", pie, err)
+	}
+
+	// Spawning in auto mode targets PIE and leaves the editor level alone.
+	if _, _, err := h.SpawnActor(ctx, nil, editor.SpawnActorInput{ClassName: "Enemy"}); err != nil {
+		t.Fatalf("SpawnActor: %v", err)
+	}
+	_, inPIE, _ := h.GetLevelActors(ctx, nil, editor.GetLevelActorsInput{World: "pie", ClassFilter: "Enemy"})
+	_, inEditor, _ := h.GetLevelActors(ctx, nil, editor.GetLevelActorsInput{World: "editor", ClassFilter: "Enemy"})
+	if inPIE.Total != 1 || inEditor.Total != 0 {
+		t.Errorf("enemy counts pie=%d editor=%d, want 1 and 0", inPIE.Total, inEditor.Total)
+	}
+
+	loc := [3]float64{5, 5, 5}
+	_, player, err := h.PlayerControl(ctx, nil, editor.PlayerControlInput{Operation: "teleport", Location: &loc})
+	if err != nil || player.Location == nil || *player.Location != loc {
+		t.Errorf("PlayerControl(teleport) = (%+v, %v)", player, err)
+	}
+
+	if _, _, err := h.PIEControl(ctx, nil, editor.PIEControlInput{Operation: "stop"}); err != nil {
+		t.Fatalf("PIEControl(stop): %v", err)
+	}
+	_, list, _ := h.GetLevelActors(ctx, nil, editor.GetLevelActorsInput{})
+	if list.Total != 3 {
+		t.Errorf("editor level has %d actors after PIE, want the original 3", list.Total)
+	}
+}
+
+func TestBlueprints(t *testing.T) {
+	_, h := startFake(t, nil)
+	ctx := context.Background()
+
+	_, created, err := h.BlueprintModify(ctx, nil, editor.BlueprintModifyInput{
+		Operation: "create", PackagePath: "/Game/Blueprints", BlueprintName: "BP_Door", ClassName: "Actor",
+	})
+	if err != nil || created.Path != "/Game/Blueprints/BP_Door.BP_Door" {
+		t.Fatalf("create = (%+v, %v)", created, err)
+	}
+	if _, _, err := h.BlueprintModify(ctx, nil, editor.BlueprintModifyInput{
+		Operation: "add_variable", BlueprintPath: created.Path, VariableName: "bOpen", VariableType: "bool",
+	}); err != nil {
+		t.Fatalf("add_variable: %v", err)
+	}
+	_, node, err := h.BlueprintModify(ctx, nil, editor.BlueprintModifyInput{
+		Operation: "add_node", BlueprintPath: created.Path, NodeClass: "K2Node_CallFunction",
+	})
+	if err != nil || node.NodeID == "" {
+		t.Fatalf("add_node = (%+v, %v)", node, err)
+	}
+
+	_, inspected, err := h.BlueprintQuery(ctx, nil, editor.BlueprintQueryInput{Operation: "inspect", Path: created.Path})
+	if err != nil {
+		t.Fatalf("inspect: %v", err)
+	}
+	data, _ := json.Marshal(inspected.Result)
+	if !strings.Contains(string(data), `"name":"bOpen"`) || !strings.Contains(string(data), `"node_count":1`) {
+		t.Errorf("inspect = %s", data)
+	}
+
+	_, compiled, err := h.BlueprintModify(ctx, nil, editor.BlueprintModifyInput{Operation: "compile", BlueprintPath: created.Path})
+	if err != nil || !compiled.Compiled {
+		t.Errorf("compile = (%+v, %v)", compiled, err)
+	}
+
+	_, assets, err := h.SearchAssets(ctx, nil, editor.SearchAssetsInput{ClassFilter: "Blueprint", PathFilter: "/Game/Blueprints"})
+	if err != nil || assets.Total != 2 {
+		t.Errorf("search = (%+v, %v), want BP_Player and BP_Door", assets, err)
+	}
+}
+
+func TestOutputLog(t *testing.T) {
+	w := editortest.SampleWorld()
+	w.Log = []editortest.LogEntry{
+		{Category: "LogTemp", Verbosity: "Log", Message: "spawn wave 1"},
+		{Category: "LogTemp", Verbosity: "Warning", Message: "spawn failed"},
+		{Category: "LogNet", Verbosity: "Error", Message: "connection lost"},
+	}
+	_, h := startFake(t, w)
+
+	tests := []struct {
+		name  string
+		input editor.GetOutputLogInput
+		want  int
+	}{
+		{"all", editor.GetOutputLogInput{}, 3},
+		{"category", editor.GetOutputLogInput{Category: "LogTemp"}, 2},
+		{"verbosity", editor.GetOutputLogInput{Verbosity: "warning"}, 2},
+		{"pattern", editor.GetOutputLogInput{Pattern: "spawn.*failed"}, 1},
+		{"max lines", editor.GetOutputLogInput{MaxLines: 1}, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, out, err := h.GetOutputLog(context.Background(), nil, tt.input)
+			if err != nil {
+				t.Fatalf("GetOutputLog: %v", err)
+			}
+			if out.Count != tt.want {
+				t.Errorf("count = %d, want %d: %+v", out.Count, tt.want, out.Entries)
+			}
+		})
+	}
+}
+
+func TestInjectFaultAndCalls(t *testing.T) {
+	fake, h := startFake(t, nil)
+	ctx := context.Background()
+
+	fake.InjectFault("/api/actors/list", editortest.Fault{Message: "editor busy"})
+	fake.InjectFault("/api/actors/list", editortest.Fault{Status: http.StatusServiceUnavailable})
+	fake.InjectFault("/api/actors/spawn", editortest.Fault{Status: http.StatusServiceUnavailable})
+
+	if _, _, err := h.GetLevelActors(ctx, nil, editor.GetLevelActorsInput{}); err == nil || !strings.Contains(err.Error(), "editor busy") {
+		t.Errorf("first call: expected payload error, got %v", err)
+	}
+	// A 503 on a read-only call is retried by the client.
+	if _, _, err := h.GetLevelActors(ctx, nil, editor.GetLevelActorsInput{TagFilter: "Spawn"}); err != nil {
+		t.Errorf("second call should succeed after a retry: %v", err)
+	}
+	// A 503 on a mutation is not.
+	if _, _, err := h.SpawnActor(ctx, nil, editor.SpawnActorInput{ClassName: "PointLight"}); err == nil || !strings.Contains(err.Error(), "HTTP 503") {
+		t.Errorf("spawn: expected HTTP 503, got %v", err)
+	}
+
+	// The first plugin call is preceded by the handshake.
+	calls := fake.Calls()
+	if len(calls) != 5 || calls[0].Path != "/api/status" {
+		t.Fatalf("recorded %d calls, want the handshake and 4 more: %+v", len(calls), calls)
+	}
+	calls = calls[1:]
+	if retried := calls[2]; retried.Method != http.MethodPost || retried.Path != "/api/actors/list" || retried.Body["tag_filter"] != "Spawn" {
+		t.Errorf("retried call = %+v", retried)
+	}
+	if last := calls[3]; last.Path != "/api/actors/spawn" {
+		t.Errorf("last call = %+v", last)
+	}
+}
+
+func TestOlderPluginFailsFast(t *testing.T) {
+	fake, h := startFake(t, nil)
+	fake.RemoveRoutes("/api/pcg/ops")
+	ctx := context.Background()
+
+	_, _, err := h.PCGOps(ctx, nil, editor.PCGOpsInput{Operation: "get_graph_info"})
+	if err == nil || !strings.Contains(err.Error(), "too old") || !strings.Contains(err.Error(), "needs route /api/pcg/ops") {
+		t.Fatalf("expected plugin too old error, got %v", err)
+	}
+	for _, c := range fake.Calls() {
+		if c.Path == "/api/pcg/ops" {
+			t.Error("request sent to a route the plugin does not list")
+		}
+	}
+	if info := h.Client.NegotiatedPlugin(); info == nil || info.Version != editortest.PluginVersion || info.ProtocolVersion != editortest.ProtocolVersion {
+		t.Errorf("negotiated plugin = %+v", info)
+	}
+	if _, _, err := h.GetLevelActors(ctx, nil, editor.GetLevelActorsInput{}); err != nil {
+		t.Errorf("listed route: %v", err)
+	}
+}
+
+func TestUnknownRoute(t *testing.T) {
+	fake, _ := startFake(t, nil)
+	resp, err := http.Post(fake.PluginURL()+"/api/nope", "application/json", strings.NewReader("{}"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("status = %d, want 404", resp.StatusCode)
+	}
+}
+
+func TestPingAndStatus(t *testing.T) {
+	fake, h := startFake(t, nil)
+	ctx := context.Background()
+	if !h.Client.PingPlugin(ctx) || !h.Client.PingRCAPI(ctx) {
+		t.Fatal("fake should answer both pings")
+	}
+
+	resp, err := http.Get(fake.PluginURL() + "/api/status")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	var status struct {
+		Version   string `json:"version"`
+		PIEActive bool   `json:"pie_active"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&status); err != nil {
+		t.Fatal(err)
+	}
+	if status.Version != editortest.PluginVersion || status.PIEActive {
+		t.Errorf("status = %+v", status)
+	}
+}
+
+func TestEventsPoll(t *testing.T) {
+	fake, h := startFake(t, nil)
+	ctx := context.Background()
+
+	type batch struct {
+		StreamID string `json:"stream_id"`
+		Events   []struct {
+			Seq  int64          `json:"seq"`
+			Type string         `json:"type"`
+			Data map[string]any `json:"data"`
+		} `json:"events"`
+		LastSeq int64 `json:"last_seq"`
+	}
+	poll := func(since, timeoutMS int) batch {
+		t.Helper()
+		raw, err := h.Client.PluginCall(ctx, "/api/events/poll", map[string]any{"since": since, "timeout_ms": timeoutMS})
+		if err != nil {
+			t.Fatalf("poll: %v", err)
+		}
+		var b batch
+		if err := json.Unmarshal(raw, &b); err != nil {
+			t.Fatal(err)
+		}
+		return b
+	}
+
+	if b := poll(0, 0); len(b.Events) != 0 || b.LastSeq != 0 || b.StreamID == "" {
+		t.Fatalf("initial poll = %+v", b)
+	}
+
+	// A held poll returns as soon as an event arrives.
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		fake.Emit("asset_saved", map[string]any{"package": "/Game/Maps/Main"})
+	}()
+	start := time.Now()
+	b := poll(0, 5000)
+	if len(b.Events) != 1 || b.Events[0].Type != "asset_saved" || time.Since(start) > 4*time.Second {
+		t.Fatalf("long poll = %+v after %s", b, time.Since(start))
+	}
+
+	// Editor operations raise their own events.
+	if _, _, err := h.LevelOps(ctx, nil, editor.LevelOpsInput{Operation: "load_level", LevelPath: "/Game/Maps/Main"}); err != nil {
+		t.Fatalf("load_level: %v", err)
+	}
+	if _, _, err := h.PIEControl(ctx, nil, editor.PIEControlInput{Operation: "start"}); err != nil {
+		t.Fatalf("pie start: %v", err)
+	}
+	b = poll(int(b.LastSeq), 0)
+	var types []string
+	for _, e := range b.Events {
+		types = append(types, e.Type)
+	}
+	if strings.Join(types, ",") != "level_loaded,pie_started" {
+		t.Errorf("events = %v, want level_loaded,pie_started", types)
+	}
+}
+
+func TestTransactions(t *testing.T) {
+	fake, h := startFake(t, nil)
+	ctx := context.Background()
+
+	if _, _, err := h.TransactionBegin(ctx, nil, editor.TransactionBeginInput{Label: "Add lamps"}); err != nil {
+		t.Fatalf("TransactionBegin: %v", err)
+	}
+	for _, name := range []string{"Lamp1", "Lamp2"} {
+		if _, _, err := h.SpawnActor(ctx, nil, editor.SpawnActorInput{ClassName: "PointLight", Name: name}); err != nil {
+			t.Fatalf("SpawnActor %s: %v", name, err)
+		}
+	}
+	if _, _, err := h.Undo(ctx, nil, editor.UndoInput{}); err == nil || !strings.Contains(err.Error(), "still open") {
+		t.Errorf("Undo with an open transaction: err = %v", err)
+	}
+	_, ended, err := h.TransactionEnd(ctx, nil, editor.TransactionEndInput{})
+	if err != nil || !ended.Recorded || ended.Depth != 0 {
+		t.Fatalf("TransactionEnd = (%+v, %v)", ended, err)
+	}
+
+	// A transaction without changes is dropped, as the editor does.
+	if _, _, err := h.TransactionBegin(ctx, nil, editor.TransactionBeginInput{Label: "Nothing"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, ended, err := h.TransactionEnd(ctx, nil, editor.TransactionEndInput{}); err != nil || ended.Recorded {
+		t.Errorf("empty TransactionEnd = (%+v, %v), want not recorded", ended, err)
+	}
+
+	_, undone, err := h.Undo(ctx, nil, editor.UndoInput{})
+	if err != nil || len(undone.Transactions) != 1 || undone.Transactions[0] != "Add lamps" || !undone.CanRedo {
+		t.Fatalf("Undo = (%+v, %v)", undone, err)
+	}
+	for _, name := range []string{"Lamp1", "Lamp2"} {
+		if _, ok := fake.Actor(name); ok {
+			t.Errorf("%s still present after undo", name)
+		}
+	}
+
+	_, hist, err := h.UndoHistory(ctx, nil, editor.UndoHistoryInput{})
+	if err != nil || hist.Total != 1 || !hist.Entries[0].Undone || hist.Open != nil {
+		t.Errorf("UndoHistory = (%+v, %v)", hist, err)
+	}
+
+	if _, _, err := h.Redo(ctx, nil, editor.RedoInput{}); err != nil {
+		t.Fatalf("Redo: %v", err)
+	}
+	if _, ok := fake.Actor("Lamp2"); !ok {
+		t.Error("Lamp2 missing after redo")
+	}
+	if _, _, err := h.Redo(ctx, nil, editor.RedoInput{}); err == nil {
+		t.Error("second Redo succeeded with nothing to redo")
+	}
+
+	// revert abandons the edit.
+	if _, _, err := h.TransactionBegin(ctx, nil, editor.TransactionBeginInput{Label: "Oops"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := h.DeleteActors(ctx, nil, editor.DeleteActorsInput{ActorNames: []string{"Floor"}}); err != nil {
+		t.Fatal(err)
+	}
+	if _, ended, err := h.TransactionEnd(ctx, nil, editor.TransactionEndInput{Revert: true}); err != nil || !ended.Reverted {
+		t.Fatalf("TransactionEnd(revert) = (%+v, %v)", ended, err)
+	}
+	if _, ok := fake.Actor("Floor"); !ok {
+		t.Error("Floor missing after a reverted transaction")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	fake, h := startFake(t, nil)
+	ctx := context.Background()
+
+	validate := func(route string, body map[string]any) map[string]any {
+		t.Helper()
+		raw, err := h.Client.PluginCall(ctx, "/api/validate", map[string]any{"route": route, "body": body})
+		if err != nil {
+			t.Fatalf("validate %s: %v", route, err)
+		}
+		var out map[string]any
+		if err := json.Unmarshal(raw, &out); err != nil {
+			t.Fatal(err)
+		}
+		return out
+	}
+
+	out := validate("/api/actors/spawn", map[string]any{"class_name": "PointLight", "name": "Lamp"})
+	affected, _ := out["affected"].([]any)
+	if out["valid"] != true || len(affected) != 1 {
+		t.Fatalf("spawn = %v", out)
+	}
+	if a := affected[0].(map[string]any); a["change"] != "created" || a["class"] != "PointLight" {
+		t.Errorf("affected = %v", a)
+	}
+	if _, ok := fake.Actor("Lamp"); ok {
+		t.Error("validation spawned the actor")
+	}
+
+	// A request that would fail is invalid, with the plugin's reason, and
+	// validation raises no events.
+	out = validate("/api/levels/ops", map[string]any{"operation": "load_level", "level_path": "/Game/Maps/Main"})
+	if out["valid"] != true {
+		t.Errorf("load_level = %v", out)
+	}
+	out = validate("/api/actors/delete", map[string]any{})
+	if out["valid"] != false || out["reason"] == "" {
+		t.Errorf("delete without actors = %v", out)
+	}
+	raw, err := h.Client.PluginCall(ctx, "/api/events/poll", map[string]any{"since": 0, "timeout_ms": 0})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(raw), "level_loaded") {
+		t.Errorf("validation raised events: %s", raw)
+	}
+
+	if _, err := h.Client.PluginCall(ctx, "/api/validate", map[string]any{"route": "/api/nope"}); err == nil {
+		t.Error("validating an unknown route succeeded")
+	}
+}
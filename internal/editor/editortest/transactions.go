@@ -0,0 +1,208 @@
+// Copyright (c) mcp-unreal project contributors. Apache-2.0 license.
+
+package editortest
+
+import (
+	"fmt"
+	"maps"
+	"reflect"
+)
+
+// maxUndoSteps caps undo and redo counts, as the plugin does.
+const maxUndoSteps = 100
+
+// transaction is one step of the fake's undo history: the editor state
+// before and after it.
+type transaction struct {
+	label         string
+	before, after *snapshot
+}
+
+// openTransaction is the transaction opened through
+// /api/transactions/begin and not yet ended.
+type openTransaction struct {
+	label  string
+	depth  int
+	before *snapshot
+}
+
+// snapshot is the undoable part of the world: the editor level and the
+// project's assets and Blueprints. PIE state and the log are not undone.
+type snapshot struct {
+	Map        string
+	Actors     []*Actor
+	Assets     []*Asset
+	Blueprints []*Blueprint
+}
+
+func (w *World) snapshot() *snapshot {
+	s := &snapshot{Map: w.Map}
+	for _, a := range w.Actors {
+		s.Actors = append(s.Actors, a.clone())
+	}
+	for _, a := range w.Assets {
+		c := *a
+		c.Dependencies = append([]string(nil), a.Dependencies...)
+		c.Referencers = append([]string(nil), a.Referencers...)
+		c.Tags = maps.Clone(a.Tags)
+		s.Assets = append(s.Assets, &c)
+	}
+	for _, bp := range w.Blueprints {
+		c := *bp
+		c.Variables = append([]Variable(nil), bp.Variables...)
+		c.Functions = append([]string(nil), bp.Functions...)
+		c.Graphs = make(map[string][]Node, len(bp.Graphs))
+		for name, nodes := range bp.Graphs {
+			c.Graphs[name] = append([]Node(nil), nodes...)
+		}
+		s.Blueprints = append(s.Blueprints, &c)
+	}
+	return s
+}
+
+// restore replaces the world's undoable state with a copy of s.
+func (w *World) restore(s *snapshot) {
+	c := (&World{Map: s.Map, Actors: s.Actors, Assets: s.Assets, Blueprints: s.Blueprints}).snapshot()
+	w.Map, w.Actors, w.Assets, w.Blueprints = c.Map, c.Actors, c.Assets, c.Blueprints
+}
+
+// newestUndoable is the index in s.undo of the step undo would revert,
+// or -1.
+func (s *Server) newestUndoable() int {
+	return len(s.undo) - s.undone - 1
+}
+
+func (s *Server) openTransactionState() map[string]any {
+	if s.open == nil {
+		return map[string]any{"depth": 0}
+	}
+	return map[string]any{"label": s.open.label, "depth": s.open.depth}
+}
+
+func (s *Server) transactionBegin(body map[string]any) (any, error) {
+	label := str(body, "label")
+	if label == "" {
+		return nil, fmt.Errorf("label is required")
+	}
+	if s.open == nil {
+		s.open = &openTransaction{label: label, before: s.world.snapshot()}
+	}
+	s.open.depth++
+	return s.openTransactionState(), nil
+}
+
+// transactionEnd closes one level of the open transaction. Closing the
+// outermost records it, unless nothing changed, which the editor drops.
+func (s *Server) transactionEnd(body map[string]any) (any, error) {
+	if s.open == nil {
+		return nil, fmt.Errorf("No transaction is open")
+	}
+	s.open.depth--
+	out := s.openTransactionState()
+	if s.open.depth > 0 {
+		return out, nil
+	}
+
+	t := transaction{label: s.open.label, before: s.open.before, after: s.world.snapshot()}
+	s.open = nil
+	out = s.openTransactionState()
+	recorded := !reflect.DeepEqual(t.before, t.after)
+	out["recorded"] = recorded
+	out["reverted"] = false
+	if !recorded {
+		return out, nil
+	}
+	// A new step discards the steps that were undone.
+	s.undo = append(s.undo[:len(s.undo)-s.undone], t)
+	s.undone = 0
+	if b, _ := body["revert"].(bool); b {
+		s.world.restore(t.before)
+		s.undone = 1
+		out["reverted"] = true
+	}
+	return out, nil
+}
+
+func (s *Server) transactionUndo(body map[string]any) (any, error) {
+	if s.open != nil {
+		return nil, fmt.Errorf("Transaction '%s' is still open — end it before undoing", s.open.label)
+	}
+	label := str(body, "label")
+	count := min(max(int(num(body, "count")), 1), maxUndoSteps)
+	if label != "" {
+		newest := ""
+		if i := s.newestUndoable(); i >= 0 {
+			newest = s.undo[i].label
+		}
+		if newest != label {
+			return nil, fmt.Errorf("The newest transaction is '%s', not '%s'", newest, label)
+		}
+		count = maxUndoSteps
+	}
+
+	undone := []string{}
+	for ; count > 0 && s.newestUndoable() >= 0; count-- {
+		t := s.undo[s.newestUndoable()]
+		if label != "" && t.label != label {
+			break
+		}
+		s.world.restore(t.before)
+		s.undone++
+		undone = append(undone, t.label)
+	}
+	if len(undone) == 0 {
+		return nil, fmt.Errorf("Nothing to undo")
+	}
+	return s.undoResult(undone), nil
+}
+
+func (s *Server) transactionRedo(body map[string]any) (any, error) {
+	if s.open != nil {
+		return nil, fmt.Errorf("Transaction '%s' is still open — end it before redoing", s.open.label)
+	}
+	count := min(max(int(num(body, "count")), 1), maxUndoSteps)
+	redone := []string{}
+	for ; count > 0 && s.undone > 0; count-- {
+		t := s.undo[len(s.undo)-s.undone]
+		s.world.restore(t.after)
+		s.undone--
+		redone = append(redone, t.label)
+	}
+	if len(redone) == 0 {
+		return nil, fmt.Errorf("Nothing to redo")
+	}
+	return s.undoResult(redone), nil
+}
+
+func (s *Server) undoResult(labels []string) map[string]any {
+	return map[string]any{
+		"transactions": labels,
+		"can_undo":     s.newestUndoable() >= 0,
+		"can_redo":     s.undone > 0,
+	}
+}
+
+func (s *Server) transactionHistory(body map[string]any) (any, error) {
+	limit := int(num(body, "limit"))
+	if limit <= 0 {
+		limit = 20
+	}
+	entries := []map[string]any{}
+	for i := len(s.undo) - 1; i >= 0 && len(entries) < limit; i-- {
+		entries = append(entries, map[string]any{
+			"index":  i,
+			"label":  s.undo[i].label,
+			"undone": i > s.newestUndoable(),
+		})
+	}
+	out := map[string]any{
+		"entries":  entries,
+		"total":    len(s.undo),
+		"can_undo": s.newestUndoable() >= 0,
+		"can_redo": s.undone > 0,
+	}
+	if s.open != nil {
+		out["open"] = s.openTransactionState()
+	}
+	return out, nil
+}
@@ -0,0 +1,113 @@
+// Copyright (c) mcp-unreal project contributors. Apache-2.0 license.
+
+package editortest
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// validate answers /api/validate the way the plugin does: it checks the
+// wrapped request for another route and reports what it would change.
+// The fake runs the request and rolls the world back, so any route it
+// simulates is validated exactly; acknowledged routes affect nothing.
+func (s *Server) validate(body map[string]any) (any, error) {
+	route := str(body, "route")
+	if route == "" {
+		return nil, fmt.Errorf("Missing 'route' field")
+	}
+	target, ok := pluginRoutes[route]
+	if !ok {
+		target, ok = rcRoutes[route]
+	}
+	if !ok || s.removed[route] || route == "/api/validate" {
+		return nil, fmt.Errorf("Cannot validate '%s': no such route", route)
+	}
+	req, _ := body["body"].(map[string]any)
+
+	before := s.world.snapshot()
+	pie := cloneActors(s.world.PIEActors)
+	logLen, nodeID := len(s.world.Log), s.nodeID
+	s.validating = true
+	_, err := target(s, req)
+	s.validating = false
+	after := s.world.snapshot()
+	pieAfter := s.world.PIEActors
+
+	s.world.restore(before)
+	s.world.PIEActors = pie
+	s.world.Log = s.world.Log[:logLen]
+	s.nodeID = nodeID
+
+	if err != nil {
+		return map[string]any{"valid": false, "reason": err.Error(), "affected": []any{}}, nil
+	}
+	affected := []map[string]string{}
+	affected = s.diffActors(affected, before.Actors, after.Actors, false)
+	if pie != nil {
+		affected = s.diffActors(affected, pie, pieAfter, true)
+	}
+	for _, c := range diffByPath(before.Assets, after.Assets, func(a *Asset) string { return a.Path }) {
+		affected = append(affected, map[string]string{"path": c.key, "class": c.obj.Class, "change": c.change})
+	}
+	for _, c := range diffByPath(before.Blueprints, after.Blueprints, func(bp *Blueprint) string { return bp.Path }) {
+		affected = append(affected, map[string]string{"path": c.key, "class": "Blueprint", "change": c.change})
+	}
+	return map[string]any{"valid": true, "affected": affected}, nil
+}
+
+func (s *Server) diffActors(out []map[string]string, before, after []*Actor, pie bool) []map[string]string {
+	for _, c := range diffByPath(before, after, func(a *Actor) string { return a.Name }) {
+		out = append(out, map[string]string{
+			"path": s.world.actorPath(c.obj, pie), "class": c.obj.Class, "change": c.change,
+		})
+	}
+	return out
+}
+
+// objectChange is an object created, modified, or deleted between two
+// states of the world.
+type objectChange[T any] struct {
+	key    string
+	obj    T
+	change string
+}
+
+// diffByPath compares two states of a list of objects keyed by key,
+// reporting changes in the order the objects appear.
+func diffByPath[T any](before, after []T, key func(T) string) []objectChange[T] {
+	old := make(map[string]T, len(before))
+	for _, o := range before {
+		old[key(o)] = o
+	}
+	var out []objectChange[T]
+	seen := make(map[string]bool, len(after))
+	for _, o := range after {
+		k := key(o)
+		seen[k] = true
+		prev, ok := old[k]
+		switch {
+		case !ok:
+			out = append(out, objectChange[T]{k, o, "created"})
+		case !reflect.DeepEqual(prev, o):
+			out = append(out, objectChange[T]{k, o, "modified"})
+		}
+	}
+	for _, o := range before {
+		if k := key(o); !seen[k] {
+			out = append(out, objectChange[T]{k, o, "deleted"})
+		}
+	}
+	return out
+}
+
+func cloneActors(actors []*Actor) []*Actor {
+	if actors == nil {
+		return nil
+	}
+	out := make([]*Actor, 0, len(actors))
+	for _, a := range actors {
+		out = append(out, a.clone())
+	}
+	return out
+}
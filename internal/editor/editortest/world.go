@@ -0,0 +1,246 @@
+// Copyright (c) mcp-unreal project contributors. Apache-2.0 license.
+
+package editortest
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Actor is an actor in the fake editor world.
+type Actor struct {
+	Name       string
+	Class      string
+	Location   [3]float64
+	Rotation   [3]float64
+	Scale      [3]float64
+	Tags       []string
+	Components []Component
+	// Properties holds values written through the Remote Control API,
+	// keyed by property name.
+	Properties map[string]any
+}
+
+// Component is a component attached to an Actor. Scene components form
+// the reported hierarchy; others are listed as non-scene components.
+type Component struct {
+	Name    string
+	Class   string
+	Scene   bool
+	Visible bool
+	Mesh    string
+}
+
+// Asset is an entry in the fake Asset Registry.
+type Asset struct {
+	Name         string
+	Path         string
+	Class        string
+	Dependencies []string
+	Referencers  []string
+	Tags         map[string]string
+}
+
+// Package returns the asset's package name: its path without the
+// ".ObjectName" suffix.
+func (a Asset) Package() string {
+	if i := strings.LastIndex(a.Path, "."); i > strings.LastIndex(a.Path, "/") {
+		return a.Path[:i]
+	}
+	return a.Path
+}
+
+// Variable is a Blueprint member variable.
+type Variable struct {
+	Name string
+	Type string
+}
+
+// Node is a node in a Blueprint graph.
+type Node struct {
+	ID    string
+	Class string
+	Title string
+}
+
+// Blueprint is a Blueprint asset with its variables, functions, and
+// graphs. Graphs maps a graph name to its nodes; every Blueprint has an
+// EventGraph.
+type Blueprint struct {
+	Name        string
+	Path        string
+	ParentClass string
+	Variables   []Variable
+	Functions   []string
+	Graphs      map[string][]Node
+	Compiled    bool
+}
+
+// LogEntry is one line of the fake Output Log.
+type LogEntry struct {
+	Category  string
+	Verbosity string
+	Message   string
+}
+
+// World is the in-memory editor state the fake serves: the editor level,
+// the PIE copy of it while a play session runs, and the project's assets
+// and Blueprints.
+type World struct {
+	// Map is the package path of the open level, e.g. /Game/Maps/Main.
+	Map        string
+	Actors     []*Actor
+	Assets     []*Asset
+	Blueprints []*Blueprint
+	Log        []LogEntry
+
+	// PIEActors is the play-in-editor world, copied from Actors when PIE
+	// starts and discarded when it stops. Nil when PIE is not running.
+	PIEActors []*Actor
+	Simulate  bool
+
+	// CameraLocation and CameraRotation are the editor viewport camera.
+	CameraLocation [3]float64
+	CameraRotation [3]float64
+}
+
+// SampleWorld returns a small level resembling a new third-person
+// project: a floor, a light, a player start, and a player Blueprint.
+func SampleWorld() *World {
+	return &World{
+		Map:            "/Game/Maps/Main",
+		CameraLocation: [3]float64{-500, 0, 300},
+		CameraRotation: [3]float64{-20, 0, 0},
+		Actors: []*Actor{
+			{
+				Name: "Floor", Class: "StaticMeshActor", Scale: [3]float64{10, 10, 1},
+				Components: []Component{
+					{Name: "StaticMeshComponent0", Class: "StaticMeshComponent", Scene: true, Visible: true, Mesh: "/Engine/BasicShapes/Plane.Plane"},
+				},
+			},
+			{
+				Name: "DirectionalLight", Class: "DirectionalLight", Location: [3]float64{0, 0, 500},
+				Rotation: [3]float64{-45, 0, 0}, Scale: [3]float64{1, 1, 1},
+				Components: []Component{
+					{Name: "LightComponent0", Class: "DirectionalLightComponent", Scene: true, Visible: true},
+				},
+			},
+			{
+				Name: "PlayerStart", Class: "PlayerStart", Location: [3]float64{0, 0, 100}, Scale: [3]float64{1, 1, 1},
+				Tags: []string{"Spawn"},
+				Components: []Component{
+					{Name: "CollisionCapsule", Class: "CapsuleComponent", Scene: true},
+				},
+			},
+		},
+		Assets: []*Asset{
+			{Name: "Main", Path: "/Game/Maps/Main.Main", Class: "World"},
+			{Name: "BP_Player", Path: "/Game/Blueprints/BP_Player.BP_Player", Class: "Blueprint",
+				Dependencies: []string{"/Game/Materials/M_Base"}, Referencers: []string{"/Game/Maps/Main"}},
+			{Name: "M_Base", Path: "/Game/Materials/M_Base.M_Base", Class: "Material",
+				Referencers: []string{"/Game/Blueprints/BP_Player"}},
+			{Name: "SM_Cube", Path: "/Game/Meshes/SM_Cube.SM_Cube", Class: "StaticMesh",
+				Tags: map[string]string{"Triangles": "12"}},
+		},
+		Blueprints: []*Blueprint{
+			{
+				Name: "BP_Player", Path: "/Game/Blueprints/BP_Player.BP_Player", ParentClass: "Character",
+				Variables: []Variable{{Name: "Health", Type: "float"}},
+				Functions: []string{"TakeHit"},
+				Graphs: map[string][]Node{
+					"EventGraph": {{ID: "node-1", Class: "K2Node_Event", Title: "Event BeginPlay"}},
+					"TakeHit":    {{ID: "node-2", Class: "K2Node_FunctionEntry", Title: "Take Hit"}},
+				},
+				Compiled: true,
+			},
+		},
+	}
+}
+
+// clone returns a deep copy of the actor.
+func (a *Actor) clone() *Actor {
+	c := *a
+	c.Tags = append([]string(nil), a.Tags...)
+	c.Components = append([]Component(nil), a.Components...)
+	if a.Properties != nil {
+		c.Properties = make(map[string]any, len(a.Properties))
+		for k, v := range a.Properties {
+			c.Properties[k] = v
+		}
+	}
+	return &c
+}
+
+// mapName returns the short name of the open level ("Main").
+func (w *World) mapName() string {
+	return w.Map[strings.LastIndex(w.Map, "/")+1:]
+}
+
+// pieMapName returns the PIE world's map name, which the editor prefixes
+// with UEDPIE_<instance>_.
+func (w *World) pieMapName() string {
+	return "UEDPIE_0_" + w.mapName()
+}
+
+// actorPath returns the object path of an actor in the editor or PIE world.
+func (w *World) actorPath(a *Actor, pie bool) string {
+	level := w.Map + "." + w.mapName()
+	if pie {
+		dir := w.Map[:strings.LastIndex(w.Map, "/")+1]
+		level = dir + w.pieMapName() + "." + w.pieMapName()
+	}
+	return level + ":PersistentLevel." + a.Name
+}
+
+// findActor looks an actor up by object path or name in both worlds,
+// reporting which world it was found in.
+func (w *World) findActor(ref string) (a *Actor, pie bool) {
+	for _, list := range []struct {
+		actors []*Actor
+		pie    bool
+	}{{w.PIEActors, true}, {w.Actors, false}} {
+		for _, a := range list.actors {
+			if ref == a.Name || ref == w.actorPath(a, list.pie) {
+				return a, list.pie
+			}
+		}
+	}
+	return nil, false
+}
+
+// findBlueprint looks a Blueprint up by object or package path.
+func (w *World) findBlueprint(path string) *Blueprint {
+	for _, bp := range w.Blueprints {
+		if path == bp.Path || path == (Asset{Path: bp.Path}).Package() {
+			return bp
+		}
+	}
+	return nil
+}
+
+// findAsset looks an asset up by object or package path.
+func (w *World) findAsset(path string) *Asset {
+	for _, a := range w.Assets {
+		if path == a.Path || path == a.Package() {
+			return a
+		}
+	}
+	return nil
+}
+
+// uniqueName returns name if no actor uses it, otherwise name_N with the
+// lowest free N, the way the editor labels duplicates.
+func uniqueName(actors []*Actor, name string) string {
+	taken := make(map[string]bool, len(actors))
+	for _, a := range actors {
+		taken[a.Name] = true
+	}
+	if !taken[name] {
+		return name
+	}
+	for i := 1; ; i++ {
+		if n := name + "_" + strconv.Itoa(i); !taken[n] {
+			return n
+		}
+	}
+}
@@ -0,0 +1,72 @@
+// Copyright (c) mcp-unreal project contributors. Apache-2.0 license.
+
+package editor
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/remiphilippe/mcp-unreal/internal/toolerr"
+)
+
+// unreachableCode classifies a request that never got an answer: a
+// timeout, or an editor that is not running.
+func unreachableCode(err error) toolerr.Code {
+	var netErr net.Error
+	if errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &netErr) && netErr.Timeout()) {
+		return toolerr.Timeout
+	}
+	return toolerr.EditorOffline
+}
+
+// statusCode classifies a non-2xx answer from service by its status,
+// then by its body's error message. The plugin reports its own failures with HTTP 200
+// and an error payload, so a plugin 404 comes from its router: the route
+// does not exist.
+func statusCode(service string, status int, body string) toolerr.Code {
+	switch status {
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return toolerr.EditorAuth
+	case http.StatusRequestTimeout, http.StatusGatewayTimeout:
+		return toolerr.Timeout
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable:
+		return toolerr.EditorOffline
+	case http.StatusNotFound:
+		if service == pluginService {
+			return toolerr.PluginRouteMissing
+		}
+	}
+	var payload struct {
+		Error string `json:"error"`
+	}
+	if json.Unmarshal([]byte(body), &payload) == nil && payload.Error != "" {
+		body = payload.Error
+	}
+	return messageCode(body)
+}
+
+// messageCode classifies an error message reported by the editor. The
+// plugin's messages follow a few fixed shapes ("X not found", "x is
+// required", "Unknown ... operation", "PIE is not running"), which are
+// matched here; anything else is an editor-side failure.
+func messageCode(msg string) toolerr.Code {
+	m := strings.ToLower(msg)
+	switch {
+	case strings.Contains(m, "pie is not running"), strings.Contains(m, "start pie"),
+		strings.Contains(m, "requires pie"), strings.Contains(m, "no player controller"),
+		strings.Contains(m, "no possessed pawn"):
+		return toolerr.PIERequired
+	case strings.Contains(m, "not found"), strings.Contains(m, "does not exist"), strings.Contains(m, "no such"):
+		return toolerr.NotFound
+	case strings.Contains(m, " is required"), strings.Contains(m, " are required"),
+		strings.Contains(m, "are all required"), strings.HasPrefix(m, "unknown "),
+		strings.HasPrefix(m, "invalid "), strings.HasPrefix(m, "missing "),
+		strings.Contains(m, "out of range"):
+		return toolerr.Validation
+	}
+	return toolerr.EditorError
+}
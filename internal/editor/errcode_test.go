@@ -0,0 +1,75 @@
+// Copyright (c) mcp-unreal project contributors. Apache-2.0 license.
+
+package editor
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/remiphilippe/mcp-unreal/internal/toolerr"
+)
+
+func TestMessageCode(t *testing.T) {
+	tests := []struct {
+		msg  string
+		want toolerr.Code
+	}{
+		{"Actor not found: 'Cube'", toolerr.NotFound},
+		{"Object /Game/Missing does not exist", toolerr.NotFound},
+		{"asset_path is required", toolerr.Validation},
+		{"asset and row_name are required for add_row", toolerr.Validation},
+		{"Unknown GAS operation: 'fly'", toolerr.Validation},
+		{"Invalid JSON in request body", toolerr.Validation},
+		{"Instance index 4 out of range [0, 2)", toolerr.Validation},
+		{"PIE is not running", toolerr.PIERequired},
+		{"include_ui requires a game viewport — start PIE first", toolerr.PIERequired},
+		{"No player controller found in the current world", toolerr.PIERequired},
+		{"Failed to spawn actor", toolerr.EditorError},
+	}
+	for _, tt := range tests {
+		if got := messageCode(tt.msg); got != tt.want {
+			t.Errorf("messageCode(%q) = %s, want %s", tt.msg, got, tt.want)
+		}
+	}
+}
+
+func TestStatusCode(t *testing.T) {
+	tests := []struct {
+		service string
+		status  int
+		body    string
+		want    toolerr.Code
+	}{
+		{pluginService, http.StatusNotFound, "", toolerr.PluginRouteMissing},
+		{rcAPIService, http.StatusNotFound, `{"errorMessage":"..."}`, toolerr.EditorError},
+		{rcAPIService, http.StatusBadRequest, `{"error":"Object not found"}`, toolerr.NotFound},
+		{pluginService, http.StatusUnauthorized, "", toolerr.EditorAuth},
+		{pluginService, http.StatusServiceUnavailable, "", toolerr.EditorOffline},
+		{pluginService, http.StatusGatewayTimeout, "", toolerr.Timeout},
+		{pluginService, http.StatusInternalServerError, "crash", toolerr.EditorError},
+	}
+	for _, tt := range tests {
+		if got := statusCode(tt.service, tt.status, tt.body); got != tt.want {
+			t.Errorf("statusCode(%s, %d, %q) = %s, want %s", tt.service, tt.status, tt.body, got, tt.want)
+		}
+	}
+}
+
+func TestClient_ErrorCodes(t *testing.T) {
+	offline := newTestClient("http://127.0.0.1:1", "http://127.0.0.1:1")
+	_, err := offline.PluginCall(context.Background(), "/api/status", nil)
+	if code := toolerr.CodeOf(err); code != toolerr.EditorOffline {
+		t.Errorf("offline plugin: code = %s (%v)", code, err)
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"error":"PIE is not running"}`))
+	}))
+	defer server.Close()
+	_, err = newTestClient("http://127.0.0.1:1", server.URL).PluginCall(context.Background(), "/api/pie/control", nil)
+	if code := toolerr.CodeOf(err); code != toolerr.PIERequired {
+		t.Errorf("PIE error payload: code = %s (%v)", code, err)
+	}
+}
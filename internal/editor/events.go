@@ -0,0 +1,414 @@
+// Copyright (c) mcp-unreal project contributors. Apache-2.0 license.
+
+package editor
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/remiphilippe/mcp-unreal/internal/toolerr"
+)
+
+// Editor event types raised by the plugin's event stream.
+const (
+	EventPIEStarted        = "pie_started"
+	EventPIEStopped        = "pie_stopped"
+	EventAssetSaved        = "asset_saved"
+	EventBlueprintCompiled = "blueprint_compiled"
+	EventLevelLoaded       = "level_loaded"
+	EventLogError          = "log_error"
+)
+
+// EventTypes lists every event type, in documentation order.
+var EventTypes = []string{
+	EventPIEStarted, EventPIEStopped, EventAssetSaved,
+	EventBlueprintCompiled, EventLevelLoaded, EventLogError,
+}
+
+const (
+	// eventsRoute is the plugin's long-poll endpoint.
+	eventsRoute = "/api/events/poll"
+
+	// eventPollWait is how long the plugin holds a poll open without
+	// events. It stays below defaultRequestTimeout.
+	eventPollWait = 20 * time.Second
+
+	// eventRetryDelay is the pause after a failed poll, e.g. while the
+	// editor is closed.
+	eventRetryDelay = 2 * time.Second
+
+	// maxBufferedEvents is how many events the stream keeps for
+	// wait_for_event and the events resources.
+	maxBufferedEvents = 500
+
+	// EventsResourceURI is the resource listing recent editor events.
+	// Each event type also has its own resource under it, e.g.
+	// unreal://editor/events/pie_started.
+	EventsResourceURI = "unreal://editor/events"
+)
+
+// Event is one editor event. Seq numbers are assigned by this server and
+// keep increasing across editor restarts.
+type Event struct {
+	Seq  int64          `json:"seq" jsonschema:"sequence number; pass as after_seq to wait for the next event"`
+	Type string         `json:"type" jsonschema:"pie_started, pie_stopped, asset_saved, blueprint_compiled, level_loaded, or log_error"`
+	Time string         `json:"time" jsonschema:"time the editor raised the event (RFC 3339)"`
+	Data map[string]any `json:"data,omitempty" jsonschema:"event details, e.g. map, package, blueprint, or log message"`
+}
+
+// pluginEventBatch is the plugin's /api/events/poll response.
+type pluginEventBatch struct {
+	StreamID string  `json:"stream_id"`
+	Events   []Event `json:"events"`
+	LastSeq  int64   `json:"last_seq"`
+}
+
+// EventStream long-polls the plugin for editor events, keeps the most
+// recent ones, and wakes waiters and listeners as they arrive. Events are
+// only seen while Run is running.
+type EventStream struct {
+	client *Client
+	logger *slog.Logger
+
+	mu        sync.Mutex
+	events    []Event
+	seq       int64
+	changed   chan struct{}
+	listeners []func(Event)
+
+	// streamID and pluginSeq track the plugin's own numbering; a new
+	// stream ID means the editor restarted and numbering starts over.
+	streamID  string
+	pluginSeq int64
+}
+
+// NewEventStream returns an event stream for the client's default editor.
+func NewEventStream(client *Client, logger *slog.Logger) *EventStream {
+	return &EventStream{client: client, logger: logger, changed: make(chan struct{})}
+}
+
+// OnEvent registers fn to be called, outside any lock, for every event.
+func (s *EventStream) OnEvent(fn func(Event)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.listeners = append(s.listeners, fn)
+}
+
+// Run polls the plugin until ctx is done. Failed polls are retried after
+// a short pause, so the stream resumes when the editor comes back.
+func (s *EventStream) Run(ctx context.Context) {
+	for ctx.Err() == nil {
+		if err := s.poll(ctx); err != nil && ctx.Err() == nil {
+			s.logger.Debug("editor event poll failed", "error", err)
+			_ = sleepCtx(ctx, eventRetryDelay)
+		}
+	}
+}
+
+// poll makes one long-poll request and publishes what it returns.
+func (s *EventStream) poll(ctx context.Context) error {
+	s.mu.Lock()
+	since, wait := s.pluginSeq, eventPollWait
+	if s.streamID == "" {
+		// First contact only learns the stream's position, so it must
+		// not wait for (and then drop) the next event.
+		wait = 0
+	}
+	s.mu.Unlock()
+
+	raw, err := s.client.pollEvents(ctx, map[string]any{
+		"since":      since,
+		"timeout_ms": wait.Milliseconds(),
+	})
+	if err != nil {
+		return err
+	}
+	var batch pluginEventBatch
+	if err := json.Unmarshal(raw, &batch); err != nil {
+		return fmt.Errorf("parsing editor events: %w", err)
+	}
+
+	s.mu.Lock()
+	if batch.StreamID != s.streamID {
+		// On first contact, start from the plugin's current position:
+		// its backlog predates this server. After an editor restart,
+		// fetch the new editor's events from the start.
+		restarted := s.streamID != ""
+		s.streamID, s.pluginSeq = batch.StreamID, batch.LastSeq
+		if restarted {
+			s.pluginSeq = 0
+		}
+		s.mu.Unlock()
+		if restarted {
+			s.logger.Info("editor event stream restarted", "stream", batch.StreamID)
+		}
+		return nil
+	}
+	if batch.LastSeq > s.pluginSeq {
+		s.pluginSeq = batch.LastSeq
+	}
+	s.mu.Unlock()
+
+	s.publish(batch.Events)
+	return nil
+}
+
+// pollEvents sends one long-poll request to the plugin. Unlike
+// PluginCall it bypasses the circuit breaker, retries, and request
+// telemetry: the stream polls continuously and retries on its own, and
+// a failed poll is not a failed editor call, so it must neither open the
+// breaker nor replace the last error that status reports.
+func (c *Client) pollEvents(ctx context.Context, body any) (json.RawMessage, error) {
+	if err := c.requireRoute(ctx, eventsRoute); err != nil {
+		return nil, err
+	}
+	var ex exchange
+	return c.send(ctx, http.MethodPost, c.pluginBaseURL+eventsRoute, body, "", &ex)
+}
+
+// publish renumbers events, buffers them, and notifies waiters and
+// listeners.
+func (s *EventStream) publish(events []Event) {
+	if len(events) == 0 {
+		return
+	}
+	s.mu.Lock()
+	for i := range events {
+		s.seq++
+		events[i].Seq = s.seq
+	}
+	s.events = append(s.events, events...)
+	if len(s.events) > maxBufferedEvents {
+		s.events = slices.Clone(s.events[len(s.events)-maxBufferedEvents:])
+	}
+	close(s.changed)
+	s.changed = make(chan struct{})
+	listeners := slices.Clone(s.listeners)
+	s.mu.Unlock()
+
+	for _, e := range events {
+		for _, fn := range listeners {
+			fn(e)
+		}
+	}
+}
+
+// LastSeq returns the sequence number of the newest event, or 0.
+func (s *EventStream) LastSeq() int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.seq
+}
+
+// Recent returns up to limit of the newest buffered events matching f,
+// oldest first.
+func (s *EventStream) Recent(f EventFilter, limit int) []Event {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var out []Event
+	for i := len(s.events) - 1; i >= 0 && len(out) < limit; i-- {
+		if f.Match(s.events[i]) {
+			out = append(out, s.events[i])
+		}
+	}
+	slices.Reverse(out)
+	return out
+}
+
+// Wait returns the first buffered or new event after afterSeq that
+// matches f, waiting for one until ctx is done. Pass LastSeq for events
+// from now on.
+func (s *EventStream) Wait(ctx context.Context, afterSeq int64, f EventFilter) (Event, error) {
+	s.mu.Lock()
+	for {
+		for _, e := range s.events {
+			if e.Seq > afterSeq && f.Match(e) {
+				s.mu.Unlock()
+				return e, nil
+			}
+		}
+		if n := len(s.events); n > 0 {
+			afterSeq = max(afterSeq, s.events[n-1].Seq)
+		}
+		changed := s.changed
+		s.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return Event{}, ctx.Err()
+		case <-changed:
+		}
+		s.mu.Lock()
+	}
+}
+
+// EventFilter selects events by type and by a substring of their data.
+type EventFilter struct {
+	Types    []string
+	Contains string
+}
+
+// Match reports whether e passes the filter.
+func (f EventFilter) Match(e Event) bool {
+	if len(f.Types) > 0 && !slices.Contains(f.Types, e.Type) {
+		return false
+	}
+	if f.Contains == "" {
+		return true
+	}
+	data, _ := json.Marshal(e.Data)
+	return strings.Contains(strings.ToLower(string(data)), strings.ToLower(f.Contains))
+}
+
+// --- MCP surface ---
+
+// maxEventWait bounds the wait_for_event timeout.
+const maxEventWait = 10 * time.Minute
+
+// WaitForEventInput defines parameters for the wait_for_event tool.
+type WaitForEventInput struct {
+	InstanceInput
+
+	Types          []string `json:"types,omitempty" jsonschema:"Event types to wait for: pie_started, pie_stopped, asset_saved, blueprint_compiled, level_loaded, log_error. Empty means any"`
+	Contains       string   `json:"contains,omitempty" jsonschema:"Only match events whose data contains this text (case-insensitive), e.g. a map or Blueprint name"`
+	TimeoutSeconds int      `json:"timeout_seconds,omitempty" jsonschema:"How long to wait (default 30, max 600)"`
+	AfterSeq       int64    `json:"after_seq,omitempty" jsonschema:"Return the first match after this seq, e.g. the seq of a previous result, to not miss events in between. 0 waits for new events only"`
+	NoWait         bool     `json:"no_wait,omitempty" jsonschema:"Return at once: a match already received after after_seq, or else timed_out=true with the current last_seq. Call this before starting an action and pass its last_seq as after_seq to catch the action's events"`
+}
+
+// WaitForEventOutput is returned by the wait_for_event tool.
+type WaitForEventOutput struct {
+	Event    *Event `json:"event,omitempty" jsonschema:"the matching event, absent on timeout"`
+	TimedOut bool   `json:"timed_out" jsonschema:"true if no matching event arrived in time"`
+	LastSeq  int64  `json:"last_seq" jsonschema:"newest event seq seen so far; pass as after_seq to continue"`
+}
+
+// RegisterEvents adds the wait_for_event tool to the MCP server.
+func (h *Handler) RegisterEvents(server *mcp.Server) {
+	mcp.AddTool(server, &mcp.Tool{
+		Name: "wait_for_event",
+		Description: "Wait for an editor event instead of polling: pie_started, pie_stopped, asset_saved, " +
+			"blueprint_compiled, level_loaded, or log_error. Filter by types and by text in the event data. " +
+			"Returns the first matching event or timed_out=true. Pass a previous result's seq as after_seq " +
+			"to catch events raised between calls; no_wait=true returns the current last_seq at once. Events are also readable as the " + EventsResourceURI +
+			" resource, which sends update notifications to subscribed clients.",
+	}, h.WaitForEvent)
+}
+
+// WaitForEvent implements the wait_for_event tool.
+func (h *Handler) WaitForEvent(ctx context.Context, req *mcp.CallToolRequest, input WaitForEventInput) (*mcp.CallToolResult, WaitForEventOutput, error) {
+	if h.Events == nil {
+		return nil, WaitForEventOutput{}, toolerr.New(toolerr.Unsupported, "editor event stream is not running (disabled while recording or replaying a cassette)")
+	}
+	if name := InstanceFrom(ctx); name != "" && name != DefaultInstance {
+		return nil, WaitForEventOutput{}, toolerr.New(toolerr.Validation, "events are only streamed from the default editor, not instance %q", name)
+	}
+	for _, t := range input.Types {
+		if !slices.Contains(EventTypes, t) {
+			return nil, WaitForEventOutput{}, toolerr.New(toolerr.Validation, "unknown event type %q — use one of %s", t, strings.Join(EventTypes, ", "))
+		}
+	}
+	timeout := time.Duration(input.TimeoutSeconds) * time.Second
+	if timeout <= 0 {
+		timeout = 30 * time.Second
+	}
+	timeout = min(timeout, maxEventWait)
+	if input.NoWait {
+		timeout = 0
+	}
+
+	waitCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	after := input.AfterSeq
+	if after <= 0 {
+		after = h.Events.LastSeq()
+	}
+	e, err := h.Events.Wait(waitCtx, after, EventFilter{Types: input.Types, Contains: input.Contains})
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, WaitForEventOutput{}, ctx.Err()
+		}
+		if errors.Is(err, context.DeadlineExceeded) {
+			return nil, WaitForEventOutput{TimedOut: true, LastSeq: h.Events.LastSeq()}, nil
+		}
+		return nil, WaitForEventOutput{}, err
+	}
+	return nil, WaitForEventOutput{Event: &e, LastSeq: e.Seq}, nil
+}
+
+// recentEventLimit is how many events the events resources return.
+const recentEventLimit = 100
+
+// RegisterResources adds the events resources and sends
+// notifications/resources/updated to subscribers as events arrive.
+func (s *EventStream) RegisterResources(server *mcp.Server) {
+	server.AddResource(&mcp.Resource{
+		URI:         EventsResourceURI,
+		Name:        "editor-events",
+		Description: "The most recent UE editor events (PIE, saves, Blueprint compiles, level loads, log errors).",
+		MIMEType:    "application/json",
+	}, s.readResource)
+	for _, t := range EventTypes {
+		server.AddResource(&mcp.Resource{
+			URI:         EventsResourceURI + "/" + t,
+			Name:        "editor-events-" + t,
+			Description: "The most recent " + t + " editor events.",
+			MIMEType:    "application/json",
+		}, s.readResource)
+	}
+	s.OnEvent(func(e Event) {
+		ctx := context.Background()
+		for _, uri := range []string{EventsResourceURI, EventsResourceURI + "/" + e.Type} {
+			_ = server.ResourceUpdated(ctx, &mcp.ResourceUpdatedNotificationParams{URI: uri})
+		}
+	})
+}
+
+func (s *EventStream) readResource(_ context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+	uri := req.Params.URI
+	var f EventFilter
+	if t, ok := strings.CutPrefix(uri, EventsResourceURI+"/"); ok {
+		if !slices.Contains(EventTypes, t) {
+			return nil, mcp.ResourceNotFoundError(uri)
+		}
+		f.Types = []string{t}
+	} else if uri != EventsResourceURI {
+		return nil, mcp.ResourceNotFoundError(uri)
+	}
+	events := s.Recent(f, recentEventLimit)
+	if events == nil {
+		events = []Event{}
+	}
+	data, err := json.Marshal(map[string]any{"events": events, "last_seq": s.LastSeq()})
+	if err != nil {
+		return nil, fmt.Errorf("encoding events: %w", err)
+	}
+	return &mcp.ReadResourceResult{Contents: []*mcp.ResourceContents{
+		{URI: uri, MIMEType: "application/json", Text: string(data)},
+	}}, nil
+}
+
+// SubscribeEvents accepts resource subscriptions to the events
+// resources. Use it as mcp.ServerOptions.SubscribeHandler.
+func SubscribeEvents(_ context.Context, req *mcp.SubscribeRequest) error {
+	uri := req.Params.URI
+	if uri == EventsResourceURI {
+		return nil
+	}
+	if t, ok := strings.CutPrefix(uri, EventsResourceURI+"/"); ok && slices.Contains(EventTypes, t) {
+		return nil
+	}
+	return mcp.ResourceNotFoundError(uri)
+}
+
+// UnsubscribeEvents is the matching mcp.ServerOptions.UnsubscribeHandler.
+func UnsubscribeEvents(context.Context, *mcp.UnsubscribeRequest) error { return nil }
@@ -0,0 +1,226 @@
+// Copyright (c) mcp-unreal project contributors. Apache-2.0 license.
+
+package editor
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/remiphilippe/mcp-unreal/internal/editor/editortest"
+)
+
+func TestEventFilter(t *testing.T) {
+	e := Event{Type: EventBlueprintCompiled, Data: map[string]any{"blueprint": "/Game/BP_Door.BP_Door"}}
+	tests := []struct {
+		name   string
+		filter EventFilter
+		want   bool
+	}{
+		{"empty", EventFilter{}, true},
+		{"type match", EventFilter{Types: []string{EventPIEStarted, EventBlueprintCompiled}}, true},
+		{"type mismatch", EventFilter{Types: []string{EventPIEStarted}}, false},
+		{"contains, any case", EventFilter{Contains: "bp_door"}, true},
+		{"contains mismatch", EventFilter{Contains: "BP_Window"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.filter.Match(e); got != tt.want {
+				t.Errorf("Match = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEventStream(t *testing.T) {
+	fake := editortest.New(nil)
+	plugin := httptest.NewServer(fake.PluginHandler())
+	defer plugin.Close()
+
+	client := newTestClient(offlineURL, plugin.URL)
+	stream := NewEventStream(client, testLogger())
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Events raised before first contact are not delivered.
+	fake.Emit(EventLogError, map[string]any{"message": "before the server started"})
+	go stream.Run(ctx)
+	waitConnected(t, stream)
+
+	wait := func(after int64, f EventFilter) (Event, error) {
+		t.Helper()
+		wctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+		defer cancel()
+		return stream.Wait(wctx, after, f)
+	}
+
+	// Raise a PIE start through the plugin.
+	before := stream.LastSeq()
+	if _, err := client.PluginCall(ctx, "/api/editor/pie_control", map[string]any{"operation": "start"}); err != nil {
+		t.Fatal(err)
+	}
+	pie, err := wait(before, EventFilter{Types: []string{EventPIEStarted}})
+	if err != nil {
+		t.Fatalf("waiting for pie_started: %v", err)
+	}
+	if pie.Data["map"] == nil {
+		t.Errorf("pie_started data = %v, want map", pie.Data)
+	}
+
+	if _, err := client.PluginCall(ctx, "/api/editor/pie_control", map[string]any{"operation": "stop"}); err != nil {
+		t.Fatal(err)
+	}
+	stopped, err := wait(pie.Seq, EventFilter{Types: []string{EventPIEStopped}})
+	if err != nil {
+		t.Fatalf("waiting for pie_stopped after seq %d: %v", pie.Seq, err)
+	}
+	if stopped.Seq <= pie.Seq {
+		t.Errorf("pie_stopped seq %d not after pie_started seq %d", stopped.Seq, pie.Seq)
+	}
+
+	if events := stream.Recent(EventFilter{Contains: "before the server started"}, 10); len(events) > 0 {
+		t.Errorf("backlog event delivered: %+v", events)
+	}
+
+	// Nothing else happens: the wait times out.
+	if _, err := wait(stream.LastSeq(), EventFilter{Types: []string{EventLevelLoaded}}); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("wait with no events: err = %v, want deadline exceeded", err)
+	}
+}
+
+// waitConnected waits for the stream's first contact with the plugin.
+func waitConnected(t *testing.T, stream *EventStream) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		stream.mu.Lock()
+		connected := stream.streamID != ""
+		stream.mu.Unlock()
+		if connected {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("event stream never reached the plugin")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// TestEventStreamFirstEvent checks that the first event after contact
+// with an idle plugin is delivered, not taken as the stream's baseline.
+func TestEventStreamFirstEvent(t *testing.T) {
+	fake := editortest.New(nil)
+	plugin := httptest.NewServer(fake.PluginHandler())
+	defer plugin.Close()
+
+	stream := NewEventStream(newTestClient(offlineURL, plugin.URL), testLogger())
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go stream.Run(ctx)
+	waitConnected(t, stream)
+
+	fake.Emit(EventLevelLoaded, map[string]any{"map": "/Game/Maps/Main"})
+	wctx, wcancel := context.WithTimeout(ctx, 2*time.Second)
+	defer wcancel()
+	if _, err := stream.Wait(wctx, 0, EventFilter{Types: []string{EventLevelLoaded}}); err != nil {
+		t.Fatalf("first event not delivered: %v", err)
+	}
+}
+
+// TestEventPollSkipsBreaker checks that failed polls of an offline editor
+// leave the plugin circuit breaker alone.
+func TestEventPollSkipsBreaker(t *testing.T) {
+	client := newTestClient(offlineURL, offlineURL)
+	stream := NewEventStream(client, testLogger())
+	for range 5 {
+		if err := stream.poll(context.Background()); err == nil {
+			t.Fatal("poll of an offline editor succeeded")
+		}
+	}
+	if st := client.BreakerStates()[0]; st.State != "closed" || st.ConsecutiveFailures != 0 || st.LastError != "" {
+		t.Errorf("plugin breaker after failed polls = %+v, want closed with no failures", st)
+	}
+}
+
+func TestWaitForEventNoWait(t *testing.T) {
+	stream := NewEventStream(newTestClient(offlineURL, offlineURL), testLogger())
+	h := &Handler{Client: stream.client, Logger: testLogger(), Events: stream}
+	ctx := context.Background()
+	stream.publish([]Event{{Type: EventLevelLoaded}})
+
+	start := time.Now()
+	_, out, err := h.WaitForEvent(ctx, nil, WaitForEventInput{Types: []string{EventPIEStarted}, NoWait: true})
+	if err != nil || !out.TimedOut || out.LastSeq != 1 {
+		t.Fatalf("no_wait without a match = %+v, %v; want timed_out with last_seq 1", out, err)
+	}
+	if time.Since(start) > time.Second {
+		t.Errorf("no_wait waited %s", time.Since(start))
+	}
+
+	stream.publish([]Event{{Type: EventPIEStarted}})
+	_, out, err = h.WaitForEvent(ctx, nil, WaitForEventInput{Types: []string{EventPIEStarted}, AfterSeq: out.LastSeq, NoWait: true})
+	if err != nil || out.Event == nil || out.Event.Seq != 2 {
+		t.Errorf("no_wait with a match = %+v, %v; want the pie_started event", out, err)
+	}
+}
+
+func TestEventResources(t *testing.T) {
+	stream := NewEventStream(newTestClient(offlineURL, offlineURL), testLogger())
+	server := mcp.NewServer(&mcp.Implementation{Name: "test"}, &mcp.ServerOptions{
+		SubscribeHandler:   SubscribeEvents,
+		UnsubscribeHandler: UnsubscribeEvents,
+	})
+	stream.RegisterResources(server)
+
+	ctx := context.Background()
+	updated := make(chan string, 10)
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	ss, err := server.Connect(ctx, serverTransport, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = ss.Close() }()
+	session, err := mcp.NewClient(&mcp.Implementation{Name: "test-client"}, &mcp.ClientOptions{
+		ResourceUpdatedHandler: func(_ context.Context, req *mcp.ResourceUpdatedNotificationRequest) {
+			updated <- req.Params.URI
+		},
+	}).Connect(ctx, clientTransport, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = session.Close() }()
+
+	uri := EventsResourceURI + "/" + EventAssetSaved
+	if err := session.Subscribe(ctx, &mcp.SubscribeParams{URI: uri}); err != nil {
+		t.Fatalf("Subscribe: %v", err)
+	}
+	if err := session.Subscribe(ctx, &mcp.SubscribeParams{URI: EventsResourceURI + "/nope"}); err == nil {
+		t.Error("subscribing to an unknown event type succeeded")
+	}
+
+	stream.publish([]Event{
+		{Type: EventPIEStarted},
+		{Type: EventAssetSaved, Data: map[string]any{"package": "/Game/Maps/Main"}},
+	})
+	select {
+	case got := <-updated:
+		if got != uri {
+			t.Errorf("updated %s, want %s", got, uri)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("no resources/updated notification")
+	}
+
+	res, err := session.ReadResource(ctx, &mcp.ReadResourceParams{URI: uri})
+	if err != nil {
+		t.Fatalf("ReadResource: %v", err)
+	}
+	text := res.Contents[0].Text
+	if !strings.Contains(text, "/Game/Maps/Main") || strings.Contains(text, EventPIEStarted) {
+		t.Errorf("asset_saved resource = %s", text)
+	}
+}
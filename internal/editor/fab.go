@@ -8,6 +8,7 @@ import (
 	"fmt"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/remiphilippe/mcp-unreal/internal/toolerr"
 )
 
 // --- fab_ops ---
@@ -20,6 +21,10 @@ type FabCachedAsset struct {
 
 // FabOpsInput defines parameters for the fab_ops tool.
 type FabOpsInput struct {
+	InstanceInput
+	TransactionInput
+	DryRunInput
+
 	Operation string `json:"operation" jsonschema:"required,Operation: list_cache, cache_info, import, clear_cache"`
 	// For import: which cached asset to import.
 	AssetID string `json:"asset_id,omitempty" jsonschema:"Fab asset ID to import (for import operation)"`
@@ -54,7 +59,7 @@ func (h *Handler) RegisterFab(server *mcp.Server) {
 // FabOps implements the fab_ops tool.
 func (h *Handler) FabOps(ctx context.Context, req *mcp.CallToolRequest, input FabOpsInput) (*mcp.CallToolResult, FabOpsOutput, error) {
 	if input.Operation == "" {
-		return nil, FabOpsOutput{}, fmt.Errorf("operation is required")
+		return nil, FabOpsOutput{}, toolerr.New(toolerr.Validation, "operation is required")
 	}
 
 	body := map[string]any{
@@ -0,0 +1,55 @@
+// Copyright (c) mcp-unreal project contributors. Apache-2.0 license.
+
+package editor
+
+// Feature is an editor capability provided by one plugin route.
+type Feature struct {
+	Name  string
+	Route string
+}
+
+// PluginFeatures lists what the MCPUnreal plugin provides, in the order
+// the status tool reports it. A feature is available when the negotiated
+// plugin serves its route. Tool groups name the feature they need, so
+// the status tool and the ToolWatcher agree on what a plugin provides.
+var PluginFeatures = []Feature{
+	{"actors", "/api/actors/list"},
+	{"components", "/api/actors/components"},
+	{"console", "/api/editor/console_command"},
+	{"blueprints", "/api/blueprints/modify"},
+	{"anim_blueprints", "/api/anim_blueprints/query"},
+	{"assets", "/api/assets/search"},
+	{"materials", "/api/materials/ops"},
+	{"characters", "/api/characters/config"},
+	{"input", "/api/input/ops"},
+	{"levels", "/api/levels/ops"},
+	{"mesh", "/api/mesh/procedural"},
+	{"output_log", "/api/editor/output_log"},
+	{"viewport_capture", "/api/editor/capture_viewport"},
+	{"script_execution", "/api/editor/execute_script"},
+	{"pie_control", "/api/editor/pie_control"},
+	{"live_compile", "/api/editor/live_compile"},
+	{"pcg", "/api/pcg/ops"},
+	{"gas", "/api/gas/ops"},
+	{"niagara", "/api/niagara/ops"},
+	{"ism", "/api/ism/ops"},
+	{"textures", "/api/textures/ops"},
+	{"data_assets", "/api/data/ops"},
+	{"fab", "/api/fab/ops"},
+	{"subsystems", "/api/subsystems/query"},
+	{"ui_query", "/api/ui/query"},
+	{"network_debug", "/api/network/debug"},
+	{"events", eventsRoute},
+	{"transactions", transactionHistoryRoute},
+}
+
+// featureRoute returns the route of the plugin feature name, or "" if
+// there is no such feature.
+func featureRoute(name string) string {
+	for _, f := range PluginFeatures {
+		if f.Name == name {
+			return f.Route
+		}
+	}
+	return ""
+}
@@ -8,12 +8,17 @@ import (
 	"fmt"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/remiphilippe/mcp-unreal/internal/toolerr"
 )
 
 // --- gas_ops ---
 
 // GASOpsInput defines parameters for the gas_ops tool.
 type GASOpsInput struct {
+	InstanceInput
+	TransactionInput
+	DryRunInput
+
 	Operation      string          `json:"operation" jsonschema:"required,One of: grant_ability, revoke_ability, list_abilities, apply_effect, get_attributes, set_attribute"`
 	ActorPath      string          `json:"actor_path,omitempty" jsonschema:"Actor with AbilitySystemComponent — required for all operations"`
 	AbilityClass   string          `json:"ability_class,omitempty" jsonschema:"Gameplay ability class path for grant_ability/revoke_ability"`
@@ -50,7 +55,7 @@ func (h *Handler) RegisterGAS(server *mcp.Server) {
 // GASOps implements the gas_ops tool.
 func (h *Handler) GASOps(ctx context.Context, req *mcp.CallToolRequest, input GASOpsInput) (*mcp.CallToolResult, GASOpsOutput, error) {
 	if input.Operation == "" {
-		return nil, GASOpsOutput{}, fmt.Errorf("operation is required (grant_ability, revoke_ability, list_abilities, apply_effect, get_attributes, set_attribute)")
+		return nil, GASOpsOutput{}, toolerr.New(toolerr.Validation, "operation is required (grant_ability, revoke_ability, list_abilities, apply_effect, get_attributes, set_attribute)")
 	}
 
 	body := map[string]any{
@@ -0,0 +1,171 @@
+// Copyright (c) mcp-unreal project contributors. Apache-2.0 license.
+
+package editor
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+
+	"github.com/remiphilippe/mcp-unreal/internal/toolerr"
+)
+
+// PluginInfo is what the MCPUnreal plugin reports about itself from
+// /api/status. Plugins before 0.3.0 do not report Routes.
+type PluginInfo struct {
+	Name            string   `json:"name,omitempty"`
+	Version         string   `json:"version,omitempty"`
+	UEVersion       string   `json:"ue_version,omitempty"`
+	ProtocolVersion int      `json:"protocol_version,omitempty"`
+	Routes          []string `json:"routes,omitempty"`
+
+	// Project names discovered editor instances. RCAPIPort is optional;
+	// when absent, discovered instances use the configured RC_API_PORT.
+	Project   string `json:"project,omitempty"`
+	RCAPIPort int    `json:"rc_api_port,omitempty"`
+}
+
+// PluginStatus is the plugin's answer to /api/status: its handshake and
+// whether Play In Editor is running.
+type PluginStatus struct {
+	PluginInfo
+	PIEActive bool   `json:"pie_active"`
+	PIEMap    string `json:"pie_map"`
+}
+
+// Supports reports whether the plugin serves route. A plugin that does
+// not list its routes is assumed to serve all of them; calls to a route
+// it lacks then fail with its 404.
+func (p *PluginInfo) Supports(route string) bool {
+	if p == nil || p.Routes == nil {
+		return true
+	}
+	return slices.Contains(p.Routes, route)
+}
+
+// HTTPError is returned when the editor answers with a non-2xx status.
+type HTTPError struct {
+	Service string
+	Status  int
+	Body    string
+}
+
+func (e *HTTPError) Error() string {
+	return fmt.Sprintf("%s returned HTTP %d: %s", e.Service, e.Status, truncate(e.Body, 500))
+}
+
+// ErrorCode implements toolerr.Coder.
+func (e *HTTPError) ErrorCode() toolerr.Code {
+	return statusCode(e.Service, e.Status, e.Body)
+}
+
+// Handshake asks the plugin for its version and routes on first contact
+// and caches the answer until the plugin becomes unreachable. The request
+// is sent once, without retries, so an offline editor costs one refused
+// connection; the caller's own request then reports the outage.
+func (c *Client) Handshake(ctx context.Context) (*PluginInfo, error) {
+	if info := c.NegotiatedPlugin(); info != nil {
+		return info, nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, defaultConnectTimeout)
+	defer cancel()
+	raw, err := c.doRequest(ctx, http.MethodPost, c.pluginBaseURL+"/api/status", nil, "")
+	if err != nil {
+		return nil, fmt.Errorf("plugin handshake: %w", err)
+	}
+	var info PluginInfo
+	if err := json.Unmarshal(raw, &info); err != nil {
+		return nil, fmt.Errorf("parsing plugin status: %w", err)
+	}
+
+	c.negotiate(&info)
+	return &info, nil
+}
+
+// ProbePlugin asks the plugin for its status once, without retries or the
+// circuit breaker. A failed probe drops the negotiated handshake. The
+// answer carries the handshake fields, so a plugin whose name, version,
+// or protocol differs from the negotiated one is renegotiated from it;
+// otherwise the negotiated handshake is kept.
+func (c *Client) ProbePlugin(ctx context.Context) (*PluginStatus, error) {
+	st, err := c.probeStatus(ctx, c.pluginBaseURL, defaultConnectTimeout)
+	if err != nil {
+		c.forgetPlugin()
+		return nil, err
+	}
+	if cur := c.NegotiatedPlugin(); cur == nil || cur.Name != st.Name ||
+		cur.Version != st.Version || cur.ProtocolVersion != st.ProtocolVersion {
+		info := st.PluginInfo
+		c.negotiate(&info)
+	}
+	return &st, nil
+}
+
+// negotiate caches info as the plugin's handshake.
+func (c *Client) negotiate(info *PluginInfo) {
+	c.mu.Lock()
+	c.plugin = info
+	c.mu.Unlock()
+	c.logger.Debug("negotiated MCPUnreal plugin", "version", info.Version,
+		"protocol", info.ProtocolVersion, "routes", len(info.Routes))
+}
+
+// NegotiatedPlugin returns the cached handshake result, or nil if the
+// client has not completed a handshake since the plugin was last reached.
+func (c *Client) NegotiatedPlugin() *PluginInfo {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.plugin
+}
+
+// forgetPlugin drops the cached handshake so the next call renegotiates,
+// e.g. after the editor restarts with a different plugin build.
+func (c *Client) forgetPlugin() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.plugin = nil
+}
+
+// requireRoute fails fast when the negotiated plugin lacks route. If the
+// handshake itself fails the call proceeds and surfaces its own error.
+func (c *Client) requireRoute(ctx context.Context, route string) error {
+	if !c.handshake {
+		return nil
+	}
+	info, err := c.Handshake(ctx)
+	if err != nil {
+		c.logger.Debug("skipping route check", "route", route, "error", err)
+		return nil
+	}
+	if !info.Supports(route) {
+		return pluginTooOld(info, route)
+	}
+	return nil
+}
+
+// routeNotFound converts the plugin's 404 for an unknown route into the
+// same error, for plugins that do not list their routes.
+func (c *Client) routeNotFound(err error, route string) error {
+	var httpErr *HTTPError
+	if !errors.As(err, &httpErr) || httpErr.Status != http.StatusNotFound {
+		return err
+	}
+	info := c.NegotiatedPlugin()
+	c.forgetPlugin()
+	return pluginTooOld(info, route)
+}
+
+func pluginTooOld(info *PluginInfo, route string) error {
+	version := "unknown version"
+	if info != nil && info.Version != "" {
+		version = "version " + info.Version
+	}
+	return toolerr.New(toolerr.PluginRouteMissing,
+		"%s too old (%s), needs route %s — update the plugin in your project's Plugins/MCPUnreal folder and restart the editor",
+		pluginService, version, route,
+	)
+}
@@ -0,0 +1,121 @@
+// Copyright (c) mcp-unreal project contributors. Apache-2.0 license.
+
+package editor
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/remiphilippe/mcp-unreal/internal/toolerr"
+)
+
+// pluginServer serves /api/status with status and answers {"ok": true}
+// on the routes in serves, 404 elsewhere.
+func pluginServer(t *testing.T, status map[string]any, serves ...string) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+	var handshakes atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/status" {
+			handshakes.Add(1)
+			_ = json.NewEncoder(w).Encode(status)
+			return
+		}
+		for _, route := range serves {
+			if r.URL.Path == route {
+				_, _ = w.Write([]byte(`{"ok": true}`))
+				return
+			}
+		}
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &handshakes
+}
+
+func TestPluginCall_HandshakeCachesRoutes(t *testing.T) {
+	srv, handshakes := pluginServer(t, map[string]any{
+		"version": "0.3.0", "protocol_version": 1, "routes": []string{"/api/status", "/api/actors/list"},
+	}, "/api/actors/list")
+	client := newTestClient("http://127.0.0.1:1", srv.URL)
+	client.handshake = true
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		if _, err := client.PluginCall(ctx, "/api/actors/list", nil); err != nil {
+			t.Fatalf("call %d: %v", i, err)
+		}
+	}
+	if handshakes.Load() != 1 {
+		t.Errorf("handshake sent %d times, want 1", handshakes.Load())
+	}
+	if info := client.NegotiatedPlugin(); info == nil || info.Version != "0.3.0" || info.ProtocolVersion != 1 {
+		t.Errorf("negotiated = %+v", info)
+	}
+
+	_, err := client.PluginCall(ctx, "/api/pcg/ops", map[string]any{"operation": "execute"})
+	if err == nil || !strings.Contains(err.Error(), "too old (version 0.3.0), needs route /api/pcg/ops") {
+		t.Errorf("expected fast failure, got %v", err)
+	}
+}
+
+func TestPluginCall_LegacyPluginNotFound(t *testing.T) {
+	// Plugins before 0.3.0 report no routes, so the call goes out and the
+	// router's 404 is reported the same way.
+	srv, _ := pluginServer(t, map[string]any{"version": "0.2.0"}, "/api/actors/list")
+	client := newTestClient("http://127.0.0.1:1", srv.URL)
+	client.handshake = true
+	ctx := context.Background()
+
+	if _, err := client.PluginCall(ctx, "/api/actors/list", nil); err != nil {
+		t.Fatalf("served route: %v", err)
+	}
+	_, err := client.PluginCall(ctx, "/api/ui/query", nil)
+	if err == nil || !strings.Contains(err.Error(), "too old (version 0.2.0), needs route /api/ui/query") {
+		t.Errorf("expected plugin too old error, got %v", err)
+	}
+	if code := toolerr.CodeOf(err); code != toolerr.PluginRouteMissing {
+		t.Errorf("code = %s, want %s", code, toolerr.PluginRouteMissing)
+	}
+	if client.NegotiatedPlugin() != nil {
+		t.Error("a 404 should drop the cached handshake")
+	}
+}
+
+func TestPluginCall_HandshakeFailureDoesNotBlock(t *testing.T) {
+	client := newTestClient("http://127.0.0.1:1", "http://127.0.0.1:1")
+	client.handshake = true
+
+	_, err := client.PluginCall(context.Background(), "/api/actors/list", nil)
+	if err == nil || !strings.Contains(err.Error(), "unreachable") {
+		t.Errorf("expected the call's own connection error, got %v", err)
+	}
+	if client.NegotiatedPlugin() != nil {
+		t.Error("failed handshake must not be cached")
+	}
+}
+
+func TestPluginInfo_Supports(t *testing.T) {
+	tests := []struct {
+		name string
+		info *PluginInfo
+		want bool
+	}{
+		{"no handshake", nil, true},
+		{"legacy", &PluginInfo{Version: "0.2.0"}, true},
+		{"listed", &PluginInfo{Routes: []string{"/api/gas/ops"}}, true},
+		{"missing", &PluginInfo{Routes: []string{"/api/status"}}, false},
+		{"empty list", &PluginInfo{Routes: []string{}}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.info.Supports("/api/gas/ops"); got != tt.want {
+				t.Errorf("Supports = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -8,12 +8,17 @@ import (
 	"fmt"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/remiphilippe/mcp-unreal/internal/toolerr"
 )
 
 // --- input_ops ---
 
 // InputOpsInput defines parameters for the input_ops tool.
 type InputOpsInput struct {
+	InstanceInput
+	TransactionInput
+	DryRunInput
+
 	Operation   string          `json:"operation" jsonschema:"required,One of: list_actions, list_contexts, add_action, remove_action, add_context, bind_action, unbind_action, get_bindings"`
 	AssetPath   string          `json:"asset_path,omitempty" jsonschema:"Input Action or Mapping Context asset path"`
 	ActionName  string          `json:"action_name,omitempty" jsonschema:"Input Action name for create/bind/unbind"`
@@ -51,7 +56,7 @@ func (h *Handler) RegisterInput(server *mcp.Server) {
 // InputOps implements the input_ops tool.
 func (h *Handler) InputOps(ctx context.Context, req *mcp.CallToolRequest, input InputOpsInput) (*mcp.CallToolResult, InputOpsOutput, error) {
 	if input.Operation == "" {
-		return nil, InputOpsOutput{}, fmt.Errorf("operation is required")
+		return nil, InputOpsOutput{}, toolerr.New(toolerr.Validation, "operation is required")
 	}
 
 	body := map[string]any{
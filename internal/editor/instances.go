@@ -0,0 +1,483 @@
+// Copyright (c) mcp-unreal project contributors. Apache-2.0 license.
+
+package editor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/remiphilippe/mcp-unreal/internal/config"
+	"github.com/remiphilippe/mcp-unreal/internal/toolerr"
+)
+
+// DefaultInstance names the editor at the configured PLUGIN_PORT and
+// RC_API_PORT. Tools target it when no instance is given.
+const DefaultInstance = "default"
+
+// discoveryTimeout bounds each /api/status probe during a port scan.
+const discoveryTimeout = 750 * time.Millisecond
+
+// InstanceInput is embedded in every editor tool input. InstanceMiddleware
+// reads it from the raw arguments and routes the call, so handlers do not
+// use it directly.
+type InstanceInput struct {
+	Instance string `json:"instance,omitempty" jsonschema:"Editor instance to target, as listed by editor_instances. Empty means the default editor"`
+}
+
+type instanceKey struct{}
+
+// WithInstance returns a context whose editor calls go to the named
+// instance.
+func WithInstance(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, instanceKey{}, name)
+}
+
+// InstanceFrom returns the editor instance selected in ctx, or "".
+func InstanceFrom(ctx context.Context) string {
+	name, _ := ctx.Value(instanceKey{}).(string)
+	return name
+}
+
+// InstanceMiddleware routes tools/call requests that carry an "instance"
+// argument to that editor instance. Install it with
+// server.AddReceivingMiddleware.
+func InstanceMiddleware(next mcp.MethodHandler) mcp.MethodHandler {
+	return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+		if call, ok := req.(*mcp.CallToolRequest); ok && call.Params != nil && len(call.Params.Arguments) > 0 {
+			var args struct {
+				Instance string `json:"instance"`
+			}
+			if json.Unmarshal(call.Params.Arguments, &args) == nil && args.Instance != "" {
+				ctx = WithInstance(ctx, args.Instance)
+			}
+		}
+		return next(ctx, method, req)
+	}
+}
+
+// InstanceInfo describes one editor instance.
+type InstanceInfo struct {
+	Name          string `json:"name" jsonschema:"instance name to pass as the instance parameter"`
+	Source        string `json:"source" jsonschema:"default, configured (MCP_UNREAL_EDITOR_INSTANCES), or discovered (port scan)"`
+	PluginURL     string `json:"plugin_url" jsonschema:"MCPUnreal plugin base URL"`
+	RCAPIURL      string `json:"rc_api_url" jsonschema:"Remote Control API base URL"`
+	Online        bool   `json:"online" jsonschema:"whether the plugin answered /api/status"`
+	Project       string `json:"project,omitempty" jsonschema:"project loaded in the editor"`
+	PluginVersion string `json:"plugin_version,omitempty" jsonschema:"MCPUnreal plugin version"`
+	PIEActive     bool   `json:"pie_active,omitempty" jsonschema:"whether Play In Editor is running"`
+	PIEMap        string `json:"pie_map,omitempty" jsonschema:"PIE world map name if active"`
+}
+
+// Instances is the registry of named editor instances: the default
+// editor, instances configured in MCP_UNREAL_EDITOR_INSTANCES, and
+// instances discovered by scanning MCP_UNREAL_DISCOVER_PORTS for the
+// plugin's /api/status. Each instance has its own Client, and so its own
+// handshake and circuit breakers, sharing the default client's transport.
+type Instances struct {
+	def   *Client
+	cfg   *config.Config
+	ports []int
+
+	mu         sync.Mutex
+	entries    map[string]*instanceEntry
+	discovered bool
+}
+
+type instanceEntry struct {
+	info   InstanceInfo
+	client *Client
+}
+
+// instanceNameRe restricts instance names to simple identifiers.
+var instanceNameRe = regexp.MustCompile(`^[A-Za-z0-9_.-]+$`)
+
+// NewInstances builds the registry around the default client and makes
+// that client route calls by instance. It fails on a malformed instance
+// list or port range.
+func NewInstances(def *Client, cfg *config.Config) (*Instances, error) {
+	r := &Instances{def: def, cfg: cfg, entries: make(map[string]*instanceEntry)}
+	r.entries[DefaultInstance] = &instanceEntry{
+		info:   InstanceInfo{Name: DefaultInstance, Source: "default", PluginURL: def.pluginBaseURL, RCAPIURL: def.rcAPIBaseURL},
+		client: def,
+	}
+
+	specs, err := parseInstanceSpecs(cfg.EditorInstances)
+	if err != nil {
+		return nil, err
+	}
+	for _, s := range specs {
+		r.add(s.name, "configured", s.pluginPort, s.rcAPIPort)
+	}
+	if r.ports, err = parsePortRange(cfg.DiscoverPorts); err != nil {
+		return nil, err
+	}
+
+	def.mu.Lock()
+	def.instances = r
+	def.mu.Unlock()
+	return r, nil
+}
+
+// add registers an instance on the configured hosts.
+func (r *Instances) add(name, source string, pluginPort, rcAPIPort int) {
+	cfg := *r.cfg
+	cfg.PluginPort, cfg.RCAPIPort = pluginPort, rcAPIPort
+	client := r.def.forEndpoints(name, cfg.PluginURL(), cfg.RCAPIURL())
+	r.entries[name] = &instanceEntry{
+		info:   InstanceInfo{Name: name, Source: source, PluginURL: client.pluginBaseURL, RCAPIURL: client.rcAPIBaseURL},
+		client: client,
+	}
+}
+
+// Client returns the client for the named instance; "" is the default.
+func (r *Instances) Client(name string) (*Client, error) {
+	if name == "" {
+		name = DefaultInstance
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	e, ok := r.entries[name]
+	if !ok {
+		return nil, toolerr.New(toolerr.Validation, "unknown editor instance %q (known: %s) — call editor_instances to list or rediscover them",
+			name, strings.Join(r.namesLocked(), ", "))
+	}
+	return e.client, nil
+}
+
+func (r *Instances) namesLocked() []string {
+	names := make([]string, 0, len(r.entries))
+	for name := range r.entries {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// Discover scans the discovery port range for MCPUnreal plugins and
+// replaces the previously discovered instances with the ones found.
+// Ports of the default and configured instances are skipped. A
+// discovered instance is named after its project, with the port appended
+// if two editors have the same project open.
+func (r *Instances) Discover(ctx context.Context) {
+	r.mu.Lock()
+	known := make(map[string]bool)
+	for _, e := range r.entries {
+		if e.info.Source != "discovered" {
+			known[e.info.PluginURL] = true
+		}
+	}
+	r.mu.Unlock()
+
+	type found struct {
+		port   int
+		status PluginStatus
+	}
+	results := make([]*found, len(r.ports))
+	var wg sync.WaitGroup
+	for i, port := range r.ports {
+		cfg := *r.cfg
+		cfg.PluginPort = port
+		if known[cfg.PluginURL()] {
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if st, err := r.def.probeStatus(ctx, cfg.PluginURL(), discoveryTimeout); err == nil {
+				results[i] = &found{port: port, status: st}
+			}
+		}()
+	}
+	wg.Wait()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for name, e := range r.entries {
+		if e.info.Source == "discovered" {
+			delete(r.entries, name)
+		}
+	}
+	for _, f := range results {
+		if f == nil {
+			continue
+		}
+		name := instanceName(f.status.Project, f.port)
+		if _, taken := r.entries[name]; taken {
+			name = fmt.Sprintf("%s-%d", name, f.port)
+		}
+		rcPort := f.status.RCAPIPort
+		if rcPort == 0 {
+			rcPort = r.cfg.RCAPIPort
+		}
+		r.add(name, "discovered", f.port, rcPort)
+	}
+	r.discovered = true
+}
+
+// clients returns the client of every instance, the default first,
+// scanning for instances first if no scan has run yet.
+func (r *Instances) clients(ctx context.Context) []*Client {
+	r.mu.Lock()
+	scan := len(r.ports) > 0 && !r.discovered
+	r.mu.Unlock()
+	if scan {
+		r.Discover(ctx)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	out := []*Client{r.def}
+	for _, name := range r.namesLocked() {
+		if name != DefaultInstance {
+			out = append(out, r.entries[name].client)
+		}
+	}
+	return out
+}
+
+// List returns every instance with live status, scanning for new ones
+// first if rescan is set or no scan has run yet.
+func (r *Instances) List(ctx context.Context, rescan bool) []InstanceInfo {
+	r.mu.Lock()
+	scan := len(r.ports) > 0 && (rescan || !r.discovered)
+	r.mu.Unlock()
+	if scan {
+		r.Discover(ctx)
+	}
+
+	r.mu.Lock()
+	names := r.namesLocked()
+	entries := make([]*instanceEntry, len(names))
+	for i, name := range names {
+		entries[i] = r.entries[name]
+	}
+	r.mu.Unlock()
+
+	out := make([]InstanceInfo, len(entries))
+	var wg sync.WaitGroup
+	for i, e := range entries {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			info := e.info
+			if st, err := e.client.probeStatus(ctx, e.client.pluginBaseURL, defaultConnectTimeout); err == nil {
+				info.Online = true
+				info.Project, info.PluginVersion = st.Project, st.Version
+				info.PIEActive, info.PIEMap = st.PIEActive, st.PIEMap
+			}
+			out[i] = info
+		}()
+	}
+	wg.Wait()
+
+	// Default first, then by name.
+	slices.SortStableFunc(out, func(a, b InstanceInfo) int {
+		switch {
+		case a.Name == DefaultInstance:
+			return -1
+		case b.Name == DefaultInstance:
+			return 1
+		}
+		return strings.Compare(a.Name, b.Name)
+	})
+	return out
+}
+
+// probeStatus asks the plugin at baseURL for its status, once. A
+// reachable plugin whose answer cannot be parsed reports an empty status.
+func (c *Client) probeStatus(ctx context.Context, baseURL string, timeout time.Duration) (PluginStatus, error) {
+	c.tokens.allow(baseURL)
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	raw, err := c.doRequest(ctx, http.MethodPost, baseURL+"/api/status", nil, "")
+	if err != nil {
+		return PluginStatus{}, err
+	}
+	var st PluginStatus
+	_ = json.Unmarshal(raw, &st)
+	return st, nil
+}
+
+// forEndpoints returns a client for another editor that shares this
+// client's transport and policies. The plugin token, if any, is sent to
+// its plugin too.
+func (c *Client) forEndpoints(name, pluginURL, rcAPIURL string) *Client {
+	c.tokens.allow(pluginURL)
+	return &Client{
+		rcAPIBaseURL:  rcAPIURL,
+		pluginBaseURL: pluginURL,
+		httpClient:    c.httpClient,
+		tokens:        c.tokens,
+		logger:        c.logger.With("instance", name),
+		retry:         c.retry,
+		breakerPolicy: c.breakerPolicy,
+		handshake:     c.handshake,
+		instance:      name,
+	}
+}
+
+// route returns the client for the instance selected in ctx.
+func (c *Client) route(ctx context.Context) (*Client, error) {
+	name := InstanceFrom(ctx)
+	if c.instance != "" || name == "" {
+		return c, nil
+	}
+	c.mu.Lock()
+	r := c.instances
+	c.mu.Unlock()
+	if r == nil {
+		if name == DefaultInstance {
+			return c, nil
+		}
+		return nil, toolerr.New(toolerr.Validation, "unknown editor instance %q — no instances are configured", name)
+	}
+	return r.Client(name)
+}
+
+// instanceName derives an instance name from a project name.
+func instanceName(project string, port int) string {
+	name := strings.Map(func(r rune) rune {
+		if r == ' ' {
+			return '_'
+		}
+		return r
+	}, strings.TrimSpace(project))
+	if name == "" || name == DefaultInstance || !instanceNameRe.MatchString(name) {
+		return "editor-" + strconv.Itoa(port)
+	}
+	return name
+}
+
+type instanceSpec struct {
+	name                  string
+	pluginPort, rcAPIPort int
+}
+
+// parseInstanceSpecs parses MCP_UNREAL_EDITOR_INSTANCES: comma-separated
+// name=pluginPort or name=pluginPort/rcAPIPort entries, e.g.
+// "client=8091/30011,server=8092/30012".
+func parseInstanceSpecs(spec string) ([]instanceSpec, error) {
+	var out []instanceSpec
+	seen := map[string]bool{DefaultInstance: true}
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		name, ports, ok := strings.Cut(entry, "=")
+		name = strings.TrimSpace(name)
+		if !ok || !instanceNameRe.MatchString(name) {
+			return nil, fmt.Errorf("editor instance %q: want name=pluginPort[/rcAPIPort]", entry)
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("editor instance %q: name used twice or reserved", name)
+		}
+		seen[name] = true
+		pluginPort, rcPort, hasRC := strings.Cut(ports, "/")
+		s := instanceSpec{name: name}
+		var err error
+		if s.pluginPort, err = parsePort(pluginPort); err != nil {
+			return nil, fmt.Errorf("editor instance %q: %w", name, err)
+		}
+		s.rcAPIPort = 30010
+		if hasRC {
+			if s.rcAPIPort, err = parsePort(rcPort); err != nil {
+				return nil, fmt.Errorf("editor instance %q: %w", name, err)
+			}
+		}
+		out = append(out, s)
+	}
+	return out, nil
+}
+
+// maxDiscoverPorts bounds the discovery scan.
+const maxDiscoverPorts = 256
+
+// parsePortRange parses MCP_UNREAL_DISCOVER_PORTS, e.g. "8090-8099".
+// An empty string disables discovery.
+func parsePortRange(spec string) ([]int, error) {
+	spec = strings.TrimSpace(spec)
+	if spec == "" {
+		return nil, nil
+	}
+	lo, hi, isRange := strings.Cut(spec, "-")
+	first, err := parsePort(lo)
+	if err != nil {
+		return nil, fmt.Errorf("discovery port range %q: %w", spec, err)
+	}
+	last := first
+	if isRange {
+		if last, err = parsePort(hi); err != nil {
+			return nil, fmt.Errorf("discovery port range %q: %w", spec, err)
+		}
+	}
+	if last < first || last-first+1 > maxDiscoverPorts {
+		return nil, fmt.Errorf("discovery port range %q: must be ascending and at most %d ports", spec, maxDiscoverPorts)
+	}
+	ports := make([]int, 0, last-first+1)
+	for p := first; p <= last; p++ {
+		ports = append(ports, p)
+	}
+	return ports, nil
+}
+
+func parsePort(s string) (int, error) {
+	p, err := strconv.Atoi(strings.TrimSpace(s))
+	if err != nil || p < 1 || p > 65535 {
+		return 0, fmt.Errorf("invalid port %q", s)
+	}
+	return p, nil
+}
+
+// EditorInstancesInput defines parameters for the editor_instances tool.
+type EditorInstancesInput struct {
+	Rescan bool `json:"rescan,omitempty" jsonschema:"Scan the discovery port range again for editors started since the last scan"`
+}
+
+// EditorInstancesOutput is returned by the editor_instances tool.
+type EditorInstancesOutput struct {
+	Instances []InstanceInfo `json:"instances" jsonschema:"known editor instances, default first"`
+	Count     int            `json:"count" jsonschema:"number of instances"`
+}
+
+// RegisterInstances adds the editor_instances tool. It is registered
+// regardless of editor availability so agents can find other editors
+// while the default one is down.
+func (h *Handler) RegisterInstances(server *mcp.Server) {
+	mcp.AddTool(server, &mcp.Tool{
+		Name: "editor_instances",
+		Description: "List the UE editor instances this server can reach: the default editor, instances " +
+			"configured in MCP_UNREAL_EDITOR_INSTANCES, and editors discovered by scanning " +
+			"MCP_UNREAL_DISCOVER_PORTS. Pass an instance's name as the 'instance' parameter of any " +
+			"editor tool to target it. Set rescan=true to pick up editors started since the last scan.",
+	}, h.EditorInstances)
+}
+
+// EditorInstances implements the editor_instances tool.
+func (h *Handler) EditorInstances(ctx context.Context, req *mcp.CallToolRequest, input EditorInstancesInput) (*mcp.CallToolResult, EditorInstancesOutput, error) {
+	h.Client.mu.Lock()
+	r := h.Client.instances
+	h.Client.mu.Unlock()
+
+	var list []InstanceInfo
+	if r != nil {
+		list = r.List(ctx, input.Rescan)
+	} else {
+		info := InstanceInfo{Name: DefaultInstance, Source: "default", PluginURL: h.Client.pluginBaseURL, RCAPIURL: h.Client.rcAPIBaseURL}
+		if st, err := h.Client.probeStatus(ctx, h.Client.pluginBaseURL, defaultConnectTimeout); err == nil {
+			info.Online, info.Project, info.PluginVersion = true, st.Project, st.Version
+			info.PIEActive, info.PIEMap = st.PIEActive, st.PIEMap
+		}
+		list = []InstanceInfo{info}
+	}
+	return nil, EditorInstancesOutput{Instances: list, Count: len(list)}, nil
+}
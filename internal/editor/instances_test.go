@@ -0,0 +1,290 @@
+// Copyright (c) mcp-unreal project contributors. Apache-2.0 license.
+
+package editor
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/remiphilippe/mcp-unreal/internal/config"
+	"github.com/remiphilippe/mcp-unreal/internal/editor/editortest"
+)
+
+func TestParseInstanceSpecs(t *testing.T) {
+	tests := []struct {
+		spec    string
+		want    []instanceSpec
+		wantErr string
+	}{
+		{"", nil, ""},
+		{"client=8091", []instanceSpec{{"client", 8091, 30010}}, ""},
+		{"client=8091/30011, server=8092/30012", []instanceSpec{{"client", 8091, 30011}, {"server", 8092, 30012}}, ""},
+		{"client", nil, "want name=pluginPort"},
+		{"a b=8091", nil, "want name=pluginPort"},
+		{"default=8091", nil, "reserved"},
+		{"x=8091,x=8092", nil, "used twice"},
+		{"x=port", nil, "invalid port"},
+		{"x=8091/70000", nil, "invalid port"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			got, err := parseInstanceSpecs(tt.spec)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("spec[%d] = %+v, want %+v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestParsePortRange(t *testing.T) {
+	tests := []struct {
+		spec      string
+		wantFirst int
+		wantLen   int
+		wantErr   bool
+	}{
+		{"", 0, 0, false},
+		{"8090", 8090, 1, false},
+		{"8090-8099", 8090, 10, false},
+		{"8099-8090", 0, 0, true},
+		{"1-1000", 0, 0, true},
+		{"x-8090", 0, 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			got, err := parsePortRange(tt.spec)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(got) != tt.wantLen || (tt.wantLen > 0 && got[0] != tt.wantFirst) {
+				t.Errorf("ports = %v, want %d from %d", got, tt.wantLen, tt.wantFirst)
+			}
+		})
+	}
+}
+
+func TestInstanceName(t *testing.T) {
+	tests := []struct {
+		project string
+		want    string
+	}{
+		{"Shooter", "Shooter"},
+		{"My Game", "My_Game"},
+		{"", "editor-8091"},
+		{"default", "editor-8091"},
+		{"Spiel/Ä", "editor-8091"},
+	}
+	for _, tt := range tests {
+		if got := instanceName(tt.project, 8091); got != tt.want {
+			t.Errorf("instanceName(%q) = %q, want %q", tt.project, got, tt.want)
+		}
+	}
+}
+
+// startFake starts a fake editor on localhost and returns it with its
+// plugin and RC API ports.
+func startFake(t *testing.T) (fake *editortest.Server, pluginPort, rcPort int) {
+	t.Helper()
+	fake = editortest.New(nil)
+	if err := fake.Start(); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = fake.Close() })
+	port := func(raw string) int {
+		_, p, _ := net.SplitHostPort(strings.TrimPrefix(raw, "http://"))
+		n, _ := strconv.Atoi(p)
+		return n
+	}
+	return fake, port(fake.PluginURL()), port(fake.RCAPIURL())
+}
+
+func TestInstancesRouting(t *testing.T) {
+	main, mainPlugin, mainRC := startFake(t)
+	other, otherPlugin, otherRC := startFake(t)
+
+	cfg := &config.Config{PluginPort: mainPlugin, RCAPIPort: mainRC,
+		EditorInstances: "other=" + strconv.Itoa(otherPlugin) + "/" + strconv.Itoa(otherRC)}
+	client := NewClient(cfg, testLogger())
+	if _, err := NewInstances(client, cfg); err != nil {
+		t.Fatalf("NewInstances: %v", err)
+	}
+	ctx := context.Background()
+
+	tests := []struct {
+		name     string
+		instance string
+		want     *editortest.Server
+		wantErr  string
+	}{
+		{"no instance", "", main, ""},
+		{"default", DefaultInstance, main, ""},
+		{"configured", "other", other, ""},
+		{"unknown", "nope", nil, `unknown editor instance "nope" (known: default, other)`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			main.ResetCalls()
+			other.ResetCalls()
+			ctx := WithInstance(ctx, tt.instance)
+			_, err := client.PluginCall(ctx, "/api/actors/list", map[string]any{})
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("PluginCall: %v", err)
+			}
+			// The fake rejects the empty body; only where it went matters.
+			_, _ = client.RCAPICall(ctx, "/remote/object/property", map[string]any{})
+			for _, fake := range []*editortest.Server{main, other} {
+				if got, want := len(fake.Calls()) > 0, fake == tt.want; got != want {
+					t.Errorf("fake %p received calls = %v, want %v", fake, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestInstancesDiscover(t *testing.T) {
+	_, mainPlugin, mainRC := startFake(t)
+	_, otherPlugin, otherRC := startFake(t)
+
+	// The range covers both fakes; the default editor's port is skipped.
+	lo, hi := min(mainPlugin, otherPlugin), max(mainPlugin, otherPlugin)
+	if hi-lo >= maxDiscoverPorts {
+		t.Skipf("fake ports %d and %d too far apart for one scan", lo, hi)
+	}
+	cfg := &config.Config{PluginPort: mainPlugin, RCAPIPort: mainRC,
+		DiscoverPorts: strconv.Itoa(lo) + "-" + strconv.Itoa(hi)}
+	client := NewClient(cfg, testLogger())
+	r, err := NewInstances(client, cfg)
+	if err != nil {
+		t.Fatalf("NewInstances: %v", err)
+	}
+
+	list := r.List(context.Background(), false)
+	if len(list) != 2 {
+		t.Fatalf("instances = %+v, want default and one discovered", list)
+	}
+	if list[0].Name != DefaultInstance || !list[0].Online {
+		t.Errorf("first instance = %+v, want online default", list[0])
+	}
+	found := list[1]
+	if found.Name != "FakeProject" || found.Source != "discovered" || !found.Online || found.PluginVersion != editortest.PluginVersion {
+		t.Errorf("discovered instance = %+v", found)
+	}
+	if want := ":" + strconv.Itoa(otherRC); !strings.HasSuffix(found.RCAPIURL, want) {
+		t.Errorf("discovered RC API URL = %s, want port from the plugin's rc_api_port %s", found.RCAPIURL, want)
+	}
+}
+
+func TestInstancesAuthToken(t *testing.T) {
+	main, mainPlugin, mainRC := startFake(t)
+	other, otherPlugin, otherRC := startFake(t)
+	main.RequireToken("s3cret")
+	other.RequireToken("s3cret")
+
+	// The token reaches a configured instance on another port.
+	cfg := &config.Config{PluginPort: mainPlugin, RCAPIPort: mainRC, EditorToken: "s3cret",
+		EditorInstances: "other=" + strconv.Itoa(otherPlugin) + "/" + strconv.Itoa(otherRC)}
+	client := NewClient(cfg, testLogger())
+	if _, err := NewInstances(client, cfg); err != nil {
+		t.Fatalf("NewInstances: %v", err)
+	}
+	for _, instance := range []string{DefaultInstance, "other"} {
+		if _, err := client.PluginCall(WithInstance(context.Background(), instance), "/api/actors/list", map[string]any{}); err != nil {
+			t.Errorf("PluginCall on %s: %v", instance, err)
+		}
+	}
+
+	// Discovery probes carry it too, so a protected editor is found.
+	lo, hi := min(mainPlugin, otherPlugin), max(mainPlugin, otherPlugin)
+	if hi-lo >= maxDiscoverPorts {
+		t.Skipf("fake ports %d and %d too far apart for one scan", lo, hi)
+	}
+	cfg = &config.Config{PluginPort: mainPlugin, RCAPIPort: mainRC, EditorToken: "s3cret",
+		DiscoverPorts: strconv.Itoa(lo) + "-" + strconv.Itoa(hi)}
+	r, err := NewInstances(NewClient(cfg, testLogger()), cfg)
+	if err != nil {
+		t.Fatalf("NewInstances: %v", err)
+	}
+	list := r.List(context.Background(), false)
+	if len(list) != 2 || !list[0].Online || !list[1].Online {
+		t.Errorf("instances = %+v, want default and one discovered, both online", list)
+	}
+}
+
+func TestInstanceMiddleware(t *testing.T) {
+	main, mainPlugin, mainRC := startFake(t)
+	other, otherPlugin, otherRC := startFake(t)
+	cfg := &config.Config{PluginPort: mainPlugin, RCAPIPort: mainRC,
+		EditorInstances: "other=" + strconv.Itoa(otherPlugin) + "/" + strconv.Itoa(otherRC)}
+	client := NewClient(cfg, testLogger())
+	if _, err := NewInstances(client, cfg); err != nil {
+		t.Fatal(err)
+	}
+	h := &Handler{Client: client, Logger: testLogger()}
+	server := mcp.NewServer(&mcp.Implementation{Name: "test"}, nil)
+	server.AddReceivingMiddleware(InstanceMiddleware)
+	h.RegisterActors(server)
+	h.RegisterInstances(server)
+
+	ctx := context.Background()
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	ss, err := server.Connect(ctx, serverTransport, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = ss.Close() }()
+	session, err := mcp.NewClient(&mcp.Implementation{Name: "test-client"}, nil).Connect(ctx, clientTransport, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = session.Close() }()
+
+	main.ResetCalls()
+	res, err := session.CallTool(ctx, &mcp.CallToolParams{
+		Name:      "get_level_actors",
+		Arguments: map[string]any{"instance": "other"},
+	})
+	if err != nil || res.IsError {
+		t.Fatalf("get_level_actors on other: %v %+v", err, res)
+	}
+	if len(main.Calls()) != 0 || len(other.Calls()) == 0 {
+		t.Errorf("calls: default %d, other %d; want the call on other only", len(main.Calls()), len(other.Calls()))
+	}
+
+	res, err = session.CallTool(ctx, &mcp.CallToolParams{Name: "editor_instances", Arguments: map[string]any{}})
+	if err != nil || res.IsError {
+		t.Fatalf("editor_instances: %v %+v", err, res)
+	}
+	var out EditorInstancesOutput
+	raw, _ := json.Marshal(res.StructuredContent)
+	if err := json.Unmarshal(raw, &out); err != nil {
+		t.Fatal(err)
+	}
+	if out.Count != 2 || out.Instances[0].Name != DefaultInstance || out.Instances[1].Name != "other" {
+		t.Errorf("editor_instances = %+v", out)
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"fmt"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/remiphilippe/mcp-unreal/internal/toolerr"
 )
 
 // --- ism_ops ---
@@ -21,6 +22,10 @@ type ISMTransform struct {
 
 // ISMOpsInput defines parameters for the ism_ops tool.
 type ISMOpsInput struct {
+	InstanceInput
+	TransactionInput
+	DryRunInput
+
 	Operation string `json:"operation" jsonschema:"required,Operation: create, add_instances, clear_instances, get_instance_count, update_instance, remove_instance, set_material"`
 	// For create: actor to add the ISM component to.
 	ActorPath string `json:"actor_path,omitempty" jsonschema:"Actor object path (for create operation)"`
@@ -63,7 +68,7 @@ func (h *Handler) RegisterISM(server *mcp.Server) {
 // ISMOps implements the ism_ops tool.
 func (h *Handler) ISMOps(ctx context.Context, req *mcp.CallToolRequest, input ISMOpsInput) (*mcp.CallToolResult, ISMOpsOutput, error) {
 	if input.Operation == "" {
-		return nil, ISMOpsOutput{}, fmt.Errorf("operation is required")
+		return nil, ISMOpsOutput{}, toolerr.New(toolerr.Validation, "operation is required")
 	}
 
 	body := map[string]any{
@@ -8,12 +8,17 @@ import (
 	"fmt"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/remiphilippe/mcp-unreal/internal/toolerr"
 )
 
 // --- level_ops ---
 
 // LevelOpsInput defines parameters for the level_ops tool.
 type LevelOpsInput struct {
+	InstanceInput
+	TransactionInput
+	DryRunInput
+
 	Operation   string          `json:"operation" jsonschema:"required,One of: get_current, list_levels, load_level, save_level, new_level, add_sublevel, remove_sublevel, set_streaming_method"`
 	LevelPath   string          `json:"level_path,omitempty" jsonschema:"Level asset path (e.g. /Game/Maps/MainLevel)"`
 	LevelName   string          `json:"level_name,omitempty" jsonschema:"Level name for new_level"`
@@ -49,7 +54,7 @@ func (h *Handler) RegisterLevels(server *mcp.Server) {
 // LevelOps implements the level_ops tool.
 func (h *Handler) LevelOps(ctx context.Context, req *mcp.CallToolRequest, input LevelOpsInput) (*mcp.CallToolResult, LevelOpsOutput, error) {
 	if input.Operation == "" {
-		return nil, LevelOpsOutput{}, fmt.Errorf("operation is required")
+		return nil, LevelOpsOutput{}, toolerr.New(toolerr.Validation, "operation is required")
 	}
 
 	body := map[string]any{
@@ -8,12 +8,17 @@ import (
 	"fmt"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/remiphilippe/mcp-unreal/internal/toolerr"
 )
 
 // --- material_ops ---
 
 // MaterialOpsInput defines parameters for the material_ops tool.
 type MaterialOpsInput struct {
+	InstanceInput
+	TransactionInput
+	DryRunInput
+
 	Operation    string          `json:"operation" jsonschema:"required,One of: create, set_parameter, get_parameters, set_texture, create_instance, list_parameters"`
 	MaterialPath string          `json:"material_path,omitempty" jsonschema:"Material asset path — required for most operations"`
 	ParentPath   string          `json:"parent_path,omitempty" jsonschema:"Parent material path for create_instance"`
@@ -50,7 +55,7 @@ func (h *Handler) RegisterMaterials(server *mcp.Server) {
 // MaterialOps implements the material_ops tool.
 func (h *Handler) MaterialOps(ctx context.Context, req *mcp.CallToolRequest, input MaterialOpsInput) (*mcp.CallToolResult, MaterialOpsOutput, error) {
 	if input.Operation == "" {
-		return nil, MaterialOpsOutput{}, fmt.Errorf("operation is required")
+		return nil, MaterialOpsOutput{}, toolerr.New(toolerr.Validation, "operation is required")
 	}
 
 	body := map[string]any{
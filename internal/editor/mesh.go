@@ -8,12 +8,17 @@ import (
 	"fmt"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/remiphilippe/mcp-unreal/internal/toolerr"
 )
 
 // --- procedural_mesh ---
 
 // ProceduralMeshInput defines parameters for the procedural_mesh tool.
 type ProceduralMeshInput struct {
+	InstanceInput
+	TransactionInput
+	DryRunInput
+
 	Operation    string          `json:"operation" jsonschema:"required,One of: create_section, update_section, clear, set_material"`
 	ActorPath    string          `json:"actor_path,omitempty" jsonschema:"ProceduralMeshActor object path — required for update/clear/set_material"`
 	ActorName    string          `json:"actor_name,omitempty" jsonschema:"Display name for the actor (used with create_section to spawn)"`
@@ -38,6 +43,10 @@ type ProceduralMeshOutput struct {
 
 // RealtimeMeshInput defines parameters for the realtime_mesh tool.
 type RealtimeMeshInput struct {
+	InstanceInput
+	TransactionInput
+	DryRunInput
+
 	Operation          string          `json:"operation" jsonschema:"required,One of: create_lod, create_section_group, create_section, update_mesh_data, set_material_slot, setup_collision"`
 	ActorPath          string          `json:"actor_path,omitempty" jsonschema:"RealtimeMeshActor object path"`
 	ActorName          string          `json:"actor_name,omitempty" jsonschema:"Display name for the actor"`
@@ -95,7 +104,7 @@ func (h *Handler) RegisterMesh(server *mcp.Server) {
 // ProceduralMesh implements the procedural_mesh tool.
 func (h *Handler) ProceduralMesh(ctx context.Context, req *mcp.CallToolRequest, input ProceduralMeshInput) (*mcp.CallToolResult, ProceduralMeshOutput, error) {
 	if input.Operation == "" {
-		return nil, ProceduralMeshOutput{}, fmt.Errorf("operation is required (create_section, update_section, clear, set_material)")
+		return nil, ProceduralMeshOutput{}, toolerr.New(toolerr.Validation, "operation is required (create_section, update_section, clear, set_material)")
 	}
 
 	body := map[string]any{
@@ -153,7 +162,7 @@ func (h *Handler) ProceduralMesh(ctx context.Context, req *mcp.CallToolRequest,
 // RealtimeMesh implements the realtime_mesh tool.
 func (h *Handler) RealtimeMesh(ctx context.Context, req *mcp.CallToolRequest, input RealtimeMeshInput) (*mcp.CallToolResult, RealtimeMeshOutput, error) {
 	if input.Operation == "" {
-		return nil, RealtimeMeshOutput{}, fmt.Errorf("operation is required (create_lod, create_section_group, create_section, update_mesh_data, set_material_slot, setup_collision)")
+		return nil, RealtimeMeshOutput{}, toolerr.New(toolerr.Validation, "operation is required (create_lod, create_section_group, create_section, update_mesh_data, set_material_slot, setup_collision)")
 	}
 
 	body := map[string]any{
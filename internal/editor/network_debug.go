@@ -8,6 +8,7 @@ import (
 	"fmt"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/remiphilippe/mcp-unreal/internal/toolerr"
 )
 
 // --- network_debug ---
@@ -34,6 +35,8 @@ type WebSocketInfo struct {
 
 // NetworkDebugInput defines parameters for the network_debug tool.
 type NetworkDebugInput struct {
+	InstanceInput
+
 	Operation string `json:"operation" jsonschema:"required,Operation: list_active, recent_requests, websocket_status, summary"`
 	LastN     int    `json:"last_n,omitempty" jsonschema:"Number of recent requests to return (default 20). For recent_requests."`
 }
@@ -67,7 +70,7 @@ func (h *Handler) RegisterNetworkDebug(server *mcp.Server) {
 // NetworkDebug implements the network_debug tool.
 func (h *Handler) NetworkDebug(ctx context.Context, req *mcp.CallToolRequest, input NetworkDebugInput) (*mcp.CallToolResult, NetworkDebugOutput, error) {
 	if input.Operation == "" {
-		return nil, NetworkDebugOutput{}, fmt.Errorf("operation is required")
+		return nil, NetworkDebugOutput{}, toolerr.New(toolerr.Validation, "operation is required")
 	}
 
 	body := map[string]any{
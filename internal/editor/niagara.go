@@ -8,12 +8,17 @@ import (
 	"fmt"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/remiphilippe/mcp-unreal/internal/toolerr"
 )
 
 // --- niagara_ops ---
 
 // NiagaraOpsInput defines parameters for the niagara_ops tool.
 type NiagaraOpsInput struct {
+	InstanceInput
+	TransactionInput
+	DryRunInput
+
 	Operation      string          `json:"operation" jsonschema:"required,One of: spawn_system, set_parameter, get_system_info, add_emitter, remove_emitter, activate, deactivate"`
 	SystemPath     string          `json:"system_path,omitempty" jsonschema:"Niagara system asset path — required for spawn_system, get_system_info, add_emitter, remove_emitter"`
 	ActorPath      string          `json:"actor_path,omitempty" jsonschema:"Actor with NiagaraComponent — required for set_parameter, activate, deactivate"`
@@ -55,7 +60,7 @@ func (h *Handler) RegisterNiagara(server *mcp.Server) {
 // NiagaraOps implements the niagara_ops tool.
 func (h *Handler) NiagaraOps(ctx context.Context, req *mcp.CallToolRequest, input NiagaraOpsInput) (*mcp.CallToolResult, NiagaraOpsOutput, error) {
 	if input.Operation == "" {
-		return nil, NiagaraOpsOutput{}, fmt.Errorf("operation is required (spawn_system, set_parameter, get_system_info, add_emitter, remove_emitter, activate, deactivate)")
+		return nil, NiagaraOpsOutput{}, toolerr.New(toolerr.Validation, "operation is required (spawn_system, set_parameter, get_system_info, add_emitter, remove_emitter, activate, deactivate)")
 	}
 
 	body := map[string]any{
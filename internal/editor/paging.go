@@ -0,0 +1,331 @@
+// Copyright (c) mcp-unreal project contributors. Apache-2.0 license.
+
+package editor
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/remiphilippe/mcp-unreal/internal/toolerr"
+)
+
+// PageInput is embedded in the input of every editor tool that returns a
+// list. PageMiddleware reads it from the raw arguments and pages the
+// tool's result, so handlers do not use it directly.
+type PageInput struct {
+	Limit     int      `json:"limit,omitempty" jsonschema:"Maximum number of list items to return (default: as many as fit in the token budget)"`
+	Cursor    string   `json:"cursor,omitempty" jsonschema:"Opaque cursor from a previous call's page.next_cursor, to get the next page. Other arguments must be unchanged"`
+	Fields    []string `json:"fields,omitempty" jsonschema:"Return only these fields of each list item, e.g. [\"name\",\"path\"]. Dotted names select nested fields, e.g. data.Damage"`
+	MaxTokens int      `json:"max_tokens,omitempty" jsonschema:"Approximate token budget for the list items in one response (default: the server's MCP_UNREAL_RESPONSE_TOKEN_BUDGET)"`
+}
+
+// PageInfo describes the page of a list a paged tool returned.
+type PageInfo struct {
+	Total      int    `json:"total" jsonschema:"number of items in the whole list"`
+	Offset     int    `json:"offset" jsonschema:"index of the first item returned"`
+	Returned   int    `json:"returned" jsonschema:"number of items returned"`
+	NextCursor string `json:"next_cursor,omitempty" jsonschema:"cursor for the next page; absent on the last page"`
+}
+
+// pagedLists names, for each paged tool and operation, the output field
+// holding its list. Tools without an operation argument use "". A list
+// whose items nest more items under "children" (ui_query tree) is paged
+// by its top-level items and projected at every level.
+var pagedLists = map[string]map[string]string{
+	"get_level_actors": {"": "actors"},
+	"search_assets":    {"": "assets"},
+	"blueprint_query":  {"list": "result"},
+	"ui_query":         {"tree": "widgets", "find": "widgets"},
+	"data_asset_ops":   {"get_table": "rows", "list_tables": "tables"},
+}
+
+// pagedDescription is appended to the descriptions of paged tools.
+const pagedDescription = "Long lists are returned a page at a time: pass page.next_cursor back as cursor for the next page, " +
+	"limit to set the page size, and fields to return only some fields of each item."
+
+// bytesPerToken is the rough size of a token in JSON output, used to
+// estimate the tokens a list item costs.
+const bytesPerToken = 4
+
+// pagedTool returns t with the output schema of Out, relaxed so that the
+// items of the named list fields require none of their properties: a
+// fields projection leaves items with only the fields asked for.
+func pagedTool[Out any](t *mcp.Tool, fields ...string) *mcp.Tool {
+	schema, err := jsonschema.For[Out](nil)
+	if err != nil {
+		panic(fmt.Sprintf("output schema for %s: %v", t.Name, err))
+	}
+	for _, f := range fields {
+		if prop := schema.Properties[f]; prop != nil && prop.Items != nil {
+			unrequire(prop.Items)
+		}
+	}
+	t.OutputSchema = schema
+	return t
+}
+
+// unrequire clears the required properties of s and the schemas nested
+// in it.
+func unrequire(s *jsonschema.Schema) {
+	s.Required = nil
+	for _, p := range s.Properties {
+		unrequire(p)
+	}
+	if s.Items != nil {
+		unrequire(s.Items)
+	}
+}
+
+// pageArgs are the arguments PageMiddleware reads from a call.
+type pageArgs struct {
+	Operation string `json:"operation"`
+	PageInput
+}
+
+// pageCursor is the decoded form of a cursor: where the next page starts
+// and a digest of the query it belongs to.
+type pageCursor struct {
+	Offset int    `json:"o"`
+	Query  string `json:"q"`
+}
+
+// PageMiddleware pages the lists returned by the tools in pagedLists.
+// Each response holds the items from the cursor on, up to the call's
+// limit and within its token budget (tokenBudget unless the call sets
+// max_tokens; zero or less is unbounded), projected to the call's
+// fields. At least one item is returned while any remain. The output
+// gains a page object with the total count and the cursor for the next
+// page. The editor is asked for the whole list on every page, so a list
+// that changes between pages may skip or repeat items. Install it with
+// server.AddReceivingMiddleware.
+func PageMiddleware(tokenBudget int) mcp.Middleware {
+	return func(next mcp.MethodHandler) mcp.MethodHandler {
+		return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+			call, ok := req.(*mcp.CallToolRequest)
+			if !ok || call.Params == nil || pagedLists[call.Params.Name] == nil {
+				return next(ctx, method, req)
+			}
+			var args pageArgs
+			if len(call.Params.Arguments) > 0 {
+				if json.Unmarshal(call.Params.Arguments, &args) != nil {
+					return next(ctx, method, req)
+				}
+			}
+			field, ok := pagedLists[call.Params.Name][args.Operation]
+			if !ok {
+				return next(ctx, method, req)
+			}
+
+			query := queryDigest(call.Params.Arguments)
+			offset, err := decodeCursor(args.Cursor, query)
+			if err != nil {
+				return errorResult(err), nil
+			}
+			res, err := next(ctx, method, req)
+			tool, ok := res.(*mcp.CallToolResult)
+			if err != nil || !ok || tool.IsError || tool.StructuredContent == nil {
+				return res, err
+			}
+
+			budget := tokenBudget
+			if args.MaxTokens > 0 {
+				budget = args.MaxTokens
+			}
+			if err := pageResult(tool, field, offset, query, args.Limit, budget, args.Fields); err != nil {
+				return errorResult(err), nil
+			}
+			return tool, nil
+		}
+	}
+}
+
+// pageResult replaces the list in res's structured output with the page
+// starting at offset, and adds the page object.
+func pageResult(res *mcp.CallToolResult, field string, offset int, query string, limit, budget int, fields []string) error {
+	data, err := json.Marshal(res.StructuredContent)
+	if err != nil {
+		return fmt.Errorf("paging %s: %w", field, err)
+	}
+	var out map[string]any
+	if err := json.Unmarshal(data, &out); err != nil {
+		return fmt.Errorf("paging %s: %w", field, err)
+	}
+	items, _ := out[field].([]any)
+	if offset > len(items) {
+		return toolerr.New(toolerr.Validation, "cursor is past the end of the list (%d items): it changed since the cursor was issued — call again without cursor", len(items))
+	}
+
+	page := []any{}
+	tokens := 0
+	for _, item := range items[offset:] {
+		if limit > 0 && len(page) >= limit {
+			break
+		}
+		if len(fields) > 0 {
+			item = project(item, fields)
+		}
+		cost := estimateTokens(item)
+		if budget > 0 && len(page) > 0 && tokens+cost > budget {
+			break
+		}
+		page = append(page, item)
+		tokens += cost
+	}
+	if len(fields) > 0 && len(page) > 0 && slices.IndexFunc(page, nonEmpty) < 0 {
+		return toolerr.New(toolerr.Validation, "none of fields %v are in the %s items — available fields: %s",
+			fields, field, strings.Join(itemFields(items[offset]), ", "))
+	}
+
+	info := PageInfo{Total: len(items), Offset: offset, Returned: len(page)}
+	if end := offset + len(page); end < len(items) {
+		info.NextCursor = encodeCursor(pageCursor{Offset: end, Query: query})
+	}
+	out[field] = page
+	out["page"] = info
+
+	text, err := json.Marshal(out)
+	if err != nil {
+		return fmt.Errorf("paging %s: %w", field, err)
+	}
+	res.StructuredContent = json.RawMessage(text)
+	// The SDK fills Content with the structured output when the handler
+	// sets none; keep the two in step.
+	if len(res.Content) == 1 {
+		if tc, ok := res.Content[0].(*mcp.TextContent); ok && json.Valid([]byte(tc.Text)) {
+			res.Content = []mcp.Content{&mcp.TextContent{Text: string(text)}}
+		}
+	}
+	return nil
+}
+
+// project returns the given fields of item. A dotted field selects a
+// field of a nested object. Widget trees keep their children, projected
+// the same way.
+func project(item any, fields []string) any {
+	obj, ok := item.(map[string]any)
+	if !ok {
+		return item
+	}
+	out := map[string]any{}
+	for _, f := range fields {
+		copyField(out, obj, strings.Split(f, "."))
+	}
+	if children, ok := obj["children"].([]any); ok {
+		projected := make([]any, 0, len(children))
+		for _, c := range children {
+			projected = append(projected, project(c, fields))
+		}
+		out["children"] = projected
+	}
+	return out
+}
+
+// copyField copies the value at path from src into dst, creating the
+// nested objects on the way.
+func copyField(dst, src map[string]any, path []string) {
+	v, ok := src[path[0]]
+	if !ok {
+		return
+	}
+	if len(path) == 1 {
+		dst[path[0]] = v
+		return
+	}
+	inner, ok := v.(map[string]any)
+	if !ok {
+		return
+	}
+	sub, _ := dst[path[0]].(map[string]any)
+	if sub == nil {
+		sub = map[string]any{}
+	}
+	copyField(sub, inner, path[1:])
+	if len(sub) > 0 {
+		dst[path[0]] = sub
+	}
+}
+
+func nonEmpty(item any) bool {
+	obj, ok := item.(map[string]any)
+	if !ok {
+		return true
+	}
+	for k := range obj {
+		if k != "children" {
+			return true
+		}
+	}
+	return false
+}
+
+// itemFields lists the top-level fields of a list item.
+func itemFields(item any) []string {
+	obj, _ := item.(map[string]any)
+	keys := make([]string, 0, len(obj))
+	for k := range obj {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// estimateTokens estimates the tokens item takes up in the response.
+func estimateTokens(item any) int {
+	data, err := json.Marshal(item)
+	if err != nil {
+		return 0
+	}
+	return (len(data) + bytesPerToken - 1) / bytesPerToken
+}
+
+// queryDigest identifies the query a call makes: its arguments other
+// than the paging ones. A cursor is only valid for the query it came
+// from.
+func queryDigest(raw json.RawMessage) string {
+	var args map[string]any
+	_ = json.Unmarshal(raw, &args)
+	for _, k := range []string{"limit", "cursor", "fields", "max_tokens"} {
+		delete(args, k)
+	}
+	// encoding/json sorts map keys, so equal queries marshal the same.
+	data, _ := json.Marshal(args)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:8])
+}
+
+func encodeCursor(c pageCursor) string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decodeCursor returns the offset a cursor points at, checking that it
+// belongs to query. The empty cursor is the first page.
+func decodeCursor(cursor, query string) (int, error) {
+	if cursor == "" {
+		return 0, nil
+	}
+	var c pageCursor
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil || json.Unmarshal(data, &c) != nil || c.Offset < 0 {
+		return 0, toolerr.New(toolerr.Validation, "invalid cursor %q — pass page.next_cursor from the previous call unchanged", cursor)
+	}
+	if c.Query != query {
+		return 0, toolerr.New(toolerr.Validation, "cursor belongs to a call with different arguments — repeat the previous call's arguments, changing only cursor, limit, fields, or max_tokens")
+	}
+	return c.Offset, nil
+}
+
+func errorResult(err error) *mcp.CallToolResult {
+	res := &mcp.CallToolResult{}
+	res.SetError(err)
+	return res
+}
@@ -0,0 +1,172 @@
+// Copyright (c) mcp-unreal project contributors. Apache-2.0 license.
+
+package editor
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/remiphilippe/mcp-unreal/internal/config"
+)
+
+func TestPageMiddleware(t *testing.T) {
+	_, pluginPort, rcPort := startFake(t)
+	client := NewClient(&config.Config{PluginPort: pluginPort, RCAPIPort: rcPort}, testLogger())
+	h := &Handler{Client: client, Logger: testLogger()}
+	server := mcp.NewServer(&mcp.Implementation{Name: "test"}, nil)
+	server.AddReceivingMiddleware(PageMiddleware(0), InstanceMiddleware)
+	h.RegisterActors(server)
+
+	ctx := context.Background()
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	ss, err := server.Connect(ctx, serverTransport, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = ss.Close() }()
+	session, err := mcp.NewClient(&mcp.Implementation{Name: "test-client"}, nil).Connect(ctx, clientTransport, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = session.Close() }()
+
+	call := func(args map[string]any) (*mcp.CallToolResult, map[string]any) {
+		t.Helper()
+		res, err := session.CallTool(ctx, &mcp.CallToolParams{Name: "get_level_actors", Arguments: args})
+		if err != nil {
+			t.Fatal(err)
+		}
+		var out map[string]any
+		raw, _ := json.Marshal(res.StructuredContent)
+		_ = json.Unmarshal(raw, &out)
+		return res, out
+	}
+
+	// Page through the three sample actors two at a time.
+	var names []string
+	args := map[string]any{"limit": 2, "fields": []string{"name"}}
+	for pages := 0; ; pages++ {
+		res, out := call(args)
+		if res.IsError || pages > 2 {
+			t.Fatalf("page %d: %+v", pages, res.Content)
+		}
+		for _, a := range out["actors"].([]any) {
+			actor := a.(map[string]any)
+			if len(actor) != 1 {
+				t.Errorf("projected actor = %v, want only name", actor)
+			}
+			names = append(names, actor["name"].(string))
+		}
+		page := out["page"].(map[string]any)
+		if page["total"] != 3.0 || out["total"] != 3.0 {
+			t.Errorf("page = %v, total = %v", page, out["total"])
+		}
+		cursor, _ := page["next_cursor"].(string)
+		if cursor == "" {
+			break
+		}
+		args["cursor"] = cursor
+	}
+	if strings.Join(names, ",") != "Floor,DirectionalLight,PlayerStart" {
+		t.Errorf("paged names = %v", names)
+	}
+
+	// A cursor is refused for a different query.
+	_, first := call(map[string]any{"limit": 1})
+	cursor := first["page"].(map[string]any)["next_cursor"].(string)
+	res, _ := call(map[string]any{"limit": 1, "cursor": cursor, "class_filter": "PlayerStart"})
+	if !res.IsError {
+		t.Error("cursor reused with a different class_filter was accepted")
+	}
+	res, _ = call(map[string]any{"cursor": "not-a-cursor"})
+	if !res.IsError {
+		t.Error("malformed cursor was accepted")
+	}
+
+	// A token budget smaller than one item still returns one item.
+	_, out := call(map[string]any{"max_tokens": 1})
+	if page := out["page"].(map[string]any); page["returned"] != 1.0 || page["next_cursor"] == nil {
+		t.Errorf("max_tokens=1 page = %v", page)
+	}
+
+	// Fields that no item has are reported with the fields available.
+	res, _ = call(map[string]any{"fields": []string{"nope"}})
+	if !res.IsError || !strings.Contains(res.Content[0].(*mcp.TextContent).Text, "available fields: class, location, name") {
+		t.Errorf("unknown fields result = %+v", res.Content)
+	}
+}
+
+func TestPageResult(t *testing.T) {
+	page := func(structured any, field string, limit, budget int, fields []string) map[string]any {
+		t.Helper()
+		res := &mcp.CallToolResult{StructuredContent: structured}
+		if err := pageResult(res, field, 0, "q", limit, budget, fields); err != nil {
+			t.Fatal(err)
+		}
+		var out map[string]any
+		if err := json.Unmarshal(res.StructuredContent.(json.RawMessage), &out); err != nil {
+			t.Fatal(err)
+		}
+		return out
+	}
+
+	// Dotted fields select nested values of DataTable rows.
+	rows := DataAssetOpsOutput{Success: true, Rows: []DataTableRow{
+		{RowName: "Sword", Data: map[string]any{"Damage": 10, "Weight": 3}},
+		{RowName: "Shield", Data: map[string]any{"Damage": 0, "Weight": 8}},
+	}}
+	out := page(rows, "rows", 0, 0, []string{"row_name", "data.Damage"})
+	got, _ := json.Marshal(out["rows"])
+	if string(got) != `[{"data":{"Damage":10},"row_name":"Sword"},{"data":{"Damage":0},"row_name":"Shield"}]` {
+		t.Errorf("projected rows = %s", got)
+	}
+
+	// Widget trees are projected at every depth.
+	tree := UIQueryOutput{Widgets: []WidgetInfo{{
+		Type: "SWindow", Visible: true,
+		Children: []any{map[string]any{"type": "SButton", "visible": true, "enabled": true}},
+	}}}
+	out = page(tree, "widgets", 0, 0, []string{"type"})
+	got, _ = json.Marshal(out["widgets"])
+	if string(got) != `[{"children":[{"type":"SButton"}],"type":"SWindow"}]` {
+		t.Errorf("projected tree = %s", got)
+	}
+
+	// The budget cuts the page between items.
+	assets := SearchAssetsOutput{Total: 3}
+	for _, n := range []string{"A", "B", "C"} {
+		assets.Assets = append(assets.Assets, AssetEntry{Name: n, Path: "/Game/" + n, Class: "Texture2D", Package: "/Game/" + n})
+	}
+	one := estimateTokens(map[string]any{"name": "A", "path": "/Game/A", "class": "Texture2D", "package": "/Game/A"})
+	out = page(assets, "assets", 0, 2*one, nil)
+	if p := out["page"].(map[string]any); p["returned"] != 2.0 || p["total"] != 3.0 {
+		t.Errorf("budgeted page = %v", p)
+	}
+}
+
+func TestPagedTool_RelaxesItems(t *testing.T) {
+	tool := pagedTool[GetLevelActorsOutput](&mcp.Tool{Name: "get_level_actors"}, "actors")
+	data, _ := json.Marshal(tool.OutputSchema)
+	var schema struct {
+		Required   []string `json:"required"`
+		Properties struct {
+			Actors struct {
+				Items struct {
+					Required []string `json:"required"`
+				} `json:"items"`
+			} `json:"actors"`
+		} `json:"properties"`
+	}
+	if err := json.Unmarshal(data, &schema); err != nil {
+		t.Fatal(err)
+	}
+	if len(schema.Properties.Actors.Items.Required) != 0 {
+		t.Errorf("actor items still require %v", schema.Properties.Actors.Items.Required)
+	}
+	if len(schema.Required) == 0 {
+		t.Error("top-level required fields were dropped")
+	}
+}
@@ -8,12 +8,17 @@ import (
 	"fmt"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/remiphilippe/mcp-unreal/internal/toolerr"
 )
 
 // --- pcg_ops ---
 
 // PCGOpsInput defines parameters for the pcg_ops tool.
 type PCGOpsInput struct {
+	InstanceInput
+	TransactionInput
+	DryRunInput
+
 	Operation      string          `json:"operation" jsonschema:"required,One of: execute, cleanup, get_graph_info, set_parameter, add_node, connect_nodes, remove_node"`
 	ActorPath      string          `json:"actor_path,omitempty" jsonschema:"Actor with UPCGComponent — required for execute, cleanup, set_parameter"`
 	GraphPath      string          `json:"graph_path,omitempty" jsonschema:"PCG graph asset path — required for get_graph_info, add_node, connect_nodes, remove_node"`
@@ -53,7 +58,7 @@ func (h *Handler) RegisterPCG(server *mcp.Server) {
 // PCGOps implements the pcg_ops tool.
 func (h *Handler) PCGOps(ctx context.Context, req *mcp.CallToolRequest, input PCGOpsInput) (*mcp.CallToolResult, PCGOpsOutput, error) {
 	if input.Operation == "" {
-		return nil, PCGOpsOutput{}, fmt.Errorf("operation is required (execute, cleanup, get_graph_info, set_parameter, add_node, connect_nodes, remove_node)")
+		return nil, PCGOpsOutput{}, toolerr.New(toolerr.Validation, "operation is required (execute, cleanup, get_graph_info, set_parameter, add_node, connect_nodes, remove_node)")
 	}
 
 	body := map[string]any{
@@ -8,12 +8,17 @@ import (
 	"fmt"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/remiphilippe/mcp-unreal/internal/toolerr"
 )
 
 // --- set_property ---
 
 // SetPropertyInput defines parameters for the set_property tool.
 type SetPropertyInput struct {
+	InstanceInput
+	TransactionInput
+	DryRunInput
+
 	ObjectPath    string          `json:"object_path" jsonschema:"required,Full UObject path (e.g. /Game/Maps/MyMap.MyMap:PersistentLevel.MyActor)"`
 	PropertyName  string          `json:"property_name" jsonschema:"required,UPROPERTY name (e.g. RelativeLocation, bHidden, StaticMesh)"`
 	PropertyValue json.RawMessage `json:"property_value" jsonschema:"required,Value to set — type depends on property (number, string, object, array)"`
@@ -29,6 +34,8 @@ type SetPropertyOutput struct {
 
 // GetPropertyInput defines parameters for the get_property tool.
 type GetPropertyInput struct {
+	InstanceInput
+
 	ObjectPath   string `json:"object_path" jsonschema:"required,Full UObject path (e.g. /Game/Maps/MyMap.MyMap:PersistentLevel.MyActor)"`
 	PropertyName string `json:"property_name" jsonschema:"required,UPROPERTY name to read (e.g. RelativeLocation, bHidden)"`
 }
@@ -44,6 +51,10 @@ type GetPropertyOutput struct {
 
 // CallFunctionInput defines parameters for the call_function tool.
 type CallFunctionInput struct {
+	InstanceInput
+	TransactionInput
+	DryRunInput
+
 	ObjectPath   string         `json:"object_path" jsonschema:"required,Full UObject path to call the function on"`
 	FunctionName string         `json:"function_name" jsonschema:"required,UFUNCTION name to call"`
 	Parameters   map[string]any `json:"parameters,omitempty" jsonschema:"Function parameters as key-value pairs"`
@@ -84,16 +95,18 @@ func (h *Handler) RegisterProperties(server *mcp.Server) {
 // SetProperty implements the set_property tool.
 func (h *Handler) SetProperty(ctx context.Context, req *mcp.CallToolRequest, input SetPropertyInput) (*mcp.CallToolResult, SetPropertyOutput, error) {
 	if input.ObjectPath == "" {
-		return nil, SetPropertyOutput{}, fmt.Errorf("object_path is required")
+		return nil, SetPropertyOutput{}, toolerr.New(toolerr.Validation, "object_path is required")
 	}
 	if input.PropertyName == "" {
-		return nil, SetPropertyOutput{}, fmt.Errorf("property_name is required")
+		return nil, SetPropertyOutput{}, toolerr.New(toolerr.Validation, "property_name is required")
 	}
 
 	body := map[string]any{
 		"objectPath":    input.ObjectPath,
 		"propertyName":  input.PropertyName,
 		"propertyValue": input.PropertyValue,
+		// Record the write in the editor's undo buffer.
+		"access": "WRITE_TRANSACTION_ACCESS",
 	}
 
 	_, err := h.Client.RCAPICall(ctx, "/remote/object/property", body)
@@ -110,10 +123,10 @@ func (h *Handler) SetProperty(ctx context.Context, req *mcp.CallToolRequest, inp
 // GetProperty implements the get_property tool.
 func (h *Handler) GetProperty(ctx context.Context, req *mcp.CallToolRequest, input GetPropertyInput) (*mcp.CallToolResult, GetPropertyOutput, error) {
 	if input.ObjectPath == "" {
-		return nil, GetPropertyOutput{}, fmt.Errorf("object_path is required")
+		return nil, GetPropertyOutput{}, toolerr.New(toolerr.Validation, "object_path is required")
 	}
 	if input.PropertyName == "" {
-		return nil, GetPropertyOutput{}, fmt.Errorf("property_name is required")
+		return nil, GetPropertyOutput{}, toolerr.New(toolerr.Validation, "property_name is required")
 	}
 
 	body := map[string]any{
@@ -137,15 +150,16 @@ func (h *Handler) GetProperty(ctx context.Context, req *mcp.CallToolRequest, inp
 // CallFunction implements the call_function tool.
 func (h *Handler) CallFunction(ctx context.Context, req *mcp.CallToolRequest, input CallFunctionInput) (*mcp.CallToolResult, CallFunctionOutput, error) {
 	if input.ObjectPath == "" {
-		return nil, CallFunctionOutput{}, fmt.Errorf("object_path is required")
+		return nil, CallFunctionOutput{}, toolerr.New(toolerr.Validation, "object_path is required")
 	}
 	if input.FunctionName == "" {
-		return nil, CallFunctionOutput{}, fmt.Errorf("function_name is required")
+		return nil, CallFunctionOutput{}, toolerr.New(toolerr.Validation, "function_name is required")
 	}
 
 	body := map[string]any{
-		"objectPath":   input.ObjectPath,
-		"functionName": input.FunctionName,
+		"objectPath":          input.ObjectPath,
+		"functionName":        input.FunctionName,
+		"generateTransaction": true,
 	}
 	if input.Parameters != nil {
 		body["parameters"] = input.Parameters
@@ -0,0 +1,267 @@
+// Copyright (c) mcp-unreal project contributors. Apache-2.0 license.
+
+package editor
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/remiphilippe/mcp-unreal/internal/policy"
+)
+
+// RetryPolicy controls how failed editor requests are retried.
+type RetryPolicy struct {
+	// MaxAttempts counts the first try; 1 disables retries.
+	MaxAttempts int
+	// BaseDelay is the backoff before the first retry; each later retry
+	// doubles it up to MaxDelay. The actual sleep is jittered uniformly
+	// between half and all of that.
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+}
+
+// BreakerPolicy controls the per-service circuit breaker.
+type BreakerPolicy struct {
+	// FailureThreshold is the number of consecutive failed calls that
+	// opens the circuit.
+	FailureThreshold int
+	// Cooldown is how long an open circuit fails fast before letting a
+	// single trial request through.
+	Cooldown time.Duration
+}
+
+var (
+	// DefaultRetryPolicy rides out a few seconds of editor unavailability,
+	// e.g. a shader compile or hot reload stalling the game thread.
+	DefaultRetryPolicy = RetryPolicy{MaxAttempts: 4, BaseDelay: 250 * time.Millisecond, MaxDelay: 2 * time.Second}
+
+	// DefaultBreakerPolicy stops hammering an editor that is clearly down.
+	DefaultBreakerPolicy = BreakerPolicy{FailureThreshold: 3, Cooldown: 10 * time.Second}
+)
+
+// Service names used in errors, logs, and breaker state.
+const (
+	pluginService = "MCPUnreal plugin"
+	rcAPIService  = "RC API"
+)
+
+// failureKind classifies a failed attempt for retry and breaker decisions.
+type failureKind int
+
+const (
+	// failNone: the editor answered, possibly with an application error
+	// such as {"error": "Actor not found"}. Never retried.
+	failNone failureKind = iota
+	// failUnsent: the connection could not be established, so the
+	// request never reached the editor. Safe to retry for any call.
+	failUnsent
+	// failTransient: the editor may have received the request but did
+	// not complete it (timeout, reset, 502/503/504, 429). Retried only
+	// for idempotent calls.
+	failTransient
+	// failOther: any other transport error. Not retried or counted.
+	failOther
+)
+
+// classify returns the failure kind of an attempt's transport error or
+// HTTP status.
+func classify(err error, status int) failureKind {
+	if err == nil {
+		switch status {
+		case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+			return failTransient
+		}
+		return failNone
+	}
+	var opErr *net.OpError
+	if errors.As(err, &opErr) && opErr.Op == "dial" {
+		return failUnsent
+	}
+	if errors.Is(err, syscall.ECONNREFUSED) {
+		return failUnsent
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return failTransient
+	}
+	if errors.Is(err, syscall.ECONNRESET) || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return failTransient
+	}
+	return failOther
+}
+
+// retryable reports whether an attempt that failed with kind may be
+// retried for a call of the given idempotency.
+func retryable(kind failureKind, idempotent bool) bool {
+	return kind == failUnsent || (kind == failTransient && idempotent)
+}
+
+// readOnlyEndpoints are plugin endpoints that never change editor state.
+var readOnlyEndpoints = map[string]bool{
+	"/api/status":                true,
+	"/api/actors/list":           true,
+	"/api/actors/components":     true,
+	"/api/assets/search":         true,
+	"/api/assets/info":           true,
+	"/api/assets/dependencies":   true,
+	"/api/assets/referencers":    true,
+	"/api/blueprints/list":       true,
+	"/api/blueprints/inspect":    true,
+	"/api/blueprints/get_graph":  true,
+	"/api/anim_blueprints/query": true,
+	"/api/editor/output_log":     true,
+	"/api/subsystems/query":      true,
+	"/api/ui/query":              true,
+	"/api/network/debug":         true,
+	"/api/events/poll":           true,
+	"/api/transactions/history":  true,
+	"/api/validate":              true,
+}
+
+// idempotent reports whether repeating the request cannot change the
+// outcome: GETs, Remote Control property access, read-only plugin
+// endpoints, and read operations of multiplexed endpoints, as the tool
+// policy names them. Function calls and mutating operations are not
+// retried after they may have reached the editor.
+func idempotent(method, endpoint string, body []byte) bool {
+	switch {
+	case method == http.MethodGet:
+		return true
+	case endpoint == "/remote/object/property":
+		return true
+	case readOnlyEndpoints[endpoint]:
+		return true
+	}
+	var op struct {
+		Operation string `json:"operation"`
+	}
+	if json.Unmarshal(body, &op) != nil || op.Operation == "" {
+		return false
+	}
+	return policy.IsReadOperation(op.Operation)
+}
+
+// backoff returns the jittered delay before retry number n (1-based).
+func (p RetryPolicy) backoff(n int) time.Duration {
+	d := p.BaseDelay << (n - 1)
+	if d > p.MaxDelay || d <= 0 {
+		d = p.MaxDelay
+	}
+	half := d / 2
+	return half + time.Duration(rand.Int64N(int64(half)+1))
+}
+
+// sleepCtx waits for d or until ctx is done.
+func sleepCtx(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
+
+// Circuit breaker states.
+const (
+	breakerClosed   = "closed"
+	breakerOpen     = "open"
+	breakerHalfOpen = "half_open"
+)
+
+// BreakerState is a snapshot of one service's circuit breaker, reported
+// by the status tool.
+type BreakerState struct {
+	Service             string  `json:"service" jsonschema:"editor service: MCPUnreal plugin or RC API"`
+	State               string  `json:"state" jsonschema:"closed (normal), open (failing fast), or half_open (probing)"`
+	ConsecutiveFailures int     `json:"consecutive_failures" jsonschema:"failed calls since the last success"`
+	RetryInSeconds      float64 `json:"retry_in_seconds,omitempty" jsonschema:"seconds until an open circuit lets a trial request through"`
+	LastError           string  `json:"last_error,omitempty" jsonschema:"most recent connection-level error"`
+}
+
+// breaker is a consecutive-failure circuit breaker for one service.
+type breaker struct {
+	policy BreakerPolicy
+	now    func() time.Time
+
+	mu       sync.Mutex
+	state    string
+	failures int
+	openedAt time.Time
+	probing  bool
+	lastErr  string
+}
+
+func newBreaker(policy BreakerPolicy) *breaker {
+	return &breaker{policy: policy, now: time.Now, state: breakerClosed}
+}
+
+// allow reports whether a call may proceed. When the circuit is open it
+// returns false and the time left until a trial request is allowed.
+func (b *breaker) allow() (bool, time.Duration) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	switch b.state {
+	case breakerOpen:
+		if wait := b.policy.Cooldown - b.now().Sub(b.openedAt); wait > 0 {
+			return false, wait
+		}
+		b.state, b.probing = breakerHalfOpen, true
+		return true, 0
+	case breakerHalfOpen:
+		if b.probing {
+			return false, 0
+		}
+		b.probing = true
+		return true, 0
+	default:
+		return true, 0
+	}
+}
+
+// record updates the breaker with the outcome of an allowed call.
+func (b *breaker) record(failed bool, err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.probing = false
+	if !failed {
+		b.state, b.failures = breakerClosed, 0
+		return
+	}
+	b.failures++
+	if err != nil {
+		b.lastErr = err.Error()
+	}
+	if b.state == breakerHalfOpen || b.failures >= b.policy.FailureThreshold {
+		b.state, b.openedAt = breakerOpen, b.now()
+	}
+}
+
+// release ends an allowed call whose outcome says nothing about the
+// service's health.
+func (b *breaker) release() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.probing = false
+}
+
+func (b *breaker) snapshot(service string) BreakerState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	s := BreakerState{Service: service, State: b.state, ConsecutiveFailures: b.failures, LastError: b.lastErr}
+	if b.state == breakerOpen {
+		if wait := b.policy.Cooldown - b.now().Sub(b.openedAt); wait > 0 {
+			s.RetryInSeconds = wait.Round(100 * time.Millisecond).Seconds()
+		}
+	}
+	return s
+}
@@ -0,0 +1,210 @@
+// Copyright (c) mcp-unreal project contributors. Apache-2.0 license.
+
+package editor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestClassify(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		status int
+		want   failureKind
+	}{
+		{"ok", nil, 200, failNone},
+		{"app error status", nil, 400, failNone},
+		{"server error", nil, 500, failNone},
+		{"unavailable", nil, 503, failTransient},
+		{"rate limited", nil, 429, failTransient},
+		{"dial refused", &net.OpError{Op: "dial", Err: errors.New("connection refused")}, 0, failUnsent},
+		{"timeout", &net.DNSError{IsTimeout: true}, 0, failTransient},
+		{"reset", fmt.Errorf("read: %w", io.ErrUnexpectedEOF), 0, failTransient},
+		{"other", errors.New("cassette has no recorded response"), 0, failOther},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := classify(tt.err, tt.status); got != tt.want {
+				t.Errorf("classify = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIdempotent(t *testing.T) {
+	tests := []struct {
+		method, endpoint, body string
+		want                   bool
+	}{
+		{"GET", "/remote/info", "", true},
+		{"PUT", "/remote/object/property", `{"objectPath":"x"}`, true},
+		{"PUT", "/remote/object/call", `{"functionName":"K2_SetActorLocation"}`, false},
+		{"POST", "/api/actors/list", `{}`, true},
+		{"POST", "/api/actors/spawn", `{"class_name":"PointLight"}`, false},
+		{"POST", "/api/levels/ops", `{"operation":"get_current"}`, true},
+		{"POST", "/api/levels/ops", `{"operation":"load_level"}`, false},
+		{"POST", "/api/editor/pie_control", `{"operation":"status"}`, true},
+		{"POST", "/api/editor/pie_control", `{"operation":"start"}`, false},
+	}
+	for _, tt := range tests {
+		if got := idempotent(tt.method, tt.endpoint, []byte(tt.body)); got != tt.want {
+			t.Errorf("idempotent(%s %s %s) = %v, want %v", tt.method, tt.endpoint, tt.body, got, tt.want)
+		}
+	}
+}
+
+// flakyServer returns 503 for the first n requests, then {"ok": true}.
+func flakyServer(t *testing.T, n int32) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+	var hits atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if hits.Add(1) <= n {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			_, _ = w.Write([]byte("compiling shaders"))
+			return
+		}
+		_, _ = w.Write([]byte(`{"ok": true}`))
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &hits
+}
+
+func TestDoRequest_RetriesIdempotentCalls(t *testing.T) {
+	srv, hits := flakyServer(t, 2)
+	client := newTestClient("http://127.0.0.1:1", srv.URL)
+
+	if _, err := client.PluginCall(context.Background(), "/api/actors/list", map[string]any{}); err != nil {
+		t.Fatalf("expected success after retries: %v", err)
+	}
+	if hits.Load() != 3 {
+		t.Errorf("server hit %d times, want 3", hits.Load())
+	}
+	if s := client.BreakerStates()[0]; s.State != breakerClosed || s.ConsecutiveFailures != 0 {
+		t.Errorf("breaker = %+v, want closed", s)
+	}
+}
+
+func TestDoRequest_DoesNotRetryMutations(t *testing.T) {
+	srv, hits := flakyServer(t, 2)
+	client := newTestClient("http://127.0.0.1:1", srv.URL)
+
+	_, err := client.PluginCall(context.Background(), "/api/actors/spawn", map[string]any{"class_name": "PointLight"})
+	if err == nil || !strings.Contains(err.Error(), "HTTP 503") {
+		t.Fatalf("expected HTTP 503, got %v", err)
+	}
+	if hits.Load() != 1 {
+		t.Errorf("mutation sent %d times, want 1", hits.Load())
+	}
+}
+
+func TestDoRequest_RetriesConnectionFailures(t *testing.T) {
+	client := newTestClient("http://127.0.0.1:1", "http://127.0.0.1:1")
+	// Connection refused means the request never arrived, so even a
+	// mutation is retried.
+	_, err := client.PluginCall(context.Background(), "/api/actors/spawn", map[string]any{})
+	if err == nil || !strings.Contains(err.Error(), "unreachable") || !strings.Contains(err.Error(), "after 3 attempts") {
+		t.Errorf("err = %v", err)
+	}
+}
+
+func TestDoRequest_CancelStopsRetries(t *testing.T) {
+	srv, hits := flakyServer(t, 100)
+	client := newTestClient("http://127.0.0.1:1", srv.URL)
+	client.retry = RetryPolicy{MaxAttempts: 10, BaseDelay: time.Hour, MaxDelay: time.Hour}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := client.PluginCall(ctx, "/api/actors/list", nil); err == nil {
+		t.Fatal("expected error")
+	}
+	if hits.Load() != 1 {
+		t.Errorf("server hit %d times, want 1", hits.Load())
+	}
+}
+
+func TestCircuitBreaker(t *testing.T) {
+	srv, hits := flakyServer(t, 3)
+	client := newTestClient("http://127.0.0.1:1", srv.URL)
+	client.retry = RetryPolicy{MaxAttempts: 1}
+	now := time.Now()
+	b := client.breakerFor(pluginService)
+	b.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	for i := 0; i < DefaultBreakerPolicy.FailureThreshold; i++ {
+		if _, err := client.PluginCall(ctx, "/api/actors/list", nil); err == nil {
+			t.Fatalf("call %d: expected failure", i)
+		}
+	}
+	state := client.BreakerStates()[0]
+	if state.State != breakerOpen || state.ConsecutiveFailures != 3 || state.RetryInSeconds != 10 || state.LastError != "HTTP 503" {
+		t.Errorf("breaker after failures = %+v", state)
+	}
+
+	// Open: fail fast without touching the editor.
+	_, err := client.PluginCall(ctx, "/api/actors/list", nil)
+	if err == nil || !strings.Contains(err.Error(), "circuit open") {
+		t.Errorf("expected fast failure, got %v", err)
+	}
+	if hits.Load() != 3 {
+		t.Errorf("open circuit let a request through (%d hits)", hits.Load())
+	}
+
+	// The RC API has its own breaker.
+	if rc := client.BreakerStates()[1]; rc.Service != rcAPIService || rc.State != breakerClosed {
+		t.Errorf("RC API breaker = %+v", rc)
+	}
+
+	// After the cooldown a trial request goes through and closes it.
+	now = now.Add(DefaultBreakerPolicy.Cooldown)
+	if _, err := client.PluginCall(ctx, "/api/actors/list", nil); err != nil {
+		t.Fatalf("trial request: %v", err)
+	}
+	if s := client.BreakerStates()[0]; s.State != breakerClosed || s.ConsecutiveFailures != 0 {
+		t.Errorf("breaker after recovery = %+v", s)
+	}
+}
+
+func TestCircuitBreaker_HalfOpenFailureReopens(t *testing.T) {
+	b := newBreaker(BreakerPolicy{FailureThreshold: 1, Cooldown: time.Second})
+	now := time.Now()
+	b.now = func() time.Time { return now }
+
+	b.record(true, errors.New("refused"))
+	if ok, _ := b.allow(); ok {
+		t.Fatal("open breaker allowed a call")
+	}
+	now = now.Add(time.Second)
+	if ok, _ := b.allow(); !ok {
+		t.Fatal("expected a trial call after cooldown")
+	}
+	if ok, _ := b.allow(); ok {
+		t.Error("only one trial call may be in flight")
+	}
+	b.record(true, errors.New("refused"))
+	if s := b.snapshot("x"); s.State != breakerOpen {
+		t.Errorf("failed trial should reopen, got %+v", s)
+	}
+}
+
+func TestBackoffJitter(t *testing.T) {
+	p := RetryPolicy{MaxAttempts: 5, BaseDelay: 100 * time.Millisecond, MaxDelay: 300 * time.Millisecond}
+	for n, max := range map[int]time.Duration{1: 100 * time.Millisecond, 2: 200 * time.Millisecond, 3: 300 * time.Millisecond, 6: 300 * time.Millisecond} {
+		for i := 0; i < 20; i++ {
+			if d := p.backoff(n); d < max/2 || d > max {
+				t.Errorf("backoff(%d) = %v, want in [%v, %v]", n, d, max/2, max)
+			}
+		}
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"fmt"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/remiphilippe/mcp-unreal/internal/toolerr"
 )
 
 // --- subsystem_query ---
@@ -21,6 +22,8 @@ type SubsystemInfo struct {
 
 // SubsystemQueryInput defines parameters for the subsystem_query tool.
 type SubsystemQueryInput struct {
+	InstanceInput
+
 	Type  string `json:"type" jsonschema:"required,Subsystem type to query: world, game_instance, engine, editor, local_player, or all"`
 	World string `json:"world,omitempty" jsonschema:"Target world: auto (default, PIE if active else editor), pie (error if not running), editor (always editor)"`
 }
@@ -48,7 +51,7 @@ func (h *Handler) RegisterSubsystems(server *mcp.Server) {
 // SubsystemQuery implements the subsystem_query tool.
 func (h *Handler) SubsystemQuery(ctx context.Context, req *mcp.CallToolRequest, input SubsystemQueryInput) (*mcp.CallToolResult, SubsystemQueryOutput, error) {
 	if input.Type == "" {
-		return nil, SubsystemQueryOutput{}, fmt.Errorf("type is required (world, game_instance, engine, editor, local_player, or all)")
+		return nil, SubsystemQueryOutput{}, toolerr.New(toolerr.Validation, "type is required (world, game_instance, engine, editor, local_player, or all)")
 	}
 
 	body := map[string]any{
@@ -8,6 +8,7 @@ import (
 	"fmt"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/remiphilippe/mcp-unreal/internal/toolerr"
 )
 
 // --- texture_ops ---
@@ -26,6 +27,10 @@ type TextureInfo struct {
 
 // TextureOpsInput defines parameters for the texture_ops tool.
 type TextureOpsInput struct {
+	InstanceInput
+	TransactionInput
+	DryRunInput
+
 	Operation string `json:"operation" jsonschema:"required,Operation: import, get_info, set_material_texture, list"`
 	// For import: source file path and destination asset path.
 	SourcePath  string `json:"source_path,omitempty" jsonschema:"Local file path to import (PNG, TIFF, EXR, JPG). For import."`
@@ -66,14 +71,18 @@ func (h *Handler) RegisterTextures(server *mcp.Server) {
 // TextureOps implements the texture_ops tool.
 func (h *Handler) TextureOps(ctx context.Context, req *mcp.CallToolRequest, input TextureOpsInput) (*mcp.CallToolResult, TextureOpsOutput, error) {
 	if input.Operation == "" {
-		return nil, TextureOpsOutput{}, fmt.Errorf("operation is required")
+		return nil, TextureOpsOutput{}, toolerr.New(toolerr.Validation, "operation is required")
 	}
 
 	body := map[string]any{
 		"operation": input.Operation,
 	}
 	if input.SourcePath != "" {
-		body["source_path"] = input.SourcePath
+		path, err := h.resolvePath("source_path", input.SourcePath)
+		if err != nil {
+			return nil, TextureOpsOutput{}, err
+		}
+		body["source_path"] = path
 	}
 	if input.Destination != "" {
 		body["destination"] = input.Destination
@@ -7,6 +7,7 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"path/filepath"
 	"strings"
 	"testing"
 
@@ -25,6 +26,8 @@ func newTextureTestHandler(ts *httptest.Server) *Handler {
 }
 
 func TestTextureOps_Import(t *testing.T) {
+	paths, dir := testPaths(t)
+	sourcePath := filepath.Join(dir, "terrain.png")
 	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if r.URL.Path != "/api/textures/ops" {
 			t.Errorf("unexpected path: %s", r.URL.Path)
@@ -34,7 +37,7 @@ func TestTextureOps_Import(t *testing.T) {
 		if body["operation"] != "import" {
 			t.Errorf("expected import, got %v", body["operation"])
 		}
-		if body["source_path"] != "/tmp/terrain.png" {
+		if body["source_path"] != sourcePath {
 			t.Errorf("expected source_path, got %v", body["source_path"])
 		}
 		if body["compression"] != "TC_Default" {
@@ -50,9 +53,10 @@ func TestTextureOps_Import(t *testing.T) {
 	defer ts.Close()
 
 	h := newTextureTestHandler(ts)
+	h.Paths = paths
 	_, out, err := h.TextureOps(context.Background(), &mcp.CallToolRequest{}, TextureOpsInput{
 		Operation:   "import",
-		SourcePath:  "/tmp/terrain.png",
+		SourcePath:  sourcePath,
 		Destination: "/Game/Textures/T_Terrain",
 		Compression: "TC_Default",
 	})
@@ -0,0 +1,276 @@
+// Copyright (c) mcp-unreal project contributors. Apache-2.0 license.
+
+package editor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/remiphilippe/mcp-unreal/internal/toolerr"
+)
+
+// Plugin routes for editor undo transactions.
+const (
+	transactionBeginRoute   = "/api/transactions/begin"
+	transactionEndRoute     = "/api/transactions/end"
+	transactionUndoRoute    = "/api/transactions/undo"
+	transactionRedoRoute    = "/api/transactions/redo"
+	transactionHistoryRoute = "/api/transactions/history"
+)
+
+// maxUndoSteps bounds undo and redo counts, as the plugin does.
+const maxUndoSteps = 100
+
+// TransactionInput is embedded in the input of every editor tool that
+// changes editor state. TransactionMiddleware reads it from the raw
+// arguments and wraps the call, so handlers do not use it directly.
+type TransactionInput struct {
+	Transaction string `json:"transaction,omitempty" jsonschema:"Undo transaction label. The call's changes are recorded as one undo step under this label, so undo(label=...) reverts them; consecutive calls with the same label are undone together. Inside transaction_begin/transaction_end the changes join the open transaction"`
+}
+
+// TransactionMiddleware wraps tools/call requests that carry a
+// "transaction" argument in an editor transaction of that name: it opens
+// one on the target instance before the tool runs and closes it after,
+// even if the tool fails. Inside an open transaction_begin the call's
+// transaction nests and folds into the outer one. Dry runs commit
+// nothing and are not wrapped. Install it with
+// server.AddReceivingMiddleware after InstanceMiddleware, so it reaches
+// the instance the call is routed to.
+func (c *Client) TransactionMiddleware(next mcp.MethodHandler) mcp.MethodHandler {
+	return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+		call, ok := req.(*mcp.CallToolRequest)
+		if !ok || call.Params == nil || len(call.Params.Arguments) == 0 {
+			return next(ctx, method, req)
+		}
+		var args struct {
+			Transaction string `json:"transaction"`
+			DryRun      bool   `json:"dry_run"`
+		}
+		if json.Unmarshal(call.Params.Arguments, &args) != nil || args.Transaction == "" || args.DryRun {
+			return next(ctx, method, req)
+		}
+
+		if _, err := c.PluginCall(ctx, transactionBeginRoute, map[string]any{"label": args.Transaction}); err != nil {
+			res := &mcp.CallToolResult{}
+			res.SetError(fmt.Errorf("opening transaction %q: %w", args.Transaction, err))
+			return res, nil
+		}
+		res, err := next(ctx, method, req)
+		// Close the transaction even if the client went away, so the
+		// editor is not left recording.
+		if _, endErr := c.PluginCall(context.WithoutCancel(ctx), transactionEndRoute, map[string]any{}); endErr != nil {
+			c.logger.Warn("closing editor transaction failed", "transaction", args.Transaction, "error", endErr)
+		}
+		return res, err
+	}
+}
+
+// --- transaction_begin / transaction_end ---
+
+// TransactionBeginInput defines parameters for the transaction_begin tool.
+type TransactionBeginInput struct {
+	InstanceInput
+
+	Label string `json:"label" jsonschema:"required,Name of the transaction as shown in the editor's undo history (e.g. Build courtyard)"`
+}
+
+// TransactionState describes the transaction open through the API.
+type TransactionState struct {
+	Label string `json:"label,omitempty" jsonschema:"label of the outermost open transaction"`
+	Depth int    `json:"depth" jsonschema:"nesting depth; 0 means no transaction is open"`
+}
+
+// TransactionEndInput defines parameters for the transaction_end tool.
+type TransactionEndInput struct {
+	InstanceInput
+
+	Revert bool `json:"revert,omitempty" jsonschema:"Undo the transaction's changes right after closing it, to abandon a failed multi-step edit"`
+}
+
+// TransactionEndOutput is returned by the transaction_end tool.
+type TransactionEndOutput struct {
+	TransactionState
+	Recorded bool `json:"recorded,omitempty" jsonschema:"true when the outermost transaction closed with changes and is now in the undo history"`
+	Reverted bool `json:"reverted,omitempty" jsonschema:"true when revert undid the transaction"`
+}
+
+// --- undo / redo ---
+
+// UndoInput defines parameters for the undo tool.
+type UndoInput struct {
+	InstanceInput
+
+	Count int    `json:"count,omitempty" jsonschema:"Number of undo steps (default 1, max 100). Ignored when label is set"`
+	Label string `json:"label,omitempty" jsonschema:"Undo every consecutive newest step with this transaction label, e.g. the label passed to several mutating calls. Fails if the newest step has another label"`
+}
+
+// RedoInput defines parameters for the redo tool.
+type RedoInput struct {
+	InstanceInput
+
+	Count int `json:"count,omitempty" jsonschema:"Number of redo steps (default 1, max 100)"`
+}
+
+// UndoOutput is returned by the undo and redo tools.
+type UndoOutput struct {
+	Transactions []string `json:"transactions" jsonschema:"labels of the steps undone or redone, newest first for undo"`
+	CanUndo      bool     `json:"can_undo" jsonschema:"whether another undo is possible"`
+	CanRedo      bool     `json:"can_redo" jsonschema:"whether another redo is possible"`
+}
+
+// --- undo_history ---
+
+// UndoHistoryInput defines parameters for the undo_history tool.
+type UndoHistoryInput struct {
+	InstanceInput
+
+	Limit int `json:"limit,omitempty" jsonschema:"Maximum entries to return, newest first (default 20)"`
+}
+
+// UndoHistoryEntry is one step of the editor's undo history.
+type UndoHistoryEntry struct {
+	Index  int    `json:"index" jsonschema:"position in the undo buffer, 0 = oldest"`
+	Label  string `json:"label" jsonschema:"transaction label"`
+	Undone bool   `json:"undone" jsonschema:"true if the step has been undone and can be redone"`
+}
+
+// UndoHistoryOutput is returned by the undo_history tool.
+type UndoHistoryOutput struct {
+	Entries []UndoHistoryEntry `json:"entries" jsonschema:"undo history, newest first"`
+	Total   int                `json:"total" jsonschema:"number of steps in the undo buffer"`
+	CanUndo bool               `json:"can_undo" jsonschema:"whether undo is possible"`
+	CanRedo bool               `json:"can_redo" jsonschema:"whether redo is possible"`
+	Open    *TransactionState  `json:"open,omitempty" jsonschema:"the transaction open through transaction_begin, if any"`
+}
+
+// RegisterTransactions adds the transaction and undo tools to the MCP
+// server.
+func (h *Handler) RegisterTransactions(server *mcp.Server) {
+	mcp.AddTool(server, &mcp.Tool{
+		Name: "transaction_begin",
+		Description: "Open a named editor undo transaction. Every change made by editor tools until " +
+			"transaction_end is recorded as a single undo step, so undo reverts the whole multi-step edit. " +
+			"Nested begins fold into the outermost transaction. Undo and redo are refused while a " +
+			"transaction is open. Requires the editor running with MCPUnreal plugin (port 8090).",
+	}, h.TransactionBegin)
+
+	mcp.AddTool(server, &mcp.Tool{
+		Name: "transaction_end",
+		Description: "Close the transaction opened by transaction_begin. Pass revert=true to undo its " +
+			"changes at once, e.g. after a step of the edit failed. Returns whether the transaction " +
+			"recorded any changes.",
+	}, h.TransactionEnd)
+
+	mcp.AddTool(server, &mcp.Tool{
+		Name: "undo",
+		Description: "Undo editor changes, like Ctrl+Z. Undoes count steps (default 1), or with label, " +
+			"every consecutive newest step recorded under that transaction label. Changes are recorded " +
+			"by transaction_begin/transaction_end, by the transaction argument of mutating tools, and by " +
+			"the user's own edits. Returns the labels undone.",
+	}, h.Undo)
+
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "redo",
+		Description: "Redo editor changes undone by undo, like Ctrl+Y. Returns the labels redone.",
+	}, h.Redo)
+
+	mcp.AddTool(server, &mcp.Tool{
+		Name: "undo_history",
+		Description: "List the editor's undo history, newest first, with each step's label and whether " +
+			"it has been undone, plus the transaction currently open through transaction_begin.",
+	}, h.UndoHistory)
+}
+
+// TransactionBegin implements the transaction_begin tool.
+func (h *Handler) TransactionBegin(ctx context.Context, req *mcp.CallToolRequest, input TransactionBeginInput) (*mcp.CallToolResult, TransactionState, error) {
+	if input.Label == "" {
+		return nil, TransactionState{}, toolerr.New(toolerr.Validation, "label is required")
+	}
+	var out TransactionState
+	if err := h.transactionCall(ctx, transactionBeginRoute, map[string]any{"label": input.Label}, &out); err != nil {
+		return nil, TransactionState{}, fmt.Errorf("opening transaction %q: %w", input.Label, err)
+	}
+	return nil, out, nil
+}
+
+// TransactionEnd implements the transaction_end tool.
+func (h *Handler) TransactionEnd(ctx context.Context, req *mcp.CallToolRequest, input TransactionEndInput) (*mcp.CallToolResult, TransactionEndOutput, error) {
+	var out TransactionEndOutput
+	if err := h.transactionCall(ctx, transactionEndRoute, map[string]any{"revert": input.Revert}, &out); err != nil {
+		return nil, TransactionEndOutput{}, fmt.Errorf("closing transaction: %w", err)
+	}
+	return nil, out, nil
+}
+
+// Undo implements the undo tool.
+func (h *Handler) Undo(ctx context.Context, req *mcp.CallToolRequest, input UndoInput) (*mcp.CallToolResult, UndoOutput, error) {
+	count, err := undoCount(input.Count)
+	if err != nil {
+		return nil, UndoOutput{}, err
+	}
+	body := map[string]any{"count": count}
+	if input.Label != "" {
+		body["label"] = input.Label
+	}
+	var out UndoOutput
+	if err := h.transactionCall(ctx, transactionUndoRoute, body, &out); err != nil {
+		return nil, UndoOutput{}, fmt.Errorf("undoing: %w", err)
+	}
+	return nil, out, nil
+}
+
+// Redo implements the redo tool.
+func (h *Handler) Redo(ctx context.Context, req *mcp.CallToolRequest, input RedoInput) (*mcp.CallToolResult, UndoOutput, error) {
+	count, err := undoCount(input.Count)
+	if err != nil {
+		return nil, UndoOutput{}, err
+	}
+	var out UndoOutput
+	if err := h.transactionCall(ctx, transactionRedoRoute, map[string]any{"count": count}, &out); err != nil {
+		return nil, UndoOutput{}, fmt.Errorf("redoing: %w", err)
+	}
+	return nil, out, nil
+}
+
+// UndoHistory implements the undo_history tool.
+func (h *Handler) UndoHistory(ctx context.Context, req *mcp.CallToolRequest, input UndoHistoryInput) (*mcp.CallToolResult, UndoHistoryOutput, error) {
+	limit := input.Limit
+	if limit <= 0 {
+		limit = 20
+	}
+	var out UndoHistoryOutput
+	if err := h.transactionCall(ctx, transactionHistoryRoute, map[string]any{"limit": limit}, &out); err != nil {
+		return nil, UndoHistoryOutput{}, fmt.Errorf("reading undo history: %w", err)
+	}
+	if out.Entries == nil {
+		out.Entries = []UndoHistoryEntry{}
+	}
+	return nil, out, nil
+}
+
+// transactionCall sends body to a transaction route and decodes the
+// response into out.
+func (h *Handler) transactionCall(ctx context.Context, route string, body map[string]any, out any) error {
+	resp, err := h.Client.PluginCall(ctx, route, body)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(resp, out); err != nil {
+		return fmt.Errorf("parsing %s response: %w", route, err)
+	}
+	return nil
+}
+
+// undoCount validates an undo or redo count, defaulting to 1.
+func undoCount(n int) (int, error) {
+	switch {
+	case n == 0:
+		return 1, nil
+	case n < 0 || n > maxUndoSteps:
+		return 0, toolerr.New(toolerr.Validation, "count must be between 1 and %d", maxUndoSteps)
+	default:
+		return n, nil
+	}
+}
@@ -0,0 +1,105 @@
+// Copyright (c) mcp-unreal project contributors. Apache-2.0 license.
+
+package editor
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/remiphilippe/mcp-unreal/internal/config"
+)
+
+func TestUndoCount(t *testing.T) {
+	tests := []struct {
+		in      int
+		want    int
+		wantErr bool
+	}{
+		{0, 1, false},
+		{1, 1, false},
+		{100, 100, false},
+		{-1, 0, true},
+		{101, 0, true},
+	}
+	for _, tt := range tests {
+		got, err := undoCount(tt.in)
+		if got != tt.want || (err != nil) != tt.wantErr {
+			t.Errorf("undoCount(%d) = (%d, %v), want %d, error %v", tt.in, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestTransactionMiddleware(t *testing.T) {
+	fake, pluginPort, rcPort := startFake(t)
+	client := NewClient(&config.Config{PluginPort: pluginPort, RCAPIPort: rcPort}, testLogger())
+	h := &Handler{Client: client, Logger: testLogger()}
+	server := mcp.NewServer(&mcp.Implementation{Name: "test"}, nil)
+	server.AddReceivingMiddleware(InstanceMiddleware, client.TransactionMiddleware)
+	h.RegisterActors(server)
+	h.RegisterProperties(server)
+	h.RegisterTransactions(server)
+
+	ctx := context.Background()
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	ss, err := server.Connect(ctx, serverTransport, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = ss.Close() }()
+	session, err := mcp.NewClient(&mcp.Implementation{Name: "test-client"}, nil).Connect(ctx, clientTransport, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = session.Close() }()
+
+	call := func(name string, args map[string]any) *mcp.CallToolResult {
+		t.Helper()
+		res, err := session.CallTool(ctx, &mcp.CallToolParams{Name: name, Arguments: args})
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		return res
+	}
+
+	// Two labelled calls are two undo steps with the same label; a
+	// failing labelled call records nothing.
+	for _, name := range []string{"Lamp1", "Lamp2"} {
+		if res := call("spawn_actor", map[string]any{"class_name": "PointLight", "name": name, "transaction": "Lights"}); res.IsError {
+			t.Fatalf("spawn_actor %s: %+v", name, res.Content)
+		}
+	}
+	if res := call("move_actor", map[string]any{"object_path": "/Game/Maps/Main.Main:PersistentLevel.Nope", "location": []float64{1, 2, 3}, "transaction": "Lights"}); !res.IsError {
+		t.Error("move_actor on a missing actor succeeded")
+	}
+
+	res := call("undo", map[string]any{"label": "Lights"})
+	if res.IsError {
+		t.Fatalf("undo: %+v", res.Content)
+	}
+	var out UndoOutput
+	raw, _ := json.Marshal(res.StructuredContent)
+	if err := json.Unmarshal(raw, &out); err != nil {
+		t.Fatal(err)
+	}
+	if len(out.Transactions) != 2 || out.CanUndo {
+		t.Errorf("undo = %+v, want both Lights steps and nothing left", out)
+	}
+	for _, name := range []string{"Lamp1", "Lamp2"} {
+		if _, ok := fake.Actor(name); ok {
+			t.Errorf("%s still present after undo", name)
+		}
+	}
+
+	// The transaction is closed after every call.
+	res = call("undo_history", map[string]any{})
+	raw, _ = json.Marshal(res.StructuredContent)
+	if res.IsError || strings.Contains(string(raw), `"open"`) {
+		t.Errorf("undo_history = %s", raw)
+	}
+	if res := call("undo", map[string]any{"label": "Lights"}); !res.IsError {
+		t.Error("undo with a label that is not the newest step succeeded")
+	}
+}
@@ -0,0 +1,84 @@
+// Copyright (c) mcp-unreal project contributors. Apache-2.0 license.
+
+package editor
+
+import (
+	"net/http"
+	"net/url"
+	"sync"
+
+	"github.com/remiphilippe/mcp-unreal/internal/config"
+)
+
+// NewTransport returns the round tripper for editor requests: TLS with
+// the configured CA for https endpoints, and the plugin auth token on
+// requests to the plugin host. The status tool uses it for its pings.
+func NewTransport(cfg *config.Config) (http.RoundTripper, error) {
+	tlsConfig, err := cfg.EditorTLSConfig()
+	if err != nil {
+		return nil, err
+	}
+	base := http.DefaultTransport.(*http.Transport).Clone()
+	if tlsConfig != nil {
+		base.TLSClientConfig = tlsConfig
+	}
+	if cfg.EditorToken == "" {
+		return base, nil
+	}
+	plugin, err := url.Parse(cfg.PluginURL())
+	if err != nil {
+		return nil, err
+	}
+	t := &tokenTransport{base: base, token: cfg.EditorToken}
+	t.allow(plugin.String())
+	return t, nil
+}
+
+// tokenTransport adds the plugin auth token to requests for the plugin
+// endpoints it was told about — the configured plugin, named and
+// discovered instances, and discovery probes — so the token never
+// reaches the RC API, which shares the host on another port, or any
+// other server.
+type tokenTransport struct {
+	base  http.RoundTripper
+	token string
+
+	mu    sync.RWMutex
+	hosts map[string]bool
+}
+
+// allow adds the host and port of the plugin at baseURL. It is a no-op
+// on a nil transport, i.e. without a token.
+func (t *tokenTransport) allow(baseURL string) {
+	if t == nil {
+		return
+	}
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.hosts == nil {
+		t.hosts = make(map[string]bool)
+	}
+	t.hosts[u.Host] = true
+}
+
+func (t *tokenTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.mu.RLock()
+	ok := t.hosts[req.URL.Host]
+	t.mu.RUnlock()
+	if !ok {
+		return t.base.RoundTrip(req)
+	}
+	req = req.Clone(req.Context())
+	req.Header.Set("Authorization", "Bearer "+t.token)
+	return t.base.RoundTrip(req)
+}
+
+// errTransport fails every request with err. NewClient uses it when the
+// TLS settings cannot be loaded, so tools report the problem.
+type errTransport struct{ err error }
+
+func (t errTransport) RoundTrip(*http.Request) (*http.Response, error) { return nil, t.err }
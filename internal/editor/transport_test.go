@@ -0,0 +1,118 @@
+// Copyright (c) mcp-unreal project contributors. Apache-2.0 license.
+
+package editor
+
+import (
+	"context"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/remiphilippe/mcp-unreal/internal/config"
+	"github.com/remiphilippe/mcp-unreal/internal/editor/editortest"
+)
+
+// endpoint splits a test server URL into config fields.
+func endpoint(t *testing.T, raw string) (scheme, host string, port int) {
+	t.Helper()
+	u, err := url.Parse(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	port, err = strconv.Atoi(u.Port())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return u.Scheme, u.Hostname(), port
+}
+
+func TestNewClient_TLSWithCustomCA(t *testing.T) {
+	fake := editortest.New(nil)
+	plugin := httptest.NewTLSServer(fake.PluginHandler())
+	defer plugin.Close()
+
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: plugin.Certificate().Raw})
+	if err := os.WriteFile(caFile, certPEM, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := &config.Config{RCAPIPort: 1}
+	cfg.PluginScheme, cfg.PluginHost, cfg.PluginPort = endpoint(t, plugin.URL)
+	ctx := context.Background()
+
+	// Without the CA the self-signed certificate is rejected.
+	if _, err := NewClient(cfg, testLogger()).PluginCall(ctx, "/api/actors/list", nil); err == nil || !strings.Contains(err.Error(), "certificate") {
+		t.Errorf("expected certificate error without CA, got %v", err)
+	}
+
+	cfg.EditorCAFile = caFile
+	if _, err := NewClient(cfg, testLogger()).PluginCall(ctx, "/api/actors/list", nil); err != nil {
+		t.Errorf("PluginCall over TLS: %v", err)
+	}
+}
+
+func TestNewClient_InvalidCAFailsRequests(t *testing.T) {
+	cfg := &config.Config{RCAPIPort: 1, PluginPort: 1, EditorCAFile: filepath.Join(t.TempDir(), "missing.pem")}
+	_, err := NewClient(cfg, testLogger()).PluginCall(context.Background(), "/api/actors/list", nil)
+	if err == nil || !strings.Contains(err.Error(), "reading editor CA file") {
+		t.Errorf("expected CA error, got %v", err)
+	}
+}
+
+func TestNewClient_AuthToken(t *testing.T) {
+	fake := editortest.New(nil)
+	fake.RequireToken("s3cret")
+	plugin := httptest.NewServer(fake.PluginHandler())
+	defer plugin.Close()
+
+	// The RC API must never see the token.
+	var rcAuth []string
+	rc := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rcAuth = append(rcAuth, r.Header.Get("Authorization"))
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer rc.Close()
+
+	cfg := &config.Config{}
+	cfg.PluginScheme, cfg.PluginHost, cfg.PluginPort = endpoint(t, plugin.URL)
+	cfg.RCAPIScheme, cfg.RCAPIHost, cfg.RCAPIPort = endpoint(t, rc.URL)
+	ctx := context.Background()
+
+	tests := []struct {
+		name    string
+		token   string
+		wantErr string
+	}{
+		{"no token", "", "HTTP 401"},
+		{"wrong token", "guess", "HTTP 401"},
+		{"right token", "s3cret", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg.EditorToken = tt.token
+			client := NewClient(cfg, testLogger())
+			_, err := client.PluginCall(ctx, "/api/actors/list", nil)
+			if tt.wantErr == "" && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Errorf("error = %v, want %q", err, tt.wantErr)
+			}
+			if _, err := client.RCAPICall(ctx, "/remote/object/property", map[string]any{}); err != nil {
+				t.Errorf("RCAPICall: %v", err)
+			}
+		})
+	}
+	for _, got := range rcAuth {
+		if got != "" {
+			t.Errorf("RC API received Authorization %q", got)
+		}
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"fmt"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/remiphilippe/mcp-unreal/internal/toolerr"
 )
 
 // --- ui_query ---
@@ -35,6 +36,9 @@ type WidgetBounds struct {
 
 // UIQueryInput defines parameters for the ui_query tool.
 type UIQueryInput struct {
+	InstanceInput
+	PageInput
+
 	Operation string `json:"operation" jsonschema:"required,Operation: tree, find, get_widget, umg_list"`
 	// For find: widget class filter.
 	Class string `json:"class,omitempty" jsonschema:"Widget class name to search for (e.g. SCheckBox). For find."`
@@ -50,11 +54,12 @@ type UIQueryOutput struct {
 	Widgets []WidgetInfo `json:"widgets,omitempty" jsonschema:"widget tree or search results"`
 	Widget  *WidgetInfo  `json:"widget,omitempty" jsonschema:"single widget (for get_widget)"`
 	Count   int          `json:"count,omitempty" jsonschema:"number of widgets found"`
+	Page    *PageInfo    `json:"page,omitempty" jsonschema:"for tree and find: which top-level widgets were returned and the cursor for the next page"`
 }
 
 // RegisterUIQuery adds the ui_query tool to the MCP server.
 func (h *Handler) RegisterUIQuery(server *mcp.Server) {
-	mcp.AddTool(server, &mcp.Tool{
+	mcp.AddTool(server, pagedTool[UIQueryOutput](&mcp.Tool{
 		Name: "ui_query",
 		Description: "Introspect Slate and UMG widget hierarchy. " +
 			"Operations: tree (full widget tree with visibility/bounds), " +
@@ -62,14 +67,15 @@ func (h *Handler) RegisterUIQuery(server *mcp.Server) {
 			"get_widget (get details of a specific widget by path), " +
 			"umg_list (list UMG UserWidget instances). " +
 			"Supports max_depth to limit tree traversal. " +
-			"Requires the editor running with the MCPUnreal plugin loaded (port 8090).",
-	}, h.UIQuery)
+			"Requires the editor running with the MCPUnreal plugin loaded (port 8090). " +
+			"tree and find are paged by top-level widget, and fields apply at every depth: " + pagedDescription,
+	}, "widgets"), h.UIQuery)
 }
 
 // UIQuery implements the ui_query tool.
 func (h *Handler) UIQuery(ctx context.Context, req *mcp.CallToolRequest, input UIQueryInput) (*mcp.CallToolResult, UIQueryOutput, error) {
 	if input.Operation == "" {
-		return nil, UIQueryOutput{}, fmt.Errorf("operation is required")
+		return nil, UIQueryOutput{}, toolerr.New(toolerr.Validation, "operation is required")
 	}
 
 	body := map[string]any{
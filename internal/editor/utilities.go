@@ -8,12 +8,15 @@ import (
 	"fmt"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/remiphilippe/mcp-unreal/internal/toolerr"
 )
 
 // --- run_console_command ---
 
 // RunConsoleCommandInput defines parameters for the run_console_command tool.
 type RunConsoleCommandInput struct {
+	InstanceInput
+
 	Command string `json:"command" jsonschema:"required,UE console command to execute (e.g. stat fps, obj list, ShowFlag.Collision 1)"`
 	World   string `json:"world,omitempty" jsonschema:"Target world: auto (default, PIE if active else editor), pie (error if not running), editor (always editor)"`
 }
@@ -42,7 +45,7 @@ func (h *Handler) RegisterUtilities(server *mcp.Server) {
 // falls back to the RC API via KismetSystemLibrary::ExecuteConsoleCommand.
 func (h *Handler) RunConsoleCommand(ctx context.Context, req *mcp.CallToolRequest, input RunConsoleCommandInput) (*mcp.CallToolResult, RunConsoleCommandOutput, error) {
 	if input.Command == "" {
-		return nil, RunConsoleCommandOutput{}, fmt.Errorf("command is required")
+		return nil, RunConsoleCommandOutput{}, toolerr.New(toolerr.Validation, "command is required")
 	}
 
 	h.Logger.Info("executing console command", "command", input.Command)
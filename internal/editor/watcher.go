@@ -0,0 +1,237 @@
+// Copyright (c) mcp-unreal project contributors. Apache-2.0 license.
+
+package editor
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// DefaultToolRefreshInterval is how often the ToolWatcher polls the editor.
+const DefaultToolRefreshInterval = 5 * time.Second
+
+// ToolGroup is a set of editor tools registered together and the editor
+// capability they need.
+type ToolGroup struct {
+	Name     string
+	Register func(*mcp.Server)
+
+	// Feature names the entry of PluginFeatures the tools need. Empty
+	// means the tools only need the Remote Control API.
+	Feature string
+}
+
+// ToolGroups returns every editor tool group in registration order.
+// See IMPLEMENTATION.md §9 for the phase each group shipped in.
+func (h *Handler) ToolGroups() []ToolGroup {
+	return []ToolGroup{
+		// Phase 4: Editor communication tools (IMPLEMENTATION.md §3.3–§3.11).
+		{"properties", h.RegisterProperties, ""},
+		{"actors", h.RegisterActors, "actors"},
+		{"utilities", h.RegisterUtilities, "console"},
+
+		// Phase 6: Blueprint & Animation Blueprint tools.
+		{"blueprints", h.RegisterBlueprints, "blueprints"},
+		{"anim_blueprints", h.RegisterAnimBlueprints, "anim_blueprints"},
+
+		// Phase 7: Assets, materials, characters, input, levels, editor utilities.
+		{"assets", h.RegisterAssets, "assets"},
+		{"materials", h.RegisterMaterials, "materials"},
+		{"characters", h.RegisterCharacters, "characters"},
+		{"input", h.RegisterInput, "input"},
+		{"levels", h.RegisterLevels, "levels"},
+		{"editor_utils", h.RegisterEditorUtils, "pie_control"},
+
+		// Phase 8: Mesh tools.
+		{"mesh", h.RegisterMesh, "mesh"},
+
+		// Phase 10+: components (#40), ISM (#41), Fab (#42), textures (#44),
+		// subsystems (#45), data assets (#46), UI (#47), network (#48).
+		{"components", h.RegisterComponents, "components"},
+		{"ism", h.RegisterISM, "ism"},
+		{"fab", h.RegisterFab, "fab"},
+		{"textures", h.RegisterTextures, "textures"},
+		{"subsystems", h.RegisterSubsystems, "subsystems"},
+		{"data_assets", h.RegisterDataAssets, "data_assets"},
+		{"ui_query", h.RegisterUIQuery, "ui_query"},
+		{"network_debug", h.RegisterNetworkDebug, "network_debug"},
+
+		// Phase 10: PCG, GAS, Niagara tools.
+		{"pcg", h.RegisterPCG, "pcg"},
+		{"gas", h.RegisterGAS, "gas"},
+		{"niagara", h.RegisterNiagara, "niagara"},
+
+		// Editor event stream.
+		{"events", h.RegisterEvents, "events"},
+
+		// Undo transactions.
+		{"transactions", h.RegisterTransactions, "transactions"},
+	}
+}
+
+// ToolWatcher keeps the editor tools registered on an MCP server in step
+// with the running editors: a group is added when the service it needs
+// is reachable on any known editor instance and, for plugin tools, that
+// instance's plugin serves its route, and removed when no instance
+// does. Calls to an instance that lacks a group's route are then
+// rejected by the per-call route check. The server notifies clients with
+// notifications/tools/list_changed on every change.
+type ToolWatcher struct {
+	client *Client
+	server *mcp.Server
+	logger *slog.Logger
+	groups []*watchedGroup
+}
+
+type watchedGroup struct {
+	ToolGroup
+	tools      []string
+	registered bool
+}
+
+// NewToolWatcher prepares a watcher for groups. No tools are registered
+// until the first Sync.
+func NewToolWatcher(client *Client, server *mcp.Server, groups []ToolGroup, logger *slog.Logger) (*ToolWatcher, error) {
+	w := &ToolWatcher{client: client, server: server, logger: logger}
+	for _, g := range groups {
+		tools, err := toolNames(g.Register)
+		if err != nil {
+			return nil, fmt.Errorf("listing %s tools: %w", g.Name, err)
+		}
+		w.groups = append(w.groups, &watchedGroup{ToolGroup: g, tools: tools})
+	}
+	return w, nil
+}
+
+// Run calls Sync every interval until ctx is done.
+func (w *ToolWatcher) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			w.Sync(ctx)
+		}
+	}
+}
+
+// Sync probes every known editor instance once and adds or removes tool
+// groups to match. Each instance's negotiated plugin handshake is kept
+// while the plugin answers with the same identity and version, and
+// renegotiated when a probe fails or either changes, so a plugin
+// upgraded across an editor restart is picked up.
+func (w *ToolWatcher) Sync(ctx context.Context) {
+	editors := w.probe(ctx)
+	rcOnline, pluginOnline := false, false
+	for _, e := range editors {
+		rcOnline = rcOnline || e.rcOnline
+		pluginOnline = pluginOnline || e.plugin != nil
+	}
+
+	var added, removed []string
+	for _, g := range w.groups {
+		want := slices.ContainsFunc(editors, func(e editorState) bool {
+			if g.Feature == "" {
+				return e.rcOnline
+			}
+			return e.plugin != nil && e.plugin.Supports(featureRoute(g.Feature))
+		})
+		switch {
+		case want && !g.registered:
+			g.Register(w.server)
+			added = append(added, g.Name)
+		case !want && g.registered:
+			w.server.RemoveTools(g.tools...)
+			removed = append(removed, g.Name)
+		}
+		g.registered = want
+	}
+
+	if len(added) > 0 {
+		w.logger.Info("editor tools available", "groups", added, "rc_api", rcOnline, "plugin", pluginOnline)
+	}
+	if len(removed) > 0 {
+		w.logger.Info("editor tools removed", "groups", removed, "rc_api", rcOnline, "plugin", pluginOnline)
+	}
+}
+
+// editorState is what one probe found on an editor instance.
+type editorState struct {
+	rcOnline bool
+	// plugin is the plugin's status, or nil if it did not answer.
+	plugin *PluginStatus
+}
+
+// probe checks the RC API and plugin of every known instance in parallel.
+func (w *ToolWatcher) probe(ctx context.Context) []editorState {
+	clients := []*Client{w.client}
+	w.client.mu.Lock()
+	r := w.client.instances
+	w.client.mu.Unlock()
+	if r != nil {
+		clients = r.clients(ctx)
+	}
+
+	states := make([]editorState, len(clients))
+	var wg sync.WaitGroup
+	for i, c := range clients {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			states[i].rcOnline = c.PingRCAPI(ctx)
+			if st, err := c.ProbePlugin(ctx); err == nil {
+				states[i].plugin = st
+			}
+		}()
+	}
+	wg.Wait()
+	return states
+}
+
+// Registered returns the names of the groups currently registered.
+func (w *ToolWatcher) Registered() []string {
+	var out []string
+	for _, g := range w.groups {
+		if g.registered {
+			out = append(out, g.Name)
+		}
+	}
+	return out
+}
+
+// toolNames returns the names of the tools register adds, by registering
+// them on a scratch server and listing them over an in-memory session.
+func toolNames(register func(*mcp.Server)) ([]string, error) {
+	ctx := context.Background()
+	scratch := mcp.NewServer(&mcp.Implementation{Name: "scratch"}, nil)
+	register(scratch)
+
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	ss, err := scratch.Connect(ctx, serverTransport, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = ss.Close() }()
+	cs, err := mcp.NewClient(&mcp.Implementation{Name: "scratch"}, nil).Connect(ctx, clientTransport, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = cs.Close() }()
+
+	var names []string
+	for tool, err := range cs.Tools(ctx, nil) {
+		if err != nil {
+			return nil, err
+		}
+		names = append(names, tool.Name)
+	}
+	return names, nil
+}
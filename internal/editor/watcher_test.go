@@ -0,0 +1,202 @@
+// Copyright (c) mcp-unreal project contributors. Apache-2.0 license.
+
+package editor
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/remiphilippe/mcp-unreal/internal/config"
+	"github.com/remiphilippe/mcp-unreal/internal/editor/editortest"
+)
+
+func TestToolNames(t *testing.T) {
+	h := &Handler{Client: newTestClient(offlineURL, offlineURL), Logger: testLogger()}
+	names, err := toolNames(h.RegisterEditorUtils)
+	if err != nil {
+		t.Fatalf("toolNames: %v", err)
+	}
+	for _, want := range []string{"pie_control", "get_output_log", "capture_viewport"} {
+		if !slices.Contains(names, want) {
+			t.Errorf("tools = %v, missing %s", names, want)
+		}
+	}
+}
+
+func TestToolGroupFeatures(t *testing.T) {
+	h := &Handler{Client: newTestClient(offlineURL, offlineURL), Logger: testLogger()}
+	for _, g := range h.ToolGroups() {
+		if g.Feature != "" && featureRoute(g.Feature) == "" {
+			t.Errorf("group %s needs feature %q, which is not in PluginFeatures", g.Name, g.Feature)
+		}
+	}
+}
+
+func TestToolWatcher(t *testing.T) {
+	fake := editortest.New(nil)
+	fake.RemoveRoutes("/api/niagara/ops")
+	plugin := httptest.NewServer(fake.PluginHandler())
+	defer plugin.Close()
+	rc := httptest.NewServer(fake.RCHandler())
+	defer rc.Close()
+
+	client := newTestClient(rc.URL, plugin.URL)
+	h := &Handler{Client: client, Logger: testLogger()}
+	server := mcp.NewServer(&mcp.Implementation{Name: "test"}, nil)
+	mcp.AddTool(server, &mcp.Tool{Name: "status"}, func(context.Context, *mcp.CallToolRequest, struct{}) (*mcp.CallToolResult, struct{}, error) {
+		return nil, struct{}{}, nil
+	})
+	watcher, err := NewToolWatcher(client, server, h.ToolGroups(), testLogger())
+	if err != nil {
+		t.Fatalf("NewToolWatcher: %v", err)
+	}
+
+	ctx := context.Background()
+	changed := make(chan struct{}, 10)
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	ss, err := server.Connect(ctx, serverTransport, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = ss.Close() }()
+	session, err := mcp.NewClient(&mcp.Implementation{Name: "test-client"}, &mcp.ClientOptions{
+		ToolListChangedHandler: func(context.Context, *mcp.ToolListChangedRequest) { changed <- struct{}{} },
+	}).Connect(ctx, clientTransport, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = session.Close() }()
+
+	listTools := func() []string {
+		t.Helper()
+		res, err := session.ListTools(ctx, nil)
+		if err != nil {
+			t.Fatalf("ListTools: %v", err)
+		}
+		var names []string
+		for _, tool := range res.Tools {
+			names = append(names, tool.Name)
+		}
+		return names
+	}
+	waitChanged := func() {
+		t.Helper()
+		select {
+		case <-changed:
+		case <-time.After(2 * time.Second):
+			t.Fatal("no tools/list_changed notification")
+		}
+	}
+
+	// Editor up, plugin without Niagara: everything but niagara_ops.
+	watcher.Sync(ctx)
+	waitChanged()
+	tools := listTools()
+	for _, want := range []string{"status", "get_level_actors", "set_property", "gas_ops", "pie_control"} {
+		if !slices.Contains(tools, want) {
+			t.Errorf("tools = %v, missing %s", tools, want)
+		}
+	}
+	if slices.Contains(tools, "niagara_ops") {
+		t.Error("niagara_ops registered although the plugin lacks its route")
+	}
+
+	// A second sync with nothing changed leaves the groups alone.
+	before := watcher.Registered()
+	watcher.Sync(ctx)
+	if !slices.Equal(watcher.Registered(), before) {
+		t.Errorf("registered groups changed without an editor change: %v -> %v", before, watcher.Registered())
+	}
+
+	// Editor gone: only non-editor tools remain.
+	plugin.Close()
+	rc.Close()
+	watcher.Sync(ctx)
+	waitChanged()
+	if tools := listTools(); !slices.Equal(tools, []string{"status"}) {
+		t.Errorf("tools with editor offline = %v, want [status]", tools)
+	}
+	if got := watcher.Registered(); len(got) != 0 {
+		t.Errorf("registered groups with editor offline = %v", got)
+	}
+}
+
+func TestToolWatcherKeepsHandshake(t *testing.T) {
+	var version atomic.Pointer[string]
+	v := "0.3.0"
+	version.Store(&v)
+	plugin := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]any{"name": "MCPUnreal", "version": *version.Load(), "routes": []string{"/api/actors/list"}})
+	}))
+	defer plugin.Close()
+
+	client := newTestClient(offlineURL, plugin.URL)
+	h := &Handler{Client: client, Logger: testLogger()}
+	server := mcp.NewServer(&mcp.Implementation{Name: "test"}, nil)
+	watcher, err := NewToolWatcher(client, server, h.ToolGroups(), testLogger())
+	if err != nil {
+		t.Fatalf("NewToolWatcher: %v", err)
+	}
+
+	ctx := context.Background()
+	watcher.Sync(ctx)
+	first := client.NegotiatedPlugin()
+	if first == nil || first.Version != "0.3.0" {
+		t.Fatalf("negotiated plugin = %+v, want version 0.3.0", first)
+	}
+	watcher.Sync(ctx)
+	if client.NegotiatedPlugin() != first {
+		t.Error("handshake renegotiated although the plugin did not change")
+	}
+
+	v2 := "0.4.0"
+	version.Store(&v2)
+	watcher.Sync(ctx)
+	if got := client.NegotiatedPlugin(); got == nil || got.Version != "0.4.0" {
+		t.Errorf("negotiated plugin after upgrade = %+v, want version 0.4.0", got)
+	}
+
+	plugin.Close()
+	watcher.Sync(ctx)
+	if got := client.NegotiatedPlugin(); got != nil {
+		t.Errorf("negotiated plugin after a failed probe = %+v, want none", got)
+	}
+}
+
+// TestToolWatcherInstances checks that editor tools stay registered while
+// another instance is online with the default editor down.
+func TestToolWatcherInstances(t *testing.T) {
+	other, otherPlugin, otherRC := startFake(t)
+	other.RemoveRoutes("/api/niagara/ops")
+	cfg := &config.Config{PluginPort: 1, RCAPIPort: 1,
+		EditorInstances: "other=" + strconv.Itoa(otherPlugin) + "/" + strconv.Itoa(otherRC)}
+	client := NewClient(cfg, testLogger())
+	if _, err := NewInstances(client, cfg); err != nil {
+		t.Fatalf("NewInstances: %v", err)
+	}
+	h := &Handler{Client: client, Logger: testLogger()}
+	server := mcp.NewServer(&mcp.Implementation{Name: "test"}, nil)
+	watcher, err := NewToolWatcher(client, server, h.ToolGroups(), testLogger())
+	if err != nil {
+		t.Fatalf("NewToolWatcher: %v", err)
+	}
+
+	watcher.Sync(context.Background())
+	got := watcher.Registered()
+	for _, want := range []string{"properties", "actors", "editor_utils"} {
+		if !slices.Contains(got, want) {
+			t.Errorf("registered groups = %v, missing %s served by the other instance", got, want)
+		}
+	}
+	if slices.Contains(got, "niagara") {
+		t.Errorf("registered groups = %v, want no niagara: no instance serves it", got)
+	}
+}
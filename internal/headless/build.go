@@ -27,12 +27,27 @@ import (
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 	"github.com/remiphilippe/mcp-unreal/internal/config"
+	"github.com/remiphilippe/mcp-unreal/internal/sandbox"
+	"github.com/remiphilippe/mcp-unreal/internal/telemetry"
+	"github.com/remiphilippe/mcp-unreal/internal/toolerr"
 )
 
 // Handler holds references needed by headless tools.
 type Handler struct {
 	Config *config.Config
 	Logger *slog.Logger
+
+	// Paths confines file paths in tool arguments. Nil means the
+	// sandbox built from Config.
+	Paths *sandbox.Sandbox
+}
+
+// paths returns the sandbox for file paths in tool arguments.
+func (h *Handler) paths() *sandbox.Sandbox {
+	if h.Paths != nil {
+		return h.Paths
+	}
+	return sandbox.New(h.Config)
 }
 
 // --- build_project ---
@@ -78,7 +93,7 @@ func (h *Handler) Register(server *mcp.Server) {
 func (h *Handler) BuildProject(ctx context.Context, req *mcp.CallToolRequest, input BuildInput) (*mcp.CallToolResult, BuildOutput, error) {
 	editorPath := h.Config.UEEditorPath
 	if _, err := os.Stat(editorPath); err != nil {
-		return nil, BuildOutput{}, fmt.Errorf(
+		return nil, BuildOutput{}, toolerr.New(toolerr.UENotInstalled,
 			"UnrealEditor-Cmd not found at %s — set UE_EDITOR_PATH env var or install UE 5.7",
 			editorPath,
 		)
@@ -86,7 +101,7 @@ func (h *Handler) BuildProject(ctx context.Context, req *mcp.CallToolRequest, in
 
 	projectFile := h.Config.UProjectFile
 	if projectFile == "" {
-		return nil, BuildOutput{}, fmt.Errorf(
+		return nil, BuildOutput{}, toolerr.New(toolerr.ProjectNotFound,
 			"no .uproject file found — set MCP_UNREAL_PROJECT or run from inside a UE project directory",
 		)
 	}
@@ -183,7 +198,7 @@ func (h *Handler) RegisterCook(server *mcp.Server) {
 func (h *Handler) CookProject(ctx context.Context, req *mcp.CallToolRequest, input CookInput) (*mcp.CallToolResult, CookOutput, error) {
 	projectFile := h.Config.UProjectFile
 	if projectFile == "" {
-		return nil, CookOutput{}, fmt.Errorf(
+		return nil, CookOutput{}, toolerr.New(toolerr.ProjectNotFound,
 			"no .uproject file found — set MCP_UNREAL_PROJECT or run from inside a UE project directory",
 		)
 	}
@@ -191,7 +206,7 @@ func (h *Handler) CookProject(ctx context.Context, req *mcp.CallToolRequest, inp
 	// Find RunUAT script.
 	runUAT := findRunUATScript(h.Config.UEEditorPath)
 	if runUAT == "" {
-		return nil, CookOutput{}, fmt.Errorf(
+		return nil, CookOutput{}, toolerr.New(toolerr.UENotInstalled,
 			"RunUAT script not found — ensure UE 5.7 is installed and UE_EDITOR_PATH is correct",
 		)
 	}
@@ -274,7 +289,7 @@ type GenerateProjectFilesOutput struct {
 func (h *Handler) GenerateProjectFiles(ctx context.Context, req *mcp.CallToolRequest, input GenerateProjectFilesInput) (*mcp.CallToolResult, GenerateProjectFilesOutput, error) {
 	projectFile := h.Config.UProjectFile
 	if projectFile == "" {
-		return nil, GenerateProjectFilesOutput{}, fmt.Errorf(
+		return nil, GenerateProjectFilesOutput{}, toolerr.New(toolerr.ProjectNotFound,
 			"no .uproject file found — set MCP_UNREAL_PROJECT or run from inside a UE project directory",
 		)
 	}
@@ -282,7 +297,7 @@ func (h *Handler) GenerateProjectFiles(ctx context.Context, req *mcp.CallToolReq
 	// Find GenerateProjectFiles script relative to UE editor path.
 	script := findGenerateProjectFilesScript(h.Config.UEEditorPath)
 	if script == "" {
-		return nil, GenerateProjectFilesOutput{}, fmt.Errorf(
+		return nil, GenerateProjectFilesOutput{}, toolerr.New(toolerr.UENotInstalled,
 			"GenerateProjectFiles script not found — ensure UE 5.7 is installed and UE_EDITOR_PATH is correct",
 		)
 	}
@@ -309,30 +324,46 @@ func (h *Handler) GenerateProjectFiles(ctx context.Context, req *mcp.CallToolReq
 // --- helpers ---
 
 // runCommand executes a command with timeout, capturing stdout and stderr.
-// Returns (stdout, stderr, exitCode, error). exitCode is -1 if the process
-// could not be started.
+// A deadline already on ctx, such as a configured per-tool timeout,
+// replaces the default timeout. Returns (stdout, stderr, exitCode, error).
+// exitCode is -1 if the process could not be started or was killed at the
+// timeout, which is a Timeout error.
 func (h *Handler) runCommand(ctx context.Context, name string, args []string, timeout time.Duration) (string, string, int, error) {
+	if deadline, ok := ctx.Deadline(); ok {
+		timeout = time.Until(deadline)
+	}
 	ctx, cancel := context.WithTimeout(ctx, timeout)
 	defer cancel()
 
 	h.Logger.Debug("executing command", "cmd", name, "args", args)
+	ctx, end := telemetry.StartCommand(ctx, name, args)
 
 	cmd := exec.CommandContext(ctx, name, args...)
 	var stdoutBuf, stderrBuf bytes.Buffer
 	cmd.Stdout = &stdoutBuf
 	cmd.Stderr = &stderrBuf
+	// Children that outlive a killed process keep its output pipes open;
+	// stop waiting for them so the timeout is reported.
+	cmd.WaitDelay = 10 * time.Second
 
 	err := cmd.Run()
+	if ctx.Err() == context.DeadlineExceeded {
+		err = toolerr.New(toolerr.Timeout, "%s timed out after %s", filepath.Base(name), timeout.Round(time.Millisecond))
+		end(-1, err)
+		return stdoutBuf.String(), stderrBuf.String(), -1, err
+	}
 
 	exitCode := 0
 	if err != nil {
 		if exitErr, ok := err.(*exec.ExitError); ok {
 			exitCode = exitErr.ExitCode()
 		} else {
+			end(-1, err)
 			return "", "", -1, err
 		}
 	}
 
+	end(exitCode, nil)
 	return stdoutBuf.String(), stderrBuf.String(), exitCode, nil
 }
 
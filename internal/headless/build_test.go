@@ -11,8 +11,10 @@ import (
 	"runtime"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/remiphilippe/mcp-unreal/internal/config"
+	"github.com/remiphilippe/mcp-unreal/internal/toolerr"
 )
 
 // testLogger returns a debug-level logger writing to stderr (safe for tests).
@@ -253,6 +255,41 @@ func TestRunCommand_NotFound(t *testing.T) {
 	}
 }
 
+func TestRunCommand_Timeout(t *testing.T) {
+	h := &Handler{
+		Config: &config.Config{},
+		Logger: testLogger(),
+	}
+
+	start := time.Now()
+	_, _, exitCode, err := h.runCommand(context.Background(), "sh", []string{"-c", "exec sleep 10"}, 100*time.Millisecond)
+	if time.Since(start) > 5*time.Second {
+		t.Fatal("command was not killed at the timeout")
+	}
+	if exitCode != -1 || toolerr.CodeOf(err) != toolerr.Timeout {
+		t.Errorf("exitCode = %d, err = %v; want -1 and a %s error", exitCode, err, toolerr.Timeout)
+	}
+	if err != nil && !strings.Contains(err.Error(), "sh timed out after 100ms") {
+		t.Errorf("error = %q", err)
+	}
+}
+
+func TestRunCommand_ContextDeadline(t *testing.T) {
+	h := &Handler{
+		Config: &config.Config{},
+		Logger: testLogger(),
+	}
+
+	// A deadline on the context, e.g. a configured tool timeout, replaces
+	// the default timeout even when it is longer.
+	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Millisecond)
+	defer cancel()
+	_, _, exitCode, err := h.runCommand(ctx, "sh", []string{"-c", "sleep 0.2"}, 100*time.Millisecond)
+	if exitCode != 0 || err != nil {
+		t.Errorf("exitCode = %d, err = %v; want the command to finish within the context deadline", exitCode, err)
+	}
+}
+
 func TestBuildProject_Success(t *testing.T) {
 	editorPath, projectFile := createFakeEditor(t, "Build succeeded.\n", 0)
 
@@ -357,6 +394,9 @@ func TestBuildProject_NoEditor(t *testing.T) {
 	if !strings.Contains(err.Error(), "UnrealEditor-Cmd not found") {
 		t.Errorf("error = %q, want to contain 'UnrealEditor-Cmd not found'", err.Error())
 	}
+	if code := toolerr.CodeOf(err); code != toolerr.UENotInstalled {
+		t.Errorf("code = %s, want %s", code, toolerr.UENotInstalled)
+	}
 }
 
 func TestBuildProject_NoProject(t *testing.T) {
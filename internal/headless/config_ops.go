@@ -18,6 +18,8 @@ import (
 	"strings"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/remiphilippe/mcp-unreal/internal/sandbox"
+	"github.com/remiphilippe/mcp-unreal/internal/toolerr"
 )
 
 // --- config_ops ---
@@ -29,6 +31,7 @@ type ConfigOpsInput struct {
 	Section   string `json:"section,omitempty" jsonschema:"INI section name (e.g. /Script/Engine.RendererSettings). Required for get, set, delete, list."`
 	Key       string `json:"key,omitempty" jsonschema:"Config key name. Required for get, set, delete."`
 	Value     string `json:"value,omitempty" jsonschema:"Value to set. Required for set operation."`
+	DryRun    bool   `json:"dry_run,omitempty" jsonschema:"For set and delete: return the diff of the change without writing the file"`
 }
 
 // ConfigOpsOutput is returned by the config_ops tool.
@@ -40,6 +43,8 @@ type ConfigOpsOutput struct {
 	Value    string            `json:"value,omitempty" jsonschema:"value retrieved or set"`
 	Values   map[string]string `json:"values,omitempty" jsonschema:"all key-value pairs in the section (for list operation)"`
 	Sections []string          `json:"sections,omitempty" jsonschema:"all section names in the file (for list_sections operation)"`
+	Diff     string            `json:"diff,omitempty" jsonschema:"unified diff of the file change (for set and delete)"`
+	DryRun   bool              `json:"dry_run,omitempty" jsonschema:"true when the change was only previewed and the file was not written"`
 }
 
 // RegisterConfig adds the config_ops tool to the MCP server.
@@ -49,6 +54,7 @@ func (h *Handler) RegisterConfig(server *mcp.Server) {
 		Description: "Read and write UE project .ini config files (DefaultEngine.ini, DefaultGame.ini, etc.). " +
 			"Operations: get (read a key), set (write a key), delete (remove a key), " +
 			"list (all keys in a section), list_sections (all sections in a file). " +
+			"set and delete return a unified diff of the change; with dry_run=true the file is not written. " +
 			"Does not require the editor to be running. " +
 			"File paths are resolved relative to the project Config/ directory.",
 	}, h.ConfigOps)
@@ -57,10 +63,10 @@ func (h *Handler) RegisterConfig(server *mcp.Server) {
 // ConfigOps implements the config_ops tool.
 func (h *Handler) ConfigOps(ctx context.Context, req *mcp.CallToolRequest, input ConfigOpsInput) (*mcp.CallToolResult, ConfigOpsOutput, error) {
 	if input.Operation == "" {
-		return nil, ConfigOpsOutput{}, fmt.Errorf("operation is required")
+		return nil, ConfigOpsOutput{}, toolerr.New(toolerr.Validation, "operation is required")
 	}
 	if input.File == "" {
-		return nil, ConfigOpsOutput{}, fmt.Errorf("file is required")
+		return nil, ConfigOpsOutput{}, toolerr.New(toolerr.Validation, "file is required")
 	}
 
 	// Resolve the INI file path within the project Config/ directory.
@@ -81,14 +87,14 @@ func (h *Handler) ConfigOps(ctx context.Context, req *mcp.CallToolRequest, input
 	case "list_sections":
 		return h.configListSections(iniPath, input)
 	default:
-		return nil, ConfigOpsOutput{}, fmt.Errorf("unknown operation %q — use get, set, delete, list, or list_sections", input.Operation)
+		return nil, ConfigOpsOutput{}, toolerr.New(toolerr.Validation, "unknown operation %q — use get, set, delete, list, or list_sections", input.Operation)
 	}
 }
 
 // resolveINIPath safely resolves an INI file name to a full path within Config/.
 func (h *Handler) resolveINIPath(file string) (string, error) {
 	if h.Config.ProjectRoot == "" {
-		return "", fmt.Errorf("no UE project root detected — set MCP_UNREAL_PROJECT env var")
+		return "", toolerr.New(toolerr.ProjectNotFound, "no UE project root detected — set MCP_UNREAL_PROJECT env var")
 	}
 
 	// Sanitize: strip any extension the user might have added.
@@ -96,23 +102,20 @@ func (h *Handler) resolveINIPath(file string) (string, error) {
 
 	// Reject path traversal attempts.
 	if strings.Contains(file, "..") || strings.Contains(file, "/") || strings.Contains(file, "\\") {
-		return "", fmt.Errorf("invalid file name %q — use just the file name without path separators (e.g. DefaultEngine)", file)
+		return "", toolerr.New(toolerr.Validation, "invalid file name %q — use just the file name without path separators (e.g. DefaultEngine)", file)
 	}
 
 	configDir := filepath.Join(h.Config.ProjectRoot, "Config")
 	iniPath := filepath.Join(configDir, file+".ini")
 
-	// Verify the resolved path is still within Config/.
-	absPath, err := filepath.Abs(iniPath)
+	// Verify the resolved path, symlinks included, is still within
+	// Config/.
+	resolved, err := h.paths().Resolve("file", iniPath)
 	if err != nil {
-		return "", fmt.Errorf("resolving path: %w", err)
+		return "", err
 	}
-	absConfigDir, err := filepath.Abs(configDir)
-	if err != nil {
-		return "", fmt.Errorf("resolving config dir: %w", err)
-	}
-	if !strings.HasPrefix(absPath, absConfigDir) {
-		return "", fmt.Errorf("path traversal blocked: %s is outside Config/", file)
+	if !sandbox.Within(configDir, resolved) {
+		return "", toolerr.New(toolerr.PathRefused, "path traversal blocked: %s is outside Config/", file)
 	}
 
 	return iniPath, nil
@@ -120,10 +123,10 @@ func (h *Handler) resolveINIPath(file string) (string, error) {
 
 func (h *Handler) configGet(iniPath string, input ConfigOpsInput) (*mcp.CallToolResult, ConfigOpsOutput, error) {
 	if input.Section == "" {
-		return nil, ConfigOpsOutput{}, fmt.Errorf("section is required for get operation")
+		return nil, ConfigOpsOutput{}, toolerr.New(toolerr.Validation, "section is required for get operation")
 	}
 	if input.Key == "" {
-		return nil, ConfigOpsOutput{}, fmt.Errorf("key is required for get operation")
+		return nil, ConfigOpsOutput{}, toolerr.New(toolerr.Validation, "key is required for get operation")
 	}
 
 	sections, err := parseINI(iniPath)
@@ -133,12 +136,12 @@ func (h *Handler) configGet(iniPath string, input ConfigOpsInput) (*mcp.CallTool
 
 	keys, ok := sections[input.Section]
 	if !ok {
-		return nil, ConfigOpsOutput{}, fmt.Errorf("section [%s] not found in %s", input.Section, filepath.Base(iniPath))
+		return nil, ConfigOpsOutput{}, toolerr.New(toolerr.NotFound, "section [%s] not found in %s", input.Section, filepath.Base(iniPath))
 	}
 
 	value, ok := keys[input.Key]
 	if !ok {
-		return nil, ConfigOpsOutput{}, fmt.Errorf("key %q not found in section [%s]", input.Key, input.Section)
+		return nil, ConfigOpsOutput{}, toolerr.New(toolerr.NotFound, "key %q not found in section [%s]", input.Key, input.Section)
 	}
 
 	return nil, ConfigOpsOutput{
@@ -152,13 +155,14 @@ func (h *Handler) configGet(iniPath string, input ConfigOpsInput) (*mcp.CallTool
 
 func (h *Handler) configSet(iniPath string, input ConfigOpsInput) (*mcp.CallToolResult, ConfigOpsOutput, error) {
 	if input.Section == "" {
-		return nil, ConfigOpsOutput{}, fmt.Errorf("section is required for set operation")
+		return nil, ConfigOpsOutput{}, toolerr.New(toolerr.Validation, "section is required for set operation")
 	}
 	if input.Key == "" {
-		return nil, ConfigOpsOutput{}, fmt.Errorf("key is required for set operation")
+		return nil, ConfigOpsOutput{}, toolerr.New(toolerr.Validation, "key is required for set operation")
 	}
 
-	if err := setINIValue(iniPath, input.Section, input.Key, input.Value); err != nil {
+	diff, err := setINIValue(iniPath, input.Section, input.Key, input.Value, input.DryRun)
+	if err != nil {
 		return nil, ConfigOpsOutput{}, err
 	}
 
@@ -168,18 +172,21 @@ func (h *Handler) configSet(iniPath string, input ConfigOpsInput) (*mcp.CallTool
 		Section: input.Section,
 		Key:     input.Key,
 		Value:   input.Value,
+		Diff:    diff,
+		DryRun:  input.DryRun,
 	}, nil
 }
 
 func (h *Handler) configDelete(iniPath string, input ConfigOpsInput) (*mcp.CallToolResult, ConfigOpsOutput, error) {
 	if input.Section == "" {
-		return nil, ConfigOpsOutput{}, fmt.Errorf("section is required for delete operation")
+		return nil, ConfigOpsOutput{}, toolerr.New(toolerr.Validation, "section is required for delete operation")
 	}
 	if input.Key == "" {
-		return nil, ConfigOpsOutput{}, fmt.Errorf("key is required for delete operation")
+		return nil, ConfigOpsOutput{}, toolerr.New(toolerr.Validation, "key is required for delete operation")
 	}
 
-	if err := deleteINIValue(iniPath, input.Section, input.Key); err != nil {
+	diff, err := deleteINIValue(iniPath, input.Section, input.Key, input.DryRun)
+	if err != nil {
 		return nil, ConfigOpsOutput{}, err
 	}
 
@@ -188,12 +195,14 @@ func (h *Handler) configDelete(iniPath string, input ConfigOpsInput) (*mcp.CallT
 		File:    filepath.Base(iniPath),
 		Section: input.Section,
 		Key:     input.Key,
+		Diff:    diff,
+		DryRun:  input.DryRun,
 	}, nil
 }
 
 func (h *Handler) configList(iniPath string, input ConfigOpsInput) (*mcp.CallToolResult, ConfigOpsOutput, error) {
 	if input.Section == "" {
-		return nil, ConfigOpsOutput{}, fmt.Errorf("section is required for list operation")
+		return nil, ConfigOpsOutput{}, toolerr.New(toolerr.Validation, "section is required for list operation")
 	}
 
 	sections, err := parseINI(iniPath)
@@ -203,7 +212,7 @@ func (h *Handler) configList(iniPath string, input ConfigOpsInput) (*mcp.CallToo
 
 	keys, ok := sections[input.Section]
 	if !ok {
-		return nil, ConfigOpsOutput{}, fmt.Errorf("section [%s] not found in %s", input.Section, filepath.Base(iniPath))
+		return nil, ConfigOpsOutput{}, toolerr.New(toolerr.NotFound, "section [%s] not found in %s", input.Section, filepath.Base(iniPath))
 	}
 
 	return nil, ConfigOpsOutput{
@@ -252,7 +261,7 @@ func parseINI(path string) (map[string]map[string]string, error) {
 	f, err := os.Open(filepath.Clean(path))
 	if err != nil {
 		if os.IsNotExist(err) {
-			return nil, fmt.Errorf("config file not found: %s", filepath.Base(path))
+			return nil, toolerr.New(toolerr.NotFound, "config file not found: %s", filepath.Base(path))
 		}
 		return nil, fmt.Errorf("opening config file: %w", err)
 	}
@@ -297,10 +306,12 @@ func parseINI(path string) (map[string]map[string]string, error) {
 
 // setINIValue sets a key in a section, creating the section if needed.
 // It preserves all other content in the file (comments, ordering, etc.).
-func setINIValue(path, section, key, value string) error {
+// It returns a unified diff of the change; with dryRun the file is not
+// written.
+func setINIValue(path, section, key, value string, dryRun bool) (string, error) {
 	lines, err := readLines(path)
 	if err != nil && !os.IsNotExist(err) {
-		return fmt.Errorf("reading config file: %w", err)
+		return "", fmt.Errorf("reading config file: %w", err)
 	}
 
 	newLine := key + "=" + value
@@ -351,14 +362,15 @@ func setINIValue(path, section, key, value string) error {
 		lines = append(lines, sectionHeader, newLine)
 	}
 
-	return writeLines(path, lines)
+	return writeLines(path, lines, dryRun)
 }
 
-// deleteINIValue removes a key from a section.
-func deleteINIValue(path, section, key string) error {
+// deleteINIValue removes a key from a section, returning a unified diff
+// of the change. With dryRun the file is not written.
+func deleteINIValue(path, section, key string, dryRun bool) (string, error) {
 	lines, err := readLines(path)
 	if err != nil {
-		return fmt.Errorf("reading config file: %w", err)
+		return "", fmt.Errorf("reading config file: %w", err)
 	}
 
 	sectionHeader := "[" + section + "]"
@@ -387,10 +399,10 @@ func deleteINIValue(path, section, key string) error {
 	}
 
 	if !deleted {
-		return fmt.Errorf("key %q not found in section [%s]", key, section)
+		return "", toolerr.New(toolerr.NotFound, "key %q not found in section [%s]", key, section)
 	}
 
-	return writeLines(path, result)
+	return writeLines(path, result, dryRun)
 }
 
 // readLines reads a file into a slice of lines.
@@ -408,14 +420,12 @@ func readLines(path string) ([]string, error) {
 	return strings.Split(content, "\n"), nil
 }
 
-// writeLines writes a slice of lines back to a file with a trailing newline.
-func writeLines(path string, lines []string) error {
-	// Ensure the directory exists.
-	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
-		return fmt.Errorf("creating config directory: %w", err)
-	}
+// writeLines writes a slice of lines back to a file with a trailing
+// newline and returns the diff, labelled Config/<file>. With dryRun only
+// the diff is produced.
+func writeLines(path string, lines []string, dryRun bool) (string, error) {
 	content := strings.Join(lines, "\n") + "\n"
-	return os.WriteFile(path, []byte(content), 0o600)
+	return changeFile(path, "Config/"+filepath.Base(path), []byte(content), dryRun)
 }
 
 // insertLine inserts a line at position i, shifting everything after.
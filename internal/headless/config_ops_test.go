@@ -452,3 +452,59 @@ func TestConfigOps_InvalidOperation(t *testing.T) {
 		t.Fatal("expected error for invalid operation")
 	}
 }
+
+func TestConfigOps_Set_DryRun(t *testing.T) {
+	h := createTestConfig(t, "DefaultEngine", sampleINI)
+	ctx := context.Background()
+
+	_, out, err := h.ConfigOps(ctx, nil, ConfigOpsInput{
+		Operation: "set",
+		File:      "DefaultEngine",
+		Section:   "Pelorus.BaseMap",
+		Key:       "DemGridSize",
+		Value:     "4096",
+		DryRun:    true,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !out.DryRun {
+		t.Error("expected dry_run in output")
+	}
+	for _, want := range []string{"--- a/Config/DefaultEngine.ini", "-DemGridSize=2048", "+DemGridSize=4096"} {
+		if !strings.Contains(out.Diff, want) {
+			t.Errorf("diff missing %q:\n%s", want, out.Diff)
+		}
+	}
+
+	// The file is unchanged.
+	data, err := os.ReadFile(filepath.Join(h.Config.ProjectRoot, "Config", "DefaultEngine.ini")) //nolint:gosec // test reads known temp path
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != sampleINI {
+		t.Error("dry run modified the config file")
+	}
+}
+
+func TestConfigOps_Delete_DryRun(t *testing.T) {
+	h := createTestConfig(t, "DefaultEngine", sampleINI)
+
+	_, out, err := h.ConfigOps(context.Background(), nil, ConfigOpsInput{
+		Operation: "delete",
+		File:      "DefaultEngine",
+		Section:   "Pelorus.UI",
+		Key:       "DistanceUnit",
+		DryRun:    true,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(out.Diff, "-DistanceUnit=NM") {
+		t.Errorf("diff missing deletion:\n%s", out.Diff)
+	}
+	data, _ := os.ReadFile(filepath.Join(h.Config.ProjectRoot, "Config", "DefaultEngine.ini")) //nolint:gosec // test reads known temp path
+	if !strings.Contains(string(data), "DistanceUnit=NM") {
+		t.Error("dry run deleted the key")
+	}
+}
@@ -0,0 +1,179 @@
+// Copyright (c) mcp-unreal project contributors. Apache-2.0 license.
+
+package headless
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// diffContext is the number of unchanged lines around each change in a
+// unified diff.
+const diffContext = 3
+
+// editOp is one line of an edit script: kept (' '), deleted ('-'), or
+// inserted ('+'). a and b are the line's position in the old and new
+// text; for an insert a is the old line it precedes, and vice versa.
+type editOp struct {
+	kind byte
+	a, b int
+}
+
+// changeFile replaces the content of path with data and returns a
+// unified diff of the change, labelled with name. With dryRun the diff
+// is returned and the file is left alone. A missing file diffs as empty.
+func changeFile(path, name string, data []byte, dryRun bool) (string, error) {
+	old, err := os.ReadFile(filepath.Clean(path))
+	if err != nil && !os.IsNotExist(err) {
+		return "", fmt.Errorf("reading %s: %w", name, err)
+	}
+	diff := unifiedDiff(name, string(old), string(data))
+	if dryRun {
+		return diff, nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return "", fmt.Errorf("creating directory for %s: %w", name, err)
+	}
+	if err := os.WriteFile(filepath.Clean(path), data, 0o600); err != nil {
+		return "", fmt.Errorf("writing %s: %w", name, err)
+	}
+	return diff, nil
+}
+
+// unifiedDiff returns the unified diff from old to new, as diff -u
+// prints it with name as both file labels, or "" if they are equal.
+func unifiedDiff(name, old, new string) string {
+	if old == new {
+		return ""
+	}
+	a, b := splitLines(old), splitLines(new)
+	ops := diffLines(a, b)
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "--- a/%s\n+++ b/%s\n", name, name)
+	for i := 0; i < len(ops); {
+		// Find the next change and extend the hunk while changes are
+		// within 2*diffContext lines of each other.
+		for i < len(ops) && ops[i].kind == ' ' {
+			i++
+		}
+		if i == len(ops) {
+			break
+		}
+		lo := max(i-diffContext, 0)
+		hi := i
+		for j := i; j < len(ops); j++ {
+			if ops[j].kind != ' ' {
+				hi = j + 1
+			} else if j-hi >= 2*diffContext {
+				break
+			}
+		}
+		hi = min(hi+diffContext, len(ops))
+		writeHunk(&sb, a, b, ops[lo:hi])
+		i = hi
+	}
+	return sb.String()
+}
+
+func writeHunk(sb *strings.Builder, a, b []string, ops []editOp) {
+	var na, nb int
+	for _, op := range ops {
+		if op.kind != '+' {
+			na++
+		}
+		if op.kind != '-' {
+			nb++
+		}
+	}
+	start := func(pos, n int) int {
+		if n == 0 {
+			return pos
+		}
+		return pos + 1
+	}
+	fmt.Fprintf(sb, "@@ -%d,%d +%d,%d @@\n", start(ops[0].a, na), na, start(ops[0].b, nb), nb)
+	for _, op := range ops {
+		line := ""
+		if op.kind == '+' {
+			line = b[op.b]
+		} else {
+			line = a[op.a]
+		}
+		sb.WriteByte(op.kind)
+		sb.WriteString(line)
+		sb.WriteByte('\n')
+	}
+}
+
+func splitLines(s string) []string {
+	s = strings.TrimSuffix(s, "\n")
+	if s == "" {
+		return nil
+	}
+	return strings.Split(strings.ReplaceAll(s, "\r\n", "\n"), "\n")
+}
+
+// diffLines returns a shortest edit script from a to b (Myers' O(ND)
+// algorithm).
+func diffLines(a, b []string) []editOp {
+	n, m := len(a), len(b)
+	maxD := n + m
+	off := maxD + 1
+	v := make([]int, 2*maxD+2)
+	var trace [][]int
+
+search:
+	for d := 0; d <= maxD; d++ {
+		trace = append(trace, slices.Clone(v))
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[off+k-1] < v[off+k+1]) {
+				x = v[off+k+1]
+			} else {
+				x = v[off+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x, y = x+1, y+1
+			}
+			v[off+k] = x
+			if x >= n && y >= m {
+				break search
+			}
+		}
+	}
+
+	// Walk back from the end through the saved frontiers.
+	var ops []editOp
+	x, y := n, m
+	for d := len(trace) - 1; d >= 0; d-- {
+		v := trace[d]
+		k := x - y
+		var prevK int
+		if k == -d || (k != d && v[off+k-1] < v[off+k+1]) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+		prevX := v[off+prevK]
+		prevY := prevX - prevK
+		for x > prevX && y > prevY {
+			x, y = x-1, y-1
+			ops = append(ops, editOp{' ', x, y})
+		}
+		if d > 0 {
+			if x == prevX {
+				ops = append(ops, editOp{'+', x, y - 1})
+			} else {
+				ops = append(ops, editOp{'-', x - 1, y})
+			}
+		}
+		x, y = prevX, prevY
+	}
+	slices.Reverse(ops)
+	return ops
+}
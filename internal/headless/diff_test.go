@@ -0,0 +1,55 @@
+// Copyright (c) mcp-unreal project contributors. Apache-2.0 license.
+
+package headless
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestUnifiedDiff(t *testing.T) {
+	tests := []struct {
+		name     string
+		old, new string
+		want     string
+	}{
+		{"equal", "a\nb\n", "a\nb\n", ""},
+		{"change", "a\nb\nc\n", "a\nB\nc\n", "--- a/f\n+++ b/f\n@@ -1,3 +1,3 @@\n a\n-b\n+B\n c\n"},
+		{"new file", "", "x\ny\n", "--- a/f\n+++ b/f\n@@ -0,0 +1,2 @@\n+x\n+y\n"},
+		{"delete all", "x\n", "", "--- a/f\n+++ b/f\n@@ -1,1 +0,0 @@\n-x\n"},
+		{"append", "1\n2\n3\n4\n5\n", "1\n2\n3\n4\n5\n6\n", "--- a/f\n+++ b/f\n@@ -3,3 +3,4 @@\n 3\n 4\n 5\n+6\n"},
+		{"two hunks", "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n", "0\n1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n",
+			"--- a/f\n+++ b/f\n@@ -1,3 +1,4 @@\n+0\n 1\n 2\n 3\n@@ -9,4 +10,3 @@\n 9\n 10\n 11\n-12\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := unifiedDiff("f", tt.old, tt.new); got != tt.want {
+				t.Errorf("unifiedDiff =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestChangeFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sub", "f.ini")
+
+	diff, err := changeFile(path, "f.ini", []byte("a\n"), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff == "" {
+		t.Error("expected a diff for a new file")
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatal("dry run created the file")
+	}
+
+	if _, err := changeFile(path, "f.ini", []byte("a\n"), false); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path) //nolint:gosec // test reads known temp path
+	if err != nil || string(data) != "a\n" {
+		t.Fatalf("file = %q, %v", data, err)
+	}
+}
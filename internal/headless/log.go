@@ -10,6 +10,7 @@ import (
 	"strings"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/remiphilippe/mcp-unreal/internal/toolerr"
 )
 
 // --- get_test_log ---
@@ -47,18 +48,17 @@ func (h *Handler) GetTestLog(ctx context.Context, req *mcp.CallToolRequest, inpu
 	if logPath == "" {
 		logPath = h.findLatestLog()
 		if logPath == "" {
-			return nil, GetTestLogOutput{}, fmt.Errorf(
+			return nil, GetTestLogOutput{}, toolerr.New(toolerr.NotFound,
 				"no UE log file found in project Saved/Logs/ — specify log_path explicitly",
 			)
 		}
 	}
 
-	// Path validation: reject traversal attempts (CLAUDE.md Security §4).
-	cleaned := filepath.Clean(logPath)
-	if strings.Contains(cleaned, "..") {
-		return nil, GetTestLogOutput{}, fmt.Errorf(
-			"invalid log path: path traversal not allowed",
-		)
+	// Path validation: the log must be in an allowed directory
+	// (CLAUDE.md Security §4).
+	cleaned, err := h.paths().Resolve("log_path", logPath)
+	if err != nil {
+		return nil, GetTestLogOutput{}, err
 	}
 
 	data, err := os.ReadFile(cleaned)
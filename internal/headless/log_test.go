@@ -19,7 +19,7 @@ func TestGetTestLog_ReadsFile(t *testing.T) {
 	}
 
 	h := &Handler{
-		Config: &config.Config{},
+		Config: &config.Config{ProjectRoot: "testdata"},
 		Logger: testLogger(),
 	}
 
@@ -49,7 +49,7 @@ func TestGetTestLog_Filter(t *testing.T) {
 	}
 
 	h := &Handler{
-		Config: &config.Config{},
+		Config: &config.Config{ProjectRoot: "testdata"},
 		Logger: testLogger(),
 	}
 
@@ -82,7 +82,7 @@ func TestGetTestLog_MaxLines(t *testing.T) {
 	}
 
 	h := &Handler{
-		Config: &config.Config{},
+		Config: &config.Config{ProjectRoot: "testdata"},
 		Logger: testLogger(),
 	}
 
@@ -112,7 +112,7 @@ func TestGetTestLog_MaxLinesCapped(t *testing.T) {
 	}
 
 	h := &Handler{
-		Config: &config.Config{},
+		Config: &config.Config{ProjectRoot: dir},
 		Logger: testLogger(),
 	}
 
@@ -142,7 +142,7 @@ func TestGetTestLog_DefaultMaxLines(t *testing.T) {
 	}
 
 	h := &Handler{
-		Config: &config.Config{},
+		Config: &config.Config{ProjectRoot: dir},
 		Logger: testLogger(),
 	}
 
@@ -166,7 +166,7 @@ func TestGetTestLog_Offset(t *testing.T) {
 	}
 
 	h := &Handler{
-		Config: &config.Config{},
+		Config: &config.Config{ProjectRoot: "testdata"},
 		Logger: testLogger(),
 	}
 
@@ -203,7 +203,7 @@ func TestGetTestLog_OffsetBeyondEnd(t *testing.T) {
 	}
 
 	h := &Handler{
-		Config: &config.Config{},
+		Config: &config.Config{ProjectRoot: "testdata"},
 		Logger: testLogger(),
 	}
 
@@ -222,7 +222,7 @@ func TestGetTestLog_OffsetBeyondEnd(t *testing.T) {
 
 func TestGetTestLog_PathTraversal(t *testing.T) {
 	h := &Handler{
-		Config: &config.Config{},
+		Config: &config.Config{ProjectRoot: t.TempDir()},
 		Logger: testLogger(),
 	}
 
@@ -232,14 +232,14 @@ func TestGetTestLog_PathTraversal(t *testing.T) {
 	if err == nil {
 		t.Error("expected error for path traversal attempt")
 	}
-	if !strings.Contains(err.Error(), "path traversal not allowed") {
-		t.Errorf("error = %q, want to contain 'path traversal not allowed'", err.Error())
+	if !strings.Contains(err.Error(), "outside the allowed directories") {
+		t.Errorf("error = %q, want to contain 'outside the allowed directories'", err.Error())
 	}
 }
 
 func TestGetTestLog_PathTraversalRelative(t *testing.T) {
 	h := &Handler{
-		Config: &config.Config{},
+		Config: &config.Config{ProjectRoot: t.TempDir()},
 		Logger: testLogger(),
 	}
 
@@ -249,14 +249,37 @@ func TestGetTestLog_PathTraversalRelative(t *testing.T) {
 	if err == nil {
 		t.Error("expected error for relative path traversal")
 	}
-	if !strings.Contains(err.Error(), "path traversal not allowed") {
-		t.Errorf("error = %q, want to contain 'path traversal not allowed'", err.Error())
+	if !strings.Contains(err.Error(), "outside the allowed directories") {
+		t.Errorf("error = %q, want to contain 'outside the allowed directories'", err.Error())
+	}
+}
+
+func TestGetTestLog_OutsideProject(t *testing.T) {
+	outside, err := filepath.Abs("testdata/passing_tests.log")
+	if err != nil {
+		t.Fatal(err)
+	}
+	root := t.TempDir()
+	if err := os.Symlink(filepath.Dir(outside), filepath.Join(root, "linked")); err != nil {
+		t.Skipf("symlinks unavailable: %v", err)
+	}
+	h := &Handler{
+		Config: &config.Config{ProjectRoot: root},
+		Logger: testLogger(),
+	}
+
+	// Absolute paths and symlinks out of the project are refused alike.
+	for _, p := range []string{outside, filepath.Join(root, "linked", "passing_tests.log")} {
+		_, _, err := h.GetTestLog(context.Background(), nil, GetTestLogInput{LogPath: p})
+		if err == nil || !strings.Contains(err.Error(), "outside the allowed directories") {
+			t.Errorf("GetTestLog(%s) error = %v, want outside the allowed directories", p, err)
+		}
 	}
 }
 
 func TestGetTestLog_MissingFile(t *testing.T) {
 	h := &Handler{
-		Config: &config.Config{},
+		Config: &config.Config{ProjectRoot: t.TempDir()},
 		Logger: testLogger(),
 	}
 
@@ -10,6 +10,7 @@ import (
 	"path/filepath"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/remiphilippe/mcp-unreal/internal/toolerr"
 )
 
 // --- project_ops ---
@@ -46,6 +47,7 @@ type ProjectOpsInput struct {
 	Type string `json:"type,omitempty" jsonschema:"Module type: Runtime, Editor, Developer, Program. For add_module."`
 	// For set_target_platforms.
 	Platforms []string `json:"platforms,omitempty" jsonschema:"Target platform list (e.g. ['Mac', 'Win64']). For set_target_platforms."`
+	DryRun    bool     `json:"dry_run,omitempty" jsonschema:"For operations that modify the .uproject: return the diff of the change without writing the file"`
 }
 
 // ProjectOpsOutput is returned by the project_ops tool.
@@ -57,6 +59,8 @@ type ProjectOpsOutput struct {
 	Plugins         []UProjectPlugin `json:"plugins,omitempty" jsonschema:"project plugins"`
 	TargetPlatforms []string         `json:"target_platforms,omitempty" jsonschema:"target platforms"`
 	Message         string           `json:"message,omitempty" jsonschema:"status message"`
+	Diff            string           `json:"diff,omitempty" jsonschema:"unified diff of the .uproject change"`
+	DryRun          bool             `json:"dry_run,omitempty" jsonschema:"true when the change was only previewed and the file was not written"`
 }
 
 // RegisterProject adds the project_ops tool to the MCP server.
@@ -67,7 +71,8 @@ func (h *Handler) RegisterProject(server *mcp.Server) {
 			"add modules, and set target platforms. " +
 			"Operations: get_info, list_plugins, enable_plugin, disable_plugin, add_module, set_target_platforms. " +
 			"Does not require the editor to be running — reads/writes the .uproject file directly. " +
-			"Creates a .uproject.bak backup before writing.",
+			"Creates a .uproject.bak backup before writing. Modifying operations return a unified diff " +
+			"of the change; with dry_run=true the file is not written.",
 	}, h.ProjectOps)
 }
 
@@ -75,7 +80,7 @@ func (h *Handler) RegisterProject(server *mcp.Server) {
 func (h *Handler) findUProject() (string, error) {
 	root := h.Config.ProjectRoot
 	if root == "" {
-		return "", fmt.Errorf("project root not configured — set MCP_UNREAL_PROJECT env var")
+		return "", toolerr.New(toolerr.ProjectNotFound, "project root not configured — set MCP_UNREAL_PROJECT env var")
 	}
 
 	// Check if root is directly a .uproject file.
@@ -96,7 +101,7 @@ func (h *Handler) findUProject() (string, error) {
 		}
 	}
 
-	return "", fmt.Errorf("no .uproject file found in %s", root)
+	return "", toolerr.New(toolerr.ProjectNotFound, "no .uproject file found in %s", root)
 }
 
 // readUProject reads and parses the .uproject file.
@@ -112,19 +117,23 @@ func readUProject(path string) (*uprojectRaw, error) {
 	return &proj, nil
 }
 
-// writeUProject writes the .uproject file, creating a backup first.
-func writeUProject(path string, proj *uprojectRaw) error {
-	// Backup.
-	bakPath := path + ".bak"
-	if data, err := os.ReadFile(filepath.Clean(path)); err == nil {
-		_ = os.WriteFile(bakPath, data, 0o600)
-	}
-
+// writeUProject writes the .uproject file, creating a backup first, and
+// returns a unified diff of the change. With dryRun it only returns the
+// diff.
+func writeUProject(path string, proj *uprojectRaw, dryRun bool) (string, error) {
 	data, err := json.MarshalIndent(proj, "", "\t")
 	if err != nil {
-		return fmt.Errorf("marshaling .uproject: %w", err)
+		return "", fmt.Errorf("marshaling .uproject: %w", err)
+	}
+
+	// Backup.
+	if !dryRun {
+		bakPath := path + ".bak"
+		if data, err := os.ReadFile(filepath.Clean(path)); err == nil {
+			_ = os.WriteFile(bakPath, data, 0o600)
+		}
 	}
-	return os.WriteFile(filepath.Clean(path), data, 0o600)
+	return changeFile(path, filepath.Base(path), data, dryRun)
 }
 
 // uprojectRaw is the raw JSON structure for round-trip serialization.
@@ -154,7 +163,7 @@ type uprojectPlugin struct {
 // ProjectOps implements the project_ops tool.
 func (h *Handler) ProjectOps(ctx context.Context, req *mcp.CallToolRequest, input ProjectOpsInput) (*mcp.CallToolResult, ProjectOpsOutput, error) {
 	if input.Operation == "" {
-		return nil, ProjectOpsOutput{}, fmt.Errorf("operation is required")
+		return nil, ProjectOpsOutput{}, toolerr.New(toolerr.Validation, "operation is required")
 	}
 
 	uprojectPath, err := h.findUProject()
@@ -197,7 +206,7 @@ func (h *Handler) ProjectOps(ctx context.Context, req *mcp.CallToolRequest, inpu
 
 	case "enable_plugin":
 		if input.Name == "" {
-			return nil, ProjectOpsOutput{}, fmt.Errorf("name is required for enable_plugin")
+			return nil, ProjectOpsOutput{}, toolerr.New(toolerr.Validation, "name is required for enable_plugin")
 		}
 		found := false
 		for i := range proj.Plugins {
@@ -210,17 +219,20 @@ func (h *Handler) ProjectOps(ctx context.Context, req *mcp.CallToolRequest, inpu
 		if !found {
 			proj.Plugins = append(proj.Plugins, uprojectPlugin{Name: input.Name, Enabled: true})
 		}
-		if err := writeUProject(uprojectPath, proj); err != nil {
+		diff, err := writeUProject(uprojectPath, proj, input.DryRun)
+		if err != nil {
 			return nil, ProjectOpsOutput{}, err
 		}
 		return nil, ProjectOpsOutput{
 			Success: true,
 			Message: fmt.Sprintf("Plugin '%s' enabled", input.Name),
+			Diff:    diff,
+			DryRun:  input.DryRun,
 		}, nil
 
 	case "disable_plugin":
 		if input.Name == "" {
-			return nil, ProjectOpsOutput{}, fmt.Errorf("name is required for disable_plugin")
+			return nil, ProjectOpsOutput{}, toolerr.New(toolerr.Validation, "name is required for disable_plugin")
 		}
 		found := false
 		for i := range proj.Plugins {
@@ -233,17 +245,20 @@ func (h *Handler) ProjectOps(ctx context.Context, req *mcp.CallToolRequest, inpu
 		if !found {
 			proj.Plugins = append(proj.Plugins, uprojectPlugin{Name: input.Name, Enabled: false})
 		}
-		if err := writeUProject(uprojectPath, proj); err != nil {
+		diff, err := writeUProject(uprojectPath, proj, input.DryRun)
+		if err != nil {
 			return nil, ProjectOpsOutput{}, err
 		}
 		return nil, ProjectOpsOutput{
 			Success: true,
 			Message: fmt.Sprintf("Plugin '%s' disabled", input.Name),
+			Diff:    diff,
+			DryRun:  input.DryRun,
 		}, nil
 
 	case "add_module":
 		if input.Name == "" {
-			return nil, ProjectOpsOutput{}, fmt.Errorf("name is required for add_module")
+			return nil, ProjectOpsOutput{}, toolerr.New(toolerr.Validation, "name is required for add_module")
 		}
 		modType := input.Type
 		if modType == "" {
@@ -263,29 +278,35 @@ func (h *Handler) ProjectOps(ctx context.Context, req *mcp.CallToolRequest, inpu
 			Type:         modType,
 			LoadingPhase: "Default",
 		})
-		if err := writeUProject(uprojectPath, proj); err != nil {
+		diff, err := writeUProject(uprojectPath, proj, input.DryRun)
+		if err != nil {
 			return nil, ProjectOpsOutput{}, err
 		}
 		return nil, ProjectOpsOutput{
 			Success: true,
 			Message: fmt.Sprintf("Module '%s' (%s) added", input.Name, modType),
+			Diff:    diff,
+			DryRun:  input.DryRun,
 		}, nil
 
 	case "set_target_platforms":
 		if len(input.Platforms) == 0 {
-			return nil, ProjectOpsOutput{}, fmt.Errorf("platforms array is required for set_target_platforms")
+			return nil, ProjectOpsOutput{}, toolerr.New(toolerr.Validation, "platforms array is required for set_target_platforms")
 		}
 		proj.TargetPlatforms = input.Platforms
-		if err := writeUProject(uprojectPath, proj); err != nil {
+		diff, err := writeUProject(uprojectPath, proj, input.DryRun)
+		if err != nil {
 			return nil, ProjectOpsOutput{}, err
 		}
 		return nil, ProjectOpsOutput{
 			Success:         true,
 			TargetPlatforms: input.Platforms,
 			Message:         "Target platforms updated",
+			Diff:            diff,
+			DryRun:          input.DryRun,
 		}, nil
 
 	default:
-		return nil, ProjectOpsOutput{}, fmt.Errorf("unknown operation: %s", input.Operation)
+		return nil, ProjectOpsOutput{}, toolerr.New(toolerr.Validation, "unknown operation: %s", input.Operation)
 	}
 }
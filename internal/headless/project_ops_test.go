@@ -259,3 +259,28 @@ func TestProjectOps_UnknownOperation(t *testing.T) {
 		t.Fatal("expected error for unknown operation")
 	}
 }
+
+func TestProjectOps_EnablePluginDryRun(t *testing.T) {
+	projFile, h := createTestUProject(t)
+	before, _ := os.ReadFile(projFile) //nolint:gosec // test reads known temp path
+
+	_, out, err := h.ProjectOps(context.Background(), nil, ProjectOpsInput{
+		Operation: "enable_plugin",
+		Name:      "RealtimeMeshComponent",
+		DryRun:    true,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !out.DryRun || !strings.Contains(out.Diff, `+			"Name": "RealtimeMeshComponent",`) {
+		t.Errorf("expected dry-run diff adding the plugin, got:\n%s", out.Diff)
+	}
+
+	after, _ := os.ReadFile(projFile) //nolint:gosec // test reads known temp path
+	if string(after) != string(before) {
+		t.Error("dry run modified the .uproject")
+	}
+	if _, err := os.Stat(projFile + ".bak"); !os.IsNotExist(err) {
+		t.Error("dry run created a .uproject.bak backup")
+	}
+}
@@ -11,6 +11,7 @@ import (
 	"time"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/remiphilippe/mcp-unreal/internal/toolerr"
 )
 
 // --- run_tests ---
@@ -73,7 +74,7 @@ func (h *Handler) RegisterTests(server *mcp.Server) {
 func (h *Handler) RunTests(ctx context.Context, req *mcp.CallToolRequest, input RunTestsInput) (*mcp.CallToolResult, RunTestsOutput, error) {
 	editorPath := h.Config.UEEditorPath
 	if _, err := os.Stat(editorPath); err != nil {
-		return nil, RunTestsOutput{}, fmt.Errorf(
+		return nil, RunTestsOutput{}, toolerr.New(toolerr.UENotInstalled,
 			"UnrealEditor-Cmd not found at %s — set UE_EDITOR_PATH env var or install UE 5.7",
 			editorPath,
 		)
@@ -81,7 +82,7 @@ func (h *Handler) RunTests(ctx context.Context, req *mcp.CallToolRequest, input
 
 	projectFile := h.Config.UProjectFile
 	if projectFile == "" {
-		return nil, RunTestsOutput{}, fmt.Errorf(
+		return nil, RunTestsOutput{}, toolerr.New(toolerr.ProjectNotFound,
 			"no .uproject file found — set MCP_UNREAL_PROJECT or run from inside a UE project directory",
 		)
 	}
@@ -150,7 +151,7 @@ func (h *Handler) RunTests(ctx context.Context, req *mcp.CallToolRequest, input
 func (h *Handler) RunVisualTests(ctx context.Context, req *mcp.CallToolRequest, input RunTestsInput) (*mcp.CallToolResult, RunTestsOutput, error) {
 	editorPath := h.Config.UEEditorPath
 	if _, err := os.Stat(editorPath); err != nil {
-		return nil, RunTestsOutput{}, fmt.Errorf(
+		return nil, RunTestsOutput{}, toolerr.New(toolerr.UENotInstalled,
 			"UnrealEditor-Cmd not found at %s — set UE_EDITOR_PATH env var or install UE 5.7",
 			editorPath,
 		)
@@ -158,7 +159,7 @@ func (h *Handler) RunVisualTests(ctx context.Context, req *mcp.CallToolRequest,
 
 	projectFile := h.Config.UProjectFile
 	if projectFile == "" {
-		return nil, RunTestsOutput{}, fmt.Errorf(
+		return nil, RunTestsOutput{}, toolerr.New(toolerr.ProjectNotFound,
 			"no .uproject file found — set MCP_UNREAL_PROJECT or run from inside a UE project directory",
 		)
 	}
@@ -233,7 +234,7 @@ type ListTestsOutput struct {
 func (h *Handler) ListTests(ctx context.Context, req *mcp.CallToolRequest, input ListTestsInput) (*mcp.CallToolResult, ListTestsOutput, error) {
 	editorPath := h.Config.UEEditorPath
 	if _, err := os.Stat(editorPath); err != nil {
-		return nil, ListTestsOutput{}, fmt.Errorf(
+		return nil, ListTestsOutput{}, toolerr.New(toolerr.UENotInstalled,
 			"UnrealEditor-Cmd not found at %s — set UE_EDITOR_PATH env var or install UE 5.7",
 			editorPath,
 		)
@@ -241,7 +242,7 @@ func (h *Handler) ListTests(ctx context.Context, req *mcp.CallToolRequest, input
 
 	projectFile := h.Config.UProjectFile
 	if projectFile == "" {
-		return nil, ListTestsOutput{}, fmt.Errorf(
+		return nil, ListTestsOutput{}, toolerr.New(toolerr.ProjectNotFound,
 			"no .uproject file found — set MCP_UNREAL_PROJECT or run from inside a UE project directory",
 		)
 	}
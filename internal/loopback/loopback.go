@@ -0,0 +1,120 @@
+// Copyright (c) mcp-unreal project contributors. Apache-2.0 license.
+
+// Package loopback connects in-memory client sessions to the server's
+// own tools. batch, run_workflow, and replay_session run their steps
+// through such a session, so each step passes the same middleware as a
+// call from the MCP client.
+//
+// The server side of every loopback session is marked internal, so the
+// session recorder can skip its calls, which are already recorded as the
+// one call that started them. Calls through a loopback session also
+// carry their nesting depth, so chains such as run_workflow → batch →
+// run_workflow stop at MaxDepth instead of recursing without limit.
+package loopback
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/remiphilippe/mcp-unreal/internal/toolerr"
+)
+
+// MaxDepth bounds how deeply batch, run_workflow, and replay_session
+// calls nest: a replay may run a workflow, whose steps may run a batch,
+// but not deeper.
+const MaxDepth = 3
+
+// depthKey is the _meta key of a loopback call's nesting depth.
+const depthKey = "mcp-unreal/depth"
+
+type depthCtxKey struct{}
+
+// internal holds the server sides of open loopback sessions.
+var internal sync.Map
+
+// Connect returns a new in-memory client session to server and marks its
+// server side internal. name identifies the client, e.g. "batch".
+func Connect(server *mcp.Server, name string) (*mcp.ClientSession, error) {
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	ss, err := server.Connect(context.Background(), serverTransport, nil)
+	if err != nil {
+		return nil, fmt.Errorf("connecting %s session: %w", name, err)
+	}
+	internal.Store(ss, true)
+	go func() {
+		_ = ss.Wait()
+		internal.Delete(ss)
+	}()
+
+	client := mcp.NewClient(&mcp.Implementation{Name: "mcp-unreal-" + name}, nil)
+	cs, err := client.Connect(context.Background(), clientTransport, nil)
+	if err != nil {
+		_ = ss.Close()
+		return nil, fmt.Errorf("connecting %s session: %w", name, err)
+	}
+	return cs, nil
+}
+
+// IsInternal reports whether ss is the server side of a loopback
+// session.
+func IsInternal(ss *mcp.ServerSession) bool {
+	if ss == nil {
+		return false
+	}
+	_, ok := internal.Load(ss)
+	return ok
+}
+
+// Lazy connects one loopback session on first use and shares it after.
+type Lazy struct {
+	once    sync.Once
+	session *mcp.ClientSession
+	err     error
+}
+
+// Get returns the session, connecting it to server on the first call.
+func (l *Lazy) Get(server *mcp.Server, name string) (*mcp.ClientSession, error) {
+	l.once.Do(func() {
+		l.session, l.err = Connect(server, name)
+	})
+	return l.session, l.err
+}
+
+// Enter checks the nesting depth of req, a call to one of the tools that
+// run steps through a loopback session, and returns the context for those
+// steps. It fails with VALIDATION once MaxDepth is reached.
+func Enter(ctx context.Context, req *mcp.CallToolRequest) (context.Context, error) {
+	depth := 0
+	if req != nil {
+		if req.Params != nil {
+			if d, ok := req.Params.Meta[depthKey].(float64); ok {
+				depth = int(d)
+			}
+		}
+		// A loopback call without a depth is at least one level deep.
+		if depth < 1 && IsInternal(req.Session) {
+			depth = 1
+		}
+	}
+	if depth >= MaxDepth {
+		name := "this tool"
+		if req.Params != nil {
+			name = req.Params.Name
+		}
+		return ctx, toolerr.New(toolerr.Validation,
+			"%s is nested %d deep in batch, run_workflow, and replay_session calls; at most %d levels are allowed", name, depth, MaxDepth)
+	}
+	return context.WithValue(ctx, depthCtxKey{}, depth+1), nil
+}
+
+// Params returns the parameters of a call through a loopback session,
+// carrying the nesting depth of ctx from Enter.
+func Params(ctx context.Context, tool string, args any) *mcp.CallToolParams {
+	p := &mcp.CallToolParams{Name: tool, Arguments: args}
+	if depth, ok := ctx.Value(depthCtxKey{}).(int); ok {
+		p.Meta = mcp.Meta{depthKey: depth}
+	}
+	return p
+}
@@ -0,0 +1,202 @@
+// Copyright (c) mcp-unreal project contributors. Apache-2.0 license.
+
+package policy
+
+import (
+	"path"
+	"regexp"
+	"strings"
+)
+
+// builtinClasses is the class of each tool, or of one tool:operation.
+// Tools not listed are Write. For tools with an "operation" argument,
+// operations named like reads (readOperationPrefixes) are Read unless
+// listed here.
+var builtinClasses = map[string]Class{
+	// Server, docs, and headless reads.
+	"status":           Read,
+	"lookup_docs":      Read,
+	"lookup_class":     Read,
+	"lookup_api_diff":  Read,
+	"lookup_examples":  Read,
+	"list_tests":       Read,
+	"get_test_log":     Read,
+	"editor_instances": Read,
+	"audit_query":      Read,
+
+	// Editor reads.
+	"get_level_actors":     Read,
+	"get_actor_components": Read,
+	"get_property":         Read,
+	"search_assets":        Read,
+	"get_asset_info":       Read,
+	"blueprint_query":      Read,
+	"anim_blueprint_query": Read,
+	"get_output_log":       Read,
+	"capture_viewport":     Read, // Write when it saves to output_path
+	"subsystem_query":      Read,
+	"ui_query":             Read,
+	"network_debug":        Read,
+	"wait_for_event":       Read,
+	"undo_history":         Read,
+	"fab_ops:cache_info":   Read,
+
+	// batch, replay_session, and run_workflow run each step through the
+	// policy on its own.
+	"batch":          Read,
+	"replay_session": Read,
+	"run_workflow":   Read,
+
+	// Arbitrary code, hard-to-undo deletes, and project structure.
+	"execute_script":      Dangerous,
+	"run_console_command": Dangerous,
+	"call_function":       Dangerous,
+	"delete_actors":       Dangerous,
+	"fab_ops:clear_cache": Dangerous,
+	"project_ops":         Dangerous,
+}
+
+// readOperationPrefixes mark read operations of multiplexed tools and
+// their plugin endpoints (get_current, list_levels, query_*, status, ...).
+var readOperationPrefixes = []string{"get", "list", "query", "inspect", "find", "search", "status", "describe"}
+
+// IsReadOperation reports whether operation, an operation of a
+// multiplexed tool, is named as a read. The editor client also retries
+// such operations as idempotent.
+func IsReadOperation(operation string) bool {
+	for _, prefix := range readOperationPrefixes {
+		if strings.HasPrefix(operation, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// Classify returns the class of a call to tool with the given operation
+// ("" for none). Policy overrides come first, then the built-in table;
+// an operation-level entry beats a read-named operation, which beats a
+// tool-level entry.
+func (p *Policy) Classify(tool, operation string) Class {
+	lookup := func(name string) (Class, bool) {
+		if c, ok := p.file.Classes[name]; ok {
+			return c, true
+		}
+		c, ok := builtinClasses[name]
+		return c, ok
+	}
+	if operation != "" {
+		if c, ok := lookup(tool + ":" + operation); ok {
+			return c
+		}
+		if IsReadOperation(operation) {
+			return Read
+		}
+	}
+	if c, ok := lookup(tool); ok {
+		return c
+	}
+	return Write
+}
+
+// fileOutputKeys are argument names whose values name a file the call
+// writes its result to, e.g. capture_viewport's output_path.
+var fileOutputKeys = []string{"output_path"}
+
+// ClassifyCall returns the class of c: that of its tool and operation,
+// except that a read which saves its result to a file is a write.
+func (p *Policy) ClassifyCall(c Call) Class {
+	class := p.Classify(c.Tool, c.Operation)
+	if class != Read {
+		return class
+	}
+	for _, key := range fileOutputKeys {
+		if s, _ := c.Args[key].(string); s != "" {
+			return Write
+		}
+	}
+	return class
+}
+
+// targetKeys are argument names whose values name the content a call
+// changes. References to other content, such as the class to spawn or
+// a mesh to assign, are not targets and are not checked.
+var targetKeys = map[string]bool{
+	"asset":               true,
+	"asset_path":          true,
+	"assets":              true,
+	"blueprint_path":      true,
+	"anim_blueprint_path": true,
+	"package_path":        true,
+	"level_path":          true,
+	"map_path":            true,
+	"material_path":       true,
+	"texture_path":        true,
+	"system_path":         true,
+	"graph_path":          true,
+	"destination":         true,
+	"path":                true,
+	"object_path":         true,
+	"actor_path":          true,
+	"actor_paths":         true,
+}
+
+// pieLevelPrefix matches the prefix of a level package duplicated for a
+// play session, e.g. UEDPIE_0_Main.
+var pieLevelPrefix = regexp.MustCompile(`^UEDPIE_\d+_`)
+
+// contentTargets returns the content packages named by target arguments
+// anywhere in args, including nested operation parameters. Object paths
+// such as an actor's are reduced to their package, so an actor counts as
+// its level.
+func contentTargets(args map[string]any) []string {
+	var out []string
+	var walk func(key string, v any)
+	walk = func(key string, v any) {
+		switch v := v.(type) {
+		case map[string]any:
+			for k, e := range v {
+				walk(k, e)
+			}
+		case []any:
+			for _, e := range v {
+				walk(key, e)
+			}
+		case string:
+			if targetKeys[key] {
+				if pkg := contentPackage(v); pkg != "" {
+					out = append(out, pkg)
+				}
+			}
+		}
+	}
+	walk("", args)
+	return out
+}
+
+// contentPackage returns the package of a content or object path
+// ("/Game/Maps/Main.Main:PersistentLevel.Lamp" → "/Game/Maps/Main"), or
+// "" for values that are not content paths, such as /Script classes.
+func contentPackage(v string) string {
+	if !strings.HasPrefix(v, "/") || strings.HasPrefix(v, "/Script/") {
+		return ""
+	}
+	if i := strings.IndexAny(v, ".:"); i >= 0 {
+		v = v[:i]
+	}
+	v = strings.TrimSuffix(v, "/")
+	dir, name := path.Split(v)
+	return dir + pieLevelPrefix.ReplaceAllString(name, "")
+}
+
+// configTarget returns the .ini file a config_ops call changes, e.g.
+// "DefaultGame.ini", or "" for other calls.
+func configTarget(c Call) string {
+	if c.Tool != "config_ops" {
+		return ""
+	}
+	file, _ := c.Args["file"].(string)
+	if file == "" {
+		return ""
+	}
+	return strings.TrimSuffix(file, ".ini") + ".ini"
+}
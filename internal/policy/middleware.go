@@ -0,0 +1,61 @@
+// Copyright (c) mcp-unreal project contributors. Apache-2.0 license.
+
+package policy
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"slices"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Middleware returns MCP receiving middleware that checks every
+// tools/call against the policy before any handler runs, and drops tools
+// the policy denies outright from tools/list. Install it first in
+// server.AddReceivingMiddleware so it is the outermost layer.
+func (p *Policy) Middleware(logger *slog.Logger) mcp.Middleware {
+	return func(next mcp.MethodHandler) mcp.MethodHandler {
+		return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+			switch r := req.(type) {
+			case *mcp.CallToolRequest:
+				if r.Params == nil {
+					break
+				}
+				if err := p.Check(NewCall(r.Params.Name, r.Params.Arguments)); err != nil {
+					logger.Warn("tool call denied by policy", "tool", r.Params.Name, "error", err)
+					res := &mcp.CallToolResult{}
+					res.SetError(err)
+					return res, nil
+				}
+			case *mcp.ListToolsRequest:
+				res, err := next(ctx, method, req)
+				if list, ok := res.(*mcp.ListToolsResult); ok && err == nil {
+					list.Tools = slices.DeleteFunc(list.Tools, func(t *mcp.Tool) bool {
+						return !p.Listed(t.Name, HasProperty(t, "operation"))
+					})
+				}
+				return res, err
+			}
+			return next(ctx, method, req)
+		}
+	}
+}
+
+// HasProperty reports whether a tool's input schema has the top-level
+// property name, e.g. "operation" or "instance".
+func HasProperty(t *mcp.Tool, name string) bool {
+	data, err := json.Marshal(t.InputSchema)
+	if err != nil {
+		return false
+	}
+	var schema struct {
+		Properties map[string]json.RawMessage `json:"properties"`
+	}
+	if json.Unmarshal(data, &schema) != nil {
+		return false
+	}
+	_, ok := schema.Properties[name]
+	return ok
+}
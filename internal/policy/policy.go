@@ -0,0 +1,344 @@
+// Copyright (c) mcp-unreal project contributors. Apache-2.0 license.
+
+// Package policy decides which tool calls the server accepts. Every tool
+// and operation has a class — read, write, or dangerous — from a built-in
+// table that a per-project policy file can override. The policy can make
+// the server read-only, deny whole classes, allow or deny tools by name,
+// and restrict which /Game content and which .ini files writes may touch.
+//
+// The policy is enforced by Middleware on every tools/call before the
+// tool's handler runs, and tools it denies outright are left out of
+// tools/list.
+package policy
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/remiphilippe/mcp-unreal/internal/toolerr"
+)
+
+// DefaultFileName is the policy file looked up in the project root when
+// MCP_UNREAL_POLICY is not set. It is meant to be checked in.
+const DefaultFileName = "mcp-unreal.policy.json"
+
+// Class is the risk class of a tool call.
+type Class string
+
+// Tool call classes, from least to most risky.
+const (
+	// Read calls do not change the project or the editor.
+	Read Class = "read"
+	// Write calls change editor or project state in ways that can be
+	// reviewed or undone.
+	Write Class = "write"
+	// Dangerous calls run arbitrary code or make changes that are hard
+	// to undo: scripts, console commands, deletes, project structure.
+	Dangerous Class = "dangerous"
+)
+
+func (c Class) valid() bool {
+	return c == Read || c == Write || c == Dangerous
+}
+
+// File is the policy file format. Patterns in Allow, Deny, and Classes
+// name a tool ("execute_script") or one operation of a tool
+// ("fab_ops:clear_cache"), and may use path.Match wildcards
+// ("lookup_*", "*:delete_*").
+type File struct {
+	// ReadOnly denies every write and dangerous call.
+	ReadOnly bool `json:"read_only,omitempty"`
+	// DenyClasses denies every call of the listed classes, e.g.
+	// ["dangerous"].
+	DenyClasses []Class `json:"deny_classes,omitempty"`
+	// Allow, if not empty, is the only tools and operations accepted.
+	Allow []string `json:"allow,omitempty"`
+	// Deny lists tools and operations that are refused. Deny wins over
+	// Allow.
+	Deny []string `json:"deny,omitempty"`
+	// Classes overrides the built-in class of tools and operations.
+	Classes map[string]Class `json:"classes,omitempty"`
+	// ContentPaths, if not empty, are the only content packages that
+	// write and dangerous calls may name as their target, as globs
+	// where "**" spans folders: ["/Game/Sandbox/**", "/Game/Maps/Test*"].
+	ContentPaths []string `json:"content_paths,omitempty"`
+	// ConfigFiles, if not empty, are the only Config/ .ini files that
+	// config_ops may change, as globs: ["DefaultGame.ini", "Default*.ini"].
+	ConfigFiles []string `json:"config_files,omitempty"`
+}
+
+// Policy is a loaded policy. The zero value allows everything.
+type Policy struct {
+	file   File
+	source string
+}
+
+// New returns a policy for f after checking its classes and patterns.
+// source describes where f came from, for status and error messages.
+func New(f File, source string) (*Policy, error) {
+	for _, c := range f.DenyClasses {
+		if !c.valid() {
+			return nil, fmt.Errorf("deny_classes: unknown class %q (use read, write, or dangerous)", c)
+		}
+	}
+	for name, c := range f.Classes {
+		if !c.valid() {
+			return nil, fmt.Errorf("classes[%q]: unknown class %q (use read, write, or dangerous)", name, c)
+		}
+		if err := checkPattern(name); err != nil {
+			return nil, fmt.Errorf("classes: %w", err)
+		}
+	}
+	for _, list := range []struct {
+		name     string
+		patterns []string
+	}{
+		{"allow", f.Allow}, {"deny", f.Deny}, {"content_paths", f.ContentPaths}, {"config_files", f.ConfigFiles},
+	} {
+		for _, p := range list.patterns {
+			if err := checkPattern(p); err != nil {
+				return nil, fmt.Errorf("%s: %w", list.name, err)
+			}
+		}
+	}
+	return &Policy{file: f, source: source}, nil
+}
+
+func checkPattern(p string) error {
+	if p == "" {
+		return fmt.Errorf("empty pattern")
+	}
+	if _, err := path.Match(p, ""); err != nil {
+		return fmt.Errorf("invalid pattern %q: %w", p, err)
+	}
+	return nil
+}
+
+// Load reads the policy file at path. Unknown fields are an error, so a
+// misspelled rule does not silently allow what it meant to deny.
+func Load(path string) (*Policy, error) {
+	data, err := os.ReadFile(path) //nolint:gosec // operator-supplied policy path
+	if err != nil {
+		return nil, fmt.Errorf("reading policy: %w", err)
+	}
+	var f File
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&f); err != nil {
+		return nil, fmt.Errorf("parsing policy %s: %w", path, err)
+	}
+	p, err := New(f, path)
+	if err != nil {
+		return nil, fmt.Errorf("policy %s: %w", path, err)
+	}
+	return p, nil
+}
+
+// Find loads the policy at explicit, or else DefaultFileName in
+// projectRoot if it exists. With neither it returns a policy that allows
+// everything.
+func Find(explicit, projectRoot string) (*Policy, error) {
+	if explicit != "" {
+		return Load(explicit)
+	}
+	if projectRoot != "" {
+		def := filepath.Join(projectRoot, DefaultFileName)
+		if _, err := os.Stat(def); err == nil {
+			return Load(def)
+		}
+	}
+	return &Policy{source: "default"}, nil
+}
+
+// SetReadOnly turns read-only mode on, e.g. for --read-only. A policy
+// file cannot turn it off again.
+func (p *Policy) SetReadOnly() {
+	p.file.ReadOnly = true
+}
+
+// Summary describes the active policy for the status tool.
+type Summary struct {
+	Source      string  `json:"source" jsonschema:"policy file path, or default when none is loaded"`
+	ReadOnly    bool    `json:"read_only" jsonschema:"whether write and dangerous tools are refused"`
+	DenyClasses []Class `json:"deny_classes,omitempty" jsonschema:"classes of tool calls that are refused"`
+	Restricted  bool    `json:"restricted,omitempty" jsonschema:"whether allow/deny lists or content and config path globs apply"`
+}
+
+// Summary returns a description of the policy.
+func (p *Policy) Summary() Summary {
+	f := p.file
+	return Summary{
+		Source:      p.source,
+		ReadOnly:    f.ReadOnly,
+		DenyClasses: f.DenyClasses,
+		Restricted:  len(f.Allow)+len(f.Deny)+len(f.ContentPaths)+len(f.ConfigFiles) > 0,
+	}
+}
+
+// Call is a tool call to check.
+type Call struct {
+	Tool string
+	// Operation is the call's "operation" argument, if any.
+	Operation string
+	// Args are the decoded call arguments.
+	Args map[string]any
+}
+
+// NewCall decodes raw tools/call arguments into a Call.
+func NewCall(tool string, raw json.RawMessage) Call {
+	c := Call{Tool: tool}
+	if len(raw) > 0 {
+		_ = json.Unmarshal(raw, &c.Args)
+	}
+	c.Operation, _ = c.Args["operation"].(string)
+	return c
+}
+
+// name returns "tool" or "tool:operation".
+func (c Call) name() string {
+	if c.Operation == "" {
+		return c.Tool
+	}
+	return c.Tool + ":" + c.Operation
+}
+
+// DeniedError reports a call refused by the policy.
+type DeniedError struct {
+	Call   string
+	Class  Class
+	Reason string
+}
+
+// deniedPrefix starts every DeniedError message.
+const deniedPrefix = "policy denies "
+
+func (e *DeniedError) Error() string {
+	return fmt.Sprintf("%s%s (%s): %s", deniedPrefix, e.Call, e.Class, e.Reason)
+}
+
+// ErrorCode implements toolerr.Coder.
+func (e *DeniedError) ErrorCode() toolerr.Code { return toolerr.PolicyDenied }
+
+// IsDenial reports whether msg, the error text of a tool result, is a
+// policy denial.
+func IsDenial(msg string) bool {
+	return strings.HasPrefix(msg, deniedPrefix)
+}
+
+// Check returns a *DeniedError if the policy refuses c, or nil.
+func (p *Policy) Check(c Call) error {
+	f := p.file
+	class := p.ClassifyCall(c)
+	deny := func(format string, args ...any) error {
+		return &DeniedError{Call: c.name(), Class: class, Reason: fmt.Sprintf(format, args...)}
+	}
+
+	if pat, ok := matchCall(f.Deny, c); ok {
+		return deny("matches deny rule %q", pat)
+	}
+	if len(f.Allow) > 0 {
+		if _, ok := matchCall(f.Allow, c); !ok {
+			return deny("not in the allow list")
+		}
+	}
+	if class == Read {
+		return nil
+	}
+	if f.ReadOnly {
+		return deny("the server is read-only")
+	}
+	if slices.Contains(f.DenyClasses, class) {
+		return deny("%s calls are denied", class)
+	}
+	if len(f.ContentPaths) > 0 {
+		for _, pkg := range contentTargets(c.Args) {
+			if !matchAny(f.ContentPaths, pkg) {
+				return deny("%s is outside the writable content paths %v", pkg, f.ContentPaths)
+			}
+		}
+	}
+	if len(f.ConfigFiles) > 0 {
+		if ini := configTarget(c); ini != "" && !matchAny(f.ConfigFiles, ini) {
+			return deny("Config/%s is not in the writable config files %v", ini, f.ConfigFiles)
+		}
+	}
+	return nil
+}
+
+// Listed reports whether tools/list should show tool: false only when
+// every call of it would be refused regardless of its arguments. ops
+// says whether the tool takes an "operation" argument, in which case
+// some of its operations may still be allowed.
+func (p *Policy) Listed(tool string, ops bool) bool {
+	f := p.file
+	c := Call{Tool: tool}
+	if _, ok := matchCall(f.Deny, c); ok {
+		return false
+	}
+	if len(f.Allow) > 0 && !slices.ContainsFunc(f.Allow, func(pat string) bool {
+		toolPat, _, _ := strings.Cut(pat, ":")
+		ok, _ := path.Match(toolPat, tool)
+		return ok
+	}) {
+		return false
+	}
+	if ops {
+		return true
+	}
+	class := p.Classify(tool, "")
+	return class == Read || (!f.ReadOnly && !slices.Contains(f.DenyClasses, class))
+}
+
+// matchCall returns the first pattern matching the call's tool or its
+// tool:operation name.
+func matchCall(patterns []string, c Call) (string, bool) {
+	for _, pat := range patterns {
+		if ok, _ := path.Match(pat, c.Tool); ok {
+			return pat, true
+		}
+		if c.Operation != "" {
+			if ok, _ := path.Match(pat, c.name()); ok {
+				return pat, true
+			}
+		}
+	}
+	return "", false
+}
+
+func matchAny(patterns []string, name string) bool {
+	return slices.ContainsFunc(patterns, func(p string) bool { return matchGlob(p, name) })
+}
+
+// matchGlob matches a slash-separated name against a pattern in which
+// "**" matches any number of path segments and other segments follow
+// path.Match.
+func matchGlob(pattern, name string) bool {
+	return matchSegments(strings.Split(pattern, "/"), strings.Split(name, "/"))
+}
+
+func matchSegments(pat, name []string) bool {
+	for len(pat) > 0 {
+		if pat[0] == "**" {
+			for i := 0; i <= len(name); i++ {
+				if matchSegments(pat[1:], name[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(name) == 0 {
+			return false
+		}
+		if ok, _ := path.Match(pat[0], name[0]); !ok {
+			return false
+		}
+		pat, name = pat[1:], name[1:]
+	}
+	return len(name) == 0
+}
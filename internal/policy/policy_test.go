@@ -0,0 +1,304 @@
+// Copyright (c) mcp-unreal project contributors. Apache-2.0 license.
+
+package policy
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestClassify(t *testing.T) {
+	p, err := New(File{Classes: map[string]Class{
+		"run_console_command":  Write,
+		"level_ops:save_level": Dangerous,
+	}}, "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		tool, op string
+		want     Class
+	}{
+		{"status", "", Read},
+		{"get_level_actors", "", Read},
+		{"spawn_actor", "", Write},
+		{"execute_script", "", Dangerous},
+		{"delete_actors", "", Dangerous},
+		{"level_ops", "list_levels", Read},
+		{"level_ops", "load_level", Write},
+		{"fab_ops", "cache_info", Read},
+		{"fab_ops", "clear_cache", Dangerous},
+		{"project_ops", "get_info", Read},
+		{"project_ops", "enable_plugin", Dangerous},
+		{"config_ops", "list_sections", Read},
+		{"config_ops", "set", Write},
+		// Policy overrides.
+		{"run_console_command", "", Write},
+		{"level_ops", "save_level", Dangerous},
+	}
+	for _, tt := range tests {
+		if got := p.Classify(tt.tool, tt.op); got != tt.want {
+			t.Errorf("Classify(%q, %q) = %s, want %s", tt.tool, tt.op, got, tt.want)
+		}
+	}
+}
+
+func TestCheck(t *testing.T) {
+	tests := []struct {
+		name   string
+		file   File
+		tool   string
+		args   string
+		denied string // substring of the denial reason, "" if allowed
+	}{
+		{"default allows dangerous", File{}, "execute_script", `{"script":"print(1)"}`, ""},
+		{"read-only allows reads", File{ReadOnly: true}, "get_level_actors", `{}`, ""},
+		{"read-only allows read ops", File{ReadOnly: true}, "level_ops", `{"operation":"get_current"}`, ""},
+		{"read-only allows capture", File{ReadOnly: true}, "capture_viewport", `{"world":"pie"}`, ""},
+		{"read-only denies capture to file", File{ReadOnly: true}, "capture_viewport", `{"output_path":"Saved/shot.png"}`, "read-only"},
+		{"deny writes denies capture to file", File{DenyClasses: []Class{Write}}, "capture_viewport", `{"output_path":"Saved/shot.png"}`, "write calls are denied"},
+		{"read-only denies writes", File{ReadOnly: true}, "spawn_actor", `{"class_name":"PointLight"}`, "read-only"},
+		{"read-only denies write ops", File{ReadOnly: true}, "config_ops", `{"operation":"set","file":"DefaultGame"}`, "read-only"},
+		{"deny class", File{DenyClasses: []Class{Dangerous}}, "fab_ops", `{"operation":"clear_cache"}`, "dangerous calls are denied"},
+		{"deny class keeps other ops", File{DenyClasses: []Class{Dangerous}}, "fab_ops", `{"operation":"search"}`, ""},
+		{"deny tool", File{Deny: []string{"execute_script"}}, "execute_script", `{}`, `deny rule "execute_script"`},
+		{"deny op pattern", File{Deny: []string{"*:delete*"}}, "config_ops", `{"operation":"delete","file":"DefaultGame"}`, `deny rule "*:delete*"`},
+		{"deny wins over allow", File{Allow: []string{"lookup_*"}, Deny: []string{"lookup_docs"}}, "lookup_docs", `{}`, "deny rule"},
+		{"allow list", File{Allow: []string{"lookup_*", "status"}}, "lookup_class", `{}`, ""},
+		{"not in allow list", File{Allow: []string{"lookup_*", "status"}}, "spawn_actor", `{}`, "allow list"},
+		{"allow op", File{Allow: []string{"level_ops:get_*"}}, "level_ops", `{"operation":"get_current"}`, ""},
+		{"allow op only", File{Allow: []string{"level_ops:get_*"}}, "level_ops", `{"operation":"load_level"}`, "allow list"},
+		{"content path allowed", File{ContentPaths: []string{"/Game/Sandbox/**"}}, "material_ops", `{"operation":"create","asset_path":"/Game/Sandbox/M/M_Test"}`, ""},
+		{"content path denied", File{ContentPaths: []string{"/Game/Sandbox/**"}}, "material_ops", `{"operation":"create","asset_path":"/Game/Core/M_Base"}`, "/Game/Core/M_Base is outside"},
+		{"content path on read", File{ContentPaths: []string{"/Game/Sandbox/**"}}, "blueprint_query", `{"blueprint_path":"/Game/Core/BP_Door"}`, ""},
+		{"content path ignores class refs", File{ContentPaths: []string{"/Game/Sandbox/**"}}, "spawn_actor", `{"class_name":"/Game/Core/BP_Door.BP_Door_C"}`, ""},
+		{"content path ignores script", File{ContentPaths: []string{"/Game/Sandbox/**"}}, "set_property", `{"object_path":"/Script/Engine.Default__Engine"}`, ""},
+		{"actor counts as its level", File{ContentPaths: []string{"/Game/Maps/Test*"}}, "move_actor", `{"actor_path":"/Game/Maps/TestMap.TestMap:PersistentLevel.Lamp"}`, ""},
+		{"PIE actor counts as its level", File{ContentPaths: []string{"/Game/Maps/Test*"}}, "set_property", `{"object_path":"/Game/Maps/UEDPIE_0_TestMap.TestMap:PersistentLevel.Lamp"}`, ""},
+		{"actor in other level", File{ContentPaths: []string{"/Game/Maps/Test*"}}, "delete_actors", `{"actor_paths":["/Game/Maps/TestMap.TestMap:PersistentLevel.A","/Game/Maps/Main.Main:PersistentLevel.B"]}`, "/Game/Maps/Main is outside"},
+		{"nested target", File{ContentPaths: []string{"/Game/Sandbox/**"}}, "data_asset_ops", `{"operation":"create","params":{"package_path":"/Game/Core"}}`, "/Game/Core is outside"},
+		{"config file allowed", File{ConfigFiles: []string{"DefaultGame.ini"}}, "config_ops", `{"operation":"set","file":"DefaultGame"}`, ""},
+		{"config file denied", File{ConfigFiles: []string{"DefaultGame.ini"}}, "config_ops", `{"operation":"set","file":"DefaultEngine"}`, "Config/DefaultEngine.ini"},
+		{"config file read", File{ConfigFiles: []string{"DefaultGame.ini"}}, "config_ops", `{"operation":"get","file":"DefaultEngine"}`, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := New(tt.file, "test")
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = p.Check(NewCall(tt.tool, json.RawMessage(tt.args)))
+			if tt.denied == "" {
+				if err != nil {
+					t.Fatalf("Check: %v", err)
+				}
+				return
+			}
+			var denied *DeniedError
+			if !errors.As(err, &denied) {
+				t.Fatalf("Check = %v, want a DeniedError", err)
+			}
+			if !strings.Contains(err.Error(), tt.denied) {
+				t.Errorf("Check = %q, want it to contain %q", err, tt.denied)
+			}
+		})
+	}
+}
+
+func TestMatchGlob(t *testing.T) {
+	tests := []struct {
+		pattern, name string
+		want          bool
+	}{
+		{"/Game/Sandbox/**", "/Game/Sandbox/A", true},
+		{"/Game/Sandbox/**", "/Game/Sandbox/A/B/C", true},
+		{"/Game/Sandbox/**", "/Game/Sandbox", true},
+		{"/Game/Sandbox/**", "/Game/SandboxOther/A", false},
+		{"/Game/*/Textures/**", "/Game/Env/Textures/T_Rock", true},
+		{"/Game/*/Textures/**", "/Game/Env/Meshes/SM_Rock", false},
+		{"/Game/**/T_*", "/Game/Env/Sub/T_Rock", true},
+		{"Default*.ini", "DefaultGame.ini", true},
+		{"Default*.ini", "BaseGame.ini", false},
+	}
+	for _, tt := range tests {
+		if got := matchGlob(tt.pattern, tt.name); got != tt.want {
+			t.Errorf("matchGlob(%q, %q) = %v, want %v", tt.pattern, tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{"valid", `{"read_only": true, "deny": ["execute_script"], "classes": {"pie_control": "read"}}`, ""},
+		{"unknown field", `{"readonly": true}`, "unknown field"},
+		{"bad class", `{"deny_classes": ["risky"]}`, `unknown class "risky"`},
+		{"bad override", `{"classes": {"spawn_actor": "safe"}}`, `unknown class "safe"`},
+		{"bad pattern", `{"allow": ["lookup_["]}`, "invalid pattern"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(dir, tt.name+".json")
+			if err := os.WriteFile(path, []byte(tt.content), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			_, err := Load(path)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("Load: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Load = %v, want error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestFind(t *testing.T) {
+	root := t.TempDir()
+	p, err := Find("", root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := p.Summary().Source; got != "default" {
+		t.Errorf("without a file, source = %q, want default", got)
+	}
+
+	def := filepath.Join(root, DefaultFileName)
+	if err := os.WriteFile(def, []byte(`{"read_only": true}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	p, err = Find("", root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sum := p.Summary(); sum.Source != def || !sum.ReadOnly {
+		t.Errorf("project file: summary = %+v", sum)
+	}
+
+	if _, err := Find(filepath.Join(root, "missing.json"), root); err == nil {
+		t.Error("missing explicit policy file: want error")
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	p, err := New(File{ReadOnly: true, Deny: []string{"secret"}}, "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := mcp.NewServer(&mcp.Implementation{Name: "test", Version: "0"}, nil)
+	server.AddReceivingMiddleware(p.Middleware(slog.New(slog.NewTextHandler(io.Discard, nil))))
+
+	type in struct {
+		Operation string `json:"operation,omitempty"`
+	}
+	type out struct {
+		Ran bool `json:"ran"`
+	}
+	ran := map[string]bool{}
+	handler := func(name string) mcp.ToolHandlerFor[in, out] {
+		return func(ctx context.Context, req *mcp.CallToolRequest, input in) (*mcp.CallToolResult, out, error) {
+			ran[name] = true
+			return nil, out{Ran: true}, nil
+		}
+	}
+	mcp.AddTool(server, &mcp.Tool{Name: "get_level_actors"}, handler("get_level_actors"))
+	mcp.AddTool(server, &mcp.Tool{Name: "spawn_actor"}, handler("spawn_actor"))
+	mcp.AddTool(server, &mcp.Tool{Name: "level_ops"}, handler("level_ops"))
+	mcp.AddTool(server, &mcp.Tool{Name: "secret"}, handler("secret"))
+
+	ctx := context.Background()
+	st, ct := mcp.NewInMemoryTransports()
+	if _, err := server.Connect(ctx, st, nil); err != nil {
+		t.Fatal(err)
+	}
+	client := mcp.NewClient(&mcp.Implementation{Name: "client", Version: "0"}, nil)
+	session, err := client.Connect(ctx, ct, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = session.Close() }()
+
+	tools, err := session.ListTools(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, tool := range tools.Tools {
+		names = append(names, tool.Name)
+	}
+	// Every test tool takes an operation, so only the denied one is
+	// hidden; read-only still allows their read operations.
+	if slices.Contains(names, "secret") || len(names) != 3 {
+		t.Errorf("tools/list = %v, want secret hidden", names)
+	}
+
+	calls := []struct {
+		tool, op string
+		allowed  bool
+	}{
+		{"get_level_actors", "", true},
+		{"spawn_actor", "", false},
+		{"level_ops", "list_levels", true},
+		{"level_ops", "load_level", false},
+		{"secret", "", false},
+	}
+	for _, c := range calls {
+		clear(ran)
+		args := map[string]any{}
+		if c.op != "" {
+			args["operation"] = c.op
+		}
+		res, err := session.CallTool(ctx, &mcp.CallToolParams{Name: c.tool, Arguments: args})
+		if err != nil {
+			t.Fatalf("%s %s: %v", c.tool, c.op, err)
+		}
+		if res.IsError == c.allowed || ran[c.tool] != c.allowed {
+			t.Errorf("%s %s: IsError=%v ran=%v, want allowed=%v", c.tool, c.op, res.IsError, ran[c.tool], c.allowed)
+		}
+		if !c.allowed {
+			text := res.Content[0].(*mcp.TextContent).Text
+			if !strings.Contains(text, "policy denies") {
+				t.Errorf("%s %s: error %q does not name the policy", c.tool, c.op, text)
+			}
+		}
+	}
+}
+
+func TestListed(t *testing.T) {
+	p, err := New(File{ReadOnly: true, Allow: []string{"level_ops:get_*", "spawn_actor", "lookup_*"}}, "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		tool string
+		ops  bool
+		want bool
+	}{
+		{"lookup_docs", false, true},
+		{"level_ops", true, true},
+		{"spawn_actor", false, false}, // allowed by name but read-only
+		{"material_ops", true, false}, // not in the allow list
+	}
+	for _, tt := range tests {
+		if got := p.Listed(tt.tool, tt.ops); got != tt.want {
+			t.Errorf("Listed(%q) = %v, want %v", tt.tool, got, tt.want)
+		}
+	}
+}
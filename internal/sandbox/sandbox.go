@@ -0,0 +1,146 @@
+// Copyright (c) mcp-unreal project contributors. Apache-2.0 license.
+
+// Package sandbox confines the files that tool arguments can name. A path
+// is allowed only if, after resolving symlinks, it lies in the project
+// root, the engine directory, or an extra root from
+// MCP_UNREAL_EXTRA_ROOTS. Every tool input that names a file on disk is
+// resolved through a Sandbox before it is read, written, or passed to the
+// editor (CLAUDE.md Security §4).
+package sandbox
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/remiphilippe/mcp-unreal/internal/config"
+	"github.com/remiphilippe/mcp-unreal/internal/toolerr"
+)
+
+// Root is a directory that tool paths may point into.
+type Root struct {
+	Name string `json:"name" jsonschema:"project, engine, or extra"`
+	Path string `json:"path" jsonschema:"directory, with symlinks resolved"`
+}
+
+// Sandbox resolves tool paths and refuses those outside its roots.
+type Sandbox struct {
+	project string
+	roots   []Root
+}
+
+// New returns the sandbox for cfg: the project root, the Engine directory
+// containing the configured editor binary, and cfg.ExtraRoots. Roots that
+// are unset are left out.
+func New(cfg *config.Config) *Sandbox {
+	s := &Sandbox{}
+	add := func(name, dir string) {
+		if dir == "" {
+			return
+		}
+		abs, err := filepath.Abs(dir)
+		if err != nil {
+			return
+		}
+		s.roots = append(s.roots, Root{Name: name, Path: resolve(abs)})
+	}
+	add("project", cfg.ProjectRoot)
+	add("engine", engineDir(cfg.UEEditorPath))
+	for _, dir := range cfg.ExtraRoots {
+		add("extra", dir)
+	}
+	if len(s.roots) > 0 && s.roots[0].Name == "project" {
+		s.project = s.roots[0].Path
+	}
+	return s
+}
+
+// Roots returns the allowed directories.
+func (s *Sandbox) Roots() []Root {
+	return append([]Root(nil), s.roots...)
+}
+
+// Resolve checks the path given for argument arg and returns it absolute,
+// cleaned, and with symlinks resolved, ready to use. Relative paths are
+// taken from the project root. The file need not exist; its nearest
+// existing parent is resolved instead.
+func (s *Sandbox) Resolve(arg, path string) (string, error) {
+	if path == "" {
+		return "", toolerr.New(toolerr.Validation, "%s is empty", arg)
+	}
+	if !filepath.IsAbs(path) {
+		if s.project == "" {
+			return "", toolerr.New(toolerr.PathRefused, "%s %q is relative but no UE project root is set — use an absolute path or set MCP_UNREAL_PROJECT", arg, path)
+		}
+		path = filepath.Join(s.project, path)
+	}
+	resolved := resolve(filepath.Clean(path))
+	for _, r := range s.roots {
+		if within(r.Path, resolved) {
+			return resolved, nil
+		}
+	}
+	return "", s.outside(arg, path, resolved)
+}
+
+// Within reports whether a path returned by Resolve lies in dir, for
+// callers that confine a path further than the sandbox, e.g. to Config/.
+func Within(dir, resolved string) bool {
+	abs, err := filepath.Abs(dir)
+	return err == nil && within(resolve(filepath.Clean(abs)), resolved)
+}
+
+func (s *Sandbox) outside(arg, path, resolved string) error {
+	if len(s.roots) == 0 {
+		return toolerr.New(toolerr.PathRefused, "%s %q refused: no allowed directories are configured — set MCP_UNREAL_PROJECT or MCP_UNREAL_EXTRA_ROOTS", arg, path)
+	}
+	var dirs []string
+	for _, r := range s.roots {
+		dirs = append(dirs, r.Name+" "+r.Path)
+	}
+	via := ""
+	if resolved != filepath.Clean(path) {
+		via = " (resolves to " + resolved + ")"
+	}
+	return toolerr.New(toolerr.PathRefused, "%s %q%s is outside the allowed directories (%s) — add its directory to MCP_UNREAL_EXTRA_ROOTS to allow it",
+		arg, path, via, strings.Join(dirs, ", "))
+}
+
+// resolve evaluates the symlinks of an absolute, clean path. For a path
+// that does not exist yet (or cannot be resolved), the nearest resolvable
+// ancestor is resolved and the rest appended.
+func resolve(path string) string {
+	var rest []string
+	for dir := path; ; {
+		if real, err := filepath.EvalSymlinks(dir); err == nil {
+			return filepath.Join(append([]string{real}, rest...)...)
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return path
+		}
+		rest = append([]string{filepath.Base(dir)}, rest...)
+		dir = parent
+	}
+}
+
+// within reports whether path is root or inside it. Both are resolved.
+func within(root, path string) bool {
+	rel, err := filepath.Rel(root, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) && !filepath.IsAbs(rel)
+}
+
+// engineDir returns the Engine directory that contains the editor
+// binary (…/Engine/Binaries/Mac/UnrealEditor-Cmd), or "".
+func engineDir(editorPath string) string {
+	if editorPath == "" || !filepath.IsAbs(editorPath) {
+		return ""
+	}
+	for dir := filepath.Dir(editorPath); ; dir = filepath.Dir(dir) {
+		if filepath.Base(dir) == "Engine" {
+			return dir
+		}
+		if filepath.Dir(dir) == dir {
+			return ""
+		}
+	}
+}
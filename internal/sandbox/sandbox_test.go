@@ -0,0 +1,140 @@
+// Copyright (c) mcp-unreal project contributors. Apache-2.0 license.
+
+package sandbox
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/remiphilippe/mcp-unreal/internal/config"
+)
+
+// tempDir returns a temporary directory with symlinks resolved.
+func tempDir(t *testing.T) string {
+	t.Helper()
+	dir, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+func TestNew_Roots(t *testing.T) {
+	project, extra := tempDir(t), tempDir(t)
+	s := New(&config.Config{
+		ProjectRoot:  project,
+		UEEditorPath: "/Users/Shared/Epic Games/UE_5.7/Engine/Binaries/Mac/UnrealEditor-Cmd",
+		ExtraRoots:   []string{extra},
+	})
+	roots := s.Roots()
+	if len(roots) != 3 {
+		t.Fatalf("roots = %+v", roots)
+	}
+	want := []Root{
+		{Name: "project", Path: project},
+		{Name: "engine", Path: "/Users/Shared/Epic Games/UE_5.7/Engine"},
+		{Name: "extra", Path: extra},
+	}
+	for i, r := range want {
+		if roots[i] != r {
+			t.Errorf("roots[%d] = %+v, want %+v", i, roots[i], r)
+		}
+	}
+}
+
+func TestResolve(t *testing.T) {
+	project := tempDir(t)
+	s := New(&config.Config{ProjectRoot: project})
+
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"Saved/Logs/test.log", filepath.Join(project, "Saved", "Logs", "test.log")},
+		{filepath.Join(project, "Content", "a.png"), filepath.Join(project, "Content", "a.png")},
+		{filepath.Join(project, "Saved", "..", "Config"), filepath.Join(project, "Config")},
+		{project, project},
+	}
+	for _, tt := range tests {
+		got, err := s.Resolve("path", tt.path)
+		if err != nil {
+			t.Errorf("Resolve(%q): %v", tt.path, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("Resolve(%q) = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestResolve_Refused(t *testing.T) {
+	project, outside := tempDir(t), tempDir(t)
+	if err := os.Symlink(outside, filepath.Join(project, "escape")); err != nil {
+		t.Fatal(err)
+	}
+	s := New(&config.Config{ProjectRoot: project})
+
+	for _, path := range []string{
+		"",
+		"../secret.txt",
+		filepath.Join(outside, "secret.txt"),
+		filepath.Join(project, "escape", "secret.txt"),
+		project + "-sibling/file",
+	} {
+		if got, err := s.Resolve("path", path); err == nil {
+			t.Errorf("Resolve(%q) = %q, want error", path, got)
+		}
+	}
+
+	_, err := s.Resolve("log_path", filepath.Join(project, "escape", "secret.txt"))
+	if err == nil || !strings.Contains(err.Error(), "log_path") || !strings.Contains(err.Error(), "resolves to") ||
+		!strings.Contains(err.Error(), "MCP_UNREAL_EXTRA_ROOTS") {
+		t.Errorf("symlink escape error = %v", err)
+	}
+}
+
+func TestResolve_ExtraRoot(t *testing.T) {
+	project, extra := tempDir(t), tempDir(t)
+	s := New(&config.Config{ProjectRoot: project, ExtraRoots: []string{extra}})
+	path := filepath.Join(extra, "import", "items.csv")
+	if got, err := s.Resolve("source_path", path); err != nil || got != path {
+		t.Errorf("Resolve(%q) = %q, %v", path, got, err)
+	}
+}
+
+func TestResolve_NoRoots(t *testing.T) {
+	s := New(&config.Config{})
+	if _, err := s.Resolve("path", "/tmp/x"); err == nil || !strings.Contains(err.Error(), "no allowed directories") {
+		t.Errorf("error = %v", err)
+	}
+	if _, err := s.Resolve("path", "relative"); err == nil || !strings.Contains(err.Error(), "relative") {
+		t.Errorf("error = %v", err)
+	}
+}
+
+func TestWithin(t *testing.T) {
+	project := tempDir(t)
+	config := filepath.Join(project, "Config")
+	if !Within(config, filepath.Join(config, "DefaultEngine.ini")) {
+		t.Error("file in Config/ not within Config/")
+	}
+	if Within(config, filepath.Join(project, "Saved", "x.ini")) {
+		t.Error("file in Saved/ within Config/")
+	}
+}
+
+func TestEngineDir(t *testing.T) {
+	tests := map[string]string{
+		"/opt/UE_5.7/Engine/Binaries/Linux/UnrealEditor-Cmd": "/opt/UE_5.7/Engine",
+		"/usr/local/bin/UnrealEditor-Cmd":                    "",
+		"UnrealEditor-Cmd":                                   "",
+		"":                                                   "",
+	}
+	for in, want := range tests {
+		if got := engineDir(in); got != want {
+			t.Errorf("engineDir(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
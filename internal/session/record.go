@@ -0,0 +1,234 @@
+// Copyright (c) mcp-unreal project contributors. Apache-2.0 license.
+
+// Package session records each MCP session's tool calls and results to a
+// JSONL file, and replays a recording against the current editor and
+// project: the replay_session tool and "mcp-unreal replay" command.
+//
+// A recording starts with a header line (Type "session") followed by one
+// line per tool call (Type "call") in the order the calls finished.
+// Arguments are stored with secrets redacted, so a recording can be
+// shared; string arguments may be edited to hold {{name}} placeholders
+// that a replay fills in from its params, with defaults in the header.
+package session
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/remiphilippe/mcp-unreal/internal/audit"
+	"github.com/remiphilippe/mcp-unreal/internal/loopback"
+	"github.com/remiphilippe/mcp-unreal/internal/policy"
+	"github.com/remiphilippe/mcp-unreal/internal/toolerr"
+)
+
+// Record types, in Header.Type and Call.Type.
+const (
+	TypeSession = "session"
+	TypeCall    = "call"
+)
+
+// maxResult caps the recorded structured result of a call, in bytes.
+// Larger results are left out and marked truncated.
+const maxResult = 32 << 10
+
+// Header is the first line of a recording.
+type Header struct {
+	Type    string         `json:"type"`
+	ID      string         `json:"id"`
+	Client  string         `json:"client,omitempty"`
+	Started time.Time      `json:"started"`
+	Project string         `json:"project,omitempty"`
+	Params  map[string]any `json:"params,omitempty"`
+}
+
+// Call is one recorded tool call.
+type Call struct {
+	Type       string          `json:"type"`
+	Seq        int             `json:"seq"`
+	Time       time.Time       `json:"time"`
+	Tool       string          `json:"tool"`
+	Arguments  map[string]any  `json:"arguments,omitempty"`
+	Status     string          `json:"status"`
+	Code       toolerr.Code    `json:"code,omitempty"`
+	Error      string          `json:"error,omitempty"`
+	Result     json.RawMessage `json:"result,omitempty"`
+	Truncated  bool            `json:"result_truncated,omitempty"`
+	DurationMS int64           `json:"duration_ms"`
+}
+
+// Recorder writes one recording per MCP session to a directory. It is
+// safe for concurrent use.
+type Recorder struct {
+	dir     string
+	project string
+	logger  *slog.Logger
+
+	mu    sync.Mutex
+	files map[*mcp.ServerSession]*recording
+}
+
+// recording is the open file of one session.
+type recording struct {
+	file *os.File
+	seq  int
+}
+
+// NewRecorder returns a Recorder writing to dir, creating it if needed.
+// project is noted in each recording's header.
+func NewRecorder(dir, project string, logger *slog.Logger) (*Recorder, error) {
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, fmt.Errorf("creating session directory: %w", err)
+	}
+	return &Recorder{dir: dir, project: project, logger: logger, files: make(map[*mcp.ServerSession]*recording)}, nil
+}
+
+// Dir returns the directory recordings are written to.
+func (r *Recorder) Dir() string {
+	return r.dir
+}
+
+// Close closes the recordings of sessions that are still open.
+func (r *Recorder) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var firstErr error
+	for ss, rec := range r.files {
+		if err := rec.file.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+		delete(r.files, ss)
+	}
+	return firstErr
+}
+
+// Middleware returns MCP receiving middleware that records every
+// tools/call of a session, except replay_session itself and the calls
+// of loopback sessions (see package loopback). Install it inside the error
+// code middleware so failures are recorded with their codes. A failure
+// to write is reported on stderr and does not fail the call.
+func (r *Recorder) Middleware(next mcp.MethodHandler) mcp.MethodHandler {
+	return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+		call, ok := req.(*mcp.CallToolRequest)
+		if !ok || call.Params == nil || call.Session == nil || call.Params.Name == ToolName {
+			return next(ctx, method, req)
+		}
+		// Loopback calls of batch, workflows, and replays are recorded
+		// as the one call that started them.
+		if loopback.IsInternal(call.Session) {
+			return next(ctx, method, req)
+		}
+		id, client := audit.SessionInfo(call.Session)
+
+		start := time.Now()
+		res, err := next(ctx, method, req)
+
+		c := Call{
+			Type:       TypeCall,
+			Time:       start.UTC(),
+			Tool:       call.Params.Name,
+			Arguments:  audit.RedactSecrets(policy.NewCall(call.Params.Name, call.Params.Arguments).Args),
+			DurationMS: time.Since(start).Milliseconds(),
+		}
+		outcome(&c, res, err)
+		if werr := r.append(call.Session, Header{Type: TypeSession, ID: id, Client: client, Started: start.UTC(), Project: r.project}, c); werr != nil {
+			r.logger.Warn("session recording failed", "session", id, "tool", c.Tool, "error", werr)
+		}
+		return res, err
+	}
+}
+
+// append writes c to the recording of ss, starting it with h on the
+// session's first call.
+func (r *Recorder) append(ss *mcp.ServerSession, h Header, c Call) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	rec, ok := r.files[ss]
+	if !ok {
+		f, err := os.OpenFile(filepath.Join(r.dir, h.ID+".jsonl"), os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o600)
+		if err != nil {
+			return fmt.Errorf("opening session recording: %w", err)
+		}
+		if err := writeLine(f, h); err != nil {
+			_ = f.Close()
+			return err
+		}
+		rec = &recording{file: f}
+		r.files[ss] = rec
+		go r.closeWhenDone(ss)
+	}
+	rec.seq++
+	c.Seq = rec.seq
+	return writeLine(rec.file, c)
+}
+
+// closeWhenDone closes the recording of ss when the session ends.
+func (r *Recorder) closeWhenDone(ss *mcp.ServerSession) {
+	_ = ss.Wait()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if rec, ok := r.files[ss]; ok {
+		_ = rec.file.Close()
+		delete(r.files, ss)
+	}
+}
+
+func writeLine(f *os.File, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("encoding session record: %w", err)
+	}
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("writing session recording: %w", err)
+	}
+	return nil
+}
+
+// outcome fills in the status, error, and result of c.
+func outcome(c *Call, res mcp.Result, err error) {
+	if err != nil {
+		c.Status, c.Error = audit.StatusError, err.Error()
+		return
+	}
+	r, ok := res.(*mcp.CallToolResult)
+	if !ok || r == nil {
+		c.Status = audit.StatusOK
+		return
+	}
+	if r.IsError {
+		c.Status = audit.StatusError
+		if cause := r.GetError(); cause != nil {
+			c.Error, c.Code = cause.Error(), toolerr.CodeOf(cause)
+		} else {
+			c.Error = toolerr.ResultText(r)
+		}
+		if c.Code == toolerr.PolicyDenied || policy.IsDenial(c.Error) {
+			c.Status = audit.StatusDenied
+		}
+		return
+	}
+	c.Status = audit.StatusOK
+	var result any = r.StructuredContent
+	if result == nil {
+		if text := toolerr.ResultText(r); text != "" {
+			result = text
+		}
+	}
+	if result == nil {
+		return
+	}
+	data, err := json.Marshal(result)
+	switch {
+	case err != nil:
+	case len(data) > maxResult:
+		c.Truncated = true
+	default:
+		c.Result = data
+	}
+}
@@ -0,0 +1,148 @@
+// Copyright (c) mcp-unreal project contributors. Apache-2.0 license.
+
+package session
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"path/filepath"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/remiphilippe/mcp-unreal/internal/audit"
+	"github.com/remiphilippe/mcp-unreal/internal/loopback"
+	"github.com/remiphilippe/mcp-unreal/internal/toolerr"
+)
+
+type spawnInput struct {
+	Name     string `json:"name"`
+	Password string `json:"password,omitempty"`
+}
+
+type spawnOutput struct {
+	ActorPath string `json:"actor_path"`
+}
+
+// newTestServer returns a server with a spawn tool that fails with
+// NOT_FOUND for the name "bad", the replay_session tool, and a recorder
+// writing to a temporary directory.
+func newTestServer(t *testing.T) (*mcp.Server, *Recorder, *int) {
+	t.Helper()
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	recorder, err := NewRecorder(t.TempDir(), "/Projects/Demo", logger)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = recorder.Close() })
+
+	calls := new(int)
+	server := mcp.NewServer(&mcp.Implementation{Name: "test"}, nil)
+	mcp.AddTool(server, &mcp.Tool{Name: "spawn"}, func(_ context.Context, _ *mcp.CallToolRequest, in spawnInput) (*mcp.CallToolResult, spawnOutput, error) {
+		*calls++
+		if in.Name == "bad" {
+			return nil, spawnOutput{}, toolerr.New(toolerr.NotFound, "no actor class %q", in.Name)
+		}
+		return nil, spawnOutput{ActorPath: "/Game/Maps/Main." + in.Name}, nil
+	})
+	h := &Handler{Server: server, Dir: recorder.Dir(), Logger: logger}
+	h.Register(server)
+	server.AddReceivingMiddleware(toolerr.Middleware, recorder.Middleware)
+	return server, recorder, calls
+}
+
+func connect(t *testing.T, server *mcp.Server, name string) *mcp.ClientSession {
+	t.Helper()
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	if _, err := server.Connect(context.Background(), serverTransport, nil); err != nil {
+		t.Fatal(err)
+	}
+	client := mcp.NewClient(&mcp.Implementation{Name: name, Version: "1.0"}, nil)
+	cs, err := client.Connect(context.Background(), clientTransport, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = cs.Close() })
+	return cs
+}
+
+// recordSession records a session of three spawn calls, the second of
+// which fails, and returns the path of its recording.
+func recordSession(t *testing.T, server *mcp.Server, recorder *Recorder) string {
+	t.Helper()
+	cs := connect(t, server, "agent")
+	ctx := context.Background()
+	for _, args := range []map[string]any{
+		{"name": "Cube", "password": "hunter2"},
+		{"name": "bad"},
+		{"name": "{{light}}"},
+	} {
+		if _, err := cs.CallTool(ctx, &mcp.CallToolParams{Name: "spawn", Arguments: args}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Neither replays nor the calls of a loopback session are recorded.
+	if _, err := cs.CallTool(ctx, &mcp.CallToolParams{Name: ToolName, Arguments: map[string]any{"session": "missing"}}); err != nil {
+		t.Fatal(err)
+	}
+	loop, err := loopback.Connect(server, "batch")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = loop.Close() })
+	if _, err := loop.CallTool(ctx, &mcp.CallToolParams{Name: "spawn", Arguments: map[string]any{"name": "Sphere"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	paths, err := filepath.Glob(filepath.Join(recorder.Dir(), "*.jsonl"))
+	if err != nil || len(paths) != 1 {
+		t.Fatalf("recordings = %v (%v), want one", paths, err)
+	}
+	return paths[0]
+}
+
+func TestRecorder(t *testing.T) {
+	server, recorder, _ := newTestServer(t)
+	path := recordSession(t, server, recorder)
+
+	rec, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := rec.Header
+	if h.Type != TypeSession || h.Client != "agent 1.0" || h.Project != "/Projects/Demo" || h.ID == "" {
+		t.Errorf("header = %+v", h)
+	}
+	if filepath.Base(path) != h.ID+".jsonl" {
+		t.Errorf("recording %s is not named after session %s", path, h.ID)
+	}
+	if len(rec.Calls) != 3 {
+		t.Fatalf("recorded %d calls, want 3: %+v", len(rec.Calls), rec.Calls)
+	}
+
+	ok, failed := rec.Calls[0], rec.Calls[1]
+	if ok.Seq != 1 || ok.Tool != "spawn" || ok.Status != audit.StatusOK {
+		t.Errorf("call 1 = %+v", ok)
+	}
+	if ok.Arguments["password"] != audit.Redacted || ok.Arguments["name"] != "Cube" {
+		t.Errorf("call 1 arguments = %v, want password redacted", ok.Arguments)
+	}
+	if string(ok.Result) != `{"actor_path":"/Game/Maps/Main.Cube"}` {
+		t.Errorf("call 1 result = %s", ok.Result)
+	}
+	if failed.Seq != 2 || failed.Status != audit.StatusError || failed.Code != toolerr.NotFound {
+		t.Errorf("call 2 = %+v, want a NOT_FOUND error", failed)
+	}
+	if failed.Error != `no actor class "bad"` {
+		t.Errorf("call 2 error = %q", failed.Error)
+	}
+}
+
+func TestOutcomeTruncatesLargeResults(t *testing.T) {
+	var c Call
+	outcome(&c, &mcp.CallToolResult{StructuredContent: map[string]string{"data": fmt.Sprintf("%0*d", maxResult, 0)}}, nil)
+	if c.Status != audit.StatusOK || !c.Truncated || c.Result != nil {
+		t.Errorf("call = %+v, want ok with the result truncated", c)
+	}
+}
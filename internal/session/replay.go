@@ -0,0 +1,411 @@
+// Copyright (c) mcp-unreal project contributors. Apache-2.0 license.
+
+package session
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/remiphilippe/mcp-unreal/internal/audit"
+	"github.com/remiphilippe/mcp-unreal/internal/batch"
+	"github.com/remiphilippe/mcp-unreal/internal/loopback"
+	"github.com/remiphilippe/mcp-unreal/internal/sandbox"
+	"github.com/remiphilippe/mcp-unreal/internal/toolerr"
+)
+
+// ToolName is the name of the replay tool. Its own calls are not
+// recorded, and a recording that contains one is not replayed.
+const ToolName = "replay_session"
+
+// maxRecordLine caps one line of a recording, in bytes.
+const maxRecordLine = 8 << 20
+
+// Step statuses in StepResult.Status.
+const (
+	StepOK      = "ok"
+	StepError   = "error"
+	StepSkipped = "skipped"
+)
+
+// Recording is a parsed session recording.
+type Recording struct {
+	Header Header
+	Calls  []Call
+}
+
+// Load reads the recording at path.
+func Load(path string) (*Recording, error) {
+	f, err := os.Open(filepath.Clean(path))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, toolerr.New(toolerr.NotFound, "session recording %s not found", path)
+		}
+		return nil, fmt.Errorf("opening session recording: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	rec := &Recording{}
+	sc := bufio.NewScanner(f)
+	sc.Buffer(make([]byte, 64<<10), maxRecordLine)
+	for line := 1; sc.Scan(); line++ {
+		if strings.TrimSpace(sc.Text()) == "" {
+			continue
+		}
+		var kind struct {
+			Type string `json:"type"`
+		}
+		if err := json.Unmarshal(sc.Bytes(), &kind); err != nil {
+			return nil, toolerr.New(toolerr.Validation, "session recording %s line %d: %w", path, line, err)
+		}
+		switch kind.Type {
+		case TypeSession:
+			if err := json.Unmarshal(sc.Bytes(), &rec.Header); err != nil {
+				return nil, toolerr.New(toolerr.Validation, "session recording %s line %d: %w", path, line, err)
+			}
+		case TypeCall:
+			var c Call
+			if err := json.Unmarshal(sc.Bytes(), &c); err != nil {
+				return nil, toolerr.New(toolerr.Validation, "session recording %s line %d: %w", path, line, err)
+			}
+			rec.Calls = append(rec.Calls, c)
+		default:
+			return nil, toolerr.New(toolerr.Validation, "session recording %s line %d: unknown record type %q", path, line, kind.Type)
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("reading session recording %s: %w", path, err)
+	}
+	return rec, nil
+}
+
+// Options control a replay.
+type Options struct {
+	// Params fill {{name}} placeholders, over the header's defaults.
+	Params map[string]any
+	// From and To bound the replayed calls by sequence number; zero
+	// means the first and last call.
+	From, To int
+	// IncludeFailed also replays calls that failed or were denied when
+	// recorded. By default they are skipped.
+	IncludeFailed bool
+	// Continue runs every call instead of stopping at the first one
+	// whose outcome differs from the recording.
+	Continue bool
+}
+
+// StepResult reports the replay of one recorded call.
+type StepResult struct {
+	Seq            int    `json:"seq" jsonschema:"sequence number of the call in the recording"`
+	Tool           string `json:"tool" jsonschema:"tool name"`
+	Status         string `json:"status" jsonschema:"ok, error, or skipped"`
+	RecordedStatus string `json:"recorded_status" jsonschema:"ok, error, or denied when recorded"`
+	Diverged       bool   `json:"diverged,omitempty" jsonschema:"true if the outcome differs from the recording"`
+	Code           string `json:"code,omitempty" jsonschema:"error code if the call failed"`
+	Error          string `json:"error,omitempty" jsonschema:"error message if the call failed"`
+	Skipped        string `json:"skip_reason,omitempty" jsonschema:"why the call was not replayed"`
+}
+
+// Report is the outcome of a replay.
+type Report struct {
+	Session   string       `json:"session" jsonschema:"ID of the replayed recording"`
+	Steps     []StepResult `json:"steps" jsonschema:"per-call results in recorded order"`
+	Succeeded int          `json:"succeeded" jsonschema:"number of calls that succeeded"`
+	Failed    int          `json:"failed" jsonschema:"number of calls that failed"`
+	Skipped   int          `json:"skipped" jsonschema:"number of calls not replayed"`
+	Diverged  int          `json:"diverged" jsonschema:"number of calls whose outcome differs from the recording"`
+	StoppedAt int          `json:"stopped_at,omitempty" jsonschema:"sequence number of the diverging call the replay stopped at"`
+}
+
+// Replay re-executes the calls of rec through session, which should be
+// connected to a server with the recorded tools. Placeholders and
+// redacted arguments are checked before any call is made.
+func Replay(ctx context.Context, session *mcp.ClientSession, rec *Recording, opts Options) (Report, error) {
+	params := make(map[string]any, len(rec.Header.Params)+len(opts.Params))
+	for k, v := range rec.Header.Params {
+		params[k] = v
+	}
+	for k, v := range opts.Params {
+		params[k] = v
+	}
+
+	type planned struct {
+		call Call
+		args map[string]any
+		skip string
+	}
+	var plan []planned
+	for _, c := range rec.Calls {
+		p := planned{call: c}
+		switch {
+		case opts.From > 0 && c.Seq < opts.From, opts.To > 0 && c.Seq > opts.To:
+			continue
+		case c.Tool == ToolName:
+			p.skip = "replays are not nested"
+		case c.Status != audit.StatusOK && !opts.IncludeFailed:
+			p.skip = "the call " + statusVerb(c.Status) + " when recorded"
+		default:
+			if hasRedacted(c.Arguments) {
+				return Report{}, toolerr.New(toolerr.Validation,
+					"call %d (%s) has arguments redacted when recorded — replace %s in the recording with a {{name}} placeholder and pass its value in params",
+					c.Seq, c.Tool, audit.Redacted)
+			}
+			args, err := fill(c.Arguments, params)
+			if err != nil {
+				return Report{}, toolerr.New(toolerr.Validation, "call %d (%s): %w", c.Seq, c.Tool, err)
+			}
+			p.args, _ = args.(map[string]any)
+		}
+		plan = append(plan, p)
+	}
+
+	out := Report{Session: rec.Header.ID, Steps: make([]StepResult, 0, len(plan))}
+	for _, p := range plan {
+		r := StepResult{Seq: p.call.Seq, Tool: p.call.Tool, RecordedStatus: p.call.Status}
+		switch {
+		case p.skip != "":
+			r.Status, r.Skipped = StepSkipped, p.skip
+		case out.StoppedAt != 0:
+			r.Status, r.Skipped = StepSkipped, fmt.Sprintf("the replay stopped at call %d", out.StoppedAt)
+		default:
+			if err := ctx.Err(); err != nil {
+				return out, err
+			}
+			r.Status = StepOK
+			if err := call(ctx, session, p.call.Tool, p.args); err != nil {
+				r.Status, r.Error, r.Code = StepError, err.Error(), string(toolerr.CodeOf(err))
+			}
+			r.Diverged = diverged(p.call, r)
+		}
+		switch r.Status {
+		case StepOK:
+			out.Succeeded++
+		case StepError:
+			out.Failed++
+		default:
+			out.Skipped++
+		}
+		if r.Diverged {
+			out.Diverged++
+			if !opts.Continue && out.StoppedAt == 0 {
+				out.StoppedAt = r.Seq
+			}
+		}
+		out.Steps = append(out.Steps, r)
+	}
+	return out, nil
+}
+
+// diverged reports whether a replayed call's outcome differs from the
+// recorded one: it failed where it succeeded, succeeded where it failed,
+// or failed with a different error code.
+func diverged(c Call, r StepResult) bool {
+	if (c.Status == audit.StatusOK) != (r.Status == StepOK) {
+		return true
+	}
+	return r.Status == StepError && c.Code != "" && string(c.Code) != r.Code
+}
+
+func statusVerb(status string) string {
+	if status == audit.StatusDenied {
+		return "was denied"
+	}
+	return "failed"
+}
+
+// call runs one tool, returning its error result as an error.
+func call(ctx context.Context, session *mcp.ClientSession, tool string, args map[string]any) error {
+	_, err := batch.Call(ctx, session, tool, args)
+	return err
+}
+
+// placeholder matches {{name}} in recorded string arguments.
+var placeholder = regexp.MustCompile(`\{\{\s*([A-Za-z_][A-Za-z0-9_]*)\s*\}\}`)
+
+// fill returns v with every placeholder in its strings replaced from
+// params. A string that is a single placeholder becomes the parameter's
+// value itself, keeping its JSON type.
+func fill(v any, params map[string]any) (any, error) {
+	switch v := v.(type) {
+	case map[string]any:
+		out := make(map[string]any, len(v))
+		for k, e := range v {
+			filled, err := fill(e, params)
+			if err != nil {
+				return nil, err
+			}
+			out[k] = filled
+		}
+		return out, nil
+	case []any:
+		out := make([]any, len(v))
+		for i, e := range v {
+			filled, err := fill(e, params)
+			if err != nil {
+				return nil, err
+			}
+			out[i] = filled
+		}
+		return out, nil
+	case string:
+		if m := placeholder.FindStringSubmatchIndex(v); m != nil && m[0] == 0 && m[1] == len(v) {
+			return param(params, v[m[2]:m[3]])
+		}
+		var firstErr error
+		out := placeholder.ReplaceAllStringFunc(v, func(ph string) string {
+			val, err := param(params, placeholder.FindStringSubmatch(ph)[1])
+			if err != nil {
+				if firstErr == nil {
+					firstErr = err
+				}
+				return ph
+			}
+			if s, ok := val.(string); ok {
+				return s
+			}
+			data, _ := json.Marshal(val)
+			return string(data)
+		})
+		if firstErr != nil {
+			return nil, firstErr
+		}
+		return out, nil
+	default:
+		return v, nil
+	}
+}
+
+func param(params map[string]any, name string) (any, error) {
+	v, ok := params[name]
+	if !ok {
+		names := make([]string, 0, len(params))
+		for k := range params {
+			names = append(names, k)
+		}
+		slices.Sort(names)
+		return nil, fmt.Errorf("no value for {{%s}} — pass it in params (given: %s)", name, strings.Join(names, ", "))
+	}
+	return v, nil
+}
+
+// hasRedacted reports whether any string in v was redacted.
+func hasRedacted(v any) bool {
+	switch v := v.(type) {
+	case map[string]any:
+		for _, e := range v {
+			if hasRedacted(e) {
+				return true
+			}
+		}
+	case []any:
+		for _, e := range v {
+			if hasRedacted(e) {
+				return true
+			}
+		}
+	case string:
+		return strings.Contains(v, audit.Redacted)
+	}
+	return false
+}
+
+// --- replay_session tool ---
+
+// ReplayInput is the input of the replay_session tool.
+type ReplayInput struct {
+	Session       string         `json:"session" jsonschema:"Recording to replay: a session ID from the sessions directory, or the path of a .jsonl recording"`
+	Params        map[string]any `json:"params,omitempty" jsonschema:"Values for {{name}} placeholders in the recorded arguments; they override the recording's defaults"`
+	From          int            `json:"from,omitempty" jsonschema:"First call to replay, by sequence number (default the first)"`
+	To            int            `json:"to,omitempty" jsonschema:"Last call to replay, by sequence number (default the last)"`
+	IncludeFailed bool           `json:"include_failed,omitempty" jsonschema:"Also replay calls that failed or were denied when recorded (skipped by default)"`
+	OnDivergence  string         `json:"on_divergence,omitempty" jsonschema:"stop (default): stop at the first call whose outcome differs from the recording; continue: replay every call"`
+}
+
+// Handler serves replay_session against the tools of Server.
+type Handler struct {
+	Server *mcp.Server
+	// Dir is where session IDs are looked up.
+	Dir    string
+	Paths  *sandbox.Sandbox
+	Logger *slog.Logger
+
+	loop loopback.Lazy
+}
+
+// Register adds the replay_session tool to the MCP server.
+func (h *Handler) Register(server *mcp.Server) {
+	mcp.AddTool(server, &mcp.Tool{
+		Name: ToolName,
+		Description: "Re-run the tool calls of a recorded MCP session against the current editor and project, " +
+			"e.g. to rebuild a scene or reapply a fix. Every session is recorded to Saved/mcp-unreal/sessions " +
+			"as <session ID>.jsonl; audit_query with sessions=true lists session IDs. Calls that failed when " +
+			"recorded are skipped unless include_failed is set. String arguments edited in the recording to " +
+			"hold {{name}} placeholders are filled from params. on_divergence=stop (default) stops at the " +
+			"first call whose outcome differs from the recording. Returns each call's status.",
+	}, h.Replay)
+}
+
+// Replay implements the replay_session tool.
+func (h *Handler) Replay(ctx context.Context, req *mcp.CallToolRequest, input ReplayInput) (*mcp.CallToolResult, Report, error) {
+	opts := Options{Params: input.Params, From: input.From, To: input.To, IncludeFailed: input.IncludeFailed}
+	switch input.OnDivergence {
+	case "", "stop":
+	case "continue":
+		opts.Continue = true
+	default:
+		return nil, Report{}, toolerr.New(toolerr.Validation, "invalid on_divergence %q — use stop or continue", input.OnDivergence)
+	}
+	path, err := h.Locate(input.Session)
+	if err != nil {
+		return nil, Report{}, err
+	}
+	rec, err := Load(path)
+	if err != nil {
+		return nil, Report{}, err
+	}
+	ctx, err = loopback.Enter(ctx, req)
+	if err != nil {
+		return nil, Report{}, err
+	}
+	session, err := h.loop.Get(h.Server, "replay")
+	if err != nil {
+		return nil, Report{}, err
+	}
+	out, err := Replay(ctx, session, rec, opts)
+	if err != nil {
+		return nil, Report{}, err
+	}
+	h.Logger.Debug("replayed session", "session", out.Session, "succeeded", out.Succeeded,
+		"failed", out.Failed, "diverged", out.Diverged)
+	return nil, out, nil
+}
+
+// sessionID matches the IDs recordings are named by.
+var sessionID = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// Locate returns the recording named by session: an ID in h.Dir, or a
+// path confined to the path sandbox (CLAUDE.md Security §4).
+func (h *Handler) Locate(session string) (string, error) {
+	if session == "" {
+		return "", toolerr.New(toolerr.Validation, "session is required")
+	}
+	if sessionID.MatchString(session) {
+		if h.Dir == "" {
+			return "", toolerr.New(toolerr.Unsupported,
+				"session recording is off — set MCP_UNREAL_SESSION_DIR or pass the path of a recording")
+		}
+		return filepath.Join(h.Dir, session+".jsonl"), nil
+	}
+	if h.Paths == nil {
+		return "", toolerr.New(toolerr.PathRefused, "session %q refused: file paths are disabled because no path sandbox is configured", session)
+	}
+	return h.Paths.Resolve("session", session)
+}
@@ -0,0 +1,198 @@
+// Copyright (c) mcp-unreal project contributors. Apache-2.0 license.
+
+package session
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/remiphilippe/mcp-unreal/internal/audit"
+	"github.com/remiphilippe/mcp-unreal/internal/toolerr"
+)
+
+// replay calls replay_session on server and decodes its report.
+func replay(t *testing.T, server *mcp.Server, args map[string]any) (Report, *mcp.CallToolResult) {
+	t.Helper()
+	res, err := connect(t, server, "agent").CallTool(context.Background(), &mcp.CallToolParams{Name: ToolName, Arguments: args})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out Report
+	if !res.IsError {
+		data, _ := json.Marshal(res.StructuredContent)
+		if err := json.Unmarshal(data, &out); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return out, res
+}
+
+func statuses(out Report) []string {
+	var s []string
+	for _, step := range out.Steps {
+		s = append(s, step.Status)
+	}
+	return s
+}
+
+// writeRecording writes a recording of calls with the given header
+// params to dir and returns its session ID.
+func writeRecording(t *testing.T, dir string, params map[string]any, calls ...Call) string {
+	t.Helper()
+	lines := []any{Header{Type: TypeSession, ID: "fixture", Params: params}}
+	for i, c := range calls {
+		c.Type, c.Seq = TypeCall, i+1
+		lines = append(lines, c)
+	}
+	var b strings.Builder
+	for _, l := range lines {
+		data, err := json.Marshal(l)
+		if err != nil {
+			t.Fatal(err)
+		}
+		b.Write(append(data, '\n'))
+	}
+	if err := os.WriteFile(filepath.Join(dir, "fixture.jsonl"), []byte(b.String()), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return "fixture"
+}
+
+func TestReplaySession(t *testing.T) {
+	server, recorder, calls := newTestServer(t)
+	path := recordSession(t, server, recorder)
+	id := strings.TrimSuffix(filepath.Base(path), ".jsonl")
+	*calls = 0
+
+	// Call 1 has a redacted password (see TestReplayRefusesRedacted), so
+	// replay from call 2: the failed call is skipped and the placeholder
+	// of call 3 is filled.
+	out, res := replay(t, server, map[string]any{"session": id, "from": 2, "params": map[string]any{"light": "Lamp"}})
+	if res.IsError {
+		t.Fatalf("replay failed: %v", res.Content)
+	}
+	if got, want := statuses(out), []string{StepSkipped, StepOK}; !reflect.DeepEqual(got, want) {
+		t.Errorf("statuses = %v, want %v", got, want)
+	}
+	if out.Succeeded != 1 || out.Skipped != 1 || out.Diverged != 0 || out.StoppedAt != 0 {
+		t.Errorf("report = %+v", out)
+	}
+	if *calls != 1 {
+		t.Errorf("spawn ran %d times, want 1", *calls)
+	}
+	if !strings.Contains(out.Steps[0].Skipped, "failed when recorded") {
+		t.Errorf("skip reason = %q", out.Steps[0].Skipped)
+	}
+
+	// Replays are not recorded: the recorder still has one session.
+	if paths, _ := filepath.Glob(filepath.Join(recorder.Dir(), "*.jsonl")); len(paths) != 1 {
+		t.Errorf("recordings = %v, want one", paths)
+	}
+}
+
+func TestReplayRefusesRedacted(t *testing.T) {
+	server, recorder, calls := newTestServer(t)
+	path := recordSession(t, server, recorder)
+	*calls = 0
+
+	_, res := replay(t, server, map[string]any{"session": strings.TrimSuffix(filepath.Base(path), ".jsonl")})
+	if !res.IsError {
+		t.Fatal("replay of redacted arguments succeeded")
+	}
+	d, ok := toolerr.FromResult(res)
+	if !ok || d.Code != toolerr.Validation || !strings.Contains(d.Message, "redacted") {
+		t.Errorf("error = %+v, want a VALIDATION error about redaction", d)
+	}
+	if *calls != 0 {
+		t.Errorf("spawn ran %d times before the refusal", *calls)
+	}
+}
+
+func TestReplayDivergence(t *testing.T) {
+	server, recorder, calls := newTestServer(t)
+	id := writeRecording(t, recorder.Dir(), map[string]any{"name": "Cube"},
+		Call{Tool: "spawn", Arguments: map[string]any{"name": "{{name}}"}, Status: audit.StatusOK},
+		Call{Tool: "spawn", Arguments: map[string]any{"name": "bad"}, Status: audit.StatusOK},
+		Call{Tool: "spawn", Arguments: map[string]any{"name": "Lamp"}, Status: audit.StatusOK},
+	)
+
+	out, res := replay(t, server, map[string]any{"session": id})
+	if res.IsError {
+		t.Fatalf("replay failed: %v", res.Content)
+	}
+	if got, want := statuses(out), []string{StepOK, StepError, StepSkipped}; !reflect.DeepEqual(got, want) {
+		t.Errorf("statuses = %v, want %v", got, want)
+	}
+	if out.StoppedAt != 2 || out.Diverged != 1 || !out.Steps[1].Diverged || out.Steps[1].Code != string(toolerr.NotFound) {
+		t.Errorf("report = %+v, want a stop at the NOT_FOUND call 2", out)
+	}
+	if *calls != 2 {
+		t.Errorf("spawn ran %d times, want 2", *calls)
+	}
+
+	out, _ = replay(t, server, map[string]any{"session": id, "on_divergence": "continue"})
+	if got, want := statuses(out), []string{StepOK, StepError, StepOK}; !reflect.DeepEqual(got, want) {
+		t.Errorf("continue: statuses = %v, want %v", got, want)
+	}
+	if out.StoppedAt != 0 || out.Diverged != 1 {
+		t.Errorf("continue: report = %+v", out)
+	}
+}
+
+func TestReplayErrors(t *testing.T) {
+	server, recorder, _ := newTestServer(t)
+	id := writeRecording(t, recorder.Dir(), nil,
+		Call{Tool: "spawn", Arguments: map[string]any{"name": "{{missing}}"}, Status: audit.StatusOK})
+
+	tests := []struct {
+		name string
+		args map[string]any
+		code toolerr.Code
+		want string
+	}{
+		{"no session", map[string]any{"session": ""}, toolerr.Validation, "session is required"},
+		{"unknown session", map[string]any{"session": "nope"}, toolerr.NotFound, "not found"},
+		{"no sandbox", map[string]any{"session": "../x.jsonl"}, toolerr.PathRefused, "no path sandbox"},
+		{"bad on_divergence", map[string]any{"session": id, "on_divergence": "retry"}, toolerr.Validation, "on_divergence"},
+		{"missing param", map[string]any{"session": id}, toolerr.Validation, "no value for {{missing}}"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, res := replay(t, server, tt.args)
+			d, ok := toolerr.FromResult(res)
+			if !res.IsError || !ok || d.Code != tt.code || !strings.Contains(d.Message, tt.want) {
+				t.Errorf("error = %+v, want %s containing %q", d, tt.code, tt.want)
+			}
+		})
+	}
+}
+
+func TestFill(t *testing.T) {
+	params := map[string]any{"name": "Lamp", "count": float64(3), "loc": []any{float64(1), float64(2)}}
+	got, err := fill(map[string]any{
+		"label":  "{{ name }}_{{count}}",
+		"count":  "{{count}}",
+		"loc":    "{{loc}}",
+		"nested": []any{"at {{loc}}", true},
+		"plain":  "no placeholders",
+	}, params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]any{
+		"label":  "Lamp_3",
+		"count":  float64(3),
+		"loc":    []any{float64(1), float64(2)},
+		"nested": []any{"at [1,2]", true},
+		"plain":  "no placeholders",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("fill = %#v, want %#v", got, want)
+	}
+}
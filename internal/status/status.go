@@ -9,21 +9,53 @@ package status
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
-	"net/http"
 	"os"
 	"runtime"
-	"time"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 	"github.com/remiphilippe/mcp-unreal/internal/config"
+	"github.com/remiphilippe/mcp-unreal/internal/docs"
+	"github.com/remiphilippe/mcp-unreal/internal/editor"
+	"github.com/remiphilippe/mcp-unreal/internal/policy"
+	"github.com/remiphilippe/mcp-unreal/internal/sandbox"
+	"github.com/remiphilippe/mcp-unreal/internal/toolerr"
 )
 
+// DocSources reports what the documentation index holds. *docs.Index
+// implements it.
+type DocSources interface {
+	Sources() ([]docs.SourceStat, error)
+}
+
+// Editor is the editor client the status tool reports on. *editor.Client
+// implements it.
+type Editor interface {
+	PingRCAPI(ctx context.Context) bool
+	ProbePlugin(ctx context.Context) (*editor.PluginStatus, error)
+	BreakerStates() []editor.BreakerState
+}
+
+// PolicySummary describes the active tool policy. *policy.Policy
+// implements it.
+type PolicySummary interface {
+	Summary() policy.Summary
+}
+
 // Handler holds references needed by the status tool.
 type Handler struct {
 	Config  *config.Config
 	Version string
+
+	// Docs is the loaded documentation index, or nil if unavailable.
+	Docs DocSources
+
+	// Editor is the editor client whose connectivity, negotiated plugin,
+	// and circuit breakers are reported, or nil.
+	Editor Editor
+
+	// Policy is the tool policy enforced on calls, or nil.
+	Policy PolicySummary
 }
 
 // Input defines parameters for the status tool.
@@ -36,15 +68,26 @@ type Output struct {
 	GoVersion     string   `json:"go_version" jsonschema:"Go runtime version"`
 	ProjectRoot   string   `json:"project_root" jsonschema:"detected UE project root directory"`
 	UProjectFile  string   `json:"uproject_file,omitempty" jsonschema:"path to .uproject file if found"`
+	EngineVersion string   `json:"engine_version,omitempty" jsonschema:"project engine version from EngineAssociation; default version for doc lookups"`
 	UEEditorPath  string   `json:"ue_editor_path" jsonschema:"configured path to UnrealEditor-Cmd"`
 	UEInstalled   bool     `json:"ue_installed" jsonschema:"whether UnrealEditor-Cmd exists on disk"`
 	EditorOnline  bool     `json:"editor_online" jsonschema:"whether the UE editor Remote Control API is reachable"`
 	PluginOnline  bool     `json:"plugin_online" jsonschema:"whether the MCPUnreal editor plugin is reachable"`
+	PluginVersion string   `json:"plugin_version,omitempty" jsonschema:"MCPUnreal plugin version reported by the handshake"`
+	PluginError   string   `json:"plugin_error,omitempty" jsonschema:"why a reachable plugin cannot be used, e.g. a rejected auth token"`
 	PIEActive     bool     `json:"pie_active" jsonschema:"whether Play In Editor is currently active"`
 	PIEMap        string   `json:"pie_map,omitempty" jsonschema:"map name of the PIE world if active"`
 	RCAPIPort     int      `json:"rc_api_port" jsonschema:"Remote Control API port"`
 	PluginPort    int      `json:"plugin_port" jsonschema:"MCPUnreal plugin port"`
 	Features      []string `json:"features" jsonschema:"list of available feature categories"`
+
+	PathRoots []sandbox.Root `json:"path_roots" jsonschema:"directories that file paths in tool arguments may point into"`
+
+	DocSources []docs.SourceStat `json:"doc_sources,omitempty" jsonschema:"documentation sources in the index with document counts"`
+
+	EditorCircuits []editor.BreakerState `json:"editor_circuits,omitempty" jsonschema:"circuit breaker state of the plugin and RC API connections; open means editor tools fail fast until the retry time"`
+
+	Policy *policy.Summary `json:"policy,omitempty" jsonschema:"tool policy in force: its source file, read-only mode, and denied classes"`
 }
 
 // Register adds the status tool to the MCP server.
@@ -53,7 +96,7 @@ func (h *Handler) Register(server *mcp.Server) {
 		Name: "status",
 		Description: "Check mcp-unreal server health, UE installation, and editor connectivity. " +
 			"Call this first to verify your environment is set up correctly. " +
-			"Returns project info, editor online status, and available features.",
+			"Returns project info, editor online status, editor circuit breaker state, tool policy, available features, and indexed doc sources.",
 	}, h.Status)
 }
 
@@ -67,9 +110,11 @@ func (h *Handler) Status(ctx context.Context, req *mcp.CallToolRequest, input In
 		GoVersion:     runtime.Version(),
 		ProjectRoot:   cfg.ProjectRoot,
 		UProjectFile:  cfg.UProjectFile,
+		EngineVersion: cfg.EngineVersion,
 		UEEditorPath:  cfg.UEEditorPath,
 		RCAPIPort:     cfg.RCAPIPort,
 		PluginPort:    cfg.PluginPort,
+		PathRoots:     sandbox.New(cfg).Roots(),
 	}
 
 	// Check if UE editor binary exists on disk.
@@ -77,23 +122,46 @@ func (h *Handler) Status(ctx context.Context, req *mcp.CallToolRequest, input In
 		out.UEInstalled = true
 	}
 
-	// Ping RC API (localhost only, per CLAUDE.md Security §3).
-	out.EditorOnline = pingHTTP(ctx, cfg.RCAPIURL())
+	// Probe the editor through the editor client, so the checks use its
+	// TLS, token, and host settings (CLAUDE.md Security §3) and the plugin
+	// report is the handshake the editor tools negotiated.
+	var plugin *editor.PluginInfo
+	if h.Editor != nil {
+		out.EditorOnline = h.Editor.PingRCAPI(ctx)
+		st, err := h.Editor.ProbePlugin(ctx)
+		switch {
+		case err == nil:
+			out.PluginOnline = true
+			out.PIEActive, out.PIEMap = st.PIEActive, st.PIEMap
+			out.PluginVersion = st.Version
+			plugin = &st.PluginInfo
+		case toolerr.CodeOf(err) == toolerr.EditorAuth:
+			out.PluginError = "plugin rejected the request: set MCP_UNREAL_EDITOR_TOKEN to the token configured in the editor"
+		}
+		out.EditorCircuits = h.Editor.BreakerStates()
+	}
 
-	// Ping plugin.
-	ps := pingPlugin(ctx, cfg.PluginURL()+"/api/status")
-	out.PluginOnline = ps.Online
-	out.PIEActive = ps.PIEActive
-	out.PIEMap = ps.PIEMap
+	if h.Docs != nil {
+		if sources, err := h.Docs.Sources(); err == nil {
+			out.DocSources = sources
+		}
+	}
+
+	if h.Policy != nil {
+		sum := h.Policy.Summary()
+		out.Policy = &sum
+	}
 
 	// Determine available features based on what's reachable.
-	out.Features = h.availableFeatures(out)
+	out.Features = h.availableFeatures(out, plugin)
 
 	return nil, out, nil
 }
 
 // availableFeatures returns feature categories based on current state.
-func (h *Handler) availableFeatures(out Output) []string {
+// Plugin features come from the routes negotiated in the handshake; a
+// plugin that does not list its routes is assumed to provide them all.
+func (h *Handler) availableFeatures(out Output, plugin *editor.PluginInfo) []string {
 	features := []string{}
 
 	if out.UEInstalled {
@@ -108,9 +176,11 @@ func (h *Handler) availableFeatures(out Output) []string {
 	}
 
 	if out.PluginOnline {
-		features = append(features, "actors", "blueprints", "anim_blueprints", "assets",
-			"materials", "characters", "input", "levels", "mesh", "output_log",
-			"viewport_capture", "script_execution")
+		for _, f := range editor.PluginFeatures {
+			if plugin.Supports(f.Route) {
+				features = append(features, f.Name)
+			}
+		}
 	}
 
 	if out.PIEActive {
@@ -119,62 +189,3 @@ func (h *Handler) availableFeatures(out Output) []string {
 
 	return features
 }
-
-// pluginStatus holds the result of pinging the MCPUnreal plugin endpoint.
-type pluginStatus struct {
-	Online    bool
-	PIEActive bool
-	PIEMap    string
-}
-
-// pingPlugin sends a GET to the plugin status endpoint and parses PIE fields.
-func pingPlugin(ctx context.Context, url string) pluginStatus {
-	ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
-	defer cancel()
-
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
-	if err != nil {
-		return pluginStatus{}
-	}
-
-	client := &http.Client{Timeout: 2 * time.Second}
-	resp, err := client.Do(req)
-	if err != nil {
-		return pluginStatus{}
-	}
-	defer func() { _ = resp.Body.Close() }()
-
-	var body struct {
-		PIEActive bool   `json:"pie_active"`
-		PIEMap    string `json:"pie_map"`
-	}
-	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
-		// Reachable but couldn't parse — still online.
-		return pluginStatus{Online: true}
-	}
-
-	return pluginStatus{
-		Online:    true,
-		PIEActive: body.PIEActive,
-		PIEMap:    body.PIEMap,
-	}
-}
-
-// pingHTTP sends a quick HTTP request to check if a service is reachable.
-func pingHTTP(ctx context.Context, url string) bool {
-	ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
-	defer cancel()
-
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
-	if err != nil {
-		return false
-	}
-
-	client := &http.Client{Timeout: 2 * time.Second}
-	resp, err := client.Do(req)
-	if err != nil {
-		return false
-	}
-	_ = resp.Body.Close()
-	return true
-}
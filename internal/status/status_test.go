@@ -4,6 +4,8 @@ package status
 
 import (
 	"context"
+	"errors"
+	"log/slog"
 	"net/http"
 	"net/http/httptest"
 	"strconv"
@@ -11,6 +13,8 @@ import (
 	"testing"
 
 	"github.com/remiphilippe/mcp-unreal/internal/config"
+	"github.com/remiphilippe/mcp-unreal/internal/docs"
+	"github.com/remiphilippe/mcp-unreal/internal/editor"
 )
 
 func TestStatusWithEditorOffline(t *testing.T) {
@@ -22,7 +26,7 @@ func TestStatusWithEditorOffline(t *testing.T) {
 		DocsIndexPath: "./test-index.bleve",
 	}
 
-	h := &Handler{Config: cfg, Version: "0.1.0-test"}
+	h := newHandler(cfg, "0.1.0-test")
 	_, out, err := h.Status(context.Background(), nil, Input{})
 	if err != nil {
 		t.Fatalf("Status returned error: %v", err)
@@ -77,7 +81,7 @@ func TestStatusWithEditorOnline(t *testing.T) {
 		DocsIndexPath: "./test-index.bleve",
 	}
 
-	h := &Handler{Config: cfg, Version: "0.1.0-test"}
+	h := newHandler(cfg, "0.1.0-test")
 	_, out, err := h.Status(context.Background(), nil, Input{})
 	if err != nil {
 		t.Fatalf("Status returned error: %v", err)
@@ -107,7 +111,7 @@ func TestAvailableFeaturesDegradation(t *testing.T) {
 		UEInstalled:  false,
 		EditorOnline: false,
 		PluginOnline: false,
-	})
+	}, nil)
 	if len(features) != 1 || features[0] != "doc_search" {
 		t.Errorf("all offline: features = %v, want [doc_search]", features)
 	}
@@ -117,7 +121,7 @@ func TestAvailableFeaturesDegradation(t *testing.T) {
 		UEInstalled:  true,
 		EditorOnline: false,
 		PluginOnline: false,
-	})
+	}, nil)
 	if !containsFeature(features, "headless_build") {
 		t.Error("headless_build should be present when UE installed")
 	}
@@ -129,6 +133,99 @@ func TestAvailableFeaturesDegradation(t *testing.T) {
 	}
 }
 
+func TestAvailableFeaturesFromNegotiatedRoutes(t *testing.T) {
+	plugin := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"version":"0.3.0","protocol_version":1,"routes":["/api/status","/api/actors/list","/api/pcg/ops"]}`))
+	}))
+	defer plugin.Close()
+
+	cfg := &config.Config{
+		UEEditorPath: "/nonexistent/UnrealEditor-Cmd",
+		RCAPIPort:    1,
+		PluginPort:   portFromURL(t, plugin.URL),
+	}
+	h := newHandler(cfg, "test")
+	_, out, err := h.Status(context.Background(), nil, Input{})
+	if err != nil {
+		t.Fatalf("Status returned error: %v", err)
+	}
+	if out.PluginVersion != "0.3.0" {
+		t.Errorf("PluginVersion = %q, want 0.3.0", out.PluginVersion)
+	}
+	for _, f := range []string{"actors", "pcg"} {
+		if !containsFeature(out.Features, f) {
+			t.Errorf("%s should be present: %v", f, out.Features)
+		}
+	}
+	for _, f := range []string{"blueprints", "niagara"} {
+		if containsFeature(out.Features, f) {
+			t.Errorf("%s should be absent when its route is not listed: %v", f, out.Features)
+		}
+	}
+}
+
+func TestStatusReportsRejectedToken(t *testing.T) {
+	plugin := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer s3cret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"error":"Missing or invalid auth token"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"version":"0.3.0"}`))
+	}))
+	defer plugin.Close()
+
+	cfg := &config.Config{RCAPIPort: 1, PluginPort: portFromURL(t, plugin.URL), EditorToken: "wrong"}
+	h := newHandler(cfg, "test")
+	_, out, _ := h.Status(context.Background(), nil, Input{})
+	if out.PluginOnline || !strings.Contains(out.PluginError, "MCP_UNREAL_EDITOR_TOKEN") {
+		t.Errorf("wrong token: online=%v error=%q", out.PluginOnline, out.PluginError)
+	}
+
+	cfg.EditorToken = "s3cret"
+	h = newHandler(cfg, "test")
+	_, out, _ = h.Status(context.Background(), nil, Input{})
+	if !out.PluginOnline || out.PluginError != "" || out.PluginVersion != "0.3.0" {
+		t.Errorf("right token: %+v", out)
+	}
+}
+
+// newHandler returns a status handler whose editor client uses cfg.
+func newHandler(cfg *config.Config, version string) *Handler {
+	return &Handler{Config: cfg, Version: version, Editor: editor.NewClient(cfg, slog.New(slog.DiscardHandler))}
+}
+
+type fakeDocSources []docs.SourceStat
+
+func (f fakeDocSources) Sources() ([]docs.SourceStat, error) { return f, nil }
+
+func TestStatusReportsDocSources(t *testing.T) {
+	cfg := &config.Config{
+		UEEditorPath: "/nonexistent/UnrealEditor-Cmd",
+		RCAPIPort:    39999,
+		PluginPort:   39998,
+	}
+	sources := fakeDocSources{
+		{Name: "project", Docs: 1},
+		{Name: "ue5.7", Version: "5.7", Docs: 42},
+	}
+
+	h := newHandler(cfg, "test")
+	h.Docs = sources
+	_, out, err := h.Status(context.Background(), nil, Input{})
+	if err != nil {
+		t.Fatalf("Status returned error: %v", err)
+	}
+	if len(out.DocSources) != 2 || out.DocSources[1].Docs != 42 {
+		t.Errorf("DocSources = %+v, want the index's sources", out.DocSources)
+	}
+
+	h.Docs = nil
+	if _, out, _ = h.Status(context.Background(), nil, Input{}); out.DocSources != nil {
+		t.Errorf("DocSources = %+v, want none without an index", out.DocSources)
+	}
+}
+
 func containsFeature(features []string, name string) bool {
 	for _, f := range features {
 		if f == name {
@@ -147,3 +244,35 @@ func portFromURL(t *testing.T, url string) int {
 	}
 	return port
 }
+
+// fakeCircuits is an offline editor with the given breaker states.
+type fakeCircuits []editor.BreakerState
+
+func (f fakeCircuits) BreakerStates() []editor.BreakerState { return f }
+
+func (f fakeCircuits) PingRCAPI(context.Context) bool { return false }
+
+func (f fakeCircuits) ProbePlugin(context.Context) (*editor.PluginStatus, error) {
+	return nil, errors.New("offline")
+}
+
+func TestStatusReportsEditorCircuits(t *testing.T) {
+	cfg := &config.Config{
+		UEEditorPath: "/nonexistent/UnrealEditor-Cmd",
+		RCAPIPort:    39999,
+		PluginPort:   39998,
+	}
+	circuits := fakeCircuits{
+		{Service: "MCPUnreal plugin", State: "open", ConsecutiveFailures: 3, RetryInSeconds: 7.5},
+		{Service: "RC API", State: "closed"},
+	}
+
+	h := &Handler{Config: cfg, Version: "test", Editor: circuits}
+	_, out, err := h.Status(context.Background(), nil, Input{})
+	if err != nil {
+		t.Fatalf("Status returned error: %v", err)
+	}
+	if len(out.EditorCircuits) != 2 || out.EditorCircuits[0].State != "open" {
+		t.Errorf("EditorCircuits = %+v, want the client's breaker states", out.EditorCircuits)
+	}
+}
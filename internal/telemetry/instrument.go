@@ -0,0 +1,159 @@
+// Copyright (c) mcp-unreal project contributors. Apache-2.0 license.
+
+package telemetry
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/remiphilippe/mcp-unreal/internal/toolerr"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// maxErrorLen bounds the error text recorded on a span.
+const maxErrorLen = 500
+
+// Middleware records a span and metrics for every tools/call request.
+// A call fails if its handler returns an error or an error result. The
+// span is the parent of the call's editor requests and subprocesses.
+// Install it with server.AddReceivingMiddleware, first, so it times
+// everything else.
+func Middleware(next mcp.MethodHandler) mcp.MethodHandler {
+	return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+		call, ok := req.(*mcp.CallToolRequest)
+		if !ok || call.Params == nil {
+			return next(ctx, method, req)
+		}
+		name := call.Params.Name
+		attrs := []attribute.KeyValue{attribute.String("mcp.tool.name", name)}
+		var args struct {
+			Operation string `json:"operation"`
+			Instance  string `json:"instance"`
+		}
+		if len(call.Params.Arguments) > 0 && json.Unmarshal(call.Params.Arguments, &args) == nil {
+			if args.Operation != "" {
+				attrs = append(attrs, attribute.String("mcp.tool.operation", args.Operation))
+			}
+			if args.Instance != "" {
+				attrs = append(attrs, attribute.String("mcp.editor.instance", args.Instance))
+			}
+		}
+
+		ctx, span := tracer().Start(ctx, "tools/call "+name,
+			trace.WithSpanKind(trace.SpanKindServer), trace.WithAttributes(attrs...))
+		defer span.End()
+		start := time.Now()
+		res, err := next(ctx, method, req)
+
+		failed := err != nil
+		switch {
+		case err != nil:
+			fail(span, err.Error())
+		default:
+			if r, ok := res.(*mcp.CallToolResult); ok && r.IsError {
+				failed = true
+				fail(span, resultText(r))
+				if code := toolerr.CodeOf(r.GetError()); code != "" {
+					span.SetAttributes(attribute.String("error.type", string(code)))
+				}
+			}
+		}
+		toolCalls.WithLabelValues(name, outcome(failed)).Inc()
+		toolDuration.WithLabelValues(name).Observe(time.Since(start).Seconds())
+		return res, err
+	}
+}
+
+// StartEditorRequest starts the span of an HTTP request to the editor
+// service ("plugin" or "rc_api"). The returned func ends it with the
+// final status (0 if the editor was not reached), the number of attempts
+// made, and the request's error. It also counts the request and updates
+// the service's availability.
+func StartEditorRequest(ctx context.Context, service, method, endpoint string) (context.Context, func(status, attempts int, err error)) {
+	ctx, span := tracer().Start(ctx, "editor "+method+" "+endpoint,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("mcp.editor.service", service),
+			attribute.String("http.request.method", method),
+			attribute.String("url.path", endpoint),
+		))
+	start := time.Now()
+	return ctx, func(status, attempts int, err error) {
+		if status > 0 {
+			span.SetAttributes(attribute.Int("http.response.status_code", status))
+		}
+		if attempts > 1 {
+			span.SetAttributes(attribute.Int("http.request.resend_count", attempts-1))
+		}
+		if err != nil {
+			fail(span, err.Error())
+		}
+		span.End()
+
+		editorRequests.WithLabelValues(service, endpoint, outcome(err != nil)).Inc()
+		editorDuration.WithLabelValues(service).Observe(time.Since(start).Seconds())
+		up := 0.0
+		if status > 0 {
+			up = 1
+		}
+		editorUp.WithLabelValues(service).Set(up)
+	}
+}
+
+// InjectHeaders adds the trace context of ctx to outgoing request
+// headers, so an editor that traces can join the trace.
+func InjectHeaders(ctx context.Context, h http.Header) {
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(h))
+}
+
+// StartCommand starts the span of a headless subprocess. The returned
+// func ends it with the exit code (-1 if the process did not start) and
+// the error running it, and counts the run.
+func StartCommand(ctx context.Context, name string, args []string) (context.Context, func(exitCode int, err error)) {
+	command := filepath.Base(name)
+	ctx, span := tracer().Start(ctx, "exec "+command,
+		trace.WithSpanKind(trace.SpanKindInternal),
+		trace.WithAttributes(
+			attribute.String("process.executable.path", name),
+			attribute.StringSlice("process.command_args", args),
+		))
+	start := time.Now()
+	return ctx, func(exitCode int, err error) {
+		span.SetAttributes(attribute.Int("process.exit.code", exitCode))
+		switch {
+		case err != nil:
+			fail(span, err.Error())
+		case exitCode != 0:
+			fail(span, fmt.Sprintf("exit code %d", exitCode))
+		}
+		span.End()
+
+		commandRuns.WithLabelValues(command, outcome(err != nil || exitCode != 0)).Inc()
+		commandDuration.WithLabelValues(command).Observe(time.Since(start).Seconds())
+	}
+}
+
+// fail marks span as failed with msg.
+func fail(span trace.Span, msg string) {
+	if len(msg) > maxErrorLen {
+		msg = msg[:maxErrorLen] + "…"
+	}
+	span.SetStatus(codes.Error, msg)
+}
+
+// resultText returns the text of an error result.
+func resultText(res *mcp.CallToolResult) string {
+	if t := toolerr.ResultText(res); t != "" {
+		return t
+	}
+	return "tool returned an error"
+}
@@ -0,0 +1,115 @@
+// Copyright (c) mcp-unreal project contributors. Apache-2.0 license.
+
+package telemetry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Outcome label values.
+const (
+	outcomeOK    = "ok"
+	outcomeError = "error"
+)
+
+// registry holds the server's metrics. It is private so that only these
+// metrics, and the Go runtime's, are served.
+var registry = prometheus.NewRegistry()
+
+var (
+	toolCalls = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "mcp_unreal_tool_calls_total",
+		Help: "Tool calls by tool and outcome (ok or error).",
+	}, []string{"tool", "outcome"})
+
+	toolDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "mcp_unreal_tool_call_duration_seconds",
+		Help:    "Tool call latency by tool.",
+		Buckets: []float64{.01, .05, .1, .25, .5, 1, 2.5, 5, 10, 30, 60, 300, 1800},
+	}, []string{"tool"})
+
+	editorRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "mcp_unreal_editor_requests_total",
+		Help: "Editor HTTP requests by service (plugin or rc_api), endpoint, and outcome (ok or error).",
+	}, []string{"service", "endpoint", "outcome"})
+
+	editorDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "mcp_unreal_editor_request_duration_seconds",
+		Help:    "Editor HTTP request latency by service, including retries.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"service"})
+
+	editorUp = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "mcp_unreal_editor_up",
+		Help: "Whether the editor service answered its last request (1) or could not be reached (0).",
+	}, []string{"service"})
+
+	commandRuns = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "mcp_unreal_subprocess_runs_total",
+		Help: "Headless subprocess runs by command and outcome (ok for exit code 0, else error).",
+	}, []string{"command", "outcome"})
+
+	commandDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "mcp_unreal_subprocess_duration_seconds",
+		Help:    "Headless subprocess run time by command.",
+		Buckets: []float64{1, 5, 15, 30, 60, 120, 300, 600, 1200, 1800, 3600},
+	}, []string{"command"})
+)
+
+func init() {
+	registry.MustRegister(
+		toolCalls, toolDuration,
+		editorRequests, editorDuration, editorUp,
+		commandRuns, commandDuration,
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+}
+
+// Handler serves the metrics in the Prometheus text format.
+func Handler() http.Handler {
+	return promhttp.HandlerFor(registry, promhttp.HandlerOpts{})
+}
+
+// ServeMetrics serves /metrics on 127.0.0.1:port until ctx is done. It
+// returns once the port is bound, or with the error that prevented it.
+// Metrics stay on loopback like the editor endpoints (CLAUDE.md
+// Security §3).
+func ServeMetrics(ctx context.Context, port int, logger *slog.Logger) error {
+	ln, err := net.Listen("tcp", net.JoinHostPort("127.0.0.1", strconv.Itoa(port)))
+	if err != nil {
+		return fmt.Errorf("listening for metrics: %w", err)
+	}
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", Handler())
+	srv := &http.Server{Handler: mux, ReadHeaderTimeout: 5 * time.Second}
+	go func() {
+		<-ctx.Done()
+		_ = srv.Close()
+	}()
+	go func() {
+		if err := srv.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.Warn("metrics server stopped", "error", err)
+		}
+	}()
+	logger.Debug("serving metrics", "addr", "http://"+ln.Addr().String()+"/metrics")
+	return nil
+}
+
+func outcome(failed bool) string {
+	if failed {
+		return outcomeError
+	}
+	return outcomeOK
+}
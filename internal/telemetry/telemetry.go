@@ -0,0 +1,109 @@
+// Copyright (c) mcp-unreal project contributors. Apache-2.0 license.
+
+// Package telemetry traces and measures the server: OpenTelemetry spans
+// for tool calls, editor HTTP requests, and headless subprocesses, and
+// Prometheus metrics for the same on an optional localhost port.
+//
+// Spans are recorded only after Setup installs an exporter; until then
+// the global tracer is a no-op. Metrics are always counted and served
+// only if ServeMetrics is called.
+package telemetry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/remiphilippe/mcp-unreal/internal/config"
+)
+
+// instrumentation names the tracer of this module.
+const instrumentation = "github.com/remiphilippe/mcp-unreal"
+
+// tracer returns the module's tracer from the global provider, so spans
+// follow whatever Setup installed.
+func tracer() trace.Tracer {
+	return otel.Tracer(instrumentation)
+}
+
+// Setup installs the span exporter chosen by cfg.TraceExporter as the
+// global tracer provider. It returns a function that flushes and stops
+// the exporter; with tracing off, both are no-ops. Spans never go to
+// stdout, which carries the MCP protocol (CLAUDE.md Security §1).
+func Setup(ctx context.Context, cfg *config.Config, version string, logger *slog.Logger) (func(context.Context) error, error) {
+	var (
+		exporter sdktrace.SpanExporter
+		closer   func() error
+		err      error
+	)
+	switch cfg.TraceExporter {
+	case "", "off", "none":
+		return func(context.Context) error { return nil }, nil
+	case "otlp":
+		exporter, err = otlptracehttp.New(ctx, otlpOptions()...)
+	case "file":
+		if cfg.TraceFile == "" {
+			return nil, errors.New("trace exporter file needs MCP_UNREAL_TRACE_FILE or a project root")
+		}
+		var f *os.File
+		if f, err = openTraceFile(cfg.TraceFile); err == nil {
+			closer = f.Close
+			exporter, err = stdouttrace.New(stdouttrace.WithWriter(f))
+		}
+	default:
+		return nil, fmt.Errorf("unknown trace exporter %q — use otlp or file", cfg.TraceExporter)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("creating %s trace exporter: %w", cfg.TraceExporter, err)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(resource.NewSchemaless(
+			attribute.String("service.name", "mcp-unreal"),
+			attribute.String("service.version", version),
+		)),
+	)
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	otel.SetErrorHandler(otel.ErrorHandlerFunc(func(err error) {
+		logger.Debug("telemetry export failed", "error", err)
+	}))
+	logger.Debug("tracing enabled", "exporter", cfg.TraceExporter)
+
+	return func(ctx context.Context) error {
+		err := provider.Shutdown(ctx)
+		if closer != nil {
+			err = errors.Join(err, closer())
+		}
+		return err
+	}, nil
+}
+
+// otlpOptions points the OTLP exporter at a local collector over plain
+// HTTP unless the standard OTEL_EXPORTER_OTLP_* variables say otherwise.
+func otlpOptions() []otlptracehttp.Option {
+	if os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT") != "" || os.Getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT") != "" {
+		return nil
+	}
+	return []otlptracehttp.Option{otlptracehttp.WithEndpoint("localhost:4318"), otlptracehttp.WithInsecure()}
+}
+
+func openTraceFile(path string) (*os.File, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, err
+	}
+	return os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+}
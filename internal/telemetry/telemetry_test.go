@@ -0,0 +1,209 @@
+// Copyright (c) mcp-unreal project contributors. Apache-2.0 license.
+
+package telemetry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"github.com/remiphilippe/mcp-unreal/internal/config"
+)
+
+func testLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(io.Discard, nil))
+}
+
+// recordSpans installs a global tracer provider that records spans in
+// memory for the rest of the test.
+func recordSpans(t *testing.T) *tracetest.SpanRecorder {
+	t.Helper()
+	rec := tracetest.NewSpanRecorder()
+	prev := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(rec)))
+	t.Cleanup(func() { otel.SetTracerProvider(prev) })
+	return rec
+}
+
+// scrape returns the metrics page.
+func scrape(t *testing.T) string {
+	t.Helper()
+	rr := httptest.NewRecorder()
+	Handler().ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	return rr.Body.String()
+}
+
+func TestMiddleware(t *testing.T) {
+	rec := recordSpans(t)
+
+	server := mcp.NewServer(&mcp.Implementation{Name: "test"}, nil)
+	server.AddReceivingMiddleware(Middleware)
+	type in struct {
+		Operation string `json:"operation"`
+	}
+	mcp.AddTool(server, &mcp.Tool{Name: "probe"}, func(ctx context.Context, req *mcp.CallToolRequest, input in) (*mcp.CallToolResult, struct{}, error) {
+		// Editor requests made by the handler are children of the tool span.
+		_, end := StartEditorRequest(ctx, "plugin", http.MethodPost, "/api/probe")
+		end(200, 1, nil)
+		if input.Operation == "fail" {
+			return nil, struct{}{}, errors.New("probe failed")
+		}
+		return nil, struct{}{}, nil
+	})
+
+	ctx := context.Background()
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	ss, err := server.Connect(ctx, serverTransport, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = ss.Close() }()
+	session, err := mcp.NewClient(&mcp.Implementation{Name: "test-client"}, nil).Connect(ctx, clientTransport, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = session.Close() }()
+
+	for _, op := range []string{"ok", "fail"} {
+		if _, err := session.CallTool(ctx, &mcp.CallToolParams{Name: "probe", Arguments: map[string]any{"operation": op}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var tools []sdktrace.ReadOnlySpan
+	parents := map[string]bool{}
+	for _, s := range rec.Ended() {
+		switch s.Name() {
+		case "tools/call probe":
+			tools = append(tools, s)
+		case "editor POST /api/probe":
+			parents[s.Parent().SpanID().String()] = true
+		}
+	}
+	if len(tools) != 2 {
+		t.Fatalf("got %d tool spans, want 2", len(tools))
+	}
+	for _, s := range tools {
+		if !parents[s.SpanContext().SpanID().String()] {
+			t.Errorf("editor span is not a child of %s", s.Name())
+		}
+	}
+	if tools[0].Status().Code == codes.Error || tools[1].Status().Code != codes.Error ||
+		!strings.Contains(tools[1].Status().Description, "probe failed") {
+		t.Errorf("span statuses = %v, %v", tools[0].Status(), tools[1].Status())
+	}
+
+	metrics := scrape(t)
+	for _, want := range []string{
+		`mcp_unreal_tool_calls_total{outcome="ok",tool="probe"} 1`,
+		`mcp_unreal_tool_calls_total{outcome="error",tool="probe"} 1`,
+		`mcp_unreal_tool_call_duration_seconds_count{tool="probe"} 2`,
+		`mcp_unreal_editor_requests_total{endpoint="/api/probe",outcome="ok",service="plugin"} 2`,
+	} {
+		if !strings.Contains(metrics, want) {
+			t.Errorf("metrics missing %s", want)
+		}
+	}
+}
+
+func TestStartEditorRequest_Availability(t *testing.T) {
+	_, end := StartEditorRequest(context.Background(), "rc_api", http.MethodPut, "/remote/object/call")
+	end(0, 3, errors.New("connection refused"))
+	if m := scrape(t); !strings.Contains(m, `mcp_unreal_editor_up{service="rc_api"} 0`) {
+		t.Errorf("rc_api not reported down:\n%s", m)
+	}
+
+	_, end = StartEditorRequest(context.Background(), "rc_api", http.MethodPut, "/remote/object/call")
+	end(404, 1, errors.New("HTTP 404"))
+	if m := scrape(t); !strings.Contains(m, `mcp_unreal_editor_up{service="rc_api"} 1`) {
+		t.Errorf("rc_api not reported up after it answered:\n%s", m)
+	}
+}
+
+func TestStartCommand(t *testing.T) {
+	rec := recordSpans(t)
+	_, end := StartCommand(context.Background(), "/opt/UE/Engine/Build/BatchFiles/Linux/Build.sh", []string{"MyGameEditor", "Linux"})
+	end(6, nil)
+
+	spans := rec.Ended()
+	if len(spans) != 1 || spans[0].Name() != "exec Build.sh" || spans[0].Status().Code != codes.Error {
+		t.Fatalf("spans = %v", spans)
+	}
+	if m := scrape(t); !strings.Contains(m, `mcp_unreal_subprocess_runs_total{command="Build.sh",outcome="error"} 1`) {
+		t.Errorf("subprocess run not counted:\n%s", m)
+	}
+}
+
+func TestSetup_File(t *testing.T) {
+	prev := otel.GetTracerProvider()
+	t.Cleanup(func() { otel.SetTracerProvider(prev) })
+
+	path := filepath.Join(t.TempDir(), "Saved", "traces.jsonl")
+	shutdown, err := Setup(context.Background(), &config.Config{TraceExporter: "file", TraceFile: path}, "test", testLogger())
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, end := StartCommand(context.Background(), "RunUAT.sh", nil)
+	end(0, nil)
+	if err := shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `"Name":"exec RunUAT.sh"`) || !strings.Contains(string(data), "mcp-unreal") {
+		t.Errorf("trace file = %s", data)
+	}
+}
+
+func TestSetup_Off(t *testing.T) {
+	shutdown, err := Setup(context.Background(), &config.Config{}, "test", testLogger())
+	if err != nil || shutdown(context.Background()) != nil {
+		t.Errorf("Setup with tracing off: %v", err)
+	}
+	if _, err := Setup(context.Background(), &config.Config{TraceExporter: "zipkin"}, "test", testLogger()); err == nil {
+		t.Error("unknown exporter accepted")
+	}
+}
+
+func TestServeMetrics(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	// Find a free port.
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	port := ln.Addr().(*net.TCPAddr).Port
+	_ = ln.Close()
+
+	if err := ServeMetrics(ctx, port, testLogger()); err != nil {
+		t.Fatal(err)
+	}
+	resp, err := http.Get(fmt.Sprintf("http://127.0.0.1:%d/metrics", port))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK || !strings.Contains(string(body), "go_goroutines") {
+		t.Errorf("GET /metrics = %d %s", resp.StatusCode, body)
+	}
+}
@@ -0,0 +1,79 @@
+// Copyright (c) mcp-unreal project contributors. Apache-2.0 license.
+
+package toolerr
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Middleware rewrites every tools/call error result into its coded form:
+// "CODE: message" text followed by a "Hint:" line, and structured content
+// {"error": Detail}. Results built without SetError carry no handler
+// error and are coded INTERNAL with their text as the message. Install it
+// with server.AddReceivingMiddleware directly inside the telemetry
+// middleware, so every other layer's errors are coded too.
+func Middleware(next mcp.MethodHandler) mcp.MethodHandler {
+	return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+		res, err := next(ctx, method, req)
+		if r, ok := res.(*mcp.CallToolResult); ok && err == nil && r.IsError {
+			annotate(r)
+		}
+		return res, err
+	}
+}
+
+// annotate replaces the content of the error result r with its coded
+// form. The handler error stays available through r.GetError.
+func annotate(r *mcp.CallToolResult) {
+	cause := r.GetError()
+	if cause == nil {
+		cause = errors.New(text(r))
+	}
+	d := Describe(cause)
+	r.Content = []mcp.Content{&mcp.TextContent{Text: d.Text()}}
+	r.StructuredContent = map[string]Detail{"error": d}
+}
+
+// text returns the text of r, or a generic message if it has none.
+func text(r *mcp.CallToolResult) string {
+	if t := ResultText(r); t != "" {
+		return t
+	}
+	return "tool call failed"
+}
+
+// ResultText joins the text content of r.
+func ResultText(r *mcp.CallToolResult) string {
+	var parts []string
+	for _, c := range r.Content {
+		if tc, ok := c.(*mcp.TextContent); ok {
+			parts = append(parts, tc.Text)
+		}
+	}
+	return strings.Join(parts, "\n")
+}
+
+// FromResult returns the error detail of a result that passed through
+// Middleware, as seen by an MCP client, and whether it has one.
+func FromResult(r *mcp.CallToolResult) (Detail, bool) {
+	if r == nil || !r.IsError {
+		return Detail{}, false
+	}
+	var d Detail
+	switch sc := r.StructuredContent.(type) {
+	case map[string]Detail:
+		d = sc["error"]
+	case map[string]any:
+		e, _ := sc["error"].(map[string]any)
+		code, _ := e["code"].(string)
+		d.Code = Code(code)
+		d.Message, _ = e["message"].(string)
+		d.Hint, _ = e["hint"].(string)
+		d.Retryable, _ = e["retryable"].(bool)
+	}
+	return d, d.Code != ""
+}
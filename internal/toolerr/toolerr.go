@@ -0,0 +1,199 @@
+// Copyright (c) mcp-unreal project contributors. Apache-2.0 license.
+
+// Package toolerr gives tool errors a stable, machine-readable code and a
+// remediation hint, so agents can branch on the code instead of matching
+// message text. Handlers return errors built with New or Wrap, or types
+// that implement Coder; Middleware then rewrites every error result into
+// "CODE: message" text plus a structured {"error": {...}} object.
+//
+// Codes are part of the tool contract: add new ones, but never rename or
+// repurpose an existing code.
+package toolerr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+)
+
+// Code classifies a tool error.
+type Code string
+
+// Error codes. The hint table below documents what each one means.
+const (
+	// EditorOffline: the editor's plugin or Remote Control API could not
+	// be reached.
+	EditorOffline Code = "EDITOR_OFFLINE"
+	// PluginRouteMissing: the MCPUnreal plugin does not serve the
+	// endpoint the tool needs, usually because it is older than the server.
+	PluginRouteMissing Code = "PLUGIN_ROUTE_MISSING"
+	// EditorAuth: the editor refused the request's credentials.
+	EditorAuth Code = "EDITOR_AUTH"
+	// EditorError: the editor received the request and reported a failure.
+	EditorError Code = "EDITOR_ERROR"
+	// Validation: the tool arguments are missing, malformed, or
+	// inconsistent.
+	Validation Code = "VALIDATION"
+	// NotFound: an asset, actor, file, section, key, or version named by
+	// the arguments does not exist.
+	NotFound Code = "NOT_FOUND"
+	// UENotInstalled: the Unreal Engine binaries needed for a headless
+	// operation could not be found.
+	UENotInstalled Code = "UE_NOT_INSTALLED"
+	// ProjectNotFound: no .uproject is configured or found.
+	ProjectNotFound Code = "PROJECT_NOT_FOUND"
+	// Timeout: the operation did not finish in time.
+	Timeout Code = "TIMEOUT"
+	// PIERequired: the operation needs a Play In Editor session.
+	PIERequired Code = "PIE_REQUIRED"
+	// PathRefused: a path argument lies outside the allowed roots.
+	PathRefused Code = "PATH_REFUSED"
+	// PolicyDenied: the server's tool policy refuses the call.
+	PolicyDenied Code = "POLICY_DENIED"
+	// Unsupported: the server was started without the feature the tool
+	// needs.
+	Unsupported Code = "UNSUPPORTED"
+	// DocsUnavailable: the documentation index cannot answer the query.
+	DocsUnavailable Code = "DOCS_UNAVAILABLE"
+	// CheckFailed: a workflow step ran, but its expect condition does not
+	// hold for the result.
+	CheckFailed Code = "CHECK_FAILED"
+	// Internal: an unexpected failure in the server itself.
+	Internal Code = "INTERNAL"
+)
+
+// info holds the default hint of a code and whether retrying the same
+// call unchanged can succeed.
+type info struct {
+	hint      string
+	retryable bool
+}
+
+var codes = map[Code]info{
+	EditorOffline: {"Start the Unreal Editor with the MCPUnreal plugin enabled and Remote Control API on, " +
+		"then retry. Call status to check the connection.", true},
+	PluginRouteMissing: {"Update the MCPUnreal plugin in the project's Plugins/MCPUnreal folder to match " +
+		"this server and restart the editor.", false},
+	EditorAuth:  {"Set MCP_UNREAL_EDITOR_TOKEN to the plugin's mcp.AuthToken, then restart the server.", false},
+	EditorError: {"Check the editor's Output Log (get_output_log) for details, fix the cause, and retry.", false},
+	Validation:  {"Fix the arguments named in the message; the tool's input schema lists the valid values.", false},
+	NotFound:    {"Check the name or path; search_assets, get_level_actors, and status show what exists.", false},
+	UENotInstalled: {"Install Unreal Engine or set UE_EDITOR_PATH to UnrealEditor-Cmd in the engine's " +
+		"Binaries folder, then restart the server.", false},
+	ProjectNotFound: {"Set MCP_UNREAL_PROJECT to the directory containing the .uproject file, " +
+		"or start the server from it.", false},
+	Timeout: {"Retry; if it times out again, narrow the operation (e.g. a test filter) " +
+		"or check that the editor is not blocked by a modal dialog.", true},
+	PIERequired: {"Start Play In Editor with pie_control (operation start) and retry.", false},
+	PathRefused: {"Use a path inside the project, the engine, or MCP_UNREAL_EXTRA_ROOTS; " +
+		"status lists the allowed roots.", false},
+	PolicyDenied: {"The server's tool policy forbids this call; ask the user to change the policy " +
+		"file if it is needed.", false},
+	Unsupported:     {"Restart the server with the feature enabled; the message names the setting.", false},
+	DocsUnavailable: {"Rebuild the documentation index with mcp-unreal --build-index, then restart the server.", false},
+	CheckFailed: {"The tool ran but its result is not what the workflow expects, e.g. a failed build " +
+		"or test; read the step's result in the report.", false},
+	Internal: {"This is unexpected; retry once, and report the message if it persists.", false},
+}
+
+// Coder is implemented by errors that carry a code, such as *Error.
+type Coder interface {
+	ErrorCode() Code
+}
+
+// Error is a tool error with a code and an optional hint that replaces
+// the code's default.
+type Error struct {
+	Code Code
+	Msg  string
+	Hint string
+	err  error
+}
+
+// New returns an error with code and a message formatted like
+// fmt.Errorf; a %w verb wraps its operand.
+func New(code Code, format string, args ...any) *Error {
+	err := fmt.Errorf(format, args...)
+	return &Error{Code: code, Msg: err.Error(), err: errors.Unwrap(err)}
+}
+
+// Wrap returns err with code, keeping its message. It returns nil if err
+// is nil.
+func Wrap(code Code, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &Error{Code: code, Msg: err.Error(), err: err}
+}
+
+// WithHint sets the hint shown instead of the code's default.
+func (e *Error) WithHint(hint string) *Error {
+	e.Hint = hint
+	return e
+}
+
+func (e *Error) Error() string { return e.Msg }
+
+func (e *Error) Unwrap() error { return e.err }
+
+// ErrorCode implements Coder.
+func (e *Error) ErrorCode() Code { return e.Code }
+
+// CodeOf returns the code of the outermost Coder in err's chain. Errors
+// without one are classified by their cause: deadlines are Timeout,
+// missing files NotFound, and anything else Internal.
+func CodeOf(err error) Code {
+	var c Coder
+	switch {
+	case err == nil:
+		return ""
+	case errors.As(err, &c):
+		return c.ErrorCode()
+	case errors.Is(err, context.DeadlineExceeded):
+		return Timeout
+	case errors.Is(err, fs.ErrNotExist):
+		return NotFound
+	}
+	return Internal
+}
+
+// Hint returns the remediation hint for err: its own, if the error that
+// set its code has one, else the code's default.
+func Hint(err error) string {
+	code := CodeOf(err)
+	var e *Error
+	if errors.As(err, &e) && e.Code == code && e.Hint != "" {
+		return e.Hint
+	}
+	return codes[code].hint
+}
+
+// Retryable reports whether the same call may succeed if retried
+// unchanged.
+func Retryable(code Code) bool {
+	return codes[code].retryable
+}
+
+// Detail is the structured form of a tool error.
+type Detail struct {
+	Code      Code   `json:"code" jsonschema:"stable error code, e.g. EDITOR_OFFLINE or VALIDATION"`
+	Message   string `json:"message" jsonschema:"what went wrong"`
+	Hint      string `json:"hint,omitempty" jsonschema:"how to fix it"`
+	Retryable bool   `json:"retryable" jsonschema:"whether retrying the same call unchanged may succeed"`
+}
+
+// Describe returns the structured form of err.
+func Describe(err error) Detail {
+	code := CodeOf(err)
+	return Detail{Code: code, Message: err.Error(), Hint: Hint(err), Retryable: Retryable(code)}
+}
+
+// Text formats d as the text content of an error result.
+func (d Detail) Text() string {
+	text := string(d.Code) + ": " + d.Message
+	if d.Hint != "" {
+		text += "\nHint: " + d.Hint
+	}
+	return text
+}
@@ -0,0 +1,148 @@
+// Copyright (c) mcp-unreal project contributors. Apache-2.0 license.
+
+package toolerr
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// coded is a Coder that is not an *Error.
+type coded struct{}
+
+func (coded) Error() string   { return "HTTP 401" }
+func (coded) ErrorCode() Code { return EditorAuth }
+
+func TestCodeOf(t *testing.T) {
+	_, statErr := os.Stat("/nonexistent/mcp-unreal")
+	tests := []struct {
+		name string
+		err  error
+		want Code
+	}{
+		{"nil", nil, ""},
+		{"new", New(Validation, "label is required"), Validation},
+		{"wrapped", fmt.Errorf("editor unreachable: %w", New(NotFound, "Actor not found")), NotFound},
+		{"outermost wins", New(EditorOffline, "unreachable: %w", New(Timeout, "deadline")), EditorOffline},
+		{"coder", fmt.Errorf("plugin: %w", coded{}), EditorAuth},
+		{"deadline", fmt.Errorf("waiting: %w", context.DeadlineExceeded), Timeout},
+		{"missing file", statErr, NotFound},
+		{"plain", errors.New("boom"), Internal},
+	}
+	for _, tt := range tests {
+		if got := CodeOf(tt.err); got != tt.want {
+			t.Errorf("%s: CodeOf = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestNew_Wraps(t *testing.T) {
+	err := New(DocsUnavailable, "search failed: %w", os.ErrClosed)
+	if !errors.Is(err, os.ErrClosed) || err.Error() != "search failed: file already closed" {
+		t.Errorf("New = %q, unwraps to ErrClosed: %v", err, errors.Is(err, os.ErrClosed))
+	}
+	if Wrap(NotFound, nil) != nil {
+		t.Error("Wrap(nil) is not nil")
+	}
+}
+
+func TestHint(t *testing.T) {
+	if got := Hint(New(PIERequired, "PIE is not running")); !strings.Contains(got, "pie_control") {
+		t.Errorf("default hint = %q", got)
+	}
+	if got := Hint(New(Validation, "bad").WithHint("use foo")); got != "use foo" {
+		t.Errorf("own hint = %q", got)
+	}
+	// An inner hint belongs to the inner code, not the outer one.
+	err := New(EditorOffline, "unreachable: %w", New(Validation, "bad").WithHint("use foo"))
+	if got := Hint(err); got != codes[EditorOffline].hint {
+		t.Errorf("outer hint = %q", got)
+	}
+	for code, info := range codes {
+		if info.hint == "" {
+			t.Errorf("%s has no hint", code)
+		}
+	}
+}
+
+func TestDescribe(t *testing.T) {
+	d := Describe(New(EditorOffline, "plugin unreachable at http://127.0.0.1:8090"))
+	if d.Code != EditorOffline || !d.Retryable || d.Hint == "" {
+		t.Errorf("Describe = %+v", d)
+	}
+	want := "EDITOR_OFFLINE: plugin unreachable at http://127.0.0.1:8090\nHint: " + d.Hint
+	if d.Text() != want {
+		t.Errorf("Text = %q, want %q", d.Text(), want)
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	server := mcp.NewServer(&mcp.Implementation{Name: "test"}, nil)
+	server.AddReceivingMiddleware(Middleware)
+	type in struct {
+		Fail string `json:"fail"`
+	}
+	type out struct {
+		OK bool `json:"ok"`
+	}
+	mcp.AddTool(server, &mcp.Tool{Name: "probe"}, func(ctx context.Context, req *mcp.CallToolRequest, input in) (*mcp.CallToolResult, out, error) {
+		switch input.Fail {
+		case "validation":
+			return nil, out{}, New(Validation, "operation is required")
+		case "plain":
+			return nil, out{}, errors.New("boom")
+		}
+		return nil, out{OK: true}, nil
+	})
+
+	ctx := context.Background()
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	ss, err := server.Connect(ctx, serverTransport, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = ss.Close() }()
+	session, err := mcp.NewClient(&mcp.Implementation{Name: "test-client"}, nil).Connect(ctx, clientTransport, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = session.Close() }()
+
+	call := func(fail string) *mcp.CallToolResult {
+		t.Helper()
+		res, err := session.CallTool(ctx, &mcp.CallToolParams{Name: "probe", Arguments: map[string]any{"fail": fail}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return res
+	}
+
+	res := call("validation")
+	d, ok := FromResult(res)
+	if !ok || d.Code != Validation || d.Message != "operation is required" || d.Retryable {
+		t.Errorf("FromResult = %+v, %v", d, ok)
+	}
+	if text := res.Content[0].(*mcp.TextContent).Text; !strings.HasPrefix(text, "VALIDATION: operation is required\nHint: ") {
+		t.Errorf("text = %q", text)
+	}
+
+	if d, _ := FromResult(call("plain")); d.Code != Internal || d.Message != "boom" {
+		t.Errorf("uncoded error = %+v", d)
+	}
+
+	res = call("")
+	if _, ok := FromResult(res); ok || res.IsError {
+		t.Errorf("success result annotated: %+v", res)
+	}
+	data, _ := json.Marshal(res.StructuredContent)
+	if string(data) != `{"ok":true}` {
+		t.Errorf("success structured content = %s", data)
+	}
+}
@@ -0,0 +1,483 @@
+// Copyright (c) mcp-unreal project contributors. Apache-2.0 license.
+
+package workflow
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Cond is a parsed step condition, the "if" or "expect" of a step:
+//
+//	${build.success}
+//	failed(tests) && ${tests.failed} > 0
+//	!${pie.pie_active} || ${params.force} == true
+//
+// Operands are ${name.path} references, numbers, quoted strings, true,
+// false, null, and bare words (strings). Comparisons are == != < <= > >=,
+// combined with !, &&, ||, and parentheses. The status functions
+// success() and failure() report whether an earlier step has failed,
+// always() is true, and succeeded(id), failed(id), and skipped(id) test
+// one step. A value is true unless it is false, null, 0, "", or empty.
+type Cond struct {
+	src  string
+	root node
+
+	// names are the reference names and status function arguments used.
+	names []string
+	// status is set if the condition calls a status function.
+	status bool
+}
+
+// env evaluates conditions against a run.
+type env struct {
+	// lookup returns a reference's value, or an error if it cannot be
+	// resolved, which conditions treat as null.
+	lookup func(name, path string) (any, error)
+	// stepStatus returns "ok", "error", "skipped", or "" if the step has
+	// not run.
+	stepStatus func(id string) string
+	// failed is set once a step has failed.
+	failed bool
+}
+
+type node interface {
+	eval(e *env) (any, error)
+}
+
+// ParseCond parses a condition.
+func ParseCond(src string) (*Cond, error) {
+	toks, err := tokenize(src)
+	if err != nil {
+		return nil, fmt.Errorf("condition %q: %w", src, err)
+	}
+	p := &parser{toks: toks, cond: &Cond{src: src}}
+	root, err := p.or()
+	if err == nil && p.pos < len(p.toks) {
+		err = fmt.Errorf("unexpected %q", p.toks[p.pos].text)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("condition %q: %w", src, err)
+	}
+	p.cond.root = root
+	return p.cond, nil
+}
+
+func (c *Cond) String() string { return c.src }
+
+// Eval reports whether the condition holds.
+func (c *Cond) Eval(e *env) (bool, error) {
+	v, err := c.root.eval(e)
+	if err != nil {
+		return false, fmt.Errorf("condition %q: %w", c.src, err)
+	}
+	return truthy(v), nil
+}
+
+// --- tokens ---
+
+type tokKind int
+
+const (
+	tokOp tokKind = iota
+	tokRef
+	tokString
+	tokNumber
+	tokWord
+)
+
+type token struct {
+	kind tokKind
+	text string
+	// name and path of a reference.
+	name, path string
+	num        float64
+}
+
+// operators, longest first.
+var operators = []string{"&&", "||", "==", "!=", "<=", ">=", "<", ">", "!", "(", ")"}
+
+func tokenize(src string) ([]token, error) {
+	var toks []token
+	for i := 0; i < len(src); {
+		c := src[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case strings.HasPrefix(src[i:], "${"):
+			end := strings.IndexByte(src[i:], '}')
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated reference at %q", src[i:])
+			}
+			ref := src[i+2 : i+end]
+			name, path, _ := strings.Cut(ref, ".")
+			if !isIdent(name) {
+				return nil, fmt.Errorf("invalid reference ${%s}", ref)
+			}
+			if path != "" {
+				path = "." + path
+			}
+			toks = append(toks, token{kind: tokRef, text: src[i : i+end+1], name: name, path: path})
+			i += end + 1
+		case c == '"' || c == '\'':
+			end := strings.IndexByte(src[i+1:], c)
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated string at %q", src[i:])
+			}
+			toks = append(toks, token{kind: tokString, text: src[i+1 : i+1+end]})
+			i += end + 2
+		case c == '-' || c == '.' || (c >= '0' && c <= '9'):
+			j := i + 1
+			for j < len(src) && strings.IndexByte("0123456789.eE+-", src[j]) >= 0 {
+				j++
+			}
+			n, err := strconv.ParseFloat(src[i:j], 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid number %q", src[i:j])
+			}
+			toks = append(toks, token{kind: tokNumber, text: src[i:j], num: n})
+			i = j
+		case isIdentByte(c):
+			j := i + 1
+			for j < len(src) && (isIdentByte(src[j]) || src[j] == '-') {
+				j++
+			}
+			toks = append(toks, token{kind: tokWord, text: src[i:j]})
+			i = j
+		default:
+			op := ""
+			for _, o := range operators {
+				if strings.HasPrefix(src[i:], o) {
+					op = o
+					break
+				}
+			}
+			if op == "" {
+				return nil, fmt.Errorf("unexpected %q", string(c))
+			}
+			toks = append(toks, token{kind: tokOp, text: op})
+			i += len(op)
+		}
+	}
+	if len(toks) == 0 {
+		return nil, fmt.Errorf("empty condition")
+	}
+	return toks, nil
+}
+
+func isIdentByte(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
+
+func isIdent(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if !isIdentByte(s[i]) && s[i] != '-' {
+			return false
+		}
+	}
+	return true
+}
+
+// --- parser ---
+
+type parser struct {
+	toks []token
+	pos  int
+	cond *Cond
+}
+
+func (p *parser) peek(op string) bool {
+	return p.pos < len(p.toks) && p.toks[p.pos].kind == tokOp && p.toks[p.pos].text == op
+}
+
+func (p *parser) expect(op string) error {
+	if !p.peek(op) {
+		if p.pos < len(p.toks) {
+			return fmt.Errorf("expected %q, got %q", op, p.toks[p.pos].text)
+		}
+		return fmt.Errorf("expected %q at the end", op)
+	}
+	p.pos++
+	return nil
+}
+
+func (p *parser) or() (node, error) {
+	left, err := p.and()
+	for err == nil && p.peek("||") {
+		p.pos++
+		var right node
+		if right, err = p.and(); err == nil {
+			left = orNode{left, right}
+		}
+	}
+	return left, err
+}
+
+func (p *parser) and() (node, error) {
+	left, err := p.unary()
+	for err == nil && p.peek("&&") {
+		p.pos++
+		var right node
+		if right, err = p.unary(); err == nil {
+			left = andNode{left, right}
+		}
+	}
+	return left, err
+}
+
+func (p *parser) unary() (node, error) {
+	if p.peek("!") {
+		p.pos++
+		n, err := p.unary()
+		return notNode{n}, err
+	}
+	return p.compare()
+}
+
+func (p *parser) compare() (node, error) {
+	left, err := p.primary()
+	if err != nil {
+		return nil, err
+	}
+	for _, op := range []string{"==", "!=", "<=", ">=", "<", ">"} {
+		if p.peek(op) {
+			p.pos++
+			right, err := p.primary()
+			if err != nil {
+				return nil, err
+			}
+			return cmpNode{op, left, right}, nil
+		}
+	}
+	return left, nil
+}
+
+// statusFuncs maps status functions to whether they take a step id.
+var statusFuncs = map[string]bool{
+	"success": false, "failure": false, "always": false,
+	"succeeded": true, "failed": true, "skipped": true,
+}
+
+func (p *parser) primary() (node, error) {
+	if p.pos >= len(p.toks) {
+		return nil, fmt.Errorf("unexpected end")
+	}
+	t := p.toks[p.pos]
+	p.pos++
+	switch t.kind {
+	case tokRef:
+		p.cond.names = append(p.cond.names, t.name)
+		return refNode{t.name, t.path}, nil
+	case tokString:
+		return literal{t.text}, nil
+	case tokNumber:
+		return literal{t.num}, nil
+	case tokWord:
+		if p.peek("(") {
+			takesID, ok := statusFuncs[t.text]
+			if !ok {
+				return nil, fmt.Errorf("unknown function %s() — use success, failure, always, succeeded, failed, or skipped", t.text)
+			}
+			p.pos++
+			f := funcNode{name: t.text}
+			if takesID {
+				if p.pos >= len(p.toks) || p.toks[p.pos].kind != tokWord {
+					return nil, fmt.Errorf("%s() takes a step id", t.text)
+				}
+				f.id = p.toks[p.pos].text
+				p.cond.names = append(p.cond.names, f.id)
+				p.pos++
+			}
+			p.cond.status = true
+			return f, p.expect(")")
+		}
+		switch t.text {
+		case "true":
+			return literal{true}, nil
+		case "false":
+			return literal{false}, nil
+		case "null":
+			return literal{nil}, nil
+		}
+		return literal{t.text}, nil
+	default:
+		if t.text == "(" {
+			n, err := p.or()
+			if err != nil {
+				return nil, err
+			}
+			return n, p.expect(")")
+		}
+		return nil, fmt.Errorf("unexpected %q", t.text)
+	}
+}
+
+// --- evaluation ---
+
+type literal struct{ v any }
+
+func (l literal) eval(*env) (any, error) { return l.v, nil }
+
+type refNode struct{ name, path string }
+
+func (r refNode) eval(e *env) (any, error) {
+	v, err := e.lookup(r.name, r.path)
+	if err != nil {
+		return nil, nil // unresolvable references are null
+	}
+	return v, nil
+}
+
+type funcNode struct{ name, id string }
+
+func (f funcNode) eval(e *env) (any, error) {
+	switch f.name {
+	case "success":
+		return !e.failed, nil
+	case "failure":
+		return e.failed, nil
+	case "always":
+		return true, nil
+	case "succeeded":
+		return e.stepStatus(f.id) == statusOK, nil
+	case "failed":
+		return e.stepStatus(f.id) == statusError, nil
+	default:
+		return e.stepStatus(f.id) == statusSkipped, nil
+	}
+}
+
+type notNode struct{ n node }
+
+func (n notNode) eval(e *env) (any, error) {
+	v, err := n.n.eval(e)
+	return !truthy(v), err
+}
+
+type andNode struct{ l, r node }
+
+func (n andNode) eval(e *env) (any, error) {
+	v, err := n.l.eval(e)
+	if err != nil || !truthy(v) {
+		return false, err
+	}
+	v, err = n.r.eval(e)
+	return truthy(v), err
+}
+
+type orNode struct{ l, r node }
+
+func (n orNode) eval(e *env) (any, error) {
+	v, err := n.l.eval(e)
+	if err != nil || truthy(v) {
+		return err == nil, err
+	}
+	v, err = n.r.eval(e)
+	return truthy(v), err
+}
+
+type cmpNode struct {
+	op   string
+	l, r node
+}
+
+func (n cmpNode) eval(e *env) (any, error) {
+	a, err := n.l.eval(e)
+	if err != nil {
+		return nil, err
+	}
+	b, err := n.r.eval(e)
+	if err != nil {
+		return nil, err
+	}
+	switch n.op {
+	case "==":
+		return equal(a, b), nil
+	case "!=":
+		return !equal(a, b), nil
+	}
+	c, ok := order(a, b)
+	if !ok {
+		return false, nil // unordered values, e.g. null, compare false
+	}
+	switch n.op {
+	case "<":
+		return c < 0, nil
+	case "<=":
+		return c <= 0, nil
+	case ">":
+		return c > 0, nil
+	default:
+		return c >= 0, nil
+	}
+}
+
+// truthy reports whether v counts as true.
+func truthy(v any) bool {
+	switch v := v.(type) {
+	case nil:
+		return false
+	case bool:
+		return v
+	case float64:
+		return v != 0
+	case string:
+		return v != ""
+	case []any:
+		return len(v) > 0
+	case map[string]any:
+		return len(v) > 0
+	}
+	return true
+}
+
+// number returns v as a number, parsing numeric strings.
+func number(v any) (float64, bool) {
+	switch v := v.(type) {
+	case float64:
+		return v, true
+	case string:
+		n, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		return n, err == nil
+	}
+	return 0, false
+}
+
+func equal(a, b any) bool {
+	_, aStr := a.(string)
+	_, bStr := b.(string)
+	if !aStr || !bStr {
+		if x, ok := number(a); ok {
+			if y, ok := number(b); ok {
+				return x == y
+			}
+		}
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+// order compares a and b as numbers, or else as strings.
+func order(a, b any) (int, bool) {
+	if x, ok := number(a); ok {
+		if y, ok := number(b); ok {
+			switch {
+			case x < y:
+				return -1, true
+			case x > y:
+				return 1, true
+			}
+			return 0, true
+		}
+	}
+	x, aok := a.(string)
+	y, bok := b.(string)
+	if !aok || !bok {
+		return 0, false
+	}
+	return strings.Compare(x, y), true
+}
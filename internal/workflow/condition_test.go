@@ -0,0 +1,86 @@
+// Copyright (c) mcp-unreal project contributors. Apache-2.0 license.
+
+package workflow
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/remiphilippe/mcp-unreal/internal/batch"
+)
+
+func TestCond(t *testing.T) {
+	results := map[string]any{
+		"build": map[string]any{"success": true, "error_count": float64(0), "target": "DemoEditor"},
+		"tests": map[string]any{"success": false, "failed": float64(2), "log_path": ""},
+	}
+	statuses := map[string]string{"build": statusOK, "tests": statusError, "log": statusSkipped}
+	e := &env{
+		lookup: func(name, path string) (any, error) {
+			v, ok := results[name]
+			if !ok {
+				return nil, errors.New("no result")
+			}
+			return batch.Select(v, name, path)
+		},
+		stepStatus: func(id string) string { return statuses[id] },
+		failed:     true,
+	}
+
+	tests := []struct {
+		cond   string
+		want   bool
+		status bool
+	}{
+		{"${build.success}", true, false},
+		{"!${tests.success}", true, false},
+		{"${tests.failed} > 0", true, false},
+		{"${tests.failed} >= 3", false, false},
+		{"${build.error_count} == 0 && ${build.target} == DemoEditor", true, false},
+		{"${build.target} != 'DemoEditor'", false, false},
+		{`${tests.failed} == "2"`, true, false},
+		{"${tests.log_path}", false, false},
+		{"${missing.field} == null", true, false},
+		{"${tests.nope} > 1", false, false},
+		{"failed(tests) && (${tests.failed} > 5 || succeeded(build))", true, true},
+		{"skipped(log)", true, true},
+		{"success()", false, true},
+		{"failure() || always()", true, true},
+		{"!always()", false, true},
+	}
+	for _, tt := range tests {
+		c, err := ParseCond(tt.cond)
+		if err != nil {
+			t.Errorf("ParseCond(%q): %v", tt.cond, err)
+			continue
+		}
+		got, err := c.Eval(e)
+		if err != nil {
+			t.Errorf("%q: %v", tt.cond, err)
+			continue
+		}
+		if got != tt.want || c.status != tt.status {
+			t.Errorf("%q = %v (status %v), want %v (status %v)", tt.cond, got, c.status, tt.want, tt.status)
+		}
+	}
+}
+
+func TestParseCondErrors(t *testing.T) {
+	tests := []struct{ cond, want string }{
+		{"", "empty condition"},
+		{"${build.success", "unterminated reference"},
+		{"'open", "unterminated string"},
+		{"${build.success} &&", "unexpected end"},
+		{"(${a}", `expected ")"`},
+		{"retry(build)", "unknown function retry()"},
+		{"failed()", "takes a step id"},
+		{"${a} ${b}", "unexpected"},
+		{"${a} = 1", `unexpected "="`},
+	}
+	for _, tt := range tests {
+		if _, err := ParseCond(tt.cond); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("ParseCond(%q) = %v, want error containing %q", tt.cond, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,250 @@
+// Copyright (c) mcp-unreal project contributors. Apache-2.0 license.
+
+package workflow
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/remiphilippe/mcp-unreal/internal/batch"
+	"github.com/remiphilippe/mcp-unreal/internal/loopback"
+	"github.com/remiphilippe/mcp-unreal/internal/toolerr"
+)
+
+// Input defines parameters for the run_workflow tool.
+type Input struct {
+	Name       string         `json:"name,omitempty" jsonschema:"Named workflow from the library; list=true shows them"`
+	Definition any            `json:"definition,omitempty" jsonschema:"Inline workflow instead of a name: an object, or YAML or JSON text, with steps and optional description, params, and timeout"`
+	Params     map[string]any `json:"params,omitempty" jsonschema:"Values for the workflow's params, referenced in steps as ${params.name}"`
+	List       bool           `json:"list,omitempty" jsonschema:"List the named workflows instead of running one"`
+}
+
+// StepResult reports the outcome of one step.
+type StepResult struct {
+	Index      int    `json:"index" jsonschema:"0-based step index"`
+	ID         string `json:"id,omitempty" jsonschema:"step id, if given"`
+	Tool       string `json:"tool" jsonschema:"tool name"`
+	Status     string `json:"status" jsonschema:"ok, error, or skipped"`
+	Code       string `json:"code,omitempty" jsonschema:"error code if the step failed, e.g. TIMEOUT or CHECK_FAILED"`
+	Error      string `json:"error,omitempty" jsonschema:"error message if the step failed"`
+	Result     any    `json:"result,omitempty" jsonschema:"the tool's structured result, or its text output"`
+	Skipped    string `json:"skip_reason,omitempty" jsonschema:"why the step was not run"`
+	DurationMS int64  `json:"duration_ms,omitempty" jsonschema:"how long the step ran"`
+}
+
+// Output is returned by the run_workflow tool.
+type Output struct {
+	Workflow   string       `json:"workflow,omitempty" jsonschema:"name of the workflow run"`
+	Status     string       `json:"status,omitempty" jsonschema:"ok, or failed if a step failed without continue_on_error"`
+	Steps      []StepResult `json:"steps,omitempty" jsonschema:"per-step results in order"`
+	Succeeded  int          `json:"succeeded" jsonschema:"number of steps that succeeded"`
+	Failed     int          `json:"failed" jsonschema:"number of steps that failed"`
+	Skipped    int          `json:"skipped" jsonschema:"number of steps not run"`
+	DurationMS int64        `json:"duration_ms" jsonschema:"how long the workflow ran"`
+	Workflows  []Summary    `json:"workflows,omitempty" jsonschema:"the named workflows, with list=true"`
+}
+
+// Handler runs workflows against the tools of Server.
+type Handler struct {
+	Server  *mcp.Server
+	Library *Library
+	Logger  *slog.Logger
+
+	loop loopback.Lazy
+}
+
+// Register adds the run_workflow tool to the MCP server.
+func (h *Handler) Register(server *mcp.Server) {
+	mcp.AddTool(server, &mcp.Tool{
+		Name: ToolName,
+		Description: "Run a workflow: a pipeline of tool steps written in YAML or JSON, e.g. build_project, then " +
+			"run_tests, then get_test_log if tests failed. Give a library workflow's name (list=true shows the " +
+			"built-in and project workflows) or an inline definition with steps of {id, tool, arguments, if, " +
+			"expect, timeout, continue_on_error}. Arguments reference params and earlier results as " +
+			"${params.name} or ${id.path}. if and expect are conditions such as \"${build.success}\", " +
+			"\"failed(tests) && ${tests.failed} > 0\", or \"always()\"; a step whose expect does not hold fails " +
+			"with CHECK_FAILED. After a failure, later steps are skipped unless their if calls failure(), " +
+			"always(), or another status function. Returns each step's status, result, and duration.",
+	}, h.Run)
+}
+
+// Run implements the run_workflow tool.
+func (h *Handler) Run(ctx context.Context, req *mcp.CallToolRequest, input Input) (*mcp.CallToolResult, Output, error) {
+	if input.List {
+		list, err := h.Library.List()
+		if err != nil {
+			return nil, Output{}, err
+		}
+		return nil, Output{Workflows: list}, nil
+	}
+
+	if (input.Name == "") == (input.Definition == nil) {
+		return nil, Output{}, toolerr.New(toolerr.Validation, "give either name or definition")
+	}
+	var w *Workflow
+	var err error
+	switch def := input.Definition.(type) {
+	case nil:
+		w, err = h.Library.Get(input.Name)
+	case string:
+		w, err = Parse([]byte(def))
+	default:
+		w, err = decode(def)
+	}
+	if err != nil {
+		return nil, Output{}, err
+	}
+	params, err := w.params(input.Params)
+	if err != nil {
+		return nil, Output{}, err
+	}
+
+	ctx, err = loopback.Enter(ctx, req)
+	if err != nil {
+		return nil, Output{}, err
+	}
+	session, err := h.loop.Get(h.Server, "workflow")
+	if err != nil {
+		return nil, Output{}, err
+	}
+	out, err := run(ctx, session, w, params)
+	if err != nil {
+		return nil, Output{}, err
+	}
+	h.Logger.Debug("ran workflow", "workflow", w.Name, "status", out.Status,
+		"succeeded", out.Succeeded, "failed", out.Failed, "skipped", out.Skipped)
+	return nil, out, nil
+}
+
+// run executes the steps of w through session.
+func run(ctx context.Context, session *mcp.ClientSession, w *Workflow, params map[string]any) (Output, error) {
+	start := time.Now()
+	if w.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, w.timeout)
+		defer cancel()
+	}
+
+	out := Output{Workflow: w.Name, Status: statusOK, Steps: make([]StepResult, len(w.Steps))}
+	ids := make(map[string]int)
+	e := &env{
+		lookup: func(name, path string) (any, error) {
+			if name == ParamsName {
+				return batch.Select(params, name, path)
+			}
+			i, ok := ids[name]
+			if !ok {
+				return nil, toolerr.New(toolerr.Validation, "reference ${%s%s}: no earlier step with id %q", name, path, name)
+			}
+			// A step that failed its expect still has a result.
+			if r := out.Steps[i]; r.Status == statusSkipped || r.Result == nil {
+				return nil, toolerr.New(toolerr.Validation, "reference ${%s%s}: step %s has no result (%s)", name, path, name, r.Status)
+			}
+			return batch.Select(out.Steps[i].Result, name, path)
+		},
+		stepStatus: func(id string) string {
+			if i, ok := ids[id]; ok {
+				return out.Steps[i].Status
+			}
+			return ""
+		},
+	}
+
+	for i := range w.Steps {
+		s := &w.Steps[i]
+		r := &out.Steps[i]
+		*r = StepResult{Index: i, ID: s.ID, Tool: s.Tool}
+		if s.ID != "" {
+			ids[s.ID] = i
+		}
+
+		skip, err := shouldSkip(ctx, s, e)
+		switch {
+		case errors.Is(ctx.Err(), context.DeadlineExceeded):
+			r.Status, r.Skipped = statusSkipped, fmt.Sprintf("the workflow timed out after %s", w.timeout)
+			out.Skipped++
+			e.failed = true
+			continue
+		case ctx.Err() != nil:
+			return Output{}, ctx.Err()
+		case err == nil && skip != "":
+			r.Status, r.Skipped = statusSkipped, skip
+			out.Skipped++
+			continue
+		}
+
+		stepStart := time.Now()
+		if err == nil {
+			r.Result, err = callStep(ctx, session, s, e.lookup)
+		}
+		if err == nil && s.expect != nil {
+			var ok bool
+			if ok, err = s.expect.Eval(e); err == nil && !ok {
+				err = toolerr.New(toolerr.CheckFailed, "expect %s does not hold", s.expect)
+			}
+		}
+		r.DurationMS = time.Since(stepStart).Milliseconds()
+		if err != nil {
+			r.Status, r.Error, r.Code = statusError, err.Error(), string(toolerr.CodeOf(err))
+			out.Failed++
+			if !s.ContinueOnError {
+				e.failed = true
+				out.Status = "failed"
+			}
+			continue
+		}
+		r.Status = statusOK
+		out.Succeeded++
+	}
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		out.Status = "failed"
+	}
+	out.DurationMS = time.Since(start).Milliseconds()
+	return out, nil
+}
+
+// shouldSkip returns why step s does not run, or "" if it runs.
+func shouldSkip(ctx context.Context, s *Step, e *env) (string, error) {
+	if ctx.Err() != nil {
+		return "", ctx.Err()
+	}
+	if s.cond == nil || !s.cond.status {
+		if e.failed {
+			return "an earlier step failed", nil
+		}
+	}
+	if s.cond == nil {
+		return "", nil
+	}
+	ok, err := s.cond.Eval(e)
+	if err != nil {
+		return "", toolerr.Wrap(toolerr.Validation, err)
+	}
+	if !ok {
+		return "if " + s.cond.String() + " is false", nil
+	}
+	return "", nil
+}
+
+// callStep resolves the arguments of s and calls its tool within the
+// step's timeout.
+func callStep(ctx context.Context, session *mcp.ClientSession, s *Step, lookup batch.Lookup) (any, error) {
+	args, err := batch.Resolve(s.Arguments, lookup)
+	if err != nil {
+		return nil, err
+	}
+	if s.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.timeout)
+		defer cancel()
+	}
+	result, err := batch.Call(ctx, session, s.Tool, args)
+	if err != nil && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return nil, toolerr.New(toolerr.Timeout, "%s timed out", s.Tool)
+	}
+	return result, err
+}
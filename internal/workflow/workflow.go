@@ -0,0 +1,352 @@
+// Copyright (c) mcp-unreal project contributors. Apache-2.0 license.
+
+// Package workflow implements the "run_workflow" MCP tool, which runs a
+// declarative pipeline of tool steps written in YAML or JSON, e.g. build,
+// run the tests, and read the test log if any failed.
+//
+// Like batch, steps reference earlier results and the workflow's params
+// with ${name.path} placeholders, and run through an in-memory client
+// session to the same server, so each one passes the same middleware as a
+// call from the MCP client. On top of batch, a step can have an "if"
+// condition, an "expect" condition its result must meet, and a timeout.
+//
+// Named workflows are YAML or JSON files in the project's workflow
+// directory; a few built-in ones cover common chains and can be
+// overridden by a project file of the same name.
+package workflow
+
+import (
+	"bytes"
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/remiphilippe/mcp-unreal/internal/toolerr"
+	"gopkg.in/yaml.v3"
+)
+
+// ToolName is the name of the workflow tool. Workflows cannot run it.
+const ToolName = "run_workflow"
+
+// MaxSteps bounds the number of steps in one workflow.
+const MaxSteps = 200
+
+// ParamsName is the reference name of the workflow's params, as in
+// ${params.filter}. Steps cannot use it as their id.
+const ParamsName = "params"
+
+// Step statuses in StepResult.Status.
+const (
+	statusOK      = "ok"
+	statusError   = "error"
+	statusSkipped = "skipped"
+)
+
+// Workflow is a parsed workflow definition.
+type Workflow struct {
+	Name        string `json:"name,omitempty"`
+	Description string `json:"description,omitempty"`
+	// Params are the parameters the steps reference as ${params.name},
+	// with their defaults. A null default makes the parameter required.
+	Params map[string]any `json:"params,omitempty"`
+	// Timeout bounds the whole run, as a Go duration such as "45m".
+	Timeout string `json:"timeout,omitempty"`
+	Steps   []Step `json:"steps"`
+
+	timeout time.Duration
+}
+
+// Step is one tool call of a workflow.
+type Step struct {
+	ID        string         `json:"id,omitempty"`
+	Tool      string         `json:"tool"`
+	Arguments map[string]any `json:"arguments,omitempty"`
+	// If is a condition for running the step. Without one, or unless it
+	// calls a status function, the step runs only while no earlier step
+	// has failed.
+	If string `json:"if,omitempty"`
+	// Expect is a condition the step's result must meet, or the step
+	// fails with CHECK_FAILED.
+	Expect string `json:"expect,omitempty"`
+	// Timeout bounds the call, as a Go duration such as "10m".
+	Timeout string `json:"timeout,omitempty"`
+	// ContinueOnError keeps a failure of this step from failing the
+	// workflow and skipping the steps after it.
+	ContinueOnError bool `json:"continue_on_error,omitempty"`
+
+	cond, expect *Cond
+	timeout      time.Duration
+}
+
+// Parse reads a workflow definition from YAML or JSON and checks it.
+func Parse(data []byte) (*Workflow, error) {
+	var raw any
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return nil, toolerr.New(toolerr.Validation, "parsing workflow: %w", err)
+	}
+	return decode(raw)
+}
+
+// decode checks a definition given as a plain value, e.g. parsed YAML or
+// a JSON object from tool arguments.
+func decode(raw any) (*Workflow, error) {
+	// A JSON round trip normalizes YAML values (integers, maps) to what
+	// tools receive and rejects non-string keys.
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return nil, toolerr.New(toolerr.Validation, "parsing workflow: %w", err)
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	var w Workflow
+	if err := dec.Decode(&w); err != nil {
+		return nil, toolerr.New(toolerr.Validation, "parsing workflow: %w", err)
+	}
+	if err := w.check(); err != nil {
+		return nil, err
+	}
+	return &w, nil
+}
+
+// idPattern matches step ids and workflow names.
+var idPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_-]*$`)
+
+// check validates w and parses its conditions and timeouts.
+func (w *Workflow) check() error {
+	if len(w.Steps) == 0 {
+		return toolerr.New(toolerr.Validation, "workflow has no steps")
+	}
+	if len(w.Steps) > MaxSteps {
+		return toolerr.New(toolerr.Validation, "workflow has %d steps, at most %d allowed", len(w.Steps), MaxSteps)
+	}
+	var err error
+	if w.timeout, err = parseTimeout(w.Timeout); err != nil {
+		return toolerr.New(toolerr.Validation, "workflow timeout: %w", err)
+	}
+
+	ids := make(map[string]int)
+	for i := range w.Steps {
+		s := &w.Steps[i]
+		where := fmt.Sprintf("step %d", i)
+		if s.ID != "" {
+			where = fmt.Sprintf("step %d (%s)", i, s.ID)
+		}
+		switch {
+		case s.Tool == "":
+			return toolerr.New(toolerr.Validation, "%s: tool is required", where)
+		case s.Tool == ToolName:
+			return toolerr.New(toolerr.Validation, "%s: workflows cannot be nested", where)
+		case s.ID == ParamsName:
+			return toolerr.New(toolerr.Validation, "%s: id %q is reserved for the workflow's params", where, ParamsName)
+		case s.ID != "" && !idPattern.MatchString(s.ID):
+			return toolerr.New(toolerr.Validation, "%s: id must start with a letter or _ and hold only letters, digits, _ and -", where)
+		}
+		if _, dup := ids[s.ID]; dup && s.ID != "" {
+			return toolerr.New(toolerr.Validation, "%s: duplicate id %q", where, s.ID)
+		}
+		if s.timeout, err = parseTimeout(s.Timeout); err != nil {
+			return toolerr.New(toolerr.Validation, "%s: timeout: %w", where, err)
+		}
+		if s.If != "" {
+			if s.cond, err = ParseCond(s.If); err != nil {
+				return toolerr.New(toolerr.Validation, "%s: if: %w", where, err)
+			}
+			if err := w.checkNames(s.cond, ids); err != nil {
+				return toolerr.New(toolerr.Validation, "%s: if: %w", where, err)
+			}
+		}
+		if s.ID != "" {
+			ids[s.ID] = i
+		}
+		if s.Expect != "" {
+			if s.expect, err = ParseCond(s.Expect); err != nil {
+				return toolerr.New(toolerr.Validation, "%s: expect: %w", where, err)
+			}
+			// expect runs after the step, so it may name the step itself.
+			if err := w.checkNames(s.expect, ids); err != nil {
+				return toolerr.New(toolerr.Validation, "%s: expect: %w", where, err)
+			}
+		}
+	}
+	return nil
+}
+
+// checkNames checks that c names only params and the steps in ids.
+func (w *Workflow) checkNames(c *Cond, ids map[string]int) error {
+	for _, name := range c.names {
+		if _, ok := ids[name]; !ok && name != ParamsName {
+			return fmt.Errorf("no earlier step with id %q", name)
+		}
+	}
+	return nil
+}
+
+func parseTimeout(s string) (time.Duration, error) {
+	if s == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, err
+	}
+	if d <= 0 {
+		return 0, fmt.Errorf("%s is not positive", s)
+	}
+	return d, nil
+}
+
+// params returns w's params with values overridden by given.
+func (w *Workflow) params(given map[string]any) (map[string]any, error) {
+	out := make(map[string]any, len(w.Params))
+	for k, v := range w.Params {
+		out[k] = v
+	}
+	for k, v := range given {
+		if _, ok := w.Params[k]; !ok {
+			return nil, toolerr.New(toolerr.Validation, "unknown param %q — the workflow takes %s", k, paramNames(w.Params))
+		}
+		out[k] = v
+	}
+	for k, v := range out {
+		if v == nil {
+			return nil, toolerr.New(toolerr.Validation, "param %q is required", k)
+		}
+	}
+	return out, nil
+}
+
+func paramNames(params map[string]any) string {
+	if len(params) == 0 {
+		return "no params"
+	}
+	names := make([]string, 0, len(params))
+	for k := range params {
+		names = append(names, k)
+	}
+	slices.Sort(names)
+	return strings.Join(names, ", ")
+}
+
+// --- library ---
+
+//go:embed builtin/*.yaml
+var builtin embed.FS
+
+// BuiltinSource is the Summary.Source of built-in workflows.
+const BuiltinSource = "builtin"
+
+// extensions are the file extensions of workflow files, in lookup order.
+var extensions = []string{".yaml", ".yml", ".json"}
+
+// Library holds the named workflows: the built-in ones, and the files in
+// Dir, which override built-ins of the same name.
+type Library struct {
+	// Dir is the project's workflow directory. Empty means built-ins only.
+	Dir string
+}
+
+// Summary describes a named workflow.
+type Summary struct {
+	Name        string         `json:"name" jsonschema:"workflow name"`
+	Description string         `json:"description,omitempty" jsonschema:"what the workflow does"`
+	Params      map[string]any `json:"params,omitempty" jsonschema:"parameters and their defaults; null means required"`
+	Steps       int            `json:"steps" jsonschema:"number of steps"`
+	Source      string         `json:"source" jsonschema:"file the workflow is defined in, or builtin"`
+	Error       string         `json:"error,omitempty" jsonschema:"why the file cannot be used, if it is invalid"`
+}
+
+// List returns the named workflows, sorted by name. Invalid files are
+// listed with their error.
+func (l *Library) List() ([]Summary, error) {
+	found := make(map[string]Summary)
+	entries, err := fs.ReadDir(builtin, "builtin")
+	if err != nil {
+		return nil, fmt.Errorf("reading built-in workflows: %w", err)
+	}
+	for _, e := range entries {
+		found[strings.TrimSuffix(e.Name(), ".yaml")] = summarize(fs.ReadFile(builtin, path.Join("builtin", e.Name())))
+	}
+	if l.Dir != "" {
+		entries, err := os.ReadDir(l.Dir)
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return nil, fmt.Errorf("reading workflow directory: %w", err)
+		}
+		for _, e := range entries {
+			ext := filepath.Ext(e.Name())
+			name := strings.TrimSuffix(e.Name(), ext)
+			if e.IsDir() || !slices.Contains(extensions, ext) || !idPattern.MatchString(name) {
+				continue
+			}
+			file := filepath.Join(l.Dir, e.Name())
+			s := summarize(os.ReadFile(filepath.Clean(file)))
+			s.Source = file
+			found[name] = s
+		}
+	}
+
+	out := make([]Summary, 0, len(found))
+	for name, s := range found {
+		s.Name = name
+		if s.Source == "" {
+			s.Source = BuiltinSource
+		}
+		out = append(out, s)
+	}
+	slices.SortFunc(out, func(a, b Summary) int { return strings.Compare(a.Name, b.Name) })
+	return out, nil
+}
+
+func summarize(data []byte, err error) Summary {
+	if err != nil {
+		return Summary{Error: err.Error()}
+	}
+	w, err := Parse(data)
+	if err != nil {
+		return Summary{Error: err.Error()}
+	}
+	return Summary{Description: w.Description, Params: w.Params, Steps: len(w.Steps)}
+}
+
+// Get returns the named workflow: a file in Dir, or else a built-in.
+func (l *Library) Get(name string) (*Workflow, error) {
+	if !idPattern.MatchString(name) {
+		return nil, toolerr.New(toolerr.Validation, "invalid workflow name %q", name)
+	}
+	if l.Dir != "" {
+		for _, ext := range extensions {
+			data, err := os.ReadFile(filepath.Join(l.Dir, name+ext))
+			if errors.Is(err, fs.ErrNotExist) {
+				continue
+			}
+			if err != nil {
+				return nil, fmt.Errorf("reading workflow %s: %w", name, err)
+			}
+			return named(name, data)
+		}
+	}
+	data, err := fs.ReadFile(builtin, path.Join("builtin", name+".yaml"))
+	if err != nil {
+		return nil, toolerr.New(toolerr.NotFound, "no workflow named %q — run_workflow with list=true shows the library", name)
+	}
+	return named(name, data)
+}
+
+func named(name string, data []byte) (*Workflow, error) {
+	w, err := Parse(data)
+	if err != nil {
+		return nil, toolerr.New(toolerr.CodeOf(err), "workflow %s: %w", name, err)
+	}
+	if w.Name == "" {
+		w.Name = name
+	}
+	return w, nil
+}
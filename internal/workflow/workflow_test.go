@@ -0,0 +1,348 @@
+// Copyright (c) mcp-unreal project contributors. Apache-2.0 license.
+
+package workflow
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/remiphilippe/mcp-unreal/internal/batch"
+	"github.com/remiphilippe/mcp-unreal/internal/toolerr"
+)
+
+type buildInput struct {
+	Config string `json:"config,omitempty"`
+}
+
+type buildOutput struct {
+	Success bool   `json:"success"`
+	Config  string `json:"config"`
+}
+
+type testsInput struct {
+	Filter string `json:"filter,omitempty"`
+}
+
+type testsOutput struct {
+	Success bool   `json:"success"`
+	Failed  int    `json:"failed"`
+	LogPath string `json:"log_path"`
+}
+
+type logInput struct {
+	LogPath string `json:"log_path"`
+}
+
+type logOutput struct {
+	Content string `json:"content"`
+}
+
+// newTestServer returns a server with the run_workflow tool and fake
+// build, tests, log, and slow tools. build fails its check for config
+// "Broken", tests fail for the filter "Fail.", and slow waits until it
+// is cancelled.
+func newTestServer(t *testing.T, dir string) *mcp.Server {
+	t.Helper()
+	server := mcp.NewServer(&mcp.Implementation{Name: "test"}, nil)
+	mcp.AddTool(server, &mcp.Tool{Name: "build"}, func(_ context.Context, _ *mcp.CallToolRequest, in buildInput) (*mcp.CallToolResult, buildOutput, error) {
+		return nil, buildOutput{Success: in.Config != "Broken", Config: in.Config}, nil
+	})
+	mcp.AddTool(server, &mcp.Tool{Name: "tests"}, func(_ context.Context, _ *mcp.CallToolRequest, in testsInput) (*mcp.CallToolResult, testsOutput, error) {
+		if in.Filter == "Fail." {
+			return nil, testsOutput{Failed: 2, LogPath: "/Logs/Demo.log"}, nil
+		}
+		return nil, testsOutput{Success: true}, nil
+	})
+	mcp.AddTool(server, &mcp.Tool{Name: "log"}, func(_ context.Context, _ *mcp.CallToolRequest, in logInput) (*mcp.CallToolResult, logOutput, error) {
+		if in.LogPath == "" {
+			return nil, logOutput{}, toolerr.New(toolerr.NotFound, "no log")
+		}
+		return nil, logOutput{Content: "Error: in " + in.LogPath}, nil
+	})
+	mcp.AddTool(server, &mcp.Tool{Name: "slow"}, func(ctx context.Context, _ *mcp.CallToolRequest, _ struct{}) (*mcp.CallToolResult, struct{}, error) {
+		<-ctx.Done()
+		return nil, struct{}{}, ctx.Err()
+	})
+	h := &Handler{Server: server, Library: &Library{Dir: dir}, Logger: slog.New(slog.NewTextHandler(io.Discard, nil))}
+	h.Register(server)
+	server.AddReceivingMiddleware(toolerr.Middleware)
+	return server
+}
+
+// runWorkflow calls run_workflow and decodes its output, or returns the
+// error detail of a failed call.
+func runWorkflow(t *testing.T, server *mcp.Server, args map[string]any) (Output, *toolerr.Detail) {
+	t.Helper()
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	if _, err := server.Connect(context.Background(), serverTransport, nil); err != nil {
+		t.Fatal(err)
+	}
+	cs, err := mcp.NewClient(&mcp.Implementation{Name: "agent"}, nil).Connect(context.Background(), clientTransport, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = cs.Close() }()
+	res, err := cs.CallTool(context.Background(), &mcp.CallToolParams{Name: ToolName, Arguments: args})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.IsError {
+		d, _ := toolerr.FromResult(res)
+		return Output{}, &d
+	}
+	var out Output
+	data, _ := json.Marshal(res.StructuredContent)
+	if err := json.Unmarshal(data, &out); err != nil {
+		t.Fatal(err)
+	}
+	return out, nil
+}
+
+func statuses(out Output) []string {
+	var s []string
+	for _, r := range out.Steps {
+		s = append(s, r.Status)
+	}
+	return s
+}
+
+const pipeline = `
+description: Build, test, and read the log on failure.
+params:
+  config: Development
+  filter: "Demo."
+steps:
+  - id: build
+    tool: build
+    arguments: {config: "${params.config}"}
+    expect: ${build.success}
+  - id: tests
+    tool: tests
+    arguments: {filter: "${params.filter}"}
+    expect: ${tests.success}
+  - id: log
+    tool: log
+    if: failed(tests) && ${tests.log_path}
+    arguments: {log_path: "${tests.log_path}"}
+  - id: report
+    tool: log
+    arguments: {log_path: "Report for ${build.config}"}
+`
+
+func TestRunWorkflow(t *testing.T) {
+	server := newTestServer(t, "")
+
+	tests := []struct {
+		name   string
+		params map[string]any
+		want   []string
+		status string
+	}{
+		{"passing", nil, []string{statusOK, statusOK, statusSkipped, statusOK}, statusOK},
+		{"failing tests", map[string]any{"filter": "Fail."}, []string{statusOK, statusError, statusOK, statusSkipped}, "failed"},
+		{"failing build", map[string]any{"config": "Broken"}, []string{statusError, statusSkipped, statusSkipped, statusSkipped}, "failed"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args := map[string]any{"definition": pipeline}
+			if tt.params != nil {
+				args["params"] = tt.params
+			}
+			out, d := runWorkflow(t, server, args)
+			if d != nil {
+				t.Fatalf("run_workflow failed: %+v", d)
+			}
+			if got := statuses(out); !reflect.DeepEqual(got, tt.want) || out.Status != tt.status {
+				t.Errorf("statuses = %v (%s), want %v (%s)", got, out.Status, tt.want, tt.status)
+			}
+		})
+	}
+
+	out, _ := runWorkflow(t, server, map[string]any{"definition": pipeline, "params": map[string]any{"filter": "Fail."}})
+	tests2 := out.Steps[1]
+	if tests2.Code != string(toolerr.CheckFailed) || tests2.Result == nil {
+		t.Errorf("tests step = %+v, want CHECK_FAILED with its result", tests2)
+	}
+	if log, _ := out.Steps[2].Result.(map[string]any); log["content"] != "Error: in /Logs/Demo.log" {
+		t.Errorf("log step result = %v", out.Steps[2].Result)
+	}
+	if out.Succeeded != 2 || out.Failed != 1 || out.Skipped != 1 {
+		t.Errorf("counts = %d/%d/%d, want 2/1/1", out.Succeeded, out.Failed, out.Skipped)
+	}
+	if !strings.Contains(out.Steps[3].Skipped, "earlier step failed") {
+		t.Errorf("report skip reason = %q", out.Steps[3].Skipped)
+	}
+}
+
+func TestRunWorkflowInlineObject(t *testing.T) {
+	server := newTestServer(t, "")
+	out, d := runWorkflow(t, server, map[string]any{"definition": map[string]any{
+		"steps": []any{
+			map[string]any{"id": "a", "tool": "log", "arguments": map[string]any{"log_path": ""}, "continue_on_error": true},
+			map[string]any{"tool": "log", "arguments": map[string]any{"log_path": "x"}},
+			map[string]any{"tool": "log", "if": "failed(a)", "arguments": map[string]any{"log_path": "y"}},
+		},
+	}})
+	if d != nil {
+		t.Fatalf("run_workflow failed: %+v", d)
+	}
+	if got, want := statuses(out), []string{statusError, statusOK, statusOK}; !reflect.DeepEqual(got, want) || out.Status != statusOK {
+		t.Errorf("statuses = %v (%s), want %v (ok)", got, out.Status, want)
+	}
+	if out.Steps[0].Code != string(toolerr.NotFound) {
+		t.Errorf("step 0 code = %q, want NOT_FOUND", out.Steps[0].Code)
+	}
+}
+
+func TestRunWorkflowTimeouts(t *testing.T) {
+	server := newTestServer(t, "")
+	out, d := runWorkflow(t, server, map[string]any{"definition": `
+steps:
+  - {id: wait, tool: slow, timeout: 20ms}
+  - {tool: log, if: always(), arguments: {log_path: after}}
+`})
+	if d != nil {
+		t.Fatalf("run_workflow failed: %+v", d)
+	}
+	if got, want := statuses(out), []string{statusError, statusOK}; !reflect.DeepEqual(got, want) {
+		t.Errorf("statuses = %v, want %v", got, want)
+	}
+	if out.Steps[0].Code != string(toolerr.Timeout) {
+		t.Errorf("step code = %q, want TIMEOUT", out.Steps[0].Code)
+	}
+
+	out, _ = runWorkflow(t, server, map[string]any{"definition": `
+timeout: 20ms
+steps:
+  - {tool: slow}
+  - {tool: log, if: always(), arguments: {log_path: after}}
+`})
+	if got, want := statuses(out), []string{statusError, statusSkipped}; !reflect.DeepEqual(got, want) || out.Status != "failed" {
+		t.Errorf("statuses = %v (%s), want %v (failed)", got, out.Status, want)
+	}
+	if !strings.Contains(out.Steps[1].Skipped, "timed out") {
+		t.Errorf("skip reason = %q", out.Steps[1].Skipped)
+	}
+}
+
+func TestRunWorkflowErrors(t *testing.T) {
+	server := newTestServer(t, "")
+	tests := []struct {
+		name string
+		args map[string]any
+		code toolerr.Code
+		want string
+	}{
+		{"nothing", map[string]any{}, toolerr.Validation, "either name or definition"},
+		{"both", map[string]any{"name": "x", "definition": pipeline}, toolerr.Validation, "either name or definition"},
+		{"unknown name", map[string]any{"name": "nope"}, toolerr.NotFound, `no workflow named "nope"`},
+		{"bad yaml", map[string]any{"definition": "steps: [tool: {"}, toolerr.Validation, "parsing workflow"},
+		{"unknown field", map[string]any{"definition": "steps: [{tool: log, retry: 3}]"}, toolerr.Validation, `unknown field "retry"`},
+		{"no steps", map[string]any{"definition": "description: empty"}, toolerr.Validation, "no steps"},
+		{"nested", map[string]any{"definition": "steps: [{tool: run_workflow}]"}, toolerr.Validation, "cannot be nested"},
+		{"duplicate id", map[string]any{"definition": "steps: [{id: a, tool: log}, {id: a, tool: log}]"}, toolerr.Validation, `duplicate id "a"`},
+		{"reserved id", map[string]any{"definition": "steps: [{id: params, tool: log}]"}, toolerr.Validation, "reserved"},
+		{"bad timeout", map[string]any{"definition": "steps: [{tool: log, timeout: soon}]"}, toolerr.Validation, "timeout"},
+		{"later step in if", map[string]any{"definition": "steps: [{tool: log, if: 'failed(b)'}, {id: b, tool: log}]"}, toolerr.Validation, `no earlier step with id "b"`},
+		{"bad condition", map[string]any{"definition": "steps: [{tool: log, if: '${a} ='}]"}, toolerr.Validation, "condition"},
+		{"unknown param", map[string]any{"definition": pipeline, "params": map[string]any{"filtr": "x"}}, toolerr.Validation, `unknown param "filtr"`},
+		{"required param", map[string]any{"definition": "params: {map: null}\nsteps: [{tool: log}]"}, toolerr.Validation, `param "map" is required`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, d := runWorkflow(t, server, tt.args)
+			if d == nil || d.Code != tt.code || !strings.Contains(d.Message, tt.want) {
+				t.Errorf("error = %+v, want %s containing %q", d, tt.code, tt.want)
+			}
+		})
+	}
+}
+
+func TestRunWorkflowNestingDepth(t *testing.T) {
+	// A workflow that runs itself through batch would recurse forever;
+	// the loopback depth limit stops the innermost meta-tool call.
+	dir := t.TempDir()
+	loop := "steps:\n  - tool: batch\n    arguments:\n      steps: [{tool: run_workflow, arguments: {name: loop}}]\n"
+	if err := os.WriteFile(filepath.Join(dir, "loop.yaml"), []byte(loop), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	server := newTestServer(t, dir)
+	b := &batch.Handler{Server: server, Logger: slog.New(slog.NewTextHandler(io.Discard, nil))}
+	b.Register(server)
+
+	out, d := runWorkflow(t, server, map[string]any{"name": "loop"})
+	if d != nil {
+		t.Fatalf("run_workflow failed: %+v", d)
+	}
+	data, _ := json.Marshal(out)
+	if !strings.Contains(string(data), "batch is nested 3 deep") {
+		t.Errorf("output = %s, want the innermost batch refused for depth", data)
+	}
+}
+
+func TestLibrary(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"smoke.yaml":          "description: Smoke test.\nsteps: [{tool: tests, arguments: {filter: Smoke.}}]\n",
+		"build-and-test.json": `{"description": "Project override.", "steps": [{"tool": "build"}]}`,
+		"broken.yml":          "steps: []\n",
+		"notes.txt":           "not a workflow",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	lib := &Library{Dir: dir}
+
+	list, err := lib.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	byName := make(map[string]Summary)
+	var names []string
+	for _, s := range list {
+		byName[s.Name] = s
+		names = append(names, s.Name)
+	}
+	if want := []string{"broken", "build-and-test", "live-compile-and-capture", "smoke"}; !reflect.DeepEqual(names, want) {
+		t.Fatalf("names = %v, want %v", names, want)
+	}
+	if s := byName["build-and-test"]; s.Description != "Project override." || s.Source != filepath.Join(dir, "build-and-test.json") {
+		t.Errorf("override = %+v", s)
+	}
+	if s := byName["live-compile-and-capture"]; s.Source != BuiltinSource || s.Steps != 5 || s.Error != "" {
+		t.Errorf("builtin = %+v", s)
+	}
+	if s := byName["broken"]; !strings.Contains(s.Error, "no steps") {
+		t.Errorf("broken = %+v", s)
+	}
+
+	w, err := lib.Get("smoke")
+	if err != nil || w.Name != "smoke" || len(w.Steps) != 1 {
+		t.Errorf("Get(smoke) = %+v, %v", w, err)
+	}
+	if _, err := lib.Get("../smoke"); toolerr.CodeOf(err) != toolerr.Validation {
+		t.Errorf("Get(../smoke) = %v, want VALIDATION", err)
+	}
+
+	// Built-ins are available without a project directory.
+	w, err = (&Library{}).Get("build-and-test")
+	if err != nil || w.Params["filter"] != "." || len(w.Steps) != 3 {
+		t.Errorf("builtin build-and-test = %+v, %v", w, err)
+	}
+
+	// Named workflows run like inline ones.
+	out, d := runWorkflow(t, newTestServer(t, dir), map[string]any{"name": "smoke"})
+	if d != nil || out.Workflow != "smoke" || out.Status != statusOK {
+		t.Errorf("run smoke = %+v, %+v", out, d)
+	}
+}
@@ -0,0 +1,316 @@
+// Copyright (c) mcp-unreal project contributors. Apache-2.0 license.
+//
+// EventRoutes.cpp — Editor event stream for the Go server: PIE started and
+// stopped, assets saved, Blueprints compiled, levels loaded, and error log
+// lines. Served by a long-poll route so the Go side does not have to poll
+// pie_control or the output log.
+
+#include "MCPUnrealUtils.h"
+
+#include "Async/Async.h"
+#include "Containers/Ticker.h"
+#include "Engine/Blueprint.h"
+#include "Misc/DateTime.h"
+#include "Misc/Guid.h"
+#include "Misc/PackageName.h"
+#include "Misc/OutputDeviceRedirector.h"
+#include "UObject/ObjectSaveContext.h"
+#include "UObject/Package.h"
+
+namespace MCPUnreal {
+
+  // ---------------------------------------------------------------------------
+  // Event buffer and pending polls. Everything but Serialize runs on the
+  // game thread, where HTTP handlers, editor delegates, and the ticker run.
+  // ---------------------------------------------------------------------------
+
+  class FMCPEventStream : public FOutputDevice {
+   public:
+    static constexpr int32 MaxEvents = 1000;
+    static constexpr int32 MaxWaitMs = 30000;
+
+    struct FEvent {
+      int64 Seq = 0;
+      FString Type;
+      FDateTime Time;
+      TSharedPtr<FJsonObject> Data;
+    };
+
+    struct FWaiter {
+      int64 Since = 0;
+      double Deadline = 0.0;
+      FHttpResultCallback OnComplete;
+    };
+
+    void Start() {
+      if (bStarted) {
+        return;
+      }
+      StreamId = FGuid::NewGuid().ToString(EGuidFormats::DigitsWithHyphens);
+
+      PIEStartedHandle = FEditorDelegates::PostPIEStarted.AddRaw(this, &FMCPEventStream::OnPIEStarted);
+      PIEEndedHandle = FEditorDelegates::EndPIE.AddRaw(this, &FMCPEventStream::OnPIEEnded);
+      MapOpenedHandle = FEditorDelegates::OnMapOpened.AddRaw(this, &FMCPEventStream::OnMapOpened);
+      PackageSavedHandle =
+          UPackage::PackageSavedWithContextEvent.AddRaw(this, &FMCPEventStream::OnPackageSaved);
+      GLog->AddOutputDevice(this);
+
+      // The ticker expires waiting polls and binds the Blueprint delegates
+      // once GEditor exists.
+      TickerHandle = FTSTicker::GetCoreTicker().AddTicker(
+          FTickerDelegate::CreateRaw(this, &FMCPEventStream::OnTick), 0.1f);
+      bStarted = true;
+    }
+
+    void Stop() {
+      if (!bStarted) {
+        return;
+      }
+      FEditorDelegates::PostPIEStarted.Remove(PIEStartedHandle);
+      FEditorDelegates::EndPIE.Remove(PIEEndedHandle);
+      FEditorDelegates::OnMapOpened.Remove(MapOpenedHandle);
+      UPackage::PackageSavedWithContextEvent.Remove(PackageSavedHandle);
+      if (GEditor && bBlueprintHooks) {
+        GEditor->OnBlueprintPreCompile().Remove(BlueprintPreCompileHandle);
+        GEditor->OnBlueprintCompiled().Remove(BlueprintCompiledHandle);
+      }
+      bBlueprintHooks = false;
+      if (GLog) {
+        GLog->RemoveOutputDevice(this);
+      }
+      FTSTicker::GetCoreTicker().RemoveTicker(TickerHandle);
+
+      // Answer pending polls so their connections close cleanly.
+      for (FWaiter& Waiter : Waiters) {
+        SendBatch(Waiter.OnComplete, Waiter.Since);
+      }
+      Waiters.Empty();
+      bStarted = false;
+    }
+
+    /** Handle POST /api/events/poll. Returns at once when events newer than
+     *  "since" exist, otherwise holds the request for up to timeout_ms
+     *  (capped at MaxWaitMs). */
+    bool HandlePoll(const FHttpServerRequest& Request, const FHttpResultCallback& OnComplete) {
+      TSharedPtr<FJsonObject> Body;
+      if (!ParseJsonBody(Request, Body)) {
+        SendError(OnComplete, TEXT("Invalid JSON body"));
+        return true;
+      }
+      double SinceValue = 0.0;
+      double TimeoutValue = 0.0;
+      Body->TryGetNumberField(TEXT("since"), SinceValue);
+      Body->TryGetNumberField(TEXT("timeout_ms"), TimeoutValue);
+      const int64 Since = static_cast<int64>(SinceValue);
+      const int32 TimeoutMs = FMath::Clamp(static_cast<int32>(TimeoutValue), 0, MaxWaitMs);
+
+      // A "since" ahead of this stream comes from a client of a previous
+      // editor session; answer at once so it sees the new stream_id.
+      if (LastSeq() != Since || TimeoutMs == 0) {
+        SendBatch(OnComplete, Since);
+        return true;
+      }
+      FWaiter Waiter;
+      Waiter.Since = Since;
+      Waiter.Deadline = FPlatformTime::Seconds() + TimeoutMs / 1000.0;
+      Waiter.OnComplete = OnComplete;
+      Waiters.Add(MoveTemp(Waiter));
+      return true;
+    }
+
+    // FOutputDevice — may be called from any thread.
+    virtual bool CanBeUsedOnMultipleThreads() const override { return true; }
+
+    virtual void Serialize(const TCHAR* Message, ELogVerbosity::Type Verbosity,
+                           const FName& Category) override {
+      if ((Verbosity & ELogVerbosity::VerbosityMask) > ELogVerbosity::Error) {
+        return;
+      }
+      const FString Msg(Message);
+      const FString Cat = Category.ToString();
+      const FString Level =
+          (Verbosity & ELogVerbosity::VerbosityMask) == ELogVerbosity::Fatal ? TEXT("Fatal") : TEXT("Error");
+      AsyncTask(ENamedThreads::GameThread, [this, Msg, Cat, Level]() {
+        if (!bStarted) {
+          return;
+        }
+        TSharedPtr<FJsonObject> Data = MakeShareable(new FJsonObject());
+        Data->SetStringField(TEXT("category"), Cat);
+        Data->SetStringField(TEXT("verbosity"), Level);
+        Data->SetStringField(TEXT("message"), Msg);
+        Push(TEXT("log_error"), Data);
+      });
+    }
+
+   private:
+    int64 LastSeq() const { return Events.Num() > 0 ? Events.Last().Seq : NextSeq - 1; }
+
+    void Push(const FString& Type, const TSharedPtr<FJsonObject>& Data) {
+      FEvent Event;
+      Event.Seq = NextSeq++;
+      Event.Type = Type;
+      Event.Time = FDateTime::UtcNow();
+      Event.Data = Data;
+      Events.Add(MoveTemp(Event));
+      if (Events.Num() > MaxEvents) {
+        Events.RemoveAt(0, Events.Num() - MaxEvents);
+      }
+
+      // Wake every pending poll; each was waiting for anything newer.
+      TArray<FWaiter> Ready = MoveTemp(Waiters);
+      Waiters.Reset();
+      for (FWaiter& Waiter : Ready) {
+        SendBatch(Waiter.OnComplete, Waiter.Since);
+      }
+    }
+
+    void SendBatch(const FHttpResultCallback& OnComplete, int64 Since) const {
+      TArray<TSharedPtr<FJsonValue>> EventArray;
+      for (const FEvent& Event : Events) {
+        if (Event.Seq <= Since) {
+          continue;
+        }
+        TSharedPtr<FJsonObject> EventJson = MakeShareable(new FJsonObject());
+        EventJson->SetNumberField(TEXT("seq"), static_cast<double>(Event.Seq));
+        EventJson->SetStringField(TEXT("type"), Event.Type);
+        EventJson->SetStringField(TEXT("time"), Event.Time.ToIso8601());
+        if (Event.Data.IsValid()) {
+          EventJson->SetObjectField(TEXT("data"), Event.Data);
+        }
+        EventArray.Add(MakeShareable(new FJsonValueObject(EventJson)));
+      }
+
+      TSharedPtr<FJsonObject> Response = MakeShareable(new FJsonObject());
+      Response->SetStringField(TEXT("stream_id"), StreamId);
+      Response->SetArrayField(TEXT("events"), EventArray);
+      Response->SetNumberField(TEXT("last_seq"), static_cast<double>(LastSeq()));
+      SendJson(OnComplete, Response);
+    }
+
+    bool OnTick(float DeltaTime) {
+      if (!bBlueprintHooks && GEditor) {
+        BlueprintPreCompileHandle =
+            GEditor->OnBlueprintPreCompile().AddRaw(this, &FMCPEventStream::OnBlueprintPreCompile);
+        BlueprintCompiledHandle =
+            GEditor->OnBlueprintCompiled().AddRaw(this, &FMCPEventStream::OnBlueprintCompiled);
+        bBlueprintHooks = true;
+      }
+
+      const double Now = FPlatformTime::Seconds();
+      for (int32 i = Waiters.Num() - 1; i >= 0; --i) {
+        if (Waiters[i].Deadline <= Now) {
+          SendBatch(Waiters[i].OnComplete, Waiters[i].Since);
+          Waiters.RemoveAtSwap(i);
+        }
+      }
+      return true;
+    }
+
+    // Editor delegates.
+
+    void OnPIEStarted(const bool bIsSimulating) {
+      TSharedPtr<FJsonObject> Data = MakeShareable(new FJsonObject());
+      if (GEditor && GEditor->PlayWorld) {
+        Data->SetStringField(TEXT("map"), GEditor->PlayWorld->GetMapName());
+      }
+      Data->SetBoolField(TEXT("simulate"), bIsSimulating);
+      Push(TEXT("pie_started"), Data);
+    }
+
+    void OnPIEEnded(const bool bIsSimulating) {
+      TSharedPtr<FJsonObject> Data = MakeShareable(new FJsonObject());
+      if (UWorld* World = GetEditorWorld()) {
+        Data->SetStringField(TEXT("map"), World->GetMapName());
+      }
+      Data->SetBoolField(TEXT("simulate"), bIsSimulating);
+      Push(TEXT("pie_stopped"), Data);
+    }
+
+    void OnMapOpened(const FString& Filename, bool bAsTemplate) {
+      TSharedPtr<FJsonObject> Data = MakeShareable(new FJsonObject());
+      FString PackageName;
+      if (FPackageName::TryConvertFilenameToLongPackageName(Filename, PackageName)) {
+        Data->SetStringField(TEXT("map"), PackageName);
+      }
+      Data->SetStringField(TEXT("file"), Filename);
+      Data->SetBoolField(TEXT("as_template"), bAsTemplate);
+      Push(TEXT("level_loaded"), Data);
+    }
+
+    void OnPackageSaved(const FString& PackageFilename, UPackage* Package,
+                        FObjectPostSaveContext SaveContext) {
+      // Skip cooking and procedural saves; report what the user saved.
+      if (!Package || SaveContext.IsProceduralSave()) {
+        return;
+      }
+      TSharedPtr<FJsonObject> Data = MakeShareable(new FJsonObject());
+      Data->SetStringField(TEXT("package"), Package->GetName());
+      Data->SetStringField(TEXT("file"), PackageFilename);
+      Push(TEXT("asset_saved"), Data);
+    }
+
+    void OnBlueprintPreCompile(UBlueprint* Blueprint) {
+      if (Blueprint) {
+        Compiling.Add(TWeakObjectPtr<UBlueprint>(Blueprint));
+      }
+    }
+
+    void OnBlueprintCompiled() {
+      for (const TWeakObjectPtr<UBlueprint>& Weak : Compiling) {
+        UBlueprint* Blueprint = Weak.Get();
+        if (!Blueprint) {
+          continue;
+        }
+        TSharedPtr<FJsonObject> Data = MakeShareable(new FJsonObject());
+        Data->SetStringField(TEXT("blueprint"), Blueprint->GetPathName());
+        Data->SetStringField(TEXT("name"), Blueprint->GetName());
+        Data->SetBoolField(TEXT("success"), Blueprint->Status != BS_Error);
+        Push(TEXT("blueprint_compiled"), Data);
+      }
+      Compiling.Reset();
+    }
+
+    FString StreamId;
+    TArray<FEvent> Events;
+    int64 NextSeq = 1;
+    TArray<FWaiter> Waiters;
+    TArray<TWeakObjectPtr<UBlueprint>> Compiling;
+
+    FDelegateHandle PIEStartedHandle;
+    FDelegateHandle PIEEndedHandle;
+    FDelegateHandle MapOpenedHandle;
+    FDelegateHandle PackageSavedHandle;
+    FDelegateHandle BlueprintPreCompileHandle;
+    FDelegateHandle BlueprintCompiledHandle;
+    FTSTicker::FDelegateHandle TickerHandle;
+    bool bBlueprintHooks = false;
+    bool bStarted = false;
+  };
+
+  static FMCPEventStream& GetEventStream() {
+    static FMCPEventStream Instance;
+    return Instance;
+  }
+
+  static bool HandleEventsPoll(const FHttpServerRequest& Request,
+                               const FHttpResultCallback& OnComplete) {
+    return GetEventStream().HandlePoll(Request, OnComplete);
+  }
+
+  // ---------------------------------------------------------------------------
+  // Registration
+  // ---------------------------------------------------------------------------
+
+  void RegisterEventRoutes(TSharedPtr<IHttpRouter> Router, TArray<FHttpRouteHandle>& Handles) {
+    GetEventStream().Start();
+
+    Handles.Add(Router->BindRoute(FHttpPath(TEXT("/api/events/poll")),
+                                  EHttpServerRequestVerbs::VERB_POST,
+                                  FHttpRequestHandler::CreateStatic(&HandleEventsPoll)));
+
+    UE_LOG(LogMCPUnreal, Verbose, TEXT("Registered event stream route (1 endpoint)"));
+  }
+
+  void StopEventStream() { GetEventStream().Stop(); }
+
+}  // namespace MCPUnreal
@@ -16,6 +16,8 @@
 #include "Serialization/JsonSerializer.h"
 #include "Serialization/JsonWriter.h"
 #include "Misc/App.h"
+#include "HAL/PlatformMisc.h"
+#include "IPAddress.h"
 
 DEFINE_LOG_CATEGORY(LogMCPUnreal);
 
@@ -24,6 +26,14 @@ static TAutoConsoleVariable<int32> CVarMCPPort(
     TEXT("mcp.Port"), 8090, TEXT("HTTP server port for the MCPUnreal editor plugin. Default 8090."),
     ECVF_Default);
 
+// Console variable for the shared auth token expected from mcp-unreal.
+static TAutoConsoleVariable<FString> CVarMCPAuthToken(
+    TEXT("mcp.AuthToken"), TEXT(""),
+    TEXT("Bearer token required on every MCPUnreal HTTP request. Empty falls back to the ")
+        TEXT("MCP_UNREAL_EDITOR_TOKEN environment variable; if both are empty only loopback ")
+        TEXT("clients are served."),
+    ECVF_Default);
+
 // ---------------------------------------------------------------------------
 // Module lifecycle
 // ---------------------------------------------------------------------------
@@ -58,8 +68,16 @@ void FMCPUnrealModule::StartHttpServer() {
     return;
   }
 
+  // Authorize every request before it reaches a route.
+  AuthPreprocessorHandle = Router->RegisterRequestPreprocessor(
+      FHttpRequestHandler::CreateRaw(this, &FMCPUnrealModule::AuthorizeRequest));
+  if (GetAuthToken().IsEmpty()) {
+    UE_LOG(LogMCPUnreal, Log, TEXT("No mcp.AuthToken set; serving loopback clients only"));
+  }
+
   // Register routes. Each handler validates its input JSON.
-  // POST /api/status — server health and capabilities.
+  // POST /api/status — server health, capabilities, and the route list
+  // used by the Go server's handshake.
   RouteHandles.Add(
       Router->BindRoute(FHttpPath(TEXT("/api/status")),
                         EHttpServerRequestVerbs::VERB_POST | EHttpServerRequestVerbs::VERB_GET,
@@ -87,6 +105,9 @@ void FMCPUnrealModule::StartHttpServer() {
   MCPUnreal::RegisterDataAssetRoutes(Router, RouteHandles);
   MCPUnreal::RegisterUIQueryRoutes(Router, RouteHandles);
   MCPUnreal::RegisterNetworkDebugRoutes(Router, RouteHandles);
+  MCPUnreal::RegisterEventRoutes(Router, RouteHandles);
+  MCPUnreal::RegisterTransactionRoutes(Router, RouteHandles);
+  MCPUnreal::RegisterValidateRoutes(Router, RouteHandles);
 
   HttpModule.StartAllListeners();
   bServerStarted = true;
@@ -109,15 +130,96 @@ void FMCPUnrealModule::StopHttpServer() {
       for (const FHttpRouteHandle& Handle : RouteHandles) {
         Router->UnbindRoute(Handle);
       }
+      Router->UnregisterRequestPreprocessor(AuthPreprocessorHandle);
     }
   }
 
+  MCPUnreal::StopEventStream();
+  MCPUnreal::EndOpenTransactions();
   RouteHandles.Empty();
   bServerStarted = false;
 
   UE_LOG(LogMCPUnreal, Log, TEXT("MCPUnreal HTTP server stopped"));
 }
 
+// ---------------------------------------------------------------------------
+// Authorization
+// ---------------------------------------------------------------------------
+
+FString FMCPUnrealModule::GetAuthToken() {
+  FString Token = CVarMCPAuthToken.GetValueOnAnyThread();
+  if (Token.IsEmpty()) {
+    Token = FPlatformMisc::GetEnvironmentVariable(TEXT("MCP_UNREAL_EDITOR_TOKEN"));
+  }
+  return Token.TrimStartAndEnd();
+}
+
+namespace {
+
+  /** Compare two strings in time independent of where they differ. */
+  bool ConstantTimeEquals(const FString& A, const FString& B) {
+    const int32 Len = FMath::Max(A.Len(), B.Len());
+    uint32 Diff = static_cast<uint32>(A.Len() ^ B.Len());
+    for (int32 i = 0; i < Len; ++i) {
+      const TCHAR CA = i < A.Len() ? A[i] : 0;
+      const TCHAR CB = i < B.Len() ? B[i] : 0;
+      Diff |= static_cast<uint32>(CA ^ CB);
+    }
+    return Diff == 0;
+  }
+
+  bool IsLoopbackPeer(const FHttpServerRequest& Request) {
+    if (!Request.PeerAddress.IsValid()) {
+      return false;
+    }
+    const FString Addr = Request.PeerAddress->ToString(false);
+    return Addr.StartsWith(TEXT("127.")) || Addr == TEXT("::1") ||
+           Addr.StartsWith(TEXT("::ffff:127."));
+  }
+
+  void SendDenied(const FHttpResultCallback& OnComplete, EHttpServerResponseCodes Code,
+                  const FString& Message) {
+    TSharedPtr<FJsonObject> ErrorJson = MakeShareable(new FJsonObject());
+    ErrorJson->SetStringField(TEXT("error"), Message);
+    auto Response =
+        FHttpServerResponse::Create(MCPUnreal::JsonToString(ErrorJson), TEXT("application/json"));
+    Response->Code = Code;
+    OnComplete(MoveTemp(Response));
+  }
+
+}  // namespace
+
+bool FMCPUnrealModule::AuthorizeRequest(const FHttpServerRequest& Request,
+                                        const FHttpResultCallback& OnComplete) {
+  const FString Token = GetAuthToken();
+  if (Token.IsEmpty()) {
+    if (IsLoopbackPeer(Request)) {
+      return false;
+    }
+    SendDenied(OnComplete, EHttpServerResponseCodes::Forbidden,
+               TEXT("Remote clients require an auth token: set mcp.AuthToken in the editor and ")
+                   TEXT("MCP_UNREAL_EDITOR_TOKEN for mcp-unreal"));
+    return true;
+  }
+
+  FString Presented;
+  for (const auto& Header : Request.Headers) {
+    if (Header.Key.Equals(TEXT("Authorization"), ESearchCase::IgnoreCase) &&
+        Header.Value.Num() > 0) {
+      Presented = Header.Value[0];
+      break;
+    }
+  }
+  if (!ConstantTimeEquals(Presented, TEXT("Bearer ") + Token)) {
+    UE_LOG(LogMCPUnreal, Warning, TEXT("Rejected request to %s: missing or invalid auth token"),
+           *Request.RelativePath.GetPath());
+    SendDenied(OnComplete, EHttpServerResponseCodes::Denied,
+               TEXT("Missing or invalid auth token"));
+    return true;
+  }
+  return false;
+}
+
 // ---------------------------------------------------------------------------
 // POST /api/status
 // ---------------------------------------------------------------------------
@@ -127,6 +229,7 @@ bool FMCPUnrealModule::HandleStatus(const FHttpServerRequest& Request,
   TSharedPtr<FJsonObject> ResponseJson = MakeShareable(new FJsonObject());
   ResponseJson->SetStringField(TEXT("name"), TEXT("MCPUnreal"));
   ResponseJson->SetStringField(TEXT("version"), PluginVersion);
+  ResponseJson->SetNumberField(TEXT("protocol_version"), ProtocolVersion);
   ResponseJson->SetStringField(TEXT("ue_version"), FApp::GetBuildVersion());
   ResponseJson->SetNumberField(TEXT("port"), ServerPort);
 
@@ -166,6 +269,21 @@ bool FMCPUnrealModule::HandleStatus(const FHttpServerRequest& Request,
   Capabilities.Add(MakeShareable(new FJsonValueString(TEXT("network_debug"))));
   ResponseJson->SetArrayField(TEXT("capabilities"), Capabilities);
 
+  // Routes — every bound path, so the Go server can tell which tools this
+  // plugin build supports and fail fast on the rest.
+  TArray<FString> RoutePaths;
+  for (const FHttpRouteHandle& Handle : RouteHandles) {
+    if (Handle.IsValid()) {
+      RoutePaths.AddUnique(Handle->Path);
+    }
+  }
+  RoutePaths.Sort();
+  TArray<TSharedPtr<FJsonValue>> Routes;
+  for (const FString& Path : RoutePaths) {
+    Routes.Add(MakeShareable(new FJsonValueString(Path)));
+  }
+  ResponseJson->SetArrayField(TEXT("routes"), Routes);
+
   FString ResponseStr;
   TSharedRef<TJsonWriter<>> Writer = TJsonWriterFactory<>::Create(&ResponseStr);
   FJsonSerializer::Serialize(ResponseJson.ToSharedRef(), Writer);
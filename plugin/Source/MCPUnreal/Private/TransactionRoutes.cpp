@@ -0,0 +1,334 @@
+// Copyright (c) mcp-unreal project contributors. Apache-2.0 license.
+//
+// TransactionRoutes.cpp — Editor undo transactions for the Go server:
+// open and close a named transaction across several requests, undo and
+// redo, and list the undo history.
+//
+// A transaction opened here stays active between requests, so every
+// route that calls Modify() on the objects it changes (spawning and
+// deleting actors, Blueprint edits, property writes through the Remote
+// Control API with WRITE_TRANSACTION_ACCESS) is recorded in it. Nested
+// begins fold into the outermost transaction, as FScopedTransaction does.
+
+#include "MCPUnrealUtils.h"
+
+#include "Editor/TransBuffer.h"
+#include "Editor/Transactor.h"
+
+namespace MCPUnreal {
+
+  // ---------------------------------------------------------------------------
+  // Transactions opened through the API. Game thread only.
+  // ---------------------------------------------------------------------------
+
+  static constexpr int32 MaxUndoSteps = 100;
+
+  struct FOpenTransaction {
+    FString Label;
+    int32 Depth = 0;
+    int32 QueueIndex = INDEX_NONE;
+  };
+
+  static FOpenTransaction& GetOpenTransaction() {
+    static FOpenTransaction Open;
+    return Open;
+  }
+
+  static TSharedPtr<FJsonObject> OpenTransactionJson() {
+    const FOpenTransaction& Open = GetOpenTransaction();
+    TSharedPtr<FJsonObject> Json = MakeShareable(new FJsonObject());
+    Json->SetStringField(TEXT("label"), Open.Label);
+    Json->SetNumberField(TEXT("depth"), Open.Depth);
+    return Json;
+  }
+
+  /** Title of the transaction at QueueIndex, or empty if out of range. */
+  static FString TransactionTitle(int32 QueueIndex) {
+    if (!GEditor || !GEditor->Trans || QueueIndex < 0 ||
+        QueueIndex >= GEditor->Trans->GetQueueLength()) {
+      return FString();
+    }
+    const FTransaction* Transaction = GEditor->Trans->GetTransaction(QueueIndex);
+    return Transaction ? Transaction->GetContext().Title.ToString() : FString();
+  }
+
+  /** Index of the newest transaction that can be undone, or INDEX_NONE. */
+  static int32 NewestUndoable() {
+    if (!GEditor || !GEditor->Trans) {
+      return INDEX_NONE;
+    }
+    return GEditor->Trans->GetQueueLength() - GEditor->Trans->GetUndoCount() - 1;
+  }
+
+  static bool CheckEditor(const FHttpResultCallback& OnComplete) {
+    if (!GEditor || !GEditor->Trans) {
+      SendError(OnComplete, TEXT("Editor transaction buffer not available"), 500);
+      return false;
+    }
+    return true;
+  }
+
+  // ---------------------------------------------------------------------------
+  // POST /api/transactions/begin
+  // ---------------------------------------------------------------------------
+
+  static bool HandleTransactionBegin(const FHttpServerRequest& Request,
+                                     const FHttpResultCallback& OnComplete) {
+    TSharedPtr<FJsonObject> Body;
+    if (!ParseJsonBody(Request, Body)) {
+      SendError(OnComplete, TEXT("Invalid JSON in request body"));
+      return true;
+    }
+    if (!CheckEditor(OnComplete)) {
+      return true;
+    }
+
+    const FString Label = Body->GetStringField(TEXT("label"));
+    if (Label.IsEmpty()) {
+      SendError(OnComplete, TEXT("label is required"));
+      return true;
+    }
+
+    FOpenTransaction& Open = GetOpenTransaction();
+    const int32 Index =
+        GEditor->BeginTransaction(TEXT("MCPUnreal"), FText::FromString(Label), nullptr);
+    if (Open.Depth == 0) {
+      Open.Label = Label;
+      Open.QueueIndex = Index;
+    }
+    Open.Depth++;
+
+    SendJson(OnComplete, OpenTransactionJson());
+    return true;
+  }
+
+  // ---------------------------------------------------------------------------
+  // POST /api/transactions/end
+  // ---------------------------------------------------------------------------
+
+  static bool HandleTransactionEnd(const FHttpServerRequest& Request,
+                                   const FHttpResultCallback& OnComplete) {
+    TSharedPtr<FJsonObject> Body;
+    if (!ParseJsonBody(Request, Body)) {
+      SendError(OnComplete, TEXT("Invalid JSON in request body"));
+      return true;
+    }
+    if (!CheckEditor(OnComplete)) {
+      return true;
+    }
+
+    FOpenTransaction& Open = GetOpenTransaction();
+    if (Open.Depth == 0) {
+      SendError(OnComplete, TEXT("No transaction is open"));
+      return true;
+    }
+
+    GEditor->EndTransaction();
+    Open.Depth--;
+
+    TSharedPtr<FJsonObject> ResponseJson = OpenTransactionJson();
+    if (Open.Depth == 0) {
+      // Empty transactions are dropped from the buffer when they end.
+      const bool bRecorded = TransactionTitle(Open.QueueIndex) == Open.Label &&
+                             Open.QueueIndex == NewestUndoable();
+      bool bReverted = false;
+      if (bRecorded && Body->GetBoolField(TEXT("revert"))) {
+        bReverted = GEditor->UndoTransaction();
+      }
+      ResponseJson->SetBoolField(TEXT("recorded"), bRecorded);
+      ResponseJson->SetBoolField(TEXT("reverted"), bReverted);
+      Open = FOpenTransaction();
+    }
+
+    SendJson(OnComplete, ResponseJson);
+    return true;
+  }
+
+  // ---------------------------------------------------------------------------
+  // POST /api/transactions/undo
+  // ---------------------------------------------------------------------------
+
+  static bool HandleTransactionUndo(const FHttpServerRequest& Request,
+                                    const FHttpResultCallback& OnComplete) {
+    TSharedPtr<FJsonObject> Body;
+    if (!ParseJsonBody(Request, Body)) {
+      SendError(OnComplete, TEXT("Invalid JSON in request body"));
+      return true;
+    }
+    if (!CheckEditor(OnComplete)) {
+      return true;
+    }
+    if (GEditor->IsTransactionActive()) {
+      SendError(OnComplete,
+                FString::Printf(TEXT("Transaction '%s' is still open — end it before undoing"),
+                                *GetOpenTransaction().Label));
+      return true;
+    }
+
+    const FString Label = Body->GetStringField(TEXT("label"));
+    int32 Count = 1;
+    Body->TryGetNumberField(TEXT("count"), Count);
+    Count = FMath::Clamp(Count, 1, MaxUndoSteps);
+
+    // With a label, undo every consecutive newest transaction carrying it.
+    if (!Label.IsEmpty()) {
+      const FString Newest = TransactionTitle(NewestUndoable());
+      if (Newest != Label) {
+        SendError(OnComplete,
+                  FString::Printf(TEXT("The newest transaction is '%s', not '%s'"), *Newest, *Label));
+        return true;
+      }
+      Count = MaxUndoSteps;
+    }
+
+    TArray<TSharedPtr<FJsonValue>> Undone;
+    for (int32 i = 0; i < Count && NewestUndoable() >= 0; ++i) {
+      const FString Title = TransactionTitle(NewestUndoable());
+      if (!Label.IsEmpty() && Title != Label) {
+        break;
+      }
+      if (!GEditor->UndoTransaction()) {
+        break;
+      }
+      Undone.Add(MakeShareable(new FJsonValueString(Title)));
+    }
+    if (Undone.Num() == 0) {
+      SendError(OnComplete, TEXT("Nothing to undo"));
+      return true;
+    }
+
+    TSharedPtr<FJsonObject> ResponseJson = MakeShareable(new FJsonObject());
+    ResponseJson->SetArrayField(TEXT("transactions"), Undone);
+    ResponseJson->SetBoolField(TEXT("can_undo"), GEditor->Trans->CanUndo());
+    ResponseJson->SetBoolField(TEXT("can_redo"), GEditor->Trans->CanRedo());
+    SendJson(OnComplete, ResponseJson);
+    return true;
+  }
+
+  // ---------------------------------------------------------------------------
+  // POST /api/transactions/redo
+  // ---------------------------------------------------------------------------
+
+  static bool HandleTransactionRedo(const FHttpServerRequest& Request,
+                                    const FHttpResultCallback& OnComplete) {
+    TSharedPtr<FJsonObject> Body;
+    if (!ParseJsonBody(Request, Body)) {
+      SendError(OnComplete, TEXT("Invalid JSON in request body"));
+      return true;
+    }
+    if (!CheckEditor(OnComplete)) {
+      return true;
+    }
+    if (GEditor->IsTransactionActive()) {
+      SendError(OnComplete,
+                FString::Printf(TEXT("Transaction '%s' is still open — end it before redoing"),
+                                *GetOpenTransaction().Label));
+      return true;
+    }
+
+    int32 Count = 1;
+    Body->TryGetNumberField(TEXT("count"), Count);
+    Count = FMath::Clamp(Count, 1, MaxUndoSteps);
+
+    TArray<TSharedPtr<FJsonValue>> Redone;
+    for (int32 i = 0; i < Count && GEditor->Trans->GetUndoCount() > 0; ++i) {
+      const FString Title = TransactionTitle(NewestUndoable() + 1);
+      if (!GEditor->RedoTransaction()) {
+        break;
+      }
+      Redone.Add(MakeShareable(new FJsonValueString(Title)));
+    }
+    if (Redone.Num() == 0) {
+      SendError(OnComplete, TEXT("Nothing to redo"));
+      return true;
+    }
+
+    TSharedPtr<FJsonObject> ResponseJson = MakeShareable(new FJsonObject());
+    ResponseJson->SetArrayField(TEXT("transactions"), Redone);
+    ResponseJson->SetBoolField(TEXT("can_undo"), GEditor->Trans->CanUndo());
+    ResponseJson->SetBoolField(TEXT("can_redo"), GEditor->Trans->CanRedo());
+    SendJson(OnComplete, ResponseJson);
+    return true;
+  }
+
+  // ---------------------------------------------------------------------------
+  // POST /api/transactions/history
+  // ---------------------------------------------------------------------------
+
+  static bool HandleTransactionHistory(const FHttpServerRequest& Request,
+                                       const FHttpResultCallback& OnComplete) {
+    TSharedPtr<FJsonObject> Body;
+    if (!ParseJsonBody(Request, Body)) {
+      SendError(OnComplete, TEXT("Invalid JSON in request body"));
+      return true;
+    }
+    if (!CheckEditor(OnComplete)) {
+      return true;
+    }
+
+    int32 Limit = 20;
+    Body->TryGetNumberField(TEXT("limit"), Limit);
+    Limit = FMath::Clamp(Limit, 1, 500);
+
+    // Newest first; entries past the undo position have been undone.
+    const int32 QueueLength = GEditor->Trans->GetQueueLength();
+    const int32 FirstUndone = QueueLength - GEditor->Trans->GetUndoCount();
+    TArray<TSharedPtr<FJsonValue>> Entries;
+    for (int32 i = QueueLength - 1; i >= 0 && Entries.Num() < Limit; --i) {
+      TSharedPtr<FJsonObject> Entry = MakeShareable(new FJsonObject());
+      Entry->SetNumberField(TEXT("index"), i);
+      Entry->SetStringField(TEXT("label"), TransactionTitle(i));
+      Entry->SetBoolField(TEXT("undone"), i >= FirstUndone);
+      Entries.Add(MakeShareable(new FJsonValueObject(Entry)));
+    }
+
+    TSharedPtr<FJsonObject> ResponseJson = MakeShareable(new FJsonObject());
+    ResponseJson->SetArrayField(TEXT("entries"), Entries);
+    ResponseJson->SetNumberField(TEXT("total"), QueueLength);
+    ResponseJson->SetBoolField(TEXT("can_undo"), GEditor->Trans->CanUndo());
+    ResponseJson->SetBoolField(TEXT("can_redo"), GEditor->Trans->CanRedo());
+    if (GetOpenTransaction().Depth > 0) {
+      ResponseJson->SetObjectField(TEXT("open"), OpenTransactionJson());
+    }
+    SendJson(OnComplete, ResponseJson);
+    return true;
+  }
+
+  // ---------------------------------------------------------------------------
+  // Registration
+  // ---------------------------------------------------------------------------
+
+  void RegisterTransactionRoutes(TSharedPtr<IHttpRouter> Router,
+                                 TArray<FHttpRouteHandle>& Handles) {
+    Handles.Add(Router->BindRoute(FHttpPath(TEXT("/api/transactions/begin")),
+                                  EHttpServerRequestVerbs::VERB_POST,
+                                  FHttpRequestHandler::CreateStatic(&HandleTransactionBegin)));
+    Handles.Add(Router->BindRoute(FHttpPath(TEXT("/api/transactions/end")),
+                                  EHttpServerRequestVerbs::VERB_POST,
+                                  FHttpRequestHandler::CreateStatic(&HandleTransactionEnd)));
+    Handles.Add(Router->BindRoute(FHttpPath(TEXT("/api/transactions/undo")),
+                                  EHttpServerRequestVerbs::VERB_POST,
+                                  FHttpRequestHandler::CreateStatic(&HandleTransactionUndo)));
+    Handles.Add(Router->BindRoute(FHttpPath(TEXT("/api/transactions/redo")),
+                                  EHttpServerRequestVerbs::VERB_POST,
+                                  FHttpRequestHandler::CreateStatic(&HandleTransactionRedo)));
+    Handles.Add(Router->BindRoute(FHttpPath(TEXT("/api/transactions/history")),
+                                  EHttpServerRequestVerbs::VERB_POST,
+                                  FHttpRequestHandler::CreateStatic(&HandleTransactionHistory)));
+
+    UE_LOG(LogMCPUnreal, Verbose, TEXT("Registered transaction routes (5 endpoints)"));
+  }
+
+  void EndOpenTransactions() {
+    FOpenTransaction& Open = GetOpenTransaction();
+    if (Open.Depth > 0 && GEditor) {
+      UE_LOG(LogMCPUnreal, Warning, TEXT("Closing transaction '%s' left open by the Go server"),
+             *Open.Label);
+      while (Open.Depth-- > 0) {
+        GEditor->EndTransaction();
+      }
+    }
+    Open = FOpenTransaction();
+  }
+
+}  // namespace MCPUnreal
@@ -0,0 +1,291 @@
+// Copyright (c) mcp-unreal project contributors. Apache-2.0 license.
+//
+// ValidateRoutes.cpp — Dry-run validation for the Go server's dry_run
+// flag: check a mutating request meant for another route and report the
+// objects it would create, modify, or delete, without changing anything.
+//
+// Routes with a dedicated validator (actor spawn and delete, DataTable
+// operations, Remote Control property writes and function calls) are
+// checked the way their handler would check them. Any other route is
+// checked only for the content its request names: existing targets are
+// reported as modified, and the response carries a note saying so.
+
+#include "MCPUnrealUtils.h"
+
+#include "EngineUtils.h"
+#include "Engine/DataTable.h"
+#include "UObject/UObjectGlobals.h"
+
+namespace MCPUnreal {
+
+  // ---------------------------------------------------------------------------
+  // Validation result
+  // ---------------------------------------------------------------------------
+
+  struct FValidation {
+    bool bValid = true;
+    FString Reason;
+    FString Note;
+    TArray<TSharedPtr<FJsonValue>> Affected;
+
+    void Fail(const FString& InReason) {
+      bValid = false;
+      Reason = InReason;
+    }
+
+    void Add(const FString& Path, const FString& Class, const TCHAR* Change) {
+      TSharedPtr<FJsonObject> Json = MakeShareable(new FJsonObject());
+      Json->SetStringField(TEXT("path"), Path);
+      if (!Class.IsEmpty()) {
+        Json->SetStringField(TEXT("class"), Class);
+      }
+      Json->SetStringField(TEXT("change"), Change);
+      Affected.Add(MakeShareable(new FJsonValueObject(Json)));
+    }
+
+    void Add(const UObject* Object, const TCHAR* Change) {
+      Add(Object->GetPathName(), Object->GetClass()->GetName(), Change);
+    }
+  };
+
+  /** Find an object by path, loading its package if it is not in memory. */
+  static UObject* ResolveObject(const FString& Path) {
+    if (Path.IsEmpty()) {
+      return nullptr;
+    }
+    if (UObject* Found = FindObject<UObject>(nullptr, *Path)) {
+      return Found;
+    }
+    return LoadObject<UObject>(nullptr, *Path, nullptr, LOAD_NoWarn | LOAD_Quiet);
+  }
+
+  // ---------------------------------------------------------------------------
+  // Route validators
+  // ---------------------------------------------------------------------------
+
+  static void ValidateActorsSpawn(const TSharedPtr<FJsonObject>& Body, FValidation& Out) {
+    UWorld* World = GetWorld(Body);
+    if (!World) {
+      Out.Fail(TEXT("World not available — if world=pie was requested, ensure PIE is running"));
+      return;
+    }
+    const FString ClassName = Body->GetStringField(TEXT("class_name"));
+    if (ClassName.IsEmpty()) {
+      Out.Fail(TEXT("class_name is required"));
+      return;
+    }
+    // Resolve the class as HandleActorsSpawn does.
+    UClass* Class = FindFirstObject<UClass>(*ClassName, EFindFirstObjectOptions::None);
+    if (!Class) {
+      Class = FindFirstObject<UClass>(*(TEXT("A") + ClassName), EFindFirstObjectOptions::None);
+    }
+    if (!Class) {
+      Class = LoadClass<AActor>(nullptr, *ClassName);
+    }
+    if (!Class || !Class->IsChildOf(AActor::StaticClass())) {
+      Out.Fail(
+          FString::Printf(TEXT("Actor class '%s' not found or is not an Actor class"), *ClassName));
+      return;
+    }
+    FString Name = Body->GetStringField(TEXT("name"));
+    if (Name.IsEmpty()) {
+      Name = Class->GetName();
+    }
+    Out.Add(World->PersistentLevel->GetPathName() + TEXT(".") + Name, Class->GetName(),
+            TEXT("created"));
+  }
+
+  static void ValidateActorsDelete(const TSharedPtr<FJsonObject>& Body, FValidation& Out) {
+    UWorld* World = GetWorld(Body);
+    if (!World) {
+      Out.Fail(TEXT("World not available — if world=pie was requested, ensure PIE is running"));
+      return;
+    }
+    TSet<FString> Paths;
+    TSet<FString> Names;
+    const TArray<TSharedPtr<FJsonValue>>* Array;
+    if (Body->TryGetArrayField(TEXT("actor_paths"), Array)) {
+      for (const auto& Val : *Array) {
+        Paths.Add(Val->AsString());
+      }
+    }
+    if (Body->TryGetArrayField(TEXT("actor_names"), Array)) {
+      for (const auto& Val : *Array) {
+        Names.Add(Val->AsString());
+      }
+    }
+    if (Paths.Num() == 0 && Names.Num() == 0) {
+      Out.Fail(TEXT("At least one of actor_paths or actor_names is required"));
+      return;
+    }
+    for (TActorIterator<AActor> It(World); It; ++It) {
+      AActor* Actor = *It;
+      if (Actor && !Actor->IsPendingKillPending() &&
+          (Paths.Contains(Actor->GetPathName()) || Names.Contains(Actor->GetActorNameOrLabel()))) {
+        Out.Add(Actor, TEXT("deleted"));
+      }
+    }
+  }
+
+  static void ValidateDataOps(const TSharedPtr<FJsonObject>& Body, FValidation& Out) {
+    const FString Operation = Body->GetStringField(TEXT("operation"));
+    const FString AssetPath = Body->GetStringField(TEXT("asset"));
+    const FString RowName = Body->GetStringField(TEXT("row_name"));
+
+    if (Operation == TEXT("create_table")) {
+      const FString Destination = Body->GetStringField(TEXT("destination"));
+      if (Destination.IsEmpty()) {
+        Out.Fail(TEXT("destination is required for create_table"));
+      } else if (ResolveObject(Destination)) {
+        Out.Fail(FString::Printf(TEXT("Asset already exists: %s"), *Destination));
+      } else {
+        Out.Add(Destination, TEXT("DataTable"), TEXT("created"));
+      }
+      return;
+    }
+
+    UDataTable* DT = AssetPath.IsEmpty() ? nullptr : LoadObject<UDataTable>(nullptr, *AssetPath);
+    if (!DT) {
+      Out.Fail(FString::Printf(TEXT("DataTable not found: %s"), *AssetPath));
+      return;
+    }
+    const bool bRowExists = !RowName.IsEmpty() && DT->GetRowMap().Contains(FName(*RowName));
+    if (Operation == TEXT("add_row") || Operation == TEXT("update_row") ||
+        Operation == TEXT("delete_row")) {
+      if (RowName.IsEmpty()) {
+        Out.Fail(FString::Printf(TEXT("row_name is required for %s"), *Operation));
+        return;
+      }
+      if (Operation == TEXT("add_row") && bRowExists) {
+        Out.Fail(FString::Printf(TEXT("Row '%s' already exists in %s"), *RowName, *AssetPath));
+        return;
+      }
+      if (Operation != TEXT("add_row") && !bRowExists) {
+        Out.Fail(FString::Printf(TEXT("Row '%s' not found in %s"), *RowName, *AssetPath));
+        return;
+      }
+      const TCHAR* Change = Operation == TEXT("add_row")      ? TEXT("created")
+                            : Operation == TEXT("update_row") ? TEXT("modified")
+                                                              : TEXT("deleted");
+      Out.Add(DT->GetPathName() + TEXT(":") + RowName, TEXT("DataTableRow"), Change);
+      return;
+    }
+    Out.Add(DT, TEXT("modified"));
+  }
+
+  /** /remote/object/call and /remote/object/property writes. */
+  static void ValidateRemoteObject(const FString& Route, const TSharedPtr<FJsonObject>& Body,
+                                   FValidation& Out) {
+    const FString ObjectPath = Body->GetStringField(TEXT("objectPath"));
+    UObject* Object = ResolveObject(ObjectPath);
+    if (!Object) {
+      Out.Fail(FString::Printf(TEXT("Object: %s does not exist."), *ObjectPath));
+      return;
+    }
+    if (Route == TEXT("/remote/object/call")) {
+      const FString FunctionName = Body->GetStringField(TEXT("functionName"));
+      if (!Object->FindFunction(FName(*FunctionName))) {
+        Out.Fail(FString::Printf(TEXT("Function %s does not exist on %s"), *FunctionName,
+                                 *Object->GetName()));
+        return;
+      }
+    } else {
+      const FString PropertyName = Body->GetStringField(TEXT("propertyName"));
+      if (!PropertyName.IsEmpty() && !Object->GetClass()->FindPropertyByName(FName(*PropertyName))) {
+        Out.Fail(FString::Printf(TEXT("Property %s does not exist on %s"), *PropertyName,
+                                 *Object->GetName()));
+        return;
+      }
+    }
+    Out.Add(Object, TEXT("modified"));
+  }
+
+  /** Request fields that name the content a request changes. */
+  static const TCHAR* const TargetFields[] = {
+      TEXT("asset"),          TEXT("asset_path"),          TEXT("blueprint_path"),
+      TEXT("anim_blueprint_path"), TEXT("level_path"),     TEXT("material_path"),
+      TEXT("texture_path"),   TEXT("system_path"),         TEXT("graph_path"),
+      TEXT("object_path"),    TEXT("actor_path"),          TEXT("destination"),
+  };
+
+  static void ValidateGeneric(const TSharedPtr<FJsonObject>& Body, FValidation& Out) {
+    for (const TCHAR* Field : TargetFields) {
+      FString Path;
+      if (!Body->TryGetStringField(Field, Path) || !Path.StartsWith(TEXT("/"))) {
+        continue;
+      }
+      if (UObject* Object = ResolveObject(Path)) {
+        Out.Add(Object, TEXT("modified"));
+      } else if (FCString::Strcmp(Field, TEXT("destination")) == 0) {
+        Out.Add(Path, FString(), TEXT("created"));
+      } else {
+        Out.Fail(FString::Printf(TEXT("Object not found: %s"), *Path));
+        return;
+      }
+    }
+    Out.Note = TEXT("No dedicated validator for this route: only the objects it names were checked");
+  }
+
+  // ---------------------------------------------------------------------------
+  // POST /api/validate
+  // ---------------------------------------------------------------------------
+
+  static bool HandleValidate(const FHttpServerRequest& Request,
+                             const FHttpResultCallback& OnComplete) {
+    TSharedPtr<FJsonObject> Body;
+    if (!ParseJsonBody(Request, Body)) {
+      SendError(OnComplete, TEXT("Invalid JSON in request body"));
+      return true;
+    }
+
+    const FString Route = Body->GetStringField(TEXT("route"));
+    if (Route.IsEmpty()) {
+      SendError(OnComplete, TEXT("Missing 'route' field"));
+      return true;
+    }
+    const TSharedPtr<FJsonObject>* InnerPtr = nullptr;
+    TSharedPtr<FJsonObject> Inner = Body->TryGetObjectField(TEXT("body"), InnerPtr)
+                                        ? *InnerPtr
+                                        : MakeShareable(new FJsonObject());
+
+    FValidation Out;
+    if (Route == TEXT("/api/actors/spawn")) {
+      ValidateActorsSpawn(Inner, Out);
+    } else if (Route == TEXT("/api/actors/delete")) {
+      ValidateActorsDelete(Inner, Out);
+    } else if (Route == TEXT("/api/data/ops")) {
+      ValidateDataOps(Inner, Out);
+    } else if (Route == TEXT("/remote/object/call") || Route == TEXT("/remote/object/property")) {
+      ValidateRemoteObject(Route, Inner, Out);
+    } else {
+      ValidateGeneric(Inner, Out);
+    }
+
+    TSharedPtr<FJsonObject> ResponseJson = MakeShareable(new FJsonObject());
+    ResponseJson->SetStringField(TEXT("route"), Route);
+    ResponseJson->SetBoolField(TEXT("valid"), Out.bValid);
+    if (!Out.bValid) {
+      ResponseJson->SetStringField(TEXT("reason"), Out.Reason);
+    }
+    if (!Out.Note.IsEmpty()) {
+      ResponseJson->SetStringField(TEXT("note"), Out.Note);
+    }
+    ResponseJson->SetArrayField(TEXT("affected"), Out.Affected);
+
+    SendJson(OnComplete, ResponseJson);
+    return true;
+  }
+
+  // ---------------------------------------------------------------------------
+  // Registration
+  // ---------------------------------------------------------------------------
+
+  void RegisterValidateRoutes(TSharedPtr<IHttpRouter> Router, TArray<FHttpRouteHandle>& Handles) {
+    Handles.Add(Router->BindRoute(FHttpPath(TEXT("/api/validate")),
+                                  EHttpServerRequestVerbs::VERB_POST,
+                                  FHttpRequestHandler::CreateStatic(&HandleValidate)));
+
+    UE_LOG(LogMCPUnreal, Verbose, TEXT("Registered validation routes (1 endpoint)"));
+  }
+
+}  // namespace MCPUnreal
@@ -19,8 +19,11 @@ DECLARE_LOG_CATEGORY_EXTERN(LogMCPUnreal, Log, All);
  * Starts an HTTP server on localhost:8090 (configurable via mcp.Port console
  * variable) that exposes editor internals to the mcp-unreal Go MCP server.
  *
- * The server binds to 127.0.0.1 only. All route handlers validate input
- * JSON before acting on it. See CLAUDE.md Security §3 and §4.
+ * The server binds to 127.0.0.1 by default. When an auth token is set
+ * (mcp.AuthToken or MCP_UNREAL_EDITOR_TOKEN), every request must carry it
+ * as "Authorization: Bearer <token>"; without one, only loopback peers are
+ * served. All route handlers validate input JSON before acting on it.
+ * See CLAUDE.md Security §3 and §4.
  */
 class FMCPUnrealModule : public IModuleInterface {
  public:
@@ -30,7 +33,14 @@ class FMCPUnrealModule : public IModuleInterface {
   virtual bool IsGameModule() const override { return false; }
 
   /** Plugin version reported by the /api/status endpoint. */
-  static constexpr const TCHAR* PluginVersion = TEXT("0.2.0");
+  static constexpr const TCHAR* PluginVersion = TEXT("0.5.0");
+
+  /**
+   * Version of the HTTP protocol spoken with the Go server. Bump it when a
+   * route changes incompatibly; adding routes only extends the route list
+   * reported by /api/status.
+   */
+  static constexpr int32 ProtocolVersion = 1;
 
  private:
   /** HTTP server lifecycle. */
@@ -40,9 +50,21 @@ class FMCPUnrealModule : public IModuleInterface {
   /** Route handlers — each returns true if the request was handled. */
   bool HandleStatus(const FHttpServerRequest& Request, const FHttpResultCallback& OnComplete);
 
+  /**
+   * Request preprocessor run before every route. Returns true (handled)
+   * after sending 401/403 when the request is not authorized.
+   */
+  bool AuthorizeRequest(const FHttpServerRequest& Request, const FHttpResultCallback& OnComplete);
+
+  /** Auth token from mcp.AuthToken, falling back to MCP_UNREAL_EDITOR_TOKEN. */
+  static FString GetAuthToken();
+
   /** Active route handles for cleanup. */
   TArray<FHttpRouteHandle> RouteHandles;
 
+  /** Handle of the AuthorizeRequest preprocessor for cleanup. */
+  FDelegateHandle AuthPreprocessorHandle;
+
   /** Server state. */
   bool bServerStarted = false;
   int32 ServerPort = 8090;
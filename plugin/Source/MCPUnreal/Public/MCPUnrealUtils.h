@@ -238,4 +238,20 @@ namespace MCPUnreal {
   void RegisterNetworkDebugRoutes(TSharedPtr<IHttpRouter> Router,
                                   TArray<FHttpRouteHandle>& Handles);
 
+  /** Register the editor event stream long-poll route and start capturing events. */
+  void RegisterEventRoutes(TSharedPtr<IHttpRouter> Router, TArray<FHttpRouteHandle>& Handles);
+
+  /** Stop capturing editor events and answer pending polls. */
+  void StopEventStream();
+
+  /** Register undo transaction routes (begin, end, undo, redo, history). */
+  void RegisterTransactionRoutes(TSharedPtr<IHttpRouter> Router,
+                                 TArray<FHttpRouteHandle>& Handles);
+
+  /** End any transaction still open through the transaction routes. */
+  void EndOpenTransactions();
+
+  /** Register the dry-run validation route. */
+  void RegisterValidateRoutes(TSharedPtr<IHttpRouter> Router, TArray<FHttpRouteHandle>& Handles);
+
 }  // namespace MCPUnreal